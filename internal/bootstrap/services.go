@@ -4,10 +4,12 @@ import (
 	"time"
 
 	ai "pixelpunk/internal/services/ai"
+	filesvc "pixelpunk/internal/services/file"
 	"pixelpunk/internal/services/message"
 	"pixelpunk/internal/services/setting"
 	"pixelpunk/internal/services/user"
 	vectorSvc "pixelpunk/internal/services/vector"
+	"pixelpunk/internal/services/webhook"
 	"pixelpunk/pkg/logger"
 	"pixelpunk/pkg/vector"
 )
@@ -22,6 +24,8 @@ func InitAllServices(appVersion string) {
 	if err := ai.InitGlobalTaggingQueue(); err != nil {
 		logger.Warn("AI打标队列初始化警告: %v", err)
 	}
+	filesvc.ResumeInterruptedStorageMigrations()
+	webhook.InitWebhookDelivery()
 }
 
 func initVectorEngine() {
@@ -31,15 +35,11 @@ func initVectorEngine() {
 		return
 	}
 
+	// 未配置或无法连接 Qdrant 时按 vector_store 设置回退到内置向量存储
 	qdrantURL := setting.GetStringDirectFromDB("vector", "qdrant_url", "")
-	if qdrantURL == "" {
-		logger.Warn("向量功能已启用，但未配置 qdrant_url，跳过初始化")
-		return
-	}
-
 	qdrantTimeout := setting.GetIntDirectFromDB("vector", "qdrant_timeout", 30)
 
-	if err := vector.InitQdrantVectorEngine(qdrantURL, qdrantTimeout); err != nil {
+	if err := vector.InitConfiguredVectorEngine(qdrantURL, qdrantTimeout); err != nil {
 		logger.Error("向量引擎初始化失败: %v", err)
 		return
 	}
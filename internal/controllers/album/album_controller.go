@@ -0,0 +1,263 @@
+package album
+
+// 相册控制器
+
+import (
+	"pixelpunk/internal/controllers/album/dto"
+	"pixelpunk/internal/middleware"
+	albumsvc "pixelpunk/internal/services/album"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func normalizePage(page, size, defaultSize int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = defaultSize
+	}
+	return page, size
+}
+
+func paginated(items interface{}, total int64, page, size int) gin.H {
+	return gin.H{
+		"items": items,
+		"pagination": gin.H{
+			"total":        total,
+			"size":         size,
+			"current_page": page,
+			"last_page":    (total + int64(size) - 1) / int64(size),
+		},
+	}
+}
+
+// CreateAlbum 创建相册
+func CreateAlbum(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.CreateAlbumDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	album, err := albumsvc.CreateAlbum(middleware.GetCurrentUserID(c), albumsvc.AlbumInput{
+		Title:       &req.Title,
+		Description: &req.Description,
+		Slug:        &req.Slug,
+		AccessLevel: &req.AccessLevel,
+		SortMode:    &req.SortMode,
+		SortDesc:    &req.SortDesc,
+		Layout:      &req.Layout,
+		LayoutHint:  req.LayoutHint,
+	})
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, album, "创建相册成功")
+}
+
+// ListAlbums 获取当前用户的相册
+func ListAlbums(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.AlbumListQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	page, size := normalizePage(req.Page, req.Size, 20)
+	albums, total, err := albumsvc.ListAlbums(middleware.GetCurrentUserID(c), req.Keyword, req.FileID, page, size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, paginated(albums, total, page, size), "获取成功")
+}
+
+// GetAlbum 获取相册详情
+func GetAlbum(c *gin.Context) {
+	album, err := albumsvc.GetAlbum(middleware.GetCurrentUserID(c), c.Param("album_id"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, album, "获取成功")
+}
+
+// UpdateAlbum 更新相册信息
+func UpdateAlbum(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.UpdateAlbumDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	album, err := albumsvc.UpdateAlbum(middleware.GetCurrentUserID(c), c.Param("album_id"), albumsvc.AlbumInput{
+		Title:       req.Title,
+		Description: req.Description,
+		Slug:        req.Slug,
+		AccessLevel: req.AccessLevel,
+		CoverFileID: req.CoverFileID,
+		SortMode:    req.SortMode,
+		SortDesc:    req.SortDesc,
+		Layout:      req.Layout,
+		LayoutHint:  req.LayoutHint,
+	})
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, album, "更新相册成功")
+}
+
+// DeleteAlbum 删除相册，相册中的文件不受影响
+func DeleteAlbum(c *gin.Context) {
+	if err := albumsvc.DeleteAlbum(middleware.GetCurrentUserID(c), c.Param("album_id")); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "删除相册成功")
+}
+
+// ReorderAlbums 调整相册顺序
+func ReorderAlbums(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.ReorderAlbumsDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	if err := albumsvc.ReorderAlbums(middleware.GetCurrentUserID(c), req.AlbumIDs); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "调整顺序成功")
+}
+
+// ListAlbumFiles 获取相册中的文件
+func ListAlbumFiles(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.AlbumFilesQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	page, size := normalizePage(req.Page, req.Size, 50)
+	files, total, err := albumsvc.ListAlbumFiles(middleware.GetCurrentUserID(c), c.Param("album_id"), page, size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, paginated(files, total, page, size), "获取成功")
+}
+
+// AddAlbumFiles 添加文件到相册
+func AddAlbumFiles(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.AlbumFileIDsDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	added, err := albumsvc.AddFiles(middleware.GetCurrentUserID(c), c.Param("album_id"), req.FileIDs)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"added": added}, "添加成功")
+}
+
+// RemoveAlbumFiles 从相册移除文件
+func RemoveAlbumFiles(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.AlbumFileIDsDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	removed, err := albumsvc.RemoveFiles(middleware.GetCurrentUserID(c), c.Param("album_id"), req.FileIDs)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"removed": removed}, "移除成功")
+}
+
+// ReorderAlbumFiles 重排相册中的文件
+func ReorderAlbumFiles(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.AlbumFileIDsDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	if err := albumsvc.ReorderFiles(middleware.GetCurrentUserID(c), c.Param("album_id"), req.FileIDs); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "调整顺序成功")
+}
+
+// UpdateAlbumFileCaption 设置文件在相册中的说明
+func UpdateAlbumFileCaption(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.UpdateAlbumFileCaptionDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	if err := albumsvc.UpdateFileCaption(middleware.GetCurrentUserID(c), c.Param("album_id"), c.Param("file_id"), req.Caption); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "更新说明成功")
+}
+
+// ViewPublicAlbum 公开画廊：通过访问标识查看公开或不公开列出的相册
+func ViewPublicAlbum(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.AlbumFilesQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	page, size := normalizePage(req.Page, req.Size, 50)
+	data, err := albumsvc.GetAlbumForView(c.Param("slug"), page, size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, data, "获取成功")
+}
+
+// ListUserPublicAlbums 获取用户的公开相册列表
+func ListUserPublicAlbums(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.AlbumFilesQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	page, size := normalizePage(req.Page, req.Size, 20)
+	albums, total, err := albumsvc.ListPublicAlbums(c.Param("username"), page, size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, paginated(albums, total, page, size), "获取成功")
+}
@@ -0,0 +1,120 @@
+package dto
+
+// CreateAlbumDTO 创建相册DTO
+type CreateAlbumDTO struct {
+	Title       string                 `json:"title" binding:"required,max=100"`
+	Description string                 `json:"description" binding:"omitempty,max=2000"`
+	Slug        string                 `json:"slug" binding:"omitempty,max=64"` // 为空时随机生成
+	AccessLevel string                 `json:"access_level" binding:"omitempty,oneof=public unlisted private"`
+	SortMode    string                 `json:"sort_mode" binding:"omitempty,oneof=custom taken_at created_at name"`
+	SortDesc    bool                   `json:"sort_desc"`
+	Layout      string                 `json:"layout" binding:"omitempty,oneof=grid masonry justified slideshow"`
+	LayoutHint  map[string]interface{} `json:"layout_hint"` // 前端展示提示，原样保存
+}
+
+func (d *CreateAlbumDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Title.required":    "相册标题不能为空",
+		"Title.max":         "相册标题不能超过100个字符",
+		"Description.max":   "相册描述不能超过2000个字符",
+		"Slug.max":          "访问标识不能超过64个字符",
+		"AccessLevel.oneof": "访问级别只能是 public、unlisted 或 private",
+		"SortMode.oneof":    "排序方式只能是 custom、taken_at、created_at 或 name",
+		"Layout.oneof":      "布局只能是 grid、masonry、justified 或 slideshow",
+	}
+}
+
+// UpdateAlbumDTO 更新相册DTO，未传字段保持不变
+type UpdateAlbumDTO struct {
+	Title       *string                `json:"title" binding:"omitempty,min=1,max=100"`
+	Description *string                `json:"description" binding:"omitempty,max=2000"`
+	Slug        *string                `json:"slug" binding:"omitempty,max=64"`
+	AccessLevel *string                `json:"access_level" binding:"omitempty,oneof=public unlisted private"`
+	CoverFileID *string                `json:"cover_file_id" binding:"omitempty,max=32"` // 传空字符串表示使用第一个文件作为封面
+	SortMode    *string                `json:"sort_mode" binding:"omitempty,oneof=custom taken_at created_at name"`
+	SortDesc    *bool                  `json:"sort_desc"`
+	Layout      *string                `json:"layout" binding:"omitempty,oneof=grid masonry justified slideshow"`
+	LayoutHint  map[string]interface{} `json:"layout_hint"`
+}
+
+func (d *UpdateAlbumDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Title.min":         "相册标题不能为空",
+		"Title.max":         "相册标题不能超过100个字符",
+		"Description.max":   "相册描述不能超过2000个字符",
+		"Slug.max":          "访问标识不能超过64个字符",
+		"AccessLevel.oneof": "访问级别只能是 public、unlisted 或 private",
+		"CoverFileID.max":   "封面文件ID格式错误",
+		"SortMode.oneof":    "排序方式只能是 custom、taken_at、created_at 或 name",
+		"Layout.oneof":      "布局只能是 grid、masonry、justified 或 slideshow",
+	}
+}
+
+// AlbumListQueryDTO 相册列表查询DTO
+type AlbumListQueryDTO struct {
+	Page    int    `form:"page" binding:"omitempty,min=1"`
+	Size    int    `form:"size" binding:"omitempty,min=1,max=100"`
+	Keyword string `form:"keyword" binding:"omitempty,max=100"`
+	FileID  string `form:"file_id" binding:"omitempty,max=32"` // 只返回包含该文件的相册
+}
+
+func (d *AlbumListQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Page.min":    "页码必须大于等于1",
+		"Size.min":    "每页数量必须大于等于1",
+		"Size.max":    "每页数量不能超过100",
+		"Keyword.max": "关键词不能超过100个字符",
+		"FileID.max":  "文件ID格式错误",
+	}
+}
+
+// AlbumFilesQueryDTO 相册文件分页查询DTO
+type AlbumFilesQueryDTO struct {
+	Page int `form:"page" binding:"omitempty,min=1"`
+	Size int `form:"size" binding:"omitempty,min=1,max=200"`
+}
+
+func (d *AlbumFilesQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Page.min": "页码必须大于等于1",
+		"Size.min": "每页数量必须大于等于1",
+		"Size.max": "每页数量不能超过200",
+	}
+}
+
+// AlbumFileIDsDTO 添加、移除或重排相册文件DTO
+type AlbumFileIDsDTO struct {
+	FileIDs []string `json:"file_ids" binding:"required,min=1,max=500"`
+}
+
+func (d *AlbumFileIDsDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"FileIDs.required": "文件ID列表不能为空",
+		"FileIDs.min":      "文件ID列表不能为空",
+		"FileIDs.max":      "单次最多操作500个文件",
+	}
+}
+
+// ReorderAlbumsDTO 调整相册顺序DTO
+type ReorderAlbumsDTO struct {
+	AlbumIDs []string `json:"album_ids" binding:"required,min=1,max=500"`
+}
+
+func (d *ReorderAlbumsDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"AlbumIDs.required": "相册ID列表不能为空",
+		"AlbumIDs.min":      "相册ID列表不能为空",
+		"AlbumIDs.max":      "单次最多调整500个相册",
+	}
+}
+
+// UpdateAlbumFileCaptionDTO 设置相册内文件说明DTO
+type UpdateAlbumFileCaptionDTO struct {
+	Caption string `json:"caption" binding:"max=500"`
+}
+
+func (d *UpdateAlbumFileCaptionDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Caption.max": "说明不能超过500个字符",
+	}
+}
@@ -32,6 +32,7 @@ func CreateAPIKey(c *gin.Context) {
 		req.AllowedTypes,
 		req.FolderID,
 		req.ExpiresInDays,
+		req.S3Permissions,
 	)
 	if err != nil {
 		errors.HandleError(c, err)
@@ -47,6 +48,7 @@ func CreateAPIKey(c *gin.Context) {
 		"single_file_limit":  apiKeyModel.SingleFileLimit,
 		"upload_count_limit": apiKeyModel.UploadCountLimit,
 		"allowed_types":      apikey.ParseAllowedTypes(apiKeyModel.AllowedTypes),
+		"s3_permissions":     apikey.ParseS3Permissions(apiKeyModel.S3Permissions),
 		"folder_id":          apiKeyModel.FolderID,
 		"expires_at":         apiKeyModel.ExpiresAt,
 		"created_at":         apiKeyModel.CreatedAt,
@@ -114,6 +116,7 @@ func GetAPIKeyList(c *gin.Context) {
 			"folder_id":          key.FolderID,
 			"folder_path":        folderPath,
 			"allowed_types":      apikey.ParseAllowedTypes(key.AllowedTypes),
+			"s3_permissions":     apikey.ParseS3Permissions(key.S3Permissions),
 			"is_expired":         key.IsExpired(),
 			"expires_at":         key.ExpiresAt,
 			"last_used_at":       key.LastUsedAt,
@@ -165,6 +168,7 @@ func GetAPIKeyDetail(c *gin.Context) {
 		"folder_id":          key.FolderID,
 		"folder_path":        folderPath,
 		"allowed_types":      apikey.ParseAllowedTypes(key.AllowedTypes),
+		"s3_permissions":     apikey.ParseS3Permissions(key.S3Permissions),
 		"is_expired":         key.IsExpired(),
 		"expires_at":         key.ExpiresAt,
 		"last_used_at":       key.LastUsedAt,
@@ -212,6 +216,9 @@ func UpdateAPIKey(c *gin.Context) {
 	if req.FolderID != "" {
 		updates["folder_id"] = req.FolderID
 	}
+	if req.S3Permissions != nil {
+		updates["s3_permissions"] = req.S3Permissions
+	}
 	if c.Request.Method == "PUT" || c.PostForm("expires_in_days") != "" || c.Request.Header.Get("Content-Type") == "application/json" {
 		updates["expires_in_days"] = req.ExpiresInDays
 	}
@@ -235,6 +242,7 @@ func UpdateAPIKey(c *gin.Context) {
 		"single_file_limit":  updatedKey.SingleFileLimit,
 		"folder_id":          updatedKey.FolderID,
 		"allowed_types":      apikey.ParseAllowedTypes(updatedKey.AllowedTypes),
+		"s3_permissions":     apikey.ParseS3Permissions(updatedKey.S3Permissions),
 		"is_expired":         updatedKey.IsExpired(),
 		"expires_at":         updatedKey.ExpiresAt,
 		"updated_at":         updatedKey.UpdatedAt,
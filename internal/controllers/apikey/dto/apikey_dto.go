@@ -8,6 +8,7 @@ type CreateAPIKeyDTO struct {
 	AllowedTypes     []string `json:"allowed_types" binding:"omitempty"`
 	FolderID         string   `json:"folder_id" binding:"omitempty"`
 	ExpiresInDays    int      `json:"expires_in_days" binding:"omitempty,min=0"`
+	S3Permissions    []string `json:"s3_permissions" binding:"omitempty"`
 }
 
 func (d *CreateAPIKeyDTO) GetValidationMessages() map[string]string {
@@ -31,6 +32,7 @@ type UpdateAPIKeyDTO struct {
 	FolderID         string   `json:"folder_id" binding:"omitempty"`
 	ExpiresInDays    int      `json:"expires_in_days" binding:"omitempty,min=0"`
 	Status           int      `json:"status" binding:"omitempty,oneof=1 2"`
+	S3Permissions    []string `json:"s3_permissions" binding:"omitempty"`
 }
 
 func (d *UpdateAPIKeyDTO) GetValidationMessages() map[string]string {
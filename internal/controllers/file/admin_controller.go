@@ -6,6 +6,7 @@ import (
 	"pixelpunk/internal/services/activity"
 	filesvc "pixelpunk/internal/services/file"
 	messageService "pixelpunk/internal/services/message"
+	"pixelpunk/internal/services/webhook"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
@@ -229,7 +230,7 @@ func AdminDeleteFile(c *gin.Context) {
 		return
 	}
 
-	err := filesvc.DeleteFile(fileRecord.UserID, req.FileID)
+	err := filesvc.PurgeFile(fileRecord.UserID, req.FileID)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -239,6 +240,7 @@ func AdminDeleteFile(c *gin.Context) {
 
 	// 记录管理员删除活动日志
 	activity.LogAdminDelete(fileRecord.UserID, req.FileID, adminID)
+	webhook.Dispatch(webhook.EventFileDeleted, fileRecord.UserID, map[string]interface{}{"file_id": req.FileID, "trashed": false, "by_admin": true})
 
 	// 异步发送管理员删除文件通知
 	go sendAdminDeleteNotification(fileRecord.UserID, fileRecord.ID, fileRecord.OriginalName)
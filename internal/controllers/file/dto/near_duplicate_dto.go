@@ -0,0 +1,35 @@
+package dto
+
+// NearDuplicateQueryDTO 近似重复查询DTO
+type NearDuplicateQueryDTO struct {
+	Distance int  `form:"distance" binding:"omitempty,min=1,max=7"` // 最大汉明距离
+	Limit    int  `form:"limit" binding:"omitempty,min=1,max=100"`  // 最多返回的分组数量
+	UserID   uint `form:"user_id"`                                  // 仅管理员接口使用
+}
+
+func (d *NearDuplicateQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Distance.min": "汉明距离必须大于等于1",
+		"Distance.max": "汉明距离不能超过7",
+		"Limit.min":    "分组数量必须大于等于1",
+		"Limit.max":    "分组数量不能超过100",
+	}
+}
+
+// MergeNearDuplicatesDTO 合并近似重复文件DTO
+type MergeNearDuplicatesDTO struct {
+	KeepID       string   `json:"keep_id" binding:"required"`
+	DuplicateIDs []string `json:"duplicate_ids" binding:"required,min=1,max=100"`
+	Distance     int      `json:"distance" binding:"omitempty,min=1,max=7"`
+}
+
+func (d *MergeNearDuplicatesDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"KeepID.required":       "保留文件ID不能为空",
+		"DuplicateIDs.required": "待合并文件列表不能为空",
+		"DuplicateIDs.min":      "至少需要选择一个待合并文件",
+		"DuplicateIDs.max":      "单次最多合并100个文件",
+		"Distance.min":          "汉明距离必须大于等于1",
+		"Distance.max":          "汉明距离不能超过7",
+	}
+}
@@ -0,0 +1,63 @@
+package dto
+
+// CreateSignedLinkDTO 生成签名链接DTO
+type CreateSignedLinkDTO struct {
+	ExpireMinutes   int    `json:"expire_minutes" binding:"omitempty,min=1"`
+	MaxUses         int    `json:"max_uses" binding:"omitempty,min=0"`
+	AllowedIPs      string `json:"allowed_ips" binding:"omitempty,max=500"`      // IP或CIDR，逗号分隔
+	AllowedReferers string `json:"allowed_referers" binding:"omitempty,max=500"` // 来源域名，支持 *.example.com
+	Native          bool   `json:"native"`                                       // 优先使用存储渠道原生预签名
+	Note            string `json:"note" binding:"omitempty,max=255"`
+}
+
+func (d *CreateSignedLinkDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"ExpireMinutes.min":   "有效期必须大于0分钟",
+		"MaxUses.min":         "最大使用次数不能为负数",
+		"AllowedIPs.max":      "IP列表不能超过500个字符",
+		"AllowedReferers.max": "来源域名列表不能超过500个字符",
+		"Note.max":            "备注不能超过255个字符",
+	}
+}
+
+// BatchCreateSignedLinkDTO 批量生成签名链接DTO
+type BatchCreateSignedLinkDTO struct {
+	CreateSignedLinkDTO
+	FileIDs []string `json:"file_ids" binding:"required,min=1,max=100"`
+}
+
+func (d *BatchCreateSignedLinkDTO) GetValidationMessages() map[string]string {
+	messages := d.CreateSignedLinkDTO.GetValidationMessages()
+	messages["FileIDs.required"] = "文件ID列表不能为空"
+	messages["FileIDs.min"] = "至少需要选择一个文件"
+	messages["FileIDs.max"] = "单次最多签名100个文件"
+	return messages
+}
+
+// SignedLinkListQueryDTO 签名链接列表查询DTO
+type SignedLinkListQueryDTO struct {
+	Page   int    `form:"page" binding:"omitempty,min=1"`
+	Size   int    `form:"size" binding:"omitempty,min=1,max=100"`
+	FileID string `form:"file_id"`
+}
+
+func (d *SignedLinkListQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Page.min": "页码必须大于等于1",
+		"Size.min": "每页数量必须大于等于1",
+		"Size.max": "每页数量不能超过100",
+	}
+}
+
+// RevokeSignedLinksDTO 撤销签名链接DTO
+type RevokeSignedLinksDTO struct {
+	LinkIDs []string `json:"link_ids" binding:"required,min=1,max=100"`
+}
+
+func (d *RevokeSignedLinksDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"LinkIDs.required": "链接ID列表不能为空",
+		"LinkIDs.min":      "至少需要选择一个链接",
+		"LinkIDs.max":      "单次最多撤销100个链接",
+	}
+}
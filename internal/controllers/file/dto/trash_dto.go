@@ -0,0 +1,28 @@
+package dto
+
+// TrashListQueryDTO 回收站列表查询DTO
+type TrashListQueryDTO struct {
+	Page int `form:"page" binding:"omitempty,min=1"`
+	Size int `form:"size" binding:"omitempty,min=1,max=100"`
+}
+
+func (d *TrashListQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Page.min": "页码必须大于等于1",
+		"Size.min": "每页数量必须大于等于1",
+		"Size.max": "每页数量不能超过100",
+	}
+}
+
+// TrashItemsDTO 回收站条目操作DTO（恢复/彻底删除）
+type TrashItemsDTO struct {
+	IDs []string `json:"ids" binding:"required,min=1,max=100"`
+}
+
+func (d *TrashItemsDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"IDs.required": "回收站条目ID不能为空",
+		"IDs.min":      "至少需要选择一个条目",
+		"IDs.max":      "单次最多操作100个条目",
+	}
+}
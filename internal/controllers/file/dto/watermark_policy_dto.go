@@ -0,0 +1,64 @@
+package dto
+
+// CreateWatermarkPolicyDTO 创建水印策略DTO
+type CreateWatermarkPolicyDTO struct {
+	Name      *string `json:"name" binding:"omitempty,max=100"`
+	ScopeType string  `json:"scope_type" binding:"required,oneof=user folder apikey"`
+	ScopeID   string  `json:"scope_id" binding:"omitempty,max=36"` // 作用域为 user 时忽略
+	Config    string  `json:"config" binding:"required"`           // 水印配置 JSON
+	Enabled   *bool   `json:"enabled"`
+}
+
+func (d *CreateWatermarkPolicyDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Name.max":           "策略名称不能超过100个字符",
+		"ScopeType.required": "作用域类型不能为空",
+		"ScopeType.oneof":    "作用域类型只能是 user、folder 或 apikey",
+		"ScopeID.max":        "作用域ID不能超过36个字符",
+		"Config.required":    "水印配置不能为空",
+	}
+}
+
+// UpdateWatermarkPolicyDTO 更新水印策略DTO，未传字段保持不变
+type UpdateWatermarkPolicyDTO struct {
+	Name      *string `json:"name" binding:"omitempty,max=100"`
+	ScopeType string  `json:"scope_type" binding:"omitempty,oneof=user folder apikey"`
+	ScopeID   string  `json:"scope_id" binding:"omitempty,max=36"`
+	Config    *string `json:"config"`
+	Enabled   *bool   `json:"enabled"`
+}
+
+func (d *UpdateWatermarkPolicyDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Name.max":        "策略名称不能超过100个字符",
+		"ScopeType.oneof": "作用域类型只能是 user、folder 或 apikey",
+		"ScopeID.max":     "作用域ID不能超过36个字符",
+	}
+}
+
+// WatermarkPolicyListQueryDTO 水印策略列表查询DTO
+type WatermarkPolicyListQueryDTO struct {
+	Page int `form:"page" binding:"omitempty,min=1"`
+	Size int `form:"size" binding:"omitempty,min=1,max=100"`
+}
+
+func (d *WatermarkPolicyListQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Page.min": "页码必须大于等于1",
+		"Size.min": "每页数量必须大于等于1",
+		"Size.max": "每页数量不能超过100",
+	}
+}
+
+// PreviewWatermarkDTO 水印预览DTO
+type PreviewWatermarkDTO struct {
+	FileID string `json:"file_id" binding:"required"`
+	Config string `json:"config" binding:"required"`
+}
+
+func (d *PreviewWatermarkDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"FileID.required": "文件ID不能为空",
+		"Config.required": "水印配置不能为空",
+	}
+}
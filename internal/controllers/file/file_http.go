@@ -0,0 +1,208 @@
+package file
+
+/* 文件响应的缓存校验与 Range 支持 */
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errBackwardSeek = fmt.Errorf("代理内容不支持向后定位")
+
+/* fileValidators 基于存储的 MD5 与更新时间生成 ETag 和 Last-Modified */
+func fileValidators(file models.File, isThumb bool) (string, time.Time) {
+	modTime := time.Time(file.UpdatedAt)
+	tag := file.MD5Hash
+	if tag == "" {
+		tag = file.ID + "-" + strconv.FormatInt(modTime.Unix(), 36)
+	}
+	if isThumb {
+		tag += "-thumb"
+	}
+	return `"` + tag + `"`, modTime
+}
+
+/* checkNotModified 写入校验头并处理 If-None-Match / If-Modified-Since，命中时返回 304 */
+func checkNotModified(c *gin.Context, etag string, modTime time.Time) bool {
+	c.Header("ETag", etag)
+	if !modTime.IsZero() {
+		c.Header("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	}
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+
+	notModified := false
+	if inm := c.GetHeader("If-None-Match"); inm != "" {
+		notModified = etagListMatch(inm, etag)
+	} else if ims := c.GetHeader("If-Modified-Since"); ims != "" && !modTime.IsZero() {
+		if t, err := http.ParseTime(ims); err == nil {
+			notModified = !modTime.Truncate(time.Second).After(t)
+		}
+	}
+	if !notModified {
+		return false
+	}
+
+	h := c.Writer.Header()
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	h.Del("Content-Disposition")
+	c.Status(http.StatusNotModified)
+	c.Writer.WriteHeaderNow()
+	return true
+}
+
+/* etagListMatch 按弱比较判断 If-None-Match 列表是否包含当前 ETag */
+func etagListMatch(header, etag string) bool {
+	target := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == target {
+			return true
+		}
+	}
+	return false
+}
+
+/* serveLocalFile 以条件请求和 Range 语义返回本地文件 */
+func serveLocalFile(c *gin.Context, localPath string, file models.File, isThumb bool) {
+	etag, modTime := fileValidators(file, isThumb)
+	if checkNotModified(c, etag, modTime) {
+		return
+	}
+	f, err := os.Open(localPath)
+	if err != nil {
+		errors.HandleError(c, errors.Wrap(err, errors.CodeFileNotFound, "文件不存在"))
+		return
+	}
+	defer f.Close()
+	http.ServeContent(c.Writer, c.Request, filepath.Base(localPath), modTime, f)
+}
+
+/* serveProxyFile 以条件请求和 Range 语义转发代理模式的远程内容，304 时不读取远程数据 */
+func serveProxyFile(c *gin.Context, resp *filesvc.ProxyResponse, file models.File, isThumb bool) {
+	defer resp.Content.Close()
+
+	etag, modTime := fileValidators(file, isThumb)
+	if checkNotModified(c, etag, modTime) {
+		return
+	}
+	// 预先确定类型，避免 ServeContent 嗅探内容时向后定位
+	contentType := resp.ContentType
+	if contentType == "" {
+		contentType = filesvc.GetContentTypeByFormat(file.Format)
+	}
+	c.Header("Content-Type", contentType)
+
+	size := resp.ContentLength
+	rangeHeader := c.GetHeader("Range")
+	if countRanges(rangeHeader) > maxProxyRanges {
+		// 区间过多时每段都要单独回源，直接返回完整内容
+		c.Request.Header.Del("Range")
+		rangeHeader = ""
+	}
+
+	switch {
+	case size > 0 && resp.OpenRange != nil:
+		content := filesvc.NewRangeSeeker(resp.OpenRange, size, rangeHeader)
+		defer content.Close()
+		http.ServeContent(c.Writer, c.Request, "", modTime, content)
+	case size > 0:
+		if rs, ok := resp.Content.(io.ReadSeeker); ok {
+			http.ServeContent(c.Writer, c.Request, "", modTime, rs)
+			return
+		}
+		if isMultiRange(rangeHeader) {
+			// 顺序流无法乱序读取多个区间，忽略 Range 返回完整内容
+			c.Request.Header.Del("Range")
+		}
+		http.ServeContent(c.Writer, c.Request, "", modTime, &forwardSeeker{r: resp.Content, size: size})
+	default:
+		// 长度未知时无法计算区间，忽略 Range 直接流式返回完整内容
+		c.Status(http.StatusOK)
+		if c.Request.Method != http.MethodHead {
+			_, _ = io.Copy(c.Writer, resp.Content)
+		}
+	}
+}
+
+/* serveContentBytes 以条件请求和 Range 语义返回内存中的文件内容 */
+func serveContentBytes(c *gin.Context, data []byte, file models.File, isThumb bool) {
+	etag, modTime := fileValidators(file, isThumb)
+	if checkNotModified(c, etag, modTime) {
+		return
+	}
+	http.ServeContent(c.Writer, c.Request, "", modTime, bytes.NewReader(data))
+}
+
+// 代理模式单个请求允许的最大区间数，每个区间对应一次回源请求
+const maxProxyRanges = 8
+
+func isMultiRange(header string) bool {
+	return countRanges(header) > 1
+}
+
+func countRanges(header string) int {
+	if !strings.HasPrefix(header, "bytes=") {
+		return 0
+	}
+	return strings.Count(header, ",") + 1
+}
+
+/* forwardSeeker 将已知长度的只读流包装为仅支持向前定位的 io.ReadSeeker，跳过的字节直接丢弃 */
+type forwardSeeker struct {
+	r    io.Reader
+	size int64
+	pos  int64
+	read int64
+}
+
+func (s *forwardSeeker) Read(p []byte) (int, error) {
+	if s.pos < s.read {
+		return 0, errBackwardSeek
+	}
+	if s.pos > s.read {
+		n, err := io.CopyN(io.Discard, s.r, s.pos-s.read)
+		s.read += n
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := s.r.Read(p)
+	s.read += int64(n)
+	s.pos = s.read
+	return n, err
+}
+
+func (s *forwardSeeker) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = s.pos + offset
+	case io.SeekEnd:
+		abs = s.size + offset
+	default:
+		return 0, fmt.Errorf("无效的定位方式: %d", whence)
+	}
+	if abs < 0 {
+		return 0, fmt.Errorf("无效的定位位置: %d", abs)
+	}
+	s.pos = abs
+	return abs, nil
+}
@@ -0,0 +1,163 @@
+package file
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pixelpunk/internal/models"
+	filesvc "pixelpunk/internal/services/file"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestForwardSeekerServesSingleRange(t *testing.T) {
+	body := "0123456789abcdef"
+	content := &forwardSeeker{r: strings.NewReader(body), size: int64(len(body))}
+
+	req := httptest.NewRequest(http.MethodGet, "/f/x", nil)
+	req.Header.Set("Range", "bytes=4-9")
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Type", "image/png")
+	http.ServeContent(rec, req, "", time.Time{}, content)
+
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("status = %d, want 206", rec.Code)
+	}
+	if got := rec.Body.String(); got != "456789" {
+		t.Fatalf("body = %q, want %q", got, "456789")
+	}
+	if got := rec.Header().Get("Content-Range"); got != "bytes 4-9/16" {
+		t.Fatalf("Content-Range = %q", got)
+	}
+}
+
+func TestForwardSeekerRejectsBackwardRead(t *testing.T) {
+	s := &forwardSeeker{r: strings.NewReader("abcdef"), size: 6}
+	if _, err := io.ReadFull(s, make([]byte, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Seek(1, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Read(make([]byte, 1)); err != errBackwardSeek {
+		t.Fatalf("err = %v, want errBackwardSeek", err)
+	}
+}
+
+func TestEtagListMatch(t *testing.T) {
+	cases := []struct {
+		header string
+		want   bool
+	}{
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"x", "abc"`, true},
+		{`*`, true},
+		{`"abd"`, false},
+	}
+	for _, tc := range cases {
+		if got := etagListMatch(tc.header, `"abc"`); got != tc.want {
+			t.Errorf("etagListMatch(%q) = %v, want %v", tc.header, got, tc.want)
+		}
+	}
+}
+
+// fakeOrigin 记录每次回源的区间，模拟支持区间读取的存储渠道
+type fakeOrigin struct {
+	body  string
+	calls []string
+}
+
+func (o *fakeOrigin) response() *filesvc.ProxyResponse {
+	return &filesvc.ProxyResponse{
+		Content:       io.NopCloser(strings.NewReader(o.body)),
+		ContentType:   "video/mp4",
+		ContentLength: int64(len(o.body)),
+		OpenRange: func(offset, length int64) (io.ReadCloser, error) {
+			o.calls = append(o.calls, fmt.Sprintf("%d+%d", offset, length))
+			end := int64(len(o.body))
+			if length >= 0 {
+				end = offset + length
+			}
+			return io.NopCloser(strings.NewReader(o.body[offset:end])), nil
+		},
+	}
+}
+
+func serveProxyRequest(resp *filesvc.ProxyResponse, rangeHeader string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/f/x", nil)
+	if rangeHeader != "" {
+		c.Request.Header.Set("Range", rangeHeader)
+	}
+	serveProxyFile(c, resp, models.File{ID: "x", MD5Hash: "abc"}, false)
+	return rec
+}
+
+func TestServeProxyFileForwardsSingleRange(t *testing.T) {
+	origin := &fakeOrigin{body: "0123456789abcdef"}
+	rec := serveProxyRequest(origin.response(), "bytes=4-9")
+
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("status = %d, want 206", rec.Code)
+	}
+	if got := rec.Body.String(); got != "456789" {
+		t.Fatalf("body = %q", got)
+	}
+	if got := rec.Header().Get("Content-Range"); got != "bytes 4-9/16" {
+		t.Fatalf("Content-Range = %q", got)
+	}
+	if len(origin.calls) != 1 || origin.calls[0] != "4+6" {
+		t.Fatalf("origin calls = %v, want [4+6]", origin.calls)
+	}
+}
+
+func TestServeProxyFileUnsatisfiableRange(t *testing.T) {
+	origin := &fakeOrigin{body: "0123456789abcdef"}
+	rec := serveProxyRequest(origin.response(), "bytes=100-200")
+
+	if rec.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatalf("status = %d, want 416", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Range"); got != "bytes */16" {
+		t.Fatalf("Content-Range = %q", got)
+	}
+	if len(origin.calls) != 0 {
+		t.Fatalf("origin should not be read, calls = %v", origin.calls)
+	}
+}
+
+func TestServeProxyFileMultiRange(t *testing.T) {
+	origin := &fakeOrigin{body: "0123456789abcdef"}
+	rec := serveProxyRequest(origin.response(), "bytes=10-11,0-1")
+
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("status = %d, want 206", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "multipart/byteranges") {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Content-Range: bytes 10-11/16\r\nContent-Type: video/mp4\r\n\r\nab") || !strings.Contains(body, "Content-Range: bytes 0-1/16\r\nContent-Type: video/mp4\r\n\r\n01") {
+		t.Fatalf("unexpected multipart body: %q", body)
+	}
+	if len(origin.calls) != 2 || origin.calls[0] != "10+2" || origin.calls[1] != "0+2" {
+		t.Fatalf("origin calls = %v, want [10+2 0+2]", origin.calls)
+	}
+}
+
+func TestServeProxyFileUnknownLengthIgnoresRange(t *testing.T) {
+	resp := &filesvc.ProxyResponse{Content: io.NopCloser(strings.NewReader("hello")), ContentType: "image/png"}
+	rec := serveProxyRequest(resp, "bytes=0-1")
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "hello" {
+		t.Fatalf("status = %d, body = %q", rec.Code, rec.Body.String())
+	}
+}
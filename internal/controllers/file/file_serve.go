@@ -2,7 +2,6 @@ package file
 
 import (
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -66,18 +65,9 @@ func ServeFile(c *gin.Context) {
 			assets.ServeDefaultFile(c, assets.FileTypeNotFound)
 			return
 		}
-		c.File(localPath)
+		serveLocalFile(c, localPath, fileInfo, forceThumbnail)
 	} else if isProxy {
-		proxyResp := result.(*filesvc.ProxyResponse)
-		defer proxyResp.Content.Close()
-
-		// 设置Content-Type
-		c.Header("Content-Type", proxyResp.ContentType)
-		// 设置Content-Length以支持真实下载进度
-		c.Header("Content-Length", strconv.FormatInt(fileInfo.Size, 10))
-
-		c.Status(http.StatusOK)
-		io.Copy(c.Writer, proxyResp.Content)
+		serveProxyFile(c, result.(*filesvc.ProxyResponse), fileInfo, forceThumbnail)
 	} else {
 		url := result.(string)
 		c.Redirect(http.StatusFound, url)
@@ -122,20 +112,9 @@ func ServeThumbnailFile(c *gin.Context) {
 			assets.ServeDefaultFile(c, assets.FileTypeNotFound)
 			return
 		}
-		c.File(localPath)
+		serveLocalFile(c, localPath, fileInfo, true)
 	} else if isProxy {
-		proxyResp := result.(*filesvc.ProxyResponse)
-		defer proxyResp.Content.Close()
-
-		// 设置Content-Type
-		c.Header("Content-Type", proxyResp.ContentType)
-		// 对于缩略图，如果有实际内容长度则设置，否则不设置避免大小不匹配
-		if proxyResp.ContentLength > 0 {
-			c.Header("Content-Length", strconv.FormatInt(proxyResp.ContentLength, 10))
-		}
-
-		c.Status(http.StatusOK)
-		io.Copy(c.Writer, proxyResp.Content)
+		serveProxyFile(c, result.(*filesvc.ProxyResponse), fileInfo, true)
 	} else {
 		url := result.(string)
 		c.Redirect(http.StatusFound, url)
@@ -235,6 +214,27 @@ func DownloadFile(c *gin.Context) {
 		}
 	}
 
+	// 文件命中水印策略时只有所有者可以下载无水印原图，其他人下载水印图
+	if currentUserID != file.UserID {
+		var wmResp *filesvc.ProxyResponse
+		var err error
+		if isThumb {
+			wmResp, err = filesvc.ServeWatermarkedThumb(file)
+		} else {
+			wmResp, err = filesvc.ServeWatermarkedFile(file)
+		}
+		if err != nil {
+			errors.HandleError(c, err)
+			return
+		}
+		if wmResp != nil {
+			recordDownload(c, fileID, file.Size, currentUserID)
+			c.Header("Content-Disposition", utils.SetContentDispositionFilename(fileName))
+			writeTransformedFile(c, wmResp, "private, max-age=0")
+			return
+		}
+	}
+
 	// 根据quality参数获取相应的文件文件
 	result, isLocal, isProxy, err := filesvc.ServeFile(file, isThumb)
 	if err != nil {
@@ -242,18 +242,7 @@ func DownloadFile(c *gin.Context) {
 		return
 	}
 
-	go func() {
-		downloadLog := &models.FileDownloadLog{
-			UserID:    currentUserID, // 用户ID（公开文件下载时可能为0）
-			FileID:    fileID,
-			FileSize:  file.Size,
-			IPAddress: c.ClientIP(),
-			UserAgent: c.GetHeader("User-Agent"),
-		}
-		if err := database.DB.Create(downloadLog).Error; err != nil {
-			logger.Error("记录下载日志失败: %v", err)
-		}
-	}()
+	recordDownload(c, fileID, file.Size, currentUserID)
 
 	// 根据quality参数调整文件名
 	if isThumb && quality != "" && quality != "original" {
@@ -289,7 +278,7 @@ func DownloadFile(c *gin.Context) {
 					// 根据格式设置正确的Content-Type（保持DB格式）
 					contentType := filesvc.GetContentTypeByFormat(file.Format)
 					c.Header("Content-Type", contentType)
-					c.Data(http.StatusOK, contentType, normalizedData)
+					serveContentBytes(c, normalizedData, file, isThumb)
 					return
 				}
 			}
@@ -299,17 +288,28 @@ func DownloadFile(c *gin.Context) {
 			c.Header("Content-Type", contentType)
 		}
 
-		c.File(filePath)
+		serveLocalFile(c, filePath, file, isThumb)
 	case isProxy:
-		proxyResp := result.(*filesvc.ProxyResponse)
-		defer proxyResp.Content.Close()
-		// 设置Content-Length以支持真实下载进度
-		c.Header("Content-Length", strconv.FormatInt(file.Size, 10))
-		c.Status(http.StatusOK)
-		io.Copy(c.Writer, proxyResp.Content)
+		serveProxyFile(c, result.(*filesvc.ProxyResponse), file, isThumb)
 	default:
 		c.Redirect(http.StatusTemporaryRedirect, result.(string))
 	}
 }
 
+// recordDownload 异步记录下载日志
+func recordDownload(c *gin.Context, fileID string, fileSize int64, userID uint) {
+	downloadLog := &models.FileDownloadLog{
+		UserID:    userID, // 用户ID（公开文件下载时可能为0）
+		FileID:    fileID,
+		FileSize:  fileSize,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.GetHeader("User-Agent"),
+	}
+	go func() {
+		if err := database.DB.Create(downloadLog).Error; err != nil {
+			logger.Error("记录下载日志失败: %v", err)
+		}
+	}()
+}
+
 // helpers moved to services/file (GetCorrectFileExtension, GetContentTypeByFormat)
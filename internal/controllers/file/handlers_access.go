@@ -4,12 +4,18 @@ import (
 	"io"
 	"pixelpunk/internal/models"
 	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/assets"
 	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/imagex/transform"
+	"pixelpunk/pkg/logger"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
+// 盗链缩略图的水印图尺寸上限
+const hotlinkThumbSize = 400
+
 func ServeFileByID(c *gin.Context) {
 	fileObj, exists := c.Get("file_info")
 	if !exists {
@@ -23,6 +29,44 @@ func ServeFileByID(c *gin.Context) {
 		return
 	}
 
+	if name := c.Query("rendition"); name != "" {
+		if isHotlinkWatermarked(c) {
+			preset, ok := filesvc.FindRenditionPreset(name)
+			if !ok {
+				errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "衍生尺寸预设不存在"))
+				return
+			}
+			serveHotlinkWatermark(c, fileInfo, preset.Options())
+			return
+		}
+
+		resp, err := filesvc.ServeRendition(fileInfo, name)
+		if err != nil {
+			errors.HandleError(c, err)
+			return
+		}
+		// 预设可由管理员调整，衍生尺寸不使用 immutable 缓存
+		writeTransformedFile(c, resp, "public, max-age=86400")
+		return
+	}
+
+	opts, hasTransform, err := transform.FromQuery(c.Request.URL.Query())
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, err.Error()))
+		return
+	}
+	if isHotlinkWatermarked(c) {
+		serveHotlinkWatermark(c, fileInfo, opts)
+		return
+	}
+	if hasTransform {
+		serveTransformedFile(c, fileInfo, opts)
+		return
+	}
+
+	if serveWatermarkedFile(c, fileInfo) {
+		return
+	}
 	serveFileByInfo(c, fileInfo, false)
 }
 
@@ -39,6 +83,13 @@ func ServeThumbByID(c *gin.Context) {
 		return
 	}
 
+	if isHotlinkWatermarked(c) {
+		serveHotlinkWatermark(c, fileInfo, transform.Options{Width: hotlinkThumbSize, Height: hotlinkThumbSize})
+		return
+	}
+	if serveWatermarkedThumb(c, fileInfo) {
+		return
+	}
 	serveFileByInfo(c, fileInfo, true)
 }
 
@@ -55,6 +106,13 @@ func ServeFileByShortURL(c *gin.Context) {
 		return
 	}
 
+	if isHotlinkWatermarked(c) {
+		serveHotlinkWatermark(c, fileInfo, transform.Options{})
+		return
+	}
+	if serveWatermarkedFile(c, fileInfo) {
+		return
+	}
 	serveFileByInfo(c, fileInfo, false)
 }
 
@@ -70,27 +128,14 @@ func serveFileByInfo(c *gin.Context, fileInfo models.File, isThumb bool) {
 
 	if isLocalPath {
 		if filePath, ok := result.(string); ok {
-			c.File(filePath)
+			serveLocalFile(c, filePath, fileInfo, isThumb)
 		}
 		return
 	}
 
 	if isProxy {
 		if proxyResp, ok := result.(*filesvc.ProxyResponse); ok {
-			defer proxyResp.Content.Close()
-
-			c.Header("Content-Type", proxyResp.ContentType)
-
-			if isThumb {
-				if proxyResp.ContentLength > 0 {
-					c.Header("Content-Length", strconv.FormatInt(proxyResp.ContentLength, 10))
-				}
-			} else {
-				c.Header("Content-Length", strconv.FormatInt(fileInfo.Size, 10))
-			}
-
-			c.Status(200)
-			io.Copy(c.Writer, proxyResp.Content)
+			serveProxyFile(c, proxyResp, fileInfo, isThumb)
 		}
 		return
 	}
@@ -99,3 +144,76 @@ func serveFileByInfo(c *gin.Context, fileInfo models.File, isThumb bool) {
 		c.Redirect(302, url)
 	}
 }
+
+func serveTransformedFile(c *gin.Context, fileInfo models.File, opts transform.Options) {
+	resp, err := filesvc.ServeTransformedFile(fileInfo, opts)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	cacheControl := "public, max-age=2592000, immutable"
+	if filesvc.ResolveWatermarkPolicy(fileInfo) != nil {
+		cacheControl = "public, max-age=86400"
+	}
+	writeTransformedFile(c, resp, cacheControl)
+}
+
+// serveWatermarkedFile 文件命中水印策略时输出水印图，返回是否已响应
+func serveWatermarkedFile(c *gin.Context, fileInfo models.File) bool {
+	resp, err := filesvc.ServeWatermarkedFile(fileInfo)
+	if err != nil {
+		errors.HandleError(c, err)
+		return true
+	}
+	if resp == nil {
+		return false
+	}
+	// 策略可随时调整，水印图不使用 immutable 缓存
+	writeTransformedFile(c, resp, "public, max-age=86400")
+	return true
+}
+
+// serveWatermarkedThumb 有水印策略时缩略图同样输出水印图，避免通过缩略图地址获取无水印内容
+func serveWatermarkedThumb(c *gin.Context, fileInfo models.File) bool {
+	resp, err := filesvc.ServeWatermarkedThumb(fileInfo)
+	if err != nil {
+		errors.HandleError(c, err)
+		return true
+	}
+	if resp == nil {
+		return false
+	}
+	writeTransformedFile(c, resp, "public, max-age=86400")
+	return true
+}
+
+func writeTransformedFile(c *gin.Context, resp *filesvc.ProxyResponse, cacheControl string) {
+	defer resp.Content.Close()
+
+	c.Header("Cache-Control", cacheControl)
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Content-Type", resp.ContentType)
+	if resp.ContentLength > 0 {
+		c.Header("Content-Length", strconv.FormatInt(resp.ContentLength, 10))
+	}
+	c.Status(200)
+	io.Copy(c.Writer, resp.Content)
+}
+
+// isHotlinkWatermarked 防盗链检查未通过且阻止行为为水印时由访问控制中间件标记
+func isHotlinkWatermarked(c *gin.Context) bool {
+	return c.GetBool("watermark")
+}
+
+// serveHotlinkWatermark 输出盗链水印图，无法添加水印的文件不返回原文件
+func serveHotlinkWatermark(c *gin.Context, fileInfo models.File, opts transform.Options) {
+	resp, err := filesvc.ServeHotlinkWatermarkedFile(fileInfo, c.GetString("watermark_config"), c.GetString("watermark_domain"), opts)
+	if err != nil {
+		logger.Warn("生成盗链水印图失败 [%s]: %v", fileInfo.ID, err)
+		assets.ServeDefaultFile(c, assets.FileTypeUnauthorized)
+		return
+	}
+	// 内容随来源页面变化，不允许共享缓存
+	c.Header("Vary", "Referer")
+	writeTransformedFile(c, resp, "private, max-age=3600")
+}
@@ -0,0 +1,113 @@
+package file
+
+// 近似重复（感知哈希）控制器
+
+import (
+	"pixelpunk/internal/controllers/file/dto"
+	"pixelpunk/internal/middleware"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetNearDuplicateGroups 获取当前用户的近似重复文件分组
+func GetNearDuplicateGroups(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	req, err := common.ValidateRequest[dto.NearDuplicateQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	groups, err := filesvc.ListNearDuplicateGroups(userID, req.Distance, req.Limit)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"groups": groups}, "获取成功")
+}
+
+// GetFileNearDuplicates 获取与指定文件视觉相同的文件
+func GetFileNearDuplicates(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	req, err := common.ValidateRequest[dto.NearDuplicateQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	items, err := filesvc.FindNearDuplicates(userID, c.Param("file_id"), req.Distance)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"items": items}, "获取成功")
+}
+
+// MergeNearDuplicates 合并近似重复文件（保留一个，删除其余）
+func MergeNearDuplicates(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	req, err := common.ValidateRequest[dto.MergeNearDuplicatesDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	merged, err := filesvc.MergeNearDuplicates(userID, req.KeepID, req.DuplicateIDs, req.Distance)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"keep_id": req.KeepID, "merged_ids": merged}, "合并成功")
+}
+
+// AdminGetNearDuplicateGroups 管理员获取近似重复文件分组（可按用户筛选）
+func AdminGetNearDuplicateGroups(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.NearDuplicateQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	groups, err := filesvc.ListNearDuplicateGroups(req.UserID, req.Distance, req.Limit)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"groups": groups}, "获取成功")
+}
+
+// AdminGetFileNearDuplicates 管理员跨用户查找视觉相同的文件
+func AdminGetFileNearDuplicates(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.NearDuplicateQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	items, err := filesvc.FindNearDuplicates(req.UserID, c.Param("file_id"), req.Distance)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"items": items}, "获取成功")
+}
+
+// AdminBackfillPerceptualHashes 为历史图片补算感知哈希
+func AdminBackfillPerceptualHashes(c *gin.Context) {
+	if !filesvc.StartPHashBackfill(0) {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "感知哈希补算任务正在运行"))
+		return
+	}
+	errors.ResponseSuccess(c, nil, "已开始后台补算感知哈希")
+}
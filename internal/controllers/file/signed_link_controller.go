@@ -0,0 +1,134 @@
+package file
+
+// 签名链接控制器
+
+import (
+	"pixelpunk/internal/controllers/file/dto"
+	"pixelpunk/internal/middleware"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func toSignedLinkOptions(req dto.CreateSignedLinkDTO) filesvc.SignedLinkOptions {
+	return filesvc.SignedLinkOptions{
+		ExpireMinutes:   req.ExpireMinutes,
+		MaxUses:         req.MaxUses,
+		AllowedIPs:      req.AllowedIPs,
+		AllowedReferers: req.AllowedReferers,
+		Native:          req.Native,
+		Note:            req.Note,
+	}
+}
+
+// CreateSignedLink 为单个文件生成签名链接
+func CreateSignedLink(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	fileID := c.Param("file_id")
+
+	req, err := common.ValidateRequest[dto.CreateSignedLinkDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	result, err := filesvc.CreateSignedLink(userID, fileID, toSignedLinkOptions(*req))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, result, "生成签名链接成功")
+}
+
+// BatchCreateSignedLinks 批量生成签名链接
+func BatchCreateSignedLinks(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	req, err := common.ValidateRequest[dto.BatchCreateSignedLinkDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	results, err := filesvc.CreateSignedLinks(userID, req.FileIDs, toSignedLinkOptions(req.CreateSignedLinkDTO))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"items": results}, "批量生成签名链接成功")
+}
+
+// ListSignedLinks 获取当前用户的签名链接
+func ListSignedLinks(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	req, err := common.ValidateRequest[dto.SignedLinkListQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	page, size := req.Page, req.Size
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 20
+	}
+
+	links, total, err := filesvc.ListSignedLinks(userID, req.FileID, page, size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{
+		"items": links,
+		"pagination": gin.H{
+			"total":        total,
+			"size":         size,
+			"current_page": page,
+			"last_page":    (total + int64(size) - 1) / int64(size),
+		},
+	}, "获取成功")
+}
+
+// RevokeSignedLinks 撤销当前用户的签名链接
+func RevokeSignedLinks(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	req, err := common.ValidateRequest[dto.RevokeSignedLinksDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	revoked, err := filesvc.RevokeSignedLinks(userID, req.LinkIDs)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"revoked": revoked}, "撤销成功")
+}
+
+// AdminRevokeSignedLinks 管理员撤销任意签名链接
+func AdminRevokeSignedLinks(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.RevokeSignedLinksDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	revoked, err := filesvc.RevokeSignedLinks(0, req.LinkIDs)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"revoked": revoked}, "撤销成功")
+}
@@ -0,0 +1,100 @@
+package file
+
+// 回收站控制器
+
+import (
+	"pixelpunk/internal/controllers/file/dto"
+	"pixelpunk/internal/middleware"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListTrash 获取当前用户的回收站条目
+func ListTrash(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	req, err := common.ValidateRequest[dto.TrashListQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	page, size := req.Page, req.Size
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 20
+	}
+
+	items, total, err := filesvc.ListTrash(userID, page, size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{
+		"items":          items,
+		"retention_days": filesvc.TrashRetentionDays(),
+		"pagination": gin.H{
+			"total":        total,
+			"size":         size,
+			"current_page": page,
+			"last_page":    (total + int64(size) - 1) / int64(size),
+		},
+	}, "获取成功")
+}
+
+// RestoreTrashItems 从回收站恢复文件或文件夹
+func RestoreTrashItems(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	req, err := common.ValidateRequest[dto.TrashItemsDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	restored, err := filesvc.RestoreTrashItems(userID, req.IDs)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"restored_ids": restored}, "恢复成功")
+}
+
+// PurgeTrashItems 彻底删除回收站中的条目
+func PurgeTrashItems(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	req, err := common.ValidateRequest[dto.TrashItemsDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	purged, err := filesvc.PurgeTrashItems(userID, req.IDs)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"purged": purged}, "删除成功")
+}
+
+// EmptyTrash 清空当前用户的回收站
+func EmptyTrash(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	purged, err := filesvc.EmptyTrash(userID)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"purged": purged}, "回收站已清空")
+}
@@ -0,0 +1,166 @@
+package file
+
+// 水印策略控制器
+
+import (
+	"strconv"
+
+	"pixelpunk/internal/controllers/file/dto"
+	"pixelpunk/internal/middleware"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/watermark"
+
+	"github.com/gin-gonic/gin"
+)
+
+func parsePolicyID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("policy_id"), 10, 64)
+	if err != nil || id == 0 {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "无效的策略ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// GetWatermarkFonts 获取可用的内置字体
+func GetWatermarkFonts(c *gin.Context) {
+	errors.ResponseSuccess(c, watermark.BuiltinFontFamilies(), "获取成功")
+}
+
+// CreateWatermarkPolicy 创建水印策略
+func CreateWatermarkPolicy(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	req, err := common.ValidateRequest[dto.CreateWatermarkPolicyDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	policy, err := filesvc.CreateWatermarkPolicy(userID, filesvc.WatermarkPolicyInput{
+		Name:      req.Name,
+		ScopeType: req.ScopeType,
+		ScopeID:   req.ScopeID,
+		Config:    &req.Config,
+		Enabled:   req.Enabled,
+	})
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, policy, "创建水印策略成功")
+}
+
+// ListWatermarkPolicies 获取当前用户的水印策略
+func ListWatermarkPolicies(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	req, err := common.ValidateRequest[dto.WatermarkPolicyListQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	page, size := req.Page, req.Size
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 20
+	}
+
+	policies, total, err := filesvc.ListWatermarkPolicies(userID, page, size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{
+		"items": policies,
+		"pagination": gin.H{
+			"total":        total,
+			"size":         size,
+			"current_page": page,
+			"last_page":    (total + int64(size) - 1) / int64(size),
+		},
+	}, "获取成功")
+}
+
+// GetWatermarkPolicy 获取单条水印策略
+func GetWatermarkPolicy(c *gin.Context) {
+	id, ok := parsePolicyID(c)
+	if !ok {
+		return
+	}
+
+	policy, err := filesvc.GetWatermarkPolicy(middleware.GetCurrentUserID(c), id)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, policy, "获取成功")
+}
+
+// UpdateWatermarkPolicy 更新水印策略
+func UpdateWatermarkPolicy(c *gin.Context) {
+	id, ok := parsePolicyID(c)
+	if !ok {
+		return
+	}
+
+	req, err := common.ValidateRequest[dto.UpdateWatermarkPolicyDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	policy, err := filesvc.UpdateWatermarkPolicy(middleware.GetCurrentUserID(c), id, filesvc.WatermarkPolicyInput{
+		Name:      req.Name,
+		ScopeType: req.ScopeType,
+		ScopeID:   req.ScopeID,
+		Config:    req.Config,
+		Enabled:   req.Enabled,
+	})
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, policy, "更新水印策略成功")
+}
+
+// DeleteWatermarkPolicy 删除水印策略
+func DeleteWatermarkPolicy(c *gin.Context) {
+	id, ok := parsePolicyID(c)
+	if !ok {
+		return
+	}
+
+	if err := filesvc.DeleteWatermarkPolicy(middleware.GetCurrentUserID(c), id); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "删除水印策略成功")
+}
+
+// PreviewWatermark 按配置预览文件的水印效果，直接返回图片
+func PreviewWatermark(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.PreviewWatermarkDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	resp, err := filesvc.PreviewWatermark(middleware.GetCurrentUserID(c), req.FileID, req.Config)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	writeTransformedFile(c, resp, "no-store")
+}
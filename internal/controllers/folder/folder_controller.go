@@ -4,6 +4,7 @@ import (
 	"pixelpunk/internal/controllers/folder/dto"
 	"pixelpunk/internal/middleware"
 	"pixelpunk/internal/services/activity"
+	filesvc "pixelpunk/internal/services/file"
 	"pixelpunk/internal/services/folder"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/errors"
@@ -106,7 +107,12 @@ func DeleteFolder(c *gin.Context) {
 		return
 	}
 
-	err = folder.DeleteFolder(userID, folderID)
+	// 工作区文件夹不进入个人回收站
+	if filesvc.TrashEnabled() && folderInfo.WorkspaceID == "" {
+		err = filesvc.MoveFolderToTrash(userID, folderID)
+	} else {
+		err = folder.DeleteFolder(userID, folderID)
+	}
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -3,13 +3,13 @@ package oauth
 import (
 	"fmt"
 	"pixelpunk/internal/controllers/setting/dto"
+	"pixelpunk/internal/middleware"
 	"pixelpunk/internal/models"
-	"pixelpunk/internal/services/auth"
 	oauthService "pixelpunk/internal/services/oauth"
 	"pixelpunk/internal/services/setting"
+	userService "pixelpunk/internal/services/user"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/errors"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,55 +25,38 @@ func handleOAuthLogin(c *gin.Context, code string, provider string, handler OAut
 		return
 	}
 
-	securitySettings, err := setting.GetSettingsByGroupAsMap("security")
-	if err != nil {
-		errors.HandleError(c, errors.New(errors.CodeInternal, "安全配置读取失败"))
-		return
-	}
+	completeOAuthLogin(c, user)
+}
 
-	jwtSecret := ""
-	if val, ok := securitySettings.Settings["jwt_secret"]; ok {
-		if secretStr, ok := val.(string); ok {
-			jwtSecret = secretStr
-		}
-	}
-	if strings.TrimSpace(jwtSecret) == "" {
-		errors.HandleError(c, errors.New(errors.CodeInternal, "JWT 密钥未配置"))
+// completeOAuthLogin 第三方身份确认后的登录收尾：状态检查、两步验证挑战与签发token
+func completeOAuthLogin(c *gin.Context, user *models.User) {
+	if !user.IsNormal() {
+		errors.HandleError(c, errors.New(errors.CodeUserDisabled, "账号已被禁用"))
 		return
 	}
 
-	expiresHours := 0
-	if val, ok := securitySettings.Settings["login_expire_hours"]; ok {
-		if hours, ok := val.(float64); ok && hours > 0 {
-			expiresHours = int(hours)
-		}
+	challenge, err := userService.BeginTwoFactorChallenge(user)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
 	}
-	if expiresHours <= 0 {
-		errors.HandleError(c, errors.New(errors.CodeInternal, "登录过期时间配置错误"))
+	if challenge != nil {
+		errors.ResponseSuccess(c, gin.H{"requires_two_factor": true, "challenge": challenge}, "请完成两步验证")
 		return
 	}
 
-	token, err := auth.GenerateToken(user.ID, user.Username, int(user.Role), jwtSecret, expiresHours)
+	userInfo, tokens, err := userService.IssueLoginToken(user, middleware.GetSessionClient(c))
 	if err != nil {
-		errors.HandleError(c, errors.New(errors.CodeInternal, "生成登录凭证失败"))
+		errors.HandleError(c, err)
 		return
 	}
 
-	userInfo := map[string]interface{}{
-		"id":       user.ID,
-		"username": user.Username,
-		"email":    user.Email,
-		"avatar":   user.Avatar,
-		"bio":      user.Bio,
-		"website":  user.Website,
-		"role":     user.Role,
-		"status":   user.Status,
-	}
-
 	data := gin.H{
-		"token":    token,
-		"userInfo": userInfo,
-		"email":    user.Email,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"userInfo":      userInfo,
+		"email":         user.Email,
 	}
 
 	errors.ResponseSuccess(c, data, "登录成功")
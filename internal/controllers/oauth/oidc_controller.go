@@ -0,0 +1,81 @@
+package oauth
+
+import (
+	"fmt"
+	"pixelpunk/internal/controllers/setting/dto"
+	oauthService "pixelpunk/internal/services/oauth"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OIDCAuthorize 生成通用 OIDC 提供方的授权地址（含 PKCE 与 state）
+func OIDCAuthorize(c *gin.Context) {
+	service, err := newOIDCService(c.Param("provider"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	authURL, state, err := service.AuthorizationURL()
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInternal, fmt.Sprintf("%s 授权地址生成失败: %v", service.Config.Name, err)))
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{
+		"authorization_url": authURL,
+		"state":             state,
+	}, "获取成功")
+}
+
+// OIDCLogin 通用 OIDC 提供方回调登录
+func OIDCLogin(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.OIDCLoginDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	service, err := newOIDCService(c.Param("provider"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	user, err := service.Login(req.Code, req.State)
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInternal, fmt.Sprintf("%s 登录失败: %v", service.Config.Name, err)))
+		return
+	}
+
+	completeOAuthLogin(c, user)
+}
+
+func newOIDCService(slug string) (*oauthService.OIDCService, error) {
+	config, ok := setting.GetOIDCProviderConfig(slug)
+	if !ok {
+		return nil, errors.New(errors.CodeNotFound, "登录方式不存在或未启用")
+	}
+	if config.ClientID == "" || config.RedirectURI == "" {
+		return nil, errors.New(errors.CodeInternal, fmt.Sprintf("%s OAuth 配置不完整", config.Name))
+	}
+
+	var proxyConfig *oauthService.ProxyConfig
+	if config.ProxyEnabled {
+		proxyConfig = &oauthService.ProxyConfig{
+			Enabled:  config.ProxyEnabled,
+			Dynamic:  config.ProxyDynamic,
+			APIURL:   config.ProxyAPIURL,
+			Type:     config.ProxyType,
+			Host:     config.ProxyHost,
+			Port:     config.ProxyPort,
+			Username: config.ProxyUsername,
+			Password: config.ProxyPassword,
+		}
+	}
+
+	return oauthService.NewOIDCService(config, proxyConfig), nil
+}
@@ -0,0 +1,48 @@
+package dto
+
+// CreateRoleDTO 创建角色DTO
+type CreateRoleDTO struct {
+	Name        string   `json:"name" binding:"required,max=50"` // 小写字母、数字、下划线或连字符
+	DisplayName string   `json:"display_name" binding:"omitempty,max=100"`
+	Description string   `json:"description" binding:"omitempty,max=500"`
+	Permissions []string `json:"permissions" binding:"required,min=1"`
+}
+
+func (d *CreateRoleDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Name.required":        "角色标识不能为空",
+		"Name.max":             "角色标识不能超过50个字符",
+		"Name.alphanumunicode": "角色标识只能包含字母和数字",
+		"DisplayName.max":      "角色名称不能超过100个字符",
+		"Description.max":      "角色描述不能超过500个字符",
+		"Permissions.required": "请至少选择一项权限",
+		"Permissions.min":      "请至少选择一项权限",
+	}
+}
+
+// UpdateRoleDTO 修改角色DTO，未传字段保持不变
+type UpdateRoleDTO struct {
+	DisplayName *string  `json:"display_name" binding:"omitempty,max=100"`
+	Description *string  `json:"description" binding:"omitempty,max=500"`
+	Permissions []string `json:"permissions" binding:"omitempty,min=1"`
+}
+
+func (d *UpdateRoleDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"DisplayName.max": "角色名称不能超过100个字符",
+		"Description.max": "角色描述不能超过500个字符",
+		"Permissions.min": "请至少选择一项权限",
+	}
+}
+
+// AssignRoleDTO 分配角色DTO
+type AssignRoleDTO struct {
+	UserID uint `json:"user_id" binding:"required"`
+	RoleID uint `json:"role_id"` // 0 表示取消管理权限
+}
+
+func (d *AssignRoleDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"UserID.required": "请选择用户",
+	}
+}
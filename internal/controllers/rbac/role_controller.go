@@ -0,0 +1,129 @@
+package rbac
+
+// 后台角色权限控制器
+
+import (
+	"strconv"
+
+	"pixelpunk/internal/controllers/rbac/dto"
+	"pixelpunk/internal/middleware"
+	rbacsvc "pixelpunk/internal/services/rbac"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func parseRoleID(c *gin.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("role_id"), 10, 32)
+	if err != nil {
+		return 0, errors.New(errors.CodeInvalidParameter, "角色ID格式不正确")
+	}
+	return uint(id), nil
+}
+
+// ListPermissions 获取全部可分配的权限
+func ListPermissions(c *gin.Context) {
+	errors.ResponseSuccess(c, rbacsvc.Permissions(), "获取成功")
+}
+
+// GetMyPermissions 获取当前用户的后台权限，用于前端菜单展示
+func GetMyPermissions(c *gin.Context) {
+	perms, err := rbacsvc.UserPermissions(middleware.GetCurrentUserID(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, perms, "获取成功")
+}
+
+// ListRoles 获取角色列表
+func ListRoles(c *gin.Context) {
+	roles, err := rbacsvc.ListRoles()
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, roles, "获取成功")
+}
+
+// CreateRole 创建自定义角色
+func CreateRole(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.CreateRoleDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	role, err := rbacsvc.CreateRole(middleware.GetCurrentUserID(c), req.Name, rbacsvc.RoleInput{
+		DisplayName: &req.DisplayName,
+		Description: &req.Description,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, role, "创建角色成功")
+}
+
+// UpdateRole 修改自定义角色
+func UpdateRole(c *gin.Context) {
+	roleID, err := parseRoleID(c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	req, err := common.ValidateRequest[dto.UpdateRoleDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	role, err := rbacsvc.UpdateRole(middleware.GetCurrentUserID(c), roleID, rbacsvc.RoleInput{
+		DisplayName: req.DisplayName,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, role, "更新角色成功")
+}
+
+// DeleteRole 删除自定义角色
+func DeleteRole(c *gin.Context) {
+	roleID, err := parseRoleID(c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	if err := rbacsvc.DeleteRole(roleID); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "删除角色成功")
+}
+
+// AssignRole 为用户分配角色
+func AssignRole(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.AssignRoleDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	if err := rbacsvc.AssignRole(middleware.GetCurrentUserID(c), req.UserID, req.RoleID); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "分配角色成功")
+}
@@ -0,0 +1,418 @@
+package s3gateway
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+	filesvc "pixelpunk/internal/services/file"
+	s3svc "pixelpunk/internal/services/s3gateway"
+	"pixelpunk/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+/* Handle S3 兼容接口统一入口（路径风格：/s3/{bucket}/{key}），按方法与查询参数分发 */
+func Handle(c *gin.Context) {
+	if !s3svc.Enabled() {
+		writeError(c, s3svc.ErrServiceDisabled)
+		return
+	}
+	id, err := s3svc.Authenticate(c.Request)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	bucketName, key := splitBucketKey(c.Param("path"))
+	if err := id.Require(requiredPermission(c, key)); err != nil {
+		writeError(c, err)
+		return
+	}
+	if bucketName == "" {
+		if c.Request.Method != http.MethodGet {
+			writeError(c, s3svc.ErrNotImplemented)
+			return
+		}
+		listBuckets(c, id)
+		return
+	}
+
+	bucket, err := id.ResolveBucket(bucketName)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	if key == "" {
+		handleBucket(c, id, bucket)
+		return
+	}
+	handleObject(c, id, bucket, key)
+}
+
+// requiredPermission 请求所需的密钥 S3 权限：删除对象需要 delete，上传与分片上传需要 write，其余为 read
+func requiredPermission(c *gin.Context, key string) string {
+	switch c.Request.Method {
+	case http.MethodDelete:
+		if c.Query("uploadId") != "" {
+			return models.APIKeyS3Write
+		}
+		return models.APIKeyS3Delete
+	case http.MethodPut, http.MethodPost:
+		if key == "" {
+			// 创建存储桶只是确认映射关系
+			return models.APIKeyS3Read
+		}
+		return models.APIKeyS3Write
+	}
+	return models.APIKeyS3Read
+}
+
+func splitBucketKey(path string) (string, string) {
+	path = strings.TrimPrefix(path, "/")
+	bucket, key, _ := strings.Cut(path, "/")
+	return bucket, key
+}
+
+func handleBucket(c *gin.Context, id *s3svc.Identity, bucket *s3svc.Bucket) {
+	q := c.Request.URL.Query()
+	switch c.Request.Method {
+	case http.MethodHead:
+		c.Status(http.StatusOK)
+	case http.MethodPut:
+		// 存储桶由用户与API密钥映射，已存在的桶视为创建成功
+		c.Header("Location", "/"+bucket.Name)
+		c.Status(http.StatusOK)
+	case http.MethodGet:
+		switch {
+		case q.Has("location"):
+			writeXML(c, http.StatusOK, locationConstraint{})
+		case q.Get("list-type") == "2":
+			listObjects(c, bucket, true)
+		case len(q) == 0 || q.Has("prefix") || q.Has("delimiter") || q.Has("marker") || q.Has("max-keys") || q.Has("encoding-type"):
+			listObjects(c, bucket, false)
+		default:
+			writeError(c, s3svc.ErrNotImplemented)
+		}
+	default:
+		writeError(c, s3svc.ErrNotImplemented)
+	}
+}
+
+func handleObject(c *gin.Context, id *s3svc.Identity, bucket *s3svc.Bucket, key string) {
+	q := c.Request.URL.Query()
+	uploadID := q.Get("uploadId")
+	switch c.Request.Method {
+	case http.MethodGet:
+		if uploadID != "" {
+			listParts(c, bucket, key, uploadID)
+			return
+		}
+		getObject(c, bucket, key, false)
+	case http.MethodHead:
+		getObject(c, bucket, key, true)
+	case http.MethodPut:
+		if c.GetHeader("X-Amz-Copy-Source") != "" {
+			writeError(c, s3svc.ErrNotImplemented.WithMessage("暂不支持 CopyObject"))
+			return
+		}
+		if uploadID != "" {
+			uploadPart(c, id, bucket, key, uploadID)
+			return
+		}
+		putObject(c, id, bucket, key)
+	case http.MethodPost:
+		switch {
+		case q.Has("uploads"):
+			createMultipartUpload(c, id, bucket, key)
+		case uploadID != "":
+			completeMultipartUpload(c, id, bucket, key, uploadID)
+		default:
+			writeError(c, s3svc.ErrNotImplemented)
+		}
+	case http.MethodDelete:
+		if uploadID != "" {
+			if err := s3svc.AbortMultipartUpload(bucket, key, uploadID); err != nil {
+				writeError(c, err)
+				return
+			}
+			c.Status(http.StatusNoContent)
+			return
+		}
+		if err := s3svc.DeleteObject(bucket, key); err != nil {
+			writeError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	default:
+		writeError(c, s3svc.ErrNotImplemented)
+	}
+}
+
+func listBuckets(c *gin.Context, id *s3svc.Identity) {
+	buckets, err := id.ListBuckets()
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := listAllMyBucketsResult{Owner: s3Owner{ID: strconv.FormatUint(uint64(id.Key.UserID), 10), DisplayName: id.Key.Name}}
+	for _, b := range buckets {
+		resp.Buckets = append(resp.Buckets, s3Bucket{Name: b.Name, CreationDate: b.CreatedAt.UTC().Format(s3TimeFormat)})
+	}
+	writeXML(c, http.StatusOK, resp)
+}
+
+func listObjects(c *gin.Context, bucket *s3svc.Bucket, v2 bool) {
+	q := c.Request.URL.Query()
+	maxKeys := 1000
+	if v := q.Get("max-keys"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(c, s3svc.ErrInvalidArgument.WithMessage("无效的 max-keys"))
+			return
+		}
+		maxKeys = n
+	}
+	opts := s3svc.ListOptions{Prefix: q.Get("prefix"), Delimiter: q.Get("delimiter"), MaxKeys: maxKeys}
+	if v2 {
+		opts.StartAfter = q.Get("start-after")
+		opts.ContinuationToken = q.Get("continuation-token")
+	} else {
+		opts.StartAfter = q.Get("marker")
+	}
+
+	resp := listBucketResult{Name: bucket.Name, MaxKeys: maxKeys}
+	encode := func(s string) string { return s }
+	if q.Get("encoding-type") == "url" {
+		resp.EncodingType = "url"
+		encode = url.PathEscape
+	}
+	resp.Prefix = encode(opts.Prefix)
+	resp.Delimiter = encode(opts.Delimiter)
+
+	var result *s3svc.ListResult
+	if maxKeys == 0 {
+		result = &s3svc.ListResult{}
+	} else {
+		var err error
+		if result, err = s3svc.ListObjects(bucket, opts); err != nil {
+			writeError(c, err)
+			return
+		}
+	}
+
+	resp.IsTruncated = result.IsTruncated
+	for _, o := range result.Objects {
+		resp.Contents = append(resp.Contents, s3Object{
+			Key:          encode(o.Key),
+			LastModified: o.LastModified.UTC().Format(s3TimeFormat),
+			ETag:         `"` + o.ETag + `"`,
+			Size:         o.Size,
+			StorageClass: "STANDARD",
+		})
+	}
+	for _, p := range result.CommonPrefixes {
+		resp.CommonPrefixes = append(resp.CommonPrefixes, s3CommonPrefix{Prefix: encode(p)})
+	}
+	if v2 {
+		keyCount := len(resp.Contents) + len(resp.CommonPrefixes)
+		resp.KeyCount = &keyCount
+		resp.StartAfter = encode(opts.StartAfter)
+		resp.ContinuationToken = opts.ContinuationToken
+		resp.NextContinuationToken = result.NextContinuationToken
+	} else {
+		marker := encode(opts.StartAfter)
+		resp.Marker = &marker
+		if result.IsTruncated && opts.Delimiter != "" {
+			resp.NextMarker = encode(result.NextMarker)
+		}
+	}
+	writeXML(c, http.StatusOK, resp)
+}
+
+func getObject(c *gin.Context, bucket *s3svc.Bucket, key string, headOnly bool) {
+	file, err := s3svc.HeadObject(bucket, key)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	content, err := s3svc.OpenObject(file)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	defer content.Content.Close()
+
+	setObjectHeaders(c, file)
+	modTime := time.Time(file.UpdatedAt)
+	if headOnly {
+		size := content.ContentLength
+		if size < 0 {
+			// 渠道无法查询对象大小时只能读完内容统计实际字节数
+			if size, err = io.Copy(io.Discard, content.Content); err != nil {
+				writeError(c, err)
+				return
+			}
+		}
+		c.Header("Content-Length", strconv.FormatInt(size, 10))
+		c.Header("Accept-Ranges", "bytes")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	if content.OpenRange != nil {
+		rs := filesvc.NewRangeSeeker(content.OpenRange, content.ContentLength, c.GetHeader("Range"))
+		defer rs.Close()
+		http.ServeContent(c.Writer, c.Request, "", modTime, rs)
+		return
+	}
+	if rs, ok := content.Content.(io.ReadSeeker); ok {
+		http.ServeContent(c.Writer, c.Request, "", modTime, rs)
+		return
+	}
+	// 顺序流无法定位，忽略 Range 直接返回完整内容
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, content.Content); err != nil {
+		logger.Warn("S3对象下载中断 %s: %v", key, err)
+	}
+}
+
+func setObjectHeaders(c *gin.Context, file *models.File) {
+	contentType := file.MimeType
+	if contentType == "" {
+		contentType = filesvc.GetContentTypeByFormat(file.Format)
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("ETag", `"`+file.MD5Hash+`"`)
+	c.Header("Last-Modified", time.Time(file.UpdatedAt).UTC().Format(http.TimeFormat))
+}
+
+func putObject(c *gin.Context, id *s3svc.Identity, bucket *s3svc.Bucket, key string) {
+	body, err := id.PayloadReader(c.Request)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	file, err := id.PutObject(bucket, key, c.GetHeader("Content-Type"), accessLevelFromACL(c.GetHeader("X-Amz-Acl")), body)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	if file != nil {
+		c.Header("ETag", `"`+file.MD5Hash+`"`)
+	}
+	c.Status(http.StatusOK)
+}
+
+// accessLevelFromACL 将 canned ACL 映射为文件访问级别，未指定时沿用 API 上传的默认级别
+func accessLevelFromACL(acl string) string {
+	switch acl {
+	case "private":
+		return "private"
+	case "public-read", "public-read-write":
+		return "public"
+	}
+	return ""
+}
+
+func createMultipartUpload(c *gin.Context, id *s3svc.Identity, bucket *s3svc.Bucket, key string) {
+	upload, err := id.CreateMultipartUpload(bucket, key, c.GetHeader("Content-Type"), accessLevelFromACL(c.GetHeader("X-Amz-Acl")))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	writeXML(c, http.StatusOK, initiateMultipartUploadResult{Bucket: bucket.Name, Key: key, UploadID: upload.ID})
+}
+
+func uploadPart(c *gin.Context, id *s3svc.Identity, bucket *s3svc.Bucket, key, uploadID string) {
+	partNumber, err := strconv.Atoi(c.Query("partNumber"))
+	if err != nil {
+		writeError(c, s3svc.ErrInvalidArgument.WithMessage("无效的 partNumber"))
+		return
+	}
+	body, err := id.PayloadReader(c.Request)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	etag, err := s3svc.UploadPart(bucket, key, uploadID, partNumber, body)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Header("ETag", `"`+etag+`"`)
+	c.Status(http.StatusOK)
+}
+
+func completeMultipartUpload(c *gin.Context, id *s3svc.Identity, bucket *s3svc.Bucket, key, uploadID string) {
+	body, err := id.PayloadReader(c.Request)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	var req completeMultipartUploadRequest
+	if err := xml.NewDecoder(io.LimitReader(body, 1<<20)).Decode(&req); err != nil {
+		writeError(c, s3svc.ErrMalformedXML)
+		return
+	}
+	parts := make([]s3svc.CompletedPart, 0, len(req.Parts))
+	for _, p := range req.Parts {
+		parts = append(parts, s3svc.CompletedPart{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+	_, etag, err := id.CompleteMultipartUpload(bucket, key, uploadID, parts)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	writeXML(c, http.StatusOK, completeMultipartUploadResult{
+		Location: "/s3/" + bucket.Name + "/" + key,
+		Bucket:   bucket.Name,
+		Key:      key,
+		ETag:     `"` + etag + `"`,
+	})
+}
+
+func listParts(c *gin.Context, bucket *s3svc.Bucket, key, uploadID string) {
+	parts, err := s3svc.ListParts(bucket, key, uploadID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	resp := listPartsResult{Bucket: bucket.Name, Key: key, UploadID: uploadID, MaxParts: 10000}
+	for _, p := range parts {
+		resp.Parts = append(resp.Parts, s3Part{
+			PartNumber:   p.PartNumber,
+			LastModified: time.Time(p.UpdatedAt).UTC().Format(s3TimeFormat),
+			ETag:         `"` + p.ETag + `"`,
+			Size:         p.Size,
+		})
+	}
+	writeXML(c, http.StatusOK, resp)
+}
+
+func writeXML(c *gin.Context, status int, v interface{}) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Data(status, "application/xml", append([]byte(xml.Header), data...))
+}
+
+func writeError(c *gin.Context, err error) {
+	e := s3svc.ToError(err)
+	if e.Status >= http.StatusInternalServerError {
+		logger.Error("S3接口处理失败 %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+	}
+	if c.Request.Method == http.MethodHead {
+		c.Status(e.Status)
+		return
+	}
+	data, _ := xml.Marshal(s3Error{Code: e.Code, Message: e.Message, Resource: c.Request.URL.Path})
+	c.Data(e.Status, "application/xml", append([]byte(xml.Header), data...))
+}
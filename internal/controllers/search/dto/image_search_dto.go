@@ -0,0 +1,22 @@
+package dto
+
+/* ImageSearchRequest 以图搜图请求，图片可通过 multipart 的 file 字段上传、image 字段粘贴（base64/data URI）或 url 指定 */
+type ImageSearchRequest struct {
+	URL         string `json:"url" form:"url" binding:"omitempty,url,max=2048"`                  // 远程图片地址
+	Image       string `json:"image" form:"image"`                                               // base64 或 data URI
+	Scope       string `json:"scope" form:"scope" binding:"omitempty,oneof=mine accessible"`     // mine: 仅自己的文件，accessible: 含他人公开文件
+	Limit       int    `json:"limit" form:"limit" binding:"omitempty,min=1,max=50"`              // 返回数量
+	MaxDistance int    `json:"max_distance" form:"max_distance" binding:"omitempty,min=1,max=7"` // 感知哈希最大汉明距离
+}
+
+func (r *ImageSearchRequest) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"URL.url":         "图片地址格式错误",
+		"URL.max":         "图片地址过长",
+		"Scope.oneof":     "搜索范围只能是 mine 或 accessible",
+		"Limit.min":       "返回数量不能小于1",
+		"Limit.max":       "返回数量不能超过50",
+		"MaxDistance.min": "汉明距离不能小于1",
+		"MaxDistance.max": "汉明距离不能超过7",
+	}
+}
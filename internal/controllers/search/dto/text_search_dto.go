@@ -0,0 +1,42 @@
+package dto
+
+/* TextSearchRequest 全文检索请求，关键词为空时仅按分面筛选 */
+type TextSearchRequest struct {
+	Query          string   `json:"query" binding:"omitempty,max=200"`                  // 搜索关键词
+	Formats        []string `json:"formats" binding:"omitempty,max=20"`                 // 文件格式
+	Resolutions    []string `json:"resolutions" binding:"omitempty,max=20"`             // 分辨率等级
+	DominantColors []string `json:"dominant_colors" binding:"omitempty,max=20"`         // 主色调
+	CameraModels   []string `json:"camera_models" binding:"omitempty,max=20"`           // 相机型号
+	Tags           []string `json:"tags" binding:"omitempty,max=20"`                    // 标签名称，需全部命中
+	CategoryIDs    []uint   `json:"category_ids" binding:"omitempty,max=20"`            // 分类ID
+	FolderID       string   `json:"folder_id" binding:"omitempty,max=32"`               // 文件夹ID，包含子文件夹
+	AccessLevels   []string `json:"access_levels" binding:"omitempty,max=3"`            // 访问级别
+	NSFW           *bool    `json:"nsfw"`                                               // 是否NSFW，不传则不限
+	TakenFrom      string   `json:"taken_from" binding:"omitempty,datetime=2006-01-02"` // 拍摄日期起（含）
+	TakenTo        string   `json:"taken_to" binding:"omitempty,datetime=2006-01-02"`   // 拍摄日期止（含）
+	WithVector     bool     `json:"with_vector"`                                        // 向量搜索可用时融合语义相似度
+	VectorWeight   float64  `json:"vector_weight" binding:"omitempty,min=0,max=1"`      // 向量得分所占比例
+	Page           int      `json:"page" binding:"omitempty,min=1"`                     // 页码
+	Size           int      `json:"size" binding:"omitempty,min=1,max=100"`             // 每页数量
+}
+
+func (r *TextSearchRequest) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Query.max":          "搜索关键词不能超过200个字符",
+		"Formats.max":        "文件格式最多选择20个",
+		"Resolutions.max":    "分辨率最多选择20个",
+		"DominantColors.max": "主色调最多选择20个",
+		"CameraModels.max":   "相机型号最多选择20个",
+		"Tags.max":           "标签最多选择20个",
+		"CategoryIDs.max":    "分类最多选择20个",
+		"FolderID.max":       "文件夹ID格式错误",
+		"AccessLevels.max":   "访问级别最多选择3个",
+		"TakenFrom.datetime": "拍摄起始日期格式应为 YYYY-MM-DD",
+		"TakenTo.datetime":   "拍摄截止日期格式应为 YYYY-MM-DD",
+		"VectorWeight.min":   "向量得分比例不能小于0",
+		"VectorWeight.max":   "向量得分比例不能大于1",
+		"Page.min":           "页码必须大于等于1",
+		"Size.min":           "每页数量不能小于1",
+		"Size.max":           "每页数量不能超过100",
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/vector"
 
 	"github.com/gin-gonic/gin"
 )
@@ -70,6 +71,20 @@ func RegenerateVectors(c *gin.Context) {
 		return
 	}
 
+	// 旧版单向量集合需要重建为命名向量结构才能保存图像向量
+	if c.Query("recreate_collection") == "true" {
+		engine := vector.GetGlobalVectorEngine()
+		if engine == nil || !engine.IsEnabled() {
+			errors.HandleError(c, errors.New(errors.CodeServiceUnavailable, "向量搜索功能不可用"))
+			return
+		}
+		if err := engine.RecreateCollection(); err != nil {
+			logger.Error("重建向量集合失败: %v", err)
+			errors.HandleError(c, errors.New(errors.CodeInternal, fmt.Sprintf("重建向量集合失败: %v", err)))
+			return
+		}
+	}
+
 	result := db.Model(&models.FileVector{}).
 		Where("file_id IN (SELECT i.id FROM file i JOIN file_ai_info ai ON i.id = ai.file_id WHERE ai.description IS NOT NULL AND ai.description != '')").
 		Updates(map[string]interface{}{
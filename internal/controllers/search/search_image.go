@@ -0,0 +1,97 @@
+package search
+
+import (
+	"io"
+	"pixelpunk/internal/controllers/search/dto"
+	"pixelpunk/internal/middleware"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/* UserImageSearch 以图搜图：上传、粘贴或指定URL的图片不会被保存，返回调用者可访问的视觉相同或语义相近的文件 */
+func UserImageSearch(c *gin.Context) {
+	startTime := time.Now()
+
+	userID := middleware.GetCurrentUserID(c)
+	if userID == 0 {
+		errors.HandleError(c, errors.New(errors.CodeUnauthorized, "用户未认证"))
+		return
+	}
+
+	req, err := common.ValidateRequest[dto.ImageSearchRequest](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	data, source, err := readSearchImage(c, req)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	result, err := filesvc.SearchByImage(userID, data, req.Scope, req.Limit, req.MaxDistance, getSearchThreshold())
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	scope := req.Scope
+	if scope == "" {
+		scope = filesvc.ImageSearchScopeMine
+	}
+	response := gin.H{
+		"items": result.Items,
+		"pagination": gin.H{
+			"total":        int64(len(result.Items)),
+			"size":         len(result.Items),
+			"current_page": 1,
+			"last_page":    1,
+		},
+		"search_info": gin.H{
+			"source":       source,
+			"scope":        scope,
+			"phash":        result.PHash,
+			"description":  result.Description,
+			"vector_used":  result.VectorUsed,
+			"process_time": time.Since(startTime).String(),
+		},
+	}
+
+	errors.ResponseSuccess(c, response, "以图搜图成功")
+}
+
+// readSearchImage 按 上传文件 > 粘贴数据 > URL 的优先级读取待搜索图片
+func readSearchImage(c *gin.Context, req *dto.ImageSearchRequest) ([]byte, string, error) {
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		if fileHeader.Size > filesvc.MaxImageSearchBytes {
+			return nil, "", errors.New(errors.CodeInvalidParameter, "图片过大")
+		}
+		f, err := fileHeader.Open()
+		if err != nil {
+			return nil, "", errors.Wrap(err, errors.CodeInvalidParameter, "读取上传图片失败")
+		}
+		defer f.Close()
+		data, err := io.ReadAll(io.LimitReader(f, filesvc.MaxImageSearchBytes))
+		if err != nil {
+			return nil, "", errors.Wrap(err, errors.CodeInvalidParameter, "读取上传图片失败")
+		}
+		return data, "upload", nil
+	}
+
+	if req.Image != "" {
+		data, err := filesvc.DecodeImageSearchData(req.Image)
+		return data, "paste", err
+	}
+
+	if req.URL != "" {
+		data, err := filesvc.FetchImageForSearch(req.URL)
+		return data, "url", err
+	}
+
+	return nil, "", errors.New(errors.CodeInvalidParameter, "请上传图片、粘贴图片数据或提供图片地址")
+}
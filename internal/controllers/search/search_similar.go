@@ -5,7 +5,7 @@ import (
 	"pixelpunk/internal/controllers/search/dto"
 	"pixelpunk/internal/middleware"
 	"pixelpunk/internal/models"
-	"pixelpunk/pkg/common"
+	"pixelpunk/internal/services/rbac"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 	"pixelpunk/pkg/logger"
@@ -342,9 +342,8 @@ func UserSimilarFiles(c *gin.Context) {
 func AdminSimilarFiles(c *gin.Context) {
 	startTime := time.Now()
 
-	userRole := middleware.GetCurrentUserRole(c)
-	if userRole != common.UserRoleAdmin && userRole != common.UserRoleSuperAdmin {
-		errors.HandleError(c, errors.New(errors.CodeForbidden, "需要管理员权限"))
+	if !middleware.CurrentUserHasPermission(c, rbac.PermFileManage) {
+		errors.HandleError(c, errors.New(errors.CodeForbidden, "需要文件管理权限"))
 		return
 	}
 
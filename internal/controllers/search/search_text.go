@@ -0,0 +1,135 @@
+package search
+
+import (
+	"pixelpunk/internal/controllers/search/dto"
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/models"
+	filesvc "pixelpunk/internal/services/file"
+	searchsvc "pixelpunk/internal/services/search"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type textSearchItem struct {
+	filesvc.FileDetailResponse
+	Score        float64 `json:"score"`
+	KeywordScore float64 `json:"keyword_score"`
+	VectorScore  float64 `json:"vector_score"`
+}
+
+/* UserTextSearch 用户文件全文检索，支持分面筛选，with_vector 时融合语义相似度 */
+func UserTextSearch(c *gin.Context) {
+	runUserSearch(c, false)
+}
+
+/* UserHybridSearch 用户文件混合检索：结构化筛选下推到向量库，关键词与向量得分融合排序 */
+func UserHybridSearch(c *gin.Context) {
+	runUserSearch(c, true)
+}
+
+func runUserSearch(c *gin.Context, hybrid bool) {
+	startTime := time.Now()
+
+	userID := middleware.GetCurrentUserID(c)
+	if userID == 0 {
+		errors.HandleError(c, errors.New(errors.CodeUnauthorized, "用户未认证"))
+		return
+	}
+
+	req, err := common.ValidateRequest[dto.TextSearchRequest](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	params := searchsvc.Params{
+		UserID:         userID,
+		Query:          req.Query,
+		Formats:        req.Formats,
+		Resolutions:    req.Resolutions,
+		DominantColors: req.DominantColors,
+		CameraModels:   req.CameraModels,
+		Tags:           req.Tags,
+		CategoryIDs:    req.CategoryIDs,
+		FolderID:       req.FolderID,
+		AccessLevels:   req.AccessLevels,
+		NSFW:           req.NSFW,
+		VectorWeight:   req.VectorWeight,
+		Page:           req.Page,
+		Size:           req.Size,
+	}
+	if req.TakenFrom != "" {
+		from, _ := time.ParseInLocation("2006-01-02", req.TakenFrom, time.Local)
+		params.TakenFrom = &from
+	}
+	if req.TakenTo != "" {
+		to, _ := time.ParseInLocation("2006-01-02", req.TakenTo, time.Local)
+		to = to.AddDate(0, 0, 1)
+		params.TakenTo = &to
+	}
+
+	var result *searchsvc.Result
+	vectorUsed := false
+	if hybrid || req.WithVector {
+		result, vectorUsed, err = searchsvc.HybridSearch(params, getSearchThreshold())
+	} else {
+		result, err = searchsvc.Search(params)
+	}
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	items := make([]textSearchItem, 0, len(result.Hits))
+	if len(result.Hits) > 0 {
+		ids := make([]string, len(result.Hits))
+		for i, h := range result.Hits {
+			ids[i] = h.FileID
+		}
+		var files []models.File
+		if err := database.DB.Where("id IN ?", ids).Find(&files).Error; err != nil {
+			errors.HandleError(c, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败"))
+			return
+		}
+		byID := make(map[string]models.File, len(files))
+		for _, f := range files {
+			byID[f.ID] = f
+		}
+		for _, h := range result.Hits {
+			file, ok := byID[h.FileID]
+			if !ok {
+				continue
+			}
+			aiInfo, _ := filesvc.GetFileAIInfo(file.ID)
+			items = append(items, textSearchItem{
+				FileDetailResponse: filesvc.BuildFileDetailResponse(file, 0, aiInfo),
+				Score:              h.Score,
+				KeywordScore:       h.KeywordScore,
+				VectorScore:        h.VectorScore,
+			})
+		}
+	}
+
+	data := gin.H{
+		"items": items,
+		"pagination": gin.H{
+			"total":        result.Total,
+			"size":         result.Size,
+			"current_page": result.Page,
+			"last_page":    (result.Total + int64(result.Size) - 1) / int64(result.Size),
+		},
+		"facets": result.Facets,
+		"search_info": gin.H{
+			"query":        req.Query,
+			"terms":        result.Terms,
+			"vector_used":  vectorUsed,
+			"process_time": time.Since(startTime).String(),
+		},
+	}
+
+	errors.ResponseSuccess(c, data, "检索成功")
+}
@@ -80,6 +80,8 @@ type OAuthProvidersDTO struct {
 	GithubEnabled  bool `json:"github_enabled"`
 	GoogleEnabled  bool `json:"google_enabled"`
 	LinuxdoEnabled bool `json:"linuxdo_enabled"`
+
+	OIDC []OIDCProviderPublicDTO `json:"oidc"`
 }
 
 type GlobalSettingsResponseDTO struct {
@@ -276,10 +278,64 @@ type LinuxdoOAuthConfig struct {
 	ProxyPassword string `json:"proxy_password"`
 }
 
+// OIDCProviderConfig 通用 OpenID Connect / OAuth2 提供方配置，存放在 oauth 组的 oidc_providers 数组中
+type OIDCProviderConfig struct {
+	Slug                  string         `json:"slug"`                   // 提供方标识，用于登录路由与身份关联
+	Name                  string         `json:"name"`                   // 登录按钮显示名称
+	Icon                  string         `json:"icon"`                   // 登录按钮图标地址
+	Enabled               bool           `json:"enabled"`                // 是否启用
+	Issuer                string         `json:"issuer"`                 // 签发方，校验 id_token 的 iss
+	DiscoveryURL          string         `json:"discovery_url"`          // 发现文档地址，留空时使用 issuer + /.well-known/openid-configuration
+	AuthorizationEndpoint string         `json:"authorization_endpoint"` // 无发现文档的 OAuth2 提供方手动指定
+	TokenEndpoint         string         `json:"token_endpoint"`
+	UserinfoEndpoint      string         `json:"userinfo_endpoint"`
+	ClientID              string         `json:"client_id"`
+	ClientSecret          string         `json:"client_secret"`
+	RedirectURI           string         `json:"redirect_uri"`
+	Scope                 string         `json:"scope"`
+	SubjectClaim          string         `json:"subject_claim"`  // 默认 sub
+	UsernameClaim         string         `json:"username_claim"` // 默认 preferred_username
+	EmailClaim            string         `json:"email_claim"`    // 默认 email
+	AvatarClaim           string         `json:"avatar_claim"`   // 默认 picture
+	GroupsClaim           string         `json:"groups_claim"`   // 默认 groups
+	RoleMapping           map[string]int `json:"role_mapping"`   // 组名 → 角色（2管理员 3普通用户），配置后每次登录同步
+	DefaultRole           int            `json:"default_role"`   // 未匹配任何组时的角色，默认普通用户
+	LinkByEmail           bool           `json:"link_by_email"`  // 首次登录时按已验证邮箱关联已有账号
+	ProxyEnabled          bool           `json:"proxy_enabled"`
+	ProxyDynamic          bool           `json:"proxy_dynamic"`
+	ProxyAPIURL           string         `json:"proxy_api_url"`
+	ProxyType             string         `json:"proxy_type"`
+	ProxyHost             string         `json:"proxy_host"`
+	ProxyPort             string         `json:"proxy_port"`
+	ProxyUsername         string         `json:"proxy_username"`
+	ProxyPassword         string         `json:"proxy_password"`
+}
+
 type OAuthConfigResponseDTO struct {
 	Github  GithubOAuthConfig  `json:"github"`
 	Google  GoogleOAuthConfig  `json:"google"`
 	Linuxdo LinuxdoOAuthConfig `json:"linuxdo"`
+
+	OIDC []OIDCProviderConfig `json:"oidc"`
+}
+
+// OIDCProviderPublicDTO 登录页展示的 OIDC 提供方
+type OIDCProviderPublicDTO struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+	Icon string `json:"icon"`
+}
+
+type OIDCLoginDTO struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+func (d *OIDCLoginDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Code.required":  "授权码不能为空",
+		"State.required": "state 不能为空",
+	}
 }
 
 type GithubOAuthLoginDTO struct {
@@ -9,6 +9,7 @@ import (
 	"pixelpunk/internal/controllers/setting/dto"
 	"pixelpunk/internal/controllers/websocket"
 	"pixelpunk/internal/cron"
+	"pixelpunk/internal/middleware"
 	"pixelpunk/internal/models"
 	ai "pixelpunk/internal/services/ai"
 	"pixelpunk/internal/services/auth"
@@ -164,23 +165,7 @@ func (s *SetupController) Install(c *gin.Context) {
 	installManager.SetInstallMode(false)
 	installManager.SetSystemInstalled(true)
 
-	jwtSecret := "pixelpunk_default_secret_key"
-	expiresHours := 168
-
-	if securitySettings, err := setting.GetSettingsByGroupAsMap("security"); err == nil {
-		if val, ok := securitySettings.Settings["jwt_secret"]; ok {
-			if secretStr, ok := val.(string); ok && secretStr != "" {
-				jwtSecret = secretStr
-			}
-		}
-		if val, ok := securitySettings.Settings["login_expire_hours"]; ok {
-			if hours, ok := val.(float64); ok && hours > 0 {
-				expiresHours = int(hours)
-			}
-		}
-	}
-
-	token, err := auth.GenerateToken(adminUser.ID, adminUser.Username, int(adminUser.Role), jwtSecret, expiresHours)
+	tokens, err := auth.CreateSession(adminUser, middleware.GetSessionClient(c))
 	if err != nil {
 		installSuccess = true
 		errors.ResponseSuccess(c, gin.H{"message": userMessage}, userMessage)
@@ -189,8 +174,10 @@ func (s *SetupController) Install(c *gin.Context) {
 
 	installSuccess = true
 	errors.ResponseSuccess(c, gin.H{
-		"message": userMessage,
-		"token":   token,
+		"message":       userMessage,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 		"user": gin.H{
 			"id":       adminUser.ID,
 			"username": adminUser.Username,
@@ -1,7 +1,7 @@
 package dto
 
 type ShareItemDTO struct {
-	ItemType string `json:"item_type" binding:"required,oneof=folder file"`
+	ItemType string `json:"item_type" binding:"required,oneof=folder file album"`
 	ItemID   string `json:"item_id" binding:"required"`
 }
 
@@ -11,11 +11,17 @@ type CreateShareDTO struct {
 	Password    string         `json:"password" binding:"omitempty,max=100"`
 	ExpiredDays int            `json:"expired_days" binding:"min=0"`
 	MaxViews    int            `json:"max_views" binding:"min=0"`
-	Items       []ShareItemDTO `json:"items" binding:"required,min=1,dive"`
+	Items       []ShareItemDTO `json:"items" binding:"omitempty,dive"` // 文件收集分享不需要分享项目
 
 	CollectVisitorInfo    bool `json:"collect_visitor_info"`
 	NotificationOnAccess  bool `json:"notification_on_access"`
 	NotificationThreshold int  `json:"notification_threshold" binding:"omitempty,min=1"`
+
+	ShareType            string `json:"share_type" binding:"omitempty,oneof=view upload"`
+	UploadFolderID       string `json:"upload_folder_id" binding:"omitempty,max=32"`
+	UploadMaxFiles       int    `json:"upload_max_files" binding:"min=0"`
+	UploadMaxFileSize    int    `json:"upload_max_file_size" binding:"min=0"`               // 单位MB
+	UploadAllowedFormats string `json:"upload_allowed_formats" binding:"omitempty,max=255"` // 逗号分隔，如 jpg,png
 }
 
 func (d *CreateShareDTO) GetValidationMessages() map[string]string {
@@ -24,12 +30,15 @@ func (d *CreateShareDTO) GetValidationMessages() map[string]string {
 		"Password.max":              "密码不能超过100个字符",
 		"ExpiredDays.min":           "过期天数不能为负数",
 		"MaxViews.min":              "最大访问次数不能为负数",
-		"Items.required":            "分享项目不能为空",
-		"Items.min":                 "至少需要分享一个项目",
 		"ItemType.required":         "项目类型不能为空",
-		"ItemType.oneof":            "项目类型必须是folder或file",
+		"ItemType.oneof":            "项目类型必须是folder、file或album",
 		"ItemID.required":           "项目ID不能为空",
 		"NotificationThreshold.min": "通知阈值必须大于0",
+		"ShareType.oneof":           "分享类型必须是view或upload",
+		"UploadFolderID.max":        "目标文件夹ID格式错误",
+		"UploadMaxFiles.min":        "最多接收文件数不能为负数",
+		"UploadMaxFileSize.min":     "单文件大小上限不能为负数",
+		"UploadAllowedFormats.max":  "允许的格式不能超过255个字符",
 	}
 }
 
@@ -93,3 +102,18 @@ func (d *VisitorQueryDTO) GetValidationMessages() map[string]string {
 		"Keyword.max": "关键字不能超过100个字符",
 	}
 }
+
+// ShareUploadDTO 文件收集分享的访客上传DTO，文件通过 file 字段提交
+type ShareUploadDTO struct {
+	AccessToken  string `form:"access_token"`
+	VisitorName  string `form:"visitor_name" binding:"omitempty,max=100"`
+	VisitorEmail string `form:"visitor_email" binding:"omitempty,email,max=100"`
+}
+
+func (d *ShareUploadDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"VisitorName.max":    "访客姓名不能超过100个字符",
+		"VisitorEmail.email": "邮箱格式不正确",
+		"VisitorEmail.max":   "邮箱不能超过100个字符",
+	}
+}
@@ -165,7 +165,7 @@ func ViewShare(c *gin.Context) {
 
 	folderID := c.Query("folder_id")
 
-	data, err := share.GetShareForView(shareKey, folderID)
+	data, err := share.GetShareForView(shareKey, folderID, c.Query("album_id"))
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -276,6 +276,55 @@ func SubmitVisitorInfo(c *gin.Context) {
 	errors.ResponseSuccess(c, nil, "提交访客信息成功")
 }
 
+// UploadToShare 访客向文件收集分享上传文件
+func UploadToShare(c *gin.Context) {
+	shareKey := c.Param("key")
+
+	req, err := common.ValidateRequest[dto.ShareUploadDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "文件上传失败: "+err.Error()))
+		return
+	}
+
+	fileInfo, err := share.UploadToShare(c, shareKey, req, file)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{
+		"id":            fileInfo.ID,
+		"original_name": fileInfo.OriginalName,
+		"size":          fileInfo.Size,
+	}, "上传成功")
+}
+
+// GetShareUploads 获取文件收集分享的上传记录
+func GetShareUploads(c *gin.Context) {
+	var query dto.VisitorQueryDTO
+	if err := c.ShouldBindQuery(&query); err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "请求参数错误: "+err.Error()))
+		return
+	}
+
+	uploads, total, err := share.GetShareUploads(c.Param("id"), middleware.GetCurrentUserID(c), query.Page, query.Size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{
+		"list":  uploads,
+		"total": total,
+	}, "获取上传记录成功")
+}
+
 func getShareURL(c *gin.Context, shareKey string) string {
 	baseUrl := utils.GetBaseUrl()
 	return baseUrl + "/share/" + shareKey
@@ -375,12 +424,22 @@ func DownloadSharedFile(c *gin.Context) {
 		return
 	}
 
-	result, isLocal, isProxy, err := filesvc.ServeFile(file, false)
+	// 命中水印策略时分享下载的也是水印图
+	watermarked, err := filesvc.ServeWatermarkedFile(file)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
 	}
 
+	var result interface{}
+	var isLocal, isProxy bool
+	if watermarked != nil {
+		result, isProxy = watermarked, true
+	} else if result, isLocal, isProxy, err = filesvc.ServeFile(file, false); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
 	go func() {
 		downloadLog := &models.FileDownloadLog{
 			UserID:    0, // 分享下载设置为0，表示游客下载
@@ -404,8 +463,12 @@ func DownloadSharedFile(c *gin.Context) {
 
 	c.Header("Content-Type", "application/octet-stream")
 	c.Header("Content-Disposition", utils.SetContentDispositionFilename(fileName))
-	c.Header("Content-Length", fmt.Sprintf("%d", file.Size))
-	c.Header("Accept-Ranges", "bytes")
+	if watermarked != nil {
+		c.Header("Content-Length", fmt.Sprintf("%d", watermarked.ContentLength))
+	} else {
+		c.Header("Content-Length", fmt.Sprintf("%d", file.Size))
+		c.Header("Accept-Ranges", "bytes")
+	}
 
 	switch {
 	case isLocal:
@@ -0,0 +1,16 @@
+package dto
+
+type SetReplicationPolicyDTO struct {
+	ScopeType  string   `json:"scope_type" binding:"required,oneof=file folder user"`
+	ScopeID    string   `json:"scope_id" binding:"required"`
+	ChannelIDs []string `json:"channel_ids" binding:"max=10"` // 为空时删除策略
+}
+
+func (d *SetReplicationPolicyDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"ScopeType.required": "策略作用域不能为空",
+		"ScopeType.oneof":    "策略作用域只能是 file、folder 或 user",
+		"ScopeID.required":   "作用域ID不能为空",
+		"ChannelIDs.max":     "副本渠道不能超过10个",
+	}
+}
@@ -0,0 +1,31 @@
+package dto
+
+type CreateStorageMigrationDTO struct {
+	SourceChannelID string `json:"source_channel_id" binding:"required"`
+	TargetChannelID string `json:"target_channel_id" binding:"required"`
+	DryRun          bool   `json:"dry_run"`                                         // 仅校验，不写入目标渠道
+	DeleteSource    bool   `json:"delete_source"`                                   // 校验通过后删除源文件
+	ThrottleMs      int    `json:"throttle_ms" binding:"omitempty,min=0,max=60000"` // 每个文件之间的间隔（毫秒）
+}
+
+func (d *CreateStorageMigrationDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"SourceChannelID.required": "源渠道不能为空",
+		"TargetChannelID.required": "目标渠道不能为空",
+		"ThrottleMs.min":           "迁移间隔不能为负数",
+		"ThrottleMs.max":           "迁移间隔不能超过60000毫秒",
+	}
+}
+
+type PageQueryDTO struct {
+	Page int `form:"page" binding:"omitempty,min=1"`
+	Size int `form:"size" binding:"omitempty,min=1,max=100"`
+}
+
+func (d *PageQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Page.min": "页码必须大于等于1",
+		"Size.min": "每页数量必须大于等于1",
+		"Size.max": "每页数量不能超过100",
+	}
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"strconv"
+
+	"pixelpunk/internal/controllers/storage/dto"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetReplicationPolicy(ctx *gin.Context) {
+	req, err := common.ValidateRequest[dto.SetReplicationPolicyDTO](ctx)
+	if err != nil {
+		errors.HandleError(ctx, err)
+		return
+	}
+
+	policy, err := filesvc.SetReplicationPolicy(req.ScopeType, req.ScopeID, req.ChannelIDs)
+	if err != nil {
+		errors.HandleError(ctx, err)
+		return
+	}
+
+	errors.ResponseSuccess(ctx, policy, "副本策略已保存")
+}
+
+func ListReplicationPolicies(ctx *gin.Context) {
+	req, err := common.ValidateRequest[dto.PageQueryDTO](ctx)
+	if err != nil {
+		errors.HandleError(ctx, err)
+		return
+	}
+	page, size := normalizePage(req.Page, req.Size)
+
+	policies, total, err := filesvc.ListReplicationPolicies(page, size)
+	if err != nil {
+		errors.HandleError(ctx, err)
+		return
+	}
+
+	errors.ResponseSuccess(ctx, gin.H{
+		"items":      policies,
+		"pagination": paginationOf(total, page, size),
+	}, "获取副本策略成功")
+}
+
+func DeleteReplicationPolicy(ctx *gin.Context) {
+	policyID, err := strconv.ParseUint(ctx.Param("policy_id"), 10, 64)
+	if err != nil {
+		errors.HandleError(ctx, errors.New(errors.CodeInvalidParameter, "无效的策略ID"))
+		return
+	}
+
+	if err := filesvc.DeleteReplicationPolicy(uint(policyID)); err != nil {
+		errors.HandleError(ctx, err)
+		return
+	}
+
+	errors.ResponseSuccess(ctx, nil, "副本策略已删除")
+}
+
+func GetFileReplicas(ctx *gin.Context) {
+	replicas, err := filesvc.ListFileReplicas(ctx.Param("file_id"))
+	if err != nil {
+		errors.HandleError(ctx, err)
+		return
+	}
+
+	errors.ResponseSuccess(ctx, replicas, "获取文件副本成功")
+}
+
+func RepairReplicas(ctx *gin.Context) {
+	go filesvc.RepairReplicas(0)
+
+	errors.ResponseSuccess(ctx, nil, "已开始后台修复副本")
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"pixelpunk/internal/controllers/storage/dto"
+	"pixelpunk/internal/middleware"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func CreateMigration(ctx *gin.Context) {
+	req, err := common.ValidateRequest[dto.CreateStorageMigrationDTO](ctx)
+	if err != nil {
+		errors.HandleError(ctx, err)
+		return
+	}
+
+	job, err := filesvc.CreateStorageMigration(middleware.GetCurrentUserID(ctx), filesvc.StorageMigrationOptions{
+		SourceChannelID: req.SourceChannelID,
+		TargetChannelID: req.TargetChannelID,
+		DryRun:          req.DryRun,
+		DeleteSource:    req.DeleteSource,
+		ThrottleMs:      req.ThrottleMs,
+	})
+	if err != nil {
+		errors.HandleError(ctx, err)
+		return
+	}
+
+	errors.ResponseSuccess(ctx, job, "迁移任务已创建")
+}
+
+func ListMigrations(ctx *gin.Context) {
+	req, err := common.ValidateRequest[dto.PageQueryDTO](ctx)
+	if err != nil {
+		errors.HandleError(ctx, err)
+		return
+	}
+	page, size := normalizePage(req.Page, req.Size)
+
+	jobs, total, err := filesvc.ListStorageMigrations(page, size)
+	if err != nil {
+		errors.HandleError(ctx, err)
+		return
+	}
+
+	errors.ResponseSuccess(ctx, gin.H{
+		"items":      jobs,
+		"pagination": paginationOf(total, page, size),
+	}, "获取迁移任务列表成功")
+}
+
+func GetMigration(ctx *gin.Context) {
+	job, err := filesvc.GetStorageMigration(ctx.Param("job_id"))
+	if err != nil {
+		errors.HandleError(ctx, err)
+		return
+	}
+
+	errors.ResponseSuccess(ctx, job, "获取迁移任务成功")
+}
+
+func ListMigrationFailures(ctx *gin.Context) {
+	req, err := common.ValidateRequest[dto.PageQueryDTO](ctx)
+	if err != nil {
+		errors.HandleError(ctx, err)
+		return
+	}
+	page, size := normalizePage(req.Page, req.Size)
+
+	failures, total, err := filesvc.ListStorageMigrationFailures(ctx.Param("job_id"), page, size)
+	if err != nil {
+		errors.HandleError(ctx, err)
+		return
+	}
+
+	errors.ResponseSuccess(ctx, gin.H{
+		"items":      failures,
+		"pagination": paginationOf(total, page, size),
+	}, "获取迁移失败记录成功")
+}
+
+func PauseMigration(ctx *gin.Context) {
+	if err := filesvc.PauseStorageMigration(ctx.Param("job_id")); err != nil {
+		errors.HandleError(ctx, err)
+		return
+	}
+
+	errors.ResponseSuccess(ctx, nil, "迁移任务已暂停")
+}
+
+func ResumeMigration(ctx *gin.Context) {
+	if err := filesvc.ResumeStorageMigration(ctx.Param("job_id")); err != nil {
+		errors.HandleError(ctx, err)
+		return
+	}
+
+	errors.ResponseSuccess(ctx, nil, "迁移任务已继续")
+}
+
+func CancelMigration(ctx *gin.Context) {
+	if err := filesvc.CancelStorageMigration(ctx.Param("job_id")); err != nil {
+		errors.HandleError(ctx, err)
+		return
+	}
+
+	errors.ResponseSuccess(ctx, nil, "迁移任务已取消")
+}
+
+func normalizePage(page, size int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 20
+	}
+	return page, size
+}
+
+func paginationOf(total int64, page, size int) gin.H {
+	return gin.H{
+		"total":        total,
+		"size":         size,
+		"current_page": page,
+		"last_page":    (total + int64(size) - 1) / int64(size),
+	}
+}
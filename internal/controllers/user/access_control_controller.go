@@ -4,6 +4,7 @@ import (
 	"pixelpunk/internal/middleware"
 	"pixelpunk/internal/models"
 	"pixelpunk/internal/services/access_control"
+	filesvc "pixelpunk/internal/services/file"
 	"pixelpunk/pkg/errors"
 
 	"github.com/gin-gonic/gin"
@@ -33,6 +34,7 @@ type NewAccessControlDTO struct {
 	CustomErrorMessage string `json:"custom_error_message"` // 自定义错误信息
 	IPList             string `json:"ip_list"`              // IP列表，逗号分隔
 	DomainList         string `json:"domain_list"`          // 域名列表，逗号分隔
+	WatermarkConfig    string `json:"watermark_config"`     // 盗链水印配置JSON，为空使用默认文字水印
 }
 
 func GetUserAccessControl(c *gin.Context) {
@@ -59,6 +61,7 @@ func GetUserAccessControl(c *gin.Context) {
 		BlockAction:        config.BlockAction,
 		RedirectURL:        config.RedirectURL,
 		CustomErrorMessage: config.CustomErrorMessage,
+		WatermarkConfig:    config.WatermarkConfig,
 	}
 
 	if config.IPWhitelist != "" {
@@ -148,6 +151,8 @@ func ResetUserAccessControl(c *gin.Context) {
 		return
 	}
 
+	go filesvc.PurgeHotlinkWatermarks(userID)
+
 	errors.ResponseSuccess(c, nil, "重置访问控制配置成功")
 }
 
@@ -221,6 +226,17 @@ func CreateOrUpdateUserAccessControl(c *gin.Context) {
 		return
 	}
 
+	watermarkConfig, err := filesvc.NormalizeHotlinkWatermarkConfig(dto.WatermarkConfig)
+	if err != nil {
+		errObj := &errors.Error{
+			Code:    errors.CodeInvalidParameter,
+			Message: "参数验证失败",
+			Detail:  err.Error(),
+		}
+		errors.ResponseError(c, errObj, "")
+		return
+	}
+
 	config := &models.UserAccessControl{
 		UserID:             userID,
 		EnableRefererCheck: dto.Enabled && dto.DomainList != "",
@@ -230,6 +246,7 @@ func CreateOrUpdateUserAccessControl(c *gin.Context) {
 		BlockAction:        dto.BlockAction,
 		RedirectURL:        dto.RedirectURL,
 		CustomErrorMessage: dto.CustomErrorMessage,
+		WatermarkConfig:    watermarkConfig,
 	}
 
 	if dto.IPMode == "whitelist" {
@@ -262,5 +279,7 @@ func CreateOrUpdateUserAccessControl(c *gin.Context) {
 		return
 	}
 
+	go filesvc.PurgeHotlinkWatermarks(userID)
+
 	errors.ResponseSuccess(c, nil, "访问控制配置已创建或更新")
 }
@@ -51,7 +51,7 @@ func AdminCreateUser(c *gin.Context) {
 		return
 	}
 
-	result, err := user.AdminCreateUser(req)
+	result, err := user.AdminCreateUser(req, middleware.GetCurrentUserID(c))
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -112,7 +112,7 @@ func AdminResetUserPassword(c *gin.Context) {
 		return
 	}
 
-	result, err := user.AdminResetUserPassword(uint(id))
+	result, err := user.AdminResetUserPassword(uint(id), middleware.GetCurrentUserID(c))
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -143,7 +143,7 @@ func AdminToggleUserStatus(c *gin.Context) {
 		return
 	}
 
-	if err := user.AdminToggleUserStatus(req); err != nil {
+	if err := user.AdminToggleUserStatus(req, middleware.GetCurrentUserID(c)); err != nil {
 		errors.HandleError(c, err)
 		return
 	}
@@ -164,7 +164,7 @@ func AdminDeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := user.AdminDeleteUser(uint(id)); err != nil {
+	if err := user.AdminDeleteUser(uint(id), middleware.GetCurrentUserID(c)); err != nil {
 		errors.HandleError(c, err)
 		return
 	}
@@ -179,7 +179,7 @@ func AdminBatchOperateUsers(c *gin.Context) {
 		return
 	}
 
-	if err := user.AdminBatchOperateUsers(req); err != nil {
+	if err := user.AdminBatchOperateUsers(req, middleware.GetCurrentUserID(c)); err != nil {
 		errors.HandleError(c, err)
 		return
 	}
@@ -11,3 +11,13 @@ func (r *LoginDTO) GetValidationMessages() map[string]string {
 		"Password.required": "请输入密码",
 	}
 }
+
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+func (r *RefreshTokenDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"RefreshToken.required": "刷新令牌不能为空",
+	}
+}
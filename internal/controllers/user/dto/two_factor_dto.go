@@ -0,0 +1,38 @@
+package dto
+
+/* TwoFactorChallengeDTO 登录挑战请求（绑定身份验证器时使用） */
+type TwoFactorChallengeDTO struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+func (r *TwoFactorChallengeDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"ChallengeToken.required": "验证已过期，请重新登录",
+	}
+}
+
+/* TwoFactorVerifyDTO 提交登录挑战的验证码或恢复码 */
+type TwoFactorVerifyDTO struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required,max=32"`
+}
+
+func (r *TwoFactorVerifyDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"ChallengeToken.required": "验证已过期，请重新登录",
+		"Code.required":           "请输入验证码或恢复码",
+		"Code.max":                "验证码格式不正确",
+	}
+}
+
+/* TwoFactorCodeDTO 已登录用户提交的验证码或恢复码 */
+type TwoFactorCodeDTO struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+func (r *TwoFactorCodeDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Code.required": "请输入验证码或恢复码",
+		"Code.max":      "验证码格式不正确",
+	}
+}
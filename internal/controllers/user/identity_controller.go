@@ -0,0 +1,38 @@
+package user
+
+import (
+	"strconv"
+
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/user"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetUserIdentities 获取当前用户关联的第三方登录身份
+func GetUserIdentities(c *gin.Context) {
+	identities, err := user.ListUserIdentities(middleware.GetCurrentUserID(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, identities, "获取成功")
+}
+
+// UnlinkUserIdentity 解除第三方登录关联
+func UnlinkUserIdentity(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "关联ID格式不正确"))
+		return
+	}
+
+	if err := user.UnlinkUserIdentity(middleware.GetCurrentUserID(c), uint(id)); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "已解除关联")
+}
@@ -0,0 +1,135 @@
+package user
+
+import (
+	"strconv"
+
+	"pixelpunk/internal/controllers/user/dto"
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/auth"
+	"pixelpunk/internal/services/rbac"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RefreshToken 使用刷新令牌换取新的访问令牌，刷新令牌同时轮换
+func RefreshToken(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.RefreshTokenDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	tokens, err := auth.RefreshSession(req.RefreshToken, middleware.GetSessionClient(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, tokens, "续期成功")
+}
+
+// Logout 退出登录并撤销当前会话
+func Logout(c *gin.Context) {
+	sessionID := middleware.GetCurrentSessionID(c)
+	if sessionID != "" {
+		if err := auth.RevokeSession(middleware.GetCurrentUserID(c), sessionID); err != nil {
+			errors.HandleError(c, err)
+			return
+		}
+	}
+
+	errors.ResponseSuccess(c, nil, "已退出登录")
+}
+
+// GetSessions 获取当前用户的登录会话列表
+func GetSessions(c *gin.Context) {
+	sessions, err := auth.ListSessions(middleware.GetCurrentUserID(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	currentID := middleware.GetCurrentSessionID(c)
+	items := make([]gin.H, 0, len(sessions))
+	for _, s := range sessions {
+		items = append(items, gin.H{
+			"id":           s.ID,
+			"device_name":  s.DeviceName,
+			"user_agent":   s.UserAgent,
+			"ip_address":   s.IPAddress,
+			"created_at":   s.CreatedAt,
+			"last_seen_at": s.LastSeenAt,
+			"expires_at":   s.ExpiresAt,
+			"current":      s.ID == currentID,
+		})
+	}
+
+	errors.ResponseSuccess(c, items, "获取成功")
+}
+
+// RevokeSession 撤销指定会话（退出该设备）
+func RevokeSession(c *gin.Context) {
+	if err := auth.RevokeSession(middleware.GetCurrentUserID(c), c.Param("id")); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "已退出该设备")
+}
+
+// RevokeOtherSessions 退出除当前设备外的所有会话
+func RevokeOtherSessions(c *gin.Context) {
+	count, err := auth.RevokeAllSessions(middleware.GetCurrentUserID(c), middleware.GetCurrentSessionID(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"revoked": count}, "已退出其他设备")
+}
+
+// RevokeAllSessions 退出全部会话（包括当前设备）
+func RevokeAllSessions(c *gin.Context) {
+	count, err := auth.RevokeAllSessions(middleware.GetCurrentUserID(c), "")
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"revoked": count}, "已退出全部设备")
+}
+
+// AdminRevokeUserSessions 强制指定用户在所有设备上退出登录
+func AdminRevokeUserSessions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "用户ID格式不正确"))
+		return
+	}
+
+	if err := rbac.CheckManageTarget(middleware.GetCurrentUserID(c), uint(id)); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	count, err := auth.RevokeAllSessions(uint(id), "")
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"revoked": count}, "已强制退出")
+}
+
+func loginResponse(userInfo map[string]interface{}, tokens *auth.TokenPair) gin.H {
+	email, _ := userInfo["email"].(string)
+	return gin.H{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"userInfo":      userInfo,
+		"email":         email,
+	}
+}
@@ -0,0 +1,171 @@
+package user
+
+import (
+	"strconv"
+
+	"pixelpunk/internal/controllers/user/dto"
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/activity"
+	"pixelpunk/internal/services/user"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VerifyTwoFactorLogin 提交验证码或恢复码完成两步验证登录
+func VerifyTwoFactorLogin(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.TwoFactorVerifyDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	userInfo, tokens, err := user.CompleteTwoFactorLogin(req.ChallengeToken, req.Code, middleware.GetSessionClient(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	logTwoFactorLogin(c, userInfo)
+	errors.ResponseSuccess(c, loginResponse(userInfo, tokens), "登录成功")
+}
+
+// SetupTwoFactorByChallenge 被强制要求两步验证的用户在登录过程中获取绑定信息
+func SetupTwoFactorByChallenge(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.TwoFactorChallengeDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	u, err := user.ResolveTwoFactorChallenge(req.ChallengeToken)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	result, err := user.SetupTwoFactor(u.ID)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, result, "获取成功")
+}
+
+// EnableTwoFactorByChallenge 登录过程中确认绑定，返回恢复码并完成登录
+func EnableTwoFactorByChallenge(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.TwoFactorVerifyDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	userInfo, tokens, codes, err := user.CompleteTwoFactorEnrollment(req.ChallengeToken, req.Code, middleware.GetSessionClient(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	logTwoFactorLogin(c, userInfo)
+	data := loginResponse(userInfo, tokens)
+	data["recovery_codes"] = codes
+	errors.ResponseSuccess(c, data, "两步验证已启用")
+}
+
+// GetTwoFactorStatus 获取当前用户的两步验证状态
+func GetTwoFactorStatus(c *gin.Context) {
+	result, err := user.GetTwoFactorStatus(middleware.GetCurrentUserID(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, result, "获取成功")
+}
+
+// SetupTwoFactor 生成身份验证器密钥与 otpauth 地址
+func SetupTwoFactor(c *gin.Context) {
+	result, err := user.SetupTwoFactor(middleware.GetCurrentUserID(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, result, "获取成功")
+}
+
+// EnableTwoFactor 确认绑定并返回恢复码
+func EnableTwoFactor(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.TwoFactorCodeDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	codes, err := user.EnableTwoFactor(middleware.GetCurrentUserID(c), req.Code)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"recovery_codes": codes}, "两步验证已启用")
+}
+
+// DisableTwoFactor 关闭两步验证
+func DisableTwoFactor(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.TwoFactorCodeDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	if err := user.DisableTwoFactor(middleware.GetCurrentUserID(c), req.Code); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "两步验证已关闭")
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func RegenerateRecoveryCodes(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.TwoFactorCodeDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	codes, err := user.RegenerateRecoveryCodes(middleware.GetCurrentUserID(c), req.Code)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"recovery_codes": codes}, "恢复码已重新生成")
+}
+
+// AdminResetTwoFactor 重置指定用户的两步验证
+func AdminResetTwoFactor(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "用户ID格式不正确"))
+		return
+	}
+
+	if err := user.AdminResetTwoFactor(uint(id), middleware.GetCurrentUserID(c)); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "两步验证已重置")
+}
+
+func logTwoFactorLogin(c *gin.Context, userInfo map[string]interface{}) {
+	if userID, ok := userInfo["id"].(uint); ok {
+		username, _ := userInfo["username"].(string)
+		activity.LogUserLogin(userID, username, utils.GetClientIP(c))
+	}
+}
@@ -37,24 +37,18 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	userInfo, token, err := user.Login(req.Account, req.Password)
+	userInfo, tokens, challenge, err := user.Login(req.Account, req.Password, middleware.GetSessionClient(c))
 	if err != nil {
 		errors.HandleError(c, err)
 		return
 	}
 
-	email := ""
-	if val, ok := userInfo["email"]; ok {
-		if emailStr, isString := val.(string); isString {
-			email = emailStr
-		}
+	if challenge != nil {
+		errors.ResponseSuccess(c, gin.H{"requires_two_factor": true, "challenge": challenge}, "请完成两步验证")
+		return
 	}
 
-	data := gin.H{
-		"token":    token,
-		"userInfo": userInfo,
-		"email":    email,
-	}
+	data := loginResponse(userInfo, tokens)
 
 	if userIDVal, ok := userInfo["id"]; ok {
 		if userID, isUint := userIDVal.(uint); isUint {
@@ -128,7 +122,7 @@ func UpdatePassword(c *gin.Context) {
 
 	userID := middleware.GetCurrentUserID(c)
 
-	if err := user.UpdatePassword(userID, req.OldPassword, req.NewPassword); err != nil {
+	if err := user.UpdatePassword(userID, req.OldPassword, req.NewPassword, middleware.GetCurrentSessionID(c)); err != nil {
 		errors.HandleError(c, err)
 		return
 	}
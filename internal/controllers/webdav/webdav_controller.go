@@ -0,0 +1,57 @@
+package webdav
+
+import (
+	"net/http"
+	"os"
+
+	davsvc "pixelpunk/internal/services/webdav"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	dav "golang.org/x/net/webdav"
+)
+
+/* Prefix WebDAV 挂载路径 */
+const Prefix = "/dav"
+
+/* Handle WebDAV 统一入口：基础认证（账号密码或API密钥）通过后交由 webdav.Handler 处理 */
+func Handle(c *gin.Context) {
+	if !davsvc.Enabled() {
+		c.String(http.StatusServiceUnavailable, "WebDAV 接口未启用")
+		return
+	}
+
+	username, password, ok := c.Request.BasicAuth()
+	if !ok {
+		unauthorized(c, "需要认证")
+		return
+	}
+	id, err := davsvc.Authenticate(username, password)
+	if err != nil {
+		if e, ok := err.(*errors.Error); ok && (e.Code == errors.CodeDBQueryFailed || e.Code == errors.CodeInternal) {
+			logger.Error("WebDAV 认证失败: %v", err)
+			c.String(http.StatusInternalServerError, e.Message)
+			return
+		}
+		unauthorized(c, err.Error())
+		return
+	}
+
+	handler := &dav.Handler{
+		Prefix:     Prefix,
+		FileSystem: davsvc.NewFileSystem(id),
+		LockSystem: davsvc.LockSystemFor(id),
+		Logger: func(r *http.Request, err error) {
+			if err != nil && !os.IsNotExist(err) {
+				logger.Warn("WebDAV %s %s 失败: %v", r.Method, r.URL.Path, err)
+			}
+		},
+	}
+	handler.ServeHTTP(c.Writer, c.Request)
+}
+
+func unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Basic realm="PixelPunk WebDAV", charset="UTF-8"`)
+	c.String(http.StatusUnauthorized, message)
+}
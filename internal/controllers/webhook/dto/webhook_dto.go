@@ -0,0 +1,70 @@
+package dto
+
+type CreateWebhookDTO struct {
+	Name        string   `json:"name" binding:"required,max=100"`
+	URL         string   `json:"url" binding:"required,max=1000"`
+	Events      []string `json:"events" binding:"required,min=1,max=20"`
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	Enabled     *bool    `json:"enabled"`
+}
+
+func (d *CreateWebhookDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Name.required":   "名称不能为空",
+		"Name.max":        "名称不能超过100个字符",
+		"URL.required":    "回调地址不能为空",
+		"URL.max":         "回调地址不能超过1000个字符",
+		"Events.required": "至少订阅一个事件",
+		"Events.min":      "至少订阅一个事件",
+		"Events.max":      "订阅的事件不能超过20个",
+		"Description.max": "描述不能超过255个字符",
+	}
+}
+
+type UpdateWebhookDTO struct {
+	Name        string   `json:"name" binding:"omitempty,max=100"`
+	URL         string   `json:"url" binding:"omitempty,max=1000"`
+	Events      []string `json:"events" binding:"omitempty,min=1,max=20"`
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	Enabled     *bool    `json:"enabled"`
+}
+
+func (d *UpdateWebhookDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Name.max":        "名称不能超过100个字符",
+		"URL.max":         "回调地址不能超过1000个字符",
+		"Events.min":      "至少订阅一个事件",
+		"Events.max":      "订阅的事件不能超过20个",
+		"Description.max": "描述不能超过255个字符",
+	}
+}
+
+type PageQueryDTO struct {
+	Page int `form:"page" binding:"omitempty,min=1"`
+	Size int `form:"size" binding:"omitempty,min=1,max=100"`
+}
+
+func (d *PageQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Page.min": "页码必须大于等于1",
+		"Size.min": "每页数量必须大于等于1",
+		"Size.max": "每页数量不能超过100",
+	}
+}
+
+type DeliveryQueryDTO struct {
+	WebhookID uint   `form:"webhook_id"`
+	Status    string `form:"status" binding:"omitempty,oneof=queued processing done failed"`
+	Event     string `form:"event" binding:"omitempty,max=50"`
+	Page      int    `form:"page" binding:"omitempty,min=1"`
+	Size      int    `form:"size" binding:"omitempty,min=1,max=100"`
+}
+
+func (d *DeliveryQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Status.oneof": "状态只能是 queued、processing、done 或 failed",
+		"Page.min":     "页码必须大于等于1",
+		"Size.min":     "每页数量必须大于等于1",
+		"Size.max":     "每页数量不能超过100",
+	}
+}
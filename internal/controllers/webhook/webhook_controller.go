@@ -0,0 +1,250 @@
+package webhook
+
+import (
+	"strconv"
+
+	"pixelpunk/internal/controllers/webhook/dto"
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/models"
+	webhooksvc "pixelpunk/internal/services/webhook"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const scopeContextKey = "webhook_scope"
+
+/* WithScope 指定路由组管理的订阅范围：用户路由管理个人订阅，管理员路由管理全站订阅 */
+func WithScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(scopeContextKey, scope)
+		c.Next()
+	}
+}
+
+func ownerOf(c *gin.Context) webhooksvc.Owner {
+	scope := models.WebhookScopeUser
+	if c.GetString(scopeContextKey) == models.WebhookScopeAdmin {
+		scope = models.WebhookScopeAdmin
+	}
+	return webhooksvc.Owner{UserID: middleware.GetCurrentUserID(c), Scope: scope}
+}
+
+func parseID(c *gin.Context, name, message string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, message))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func normalizePage(page, size int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 20
+	}
+	return page, size
+}
+
+func paginationOf(total int64, page, size int) gin.H {
+	return gin.H{
+		"total":        total,
+		"size":         size,
+		"current_page": page,
+		"last_page":    (total + int64(size) - 1) / int64(size),
+	}
+}
+
+func ListEvents(c *gin.Context) {
+	errors.ResponseSuccess(c, webhooksvc.AllEvents, "获取事件列表成功")
+}
+
+func CreateWebhook(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.CreateWebhookDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	hook, secret, err := webhooksvc.CreateWebhook(ownerOf(c), webhooksvc.WebhookInput{
+		Name:        req.Name,
+		URL:         req.URL,
+		Events:      req.Events,
+		Description: req.Description,
+		Enabled:     req.Enabled,
+	})
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	// 签名密钥仅在创建与重新生成时返回
+	errors.ResponseSuccess(c, gin.H{"webhook": hook, "secret": secret}, "创建回调订阅成功")
+}
+
+func ListWebhooks(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.PageQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	page, size := normalizePage(req.Page, req.Size)
+
+	hooks, total, err := webhooksvc.ListWebhooks(ownerOf(c), page, size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{
+		"items":      hooks,
+		"pagination": paginationOf(total, page, size),
+	}, "获取回调订阅成功")
+}
+
+func GetWebhook(c *gin.Context) {
+	id, ok := parseID(c, "webhook_id", "无效的订阅ID")
+	if !ok {
+		return
+	}
+
+	hook, err := webhooksvc.GetWebhook(ownerOf(c), id)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, hook, "获取回调订阅成功")
+}
+
+func UpdateWebhook(c *gin.Context) {
+	id, ok := parseID(c, "webhook_id", "无效的订阅ID")
+	if !ok {
+		return
+	}
+	req, err := common.ValidateRequest[dto.UpdateWebhookDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	hook, err := webhooksvc.UpdateWebhook(ownerOf(c), id, webhooksvc.WebhookInput{
+		Name:        req.Name,
+		URL:         req.URL,
+		Events:      req.Events,
+		Description: req.Description,
+		Enabled:     req.Enabled,
+	})
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, hook, "更新回调订阅成功")
+}
+
+func DeleteWebhook(c *gin.Context) {
+	id, ok := parseID(c, "webhook_id", "无效的订阅ID")
+	if !ok {
+		return
+	}
+
+	if err := webhooksvc.DeleteWebhook(ownerOf(c), id); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "回调订阅已删除")
+}
+
+func RotateSecret(c *gin.Context) {
+	id, ok := parseID(c, "webhook_id", "无效的订阅ID")
+	if !ok {
+		return
+	}
+
+	secret, err := webhooksvc.RotateSecret(ownerOf(c), id)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"secret": secret}, "签名密钥已重新生成")
+}
+
+func TestWebhook(c *gin.Context) {
+	id, ok := parseID(c, "webhook_id", "无效的订阅ID")
+	if !ok {
+		return
+	}
+
+	attempt, err := webhooksvc.TestWebhook(ownerOf(c), id)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, attempt, "测试事件已发送")
+}
+
+func ListDeliveries(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.DeliveryQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	page, size := normalizePage(req.Page, req.Size)
+
+	query := webhooksvc.DeliveryQuery{WebhookID: req.WebhookID, Status: req.Status, Event: req.Event, Page: page, Size: size}
+	if c.Param("webhook_id") != "" {
+		id, ok := parseID(c, "webhook_id", "无效的订阅ID")
+		if !ok {
+			return
+		}
+		query.WebhookID = id
+	}
+
+	deliveries, total, err := webhooksvc.ListDeliveries(ownerOf(c), query)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{
+		"items":      deliveries,
+		"pagination": paginationOf(total, page, size),
+	}, "获取投递记录成功")
+}
+
+func ListDeliveryAttempts(c *gin.Context) {
+	id, ok := parseID(c, "delivery_id", "无效的投递ID")
+	if !ok {
+		return
+	}
+
+	attempts, err := webhooksvc.ListDeliveryAttempts(ownerOf(c), id)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, attempts, "获取投递尝试成功")
+}
+
+func Redeliver(c *gin.Context) {
+	id, ok := parseID(c, "delivery_id", "无效的投递ID")
+	if !ok {
+		return
+	}
+
+	if err := webhooksvc.Redeliver(ownerOf(c), id); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "已重新加入投递队列")
+}
@@ -0,0 +1,110 @@
+package dto
+
+// CreateWorkspaceDTO 创建工作区DTO
+type CreateWorkspaceDTO struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"omitempty,max=500"`
+}
+
+func (d *CreateWorkspaceDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Name.required":   "工作区名称不能为空",
+		"Name.max":        "工作区名称不能超过100个字符",
+		"Description.max": "工作区描述不能超过500个字符",
+	}
+}
+
+// UpdateWorkspaceDTO 更新工作区DTO，未传字段保持不变
+type UpdateWorkspaceDTO struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=500"`
+}
+
+func (d *UpdateWorkspaceDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Name.min":        "工作区名称不能为空",
+		"Name.max":        "工作区名称不能超过100个字符",
+		"Description.max": "工作区描述不能超过500个字符",
+	}
+}
+
+// AddMemberDTO 添加成员DTO
+type AddMemberDTO struct {
+	Account string `json:"account" binding:"required,max=100"` // 用户名或邮箱
+	Role    string `json:"role" binding:"required,oneof=editor uploader viewer"`
+}
+
+func (d *AddMemberDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Account.required": "请输入成员的用户名或邮箱",
+		"Account.max":      "用户名或邮箱不能超过100个字符",
+		"Role.required":    "成员角色不能为空",
+		"Role.oneof":       "成员角色只能是 editor、uploader 或 viewer",
+	}
+}
+
+// UpdateMemberRoleDTO 修改成员角色DTO
+type UpdateMemberRoleDTO struct {
+	Role string `json:"role" binding:"required,oneof=editor uploader viewer"`
+}
+
+func (d *UpdateMemberRoleDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Role.required": "成员角色不能为空",
+		"Role.oneof":    "成员角色只能是 editor、uploader 或 viewer",
+	}
+}
+
+// TransferOwnershipDTO 转让工作区DTO
+type TransferOwnershipDTO struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+func (d *TransferOwnershipDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"UserID.required": "请选择新的所有者",
+	}
+}
+
+// ActivityListQueryDTO 工作区动态查询DTO
+type ActivityListQueryDTO struct {
+	Page     int  `form:"page" binding:"omitempty,min=1"`
+	Size     int  `form:"size" binding:"omitempty,min=1,max=100"`
+	MemberID uint `form:"member_id"` // 只看某个成员的动态
+}
+
+func (d *ActivityListQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Page.min": "页码必须大于0",
+		"Size.min": "每页数量必须大于0",
+		"Size.max": "每页数量不能超过100",
+	}
+}
+
+// AdminWorkspaceListQueryDTO 管理员工作区列表查询DTO
+type AdminWorkspaceListQueryDTO struct {
+	Page    int    `form:"page" binding:"omitempty,min=1"`
+	Size    int    `form:"size" binding:"omitempty,min=1,max=100"`
+	Keyword string `form:"keyword" binding:"omitempty,max=100"`
+}
+
+func (d *AdminWorkspaceListQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Page.min":    "页码必须大于0",
+		"Size.min":    "每页数量必须大于0",
+		"Size.max":    "每页数量不能超过100",
+		"Keyword.max": "搜索关键词不能超过100个字符",
+	}
+}
+
+// SetStorageLimitDTO 设置工作区共享配额DTO
+type SetStorageLimitDTO struct {
+	StorageLimit *int64 `json:"storage_limit" binding:"required,min=0"` // 字节，0 表示不限制
+}
+
+func (d *SetStorageLimitDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"StorageLimit.required": "存储配额不能为空",
+		"StorageLimit.min":      "存储配额不能为负数",
+	}
+}
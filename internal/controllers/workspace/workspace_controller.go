@@ -0,0 +1,255 @@
+package workspace
+
+// 工作区控制器
+
+import (
+	"strconv"
+
+	"pixelpunk/internal/controllers/workspace/dto"
+	"pixelpunk/internal/middleware"
+	workspacesvc "pixelpunk/internal/services/workspace"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func normalizePage(page, size, defaultSize int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = defaultSize
+	}
+	return page, size
+}
+
+func paginated(items interface{}, total int64, page, size int) gin.H {
+	return gin.H{
+		"items": items,
+		"pagination": gin.H{
+			"total":        total,
+			"size":         size,
+			"current_page": page,
+			"last_page":    (total + int64(size) - 1) / int64(size),
+		},
+	}
+}
+
+func parseUserID(c *gin.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		return 0, errors.New(errors.CodeInvalidParameter, "成员ID格式不正确")
+	}
+	return uint(id), nil
+}
+
+// CreateWorkspace 创建工作区
+func CreateWorkspace(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.CreateWorkspaceDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	ws, err := workspacesvc.CreateWorkspace(middleware.GetCurrentUserID(c), req.Name, req.Description)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, ws, "创建工作区成功")
+}
+
+// ListWorkspaces 获取当前用户加入的工作区
+func ListWorkspaces(c *gin.Context) {
+	workspaces, err := workspacesvc.ListWorkspaces(middleware.GetCurrentUserID(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, workspaces, "获取成功")
+}
+
+// GetWorkspace 获取工作区详情
+func GetWorkspace(c *gin.Context) {
+	ws, err := workspacesvc.GetWorkspace(middleware.GetCurrentUserID(c), c.Param("workspace_id"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, ws, "获取成功")
+}
+
+// UpdateWorkspace 更新工作区
+func UpdateWorkspace(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.UpdateWorkspaceDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	ws, err := workspacesvc.UpdateWorkspace(middleware.GetCurrentUserID(c), c.Param("workspace_id"), req.Name, req.Description)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, ws, "更新工作区成功")
+}
+
+// DeleteWorkspace 删除工作区
+func DeleteWorkspace(c *gin.Context) {
+	if err := workspacesvc.DeleteWorkspace(middleware.GetCurrentUserID(c), c.Param("workspace_id")); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "删除工作区成功")
+}
+
+// ListMembers 获取工作区成员
+func ListMembers(c *gin.Context) {
+	members, err := workspacesvc.ListMembers(middleware.GetCurrentUserID(c), c.Param("workspace_id"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, members, "获取成功")
+}
+
+// AddMember 添加工作区成员
+func AddMember(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.AddMemberDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	member, err := workspacesvc.AddMember(middleware.GetCurrentUserID(c), c.Param("workspace_id"), req.Account, req.Role)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, member, "添加成员成功")
+}
+
+// UpdateMemberRole 修改成员角色
+func UpdateMemberRole(c *gin.Context) {
+	memberID, err := parseUserID(c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	req, err := common.ValidateRequest[dto.UpdateMemberRoleDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	if err := workspacesvc.UpdateMemberRole(middleware.GetCurrentUserID(c), c.Param("workspace_id"), memberID, req.Role); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "修改成员角色成功")
+}
+
+// RemoveMember 移除工作区成员
+func RemoveMember(c *gin.Context) {
+	memberID, err := parseUserID(c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	if err := workspacesvc.RemoveMember(middleware.GetCurrentUserID(c), c.Param("workspace_id"), memberID); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "移除成员成功")
+}
+
+// LeaveWorkspace 退出工作区
+func LeaveWorkspace(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	if err := workspacesvc.RemoveMember(userID, c.Param("workspace_id"), userID); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "已退出工作区")
+}
+
+// TransferOwnership 转让工作区
+func TransferOwnership(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.TransferOwnershipDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	if err := workspacesvc.TransferOwnership(middleware.GetCurrentUserID(c), c.Param("workspace_id"), req.UserID); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "转让工作区成功")
+}
+
+// ListActivities 获取工作区动态
+func ListActivities(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.ActivityListQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	page, size := normalizePage(req.Page, req.Size, 20)
+	activities, total, err := workspacesvc.ListActivities(middleware.GetCurrentUserID(c), c.Param("workspace_id"), req.MemberID, page, size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, paginated(activities, total, page, size), "获取成功")
+}
+
+// AdminListWorkspaces 管理员获取工作区列表
+func AdminListWorkspaces(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.AdminWorkspaceListQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	page, size := normalizePage(req.Page, req.Size, 20)
+	workspaces, total, err := workspacesvc.AdminListWorkspaces(req.Keyword, page, size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, paginated(workspaces, total, page, size), "获取成功")
+}
+
+// AdminSetStorageLimit 管理员设置工作区共享配额
+func AdminSetStorageLimit(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.SetStorageLimitDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	if err := workspacesvc.AdminSetStorageLimit(c.Param("workspace_id"), *req.StorageLimit); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "更新工作区配额成功")
+}
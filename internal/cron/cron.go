@@ -2,9 +2,13 @@ package cron
 
 import (
 	"pixelpunk/internal/services/ai"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/internal/services/search"
+	"pixelpunk/internal/services/setting"
 	"pixelpunk/internal/services/stats"
 	"pixelpunk/internal/services/tag"
 	vectorSvc "pixelpunk/internal/services/vector"
+	"pixelpunk/internal/services/webhook"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/logger"
 
@@ -59,6 +63,12 @@ func registerTasks() {
 
 	registerTagUsageCountCalibrationTask()
 
+	registerReplicaRepairTask()
+
+	registerWebhookCleanupTask()
+
+	registerSearchIndexTask()
+
 }
 
 func registerStatsTask() {
@@ -208,3 +218,43 @@ func Stop() {
 		taggingService.Stop()
 	}
 }
+
+func registerReplicaRepairTask() {
+	_, err := cronManager.AddFunc("0 */10 * * * *", func() {
+		if n, err := filesvc.RepairReplicas(200); err != nil {
+			logger.Warn("文件副本修复失败: %v", err)
+		} else if n > 0 {
+			logger.Info("文件副本修复完成：%d", n)
+		}
+	})
+	if err != nil {
+		logger.Error("注册文件副本修复任务失败: %v", err)
+	}
+}
+
+func registerWebhookCleanupTask() {
+	_, err := cronManager.AddFunc("0 30 3 * * *", func() {
+		days := setting.GetInt("security", "webhook_log_retention_days", 30)
+		if n, err := webhook.CleanupDeliveries(days); err != nil {
+			logger.Warn("清理回调投递记录失败: %v", err)
+		} else if n > 0 {
+			logger.Info("清理回调投递记录：%d", n)
+		}
+	})
+	if err != nil {
+		logger.Error("注册回调投递记录清理任务失败: %v", err)
+	}
+}
+
+func registerSearchIndexTask() {
+	_, err := cronManager.AddFunc("30 */1 * * * *", func() {
+		if n, err := search.SyncIndex(500); err != nil {
+			logger.Warn("全文索引同步失败: %v", err)
+		} else if n > 0 {
+			logger.Info("全文索引同步完成：%d", n)
+		}
+	})
+	if err != nil {
+		logger.Error("注册全文索引同步任务失败: %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package cron
 import (
 	filesvc "pixelpunk/internal/services/file"
 	"pixelpunk/internal/services/message"
+	"pixelpunk/internal/services/s3gateway"
 	"pixelpunk/pkg/logger"
 	"time"
 )
@@ -33,6 +34,18 @@ func (j *ImageCleanupJob) Execute() error {
 	} else {
 	}
 
+	if n, err := filesvc.PurgeExpiredTrash(100); err != nil {
+		logger.Error("❌ 清理过期回收站条目失败: %v", err)
+	} else if n > 0 {
+		logger.Info("🧹 清理过期回收站条目：%d", n)
+	}
+
+	if n, err := s3gateway.CleanupStaleMultipartUploads(7*24*time.Hour, 100); err != nil {
+		logger.Error("❌ 清理过期S3分片上传失败: %v", err)
+	} else if n > 0 {
+		logger.Info("🧹 清理过期S3分片上传：%d", n)
+	}
+
 	if success, failed, err := filesvc.CleanupExpiredFiles(); err != nil {
 		logger.Error("❌ 清理过期文件失败: %v", err)
 		return err
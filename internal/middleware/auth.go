@@ -3,12 +3,14 @@ package middleware
 import (
 	"pixelpunk/internal/models"
 	"pixelpunk/internal/services/auth"
+	"pixelpunk/internal/services/rbac"
 	"pixelpunk/internal/services/setting"
 	"pixelpunk/pkg/cache"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/utils"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -74,6 +76,23 @@ func GetCurrentUserID(c *gin.Context) uint {
 	return claims.UserID
 }
 
+/* GetCurrentSessionID 当前访问令牌所属的登录会话ID，旧版令牌为空 */
+func GetCurrentSessionID(c *gin.Context) string {
+	claims := GetCurrentUser(c)
+	if claims == nil {
+		return ""
+	}
+	return claims.SessionID
+}
+
+/* GetSessionClient 登录请求的客户端信息，用于记录会话设备 */
+func GetSessionClient(c *gin.Context) auth.SessionClient {
+	return auth.SessionClient{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: utils.GetClientIP(c),
+	}
+}
+
 func GetCurrentUsername(c *gin.Context) string {
 	claims := GetCurrentUser(c)
 	if claims == nil {
@@ -100,6 +119,28 @@ func IsCurrentUserSuperAdmin(c *gin.Context) bool {
 	return role == common.UserRoleSuperAdmin
 }
 
+/* CurrentUserHasPermission 当前用户是否拥有指定后台权限，普通用户直接返回false避免查库 */
+func CurrentUserHasPermission(c *gin.Context, permission string) bool {
+	claims := GetCurrentUser(c)
+	if claims == nil {
+		return false
+	}
+	return userHasPermission(claims.UserID, claims.Role, permission)
+}
+
+// userHasPermission 固定角色为管理员时再按分配的角色实时判断权限
+func userHasPermission(userID uint, role int, permission string) bool {
+	if role != common.UserRoleAdmin && role != common.UserRoleSuperAdmin {
+		return false
+	}
+	allowed, err := rbac.HasPermission(userID, permission)
+	if err != nil {
+		logger.Warn("权限查询失败: userID=%d, permission=%s, error=%v", userID, permission, err)
+		return false
+	}
+	return allowed
+}
+
 func CanUserAccessProtectedFile(c *gin.Context, imageUserID uint) bool {
 	userID := GetCurrentUserID(c)
 
@@ -111,7 +152,7 @@ func CanUserAccessProtectedFile(c *gin.Context, imageUserID uint) bool {
 		return true
 	}
 
-	return IsCurrentUserAdmin(c)
+	return CurrentUserHasPermission(c, rbac.PermFileManage)
 }
 
 /* JWTAuth JWT解析中间件（验证token有效性和过期时间） */
@@ -170,8 +211,18 @@ func JWTAuth() gin.HandlerFunc {
 			return
 		}
 
+		if auth.IsTokenRevoked(claims) {
+			c.Set(AuthErrorKey, "登录会话已失效，请重新登录")
+			c.Next()
+			return
+		}
+
 		c.Set(ContextPayloadKey, claims)
 
+		if claims.SessionID != "" {
+			go auth.TouchSession(claims.SessionID, utils.GetClientIP(c))
+		}
+
 		// 检查用户是否被禁用（在JWT解析后立即检查，覆盖所有需要认证的接口）
 		if !checkUserActive(claims) {
 			// 使用专用错误码，前端会跳转到refuse页面
@@ -243,6 +294,37 @@ func RequireSuperAdmin() gin.HandlerFunc {
 	}
 }
 
+/* RequirePermission 后台权限校验中间件，按用户当前分配的角色实时判断，角色调整无需重新登录 */
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authError, exists := c.Get(AuthErrorKey); exists {
+			errors.HandleError(c, errors.New(errors.CodeUnauthorized, authError.(string)))
+			c.Abort()
+			return
+		}
+
+		claims := GetCurrentUser(c)
+		if claims == nil {
+			errors.HandleError(c, errors.New(errors.CodeUnauthorized, "用户认证信息无效"))
+			c.Abort()
+			return
+		}
+
+		allowed, err := rbac.HasPermission(claims.UserID, permission)
+		if err != nil {
+			errors.HandleError(c, err)
+			c.Abort()
+			return
+		}
+		if !allowed {
+			errors.HandleError(c, errors.New(errors.CodeForbidden, "没有访问该功能的权限"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 /* RequireActiveUser 用户状态校验中间件（检查用户是否被禁用）
  * 使用Redis黑名单实现即时踢出，Redis不可用时降级为数据库查询
  * 建议用于：写操作、敏感接口、管理后台
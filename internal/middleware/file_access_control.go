@@ -6,8 +6,10 @@ import (
 	"net/url"
 	"pixelpunk/internal/models"
 	"pixelpunk/internal/services/access_control"
+	"pixelpunk/internal/services/album"
 	"pixelpunk/internal/services/auth"
 	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/internal/services/rbac"
 	"pixelpunk/internal/services/setting"
 	"pixelpunk/internal/services/share"
 	"pixelpunk/internal/services/stats"
@@ -50,6 +52,23 @@ func FileAccessControlMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if linkID := c.Query("lid"); linkID != "" {
+			if file.Status == "pending_review" {
+				assets.ServeDefaultFile(c, assets.FileTypeReview)
+				return
+			}
+			domain := extractDomainFromReferer(c.GetHeader("Referer"))
+			if err := filesvc.ValidateSignedLink(linkID, c.Query("sig"), file.ID, c.ClientIP(), domain, signedLinkRequestOf(c, file, isThumb), isIPInList, isDomainInList); err != nil {
+				assets.ServeDefaultFile(c, assets.FileTypeUnauthorized)
+				return
+			}
+			if !isThumb {
+				go updateFileStats(file.ID, file.UserID, file.Size)
+			}
+			c.Next()
+			return
+		}
+
 		isInternalRequest := isFromConfiguredBaseUrl(c)
 
 		if !isThumb {
@@ -89,10 +108,32 @@ func FileAccessControlMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 公开相册只放行访问级别检查，防盗链与 IP 限制仍需先通过
+		if albumSlug := c.Query("album"); albumSlug != "" {
+			if album.IsFileViewable(albumSlug, file.ID) {
+				c.Next()
+				return
+			}
+			assets.ServeDefaultFile(c, assets.FileTypeUnauthorized)
+			return
+		}
+
 		handleFileAccessLevel(c, file, isInternalRequest)
 	}
 }
 
+// signedLinkRequestOf 只有原文件的 GET 计次，区间续传由服务层按字节额度判断，缩略图与 HEAD 不计次
+func signedLinkRequestOf(c *gin.Context, file models.File, isThumb bool) filesvc.SignedLinkRequest {
+	req := filesvc.SignedLinkRequest{Use: filesvc.SignedLinkUseFull, Range: c.GetHeader("Range"), FileSize: file.Size}
+	switch {
+	case isThumb || c.Request.Method != http.MethodGet:
+		req.Use = filesvc.SignedLinkUsePeek
+	case req.Range != "":
+		req.Use = filesvc.SignedLinkUseRange
+	}
+	return req
+}
+
 func verifyShareAccess(c *gin.Context, shareKey string, fileID string) bool {
 	var share models.Share
 	if err := database.DB.Where("share_key = ? AND status = ?", shareKey, common.ShareStatusNormal).First(&share).Error; err != nil {
@@ -125,6 +166,16 @@ func verifyShareAccess(c *gin.Context, shareKey string, fileID string) bool {
 		return false
 	}
 
+	if targetImage.UserID == share.UserID {
+		database.DB.Model(&models.ShareItem{}).
+			Joins("JOIN album_file ON album_file.album_id = share_item.item_id").
+			Where("share_item.share_id = ? AND share_item.item_type = ? AND album_file.file_id = ?", share.ID, common.ShareItemTypeAlbum, fileID).
+			Count(&count)
+		if count > 0 {
+			return true
+		}
+	}
+
 	var folderShares []models.ShareItem
 	database.DB.Where("share_id = ? AND item_type = ?", share.ID, common.ShareItemTypeFolder).Find(&folderShares)
 
@@ -292,7 +343,10 @@ func handleBlockAction(c *gin.Context, config *models.UserAccessControl, ip, dom
 	case models.BlockActionBlock:
 		assets.ServeDefaultFile(c, assets.FileTypeUnauthorized)
 	case models.BlockActionWatermark:
+		// 由文件服务处理器读取并输出盗链水印图
 		c.Set("watermark", true)
+		c.Set("watermark_domain", domain)
+		c.Set("watermark_config", config.WatermarkConfig)
 		c.Next()
 	case models.BlockActionThumbnail:
 		c.Set("forceThumbnail", true)
@@ -320,7 +374,7 @@ func isAdminRequest(c *gin.Context) bool {
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		jwtSecret := getJWTSecret()
 		claims, err := auth.ParseToken(tokenString, jwtSecret)
-		if err == nil && (claims.Role == common.UserRoleAdmin || claims.Role == common.UserRoleSuperAdmin) {
+		if err == nil && !auth.IsTokenRevoked(claims) && userHasPermission(claims.UserID, claims.Role, rbac.PermFileManage) {
 			return true
 		}
 	}
@@ -2,7 +2,7 @@ package middleware
 
 import (
 	"pixelpunk/internal/models"
-	"pixelpunk/pkg/common"
+	"pixelpunk/internal/services/rbac"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 
@@ -74,8 +74,8 @@ func canUserDownloadFile(userID uint, file models.File) bool {
 			return true
 		}
 		var user models.User
-		if err := database.DB.Where("id = ?", userID).First(&user).Error; err == nil {
-			return user.Role == common.UserRoleAdmin || user.Role == common.UserRoleSuperAdmin
+		if err := database.DB.Select("id, role").Where("id = ?", userID).First(&user).Error; err == nil {
+			return userHasPermission(user.ID, user.Role, rbac.PermFileManage)
 		}
 		return false
 	default:
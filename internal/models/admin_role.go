@@ -0,0 +1,38 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+	"strings"
+)
+
+/* 内置管理角色名称，迁移时按旧的固定角色映射 */
+const (
+	AdminRoleSuperAdmin = "super_admin"
+	AdminRoleAdmin      = "admin"
+	AdminRoleModerator  = "moderator"
+)
+
+/* AdminRole 后台管理角色，Permissions 为逗号分隔的权限标识 */
+type AdminRole struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	Name        string `gorm:"size:50;not null;uniqueIndex:idx_admin_role_name" json:"name"`
+	DisplayName string `gorm:"size:100" json:"display_name"`
+	Description string `gorm:"size:500" json:"description"`
+	Permissions string `gorm:"type:text" json:"-"`
+	IsBuiltIn   bool   `gorm:"default:false" json:"is_built_in"` // 内置角色不可修改或删除
+}
+
+func (AdminRole) TableName() string {
+	return "admin_role"
+}
+
+/* PermissionList 解析权限标识列表 */
+func (r *AdminRole) PermissionList() []string {
+	if r.Permissions == "" {
+		return []string{}
+	}
+	return strings.Split(r.Permissions, ",")
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+)
+
+// 相册访问级别
+const (
+	AlbumAccessPublic   = "public"   // 公开，出现在用户的公开画廊列表，凭 slug 访问
+	AlbumAccessUnlisted = "unlisted" // 不公开列出，知道 slug 即可访问
+	AlbumAccessPrivate  = "private"  // 仅本人可见，可通过分享链接对外提供
+)
+
+// 相册内文件的排序方式
+const (
+	AlbumSortCustom    = "custom"     // 按手动调整的顺序
+	AlbumSortTakenAt   = "taken_at"   // 按拍摄时间
+	AlbumSortCreatedAt = "created_at" // 按上传时间
+	AlbumSortName      = "name"       // 按文件名
+)
+
+/* Album 相册：文件与相册多对多关联，同一文件可出现在多个相册中，与文件夹结构无关 */
+type Album struct {
+	ID        string          `gorm:"primarykey;size:32" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	UserID      uint   `gorm:"not null;index" json:"user_id"`
+	Title       string `gorm:"size:100;not null" json:"title"`
+	Description string `gorm:"type:text" json:"description"`
+	Slug        string `gorm:"size:64;not null;uniqueIndex:idx_album_slug" json:"slug"` // 公开访问标识
+	AccessLevel string `gorm:"size:20;not null;default:private;index" json:"access_level"`
+	CoverFileID string `gorm:"size:32" json:"cover_file_id"` // 为空时使用排序后的第一个文件
+
+	SortMode   string `gorm:"size:20;not null;default:custom" json:"sort_mode"`
+	SortDesc   bool   `gorm:"default:false" json:"sort_desc"`
+	Layout     string `gorm:"size:20;not null;default:grid" json:"layout"` // 展示布局：grid / masonry / justified / slideshow
+	LayoutHint string `gorm:"type:text" json:"layout_hint"`                // 前端展示提示（列数、间距、是否显示标题等）的 JSON
+	SortOrder  int    `gorm:"default:0" json:"sort_order"`                 // 相册在列表中的顺序
+	FileCount  int    `gorm:"default:0" json:"file_count"`
+}
+
+func (Album) TableName() string {
+	return "album"
+}
+
+/* AlbumFile 相册与文件的关联 */
+type AlbumFile struct {
+	AlbumID   string          `gorm:"primarykey;size:32" json:"album_id"`
+	FileID    string          `gorm:"primarykey;size:32;index" json:"file_id"`
+	SortOrder int             `gorm:"default:0;index" json:"sort_order"`
+	Caption   string          `gorm:"size:500" json:"caption"` // 相册内的说明文字
+	CreatedAt common.JSONTime `json:"created_at"`
+}
+
+func (AlbumFile) TableName() string {
+	return "album_file"
+}
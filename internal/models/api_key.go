@@ -2,6 +2,7 @@ package models
 
 import (
 	"pixelpunk/pkg/common"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -27,6 +28,10 @@ type APIKey struct {
 	AllowedTypes string `gorm:"size:255" json:"allowed_types"` // 允许的文件类型，如: "jpg,jpeg,png,gif"
 	FolderID     string `gorm:"size:32" json:"folder_id"`      // 指定上传目录
 
+	WorkspaceID string `gorm:"size:32;index" json:"workspace_id,omitempty"` // 上传目录所属工作区
+
+	S3Permissions string `gorm:"size:32" json:"s3_permissions"` // S3 兼容接口权限，逗号分隔的 read/write/delete，空表示不允许通过 S3 访问
+
 	ExpiresAt  *common.JSONTime `json:"expires_at"`   // 过期时间，nil表示永不过期
 	LastUsedAt *common.JSONTime `json:"last_used_at"` // 最后使用时间
 }
@@ -37,6 +42,13 @@ const (
 	APIKeyStatusDisabled = 2 // 禁用状态
 )
 
+/* API密钥的 S3 兼容接口权限 */
+const (
+	APIKeyS3Read   = "read"   // 列举存储桶与下载对象
+	APIKeyS3Write  = "write"  // 上传对象
+	APIKeyS3Delete = "delete" // 删除对象
+)
+
 func (APIKey) TableName() string {
 	return "api_key"
 }
@@ -52,6 +64,15 @@ func (k *APIKey) IsActive() bool {
 	return k.Status == APIKeyStatusActive
 }
 
+func (k *APIKey) HasS3Permission(perm string) bool {
+	for _, p := range strings.Split(k.S3Permissions, ",") {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
 func (k *APIKey) IsDisabled() bool {
 	return k.Status == APIKeyStatusDisabled
 }
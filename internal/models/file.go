@@ -35,6 +35,7 @@ type File struct {
 	ShortURL string `gorm:"size:32;index:idx_file_short_url" json:"short_url"`
 
 	MD5Hash       string  `gorm:"size:32;index:idx_file_md5_hash" json:"md5_hash"`
+	PHash         string  `gorm:"column:phash;size:16;index:idx_file_phash" json:"phash"` // 感知哈希(dHash)，用于近似重复检测
 	Size          int64   `gorm:"not null" json:"size"`
 	SizeFormatted string  `gorm:"size:20" json:"size_formatted"`
 	Width         int     `json:"width"`  // 文件/视频专用
@@ -81,6 +82,8 @@ type File struct {
 
 	SortOrder int `gorm:"default:0" json:"sort_order"`
 
+	WorkspaceID string `gorm:"size:32;index:idx_file_workspace_id" json:"workspace_id,omitempty"` // 所属工作区，UserID 为上传成员
+
 	User     *User         `gorm:"foreignKey:UserID;references:ID" json:"user"`
 	AIInfo   *FileAIInfo   `gorm:"foreignKey:FileID;references:ID" json:"ai_info"`
 	Category *FileCategory `gorm:"foreignKey:CategoryID;references:ID" json:"category"`
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+/* FileEmbedding 内置向量存储的向量数据，每个文件的每个命名向量（text/image）一行，未部署Qdrant时使用 */
+type FileEmbedding struct {
+	FileID    string          `gorm:"primarykey;size:32" json:"file_id"`
+	Name      string          `gorm:"primarykey;size:16" json:"name"` // 命名向量：text / image
+	UserID    uint            `gorm:"not null;index" json:"user_id"`
+	Model     string          `gorm:"size:100" json:"model"`
+	Dimension int             `gorm:"not null" json:"dimension"`
+	Vector    []byte          `gorm:"not null" json:"-"`        // float32 小端序
+	Payload   json.RawMessage `gorm:"type:json" json:"payload"` // 过滤属性与描述，与Qdrant payload一致
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+func (FileEmbedding) TableName() string {
+	return "file_embedding"
+}
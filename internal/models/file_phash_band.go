@@ -0,0 +1,14 @@
+package models
+
+/* FilePHashBand 感知哈希分段索引，用于按汉明距离快速查找近似重复文件 */
+type FilePHashBand struct {
+	ID     uint   `gorm:"primarykey" json:"id"`
+	FileID string `gorm:"size:32;not null;index:idx_file_phash_band_file" json:"file_id"`
+	UserID uint   `gorm:"not null;index:idx_file_phash_band_user,priority:1" json:"user_id"`
+	Band   uint8  `gorm:"not null;index:idx_file_phash_band_user,priority:2;index:idx_file_phash_band_value,priority:1" json:"band"`
+	Value  uint8  `gorm:"not null;index:idx_file_phash_band_user,priority:3;index:idx_file_phash_band_value,priority:2" json:"value"`
+}
+
+func (FilePHashBand) TableName() string {
+	return "file_phash_band"
+}
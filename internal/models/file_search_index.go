@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+/* FileSearchDocument 全文检索文档记录，标记文件最近一次建立索引的时间 */
+type FileSearchDocument struct {
+	FileID    string    `gorm:"primarykey;size:32" json:"file_id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	TermCount int       `json:"term_count"`
+	IndexedAt time.Time `gorm:"index" json:"indexed_at"`
+}
+
+func (FileSearchDocument) TableName() string {
+	return "file_search_document"
+}
+
+/* FileSearchTerm 全文检索倒排表，每个文件的每个词项一行 */
+type FileSearchTerm struct {
+	ID     uint    `gorm:"primarykey" json:"id"`
+	UserID uint    `gorm:"not null;index:idx_search_term_user_term,priority:1" json:"user_id"`
+	Term   string  `gorm:"size:64;not null;index:idx_search_term_user_term,priority:2" json:"term"`
+	FileID string  `gorm:"size:32;not null;index" json:"file_id"`
+	Weight float64 `json:"weight"` // 按字段权重与词频累计的得分
+}
+
+func (FileSearchTerm) TableName() string {
+	return "file_search_term"
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+)
+
+/* FileVariant 按需生成的衍生图（缩放/裁剪/转码）缓存记录 */
+type FileVariant struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+
+	FileID            string `gorm:"size:32;not null;uniqueIndex:idx_file_variant_key" json:"file_id"`
+	VariantKey        string `gorm:"size:32;not null;uniqueIndex:idx_file_variant_key" json:"variant_key"` // 参数摘要
+	Params            string `gorm:"size:100" json:"params"`                                               // 原始参数，便于排查
+	StorageProviderID string `gorm:"size:36" json:"storage_provider_id"`
+	ObjectKey         string `gorm:"size:255;not null" json:"object_key"` // 存储适配器中的对象键
+	Format            string `gorm:"size:10" json:"format"`
+	Width             int    `json:"width"`
+	Height            int    `json:"height"`
+	Size              int64  `json:"size"`
+}
+
+func (FileVariant) TableName() string {
+	return "file_variant"
+}
@@ -22,6 +22,8 @@ type Folder struct {
 	Description   string `gorm:"size:500" json:"description"`                              // 文件夹描述
 	IsRecommended bool   `gorm:"default:false;index" json:"is_recommended"`                // 是否是精选资源
 	SortOrder     int    `gorm:"default:0" json:"sort_order"`                              // 排序值
+
+	WorkspaceID string `gorm:"size:32;index" json:"workspace_id,omitempty"` // 所属工作区，空表示个人文件夹
 }
 
 func (Folder) TableName() string {
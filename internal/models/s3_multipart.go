@@ -0,0 +1,49 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+/* S3MultipartUpload S3 兼容接口的分片上传会话，分片内容暂存在本地临时目录 */
+type S3MultipartUpload struct {
+	ID        string          `gorm:"primarykey;size:32" json:"id"`
+	CreatedAt common.JSONTime `gorm:"index" json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	UserID      uint   `gorm:"not null;index" json:"user_id"`
+	APIKeyID    string `gorm:"size:32;not null;index" json:"api_key_id"`
+	Bucket      string `gorm:"size:100;not null" json:"bucket"`
+	ObjectKey   string `gorm:"size:1024;not null" json:"object_key"`
+	ContentType string `gorm:"size:100" json:"content_type"`
+	AccessLevel string `gorm:"size:20" json:"access_level"`
+}
+
+func (S3MultipartUpload) TableName() string {
+	return "s3_multipart_upload"
+}
+
+func (u *S3MultipartUpload) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == "" {
+		u.ID = strings.ReplaceAll(uuid.New().String(), "-", "")
+	}
+	return nil
+}
+
+/* S3MultipartPart 已上传的分片 */
+type S3MultipartPart struct {
+	ID         uint            `gorm:"primarykey" json:"id"`
+	CreatedAt  common.JSONTime `json:"created_at"`
+	UpdatedAt  common.JSONTime `json:"updated_at"`
+	UploadID   string          `gorm:"size:32;not null;uniqueIndex:idx_s3_part_upload_number" json:"upload_id"`
+	PartNumber int             `gorm:"not null;uniqueIndex:idx_s3_part_upload_number" json:"part_number"`
+	ETag       string          `gorm:"size:32;not null" json:"etag"` // 分片内容的MD5
+	Size       int64           `gorm:"not null" json:"size"`
+}
+
+func (S3MultipartPart) TableName() string {
+	return "s3_multipart_part"
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"pixelpunk/pkg/common"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -19,6 +20,8 @@ type Share struct {
 	Description string `gorm:"type:text" json:"description"`
 	Password    string `gorm:"size:100" json:"-"` // 访问密码(可选)，不返回到前端
 
+	ShareType string `gorm:"size:16;default:view;index" json:"share_type"` // view 浏览分享，upload 文件收集
+
 	ExpiredDays int              `gorm:"default:0" json:"expired_days"` // 过期天数(0表示永不过期)
 	ExpiredAt   *common.JSONTime `json:"expired_at"`                    // 计算得出的过期时间
 
@@ -30,6 +33,14 @@ type Share struct {
 	CollectVisitorInfo    bool `gorm:"default:false" json:"collect_visitor_info"`   // 是否收集访客信息
 	NotificationOnAccess  bool `gorm:"default:false" json:"notification_on_access"` // 是否在被访问时通知创建者
 	NotificationThreshold int  `gorm:"default:100" json:"notification_threshold"`   // 访问通知阈值，默认100次
+
+	UploadFolderID       string `gorm:"size:32" json:"upload_folder_id"`        // 文件收集的目标文件夹，空表示根目录
+	UploadMaxFiles       int    `gorm:"default:0" json:"upload_max_files"`      // 最多接收文件数(0表示不限制)
+	UploadedFiles        int    `gorm:"default:0" json:"uploaded_files"`        // 已接收文件数
+	UploadMaxFileSize    int    `gorm:"default:0" json:"upload_max_file_size"`  // 单文件大小上限MB(0表示使用系统限制)
+	UploadAllowedFormats string `gorm:"size:255" json:"upload_allowed_formats"` // 允许的格式，逗号分隔，空表示使用系统限制
+
+	WorkspaceID string `gorm:"size:32;index" json:"workspace_id,omitempty"` // 分享工作区内容时记录所属工作区
 }
 
 func (Share) TableName() string {
@@ -47,6 +58,10 @@ func (s *Share) BeforeCreate(tx *gorm.DB) error {
 		s.Status = common.ShareStatusNormal
 	}
 
+	if s.ShareType == "" {
+		s.ShareType = common.ShareTypeView
+	}
+
 	return nil
 }
 
@@ -84,3 +99,22 @@ func (s *Share) CanAccessWithPassword(password string) bool {
 
 	return s.Password == password
 }
+
+/* IsUploadShare 判断是否为文件收集分享 */
+func (s *Share) IsUploadShare() bool {
+	return s.ShareType == common.ShareTypeUpload
+}
+
+/* AllowsUploadFormat 判断文件格式是否在允许列表中，未设置时不限制 */
+func (s *Share) AllowsUploadFormat(format string) bool {
+	if s.UploadAllowedFormats == "" {
+		return true
+	}
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+	for _, f := range strings.Split(s.UploadAllowedFormats, ",") {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
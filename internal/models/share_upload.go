@@ -0,0 +1,23 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+)
+
+/* ShareUpload 文件收集分享的上传记录 */
+type ShareUpload struct {
+	ID        string          `gorm:"primarykey;size:32" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+
+	ShareID      string `gorm:"size:32;index" json:"share_id"` // 关联的分享ID
+	FileID       string `gorm:"size:32;index" json:"file_id"`  // 上传生成的文件ID
+	VisitorName  string `gorm:"size:100" json:"visitor_name"`  // 上传者姓名
+	VisitorEmail string `gorm:"size:100" json:"visitor_email"` // 上传者邮箱
+	IPAddress    string `gorm:"size:50" json:"ip_address"`     // 上传者IP地址
+	FileName     string `gorm:"size:255" json:"file_name"`     // 原始文件名
+	FileSize     int64  `json:"file_size"`                     // 文件大小
+}
+
+func (ShareUpload) TableName() string {
+	return "share_upload"
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+/* SignedLink 可撤销的文件签名链接（支持IP绑定、来源限制与次数限制） */
+type SignedLink struct {
+	ID        string          `gorm:"primarykey;size:32" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	UserID    uint            `gorm:"not null;index" json:"user_id"`
+	FileID    string          `gorm:"size:32;not null;index" json:"file_id"`
+	ExpiresAt common.JSONTime `gorm:"index" json:"expires_at"`
+
+	MaxUses  int `gorm:"default:0" json:"max_uses"`  // 最大使用次数(0表示不限制)
+	UseCount int `gorm:"default:0" json:"use_count"` // 已使用次数
+
+	AllowedIPs      string `gorm:"size:500" json:"allowed_ips"`      // 允许的IP/CIDR，逗号分隔
+	AllowedReferers string `gorm:"size:500" json:"allowed_referers"` // 允许的来源域名，逗号分隔
+	Native          bool   `gorm:"default:false" json:"native"`      // 是否为存储渠道原生预签名链接
+	Note            string `gorm:"size:255" json:"note"`
+
+	Revoked    bool             `gorm:"default:false;index" json:"revoked"`
+	RevokedAt  *common.JSONTime `json:"revoked_at"`
+	LastUsedAt *common.JSONTime `json:"last_used_at"`
+	LastUsedIP string           `gorm:"size:50" json:"last_used_ip"`
+}
+
+func (SignedLink) TableName() string {
+	return "signed_link"
+}
+
+func (l *SignedLink) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == "" {
+		l.ID = strings.ReplaceAll(uuid.New().String(), "-", "")
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	StorageMigrationStatusPending   = "pending"
+	StorageMigrationStatusRunning   = "running"
+	StorageMigrationStatusPaused    = "paused"
+	StorageMigrationStatusCompleted = "completed"
+	StorageMigrationStatusFailed    = "failed"
+	StorageMigrationStatusCancelled = "cancelled"
+)
+
+/* StorageMigrationJob 存储渠道迁移任务（将文件从一个渠道复制到另一个渠道） */
+type StorageMigrationJob struct {
+	ID        string          `gorm:"primarykey;size:32" json:"id"`
+	CreatedAt common.JSONTime `gorm:"index" json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	SourceChannelID string `gorm:"size:36;not null;index" json:"source_channel_id"`
+	TargetChannelID string `gorm:"size:36;not null" json:"target_channel_id"`
+	CreatedBy       uint   `json:"created_by"`
+	Status          string `gorm:"size:20;not null;default:pending;index" json:"status"`
+
+	DryRun       bool `gorm:"default:false" json:"dry_run"`       // 仅校验源文件可读，不写入目标渠道
+	DeleteSource bool `gorm:"default:false" json:"delete_source"` // 校验通过后删除源文件
+	ThrottleMs   int  `gorm:"default:0" json:"throttle_ms"`       // 每个文件之间的间隔（毫秒）
+
+	Total       int64  `gorm:"default:0" json:"total"`
+	Processed   int64  `gorm:"default:0" json:"processed"`
+	Succeeded   int64  `gorm:"default:0" json:"succeeded"`
+	Failed      int64  `gorm:"default:0" json:"failed"`
+	BytesCopied int64  `gorm:"default:0" json:"bytes_copied"`
+	Cursor      string `gorm:"size:32" json:"cursor"` // 已处理的最后一个文件ID，用于断点续传
+	LastError   string `gorm:"size:500" json:"last_error"`
+
+	StartedAt  *common.JSONTime `json:"started_at"`
+	FinishedAt *common.JSONTime `json:"finished_at"`
+}
+
+func (StorageMigrationJob) TableName() string {
+	return "storage_migration_job"
+}
+
+func (j *StorageMigrationJob) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == "" {
+		j.ID = strings.ReplaceAll(uuid.New().String(), "-", "")
+	}
+	return nil
+}
+
+/* StorageMigrationFailure 迁移失败的文件记录 */
+type StorageMigrationFailure struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+
+	JobID  string `gorm:"size:32;not null;index" json:"job_id"`
+	FileID string `gorm:"size:32;not null" json:"file_id"`
+	Error  string `gorm:"size:500" json:"error"`
+}
+
+func (StorageMigrationFailure) TableName() string {
+	return "storage_migration_failure"
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+)
+
+const (
+	ReplicationScopeFile   = "file"
+	ReplicationScopeFolder = "folder"
+	ReplicationScopeUser   = "user"
+
+	FileReplicaStatusPending = "pending"
+	FileReplicaStatusReady   = "ready"
+	FileReplicaStatusFailed  = "failed"
+)
+
+/* ReplicationPolicy 多渠道副本策略，作用于单个文件、文件夹（含子文件夹）或用户的全部文件 */
+type ReplicationPolicy struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	UserID     uint   `gorm:"not null;index" json:"user_id"`
+	ScopeType  string `gorm:"size:20;not null;uniqueIndex:idx_replication_policy_scope" json:"scope_type"`
+	ScopeID    string `gorm:"size:36;not null;uniqueIndex:idx_replication_policy_scope" json:"scope_id"`
+	ChannelIDs string `gorm:"size:500;not null" json:"channel_ids"` // 需要保存副本的渠道ID，逗号分隔
+}
+
+func (ReplicationPolicy) TableName() string {
+	return "replication_policy"
+}
+
+/* FileReplica 文件在非主渠道上的副本 */
+type FileReplica struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	FileID    string `gorm:"size:32;not null;uniqueIndex:idx_file_replica_target" json:"file_id"`
+	ChannelID string `gorm:"size:36;not null;uniqueIndex:idx_file_replica_target;index" json:"channel_id"`
+	Status    string `gorm:"size:20;not null;default:pending;index" json:"status"`
+
+	URL            string `gorm:"size:255" json:"url"`
+	RemoteURL      string `gorm:"size:255" json:"remote_url"`
+	LocalFilePath  string `gorm:"size:255" json:"local_file_path"`
+	ThumbURL       string `gorm:"size:255" json:"thumb_url"`
+	RemoteThumbURL string `gorm:"size:255" json:"remote_thumb_url"`
+	LocalThumbPath string `gorm:"size:255" json:"local_thumb_path"`
+	Size           int64  `json:"size"`
+
+	Attempts      int              `gorm:"default:0" json:"attempts"`
+	LastError     string           `gorm:"size:500" json:"last_error"`
+	LastCheckedAt *common.JSONTime `json:"last_checked_at"`
+}
+
+func (FileReplica) TableName() string {
+	return "file_replica"
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 回收站条目类型
+const (
+	TrashItemTypeFile   = "file"
+	TrashItemTypeFolder = "folder"
+)
+
+/* TrashItem 回收站条目，删除文件夹时其子文件夹与文件作为子条目一并记录 */
+type TrashItem struct {
+	ID        string          `gorm:"primarykey;size:32" json:"id"`
+	CreatedAt common.JSONTime `gorm:"index" json:"created_at"` // 删除时间
+
+	UserID        uint   `gorm:"not null;index" json:"user_id"`
+	ItemType      string `gorm:"size:10;not null;index:idx_trash_item_target,priority:1" json:"item_type"` // file 或 folder
+	ItemID        string `gorm:"size:32;not null;index:idx_trash_item_target,priority:2" json:"item_id"`
+	ParentTrashID string `gorm:"size:32;index" json:"-"` // 所属的顶层条目，为空表示顶层条目
+	Name          string `gorm:"size:255" json:"name"`
+	Size          int64  `gorm:"default:0" json:"size"`       // 文件大小，文件夹为其中文件总大小
+	FileCount     int    `gorm:"default:0" json:"file_count"` // 文件夹中的文件数量
+
+	OriginalParentID string          `gorm:"size:32" json:"original_parent_id"` // 原所在文件夹
+	OriginalPath     string          `gorm:"size:1000" json:"original_path"`    // 原所在文件夹路径，用于重建已不存在的父文件夹
+	PrevStatus       string          `gorm:"size:20" json:"-"`                  // 删除前的文件状态
+	ExpiresAt        common.JSONTime `gorm:"index" json:"expires_at"`           // 到期后由定时任务彻底清除
+}
+
+func (TrashItem) TableName() string {
+	return "trash_item"
+}
+
+func (t *TrashItem) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = strings.ReplaceAll(uuid.New().String(), "-", "")
+	}
+	return nil
+}
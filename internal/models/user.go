@@ -28,6 +28,8 @@ type User struct {
 
 	LastActivityAt *common.JSONTime `gorm:"column:last_activity_at" json:"last_activity_at"`
 	LastActivityIP string           `gorm:"size:45;column:last_activity_ip" json:"last_activity_ip"` // 支持IPv6
+
+	AdminRoleID uint `gorm:"default:0;index" json:"admin_role_id"` // 后台管理角色，0 表示按 Role 使用内置角色
 }
 
 func (User) TableName() string {
@@ -20,9 +20,10 @@ type UserAccessControl struct {
 
 	ControlMode string `json:"control_mode"` // 控制模式: strict(严格), moderate(适中), loose(宽松)
 
-	BlockAction        string `json:"block_action"`         // 阻止行为: block(直接阻止), redirect(重定向), watermark(添加水印)
-	RedirectURL        string `json:"redirect_url"`         // 重定向URL
-	CustomErrorMessage string `json:"custom_error_message"` // 自定义错误信息
+	BlockAction        string `json:"block_action"`                      // 阻止行为: block(直接阻止), redirect(重定向), watermark(添加水印)
+	RedirectURL        string `json:"redirect_url"`                      // 重定向URL
+	CustomErrorMessage string `json:"custom_error_message"`              // 自定义错误信息
+	WatermarkConfig    string `gorm:"type:text" json:"watermark_config"` // 盗链水印配置JSON，为空使用默认文字水印，文字支持 {domain} 占位符
 }
 
 func (UserAccessControl) TableName() string {
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+)
+
+/* UserIdentity 用户关联的第三方登录身份，同一提供方下以 subject 唯一标识（取代 user 表中按提供方分列的 github_id 等字段） */
+type UserIdentity struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	UserID      uint             `gorm:"not null;index" json:"user_id"`
+	Provider    string           `gorm:"size:50;not null;uniqueIndex:idx_identity_provider_subject" json:"provider"` // github/google/linuxdo 或自定义 OIDC 提供方标识
+	Subject     string           `gorm:"size:255;not null;uniqueIndex:idx_identity_provider_subject" json:"-"`       // 提供方返回的用户唯一ID
+	Email       string           `gorm:"size:100" json:"email"`
+	Username    string           `gorm:"size:100" json:"username"`
+	LastLoginAt *common.JSONTime `json:"last_login_at"`
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identity"
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+	"time"
+)
+
+/* UserSession 用户登录会话，保存刷新令牌摘要与设备信息 */
+type UserSession struct {
+	ID        string          `gorm:"primarykey;size:32" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	UserID            uint             `gorm:"not null;index" json:"user_id"`
+	RefreshTokenHash  string           `gorm:"size:64;not null" json:"-"` // 当前刷新令牌的 SHA-256 摘要
+	PreviousTokenHash string           `gorm:"size:64" json:"-"`          // 上一个刷新令牌摘要，再次出现视为令牌泄露
+	DeviceName        string           `gorm:"size:100" json:"device_name"`
+	UserAgent         string           `gorm:"size:500" json:"user_agent"`
+	IPAddress         string           `gorm:"size:45" json:"ip_address"`
+	LastSeenAt        common.JSONTime  `json:"last_seen_at"`
+	ExpiresAt         time.Time        `gorm:"not null;index" json:"expires_at"`
+	RevokedAt         *common.JSONTime `gorm:"index" json:"revoked_at"`
+}
+
+func (UserSession) TableName() string {
+	return "user_session"
+}
+
+/* IsActive 会话未撤销且刷新令牌未过期 */
+func (s *UserSession) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+)
+
+/* UserTwoFactor 用户两步验证(TOTP)配置 */
+type UserTwoFactor struct {
+	UserID        uint             `gorm:"primarykey;autoIncrement:false" json:"user_id"`
+	CreatedAt     common.JSONTime  `json:"created_at"`
+	UpdatedAt     common.JSONTime  `json:"updated_at"`
+	Secret        string           `gorm:"size:64;not null" json:"-"`          // Base32 编码的 TOTP 密钥
+	Enabled       bool             `gorm:"default:false;index" json:"enabled"` // 确认绑定后才生效
+	EnabledAt     *common.JSONTime `json:"enabled_at"`
+	RecoveryCodes string           `gorm:"type:text" json:"-"` // 恢复码的 SHA-256 摘要(JSON 数组)，使用后移除
+	LastUsedStep  int64            `gorm:"default:0" json:"-"` // 最近一次通过验证的时间步，防止验证码重放
+}
+
+func (UserTwoFactor) TableName() string {
+	return "user_two_factor"
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+)
+
+const (
+	WatermarkScopeUser   = "user"
+	WatermarkScopeFolder = "folder"
+	WatermarkScopeAPIKey = "apikey"
+)
+
+/* WatermarkPolicy 访问时水印策略，作用于用户全部文件、文件夹（含子文件夹）或某个API密钥上传的文件；原图保持不变 */
+type WatermarkPolicy struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	UserID    uint   `gorm:"not null;index" json:"user_id"`
+	Name      string `gorm:"size:100" json:"name"`
+	ScopeType string `gorm:"size:20;not null;uniqueIndex:idx_watermark_policy_scope" json:"scope_type"`
+	ScopeID   string `gorm:"size:36;not null;uniqueIndex:idx_watermark_policy_scope" json:"scope_id"`
+	Config    string `gorm:"type:text;not null" json:"config"` // watermark.WatermarkConfig 的 JSON
+	Enabled   bool   `gorm:"index" json:"enabled"`
+}
+
+func (WatermarkPolicy) TableName() string {
+	return "watermark_policy"
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"pixelpunk/pkg/common"
+)
+
+const (
+	WebhookScopeUser  = "user"  // 仅接收订阅者本人的事件
+	WebhookScopeAdmin = "admin" // 接收全站事件
+
+	WebhookDeliveryQueued     = "queued"
+	WebhookDeliveryProcessing = "processing"
+	WebhookDeliveryDone       = "done"
+	WebhookDeliveryFailed     = "failed" // 超过最大重试次数，进入死信
+)
+
+/* Webhook 出站回调订阅 */
+type Webhook struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	UserID      uint   `gorm:"not null;index" json:"user_id"`
+	Scope       string `gorm:"size:20;not null;default:'user';index" json:"scope"`
+	Name        string `gorm:"size:100;not null" json:"name"`
+	URL         string `gorm:"size:1000;not null" json:"url"`
+	Secret      string `gorm:"size:100;not null" json:"-"`      // HMAC-SHA256 签名密钥
+	Events      string `gorm:"size:500;not null" json:"events"` // 订阅的事件，逗号分隔，* 表示全部
+	Enabled     bool   `gorm:"index" json:"enabled"`
+	Description string `gorm:"size:255" json:"description"`
+
+	LastDeliveryAt *time.Time `json:"last_delivery_at"`
+	LastStatus     string     `gorm:"size:20" json:"last_status"` // 最近一次投递结果: done/failed
+}
+
+func (Webhook) TableName() string {
+	return "webhook"
+}
+
+/* WebhookDelivery 一次事件投递，同时作为投递队列的任务行 */
+type WebhookDelivery struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	WebhookID uint   `gorm:"not null;index" json:"webhook_id"`
+	EventID   string `gorm:"size:32;not null;index" json:"event_id"`
+	Event     string `gorm:"size:50;not null;index" json:"event"`
+	Payload   string `gorm:"type:text;not null" json:"payload"`
+
+	Status         string     `gorm:"size:20;not null;index" json:"status"` // queued|processing|done|failed
+	Attempt        int        `gorm:"default:0" json:"attempt"`
+	LeaseUntil     *time.Time `gorm:"index" json:"lease_until"`
+	LeaseBy        string     `gorm:"size:64" json:"lease_by"`
+	LastError      string     `gorm:"type:text" json:"last_error"`
+	LastStatusCode int        `json:"last_status_code"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_delivery"
+}
+
+/* WebhookDeliveryAttempt 单次投递尝试记录 */
+type WebhookDeliveryAttempt struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+
+	DeliveryID   uint   `gorm:"not null;index" json:"delivery_id"`
+	WebhookID    uint   `gorm:"not null;index" json:"webhook_id"`
+	Attempt      int    `gorm:"not null" json:"attempt"`
+	StatusCode   int    `json:"status_code"` // 0 表示未收到响应
+	LatencyMs    int64  `json:"latency_ms"`
+	Error        string `gorm:"type:text" json:"error"`
+	ResponseBody string `gorm:"type:text" json:"response_body"` // 截断保存
+}
+
+func (WebhookDeliveryAttempt) TableName() string {
+	return "webhook_delivery_attempt"
+}
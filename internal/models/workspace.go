@@ -0,0 +1,74 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+)
+
+// 工作区成员角色
+const (
+	WorkspaceRoleOwner    = "owner"    // 所有者：全部权限，可管理成员与删除工作区
+	WorkspaceRoleEditor   = "editor"   // 编辑者：上传、整理、修改和分享任意文件
+	WorkspaceRoleUploader = "uploader" // 上传者：浏览、上传，只能修改自己上传的文件
+	WorkspaceRoleViewer   = "viewer"   // 查看者：只读
+)
+
+/* DefaultWorkspaceStorageLimit 新建工作区的默认共享配额 1GB */
+const DefaultWorkspaceStorageLimit int64 = 1024 * 1024 * 1024
+
+/* MaxOwnedWorkspaces 每个用户最多拥有的工作区数量 */
+const MaxOwnedWorkspaces = 10
+
+/* Workspace 团队工作区：拥有独立的共享文件夹树和存储配额，成员按角色协作 */
+type Workspace struct {
+	ID        string          `gorm:"primarykey;size:32" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	Name         string `gorm:"size:100;not null" json:"name"`
+	Description  string `gorm:"size:500" json:"description"`
+	OwnerID      uint   `gorm:"not null;index" json:"owner_id"`
+	RootFolderID string `gorm:"size:32;index" json:"root_folder_id"` // 工作区根文件夹，成员在其下协作
+	StorageLimit int64  `gorm:"default:0" json:"storage_limit"`      // 共享存储配额(bytes)，0表示不限制，与成员个人配额相互独立，由管理员调整
+}
+
+func (Workspace) TableName() string {
+	return "workspace"
+}
+
+/* WorkspaceMember 工作区成员 */
+type WorkspaceMember struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	WorkspaceID string `gorm:"size:32;not null;uniqueIndex:idx_workspace_member" json:"workspace_id"`
+	UserID      uint   `gorm:"not null;uniqueIndex:idx_workspace_member;index" json:"user_id"`
+	Role        string `gorm:"size:16;not null;default:viewer" json:"role"`
+	InvitedBy   uint   `json:"invited_by"`
+
+	User *User `gorm:"foreignKey:UserID;references:ID" json:"user,omitempty"`
+}
+
+func (WorkspaceMember) TableName() string {
+	return "workspace_member"
+}
+
+/* WorkspaceActivity 工作区活动记录，按成员归属 */
+type WorkspaceActivity struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `gorm:"index" json:"created_at"`
+
+	WorkspaceID string `gorm:"size:32;not null;index" json:"workspace_id"`
+	UserID      uint   `gorm:"not null;index" json:"user_id"`
+	Action      string `gorm:"size:30;not null" json:"action"` // 操作类型：file_upload / file_delete / folder_create / member_add ...
+	TargetType  string `gorm:"size:20" json:"target_type"`     // 操作对象类型：file / folder / member / share
+	TargetID    string `gorm:"size:100" json:"target_id"`      // 操作对象ID
+	TargetName  string `gorm:"size:255" json:"target_name"`    // 操作对象名称快照
+	Size        int64  `gorm:"default:0" json:"size"`          // 涉及的文件大小(bytes)
+
+	User *User `gorm:"foreignKey:UserID;references:ID" json:"user,omitempty"`
+}
+
+func (WorkspaceActivity) TableName() string {
+	return "workspace_activity"
+}
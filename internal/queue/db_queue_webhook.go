@@ -0,0 +1,112 @@
+package queue
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// DBQueueWebhook 使用 webhook_delivery 表的投递队列，任务的 FileID 字段携带投递记录ID
+type DBQueueWebhook struct{ db *gorm.DB }
+
+func NewDBQueueWebhook() *DBQueueWebhook { return &DBQueueWebhook{db: database.GetDB()} }
+
+// EnqueueUnique 将已存在的投递记录重新置为待投递（用于死信重投），重试计数清零
+func (q *DBQueueWebhook) EnqueueUnique(deliveryID string, priority int) error {
+	if q.db == nil {
+		return errors.New("db not initialized")
+	}
+	id, err := strconv.ParseUint(deliveryID, 10, 64)
+	if err != nil {
+		return err
+	}
+	return q.db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status <> ?", id, models.WebhookDeliveryProcessing).
+		Updates(map[string]interface{}{
+			"status":      models.WebhookDeliveryQueued,
+			"attempt":     0,
+			"lease_until": gorm.Expr("NULL"),
+			"lease_by":    "",
+		}).Error
+}
+
+func (q *DBQueueWebhook) Fetch(lease time.Duration) (*TaggingTask, AckFunc, NackFunc, error) {
+	if q.db == nil {
+		return nil, nil, nil, errors.New("db not initialized")
+	}
+
+	now := time.Now()
+	var candidate models.WebhookDelivery
+	if err := q.db.Select("id").
+		Where("(status = ? AND (lease_until IS NULL OR lease_until < ?)) OR (status = ? AND lease_until < ?)",
+			models.WebhookDeliveryQueued, now, models.WebhookDeliveryProcessing, now).
+		Order("id ASC").Take(&candidate).Error; err != nil {
+		return nil, nil, nil, err
+	}
+
+	result := q.db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND ((status = ? AND (lease_until IS NULL OR lease_until < ?)) OR (status = ? AND lease_until < ?))",
+			candidate.ID, models.WebhookDeliveryQueued, now, models.WebhookDeliveryProcessing, now).
+		Updates(map[string]interface{}{
+			"status":      models.WebhookDeliveryProcessing,
+			"lease_until": now.Add(lease),
+			"lease_by":    "webhook",
+		})
+	if result.Error != nil {
+		return nil, nil, nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil, nil, gorm.ErrRecordNotFound
+	}
+
+	picked := candidate.ID
+	task := &TaggingTask{FileID: strconv.FormatUint(uint64(picked), 10)}
+	ack := func() error {
+		return q.db.Model(&models.WebhookDelivery{}).Where("id = ?", picked).Updates(map[string]interface{}{
+			"status": models.WebhookDeliveryDone, "attempt": gorm.Expr("attempt + 1"), "last_error": "",
+			"delivered_at": time.Now(), "lease_until": gorm.Expr("NULL"), "lease_by": "",
+		}).Error
+	}
+	nack := func(delay time.Duration, toDLQ bool, lastError string) error {
+		if toDLQ {
+			return q.db.Model(&models.WebhookDelivery{}).Where("id = ?", picked).Updates(map[string]interface{}{
+				"status": models.WebhookDeliveryFailed, "attempt": gorm.Expr("attempt + 1"), "last_error": lastError,
+				"lease_until": gorm.Expr("NULL"), "lease_by": "",
+			}).Error
+		}
+		// 延迟期间保持 queued，lease_until 作为下次可取时间
+		return q.db.Model(&models.WebhookDelivery{}).Where("id = ?", picked).Updates(map[string]interface{}{
+			"status": models.WebhookDeliveryQueued, "attempt": gorm.Expr("attempt + 1"), "last_error": lastError,
+			"lease_until": time.Now().Add(delay), "lease_by": "",
+		}).Error
+	}
+	return task, ack, nack, nil
+}
+
+func (q *DBQueueWebhook) Metrics() (*Metrics, error) {
+	if q.db == nil {
+		return nil, errors.New("db not initialized")
+	}
+	var queued, processing, delayed, dlq int64
+	now := time.Now()
+	if err := q.db.Model(&models.WebhookDelivery{}).Where("status = ?", models.WebhookDeliveryQueued).Count(&queued).Error; err != nil {
+		return nil, err
+	}
+	if err := q.db.Model(&models.WebhookDelivery{}).Where("status = ?", models.WebhookDeliveryProcessing).Count(&processing).Error; err != nil {
+		return nil, err
+	}
+	if err := q.db.Model(&models.WebhookDelivery{}).Where("status = ? AND lease_until > ?", models.WebhookDeliveryQueued, now).Count(&delayed).Error; err != nil {
+		return nil, err
+	}
+	if err := q.db.Model(&models.WebhookDelivery{}).Where("status = ?", models.WebhookDeliveryFailed).Count(&dlq).Error; err != nil {
+		return nil, err
+	}
+	return &Metrics{QueueLength: int(queued), InFlight: int(processing), DelayedCount: int(delayed), DLQCount: int(dlq)}, nil
+}
+
+func (q *DBQueueWebhook) Close() error { return nil }
@@ -3,6 +3,7 @@ package routes
 import (
 	adminController "pixelpunk/internal/controllers/admin"
 	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/rbac"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,7 +11,7 @@ import (
 func RegisterAdminContentReviewRoutes(r *gin.RouterGroup) {
 	reviewGroup := r.Group("/content-review")
 	reviewGroup.Use(middleware.RequireAuth())
-	reviewGroup.Use(middleware.RequireAdmin())
+	reviewGroup.Use(middleware.RequirePermission(rbac.PermContentReview))
 	{
 		reviewGroup.GET("/queue", adminController.GetReviewQueue)
 
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	rbacController "pixelpunk/internal/controllers/rbac"
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/rbac"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAdminRoleRoutes(r *gin.RouterGroup) {
+	r.GET("/mine", middleware.RequireAuth(), rbacController.GetMyPermissions)
+
+	manageGroup := r.Group("")
+	manageGroup.Use(middleware.RequirePermission(rbac.PermRoleManage))
+	{
+		manageGroup.GET("/permissions", rbacController.ListPermissions)
+
+		manageGroup.GET("", rbacController.ListRoles)
+
+		manageGroup.POST("", rbacController.CreateRole)
+
+		manageGroup.PUT("/:role_id", rbacController.UpdateRole)
+
+		manageGroup.DELETE("/:role_id", rbacController.DeleteRole)
+
+		manageGroup.POST("/assign", rbacController.AssignRole)
+	}
+}
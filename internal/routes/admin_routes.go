@@ -8,6 +8,7 @@ import (
 	userController "pixelpunk/internal/controllers/user"
 
 	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/rbac"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,7 +20,7 @@ func RegisterAdminRoutes(r *gin.RouterGroup) {
 	}
 
 	statsAdmin := r.Group("/stats")
-	statsAdmin.Use(middleware.RequireAdmin())
+	statsAdmin.Use(middleware.RequirePermission(rbac.PermDashboardView))
 	{
 		statsAdmin.GET("/latest-files", statsController.LatestFiles)
 
@@ -37,22 +38,25 @@ func RegisterAdminRoutes(r *gin.RouterGroup) {
 	}
 
 	userRoutes := r.Group("/user")
-	userRoutes.Use(middleware.RequireAdmin())
+	userRoutes.Use(middleware.RequirePermission(rbac.PermUserView))
+	manageUsers := middleware.RequirePermission(rbac.PermUserManage)
 	{
 		userRoutes.GET("/list", userController.AdminGetUserList)
-		userRoutes.POST("/create", middleware.RequireSuperAdmin(), userController.AdminCreateUser)
+		userRoutes.POST("/create", manageUsers, userController.AdminCreateUser)
 		userRoutes.GET("/detail/:id", userController.AdminGetUserDetail)
-		userRoutes.POST("/update", middleware.RequireSuperAdmin(), userController.AdminUpdateUser)
-		userRoutes.POST("/storage", middleware.RequireSuperAdmin(), userController.AdminUpdateUserStorage)
-		userRoutes.POST("/reset-password/:id", middleware.RequireSuperAdmin(), userController.AdminResetUserPassword)
-		userRoutes.POST("/send-email", middleware.RequireSuperAdmin(), userController.AdminSendUserEmail)
-		userRoutes.POST("/toggle-status", middleware.RequireSuperAdmin(), userController.AdminToggleUserStatus)
-		userRoutes.POST("/delete/:id", middleware.RequireSuperAdmin(), userController.AdminDeleteUser)
-		userRoutes.POST("/batch", middleware.RequireSuperAdmin(), userController.AdminBatchOperateUsers)
+		userRoutes.POST("/update", manageUsers, userController.AdminUpdateUser)
+		userRoutes.POST("/storage", manageUsers, userController.AdminUpdateUserStorage)
+		userRoutes.POST("/reset-password/:id", manageUsers, userController.AdminResetUserPassword)
+		userRoutes.POST("/two-factor/reset/:id", manageUsers, userController.AdminResetTwoFactor)
+		userRoutes.POST("/sessions/revoke/:id", manageUsers, userController.AdminRevokeUserSessions)
+		userRoutes.POST("/send-email", manageUsers, userController.AdminSendUserEmail)
+		userRoutes.POST("/toggle-status", manageUsers, userController.AdminToggleUserStatus)
+		userRoutes.POST("/delete/:id", manageUsers, userController.AdminDeleteUser)
+		userRoutes.POST("/batch", manageUsers, userController.AdminBatchOperateUsers)
 	}
 
 	imageRoutes := r.Group("/files")
-	imageRoutes.Use(middleware.RequireAdmin())
+	imageRoutes.Use(middleware.RequirePermission(rbac.PermFileManage))
 	{
 		imageRoutes.GET("/list", fileController.AdminGetFileList)
 		imageRoutes.GET("/tags", fileController.AdminGetTagList)
@@ -61,10 +65,14 @@ func RegisterAdminRoutes(r *gin.RouterGroup) {
 		imageRoutes.POST("/batch-recommend", fileController.AdminBatchRecommendFiles)
 		imageRoutes.POST("/delete", fileController.AdminDeleteFile)
 		imageRoutes.POST("/batch-delete", fileController.AdminBatchDeleteFiles)
+		imageRoutes.POST("/signed-links/revoke", fileController.AdminRevokeSignedLinks)
+		imageRoutes.GET("/near-duplicates", fileController.AdminGetNearDuplicateGroups)
+		imageRoutes.GET("/near-duplicates/:file_id", fileController.AdminGetFileNearDuplicates)
+		imageRoutes.POST("/near-duplicates/backfill", fileController.AdminBackfillPerceptualHashes)
 	}
 
 	aiRoutes := r.Group("/ai")
-	aiRoutes.Use(middleware.RequireAdmin())
+	aiRoutes.Use(middleware.RequirePermission(rbac.PermAIManage))
 	{
 		aiRoutes.POST("/trigger-tagging", aiController.TriggerFileTagging)
 
@@ -74,7 +82,7 @@ func RegisterAdminRoutes(r *gin.RouterGroup) {
 	}
 
 	vectorVerificationRoutes := r.Group("/vector-verification")
-	vectorVerificationRoutes.Use(middleware.RequireAdmin())
+	vectorVerificationRoutes.Use(middleware.RequirePermission(rbac.PermAIManage))
 	{
 		controller := adminController.NewVectorVerificationController()
 
@@ -100,7 +108,7 @@ func RegisterAdminRoutes(r *gin.RouterGroup) {
 	}
 
 	fileRoutes := r.Group("/file")
-	fileRoutes.Use(middleware.RequireAdmin())
+	fileRoutes.Use(middleware.RequirePermission(rbac.PermSettingManage))
 	{
 		fileRoutes.POST("/upload", fileController.UploadAdminFile)
 	}
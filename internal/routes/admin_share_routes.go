@@ -3,12 +3,13 @@ package routes
 import (
 	shareController "pixelpunk/internal/controllers/share"
 	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/rbac"
 
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterAdminShareRoutes(r *gin.RouterGroup) {
-	r.Use(middleware.RequireAdmin())
+	r.Use(middleware.RequirePermission(rbac.PermShareManage))
 
 	r.GET("/list", shareController.AdminGetShareList)
 
@@ -3,13 +3,14 @@ package routes
 import (
 	aiController "pixelpunk/internal/controllers/ai"
 	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/rbac"
 
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterAIRoutes(r *gin.RouterGroup) {
 	adminGroup := r.Group("")
-	adminGroup.Use(middleware.RequireAuth(), middleware.RequireAdmin())
+	adminGroup.Use(middleware.RequireAuth(), middleware.RequirePermission(rbac.PermAIManage))
 	{
 		taggingGroup := adminGroup.Group("/tagging")
 		{
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	albumController "pixelpunk/internal/controllers/album"
+	"pixelpunk/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAlbumRoutes(r *gin.RouterGroup) {
+	userAlbumGroup := r.Group("")
+	userAlbumGroup.Use(middleware.RequireAuth())
+
+	userAlbumGroup.POST("", albumController.CreateAlbum)
+
+	userAlbumGroup.GET("", albumController.ListAlbums)
+
+	userAlbumGroup.PUT("/reorder", albumController.ReorderAlbums)
+
+	userAlbumGroup.GET("/:album_id", albumController.GetAlbum)
+
+	userAlbumGroup.PUT("/:album_id", albumController.UpdateAlbum)
+
+	userAlbumGroup.DELETE("/:album_id", albumController.DeleteAlbum)
+
+	userAlbumGroup.GET("/:album_id/files", albumController.ListAlbumFiles)
+
+	userAlbumGroup.POST("/:album_id/files", albumController.AddAlbumFiles)
+
+	userAlbumGroup.POST("/:album_id/files/remove", albumController.RemoveAlbumFiles)
+
+	userAlbumGroup.PUT("/:album_id/files/reorder", albumController.ReorderAlbumFiles)
+
+	userAlbumGroup.PUT("/:album_id/files/:file_id/caption", albumController.UpdateAlbumFileCaption)
+
+	publicGroup := r.Group("/public")
+
+	publicGroup.GET("/user/:username", albumController.ListUserPublicAlbums)
+
+	publicGroup.GET("/:slug", albumController.ViewPublicAlbum)
+}
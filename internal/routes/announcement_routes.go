@@ -3,6 +3,7 @@ package routes
 import (
 	announcementController "pixelpunk/internal/controllers/announcement"
 	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/rbac"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,7 +22,7 @@ func RegisterPublicAnnouncementRoutes(r *gin.RouterGroup) {
 func RegisterAdminAnnouncementRoutes(r *gin.RouterGroup) {
 	// 管理端路由 - 需要管理员权限
 	admin := r.Group("/admin/announcements")
-	admin.Use(middleware.RequireAdmin())
+	admin.Use(middleware.RequirePermission(rbac.PermAnnouncementManage))
 	{
 		admin.POST("", announcementController.CreateAnnouncementHandler)
 		admin.PUT("/:id", announcementController.UpdateAnnouncementHandler)
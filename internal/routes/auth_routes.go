@@ -3,6 +3,7 @@ package routes
 import (
 	oauthController "pixelpunk/internal/controllers/oauth"
 	userController "pixelpunk/internal/controllers/user"
+	"pixelpunk/internal/middleware"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,6 +12,15 @@ import (
 func RegisterAuthRoutes(r *gin.RouterGroup) {
 	r.POST("/register", userController.Register)
 	r.POST("/login", userController.Login)
+	r.POST("/refresh", userController.RefreshToken)
+	r.POST("/logout", middleware.RequireAuth(), userController.Logout)
+
+	twoFactorRoutes := r.Group("/2fa")
+	{
+		twoFactorRoutes.POST("/verify", userController.VerifyTwoFactorLogin)
+		twoFactorRoutes.POST("/setup", userController.SetupTwoFactorByChallenge)
+		twoFactorRoutes.POST("/enable", userController.EnableTwoFactorByChallenge)
+	}
 
 	r.POST("/send-registration-code", userController.SendRegistrationCode)
 	r.POST("/send-reset-password-code", userController.SendResetPasswordCode)
@@ -28,5 +38,8 @@ func RegisterAuthRoutes(r *gin.RouterGroup) {
 		oauthRoutes.POST("/github/login", oauthController.GithubLogin)
 		oauthRoutes.POST("/google/login", oauthController.GoogleLogin)
 		oauthRoutes.POST("/linuxdo/login", oauthController.LinuxdoLogin)
+
+		oauthRoutes.GET("/oidc/:provider/authorize", oauthController.OIDCAuthorize)
+		oauthRoutes.POST("/oidc/:provider/login", oauthController.OIDCLogin)
 	}
 }
@@ -3,6 +3,7 @@ package routes
 import (
 	categoryController "pixelpunk/internal/controllers/category"
 	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/rbac"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,7 +13,7 @@ func RegisterCategoryTemplateRoutes(r *gin.RouterGroup) {
 
 	adminGroup := r.Group("/admin/category-templates")
 	adminGroup.Use(middleware.RequireAuth())
-	adminGroup.Use(middleware.RequireAdmin())
+	adminGroup.Use(middleware.RequirePermission(rbac.PermTagManage))
 	{
 		adminGroup.POST("/create", templateController.CreateTemplate)
 
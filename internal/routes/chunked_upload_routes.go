@@ -3,6 +3,7 @@ package routes
 import (
 	fileController "pixelpunk/internal/controllers/file"
 	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/rbac"
 
 	"github.com/gin-gonic/gin"
 )
@@ -23,7 +24,7 @@ func RegisterChunkedUploadRoutes(r *gin.RouterGroup) {
 	}
 
 	adminGroup := chunked.Group("/admin")
-	adminGroup.Use(middleware.RequireAdmin())
+	adminGroup.Use(middleware.RequirePermission(rbac.PermFileManage))
 	{
 		adminGroup.POST("/cleanup", fileController.ManualCleanupChunkedUploads)
 
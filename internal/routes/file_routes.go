@@ -40,6 +40,28 @@ func RegisterFileRoutes(r *gin.RouterGroup) {
 	authGroup.POST("/move", fileController.MoveFiles)
 
 	authGroup.GET("/:file_id/link", fileController.GenerateFileLink)
+	authGroup.POST("/:file_id/signed-links", fileController.CreateSignedLink)
+	authGroup.POST("/signed-links/batch", fileController.BatchCreateSignedLinks)
+	authGroup.GET("/signed-links", fileController.ListSignedLinks)
+	authGroup.POST("/signed-links/revoke", fileController.RevokeSignedLinks)
+
+	authGroup.GET("/watermark-policies", fileController.ListWatermarkPolicies)
+	authGroup.POST("/watermark-policies", fileController.CreateWatermarkPolicy)
+	authGroup.GET("/watermark-policies/fonts", fileController.GetWatermarkFonts)
+	authGroup.POST("/watermark-policies/preview", fileController.PreviewWatermark)
+	authGroup.GET("/watermark-policies/:policy_id", fileController.GetWatermarkPolicy)
+	authGroup.PUT("/watermark-policies/:policy_id", fileController.UpdateWatermarkPolicy)
+	authGroup.DELETE("/watermark-policies/:policy_id", fileController.DeleteWatermarkPolicy)
+
+	authGroup.GET("/near-duplicates", fileController.GetNearDuplicateGroups)
+	authGroup.POST("/near-duplicates/merge", fileController.MergeNearDuplicates)
+	authGroup.GET("/:file_id/near-duplicates", fileController.GetFileNearDuplicates)
+
+	authGroup.GET("/trash", fileController.ListTrash)
+	authGroup.POST("/trash/restore", fileController.RestoreTrashItems)
+	authGroup.POST("/trash/purge", fileController.PurgeTrashItems)
+	authGroup.POST("/trash/empty", fileController.EmptyTrash)
+
 	authGroup.POST("/:file_id/toggle-access-level", fileController.ToggleAccessLevel)
 
 	authGroup.GET("/:file_id", fileController.GetFileDetail)
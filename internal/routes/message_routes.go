@@ -3,6 +3,7 @@ package routes
 import (
 	messageController "pixelpunk/internal/controllers/message"
 	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/rbac"
 
 	"github.com/gin-gonic/gin"
 )
@@ -24,7 +25,7 @@ func RegisterMessageRoutes(r *gin.RouterGroup) {
 
 	adminMessageGroup := r.Group("/admin/messages")
 	adminMessageGroup.Use(middleware.RequireAuth())
-	adminMessageGroup.Use(middleware.RequireAdmin())
+	adminMessageGroup.Use(middleware.RequirePermission(rbac.PermMessageManage))
 	{
 		adminMessageGroup.GET("/templates", messageController.GetAllTemplates)
 
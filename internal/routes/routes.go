@@ -111,6 +111,18 @@ func RegisterRoutes(r *gin.Engine) {
 	shareRoutes := version.Group("/shares")
 	RegisterShareRoutes(shareRoutes)
 
+	albumRoutes := version.Group("/albums")
+	RegisterAlbumRoutes(albumRoutes)
+
+	workspaceRoutes := version.Group("/workspaces")
+	RegisterWorkspaceRoutes(workspaceRoutes)
+
+	adminWorkspaceRoutes := version.Group("/admin/workspaces")
+	RegisterAdminWorkspaceRoutes(adminWorkspaceRoutes)
+
+	adminRoleRoutes := version.Group("/admin/roles")
+	RegisterAdminRoleRoutes(adminRoleRoutes)
+
 	RegisterSearchRoutes(version)
 
 	vectorRoutes := version.Group("/admin")
@@ -118,6 +130,8 @@ func RegisterRoutes(r *gin.Engine) {
 
 	RegisterMessageRoutes(version)
 
+	RegisterWebhookRoutes(version)
+
 	// 注册公告管理端路由（需要管理员权限）
 	RegisterAdminAnnouncementRoutes(version)
 
@@ -158,6 +172,10 @@ func RegisterRoutes(r *gin.Engine) {
 	apiUploadRoutes.Use(middleware.APIKeyAuthMiddleware())
 	apiUploadRoutes.POST("/upload", fileController.UploadForApiKey)
 
+	RegisterS3GatewayRoutes(r)
+
+	RegisterWebDAVRoutes(r)
+
 	// 随机图片API公开接口（不需要认证）
 	randomImageRoutes := r.Group("/api/v1/r")
 	randomImageRoutes.GET("/:api_key", randomAPIController.GetRandomImage)
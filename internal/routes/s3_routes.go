@@ -0,0 +1,13 @@
+package routes
+
+import (
+	s3Controller "pixelpunk/internal/controllers/s3gateway"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterS3GatewayRoutes 注册 S3 兼容接口（路径风格，SigV4 认证由处理器完成）
+func RegisterS3GatewayRoutes(r *gin.Engine) {
+	r.Any("/s3", s3Controller.Handle)
+	r.Any("/s3/*path", s3Controller.Handle)
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	searchController "pixelpunk/internal/controllers/search"
 	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/rbac"
 
 	"github.com/gin-gonic/gin"
 )
@@ -31,6 +32,12 @@ func RegisterSearchRoutes(r *gin.RouterGroup) {
 		userGroup.Use(middleware.RequireAuth())
 		{
 			userGroup.POST("/vector/search", searchController.UserVectorSearch)
+
+			userGroup.POST("/text/search", searchController.UserTextSearch)
+
+			userGroup.POST("/hybrid/search", searchController.UserHybridSearch)
+
+			userGroup.POST("/image/search", searchController.UserImageSearch)
 		}
 
 		galleryGroup := searchGroup.Group("/gallery")
@@ -48,7 +55,7 @@ func RegisterSearchRoutes(r *gin.RouterGroup) {
 
 		adminSimilarGroup := searchGroup.Group("/admin")
 		adminSimilarGroup.Use(middleware.RequireAuth())
-		adminSimilarGroup.Use(middleware.RequireAdmin())
+		adminSimilarGroup.Use(middleware.RequirePermission(rbac.PermFileManage))
 		{
 			adminSimilarGroup.GET("/similar/:fileId", searchController.AdminSimilarFiles)
 		}
@@ -3,13 +3,14 @@ package routes
 import (
 	settingController "pixelpunk/internal/controllers/setting"
 	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/rbac"
 
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterSettingRoutes(r *gin.RouterGroup) {
 	r.Use(middleware.RequireAuth())
-	r.Use(middleware.RequireAdmin())
+	r.Use(middleware.RequirePermission(rbac.PermSettingManage))
 	{
 		r.GET("", settingController.GetSettings)
 
@@ -22,6 +22,8 @@ func RegisterShareRoutes(r *gin.RouterGroup) {
 
 	userShareGroup.DELETE("/:id/visitors/:visitor_id", shareController.DeleteShareVisitor)
 
+	userShareGroup.GET("/:id/uploads", shareController.GetShareUploads)
+
 	userShareGroup.DELETE("/:id", shareController.DeleteShare)
 
 	publicGroup := r.Group("/public")
@@ -33,4 +35,6 @@ func RegisterShareRoutes(r *gin.RouterGroup) {
 	publicGroup.POST("/:key/visitor", shareController.SubmitVisitorInfo)
 
 	publicGroup.GET("/:key/files/:file_id/download", shareController.DownloadSharedFile)
+
+	publicGroup.POST("/:key/upload", middleware.UploadConcurrencyLimit(), shareController.UploadToShare)
 }
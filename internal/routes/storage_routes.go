@@ -3,12 +3,13 @@ package routes
 import (
 	storageController "pixelpunk/internal/controllers/storage"
 	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/rbac"
 
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterStorageRoutes(r *gin.RouterGroup) {
-	r.Use(middleware.RequireAdmin())
+	r.Use(middleware.RequirePermission(rbac.PermStorageManage))
 
 	r.GET("/list", storageController.ListChannels)
 
@@ -18,6 +19,20 @@ func RegisterStorageRoutes(r *gin.RouterGroup) {
 	r.GET("/config-templates/", storageController.GetConfigTemplates)
 	r.GET("/config-templates/:type", storageController.GetConfigTemplates)
 
+	r.GET("/migrations", storageController.ListMigrations)
+	r.POST("/migrations", storageController.CreateMigration)
+	r.GET("/migrations/:job_id", storageController.GetMigration)
+	r.GET("/migrations/:job_id/failures", storageController.ListMigrationFailures)
+	r.POST("/migrations/:job_id/pause", storageController.PauseMigration)
+	r.POST("/migrations/:job_id/resume", storageController.ResumeMigration)
+	r.POST("/migrations/:job_id/cancel", storageController.CancelMigration)
+
+	r.GET("/replication/policies", storageController.ListReplicationPolicies)
+	r.POST("/replication/policies", storageController.SetReplicationPolicy)
+	r.DELETE("/replication/policies/:policy_id", storageController.DeleteReplicationPolicy)
+	r.GET("/replication/files/:file_id", storageController.GetFileReplicas)
+	r.POST("/replication/repair", storageController.RepairReplicas)
+
 	r.GET("/:id", storageController.GetChannel)
 
 	r.POST("/", storageController.CreateChannel)
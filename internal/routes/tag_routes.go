@@ -3,6 +3,7 @@ package routes
 import (
 	tagController "pixelpunk/internal/controllers/tag"
 	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/rbac"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,7 +26,7 @@ func RegisterTagRoutes(r *gin.RouterGroup) {
 	}
 
 	adminRoute := r.Group("/admin")
-	adminRoute.Use(middleware.RequireAuth(), middleware.RequireAdmin())
+	adminRoute.Use(middleware.RequireAuth(), middleware.RequirePermission(rbac.PermTagManage))
 	{
 		adminRoute.POST("/create", tagController.CreateTag)
 		adminRoute.POST("/update", tagController.UpdateTag)
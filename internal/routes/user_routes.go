@@ -40,6 +40,20 @@ func RegisterUserRoutes(r *gin.RouterGroup) {
 
 		userGroup.GET("/workspace/stats", userController.GetWorkspaceStats)
 
+		userGroup.GET("/2fa", userController.GetTwoFactorStatus)
+		userGroup.POST("/2fa/setup", userController.SetupTwoFactor)
+		userGroup.POST("/2fa/enable", userController.EnableTwoFactor)
+		userGroup.POST("/2fa/disable", userController.DisableTwoFactor)
+		userGroup.POST("/2fa/recovery-codes", userController.RegenerateRecoveryCodes)
+
+		userGroup.GET("/identities", userController.GetUserIdentities)
+		userGroup.POST("/identities/unlink/:id", userController.UnlinkUserIdentity)
+
+		userGroup.GET("/sessions", userController.GetSessions)
+		userGroup.POST("/sessions/revoke/:id", userController.RevokeSession)
+		userGroup.POST("/sessions/revoke-others", userController.RevokeOtherSessions)
+		userGroup.POST("/sessions/revoke-all", userController.RevokeAllSessions)
+
 		userGroup.GET("/activities", activityController.GetUserActivities)
 	}
 
@@ -3,6 +3,7 @@ package routes
 import (
 	vectorController "pixelpunk/internal/controllers/vector"
 	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/rbac"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,7 +11,7 @@ import (
 func RegisterVectorRoutes(r *gin.RouterGroup) {
 	vectorGroup := r.Group("/vector")
 	vectorGroup.Use(middleware.RequireAuth())
-	vectorGroup.Use(middleware.RequireAdmin()) // 管理员权限
+	vectorGroup.Use(middleware.RequirePermission(rbac.PermAIManage))
 	{
 		vectorGroup.GET("/list", vectorController.GetVectorList)              // 获取向量列表
 		vectorGroup.GET("/stats", vectorController.GetVectorStats)            // 获取向量统计
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	webdavController "pixelpunk/internal/controllers/webdav"
+
+	"github.com/gin-gonic/gin"
+)
+
+var webdavMethods = []string{
+	"OPTIONS", "GET", "HEAD", "POST", "PUT", "DELETE",
+	"PROPFIND", "PROPPATCH", "MKCOL", "COPY", "MOVE", "LOCK", "UNLOCK",
+}
+
+// RegisterWebDAVRoutes 注册 WebDAV 接口（基础认证由处理器完成）
+func RegisterWebDAVRoutes(r *gin.Engine) {
+	for _, method := range webdavMethods {
+		r.Handle(method, webdavController.Prefix, webdavController.Handle)
+		r.Handle(method, webdavController.Prefix+"/*path", webdavController.Handle)
+	}
+}
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	webhookController "pixelpunk/internal/controllers/webhook"
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/rbac"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterWebhookRoutes 用户级订阅挂在 /webhooks，全站级订阅挂在 /admin/webhooks
+func RegisterWebhookRoutes(r *gin.RouterGroup) {
+	userRoutes := r.Group("/webhooks")
+	userRoutes.Use(middleware.RequireAuth(), webhookController.WithScope(models.WebhookScopeUser))
+	registerWebhookHandlers(userRoutes)
+
+	adminRoutes := r.Group("/admin/webhooks")
+	adminRoutes.Use(middleware.RequirePermission(rbac.PermWebhookManage), webhookController.WithScope(models.WebhookScopeAdmin))
+	registerWebhookHandlers(adminRoutes)
+}
+
+func registerWebhookHandlers(r *gin.RouterGroup) {
+	r.GET("/events", webhookController.ListEvents)
+
+	r.GET("/deliveries", webhookController.ListDeliveries)
+	r.GET("/deliveries/:delivery_id/attempts", webhookController.ListDeliveryAttempts)
+	r.POST("/deliveries/:delivery_id/redeliver", webhookController.Redeliver)
+
+	r.GET("", webhookController.ListWebhooks)
+	r.POST("", webhookController.CreateWebhook)
+	r.GET("/:webhook_id", webhookController.GetWebhook)
+	r.PUT("/:webhook_id", webhookController.UpdateWebhook)
+	r.DELETE("/:webhook_id", webhookController.DeleteWebhook)
+	r.POST("/:webhook_id/rotate-secret", webhookController.RotateSecret)
+	r.POST("/:webhook_id/test", webhookController.TestWebhook)
+	r.GET("/:webhook_id/deliveries", webhookController.ListDeliveries)
+}
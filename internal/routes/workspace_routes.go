@@ -0,0 +1,48 @@
+package routes
+
+import (
+	workspaceController "pixelpunk/internal/controllers/workspace"
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/rbac"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterWorkspaceRoutes(r *gin.RouterGroup) {
+	r.Use(middleware.RequireAuth())
+
+	r.POST("", workspaceController.CreateWorkspace)
+
+	r.GET("", workspaceController.ListWorkspaces)
+
+	r.GET("/:workspace_id", workspaceController.GetWorkspace)
+
+	r.PUT("/:workspace_id", workspaceController.UpdateWorkspace)
+
+	r.DELETE("/:workspace_id", workspaceController.DeleteWorkspace)
+
+	r.POST("/:workspace_id/transfer", workspaceController.TransferOwnership)
+
+	r.POST("/:workspace_id/leave", workspaceController.LeaveWorkspace)
+
+	r.GET("/:workspace_id/activities", workspaceController.ListActivities)
+
+	memberGroup := r.Group("/:workspace_id/members")
+	{
+		memberGroup.GET("", workspaceController.ListMembers)
+
+		memberGroup.POST("", workspaceController.AddMember)
+
+		memberGroup.PUT("/:user_id", workspaceController.UpdateMemberRole)
+
+		memberGroup.DELETE("/:user_id", workspaceController.RemoveMember)
+	}
+}
+
+func RegisterAdminWorkspaceRoutes(r *gin.RouterGroup) {
+	r.Use(middleware.RequirePermission(rbac.PermWorkspaceManage))
+
+	r.GET("", workspaceController.AdminListWorkspaces)
+
+	r.PUT("/:workspace_id/storage", workspaceController.AdminSetStorageLimit)
+}
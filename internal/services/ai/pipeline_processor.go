@@ -8,6 +8,7 @@ import (
 
 	"pixelpunk/internal/models"
 	qqueue "pixelpunk/internal/queue"
+	"pixelpunk/internal/services/webhook"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/logger"
 	ai "pixelpunk/pkg/ai"
@@ -428,6 +429,7 @@ func (pp *PipelineProcessor) saveBatch(batch []*ProcessResult) {
 			} else {
 				result.Ack()
 				pp.service.notifyQueueStatsChange()
+				go pp.dispatchTaggedEvent(result.FileID)
 			}
 		} else {
 			var currentTries int
@@ -459,6 +461,28 @@ func (pp *PipelineProcessor) saveBatch(batch []*ProcessResult) {
 	}
 }
 
+// dispatchTaggedEvent 打标结果入库后派发回调事件
+func (pp *PipelineProcessor) dispatchTaggedEvent(fileID string) {
+	var file models.File
+	if err := pp.service.db.Where("id = ?", fileID).Take(&file).Error; err != nil {
+		return
+	}
+	var tags []string
+	pp.service.db.Table("file_global_tag_relation AS r").
+		Joins("JOIN global_tag AS t ON t.id = r.tag_id").
+		Where("r.file_id = ?", fileID).
+		Pluck("t.name", &tags)
+
+	data := webhook.FileEventData(&file)
+	data["tags"] = tags
+	var aiInfo models.FileAIInfo
+	if err := pp.service.db.Where("file_id = ?", fileID).Take(&aiInfo).Error; err == nil {
+		data["description"] = aiInfo.Description
+		data["is_nsfw"] = aiInfo.IsNSFW
+	}
+	webhook.Dispatch(webhook.EventFileTagged, file.UserID, data)
+}
+
 func (pp *PipelineProcessor) saveResultToDB(result *ProcessResult) error {
 	db := pp.service.db
 
@@ -338,3 +338,31 @@ func AiImageTaggingWithBase64AndPrompt(base64Data, imageFormat, customPrompt str
 
 	return parsedResp, nil
 }
+
+// DescribeImageForSearch 对未入库的图片生成描述文本，用于以图搜图的语义召回
+func DescribeImageForSearch(base64Data, imageFormat string) (string, error) {
+	resp, err := AiImageTaggingWithBase64AndPrompt(base64Data, imageFormat, prompts.GetImageAnalysisPrompt())
+	if err != nil {
+		return "", err
+	}
+	if resp == nil || !resp.Success {
+		msg := "未知错误"
+		if resp != nil && resp.ErrMsg != "" {
+			msg = resp.ErrMsg
+		}
+		return "", fmt.Errorf("AI分析图片失败: %s", msg)
+	}
+
+	result, err := parseAITaggingResult(resp.Data)
+	if err != nil {
+		return "", err
+	}
+	description := strings.TrimSpace(result.Description)
+	if description == "" {
+		description = strings.TrimSpace(result.SearchContent)
+	}
+	if description == "" {
+		return "", fmt.Errorf("AI未返回图片描述")
+	}
+	return description, nil
+}
@@ -0,0 +1,369 @@
+package album
+
+/* Albums: curated many-to-many collections of files with cover, ordering and presentation metadata. */
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	statusPendingDeletion = "pending_deletion"
+	statusPendingReview   = "pending_review"
+	maxAlbumFilesPerCall  = 500
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{2,63}$`)
+
+/* AlbumInput 创建或更新相册的参数，指针字段为 nil 时表示不修改 */
+type AlbumInput struct {
+	Title       *string
+	Description *string
+	Slug        *string
+	AccessLevel *string
+	CoverFileID *string
+	SortMode    *string
+	SortDesc    *bool
+	Layout      *string
+	LayoutHint  map[string]interface{}
+}
+
+/* CreateAlbum 创建相册，未指定 slug 时随机生成 */
+func CreateAlbum(userID uint, in AlbumInput) (*AlbumResponse, error) {
+	album := models.Album{
+		ID:          strings.ReplaceAll(uuid.NewString(), "-", ""),
+		UserID:      userID,
+		AccessLevel: models.AlbumAccessPrivate,
+		SortMode:    models.AlbumSortCustom,
+		Layout:      "grid",
+	}
+	if in.Slug == nil || *in.Slug == "" {
+		slug, err := generateSlug()
+		if err != nil {
+			return nil, err
+		}
+		in.Slug = &slug
+	}
+	if err := applyInput(&album, in); err != nil {
+		return nil, err
+	}
+	if album.Title == "" {
+		return nil, errors.New(errors.CodeInvalidParameter, "相册标题不能为空")
+	}
+
+	var maxOrder int
+	database.DB.Model(&models.Album{}).Where("user_id = ?", userID).Select("COALESCE(MAX(sort_order), 0)").Scan(&maxOrder)
+	album.SortOrder = maxOrder + 1
+
+	if err := database.DB.Create(&album).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBCreateFailed, "创建相册失败")
+	}
+	return buildAlbumResponse(album), nil
+}
+
+/* UpdateAlbum 更新相册信息 */
+func UpdateAlbum(userID uint, albumID string, in AlbumInput) (*AlbumResponse, error) {
+	album, err := getOwnedAlbum(userID, albumID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyInput(album, in); err != nil {
+		return nil, err
+	}
+	if err := database.DB.Save(album).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "更新相册失败")
+	}
+	return buildAlbumResponse(*album), nil
+}
+
+/* DeleteAlbum 删除相册及其文件关联与分享项，文件本身不受影响 */
+func DeleteAlbum(userID uint, albumID string) error {
+	album, err := getOwnedAlbum(userID, albumID)
+	if err != nil {
+		return err
+	}
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("album_id = ?", album.ID).Delete(&models.AlbumFile{}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除相册文件关联失败")
+		}
+		if err := tx.Where("item_type = ? AND item_id = ?", "album", album.ID).Delete(&models.ShareItem{}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除相册分享项失败")
+		}
+		if err := tx.Delete(album).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除相册失败")
+		}
+		return nil
+	})
+}
+
+/* GetAlbum 获取自己的相册详情 */
+func GetAlbum(userID uint, albumID string) (*AlbumResponse, error) {
+	album, err := getOwnedAlbum(userID, albumID)
+	if err != nil {
+		return nil, err
+	}
+	return buildAlbumResponse(*album), nil
+}
+
+/* ListAlbums 分页获取自己的相册，containsFileID 非空时只返回包含该文件的相册 */
+func ListAlbums(userID uint, keyword, containsFileID string, page, size int) ([]AlbumResponse, int64, error) {
+	query := database.DB.Model(&models.Album{}).Where("user_id = ?", userID)
+	if keyword != "" {
+		like := "%" + keyword + "%"
+		query = query.Where("title LIKE ? OR description LIKE ?", like, like)
+	}
+	if containsFileID != "" {
+		query = query.Where("id IN (?)", database.DB.Model(&models.AlbumFile{}).Select("album_id").Where("file_id = ?", containsFileID))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询相册失败")
+	}
+	var albums []models.Album
+	if err := query.Order("sort_order ASC, created_at DESC").Offset((page - 1) * size).Limit(size).Find(&albums).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询相册失败")
+	}
+	return buildAlbumResponses(albums), total, nil
+}
+
+/* ReorderAlbums 按给定顺序调整相册列表顺序 */
+func ReorderAlbums(userID uint, albumIDs []string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		for i, id := range albumIDs {
+			if err := tx.Model(&models.Album{}).Where("id = ? AND user_id = ?", id, userID).Update("sort_order", i+1).Error; err != nil {
+				return errors.Wrap(err, errors.CodeDBUpdateFailed, "更新相册顺序失败")
+			}
+		}
+		return nil
+	})
+}
+
+/* AddFiles 将自己的文件加入相册，已在相册中的文件忽略，新文件追加到末尾 */
+func AddFiles(userID uint, albumID string, fileIDs []string) (int, error) {
+	album, err := getOwnedAlbum(userID, albumID)
+	if err != nil {
+		return 0, err
+	}
+	if len(fileIDs) > maxAlbumFilesPerCall {
+		return 0, errors.New(errors.CodeInvalidParameter, "单次最多添加500个文件")
+	}
+
+	var owned []string
+	if err := database.DB.Model(&models.File{}).
+		Where("id IN ? AND user_id = ? AND status <> ?", fileIDs, userID, statusPendingDeletion).
+		Pluck("id", &owned).Error; err != nil {
+		return 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	}
+	ownedSet := make(map[string]bool, len(owned))
+	for _, id := range owned {
+		ownedSet[id] = true
+	}
+
+	var maxOrder int
+	database.DB.Model(&models.AlbumFile{}).Where("album_id = ?", album.ID).Select("COALESCE(MAX(sort_order), 0)").Scan(&maxOrder)
+
+	rows := make([]models.AlbumFile, 0, len(owned))
+	seen := make(map[string]bool, len(fileIDs))
+	for _, id := range fileIDs {
+		if !ownedSet[id] || seen[id] {
+			continue
+		}
+		seen[id] = true
+		maxOrder++
+		rows = append(rows, models.AlbumFile{AlbumID: album.ID, FileID: id, SortOrder: maxOrder})
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	var added int64
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rows, 200)
+		if result.Error != nil {
+			return errors.Wrap(result.Error, errors.CodeDBCreateFailed, "添加文件到相册失败")
+		}
+		added = result.RowsAffected
+		return refreshFileCount(tx, album.ID)
+	})
+	return int(added), err
+}
+
+/* RemoveFiles 从相册移除文件，被移除的文件若为封面则清空封面 */
+func RemoveFiles(userID uint, albumID string, fileIDs []string) (int, error) {
+	album, err := getOwnedAlbum(userID, albumID)
+	if err != nil {
+		return 0, err
+	}
+
+	var removed int64
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("album_id = ? AND file_id IN ?", album.ID, fileIDs).Delete(&models.AlbumFile{})
+		if result.Error != nil {
+			return errors.Wrap(result.Error, errors.CodeDBDeleteFailed, "从相册移除文件失败")
+		}
+		removed = result.RowsAffected
+		for _, id := range fileIDs {
+			if id == album.CoverFileID {
+				if err := tx.Model(album).Update("cover_file_id", "").Error; err != nil {
+					return errors.Wrap(err, errors.CodeDBUpdateFailed, "更新相册封面失败")
+				}
+				break
+			}
+		}
+		return refreshFileCount(tx, album.ID)
+	})
+	return int(removed), err
+}
+
+/* ReorderFiles 按给定顺序重排相册文件，并将相册切换为自定义排序；未列出的文件排在最后 */
+func ReorderFiles(userID uint, albumID string, fileIDs []string) error {
+	album, err := getOwnedAlbum(userID, albumID)
+	if err != nil {
+		return err
+	}
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		offset := len(fileIDs)
+		if err := tx.Model(&models.AlbumFile{}).Where("album_id = ? AND file_id NOT IN ?", album.ID, append(fileIDs, "")).
+			Update("sort_order", gorm.Expr("sort_order + ?", offset)).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBUpdateFailed, "更新文件顺序失败")
+		}
+		for i, id := range fileIDs {
+			if err := tx.Model(&models.AlbumFile{}).Where("album_id = ? AND file_id = ?", album.ID, id).Update("sort_order", i+1).Error; err != nil {
+				return errors.Wrap(err, errors.CodeDBUpdateFailed, "更新文件顺序失败")
+			}
+		}
+		if err := tx.Model(album).Update("sort_mode", models.AlbumSortCustom).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBUpdateFailed, "更新相册排序方式失败")
+		}
+		return nil
+	})
+}
+
+/* UpdateFileCaption 设置文件在相册内的说明文字 */
+func UpdateFileCaption(userID uint, albumID, fileID, caption string) error {
+	album, err := getOwnedAlbum(userID, albumID)
+	if err != nil {
+		return err
+	}
+	result := database.DB.Model(&models.AlbumFile{}).Where("album_id = ? AND file_id = ?", album.ID, fileID).Update("caption", caption)
+	if result.Error != nil {
+		return errors.Wrap(result.Error, errors.CodeDBUpdateFailed, "更新说明失败")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(errors.CodeFileNotFound, "文件不在相册中")
+	}
+	return nil
+}
+
+/* ListAlbumFiles 分页获取自己相册中的文件，按相册排序方式排列 */
+func ListAlbumFiles(userID uint, albumID string, page, size int) ([]AlbumFileResponse, int64, error) {
+	album, err := getOwnedAlbum(userID, albumID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return albumFiles(album, page, size)
+}
+
+func getOwnedAlbum(userID uint, albumID string) (*models.Album, error) {
+	var album models.Album
+	if err := database.DB.Where("id = ? AND user_id = ?", albumID, userID).First(&album).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeNotFound, "相册不存在")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询相册失败")
+	}
+	return &album, nil
+}
+
+func applyInput(album *models.Album, in AlbumInput) error {
+	if in.Title != nil {
+		album.Title = strings.TrimSpace(*in.Title)
+	}
+	if in.Description != nil {
+		album.Description = *in.Description
+	}
+	if in.AccessLevel != nil && *in.AccessLevel != "" {
+		album.AccessLevel = *in.AccessLevel
+	}
+	if in.SortMode != nil && *in.SortMode != "" {
+		album.SortMode = *in.SortMode
+	}
+	if in.SortDesc != nil {
+		album.SortDesc = *in.SortDesc
+	}
+	if in.Layout != nil && *in.Layout != "" {
+		album.Layout = *in.Layout
+	}
+	if in.LayoutHint != nil {
+		data, err := json.Marshal(in.LayoutHint)
+		if err != nil {
+			return errors.New(errors.CodeInvalidParameter, "展示提示格式错误")
+		}
+		album.LayoutHint = string(data)
+	}
+
+	if in.Slug != nil && *in.Slug != "" && *in.Slug != album.Slug {
+		slug := strings.ToLower(strings.TrimSpace(*in.Slug))
+		if !slugPattern.MatchString(slug) {
+			return errors.New(errors.CodeInvalidParameter, "访问标识只能包含小写字母、数字和连字符，长度3-64")
+		}
+		var count int64
+		if err := database.DB.Model(&models.Album{}).Where("slug = ? AND id <> ?", slug, album.ID).Count(&count).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBQueryFailed, "查询相册失败")
+		}
+		if count > 0 {
+			return errors.New(errors.CodeConflict, "访问标识已被使用")
+		}
+		album.Slug = slug
+	}
+
+	if in.CoverFileID != nil && *in.CoverFileID != album.CoverFileID {
+		if *in.CoverFileID != "" {
+			var count int64
+			if err := database.DB.Model(&models.AlbumFile{}).Where("album_id = ? AND file_id = ?", album.ID, *in.CoverFileID).Count(&count).Error; err != nil {
+				return errors.Wrap(err, errors.CodeDBQueryFailed, "查询相册文件失败")
+			}
+			if count == 0 {
+				return errors.New(errors.CodeInvalidParameter, "封面必须是相册中的文件")
+			}
+		}
+		album.CoverFileID = *in.CoverFileID
+	}
+	return nil
+}
+
+func generateSlug() (string, error) {
+	for i := 0; i < 5; i++ {
+		slug := strings.ToLower(utils.GenerateRandomString(12))
+		var count int64
+		if err := database.DB.Model(&models.Album{}).Where("slug = ?", slug).Count(&count).Error; err != nil {
+			return "", errors.Wrap(err, errors.CodeDBQueryFailed, "查询相册失败")
+		}
+		if count == 0 {
+			return slug, nil
+		}
+	}
+	return "", errors.New(errors.CodeInternal, "生成访问标识失败")
+}
+
+func refreshFileCount(tx *gorm.DB, albumID string) error {
+	var count int64
+	if err := tx.Model(&models.AlbumFile{}).Where("album_id = ?", albumID).Count(&count).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBQueryFailed, "统计相册文件失败")
+	}
+	if err := tx.Model(&models.Album{}).Where("id = ?", albumID).Update("file_count", count).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBUpdateFailed, "更新相册文件数失败")
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package album
+
+/* Public galleries: slug-addressed album pages whose file URLs carry the album slug for access checks. */
+
+import (
+	"strings"
+
+	"pixelpunk/internal/models"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/storage"
+
+	"gorm.io/gorm"
+)
+
+/* ListEntries 按相册的排序方式分页获取可展示的文件（排除已删除与待审核的文件） */
+func ListEntries(album *models.Album, page, size int) ([]Entry, int64, error) {
+	query := database.DB.Table("album_file").
+		Joins("JOIN file ON file.id = album_file.file_id").
+		Where("album_file.album_id = ? AND file.user_id = ?", album.ID, album.UserID).
+		Where("file.status NOT IN ?", []string{statusPendingDeletion, statusPendingReview})
+	if album.SortMode == models.AlbumSortTakenAt {
+		query = query.Joins("LEFT JOIN file_exif ON file_exif.file_id = album_file.file_id")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询相册文件失败")
+	}
+
+	var rows []struct {
+		FileID    string
+		Caption   string
+		SortOrder int
+	}
+	if err := query.Select("album_file.file_id, album_file.caption, album_file.sort_order").
+		Order(orderClause(album)).
+		Offset((page - 1) * size).Limit(size).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询相册文件失败")
+	}
+	if len(rows) == 0 {
+		return []Entry{}, total, nil
+	}
+
+	ids := make([]string, len(rows))
+	for i, r := range rows {
+		ids[i] = r.FileID
+	}
+	var files []models.File
+	if err := database.DB.Where("id IN ?", ids).Find(&files).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	}
+	byID := make(map[string]models.File, len(files))
+	for _, f := range files {
+		byID[f.ID] = f
+	}
+
+	entries := make([]Entry, 0, len(rows))
+	for _, r := range rows {
+		if f, ok := byID[r.FileID]; ok {
+			entries = append(entries, Entry{File: f, Caption: r.Caption, SortOrder: r.SortOrder})
+		}
+	}
+	return entries, total, nil
+}
+
+// orderClause 排序字段均为固定值，不拼接用户输入
+func orderClause(album *models.Album) string {
+	dir := " ASC"
+	if album.SortDesc {
+		dir = " DESC"
+	}
+	var order string
+	switch album.SortMode {
+	case models.AlbumSortTakenAt:
+		order = "file_exif.date_time_original" + dir + ", file.created_at" + dir
+	case models.AlbumSortCreatedAt:
+		order = "file.created_at" + dir
+	case models.AlbumSortName:
+		order = "file.display_name" + dir + ", file.original_name" + dir
+	default:
+		order = "album_file.sort_order" + dir
+	}
+	return order + ", album_file.file_id ASC"
+}
+
+/* CoverFile 获取相册封面：指定的封面文件，否则为排序后的第一个文件 */
+func CoverFile(album *models.Album) *models.File {
+	if album.CoverFileID != "" {
+		var file models.File
+		if err := database.DB.Where("id = ? AND user_id = ?", album.CoverFileID, album.UserID).
+			Where("status NOT IN ?", []string{statusPendingDeletion, statusPendingReview}).
+			First(&file).Error; err == nil {
+			return &file
+		}
+	}
+	entries, _, err := ListEntries(album, 1, 1)
+	if err != nil || len(entries) == 0 {
+		return nil
+	}
+	return &entries[0].File
+}
+
+func albumFiles(album *models.Album, page, size int) ([]AlbumFileResponse, int64, error) {
+	entries, total, err := ListEntries(album, page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	items := make([]AlbumFileResponse, 0, len(entries))
+	for _, e := range entries {
+		items = append(items, AlbumFileResponse{
+			FileDetailResponse: filesvc.BuildFileDetailResponse(e.File, 0, nil),
+			Caption:            e.Caption,
+			SortOrder:          e.SortOrder,
+		})
+	}
+	return items, total, nil
+}
+
+/* GetAlbumForView 公开画廊：通过 slug 访问公开或不公开列出的相册，文件地址附带 album 参数用于访问校验 */
+func GetAlbumForView(slug string, page, size int) (map[string]interface{}, error) {
+	album, err := getViewableAlbum(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := database.DB.Select("id, username, avatar").Where("id = ?", album.UserID).First(&user).Error; err != nil {
+		return nil, errors.New(errors.CodeNotFound, "相册不存在")
+	}
+
+	entries, total, err := ListEntries(album, page, size)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]map[string]interface{}, 0, len(entries))
+	for _, e := range entries {
+		fileMap := GalleryFileMap(e.File, "album", album.Slug)
+		fileMap["caption"] = e.Caption
+		files = append(files, fileMap)
+	}
+
+	return map[string]interface{}{
+		"album": galleryAlbumMap(album),
+		"user": map[string]interface{}{
+			"username": user.Username,
+			"avatar":   user.Avatar,
+		},
+		"files": files,
+		"pagination": map[string]interface{}{
+			"total":        total,
+			"size":         size,
+			"current_page": page,
+			"last_page":    (total + int64(size) - 1) / int64(size),
+		},
+	}, nil
+}
+
+/* ListPublicAlbums 用户的公开画廊：只列出公开相册 */
+func ListPublicAlbums(username string, page, size int) ([]map[string]interface{}, int64, error) {
+	var user models.User
+	if err := database.DB.Select("id").Where("username = ?", username).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, 0, errors.New(errors.CodeUserNotFound, "用户不存在")
+		}
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询用户失败")
+	}
+
+	query := database.DB.Model(&models.Album{}).Where("user_id = ? AND access_level = ?", user.ID, models.AlbumAccessPublic)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询相册失败")
+	}
+	var albums []models.Album
+	if err := query.Order("sort_order ASC, created_at DESC").Offset((page - 1) * size).Limit(size).Find(&albums).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询相册失败")
+	}
+
+	items := make([]map[string]interface{}, 0, len(albums))
+	for i := range albums {
+		items = append(items, galleryAlbumMap(&albums[i]))
+	}
+	return items, total, nil
+}
+
+/* IsFileViewable 文件是否属于可通过 slug 公开访问的相册 */
+func IsFileViewable(slug, fileID string) bool {
+	album, err := getViewableAlbum(slug)
+	if err != nil {
+		return false
+	}
+	var count int64
+	database.DB.Model(&models.AlbumFile{}).
+		Joins("JOIN file ON file.id = album_file.file_id").
+		Where("album_file.album_id = ? AND album_file.file_id = ? AND file.user_id = ?", album.ID, fileID, album.UserID).
+		Where("file.status NOT IN ?", []string{statusPendingDeletion, statusPendingReview}).
+		Count(&count)
+	return count > 0
+}
+
+/* GalleryFileMap 构建画廊/分享展示用的文件信息，文件地址附带访问参数 */
+func GalleryFileMap(file models.File, param, value string) map[string]interface{} {
+	fullURL, fullThumbURL, _ := storage.GetFullURLs(file)
+	return map[string]interface{}{
+		"id":             file.ID,
+		"display_name":   file.DisplayName,
+		"description":    file.Description,
+		"size":           file.Size,
+		"size_formatted": file.SizeFormatted,
+		"width":          file.Width,
+		"height":         file.Height,
+		"format":         file.Format,
+		"mime":           file.Mime,
+		"created_at":     file.CreatedAt,
+		"full_url":       withQuery(fullURL, param, value),
+		"full_thumb_url": withQuery(fullThumbURL, param, value),
+		"resolution":     file.Resolution,
+	}
+}
+
+// galleryAlbumMap 公开展示的相册信息，不含用户ID等内部字段
+func galleryAlbumMap(album *models.Album) map[string]interface{} {
+	result := map[string]interface{}{
+		"id":          album.ID,
+		"slug":        album.Slug,
+		"title":       album.Title,
+		"description": album.Description,
+		"layout":      album.Layout,
+		"layout_hint": parseLayoutHint(album.LayoutHint),
+		"sort_mode":   album.SortMode,
+		"file_count":  album.FileCount,
+		"created_at":  album.CreatedAt,
+		"updated_at":  album.UpdatedAt,
+	}
+	if cover := CoverFile(album); cover != nil {
+		result["cover"] = GalleryFileMap(*cover, "album", album.Slug)
+	}
+	return result
+}
+
+func getViewableAlbum(slug string) (*models.Album, error) {
+	var album models.Album
+	if err := database.DB.Where("slug = ? AND access_level IN ?", strings.ToLower(slug),
+		[]string{models.AlbumAccessPublic, models.AlbumAccessUnlisted}).First(&album).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeNotFound, "相册不存在或未公开")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询相册失败")
+	}
+	return &album, nil
+}
+
+func withQuery(url, key, value string) string {
+	if url == "" {
+		return ""
+	}
+	if strings.Contains(url, "?") {
+		return url + "&" + key + "=" + value
+	}
+	return url + "?" + key + "=" + value
+}
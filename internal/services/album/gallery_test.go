@@ -0,0 +1,52 @@
+package album
+
+import (
+	"testing"
+
+	"pixelpunk/internal/models"
+)
+
+func TestOrderClause(t *testing.T) {
+	cases := []struct {
+		mode string
+		desc bool
+		want string
+	}{
+		{models.AlbumSortCustom, false, "album_file.sort_order ASC, album_file.file_id ASC"},
+		{"", true, "album_file.sort_order DESC, album_file.file_id ASC"},
+		{models.AlbumSortTakenAt, true, "file_exif.date_time_original DESC, file.created_at DESC, album_file.file_id ASC"},
+		{models.AlbumSortName, false, "file.display_name ASC, file.original_name ASC, album_file.file_id ASC"},
+		// 未知排序方式不会拼接进SQL
+		{"id; DROP TABLE file", false, "album_file.sort_order ASC, album_file.file_id ASC"},
+	}
+	for _, c := range cases {
+		if got := orderClause(&models.Album{SortMode: c.mode, SortDesc: c.desc}); got != c.want {
+			t.Errorf("orderClause(%q, %v) = %q, want %q", c.mode, c.desc, got, c.want)
+		}
+	}
+}
+
+func TestSlugPatternAndQuery(t *testing.T) {
+	for slug, want := range map[string]bool{
+		"summer-2024": true,
+		"abc":         true,
+		"ab":          false,
+		"-abc":        false,
+		"Abc":         false,
+		"a b c":       false,
+	} {
+		if got := slugPattern.MatchString(slug); got != want {
+			t.Errorf("slugPattern(%q) = %v, want %v", slug, got, want)
+		}
+	}
+
+	if got := withQuery("https://x.com/f/1.jpg", "album", "trip"); got != "https://x.com/f/1.jpg?album=trip" {
+		t.Errorf("withQuery = %q", got)
+	}
+	if got := withQuery("https://x.com/f/1.jpg?w=1", "album", "trip"); got != "https://x.com/f/1.jpg?w=1&album=trip" {
+		t.Errorf("withQuery = %q", got)
+	}
+	if got := withQuery("", "album", "trip"); got != "" {
+		t.Errorf("withQuery empty = %q", got)
+	}
+}
@@ -0,0 +1,55 @@
+package album
+
+import (
+	"encoding/json"
+
+	"pixelpunk/internal/models"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/storage"
+)
+
+/* AlbumResponse 相册响应 */
+type AlbumResponse struct {
+	models.Album
+	LayoutHint    map[string]interface{} `json:"layout_hint"`
+	CoverURL      string                 `json:"cover_url"`
+	CoverThumbURL string                 `json:"cover_thumb_url"`
+}
+
+/* AlbumFileResponse 相册内文件响应 */
+type AlbumFileResponse struct {
+	filesvc.FileDetailResponse
+	Caption   string `json:"caption"`
+	SortOrder int    `json:"sort_order"`
+}
+
+/* Entry 相册中的一个文件及其相册内属性 */
+type Entry struct {
+	File      models.File
+	Caption   string
+	SortOrder int
+}
+
+func buildAlbumResponse(album models.Album) *AlbumResponse {
+	resp := &AlbumResponse{Album: album, LayoutHint: parseLayoutHint(album.LayoutHint)}
+	if cover := CoverFile(&album); cover != nil {
+		resp.CoverURL, resp.CoverThumbURL, _ = storage.GetFullURLs(*cover)
+	}
+	return resp
+}
+
+func buildAlbumResponses(albums []models.Album) []AlbumResponse {
+	items := make([]AlbumResponse, 0, len(albums))
+	for _, a := range albums {
+		items = append(items, *buildAlbumResponse(a))
+	}
+	return items
+}
+
+func parseLayoutHint(raw string) map[string]interface{} {
+	hint := map[string]interface{}{}
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &hint)
+	}
+	return hint
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/workspace"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
@@ -82,8 +83,36 @@ func calculateExpiresAt(expiresInDays int) *common.JSONTime {
 	return &jsonTime
 }
 
+// formatS3Permissions 校验并规范化 S3 权限列表，按 read/write/delete 顺序去重
+func formatS3Permissions(perms []string) (string, error) {
+	granted := make(map[string]bool, len(perms))
+	for _, p := range perms {
+		switch p {
+		case models.APIKeyS3Read, models.APIKeyS3Write, models.APIKeyS3Delete:
+			granted[p] = true
+		default:
+			return "", errors.New(errors.CodeInvalidParameter, "无效的S3权限: "+p)
+		}
+	}
+	var result []string
+	for _, p := range []string{models.APIKeyS3Read, models.APIKeyS3Write, models.APIKeyS3Delete} {
+		if granted[p] {
+			result = append(result, p)
+		}
+	}
+	return strings.Join(result, ","), nil
+}
+
+/* ParseS3Permissions 解析S3权限为切片 */
+func ParseS3Permissions(permsStr string) []string {
+	if permsStr == "" {
+		return []string{}
+	}
+	return strings.Split(permsStr, ",")
+}
+
 /* CreateAPIKey 创建新的API密钥 */
-func CreateAPIKey(userID uint, name string, storageLimit, singleFileLimit int64, uploadCountLimit int, allowedTypes []string, folderID string, expiresInDays int) (*models.APIKey, string, error) {
+func CreateAPIKey(userID uint, name string, storageLimit, singleFileLimit int64, uploadCountLimit int, allowedTypes []string, folderID string, expiresInDays int, s3Permissions []string) (*models.APIKey, string, error) {
 	db := database.DB
 
 	keyID := generateAPIKeyID()
@@ -92,15 +121,17 @@ func CreateAPIKey(userID uint, name string, storageLimit, singleFileLimit int64,
 		return nil, "", errors.Wrap(err, errors.CodeInternal, "生成API密钥失败")
 	}
 
-	if folderID != "" {
-		var count int64
-		if err := db.Model(&models.Folder{}).Where("id = ? AND user_id = ?", folderID, userID).Count(&count).Error; err != nil {
-			return nil, "", errors.Wrap(err, errors.CodeDBQueryFailed, "检查文件夹失败")
-		}
+	workspaceID, err := resolveKeyFolder(userID, folderID)
+	if err != nil {
+		return nil, "", err
+	}
 
-		if count == 0 {
-			return nil, "", errors.New(errors.CodeFolderNotFound, "指定的文件夹不存在")
-		}
+	s3Perms, err := formatS3Permissions(s3Permissions)
+	if err != nil {
+		return nil, "", err
+	}
+	if s3Perms != "" && workspaceID != "" {
+		return nil, "", errors.New(errors.CodeInvalidParameter, "工作区API密钥不支持S3访问")
 	}
 
 	expiresAt := calculateExpiresAt(expiresInDays)
@@ -121,6 +152,8 @@ func CreateAPIKey(userID uint, name string, storageLimit, singleFileLimit int64,
 		ExpiresAt:        expiresAt,
 		CreatedAt:        common.JSONTimeNow(),
 		UpdatedAt:        common.JSONTimeNow(),
+		WorkspaceID:      workspaceID,
+		S3Permissions:    s3Perms,
 	}
 
 	if err := db.Create(&apiKey).Error; err != nil {
@@ -204,15 +237,25 @@ func UpdateAPIKey(userID uint, keyID string, updates map[string]interface{}) (*m
 		updates["allowed_types"] = formatAllowedTypes(allowedTypes)
 	}
 
-	if folderID, ok := updates["folder_id"].(string); ok && folderID != "" {
-		var count int64
-		if err := db.Model(&models.Folder{}).Where("id = ? AND user_id = ?", folderID, userID).Count(&count).Error; err != nil {
-			return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "检查文件夹失败")
+	workspaceID := apiKey.WorkspaceID
+	if folderID, ok := updates["folder_id"].(string); ok {
+		var err error
+		if workspaceID, err = resolveKeyFolder(userID, folderID); err != nil {
+			return nil, err
 		}
+		updates["workspace_id"] = workspaceID
+	}
 
-		if count == 0 {
-			return nil, errors.New(errors.CodeFolderNotFound, "指定的文件夹不存在")
+	s3Perms := apiKey.S3Permissions
+	if perms, ok := updates["s3_permissions"].([]string); ok {
+		var err error
+		if s3Perms, err = formatS3Permissions(perms); err != nil {
+			return nil, err
 		}
+		updates["s3_permissions"] = s3Perms
+	}
+	if s3Perms != "" && workspaceID != "" {
+		return nil, errors.New(errors.CodeInvalidParameter, "工作区API密钥不支持S3访问")
 	}
 
 	updates["updated_at"] = common.JSONTimeNow()
@@ -342,6 +385,21 @@ func UpdateAPIKeyUsage(keyID string, fileSize int64) error {
 	return nil
 }
 
+// resolveKeyFolder 校验密钥上传目录的上传权限，返回目录所属工作区
+func resolveKeyFolder(userID uint, folderID string) (string, error) {
+	if folderID == "" {
+		return "", nil
+	}
+	folder, err := workspace.ResolveFolder(userID, folderID, workspace.ActionUpload)
+	if err != nil {
+		if errors.Is(err, errors.CodeFolderNotFound) {
+			return "", errors.New(errors.CodeFolderNotFound, "指定的文件夹不存在")
+		}
+		return "", err
+	}
+	return folder.WorkspaceID, nil
+}
+
 /* GetFolderFullPath 获取文件夹的完整路径 */
 func GetFolderFullPath(userID uint, folderID string) string {
 	if folderID == "" {
@@ -349,13 +407,13 @@ func GetFolderFullPath(userID uint, folderID string) string {
 	}
 
 	db := database.DB
-	var folder models.Folder
-	if err := db.Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error; err != nil {
+	folder, err := workspace.ResolveFolder(userID, folderID, workspace.ActionView)
+	if err != nil {
 		return "根目录"
 	}
 
 	var pathParts []string
-	current := folder
+	current := *folder
 
 	for {
 		pathParts = append([]string{current.Name}, pathParts...)
@@ -365,7 +423,7 @@ func GetFolderFullPath(userID uint, folderID string) string {
 		}
 
 		var parent models.Folder
-		if err := db.Where("id = ? AND user_id = ?", current.ParentID, userID).First(&parent).Error; err != nil {
+		if err := db.Where("id = ?", current.ParentID).First(&parent).Error; err != nil {
 			break
 		}
 		current = parent
@@ -492,6 +550,27 @@ func ValidateAPIKey(keyValue string) (*models.APIKey, error) {
 	return apiKey, nil
 }
 
+/* ValidateAPIKeyByID 根据密钥ID验证API密钥（S3 兼容接口以密钥ID作为 AccessKey） */
+func ValidateAPIKeyByID(keyID string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	if err := database.DB.Where("id = ?", keyID).First(&apiKey).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeUnauthorized, "无效的API密钥")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询API密钥失败")
+	}
+
+	if !apiKey.IsActive() {
+		return nil, errors.New(errors.CodeForbidden, "API密钥已禁用")
+	}
+
+	if apiKey.IsExpired() {
+		return nil, errors.New(errors.CodeForbidden, "API密钥已过期")
+	}
+
+	return &apiKey, nil
+}
+
 /* CheckAPIKeyLimits 检查API密钥是否超过限制 */
 func CheckAPIKeyLimits(apiKey *models.APIKey, fileSize int64) error {
 	if !apiKey.CheckUploadCountLimit() {
@@ -13,29 +13,24 @@ type JWTClaims struct {
 	Role     int    `json:"role"`
 	Username string `json:"username"`
 	jwt.RegisteredClaims
+
+	SessionID string `json:"sid,omitempty"` // 登录会话ID，旧版令牌为空
 }
 
-const (
-	defaultJWTSecret    = "defaultSecretKey" // 默认密钥（只在无法获取设置时使用）
-	defaultExpiresHours = 24                 // 默认24小时
-)
+const defaultJWTSecret = "defaultSecretKey" // 默认密钥（只在无法获取设置时使用）
 
 /* GetCurrentTimestamp 获取当前时间戳 */
 func GetCurrentTimestamp() int64 {
 	return time.Now().Unix()
 }
 
-/* GenerateToken 生成JWT令牌 */
-func GenerateToken(userID uint, username string, role int, jwtSecret string, expiresHours int) (string, error) {
+/* GenerateAccessToken 生成绑定登录会话的短期访问令牌 */
+func GenerateAccessToken(userID uint, username string, role int, sessionID string, jwtSecret string, ttl time.Duration) (string, error) {
 	if jwtSecret == "" {
 		jwtSecret = defaultJWTSecret
 	}
 
-	if expiresHours <= 0 {
-		expiresHours = defaultExpiresHours
-	}
-
-	expirationTime := time.Now().Add(time.Duration(expiresHours) * time.Hour)
+	expirationTime := time.Now().Add(ttl)
 
 	claims := JWTClaims{
 		UserID:   userID,
@@ -46,6 +41,7 @@ func GenerateToken(userID uint, username string, role int, jwtSecret string, exp
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
+		SessionID: sessionID,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
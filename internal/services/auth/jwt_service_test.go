@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestGenerateAccessTokenCarriesSession(t *testing.T) {
+	token, err := GenerateAccessToken(7, "alice", 3, "sess123", "secret", 15*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ParseToken(token, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != 7 || claims.SessionID != "sess123" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+	if ttl := time.Until(claims.ExpiresAt.Time); ttl > 15*time.Minute || ttl < 14*time.Minute {
+		t.Errorf("unexpected expiry %v", ttl)
+	}
+}
+
+func TestLegacyTokenRejectedAfterWindow(t *testing.T) {
+	claims := &JWTClaims{
+		UserID:           7,
+		RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(time.Now().Add(-legacyTokenMaxAge - time.Minute))},
+	}
+	if !IsTokenRevoked(claims) {
+		t.Error("session-less token past the migration window should be rejected")
+	}
+	if !IsTokenRevoked(&JWTClaims{UserID: 7}) {
+		t.Error("session-less token without issue time should be rejected")
+	}
+}
+
+func TestExpiredAccessTokenRejected(t *testing.T) {
+	token, _ := GenerateAccessToken(7, "alice", 3, "sess", "secret", -time.Minute)
+	if _, err := VerifyTokenValid(token, "secret"); err == nil {
+		t.Error("expired token should be rejected")
+	}
+}
@@ -0,0 +1,308 @@
+package auth
+
+/* Login sessions: short-lived access tokens bound to a server-side session with a rotating refresh token. */
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/pkg/cache"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAccessTokenMinutes = 15
+	sessionTouchInterval      = 5 * time.Minute // 最近活跃时间的最小更新间隔
+	revokedSessionKeyPrefix   = "auth:session:revoked:"
+	touchedSessionKeyPrefix   = "auth:session:touched:"
+	revokedBeforeKeyPrefix    = "auth:user:revoked_before:"
+
+	// 升级前签发的无会话令牌仅在签发后该时长内有效，过渡期结束后必须重新登录
+	legacyTokenMaxAge = 24 * time.Hour
+)
+
+/* SessionClient 发起登录的客户端信息 */
+type SessionClient struct {
+	UserAgent string
+	IPAddress string
+}
+
+/* TokenPair 登录或续期后返回给客户端的令牌 */
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // 访问令牌有效期(秒)
+	SessionID    string `json:"session_id"`
+}
+
+type tokenConfig struct {
+	secret     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+/* CreateSession 创建登录会话并签发访问令牌与刷新令牌 */
+func CreateSession(user *models.User, client SessionClient) (*TokenPair, error) {
+	cfg, err := loadTokenConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	secret := utils.GenerateRandomString(48)
+	now := common.JSONTime(time.Now())
+	session := models.UserSession{
+		ID:               utils.GenerateRandomString(32),
+		UserID:           user.ID,
+		RefreshTokenHash: hashRefreshSecret(secret),
+		DeviceName:       common.DeviceName(client.UserAgent),
+		UserAgent:        truncate(client.UserAgent, 500),
+		IPAddress:        client.IPAddress,
+		LastSeenAt:       now,
+		ExpiresAt:        time.Now().Add(cfg.refreshTTL),
+	}
+	if err := database.DB.Create(&session).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBCreateFailed, "创建登录会话失败")
+	}
+
+	pruneSessions(user.ID)
+	return issueTokenPair(user, session.ID, secret, cfg)
+}
+
+/* RefreshSession 使用刷新令牌续期：校验会话与用户状态后轮换刷新令牌。
+ * 已轮换的旧令牌再次出现说明令牌可能泄露，直接撤销整个会话
+ */
+func RefreshSession(refreshToken string, client SessionClient) (*TokenPair, error) {
+	sessionID, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok || sessionID == "" || secret == "" {
+		return nil, errors.New(errors.CodeUnauthorized, "刷新令牌无效")
+	}
+
+	cfg, err := loadTokenConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var session models.UserSession
+	if err := database.DB.Where("id = ?", sessionID).First(&session).Error; err != nil {
+		return nil, errors.New(errors.CodeUnauthorized, "登录会话不存在，请重新登录")
+	}
+	if !session.IsActive() {
+		return nil, errors.New(errors.CodeUnauthorized, "登录已过期，请重新登录")
+	}
+
+	presented := hashRefreshSecret(secret)
+	if subtle.ConstantTimeCompare([]byte(presented), []byte(session.RefreshTokenHash)) != 1 {
+		if session.PreviousTokenHash != "" && subtle.ConstantTimeCompare([]byte(presented), []byte(session.PreviousTokenHash)) == 1 {
+			logger.Warn("检测到已轮换的刷新令牌被重复使用，撤销会话: userID=%d, sessionID=%s", session.UserID, session.ID)
+			revokeSessions([]models.UserSession{session}, cfg.accessTTL)
+		}
+		return nil, errors.New(errors.CodeUnauthorized, "刷新令牌无效")
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, session.UserID).Error; err != nil {
+		return nil, errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+	if !user.IsNormal() {
+		return nil, errors.New(errors.CodeUserDisabled, "账号已被禁用")
+	}
+
+	newSecret := utils.GenerateRandomString(48)
+	updates := map[string]interface{}{
+		"refresh_token_hash":  hashRefreshSecret(newSecret),
+		"previous_token_hash": session.RefreshTokenHash,
+		"last_seen_at":        common.JSONTime(time.Now()),
+		"expires_at":          time.Now().Add(cfg.refreshTTL),
+	}
+	if client.IPAddress != "" {
+		updates["ip_address"] = client.IPAddress
+	}
+	// 条件更新保证并发刷新时只有一个请求能轮换成功
+	result := database.DB.Model(&models.UserSession{}).
+		Where("id = ? AND refresh_token_hash = ?", session.ID, session.RefreshTokenHash).
+		Updates(updates)
+	if result.Error != nil {
+		return nil, errors.Wrap(result.Error, errors.CodeDBUpdateFailed, "续期失败")
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(errors.CodeUnauthorized, "刷新令牌已被使用，请重新登录")
+	}
+
+	return issueTokenPair(&user, session.ID, newSecret, cfg)
+}
+
+/* ListSessions 获取用户当前有效的登录会话 */
+func ListSessions(userID uint) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	if err := database.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").Find(&sessions).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询登录会话失败")
+	}
+	return sessions, nil
+}
+
+/* RevokeSession 撤销用户的指定会话 */
+func RevokeSession(userID uint, sessionID string) error {
+	var session models.UserSession
+	if err := database.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).First(&session).Error; err != nil {
+		return errors.New(errors.CodeNotFound, "登录会话不存在")
+	}
+	return revokeSessions([]models.UserSession{session}, accessTTL())
+}
+
+/* RevokeAllSessions 撤销用户的全部会话，exceptSessionID 非空时保留该会话（用于退出其他设备）。
+ * 同时作废该用户此前签发的无会话旧版令牌
+ */
+func RevokeAllSessions(userID uint, exceptSessionID string) (int, error) {
+	_ = cache.GetCache().Set(fmt.Sprintf("%s%d", revokedBeforeKeyPrefix, userID), strconv.FormatInt(time.Now().Unix(), 10), legacyTokenMaxAge)
+
+	query := database.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now())
+	if exceptSessionID != "" {
+		query = query.Where("id <> ?", exceptSessionID)
+	}
+	var sessions []models.UserSession
+	if err := query.Find(&sessions).Error; err != nil {
+		return 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询登录会话失败")
+	}
+	if err := revokeSessions(sessions, accessTTL()); err != nil {
+		return 0, err
+	}
+	return len(sessions), nil
+}
+
+/* IsTokenRevoked 访问令牌是否已失效：会话令牌看所属会话是否撤销；
+ * 无会话的旧版令牌超过过渡期或签发于用户全部退出之前均视为失效
+ */
+func IsTokenRevoked(claims *JWTClaims) bool {
+	if claims.SessionID != "" {
+		return cache.GetCache().Exists(revokedSessionKeyPrefix + claims.SessionID)
+	}
+	if claims.IssuedAt == nil || time.Since(claims.IssuedAt.Time) > legacyTokenMaxAge {
+		return true
+	}
+	val, err := cache.GetCache().Get(fmt.Sprintf("%s%d", revokedBeforeKeyPrefix, claims.UserID))
+	if err != nil || val == "" {
+		return false
+	}
+	revokedAt, err := strconv.ParseInt(val, 10, 64)
+	return err == nil && claims.IssuedAt.Unix() <= revokedAt
+}
+
+/* TouchSession 更新会话最近活跃时间与IP，按间隔节流 */
+func TouchSession(sessionID, ip string) {
+	if sessionID == "" {
+		return
+	}
+	key := touchedSessionKeyPrefix + sessionID
+	if cache.GetCache().Exists(key) {
+		return
+	}
+	_ = cache.GetCache().Set(key, "1", sessionTouchInterval)
+
+	updates := map[string]interface{}{"last_seen_at": common.JSONTime(time.Now())}
+	if ip != "" {
+		updates["ip_address"] = ip
+	}
+	database.DB.Model(&models.UserSession{}).Where("id = ? AND revoked_at IS NULL", sessionID).Updates(updates)
+}
+
+func issueTokenPair(user *models.User, sessionID, secret string, cfg *tokenConfig) (*TokenPair, error) {
+	token, err := GenerateAccessToken(user.ID, user.Username, user.Role, sessionID, cfg.secret, cfg.accessTTL)
+	if err != nil {
+		return nil, errors.New(errors.CodeInternal, "生成token失败")
+	}
+	return &TokenPair{
+		AccessToken:  token,
+		RefreshToken: sessionID + "." + secret,
+		ExpiresIn:    int(cfg.accessTTL.Seconds()),
+		SessionID:    sessionID,
+	}, nil
+}
+
+func revokeSessions(sessions []models.UserSession, ttl time.Duration) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+	ids := make([]string, len(sessions))
+	for i, s := range sessions {
+		ids[i] = s.ID
+	}
+	now := common.JSONTime(time.Now())
+	if err := database.DB.Model(&models.UserSession{}).Where("id IN ?", ids).Update("revoked_at", &now).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBUpdateFailed, "撤销登录会话失败")
+	}
+	// 已签发的访问令牌在有效期内仍可解析，通过缓存标记立即失效
+	for _, id := range ids {
+		_ = cache.GetCache().Set(revokedSessionKeyPrefix+id, "1", ttl+time.Minute)
+	}
+	return nil
+}
+
+// pruneSessions 清理用户已过期或撤销超过一周的会话记录
+func pruneSessions(userID uint) {
+	database.DB.Where("user_id = ? AND (expires_at < ? OR revoked_at < ?)", userID, time.Now(), time.Now().AddDate(0, 0, -7)).
+		Delete(&models.UserSession{})
+}
+
+func loadTokenConfig() (*tokenConfig, error) {
+	securitySettings, err := setting.GetSettingsByGroupAsMap("security")
+	if err != nil {
+		return nil, errors.New(errors.CodeInternal, "安全配置读取失败：security 组缺失")
+	}
+
+	secret, _ := securitySettings.Settings["jwt_secret"].(string)
+	if strings.TrimSpace(secret) == "" {
+		return nil, errors.New(errors.CodeInternal, "安全配置缺失：jwt_secret 未设置")
+	}
+
+	refreshHours := 0
+	if hours, ok := securitySettings.Settings["login_expire_hours"].(float64); ok && hours > 0 {
+		refreshHours = int(hours)
+	}
+	if refreshHours <= 0 {
+		return nil, errors.New(errors.CodeInternal, "安全配置缺失：login_expire_hours 未设置或非法")
+	}
+
+	return &tokenConfig{
+		secret:     secret,
+		accessTTL:  accessTTLFromSettings(securitySettings.Settings),
+		refreshTTL: time.Duration(refreshHours) * time.Hour,
+	}, nil
+}
+
+func accessTTL() time.Duration {
+	securitySettings, err := setting.GetSettingsByGroupAsMap("security")
+	if err != nil {
+		return defaultAccessTokenMinutes * time.Minute
+	}
+	return accessTTLFromSettings(securitySettings.Settings)
+}
+
+func accessTTLFromSettings(settings map[string]interface{}) time.Duration {
+	if minutes, ok := settings["access_token_expire_minutes"].(float64); ok && minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return defaultAccessTokenMinutes * time.Minute
+}
+
+func hashRefreshSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
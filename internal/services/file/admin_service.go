@@ -5,6 +5,7 @@ import (
 	"pixelpunk/internal/models"
 	"pixelpunk/internal/services/activity"
 	messageService "pixelpunk/internal/services/message"
+	"pixelpunk/internal/services/webhook"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
@@ -337,6 +338,7 @@ func BatchDeleteFiles(fileIDs []string) (*BatchOperationResult, error) {
 		go func(uid uint, images []models.File) {
 			for _, img := range images {
 				activity.LogAdminDelete(uid, img.ID, 0)
+				webhook.Dispatch(webhook.EventFileDeleted, uid, map[string]interface{}{"file_id": img.ID, "trashed": false, "by_admin": true})
 			}
 		}(userID, deletedFiles)
 	}
@@ -16,6 +16,7 @@ import (
 	"pixelpunk/internal/controllers/file/dto"
 	"pixelpunk/internal/models"
 	"pixelpunk/internal/services/setting"
+	"pixelpunk/internal/services/workspace"
 	"pixelpunk/pkg/config"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
@@ -479,6 +480,16 @@ func processUploadedFile(userID uint, filePath string, session *models.UploadSes
 		return nil, err
 	}
 
+	if ctx.WorkspaceID != "" {
+		available, err := workspace.CheckStorageAvailable(ctx.WorkspaceID, header.Size)
+		if err != nil {
+			return nil, err
+		}
+		if !available {
+			return nil, errors.New(errors.CodeStorageLimitExceeded, "工作区存储空间不足，无法上传文件")
+		}
+	}
+
 	if err := processFolderPath(ctx); err != nil {
 		return nil, err
 	}
@@ -4,6 +4,7 @@ package file
 
 const (
 	StatusPendingDeletion = "pending_deletion"
+	StatusPendingReview   = "pending_review"
 
 	AccessPublic    = "public"
 	AccessPrivate   = "private"
@@ -17,8 +17,15 @@ import (
 func cleanupFileResources(fileID string, file models.File, totalReferences int64) {
 	cleanupFileLogs(fileID)
 	cleanupFileShares(fileID)
+	cleanupFileAlbums(fileID)
 	cleanupFileUploadSessions(fileID)
 	cleanupFileVectors(fileID)
+	DeleteFileVariants(fileID)
+	DeleteFileSignedLinks(fileID)
+	DeleteFilePHashBands(fileID)
+	cleanupFileTrashItems(fileID)
+	// 秒传文件共享存储对象，仍有引用时只删除记录
+	DeleteFileReplicas(file, totalReferences == 0)
 	if totalReferences == 0 {
 		cleanupPhysicalFiles(file)
 	}
@@ -44,6 +51,26 @@ func cleanupFileShares(fileID string) {
 	}
 }
 
+func cleanupFileAlbums(fileID string) {
+	db := database.DB
+	var albumIDs []string
+	db.Model(&models.AlbumFile{}).Where("file_id = ?", fileID).Pluck("album_id", &albumIDs)
+	if len(albumIDs) == 0 {
+		return
+	}
+	if err := db.Where("file_id = ?", fileID).Delete(&models.AlbumFile{}).Error; err != nil {
+		logger.Error("删除相册文件关联失败 [%s]: %v", fileID, err)
+		return
+	}
+	if err := db.Model(&models.Album{}).Where("cover_file_id = ?", fileID).Update("cover_file_id", "").Error; err != nil {
+		logger.Error("清理相册封面失败 [%s]: %v", fileID, err)
+	}
+	if err := db.Model(&models.Album{}).Where("id IN ?", albumIDs).
+		Update("file_count", gorm.Expr("(SELECT COUNT(*) FROM album_file WHERE album_file.album_id = album.id)")).Error; err != nil {
+		logger.Error("更新相册文件数失败 [%s]: %v", fileID, err)
+	}
+}
+
 func cleanupFileUploadSessions(fileID string) {
 	db := database.DB
 	if err := db.Model(&models.UploadSession{}).Where("file_id = ?", fileID).Update("file_id", "").Error; err != nil {
@@ -94,5 +121,5 @@ func DeleteNSFWFile(fileID string) error {
 		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
 	}
 	logger.Warn("违规文件自动删除：用户ID=%d, 文件ID=%s, 文件名=%s", file.UserID, fileID, file.OriginalName)
-	return deleteFileWithCascade(&file, file.UserID)
+	return deleteFileWithCascade(&file, file.UserID, true)
 }
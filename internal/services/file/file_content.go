@@ -0,0 +1,158 @@
+package file
+
+/* Raw content helpers shared by variant generation and storage maintenance. */
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/imagex/iox"
+	"pixelpunk/pkg/storage"
+	pathutil "pixelpunk/pkg/storage/path"
+)
+
+/* ResolveObjectKey 返回文件（或缩略图）在存储适配器中的对象键 */
+func ResolveObjectKey(file models.File, isThumb bool) string {
+	var candidate string
+	if isThumb {
+		if file.RemoteThumbURL != "" && !pathutil.IsHTTPURL(file.RemoteThumbURL) {
+			candidate = file.RemoteThumbURL
+		} else {
+			candidate = file.ThumbURL
+		}
+	} else {
+		if file.RemoteURL != "" && !pathutil.IsHTTPURL(file.RemoteURL) {
+			candidate = file.RemoteURL
+		} else {
+			candidate = file.URL
+		}
+	}
+	candidate = strings.TrimPrefix(candidate, "/")
+	return pathutil.EnsureObjectKey(file.UserID, candidate, isThumb)
+}
+
+/* ReadFileContent 读取文件原图（或缩略图）的完整字节，主渠道读取失败时依次尝试副本 */
+func ReadFileContent(file models.File, isThumb bool) ([]byte, error) {
+	candidates := replicaReadCandidates(file)
+	var lastErr error
+	for _, candidate := range candidates {
+		data, err := readChannelContent(candidate, isThumb)
+		if err == nil {
+			if len(candidates) > 1 {
+				recordChannelReadSuccess(candidate.StorageProviderID)
+			}
+			return data, nil
+		}
+		if len(candidates) > 1 {
+			recordChannelReadFailure(candidate.StorageProviderID)
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// readChannelContent 从文件视图所在的渠道读取内容，不做故障转移
+func readChannelContent(file models.File, isThumb bool) ([]byte, error) {
+	provider, err := storage.GetStorageProviderByChannelID(file.StorageProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if provider.IsDirectAccess() {
+		localPath, err := GetFileLocalPath(file, isThumb)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.Open(localPath)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeFileNotFound, "打开本地文件失败")
+		}
+		defer f.Close()
+		return iox.ReadAllWithLimit(f, iox.DefaultMaxReadBytes)
+	}
+
+	key := ResolveObjectKey(file, isThumb)
+	if key == "" {
+		return nil, errors.New(errors.CodeFileNotFound, "无法解析文件存储路径")
+	}
+	rc, err := storage.NewGlobalStorage().ReadFile(context.Background(), file.StorageProviderID, key)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeFileNotFound, "读取存储文件失败")
+	}
+	defer rc.Close()
+	return iox.ReadAllWithLimit(rc, iox.DefaultMaxReadBytes)
+}
+
+/* OpenFileContent 以流的方式打开文件原图（或缩略图），ContentLength 为存储对象的实际大小（< 0 表示未知），主渠道打开失败时依次尝试副本 */
+func OpenFileContent(file models.File, isThumb bool) (*ProxyResponse, error) {
+	candidates := replicaReadCandidates(file)
+	var lastErr error
+	for _, candidate := range candidates {
+		resp, err := openChannelContent(candidate, isThumb)
+		if err == nil {
+			if len(candidates) > 1 {
+				recordChannelReadSuccess(candidate.StorageProviderID)
+			}
+			return resp, nil
+		}
+		if len(candidates) > 1 {
+			recordChannelReadFailure(candidate.StorageProviderID)
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// openChannelContent 从文件视图所在的渠道打开内容流：本地文件可定位，支持区间读取的渠道按需回源，其余渠道只能顺序读取且大小未知
+func openChannelContent(file models.File, isThumb bool) (*ProxyResponse, error) {
+	provider, err := storage.GetStorageProviderByChannelID(file.StorageProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if provider.IsDirectAccess() {
+		localPath, err := GetFileLocalPath(file, isThumb)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.Open(localPath)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeFileNotFound, "打开本地文件失败")
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, errors.Wrap(err, errors.CodeFileNotFound, "读取本地文件信息失败")
+		}
+		return &ProxyResponse{Content: f, ContentLength: info.Size()}, nil
+	}
+
+	key := ResolveObjectKey(file, isThumb)
+	if key == "" {
+		return nil, errors.New(errors.CodeFileNotFound, "无法解析文件存储路径")
+	}
+	gs := storage.NewGlobalStorage()
+	if rr, ok := gs.RangeReader(file.StorageProviderID); ok {
+		size, err := rr.ObjectSize(context.Background(), key)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeFileNotFound, "查询存储文件大小失败")
+		}
+		open := func(offset, length int64) (io.ReadCloser, error) {
+			return rr.ReadRange(context.Background(), key, offset, length)
+		}
+		return &ProxyResponse{
+			Content:       &lazyReadCloser{open: func() (io.ReadCloser, error) { return open(0, -1) }},
+			ContentLength: size,
+			OpenRange:     open,
+		}, nil
+	}
+	rc, err := gs.ReadFile(context.Background(), file.StorageProviderID, key)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeFileNotFound, "读取存储文件失败")
+	}
+	return &ProxyResponse{Content: rc, ContentLength: -1}, nil
+}
@@ -3,11 +3,14 @@ package file
 /* File serving helpers (no behavior change). */
 
 import (
+	"context"
 	"io"
 	"os"
+	"path/filepath"
 	"pixelpunk/internal/models"
 	"pixelpunk/internal/services/setting"
 	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/imagex/formats"
 	"pixelpunk/pkg/logger"
 	"pixelpunk/pkg/storage"
 	pathutil "pixelpunk/pkg/storage/path"
@@ -37,8 +40,35 @@ func GetFileLocalPath(file models.File, isThumb bool) (string, error) {
 	return localPath, nil
 }
 
-/* ServeFile 根据文件存储类型获取访问信息 */
+/* ServeFile 根据文件存储类型获取访问信息，文件存在多渠道副本时自动故障转移 */
 func ServeFile(file models.File, isThumb bool) (interface{}, bool, bool, error) {
+	candidates := replicaReadCandidates(file)
+	if len(candidates) == 1 {
+		return serveFileFromChannel(file, isThumb)
+	}
+
+	var lastErr error
+	for _, candidate := range candidates {
+		result, isLocal, isProxy, err := serveFileFromChannel(candidate, isThumb)
+		if err == nil && isLocal {
+			if localPath, _ := result.(string); localPath == "" {
+				err = errors.New(errors.CodeFileNotFound, "文件不存在")
+			} else if _, statErr := os.Stat(localPath); statErr != nil {
+				err = errors.Wrap(statErr, errors.CodeFileNotFound, "本地文件不存在")
+			}
+		}
+		if err == nil {
+			recordChannelReadSuccess(candidate.StorageProviderID)
+			return result, isLocal, isProxy, nil
+		}
+		logger.Warn("渠道读取失败，尝试下一个副本 [%s@%s]: %v", file.ID, candidate.StorageProviderID, err)
+		recordChannelReadFailure(candidate.StorageProviderID)
+		lastErr = err
+	}
+	return nil, false, false, lastErr
+}
+
+func serveFileFromChannel(file models.File, isThumb bool) (interface{}, bool, bool, error) {
 	provider, err := storage.GetStorageProviderByChannelID(file.StorageProviderID)
 	if err != nil {
 		return nil, false, false, err
@@ -112,6 +142,9 @@ func ServeFile(file models.File, isThumb bool) (interface{}, bool, bool, error)
 	remoteUrl := candidate
 
 	if useProxy {
+		if resp, ok := rangeProxyResponse(provider, remoteUrl, isThumb, file.UserID); ok {
+			return resp, false, true, nil
+		}
 		content, contentType, err := provider.GetRemoteContent(remoteUrl, isThumb, file.UserID)
 		if err != nil {
 			logger.Error("代理模式获取内容失败: %v", err)
@@ -131,4 +164,57 @@ type ProxyResponse struct {
 	Content       io.ReadCloser
 	ContentType   string
 	ContentLength int64
+
+	// OpenRange 渠道支持区间读取时按字节区间回源，length < 0 表示读到末尾；为空时只能顺序读取 Content
+	OpenRange func(offset, length int64) (io.ReadCloser, error)
+}
+
+// rangeProxyResponse 渠道支持区间读取时返回按需回源的代理响应，完整内容在首次读取时才请求
+func rangeProxyResponse(provider storage.RemoteReadProvider, remoteURL string, isThumb bool, userID uint) (*ProxyResponse, bool) {
+	rr, key, ok := provider.RemoteRangeReader(remoteURL, isThumb, userID)
+	if !ok {
+		return nil, false
+	}
+	size, err := rr.ObjectSize(context.Background(), key)
+	if err != nil {
+		logger.Warn("查询远程对象大小失败，回退为完整读取 [%s]: %v", key, err)
+		return nil, false
+	}
+	open := func(offset, length int64) (io.ReadCloser, error) {
+		return rr.ReadRange(context.Background(), key, offset, length)
+	}
+	contentType := formats.GetContentType(strings.TrimPrefix(strings.ToLower(filepath.Ext(key)), "."))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return &ProxyResponse{
+		Content:       &lazyReadCloser{open: func() (io.ReadCloser, error) { return open(0, -1) }},
+		ContentType:   contentType,
+		ContentLength: size,
+		OpenRange:     open,
+	}, true
+}
+
+// lazyReadCloser 首次读取时才打开底层流，只按区间读取时不会产生完整回源
+type lazyReadCloser struct {
+	open func() (io.ReadCloser, error)
+	rc   io.ReadCloser
+	err  error
+}
+
+func (l *lazyReadCloser) Read(p []byte) (int, error) {
+	if l.rc == nil && l.err == nil {
+		l.rc, l.err = l.open()
+	}
+	if l.err != nil {
+		return 0, l.err
+	}
+	return l.rc.Read(p)
+}
+
+func (l *lazyReadCloser) Close() error {
+	if l.rc == nil {
+		return nil
+	}
+	return l.rc.Close()
 }
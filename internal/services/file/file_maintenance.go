@@ -44,7 +44,7 @@ func CleanupExpiredFiles() (int, int, error) {
 	for userID, images := range userImages {
 		deletedFiles := make([]models.File, 0)
 		for _, img := range images {
-			if deleteErr := DeleteFile(img.UserID, img.ID); deleteErr != nil {
+			if deleteErr := PurgeFile(img.UserID, img.ID); deleteErr != nil {
 				logger.Error("❌ 删除过期文件失败: ID=%s, 文件名=%s, 过期时间=%s, 错误=%v", img.ID, img.OriginalName, img.ExpiresAt.Format("2006-01-02 15:04:05"), deleteErr)
 				failedCount++
 			} else {
@@ -10,9 +10,12 @@ import (
 	"path/filepath"
 	"pixelpunk/internal/models"
 	storageChannelService "pixelpunk/internal/services/storage"
+	"pixelpunk/internal/services/webhook"
+	"pixelpunk/internal/services/workspace"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/storage"
 	"pixelpunk/pkg/utils"
 	"strings"
 	"time"
@@ -22,16 +25,18 @@ import (
 
 /* UpdateFile 更新文件信息（名称/文件夹/访问级别） */
 func UpdateFile(userID uint, fileID, name, folderID, accessLevel string) (*FileDetailResponse, error) {
-	var file models.File
-	if err := database.DB.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New(errors.CodeFileNotFound, "文件不存在")
-		}
-		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	file, err := workspace.ResolveFile(userID, fileID, workspace.ActionEdit)
+	if err != nil {
+		return nil, err
 	}
 	if folderID == "null" {
 		folderID = ""
 	}
+	if folderID != file.FolderID {
+		if err := checkTargetFolder(userID, file.WorkspaceID, folderID); err != nil {
+			return nil, err
+		}
+	}
 	file.FolderID = folderID
 
 	if name != "" {
@@ -40,9 +45,10 @@ func UpdateFile(userID uint, fileID, name, folderID, accessLevel string) (*FileD
 	if accessLevel != "" {
 		file.AccessLevel = accessLevel
 	}
-	if err := database.DB.Save(&file).Error; err != nil {
+	if err := database.DB.Save(file).Error; err != nil {
 		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "保存文件信息失败")
 	}
+	workspace.RecordActivity(file.WorkspaceID, userID, workspace.ActivityFileUpdate, "file", file.ID, file.OriginalName, 0)
 
 	var stats models.FileStats
 	if err := database.DB.Where("file_id = ?", fileID).First(&stats).Error; err != nil {
@@ -51,18 +57,35 @@ func UpdateFile(userID uint, fileID, name, folderID, accessLevel string) (*FileD
 		}
 	}
 	aiInfo, _ := GetFileAIInfo(file.ID)
-	resp := BuildFileDetailResponse(file, stats.Views, aiInfo)
+	resp := BuildFileDetailResponse(*file, stats.Views, aiInfo)
 	return &resp, nil
 }
 
+/* RenameFile 修改文件名（含扩展名），显示名称同步为去掉扩展名后的安全名称 */
+func RenameFile(userID uint, fileID, fileName string) error {
+	if fileName == "" || len(fileName) > 255 || storage.SanitizeFileName(fileName) != fileName {
+		return errors.New(errors.CodeInvalidParameter, "文件名无效：不能为空或包含 / \\ : * ? \" < > | 等特殊字符")
+	}
+	file, err := workspace.ResolveFile(userID, fileID, workspace.ActionEdit)
+	if err != nil {
+		return err
+	}
+	if err := database.DB.Model(file).
+		Updates(map[string]interface{}{
+			"original_name": fileName,
+			"display_name":  storage.SanitizeFileName(strings.TrimSuffix(fileName, filepath.Ext(fileName))),
+		}).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBUpdateFailed, "重命名文件失败")
+	}
+	workspace.RecordActivity(file.WorkspaceID, userID, workspace.ActivityFileUpdate, "file", file.ID, fileName, 0)
+	return nil
+}
+
 /* ToggleFileAccessLevel 切换文件访问级别（新语义） */
 func ToggleFileAccessLevel(userID uint, fileID string) (*FileDetailResponse, error) {
-	var file models.File
-	if err := database.DB.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New(errors.CodeFileNotFound, "文件不存在")
-		}
-		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	file, err := workspace.ResolveFile(userID, fileID, workspace.ActionEdit)
+	if err != nil {
+		return nil, err
 	}
 	switch file.AccessLevel {
 	case AccessPublic:
@@ -74,7 +97,7 @@ func ToggleFileAccessLevel(userID uint, fileID string) (*FileDetailResponse, err
 	default:
 		file.AccessLevel = AccessPublic
 	}
-	if err := database.DB.Save(&file).Error; err != nil {
+	if err := database.DB.Save(file).Error; err != nil {
 		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "更新文件失败")
 	}
 	var stats models.FileStats
@@ -84,25 +107,42 @@ func ToggleFileAccessLevel(userID uint, fileID string) (*FileDetailResponse, err
 		}
 	}
 	aiInfo, _ := GetFileAIInfo(file.ID)
-	resp2 := BuildFileDetailResponse(file, stats.Views, aiInfo)
+	resp2 := BuildFileDetailResponse(*file, stats.Views, aiInfo)
 	return &resp2, nil
 }
 
-/* DeleteFile 删除文件（异步标记+后台删除） */
+/* DeleteFile 删除文件：启用回收站时移入回收站，否则立即彻底删除；工作区文件不进入个人回收站，直接彻底删除 */
 func DeleteFile(userID uint, fileID string) error {
-	var file models.File
-	if err := database.DB.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.New(errors.CodeFileNotFound, "文件不存在")
-		}
-		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	file, err := workspace.ResolveFile(userID, fileID, workspace.ActionEdit)
+	if err != nil {
+		return err
+	}
+	trashed := TrashEnabled() && file.WorkspaceID == ""
+	if trashed {
+		err = MoveFileToTrash(userID, fileID)
+	} else {
+		err = PurgeFile(userID, fileID)
+	}
+	if err == nil {
+		webhook.Dispatch(webhook.EventFileDeleted, userID, map[string]interface{}{"file_id": fileID, "trashed": trashed})
+		workspace.RecordActivity(file.WorkspaceID, userID, workspace.ActivityFileDelete, "file", file.ID, file.OriginalName, file.Size)
+	}
+	return err
+}
+
+/* PurgeFile 彻底删除文件（异步标记+后台删除） */
+func PurgeFile(userID uint, fileID string) error {
+	file, err := workspace.ResolveFile(userID, fileID, workspace.ActionEdit)
+	if err != nil {
+		return err
 	}
-	if err := database.DB.Model(&models.File{}).Where("id = ? AND user_id = ?", fileID, userID).Updates(map[string]interface{}{"status": StatusPendingDeletion}).Error; err != nil {
+	if err := database.DB.Model(&models.File{}).Where("id = ?", file.ID).Updates(map[string]interface{}{"status": StatusPendingDeletion}).Error; err != nil {
 		return errors.Wrap(err, errors.CodeDBUpdateFailed, "标记文件为待删除失败")
 	}
-	imgCopy := file
+	imgCopy := *file
+	inTrash := isFileInTrash(fileID)
 	go func() {
-		if err := deleteFileWithCascade(&imgCopy, userID); err != nil {
+		if err := deleteFileWithCascade(&imgCopy, imgCopy.UserID, !inTrash); err != nil {
 			logger.Warn("后台删除文件失败，将由定时任务兜底处理，file=%s err=%v", fileID, err)
 		}
 	}()
@@ -147,7 +187,9 @@ func BatchDeleteUserFiles(userID uint, fileIDs []string) ([]string, []string, er
 /* CleanupPendingDeletionFiles 查找并删除标记为待删除的文件 */
 func CleanupPendingDeletionFiles(maxImages int) (int, error) {
 	var imageIDs []string
-	query := database.DB.Model(&models.File{}).Where("status = ?", "pending_deletion").Select("id")
+	query := database.DB.Model(&models.File{}).Where("status = ?", "pending_deletion").
+		Where("id NOT IN (?)", database.DB.Model(&models.TrashItem{}).Select("item_id").Where("item_type = ?", models.TrashItemTypeFile)).
+		Select("id")
 	if maxImages > 0 {
 		query = query.Limit(maxImages)
 	}
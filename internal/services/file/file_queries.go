@@ -4,6 +4,7 @@ package file
 
 import (
 	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/workspace"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 
@@ -18,7 +19,11 @@ func GetFileList(userID uint, folderID string, page, size int, sort string, acce
 	var images []models.File
 	var responses []FileDetailResponse
 
-	query := database.DB.Where("user_id = ?", userID).Where("status <> ?", StatusPendingDeletion).Joins("LEFT JOIN file_ai_info ON file_ai_info.file_id = file.id")
+	scope, err := fileFolderScope(userID, folderID, workspace.ActionView)
+	if err != nil {
+		return nil, 0, err
+	}
+	query := database.DB.Scopes(scope).Where("status <> ?", StatusPendingDeletion).Joins("LEFT JOIN file_ai_info ON file_ai_info.file_id = file.id")
 	if folderID != "" {
 		query = query.Where("folder_id = ?", folderID)
 	}
@@ -107,12 +112,12 @@ func GetFileList(userID uint, folderID string, page, size int, sort string, acce
 
 /* GetFileDetail 获取单个文件详情 */
 func GetFileDetail(userID uint, fileID string) (*FileDetailResponse, error) {
-	var file models.File
-	if err := database.DB.Where("id = ? AND user_id = ?", fileID, userID).Where("status <> ?", StatusPendingDeletion).First(&file).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New(errors.CodeFileNotFound, "文件不存在")
-		}
-		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	file, err := workspace.ResolveFile(userID, fileID, workspace.ActionView)
+	if err != nil {
+		return nil, err
+	}
+	if file.Status == StatusPendingDeletion {
+		return nil, errors.New(errors.CodeFileNotFound, "文件不存在")
 	}
 	var stats models.FileStats
 	if err := database.DB.Where("file_id = ?", fileID).First(&stats).Error; err != nil {
@@ -123,7 +128,7 @@ func GetFileDetail(userID uint, fileID string) (*FileDetailResponse, error) {
 	aiInfo, _ := GetFileAIInfo(file.ID)
 
 	var exifInfo models.FileEXIF
-	resp := BuildFileDetailResponse(file, stats.Views, aiInfo)
+	resp := BuildFileDetailResponse(*file, stats.Views, aiInfo)
 	if err := database.DB.Where("file_id = ?", fileID).First(&exifInfo).Error; err == nil {
 		resp.EXIFInfo = &exifInfo
 	}
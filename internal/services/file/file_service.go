@@ -2,6 +2,7 @@ package file
 
 import (
 	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/search"
 
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
@@ -158,7 +159,8 @@ func GetFileAIInfo(fileID string) (*AIInfoResponse, error) {
 
 /* DeleteFile 删除文件（统一删除入口） */
 
-func deleteFileWithCascade(file *models.File, userID uint) error {
+// releaseUsage 为 false 时表示文件已在移入回收站时扣减过用户统计
+func deleteFileWithCascade(file *models.File, userID uint, releaseUsage bool) error {
 	fileID := file.ID
 
 	var duplicateCount int64
@@ -183,6 +185,10 @@ func deleteFileWithCascade(file *models.File, userID uint) error {
 		return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除文件AI分析信息失败")
 	}
 
+	if err := search.RemoveFiles(fileID); err != nil {
+		return err
+	}
+
 	if err := database.DB.Unscoped().Where("file_id = ?", fileID).Delete(&models.FileStats{}).Error; err != nil {
 		return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除文件统计失败")
 	}
@@ -192,7 +198,7 @@ func deleteFileWithCascade(file *models.File, userID uint) error {
 	}
 
 	var userStats models.UserUsageStats
-	if err := database.DB.Where("user_id = ?", userID).First(&userStats).Error; err == nil {
+	if releaseUsage && file.WorkspaceID == "" && database.DB.Where("user_id = ?", userID).First(&userStats).Error == nil {
 		updates := make(map[string]interface{})
 		if userStats.TotalImages > 0 {
 			updates["total_images"] = userStats.TotalImages - 1
@@ -0,0 +1,304 @@
+package file
+
+/* On-the-fly image variants (resize/crop/re-encode) cached through the file's storage adapter. */
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/imagex/formats"
+	"pixelpunk/pkg/imagex/transform"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/storage"
+	"pixelpunk/pkg/storage/adapter"
+	"pixelpunk/pkg/storage/tenant"
+)
+
+const (
+	variantFolder = "_variants"
+
+	// 每个文件最多保留的衍生图数量，超出后淘汰最早生成的，避免任意参数组合无限占用存储
+	maxVariantsPerFile = 16
+
+	// 每个文件在一个窗口内最多即时生成的衍生图数量，防止轮换参数反复触发淘汰与重新生成
+	variantRenderWindow        = time.Minute
+	maxVariantRendersPerWindow = maxVariantsPerFile
+	// 限流记录超过该数量时清理已过期的窗口
+	variantRenderSweepSize = 4096
+)
+
+// 同一衍生图并发请求只生成一次；按引用计数回收，仍有等待者时不删除锁
+type variantLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+var (
+	variantLocksMu sync.Mutex
+	variantLocks   = make(map[string]*variantLock)
+)
+
+type variantRenderCount struct {
+	start time.Time
+	count int
+}
+
+var (
+	variantRendersMu sync.Mutex
+	variantRenders   = make(map[string]*variantRenderCount)
+)
+
+// allowVariantRender 按固定窗口统计文件的即时生成次数，超出上限时返回 false
+func allowVariantRender(fileID string) bool {
+	variantRendersMu.Lock()
+	defer variantRendersMu.Unlock()
+
+	now := time.Now()
+	if len(variantRenders) >= variantRenderSweepSize {
+		for id, rc := range variantRenders {
+			if now.Sub(rc.start) >= variantRenderWindow {
+				delete(variantRenders, id)
+			}
+		}
+	}
+
+	rc, ok := variantRenders[fileID]
+	if !ok || now.Sub(rc.start) >= variantRenderWindow {
+		variantRenders[fileID] = &variantRenderCount{start: now, count: 1}
+		return true
+	}
+	if rc.count >= maxVariantRendersPerWindow {
+		return false
+	}
+	rc.count++
+	return true
+}
+
+func lockVariant(key string) func() {
+	variantLocksMu.Lock()
+	l, ok := variantLocks[key]
+	if !ok {
+		l = &variantLock{}
+		variantLocks[key] = l
+	}
+	l.refs++
+	variantLocksMu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		variantLocksMu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(variantLocks, key)
+		}
+		variantLocksMu.Unlock()
+	}
+}
+
+/* ServeTransformedFile 返回按参数变换后的衍生图，命中缓存时直接读取存储中的副本；文件有水印策略时输出带水印的衍生图 */
+func ServeTransformedFile(file models.File, opts transform.Options) (*ProxyResponse, error) {
+	if !file.IsImage() || strings.EqualFold(file.Format, "svg") {
+		return nil, errors.New(errors.CodeFileFormatNotSupport, "该文件类型不支持图片变换")
+	}
+
+	return serveVariant(file, opts, fileWatermark(file))
+}
+
+// fileWatermark 返回文件生效的水印策略对应的衍生图水印
+func fileWatermark(file models.File) *variantWatermark {
+	if !supportsWatermark(file) {
+		return nil
+	}
+	return policyWatermark(ResolveWatermarkPolicy(file))
+}
+
+func variantKeyOf(opts transform.Options, wm *variantWatermark) string {
+	if wm != nil {
+		return wm.variantKey(opts)
+	}
+	return opts.Key()
+}
+
+func serveVariant(file models.File, opts transform.Options, wm *variantWatermark) (*ProxyResponse, error) {
+	if wm != nil && wm.transient {
+		result, err := renderVariant(file, opts, wm)
+		if err != nil {
+			return nil, err
+		}
+		return variantResponse(result), nil
+	}
+
+	variantKey := variantKeyOf(opts, wm)
+	if resp := readCachedVariant(file.ID, variantKey); resp != nil {
+		return resp, nil
+	}
+
+	unlock := lockVariant(file.ID + ":" + variantKey)
+	defer unlock()
+
+	if resp := readCachedVariant(file.ID, variantKey); resp != nil {
+		return resp, nil
+	}
+	if !allowVariantRender(file.ID) {
+		return nil, errors.New(errors.CodeRateLimited, "该文件的图片变换请求过于频繁，请稍后再试")
+	}
+
+	result, err := renderVariant(file, opts, wm)
+	if err != nil {
+		return nil, err
+	}
+
+	params := fmt.Sprintf("w=%d&h=%d&fit=%s&q=%d&fmt=%s", opts.Width, opts.Height, opts.Fit, opts.Quality, opts.Format)
+	if wm != nil {
+		// 水印配置变更时按此参数清理旧水印图
+		params += "&" + wm.tag
+	}
+	if err := storeVariant(file, variantKey, params, result); err != nil {
+		// 缓存失败不影响本次响应
+		logger.Warn("保存衍生图失败 [%s/%s]: %v", file.ID, variantKey, err)
+	}
+
+	return variantResponse(result), nil
+}
+
+// renderVariant 读取原图并生成衍生图，有水印时先合成水印
+func renderVariant(file models.File, opts transform.Options, wm *variantWatermark) (*transform.Result, error) {
+	original, err := ReadFileContent(file, false)
+	if err != nil {
+		return nil, err
+	}
+	// 先按头信息校验原图尺寸，水印合成与变换都会完整解码
+	if err := transform.CheckSourceSize(original); err != nil {
+		return nil, errors.Wrap(err, errors.CodeFileTooLarge, "原图尺寸过大，不支持图片变换")
+	}
+
+	if wm != nil {
+		return watermarkAndTransform(file, original, wm.config, opts)
+	}
+	result, err := transform.Apply(original, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeFileFormatNotSupport, "图片变换失败")
+	}
+	return result, nil
+}
+
+func variantResponse(result *transform.Result) *ProxyResponse {
+	return &ProxyResponse{
+		Content:       io.NopCloser(bytes.NewReader(result.Data)),
+		ContentType:   formats.GetContentType(result.Format),
+		ContentLength: int64(len(result.Data)),
+	}
+}
+
+func readCachedVariant(fileID, variantKey string) *ProxyResponse {
+	var variant models.FileVariant
+	if err := database.DB.Where("file_id = ? AND variant_key = ?", fileID, variantKey).First(&variant).Error; err != nil {
+		return nil
+	}
+
+	rc, err := storage.NewGlobalStorage().ReadFile(context.Background(), variant.StorageProviderID, variant.ObjectKey)
+	if err != nil {
+		// 存储中的副本已丢失，删除记录以便重新生成
+		logger.Warn("衍生图读取失败，将重新生成 [%s]: %v", variant.ObjectKey, err)
+		database.DB.Delete(&variant)
+		return nil
+	}
+
+	return &ProxyResponse{
+		Content:       rc,
+		ContentType:   formats.GetContentType(variant.Format),
+		ContentLength: variant.Size,
+	}
+}
+
+func storeVariant(file models.File, variantKey, params string, result *transform.Result) error {
+	ext := result.Format
+	if ext == transform.FormatJPEG {
+		ext = "jpg"
+	}
+	fileName := fmt.Sprintf("%s_%s.%s", file.ID, variantKey, ext)
+
+	objectKey, err := tenant.BuildObjectKey(file.UserID, variantFolder, fileName)
+	if err != nil {
+		return err
+	}
+
+	mgr := storage.NewGlobalStorage().GetManager()
+	_, err = mgr.Upload(context.Background(), file.StorageProviderID, &adapter.UploadRequest{
+		ProcessedData: result.Data,
+		UserID:        file.UserID,
+		FolderPath:    variantFolder,
+		FileName:      fileName,
+		ContentType:   formats.GetContentType(result.Format),
+		Options:       &adapter.UploadOptions{},
+	})
+	if err != nil {
+		return err
+	}
+
+	variant := models.FileVariant{
+		FileID:            file.ID,
+		VariantKey:        variantKey,
+		Params:            params,
+		StorageProviderID: file.StorageProviderID,
+		ObjectKey:         objectKey,
+		Format:            result.Format,
+		Width:             result.Width,
+		Height:            result.Height,
+		Size:              int64(len(result.Data)),
+	}
+	if err := database.DB.Create(&variant).Error; err != nil {
+		return err
+	}
+
+	evictVariants(file.ID)
+	return nil
+}
+
+// evictVariants 文件的衍生图超过上限时删除最早生成的副本，被淘汰的尺寸再次请求时重新生成
+func evictVariants(fileID string) {
+	var stale []models.FileVariant
+	if err := database.DB.Where("file_id = ?", fileID).Order("id DESC").
+		Offset(maxVariantsPerFile).Limit(maxVariantsPerFile).Find(&stale).Error; err != nil {
+		logger.Warn("查询待淘汰衍生图失败 [%s]: %v", fileID, err)
+		return
+	}
+	deleteVariants(stale)
+}
+
+/* DeleteFileVariants 删除文件的全部衍生图（存储副本与记录） */
+func DeleteFileVariants(fileID string) {
+	var variants []models.FileVariant
+	if err := database.DB.Where("file_id = ?", fileID).Find(&variants).Error; err != nil {
+		logger.Error("查询衍生图失败 [%s]: %v", fileID, err)
+		return
+	}
+	deleteVariants(variants)
+}
+
+func deleteVariants(variants []models.FileVariant) {
+	if len(variants) == 0 {
+		return
+	}
+
+	st := storage.NewGlobalStorage()
+	ids := make([]uint, 0, len(variants))
+	for _, v := range variants {
+		if err := st.Delete(context.Background(), v.StorageProviderID, v.ObjectKey); err != nil {
+			logger.Error("删除衍生图失败 %s: %v", v.ObjectKey, err)
+		}
+		ids = append(ids, v.ID)
+	}
+	if err := database.DB.Where("id IN ?", ids).Delete(&models.FileVariant{}).Error; err != nil {
+		logger.Error("删除衍生图记录失败: %v", err)
+	}
+}
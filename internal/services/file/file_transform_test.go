@@ -0,0 +1,64 @@
+package file
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLockVariantSerializesAndCleansUp(t *testing.T) {
+	var active, maxActive int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := lockVariant("f1:key")
+			n := atomic.AddInt32(&active, 1)
+			for {
+				m := atomic.LoadInt32(&maxActive)
+				if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+					break
+				}
+			}
+			atomic.AddInt32(&active, -1)
+			unlock()
+		}()
+	}
+	wg.Wait()
+
+	if maxActive != 1 {
+		t.Errorf("lock held by %d goroutines at once", maxActive)
+	}
+	variantLocksMu.Lock()
+	defer variantLocksMu.Unlock()
+	if len(variantLocks) != 0 {
+		t.Errorf("lock entries leaked: %d", len(variantLocks))
+	}
+}
+
+func TestAllowVariantRender(t *testing.T) {
+	fileID := "render-limit"
+	defer func() {
+		variantRendersMu.Lock()
+		delete(variantRenders, fileID)
+		variantRendersMu.Unlock()
+	}()
+
+	for i := 0; i < maxVariantRendersPerWindow; i++ {
+		if !allowVariantRender(fileID) {
+			t.Fatalf("render %d rejected within limit", i+1)
+		}
+	}
+	if allowVariantRender(fileID) {
+		t.Fatal("render beyond limit allowed")
+	}
+
+	variantRendersMu.Lock()
+	variantRenders[fileID].start = time.Now().Add(-variantRenderWindow)
+	variantRendersMu.Unlock()
+	if !allowVariantRender(fileID) {
+		t.Fatal("render rejected after window expired")
+	}
+}
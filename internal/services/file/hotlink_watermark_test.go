@@ -0,0 +1,49 @@
+package file
+
+import (
+	"strings"
+	"testing"
+
+	"pixelpunk/pkg/watermark"
+)
+
+func TestRenderHotlinkWatermark(t *testing.T) {
+	parse := func(raw string) *watermark.WatermarkConfig {
+		t.Helper()
+		cfg, err := watermark.ParseConfigFromJSON(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cfg
+	}
+
+	raw, perDomain, err := renderHotlinkWatermark("", "Evil.Example.COM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg := parse(raw); cfg.Text != "Hotlinked from evil.example.com" || !cfg.Tiled || !perDomain {
+		t.Errorf("default config = %+v, perDomain = %v", cfg, perDomain)
+	}
+
+	raw, _, _ = renderHotlinkWatermark(`{"type":"text","text":"via {domain}","opacity":0.5}`, "")
+	if cfg := parse(raw); cfg.Text != "via "+hotlinkUnknownDomain || !cfg.Enabled {
+		t.Errorf("custom config = %+v", cfg)
+	}
+
+	// 不含域名占位符的模板与来源无关，可以共用一份缓存
+	_, perDomain, _ = renderHotlinkWatermark(`{"type":"text","text":"no hotlinking"}`, "x.com")
+	if perDomain {
+		t.Error("static template should not be rendered per domain")
+	}
+
+	raw, _, _ = renderHotlinkWatermark(`{"type":"text","text":"{domain}"}`, strings.Repeat("a", 500))
+	if cfg := parse(raw); len([]rune(cfg.Text)) > maxHotlinkTextRunes {
+		t.Errorf("text not truncated: %d runes", len([]rune(cfg.Text)))
+	}
+
+	// 配置损坏时回退默认水印
+	raw, _, _ = renderHotlinkWatermark("{", "x.com")
+	if cfg := parse(raw); cfg.Text != "Hotlinked from x.com" {
+		t.Errorf("fallback config = %+v", cfg)
+	}
+}
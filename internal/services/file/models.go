@@ -22,26 +22,27 @@ type AIInfoResponse struct {
 }
 
 type FileDetailResponse struct {
-	ID                string            `json:"id"`
-	FullURL           string            `json:"full_url"`       // 完整的文件URL（包含域名）
-	FullThumbURL      string            `json:"full_thumb_url"` // 完整的缩略图URL（包含域名）
-	OriginalName      string            `json:"original_name"`
-	DisplayName       string            `json:"display_name"` // 显示名称
-	Size              int64             `json:"size"`
-	Width             int               `json:"width"`
-	Height            int               `json:"height"`
-	Format            string            `json:"format"`
-	AccessLevel       string            `json:"access_level"`
-	FolderID          string            `json:"folder_id,omitempty"`
-	CreatedAt         common.JSONTime   `json:"created_at"`
-	UpdatedAt         common.JSONTime   `json:"updated_at"`
-	Views             int64             `json:"views,omitempty"`
-	IsDuplicate       bool              `json:"is_duplicate,omitempty"`        // 是否是重复文件
-	MD5Hash           string            `json:"md5_hash,omitempty"`            // MD5哈希值
-	IsRecommended     bool              `json:"is_recommended"`                // 是否推荐
-	StorageProviderID string            `json:"storage_provider_id,omitempty"` // 存储提供者ID
-	AIInfo            *AIInfoResponse   `json:"ai_info,omitempty"`
-	EXIFInfo          *imodels.FileEXIF `json:"exif_info,omitempty"` // EXIF 元数据
+	ID                string              `json:"id"`
+	FullURL           string              `json:"full_url"`       // 完整的文件URL（包含域名）
+	FullThumbURL      string              `json:"full_thumb_url"` // 完整的缩略图URL（包含域名）
+	OriginalName      string              `json:"original_name"`
+	DisplayName       string              `json:"display_name"` // 显示名称
+	Size              int64               `json:"size"`
+	Width             int                 `json:"width"`
+	Height            int                 `json:"height"`
+	Format            string              `json:"format"`
+	AccessLevel       string              `json:"access_level"`
+	FolderID          string              `json:"folder_id,omitempty"`
+	CreatedAt         common.JSONTime     `json:"created_at"`
+	UpdatedAt         common.JSONTime     `json:"updated_at"`
+	Views             int64               `json:"views,omitempty"`
+	IsDuplicate       bool                `json:"is_duplicate,omitempty"`        // 是否是重复文件
+	MD5Hash           string              `json:"md5_hash,omitempty"`            // MD5哈希值
+	IsRecommended     bool                `json:"is_recommended"`                // 是否推荐
+	StorageProviderID string              `json:"storage_provider_id,omitempty"` // 存储提供者ID
+	AIInfo            *AIInfoResponse     `json:"ai_info,omitempty"`
+	EXIFInfo          *imodels.FileEXIF   `json:"exif_info,omitempty"`  // EXIF 元数据
+	Renditions        []RenditionResponse `json:"renditions,omitempty"` // 响应式衍生尺寸（srcset）
 }
 
 /* ExternalAPIFileResponse 外部API文件响应结构（简化版） */
@@ -3,11 +3,10 @@ package file
 import (
 	"fmt"
 	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/workspace"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 	"strings"
-
-	"gorm.io/gorm"
 )
 
 const (
@@ -25,29 +24,24 @@ func MoveFiles(userID uint, fileIDs []string, targetFolderID string) error {
 		return errors.New(errors.CodeInvalidParameter, fmt.Sprintf("一次最多移动%d个文件", MAX_BATCH_MOVE_FILES))
 	}
 
-	if targetFolderID != "" {
-		var targetFolder models.Folder
-		if err := database.DB.Where("id = ? AND user_id = ?", targetFolderID, userID).First(&targetFolder).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return errors.New(errors.CodeFolderNotFound, "目标文件夹不存在")
-			}
-			return errors.Wrap(err, errors.CodeDBQueryFailed, "查询目标文件夹失败")
+	workspaceID := ""
+	for i, fileID := range fileIDs {
+		file, err := workspace.ResolveFile(userID, fileID, workspace.ActionEdit)
+		if err != nil {
+			return errors.New(errors.CodeInvalidParameter, "部分文件不存在或无权限")
+		}
+		if i == 0 {
+			workspaceID = file.WorkspaceID
+		} else if file.WorkspaceID != workspaceID {
+			return errors.New(errors.CodeInvalidParameter, "不能同时移动不同空间的文件")
 		}
 	}
-
-	var count int64
-	if err := database.DB.Model(&models.File{}).
-		Where("id IN ? AND user_id = ?", fileIDs, userID).
-		Count(&count).Error; err != nil {
-		return errors.Wrap(err, errors.CodeDBQueryFailed, "验证文件所属失败")
-	}
-
-	if count != int64(len(fileIDs)) {
-		return errors.New(errors.CodeInvalidParameter, "部分文件不存在或无权限")
+	if err := checkTargetFolder(userID, workspaceID, targetFolderID); err != nil {
+		return err
 	}
 
 	result := database.DB.Model(&models.File{}).
-		Where("id IN ? AND user_id = ?", fileIDs, userID).
+		Where("id IN ?", fileIDs).
 		Update("folder_id", targetFolderID)
 	if result.Error != nil {
 		return errors.Wrap(result.Error, errors.CodeDBUpdateFailed, "移动文件失败")
@@ -69,8 +63,12 @@ func ReorderFiles(userID uint, folderID string, fileIDs []string) error {
 		return errors.New(errors.CodeInvalidParameter, fmt.Sprintf("一次最多排序%d个文件", MAX_BATCH_MOVE_FILES))
 	}
 
+	scope, err := fileFolderScope(userID, folderID, workspace.ActionEdit)
+	if err != nil {
+		return err
+	}
 	var count int64
-	query := database.DB.Model(&models.File{}).Where("user_id = ? AND id IN ?", userID, fileIDs)
+	query := database.DB.Model(&models.File{}).Scopes(scope).Where("id IN ?", fileIDs)
 
 	if folderID == "" {
 		query = query.Where("folder_id = '' OR folder_id IS NULL")
@@ -106,8 +104,12 @@ func ReorderFiles(userID uint, folderID string, fileIDs []string) error {
 
 /* GetMovableImageCount 获取可移动的文件数量 */
 func GetMovableFileCount(userID uint, folderID string) (int64, error) {
+	scope, err := fileFolderScope(userID, folderID, workspace.ActionView)
+	if err != nil {
+		return 0, err
+	}
 	var count int64
-	query := database.DB.Model(&models.File{}).Where("user_id = ?", userID)
+	query := database.DB.Model(&models.File{}).Scopes(scope)
 
 	if folderID == "" {
 		query = query.Where("folder_id = '' OR folder_id IS NULL")
@@ -0,0 +1,423 @@
+package file
+
+/* Perceptual-hash (dHash) near-duplicate lookup backed by the file_phash_band index. */
+
+import (
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	imgHash "pixelpunk/pkg/imagex/hash"
+	"pixelpunk/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultNearDuplicateDistance = 6
+	// 分段索引只能保证汉明距离小于分段数量时不漏检
+	MaxNearDuplicateDistance = imgHash.DHashBands - 1
+
+	maxNearDuplicateScan = 20000
+)
+
+/* NearDuplicateItem 近似重复文件 */
+type NearDuplicateItem struct {
+	FileDetailResponse
+	UserID   uint   `json:"user_id"`
+	PHash    string `json:"phash"`
+	Distance int    `json:"distance"`
+}
+
+/* NearDuplicateGroup 一组视觉上相同的文件 */
+type NearDuplicateGroup struct {
+	UserID uint                `json:"user_id"`
+	Files  []NearDuplicateItem `json:"files"`
+}
+
+func clampNearDuplicateDistance(d int) int {
+	if d <= 0 {
+		return DefaultNearDuplicateDistance
+	}
+	if d > MaxNearDuplicateDistance {
+		return MaxNearDuplicateDistance
+	}
+	return d
+}
+
+func savePHashBands(tx *gorm.DB, file *models.File) error {
+	if file.PHash == "" {
+		return nil
+	}
+	h, err := imgHash.ParsePerceptual(file.PHash)
+	if err != nil {
+		return nil
+	}
+
+	bands := imgHash.Bands(h)
+	rows := make([]models.FilePHashBand, 0, len(bands))
+	for i, v := range bands {
+		rows = append(rows, models.FilePHashBand{FileID: file.ID, UserID: file.UserID, Band: uint8(i), Value: v})
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBCreateFailed, "保存感知哈希索引失败")
+	}
+	return nil
+}
+
+/* DeleteFilePHashBands 删除文件的感知哈希索引 */
+func DeleteFilePHashBands(fileID string) {
+	if err := database.DB.Where("file_id = ?", fileID).Delete(&models.FilePHashBand{}).Error; err != nil {
+		logger.Error("删除感知哈希索引失败 [%s]: %v", fileID, err)
+	}
+}
+
+/* FindNearDuplicates 查找与指定文件视觉相同的文件，userID 为 0 时跨用户查找（管理员） */
+func FindNearDuplicates(userID uint, fileID string, maxDistance int) ([]NearDuplicateItem, error) {
+	maxDistance = clampNearDuplicateDistance(maxDistance)
+
+	query := database.DB.Where("id = ?", fileID)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	var target models.File
+	if err := query.First(&target).Error; err != nil {
+		return nil, errors.New(errors.CodeFileNotFound, "文件不存在或无权访问")
+	}
+	if target.PHash == "" {
+		return nil, errors.New(errors.CodeInvalidParameter, "该文件尚未计算感知哈希")
+	}
+	targetHash, err := imgHash.ParsePerceptual(target.PHash)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "感知哈希格式错误")
+	}
+
+	matches, err := findPHashMatches(targetHash, maxDistance, userID, target.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]NearDuplicateItem, 0, len(matches))
+	for _, m := range matches {
+		items = append(items, buildNearDuplicateItem(m.File, m.Distance))
+	}
+	return items, nil
+}
+
+type phashMatch struct {
+	File     models.File
+	Distance int
+}
+
+// findPHashMatches 通过分段索引召回候选并按汉明距离精确过滤，结果按距离升序；
+// userID 为 0 时不限用户，fileScope 可进一步限定候选文件
+func findPHashMatches(target uint64, maxDistance int, userID uint, excludeID string, fileScope func(*gorm.DB) *gorm.DB) ([]phashMatch, error) {
+	conds := make([]string, 0, imgHash.DHashBands)
+	args := make([]interface{}, 0, imgHash.DHashBands*2)
+	for i, v := range imgHash.Bands(target) {
+		conds = append(conds, "(band = ? AND value = ?)")
+		args = append(args, i, v)
+	}
+
+	bandQuery := database.DB.Model(&models.FilePHashBand{}).
+		Where(strings.Join(conds, " OR "), args...)
+	if excludeID != "" {
+		bandQuery = bandQuery.Where("file_id <> ?", excludeID)
+	}
+	if userID != 0 {
+		bandQuery = bandQuery.Where("user_id = ?", userID)
+	}
+	var candidateIDs []string
+	if err := bandQuery.Distinct().Pluck("file_id", &candidateIDs).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询近似重复文件失败")
+	}
+	if len(candidateIDs) == 0 {
+		return []phashMatch{}, nil
+	}
+
+	fileQuery := database.DB.Where("id IN ?", candidateIDs).
+		Where("status <> ?", StatusPendingDeletion)
+	if fileScope != nil {
+		fileQuery = fileScope(fileQuery)
+	}
+	var candidates []models.File
+	if err := fileQuery.Find(&candidates).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询近似重复文件失败")
+	}
+
+	matches := make([]phashMatch, 0, len(candidates))
+	for _, f := range candidates {
+		h, err := imgHash.ParsePerceptual(f.PHash)
+		if err != nil {
+			continue
+		}
+		if d := imgHash.Hamming(target, h); d <= maxDistance {
+			matches = append(matches, phashMatch{File: f, Distance: d})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Distance < matches[j].Distance })
+	return matches, nil
+}
+
+/* ListNearDuplicateGroups 列出视觉相同的文件分组，userID 为 0 时扫描全部用户（分组不跨用户） */
+func ListNearDuplicateGroups(userID uint, maxDistance, limit int) ([]NearDuplicateGroup, error) {
+	maxDistance = clampNearDuplicateDistance(maxDistance)
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	type hashRow struct {
+		ID     string
+		UserID uint
+		PHash  string `gorm:"column:phash"`
+	}
+	query := database.DB.Model(&models.File{}).
+		Select("id, user_id, phash").
+		Where("phash <> ''").
+		Where("status <> ?", StatusPendingDeletion)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	var rows []hashRow
+	if err := query.Order("created_at DESC").Limit(maxNearDuplicateScan).Scan(&rows).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询感知哈希失败")
+	}
+
+	hashes := make([]uint64, len(rows))
+	valid := make([]bool, len(rows))
+	for i, r := range rows {
+		if h, err := imgHash.ParsePerceptual(r.PHash); err == nil {
+			hashes[i], valid[i] = h, true
+		}
+	}
+
+	// 与数据库索引相同的分段策略：任一分段相同才进行精确比较
+	type bucketKey struct {
+		userID uint
+		band   int
+		value  uint8
+	}
+	buckets := make(map[bucketKey][]int)
+	for i := range rows {
+		if !valid[i] {
+			continue
+		}
+		for b, v := range imgHash.Bands(hashes[i]) {
+			k := bucketKey{rows[i].UserID, b, v}
+			buckets[k] = append(buckets[k], i)
+		}
+	}
+
+	parent := make([]int, len(rows))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+	for _, members := range buckets {
+		for i := 0; i < len(members); i++ {
+			for j := i + 1; j < len(members); j++ {
+				a, b := members[i], members[j]
+				if find(a) == find(b) {
+					continue
+				}
+				if imgHash.Hamming(hashes[a], hashes[b]) <= maxDistance {
+					parent[find(a)] = find(b)
+				}
+			}
+		}
+	}
+
+	clusters := make(map[int][]int)
+	for i := range rows {
+		if valid[i] {
+			root := find(i)
+			clusters[root] = append(clusters[root], i)
+		}
+	}
+	grouped := make([][]int, 0)
+	for _, members := range clusters {
+		if len(members) > 1 {
+			grouped = append(grouped, members)
+		}
+	}
+	sort.Slice(grouped, func(i, j int) bool {
+		if len(grouped[i]) != len(grouped[j]) {
+			return len(grouped[i]) > len(grouped[j])
+		}
+		return rows[grouped[i][0]].ID < rows[grouped[j][0]].ID
+	})
+	if len(grouped) > limit {
+		grouped = grouped[:limit]
+	}
+
+	ids := make([]string, 0)
+	for _, members := range grouped {
+		for _, idx := range members {
+			ids = append(ids, rows[idx].ID)
+		}
+	}
+	fileMap := make(map[string]models.File, len(ids))
+	if len(ids) > 0 {
+		var files []models.File
+		if err := database.DB.Where("id IN ?", ids).Find(&files).Error; err != nil {
+			return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询近似重复文件失败")
+		}
+		for _, f := range files {
+			fileMap[f.ID] = f
+		}
+	}
+
+	groups := make([]NearDuplicateGroup, 0, len(grouped))
+	for _, members := range grouped {
+		// 组内以最早出现的文件为基准计算距离
+		anchor := members[len(members)-1]
+		group := NearDuplicateGroup{UserID: rows[anchor].UserID}
+		for k := len(members) - 1; k >= 0; k-- {
+			idx := members[k]
+			if f, ok := fileMap[rows[idx].ID]; ok {
+				group.Files = append(group.Files, buildNearDuplicateItem(f, imgHash.Hamming(hashes[anchor], hashes[idx])))
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+/* MergeNearDuplicates 保留一个文件并删除与其视觉相同的其他文件 */
+func MergeNearDuplicates(userID uint, keepID string, duplicateIDs []string, maxDistance int) ([]string, error) {
+	maxDistance = clampNearDuplicateDistance(maxDistance)
+	if keepID == "" || len(duplicateIDs) == 0 {
+		return nil, errors.New(errors.CodeInvalidParameter, "请指定保留文件与待合并文件")
+	}
+
+	var keep models.File
+	if err := database.DB.Where("id = ? AND user_id = ?", keepID, userID).
+		Where("status <> ?", StatusPendingDeletion).
+		First(&keep).Error; err != nil {
+		return nil, errors.New(errors.CodeFileNotFound, "保留文件不存在或无权访问")
+	}
+	keepHash, err := imgHash.ParsePerceptual(keep.PHash)
+	if err != nil {
+		return nil, errors.New(errors.CodeInvalidParameter, "保留文件尚未计算感知哈希")
+	}
+
+	var duplicates []models.File
+	if err := database.DB.Where("id IN ? AND user_id = ? AND id <> ?", duplicateIDs, userID, keepID).
+		Where("status <> ?", StatusPendingDeletion).
+		Find(&duplicates).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询待合并文件失败")
+	}
+	if len(duplicates) == 0 {
+		return nil, errors.New(errors.CodeFileNotFound, "待合并文件不存在或无权访问")
+	}
+
+	for _, f := range duplicates {
+		h, err := imgHash.ParsePerceptual(f.PHash)
+		if err != nil || imgHash.Hamming(keepHash, h) > maxDistance {
+			return nil, errors.New(errors.CodeInvalidParameter, "文件 "+f.ID+" 与保留文件不是近似重复")
+		}
+	}
+
+	merged := make([]string, 0, len(duplicates))
+	for _, f := range duplicates {
+		if err := DeleteFile(userID, f.ID); err != nil {
+			logger.Warn("合并近似重复文件失败 [%s]: %v", f.ID, err)
+			continue
+		}
+		merged = append(merged, f.ID)
+	}
+	return merged, nil
+}
+
+func buildNearDuplicateItem(f models.File, distance int) NearDuplicateItem {
+	return NearDuplicateItem{
+		FileDetailResponse: BuildFileDetailResponse(f, 0, nil),
+		UserID:             f.UserID,
+		PHash:              f.PHash,
+		Distance:           distance,
+	}
+}
+
+var phashBackfillRunning int32
+
+/* StartPHashBackfill 后台为历史图片补算感知哈希，已在运行时返回 false */
+func StartPHashBackfill(batchSize int) bool {
+	if !atomic.CompareAndSwapInt32(&phashBackfillRunning, 0, 1) {
+		return false
+	}
+	if batchSize <= 0 {
+		batchSize = 200
+	}
+
+	go func() {
+		defer atomic.StoreInt32(&phashBackfillRunning, 0)
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("感知哈希补算 panic: %v", r)
+			}
+		}()
+
+		total, failed := 0, 0
+		lastID := ""
+		for {
+			var files []models.File
+			if err := database.DB.Where("phash = '' OR phash IS NULL").
+				Where("file_type = ? AND status <> ? AND id > ?", models.FileTypeImage, StatusPendingDeletion, lastID).
+				Order("id ASC").Limit(batchSize).Find(&files).Error; err != nil {
+				logger.Error("查询待补算感知哈希的文件失败: %v", err)
+				return
+			}
+			if len(files) == 0 {
+				break
+			}
+
+			for i := range files {
+				f := &files[i]
+				lastID = f.ID
+				if err := backfillFilePHash(f); err != nil {
+					failed++
+					logger.Warn("补算感知哈希失败 [%s]: %v", f.ID, err)
+					continue
+				}
+				total++
+			}
+		}
+		logger.Info("感知哈希补算完成，成功: %d, 失败: %d", total, failed)
+	}()
+	return true
+}
+
+func backfillFilePHash(f *models.File) error {
+	data, err := ReadFileContent(*f, false)
+	if err != nil && f.ThumbURL != "" {
+		// 原图不可读时退回缩略图，dHash 对缩放不敏感
+		data, err = ReadFileContent(*f, true)
+	}
+	if err != nil {
+		return err
+	}
+	h, err := imgHash.DHashFromBytes(data)
+	if err != nil {
+		return err
+	}
+
+	f.PHash = imgHash.FormatPerceptual(h)
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.File{}).Where("id = ?", f.ID).Update("phash", f.PHash).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("file_id = ?", f.ID).Delete(&models.FilePHashBand{}).Error; err != nil {
+			return err
+		}
+		return savePHashBands(tx, f)
+	})
+}
@@ -0,0 +1,255 @@
+package file
+
+/* Responsive renditions: admin-defined size presets stored as cached image variants and exposed as srcset entries. */
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/imagex/convert"
+	"pixelpunk/pkg/imagex/formats"
+	"pixelpunk/pkg/imagex/transform"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/utils"
+)
+
+const (
+	RenditionModeUpload = "upload" // 上传完成后立即生成
+	RenditionModeLazy   = "lazy"   // 首次访问时生成
+)
+
+var renditionNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+/* RenditionPreset 衍生尺寸预设，如 small=320w webp q80 */
+type RenditionPreset struct {
+	Name    string `json:"name"`
+	Width   int    `json:"width"`
+	Format  string `json:"format"`
+	Quality int    `json:"quality"`
+}
+
+/* ParseRenditionPresets 解析预设配置，多个预设以逗号、分号或换行分隔，格式为 名称=宽度w [格式] [q质量] */
+func ParseRenditionPresets(raw string) ([]RenditionPreset, error) {
+	entries := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == ';' || r == '\n'
+	})
+
+	presets := make([]RenditionPreset, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, spec, ok := strings.Cut(entry, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || !renditionNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("预设 %q 名称无效，仅支持小写字母、数字、下划线与短横线", entry)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("预设名称 %s 重复", name)
+		}
+		seen[name] = true
+
+		fields := strings.Fields(strings.ToLower(spec))
+		if len(fields) == 0 || !strings.HasSuffix(fields[0], "w") {
+			return nil, fmt.Errorf("预设 %s 缺少宽度，例如 %s=320w", name, name)
+		}
+		width, err := strconv.Atoi(strings.TrimSuffix(fields[0], "w"))
+		if err != nil || width <= 0 || width > transform.MaxDimension {
+			return nil, fmt.Errorf("预设 %s 宽度无效，取值范围 1-%d", name, transform.MaxDimension)
+		}
+
+		preset := RenditionPreset{Name: name, Width: width, Format: transform.FormatAuto}
+		for _, field := range fields[1:] {
+			switch {
+			case field == "jpg" || field == transform.FormatJPEG:
+				preset.Format = transform.FormatJPEG
+			case field == transform.FormatPNG || field == transform.FormatWebP || field == transform.FormatAVIF || field == transform.FormatAuto:
+				preset.Format = field
+			case strings.HasPrefix(field, "q"):
+				q, err := strconv.Atoi(strings.TrimPrefix(field, "q"))
+				if err != nil || q < 1 || q > 100 {
+					return nil, fmt.Errorf("预设 %s 质量无效，取值范围 q1-q100", name)
+				}
+				preset.Quality = q
+			default:
+				return nil, fmt.Errorf("预设 %s 参数 %q 无法识别", name, field)
+			}
+		}
+		presets = append(presets, preset)
+	}
+
+	sort.SliceStable(presets, func(i, j int) bool { return presets[i].Width < presets[j].Width })
+	return presets, nil
+}
+
+/* RenditionPresets 返回系统配置的衍生尺寸预设，配置无效时视为未配置 */
+func RenditionPresets() []RenditionPreset {
+	raw := setting.GetString("upload", "rendition_presets", "")
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	presets, err := ParseRenditionPresets(raw)
+	if err != nil {
+		logger.Warn("衍生尺寸预设配置无效: %v", err)
+		return nil
+	}
+	return presets
+}
+
+/* RenditionMode 衍生尺寸生成时机 */
+func RenditionMode() string {
+	if setting.GetString("upload", "rendition_mode", RenditionModeLazy) == RenditionModeUpload {
+		return RenditionModeUpload
+	}
+	return RenditionModeLazy
+}
+
+/* FindRenditionPreset 按名称查找预设 */
+func FindRenditionPreset(name string) (*RenditionPreset, bool) {
+	for _, p := range RenditionPresets() {
+		if p.Name == name {
+			return &p, true
+		}
+	}
+	return nil, false
+}
+
+/* Options 预设对应的变换参数；未安装 AVIF 编码器时回退为 WebP */
+func (p RenditionPreset) Options() transform.Options {
+	format := p.Format
+	if format == transform.FormatAVIF && !convert.AVIFAvailable() {
+		format = transform.FormatWebP
+	}
+	return transform.Options{Width: p.Width, Fit: transform.FitContain, Quality: p.Quality, Format: format}
+}
+
+// supportsRenditions 仅位图生成衍生尺寸
+func supportsRenditions(file models.File) bool {
+	return file.IsImage() && !strings.EqualFold(file.Format, "svg")
+}
+
+// applicableRenditions 返回对文件有意义的预设：不放大，宽度不小于原图的预设跳过
+func applicableRenditions(file models.File) []RenditionPreset {
+	if !supportsRenditions(file) {
+		return nil
+	}
+	var result []RenditionPreset
+	for _, p := range RenditionPresets() {
+		if file.Width > 0 && p.Width >= file.Width {
+			continue
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+/* RenditionResponse 可直接用于 srcset 的衍生尺寸 */
+type RenditionResponse struct {
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	Descriptor string `json:"descriptor"` // srcset 宽度描述符，如 320w
+	Width      int    `json:"width"`
+	Height     int    `json:"height,omitempty"`
+	Format     string `json:"format"`
+	MimeType   string `json:"mime_type"`
+	Size       int64  `json:"size,omitempty"` // 已生成时的实际大小
+	Ready      bool   `json:"ready"`          // 是否已生成
+}
+
+/* BuildRenditions 构建文件的衍生尺寸列表，按宽度升序 */
+func BuildRenditions(file models.File) []RenditionResponse {
+	presets := applicableRenditions(file)
+	if len(presets) == 0 {
+		return nil
+	}
+
+	// 有水印策略时衍生图按水印键缓存，需与 serveVariant 使用相同的键
+	wm := fileWatermark(file)
+	keys := make([]string, 0, len(presets))
+	for _, p := range presets {
+		keys = append(keys, variantKeyOf(p.Options(), wm))
+	}
+	var variants []models.FileVariant
+	if err := database.DB.Where("file_id = ? AND variant_key IN ?", file.ID, keys).Find(&variants).Error; err != nil {
+		logger.Warn("查询衍生尺寸失败 [%s]: %v", file.ID, err)
+	}
+	byKey := make(map[string]models.FileVariant, len(variants))
+	for _, v := range variants {
+		byKey[v.VariantKey] = v
+	}
+
+	baseURL := utils.GetFileFullURL(file.ID)
+	result := make([]RenditionResponse, 0, len(presets))
+	for _, p := range presets {
+		opts := p.Options()
+		r := RenditionResponse{
+			Name:   p.Name,
+			URL:    baseURL + "?rendition=" + url.QueryEscape(p.Name),
+			Width:  p.Width,
+			Format: opts.Format,
+		}
+		if file.Width > 0 && file.Height > 0 {
+			r.Height = (file.Height*p.Width + file.Width/2) / file.Width
+		}
+		if v, ok := byKey[variantKeyOf(opts, wm)]; ok {
+			r.Width, r.Height, r.Format, r.Size, r.Ready = v.Width, v.Height, v.Format, v.Size, true
+		}
+		if r.Format == transform.FormatAuto {
+			r.Format = autoRenditionFormat(file.Format)
+		}
+		r.MimeType = formats.GetContentType(r.Format)
+		r.Descriptor = strconv.Itoa(r.Width) + "w"
+		result = append(result, r)
+	}
+	return result
+}
+
+// autoRenditionFormat 与 transform 的 auto 规则一致：png/webp 保持原格式，gif 转 png，其余转 jpeg
+func autoRenditionFormat(original string) string {
+	switch strings.ToLower(original) {
+	case "png", "webp":
+		return strings.ToLower(original)
+	case "gif":
+		return transform.FormatPNG
+	default:
+		return transform.FormatJPEG
+	}
+}
+
+/* ServeRendition 返回指定名称的衍生尺寸，未生成时即时生成并缓存 */
+func ServeRendition(file models.File, name string) (*ProxyResponse, error) {
+	preset, ok := FindRenditionPreset(name)
+	if !ok {
+		return nil, errors.New(errors.CodeInvalidParameter, "衍生尺寸预设不存在")
+	}
+	return ServeTransformedFile(file, preset.Options())
+}
+
+/* GenerateRenditions 为文件生成全部缺失的衍生尺寸 */
+func GenerateRenditions(file models.File) {
+	wm := fileWatermark(file)
+	for _, p := range applicableRenditions(file) {
+		opts := p.Options()
+		var count int64
+		database.DB.Model(&models.FileVariant{}).Where("file_id = ? AND variant_key = ?", file.ID, variantKeyOf(opts, wm)).Count(&count)
+		if count > 0 {
+			continue
+		}
+		resp, err := serveVariant(file, opts, wm)
+		if err != nil {
+			logger.Warn("生成衍生尺寸失败 [%s/%s]: %v", file.ID, p.Name, err)
+			continue
+		}
+		resp.Content.Close()
+	}
+}
@@ -0,0 +1,29 @@
+package file
+
+import "testing"
+
+func TestParseRenditionPresets(t *testing.T) {
+	presets, err := ParseRenditionPresets("medium=1024w avif q60, small=320w webp;\nthumb=160w")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RenditionPreset{
+		{Name: "thumb", Width: 160, Format: "auto"},
+		{Name: "small", Width: 320, Format: "webp"},
+		{Name: "medium", Width: 1024, Format: "avif", Quality: 60},
+	}
+	if len(presets) != len(want) {
+		t.Fatalf("got %d presets, want %d", len(presets), len(want))
+	}
+	for i := range want {
+		if presets[i] != want[i] {
+			t.Errorf("preset %d = %+v, want %+v", i, presets[i], want[i])
+		}
+	}
+
+	for _, bad := range []string{"small", "small=320", "Small Size=320w", "small=320w gif", "small=320w q0", "a=1w, a=2w", "big=99999w"} {
+		if _, err := ParseRenditionPresets(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
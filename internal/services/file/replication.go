@@ -0,0 +1,562 @@
+package file
+
+/* Replicated storage: policies keep copies of files on extra channels, reads fail over between them. */
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/storage"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+	newstorage "pixelpunk/pkg/storage"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxReplicaAttempts      = 5
+	replicaAuditInterval    = 24 * time.Hour
+	channelReadStateTTL     = 30 * time.Second
+	channelHealthTimeout    = 3 * time.Second
+	defaultReplicaBatchSize = 100
+	channelFailureThreshold = 3
+)
+
+var replicaRepairRunning int32
+
+// replicaScanCursors 每个策略渠道上次扫描到的文件ID，下一轮从其后继续，避免被跳过的文件反复占满批次；
+// 只在 RepairReplicas 内访问，由 replicaRepairRunning 保证串行
+var replicaScanCursors = make(map[string]string)
+
+/* SetReplicationPolicy 设置文件、文件夹或用户的副本渠道，channelIDs 为空时删除策略 */
+func SetReplicationPolicy(scopeType, scopeID string, channelIDs []string) (*models.ReplicationPolicy, error) {
+	userID, err := resolveReplicationScope(scopeType, scopeID)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := normalizeList(strings.Join(channelIDs, ","))
+	for _, id := range splitChannelIDs(channels) {
+		if _, err := storage.GetChannelByID(id); err != nil {
+			return nil, errors.New(errors.CodeStorageProviderNotFound, fmt.Sprintf("存储渠道不存在: %s", id))
+		}
+	}
+
+	var policy models.ReplicationPolicy
+	err = database.DB.Where("scope_type = ? AND scope_id = ?", scopeType, scopeID).First(&policy).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询副本策略失败")
+	}
+	exists := err == nil
+
+	if channels == "" {
+		if exists {
+			if err := DeleteReplicationPolicy(policy.ID); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+
+	policy.UserID = userID
+	policy.ScopeType = scopeType
+	policy.ScopeID = scopeID
+	policy.ChannelIDs = channels
+	if err := database.DB.Save(&policy).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "保存副本策略失败")
+	}
+
+	go func() {
+		if exists {
+			pruneScopeReplicas(policy)
+		}
+		if _, err := RepairReplicas(defaultReplicaBatchSize); err != nil {
+			logger.Warn("副本修复失败: %v", err)
+		}
+	}()
+	return &policy, nil
+}
+
+/* ListReplicationPolicies 分页获取副本策略 */
+func ListReplicationPolicies(page, size int) ([]models.ReplicationPolicy, int64, error) {
+	query := database.DB.Model(&models.ReplicationPolicy{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询副本策略失败")
+	}
+
+	var policies []models.ReplicationPolicy
+	if err := query.Order("id DESC").Offset((page - 1) * size).Limit(size).Find(&policies).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询副本策略失败")
+	}
+	return policies, total, nil
+}
+
+/* DeleteReplicationPolicy 删除副本策略，并清理不再需要的副本 */
+func DeleteReplicationPolicy(policyID uint) error {
+	var policy models.ReplicationPolicy
+	if err := database.DB.First(&policy, policyID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New(errors.CodeNotFound, "副本策略不存在")
+		}
+		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询副本策略失败")
+	}
+	if err := database.DB.Delete(&policy).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除副本策略失败")
+	}
+	go pruneScopeReplicas(policy)
+	return nil
+}
+
+/* ListFileReplicas 获取文件的全部副本 */
+func ListFileReplicas(fileID string) ([]models.FileReplica, error) {
+	var replicas []models.FileReplica
+	if err := database.DB.Where("file_id = ?", fileID).Order("id ASC").Find(&replicas).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件副本失败")
+	}
+	return replicas, nil
+}
+
+/* DeleteFileReplicas 删除文件的全部副本记录，deleteObjects 为 true 时同时删除副本存储对象 */
+func DeleteFileReplicas(file models.File, deleteObjects bool) {
+	fileID := file.ID
+	replicas, err := ListFileReplicas(fileID)
+	if err != nil || len(replicas) == 0 {
+		return
+	}
+	for _, r := range replicas {
+		if deleteObjects && r.Status == models.FileReplicaStatusReady {
+			deleteChannelObjects(replicaView(file, r))
+		}
+	}
+	if err := database.DB.Where("file_id = ?", fileID).Delete(&models.FileReplica{}).Error; err != nil {
+		logger.Error("删除文件副本记录失败 [%s]: %v", fileID, err)
+	}
+}
+
+/* RepairReplicas 补齐缺失的副本、重试失败的副本并巡检已有副本，返回本轮成功复制的数量 */
+func RepairReplicas(limit int) (int, error) {
+	if !atomic.CompareAndSwapInt32(&replicaRepairRunning, 0, 1) {
+		return 0, nil
+	}
+	defer atomic.StoreInt32(&replicaRepairRunning, 0)
+
+	if limit <= 0 {
+		limit = defaultReplicaBatchSize
+	}
+	if err := enqueueMissingReplicas(limit); err != nil {
+		return 0, err
+	}
+	auditReadyReplicas(limit)
+
+	var replicas []models.FileReplica
+	if err := database.DB.Where("status = ? OR (status = ? AND attempts < ?)",
+		models.FileReplicaStatusPending, models.FileReplicaStatusFailed, maxReplicaAttempts).
+		Order("updated_at ASC").Limit(limit).Find(&replicas).Error; err != nil {
+		return 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询待复制副本失败")
+	}
+
+	repaired := 0
+	for _, r := range replicas {
+		if err := syncReplica(r); err != nil {
+			logger.Warn("复制文件副本失败 [%s -> %s]: %v", r.FileID, r.ChannelID, err)
+			continue
+		}
+		repaired++
+	}
+	return repaired, nil
+}
+
+// enqueueMissingReplicas 按策略为缺少副本的文件创建待复制记录
+func enqueueMissingReplicas(limit int) error {
+	var policies []models.ReplicationPolicy
+	if err := database.DB.Find(&policies).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询副本策略失败")
+	}
+
+	for _, policy := range policies {
+		for _, channelID := range splitChannelIDs(policy.ChannelIDs) {
+			cursorKey := fmt.Sprintf("%d:%s", policy.ID, channelID)
+			var files []models.File
+			err := scopeFilesQuery(policy).
+				Where("storage_provider_id <> ?", channelID).
+				Where("id NOT IN (?)", database.DB.Model(&models.FileReplica{}).Select("file_id").Where("channel_id = ?", channelID)).
+				Where("id > ?", replicaScanCursors[cursorKey]).
+				Order("id ASC").Limit(limit).Find(&files).Error
+			if err != nil {
+				return errors.Wrap(err, errors.CodeDBQueryFailed, "查询待复制文件失败")
+			}
+			if len(files) < limit {
+				// 已扫描到末尾，下一轮从头开始
+				delete(replicaScanCursors, cursorKey)
+			} else {
+				replicaScanCursors[cursorKey] = files[len(files)-1].ID
+			}
+			for _, file := range files {
+				// 更具体的策略（文件 > 文件夹 > 用户）可能排除了该渠道
+				if !containsString(desiredReplicaChannels(file), channelID) {
+					continue
+				}
+				replica := models.FileReplica{FileID: file.ID, ChannelID: channelID, Status: models.FileReplicaStatusPending}
+				if err := database.DB.Create(&replica).Error; err != nil {
+					logger.Warn("创建副本记录失败 [%s -> %s]: %v", file.ID, channelID, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// auditReadyReplicas 巡检长时间未检查的副本，丢失的副本重新进入复制队列
+func auditReadyReplicas(limit int) {
+	var replicas []models.FileReplica
+	if err := database.DB.Where("status = ? AND (last_checked_at IS NULL OR last_checked_at < ?)",
+		models.FileReplicaStatusReady, time.Now().Add(-replicaAuditInterval)).
+		Order("last_checked_at ASC").Limit(limit).Find(&replicas).Error; err != nil {
+		logger.Warn("查询待巡检副本失败: %v", err)
+		return
+	}
+
+	for _, r := range replicas {
+		var file models.File
+		if err := database.DB.Where("id = ?", r.FileID).First(&file).Error; err != nil {
+			continue
+		}
+		now := common.JSONTime(time.Now())
+		updates := map[string]interface{}{"last_checked_at": &now}
+		if !replicaObjectExists(replicaView(file, r)) {
+			logger.Warn("文件副本丢失，重新复制 [%s -> %s]", r.FileID, r.ChannelID)
+			updates["status"] = models.FileReplicaStatusPending
+			updates["attempts"] = 0
+		}
+		database.DB.Model(&models.FileReplica{}).Where("id = ?", r.ID).Updates(updates)
+	}
+}
+
+func syncReplica(r models.FileReplica) error {
+	var file models.File
+	if err := database.DB.Where("id = ?", r.FileID).First(&file).Error; err != nil {
+		database.DB.Delete(&models.FileReplica{}, r.ID)
+		return nil
+	}
+	if file.StorageProviderID == r.ChannelID {
+		// 主渠道已迁移到该渠道，副本记录不再需要
+		database.DB.Delete(&models.FileReplica{}, r.ID)
+		return nil
+	}
+
+	target, err := storage.GetChannelByID(r.ChannelID)
+	if err != nil {
+		database.DB.Delete(&models.FileReplica{}, r.ID)
+		return errors.New(errors.CodeStorageProviderNotFound, "副本渠道不存在")
+	}
+
+	cp, err := copyFileToChannel(file, target, false)
+	if err != nil {
+		database.DB.Model(&models.FileReplica{}).Where("id = ?", r.ID).Updates(map[string]interface{}{
+			"status":     models.FileReplicaStatusFailed,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": truncateMigrationError(err.Error()),
+		})
+		return err
+	}
+
+	now := common.JSONTime(time.Now())
+	view := cp.View
+	if err := database.DB.Model(&models.FileReplica{}).Where("id = ?", r.ID).Updates(map[string]interface{}{
+		"status":           models.FileReplicaStatusReady,
+		"url":              view.URL,
+		"remote_url":       view.RemoteURL,
+		"local_file_path":  view.LocalFilePath,
+		"thumb_url":        view.ThumbURL,
+		"remote_thumb_url": view.RemoteThumbURL,
+		"local_thumb_path": view.LocalThumbPath,
+		"size":             cp.Bytes,
+		"last_error":       "",
+		"last_checked_at":  &now,
+	}).Error; err != nil {
+		cp.rollback()
+		return errors.Wrap(err, errors.CodeDBUpdateFailed, "更新副本记录失败")
+	}
+	return nil
+}
+
+// pruneScopeReplicas 策略变更后删除作用域内不再需要的副本
+func pruneScopeReplicas(policy models.ReplicationPolicy) {
+	var replicas []models.FileReplica
+	if err := database.DB.Where("file_id IN (?)", scopeFilesQuery(policy).Select("id")).Find(&replicas).Error; err != nil {
+		logger.Warn("查询作用域副本失败: %v", err)
+		return
+	}
+
+	files := make(map[string]models.File)
+	for _, r := range replicas {
+		file, ok := files[r.FileID]
+		if !ok {
+			if err := database.DB.Where("id = ?", r.FileID).First(&file).Error; err != nil {
+				continue
+			}
+			files[r.FileID] = file
+		}
+		if containsString(desiredReplicaChannels(file), r.ChannelID) {
+			continue
+		}
+		if r.Status == models.FileReplicaStatusReady {
+			deleteChannelObjects(replicaView(file, r))
+		}
+		database.DB.Delete(&models.FileReplica{}, r.ID)
+	}
+}
+
+// desiredReplicaChannels 返回文件应保存副本的渠道（不含主渠道），优先级：文件 > 文件夹（由近及远） > 用户
+func desiredReplicaChannels(file models.File) []string {
+	var policy models.ReplicationPolicy
+	found := database.DB.Where("scope_type = ? AND scope_id = ?", models.ReplicationScopeFile, file.ID).First(&policy).Error == nil
+
+	for folderID, depth := file.FolderID, 0; !found && folderID != "" && folderID != "0" && depth < 64; depth++ {
+		if database.DB.Where("scope_type = ? AND scope_id = ?", models.ReplicationScopeFolder, folderID).First(&policy).Error == nil {
+			found = true
+			break
+		}
+		var f models.Folder
+		if err := database.DB.Select("id", "parent_id").Where("id = ?", folderID).First(&f).Error; err != nil {
+			break
+		}
+		folderID = f.ParentID
+	}
+
+	if !found && database.DB.Where("scope_type = ? AND scope_id = ?", models.ReplicationScopeUser, strconv.FormatUint(uint64(file.UserID), 10)).First(&policy).Error != nil {
+		return nil
+	}
+
+	var channels []string
+	for _, id := range splitChannelIDs(policy.ChannelIDs) {
+		if id != file.StorageProviderID {
+			channels = append(channels, id)
+		}
+	}
+	return channels
+}
+
+func resolveReplicationScope(scopeType, scopeID string) (uint, error) {
+	switch scopeType {
+	case models.ReplicationScopeFile:
+		var file models.File
+		if err := database.DB.Select("id", "user_id").Where("id = ?", scopeID).First(&file).Error; err != nil {
+			return 0, errors.New(errors.CodeFileNotFound, "文件不存在")
+		}
+		return file.UserID, nil
+	case models.ReplicationScopeFolder:
+		var f models.Folder
+		if err := database.DB.Select("id", "user_id").Where("id = ?", scopeID).First(&f).Error; err != nil {
+			return 0, errors.New(errors.CodeFolderNotFound, "文件夹不存在")
+		}
+		return f.UserID, nil
+	case models.ReplicationScopeUser:
+		userID, err := strconv.ParseUint(scopeID, 10, 64)
+		if err != nil {
+			return 0, errors.New(errors.CodeInvalidParameter, "无效的用户ID")
+		}
+		var count int64
+		database.DB.Model(&models.User{}).Where("id = ?", userID).Count(&count)
+		if count == 0 {
+			return 0, errors.New(errors.CodeUserNotFound, "用户不存在")
+		}
+		return uint(userID), nil
+	default:
+		return 0, errors.New(errors.CodeInvalidParameter, "无效的策略作用域")
+	}
+}
+
+func scopeFilesQuery(policy models.ReplicationPolicy) *gorm.DB {
+	query := database.DB.Model(&models.File{})
+	switch policy.ScopeType {
+	case models.ReplicationScopeFile:
+		return query.Where("id = ?", policy.ScopeID)
+	case models.ReplicationScopeFolder:
+		return query.Where("user_id = ? AND folder_id IN ?", policy.UserID, descendantFolderIDs(policy.UserID, policy.ScopeID))
+	default:
+		return query.Where("user_id = ?", policy.UserID)
+	}
+}
+
+// descendantFolderIDs 返回文件夹及其全部子文件夹ID
+func descendantFolderIDs(userID uint, rootID string) []string {
+	ids := []string{rootID}
+	for queue := []string{rootID}; len(queue) > 0; {
+		var children []string
+		if err := database.DB.Model(&models.Folder{}).Where("user_id = ? AND parent_id IN ?", userID, queue).Pluck("id", &children).Error; err != nil {
+			break
+		}
+		ids = append(ids, children...)
+		queue = children
+	}
+	return ids
+}
+
+func replicaView(file models.File, r models.FileReplica) models.File {
+	view := file
+	view.StorageProviderID = r.ChannelID
+	view.URL = r.URL
+	view.FilePath = r.URL
+	view.RemoteURL = r.RemoteURL
+	view.FullPath = r.RemoteURL
+	view.LocalFilePath = r.LocalFilePath
+	view.ThumbURL = r.ThumbURL
+	view.RemoteThumbURL = r.RemoteThumbURL
+	view.LocalThumbPath = r.LocalThumbPath
+	return view
+}
+
+func replicaObjectExists(view models.File) bool {
+	provider, err := newstorage.GetStorageProviderByChannelID(view.StorageProviderID)
+	if err != nil {
+		return false
+	}
+	if provider.IsDirectAccess() {
+		_, err := os.Stat(view.LocalFilePath)
+		return err == nil
+	}
+	ad, err := newstorage.NewGlobalStorage().GetManager().GetAdapter(view.StorageProviderID)
+	if err != nil {
+		return false
+	}
+	ok, err := ad.Exists(context.Background(), ResolveObjectKey(view, false))
+	return err == nil && ok
+}
+
+// replicaReadCandidates 返回可读取的文件视图：无副本时只有主渠道；有副本时按健康状态与读取成本排序
+func replicaReadCandidates(file models.File) []models.File {
+	var replicas []models.FileReplica
+	database.DB.Where("file_id = ? AND status = ?", file.ID, models.FileReplicaStatusReady).Find(&replicas)
+	if len(replicas) == 0 {
+		return []models.File{file}
+	}
+
+	candidates := make([]models.File, 0, len(replicas)+1)
+	candidates = append(candidates, file)
+	for _, r := range replicas {
+		candidates = append(candidates, replicaView(file, r))
+	}
+
+	states := make(map[string]channelReadState, len(candidates))
+	for _, c := range candidates {
+		states[c.StorageProviderID] = getChannelReadState(c.StorageProviderID)
+	}
+	// 稳定排序：同等条件下主渠道优先
+	sort.SliceStable(candidates, func(i, j int) bool {
+		si, sj := states[candidates[i].StorageProviderID], states[candidates[j].StorageProviderID]
+		if si.healthy != sj.healthy {
+			return si.healthy
+		}
+		return si.cost < sj.cost
+	})
+	return candidates
+}
+
+type channelReadState struct {
+	healthy   bool
+	cost      float64
+	checkedAt time.Time
+	failures  int
+}
+
+var channelReadStates sync.Map
+
+// getChannelReadState 返回渠道健康状态与读取成本（短时间缓存，避免每次读取都做健康检查）
+func getChannelReadState(channelID string) channelReadState {
+	var failures int
+	if v, ok := channelReadStates.Load(channelID); ok {
+		state := v.(channelReadState)
+		if time.Since(state.checkedAt) < channelReadStateTTL {
+			return state
+		}
+		// 未达到阈值的连续失败计数跨缓存周期保留；已判定不健康的渠道在重新检查后从零计数
+		if state.healthy {
+			failures = state.failures
+		}
+	}
+
+	mgr := newstorage.NewGlobalStorage().GetManager()
+	ctx, cancel := context.WithTimeout(context.Background(), channelHealthTimeout)
+	defer cancel()
+
+	state := channelReadState{
+		healthy:   mgr.HealthCheck(ctx, channelID) == nil,
+		cost:      channelReadCost(channelID),
+		checkedAt: time.Now(),
+		failures:  failures,
+	}
+	channelReadStates.Store(channelID, state)
+	return state
+}
+
+// recordChannelReadFailure 记录一次读取失败，连续失败达到阈值才将渠道标记为不健康，
+// 单个文件缺失或偶发错误不会让整个渠道被跳过；标记后由缓存过期时的健康检查恢复
+func recordChannelReadFailure(channelID string) {
+	state := getChannelReadState(channelID)
+	state.failures++
+	if state.failures >= channelFailureThreshold {
+		state.healthy = false
+		state.checkedAt = time.Now()
+	}
+	channelReadStates.Store(channelID, state)
+}
+
+// recordChannelReadSuccess 读取成功后清零连续失败计数
+func recordChannelReadSuccess(channelID string) {
+	if v, ok := channelReadStates.Load(channelID); ok {
+		if state := v.(channelReadState); state.failures > 0 {
+			state.failures = 0
+			channelReadStates.Store(channelID, state)
+		}
+	}
+}
+
+// channelReadCost 渠道读取成本：优先使用渠道配置 read_cost，否则本地存储为0，其余为1
+func channelReadCost(channelID string) float64 {
+	if cfg, err := newstorage.GetChannelConfigMapFromService(channelID); err == nil {
+		switch v := cfg["read_cost"].(type) {
+		case float64:
+			return v
+		case int:
+			return float64(v)
+		case string:
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return f
+			}
+		}
+	}
+	if t, err := newstorage.NewGlobalStorage().GetManager().GetChannelType(channelID); err == nil && t == "local" {
+		return 0
+	}
+	return 1
+}
+
+func splitChannelIDs(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	return strings.Split(raw, ",")
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package file
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChannelMarkedUnhealthyAfterConsecutiveFailures(t *testing.T) {
+	const channelID = "test-channel"
+	channelReadStates.Store(channelID, channelReadState{healthy: true, checkedAt: time.Now()})
+	defer channelReadStates.Delete(channelID)
+
+	for i := 1; i < channelFailureThreshold; i++ {
+		recordChannelReadFailure(channelID)
+		if !getChannelReadState(channelID).healthy {
+			t.Fatalf("channel marked unhealthy after %d failures", i)
+		}
+	}
+	recordChannelReadSuccess(channelID)
+	for i := 1; i < channelFailureThreshold; i++ {
+		recordChannelReadFailure(channelID)
+	}
+	if !getChannelReadState(channelID).healthy {
+		t.Fatal("success did not reset the failure count")
+	}
+	recordChannelReadFailure(channelID)
+	if getChannelReadState(channelID).healthy {
+		t.Fatalf("channel still healthy after %d consecutive failures", channelFailureThreshold)
+	}
+}
@@ -31,6 +31,7 @@ func BuildFileDetailResponse(file models.File, views int64, aiInfo *AIInfoRespon
 		IsRecommended:     file.IsRecommended,
 		StorageProviderID: file.StorageProviderID,
 		AIInfo:            aiInfo,
+		Renditions:        BuildRenditions(file),
 	}
 }
 
@@ -0,0 +1,300 @@
+package file
+
+/* Reverse image search: matches an unsaved image against the library by perceptual hash and image or description vectors. */
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/ai"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	imgHash "pixelpunk/pkg/imagex/hash"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/utils"
+	"pixelpunk/pkg/vector"
+
+	"github.com/disintegration/imaging"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxImageSearchBytes = 20 << 20
+	// 解码前按头信息限制像素总数，防止小体积的解压炸弹耗尽内存
+	maxImageSearchPixels = 50_000_000
+
+	ImageSearchScopeMine       = "mine"       // 仅自己的文件
+	ImageSearchScopeAccessible = "accessible" // 自己的文件与他人的公开文件
+
+	ImageSearchMatchVisual   = "visual"
+	ImageSearchMatchSemantic = "semantic"
+	ImageSearchMatchBoth     = "both"
+
+	imageSearchFetchTimeout = 20 * time.Second
+	imageSearchDescribeSize = 768 // 送AI分析前缩放的最大边长
+	defaultImageSearchLimit = 20
+	maxImageSearchLimit     = 50
+)
+
+/* ImageSearchItem 以图搜图结果项 */
+type ImageSearchItem struct {
+	FileDetailResponse
+	MatchType  string  `json:"match_type"`
+	Distance   *int    `json:"distance,omitempty"` // 感知哈希汉明距离，仅视觉匹配时返回
+	Similarity float32 `json:"similarity"`         // 向量相似度，仅语义匹配时返回
+}
+
+/* ImageSearchResult 以图搜图结果 */
+type ImageSearchResult struct {
+	Items       []ImageSearchItem `json:"items"`
+	PHash       string            `json:"phash"`
+	Description string            `json:"description"`
+	VectorUsed  bool              `json:"vector_used"`
+}
+
+/* SearchByImage 以图搜图：图片仅在内存中处理不落盘，先按感知哈希查找视觉相同的文件，向量搜索可用时再做语义召回 */
+func SearchByImage(userID uint, data []byte, scope string, limit, maxDistance int, threshold float32) (*ImageSearchResult, error) {
+	if len(data) == 0 {
+		return nil, errors.New(errors.CodeInvalidParameter, "请提供要搜索的图片")
+	}
+	if limit <= 0 {
+		limit = defaultImageSearchLimit
+	}
+	if limit > maxImageSearchLimit {
+		limit = maxImageSearchLimit
+	}
+	if scope != ImageSearchScopeAccessible {
+		scope = ImageSearchScopeMine
+	}
+	maxDistance = clampNearDuplicateDistance(maxDistance)
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New(errors.CodeInvalidParameter, "无法识别的图片格式")
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > maxImageSearchPixels {
+		return nil, errors.New(errors.CodeInvalidParameter, "图片尺寸过大")
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New(errors.CodeInvalidParameter, "无法识别的图片格式")
+	}
+	hash := imgHash.DHash(img)
+	result := &ImageSearchResult{Items: []ImageSearchItem{}, PHash: imgHash.FormatPerceptual(hash)}
+
+	bandUserID := userID
+	if scope == ImageSearchScopeAccessible {
+		bandUserID = 0
+	}
+	visual, err := findPHashMatches(hash, maxDistance, bandUserID, "", func(db *gorm.DB) *gorm.DB {
+		return accessibleScope(db, userID, scope)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(map[string]*ImageSearchItem, len(visual))
+	for _, m := range visual {
+		d := m.Distance
+		items[m.File.ID] = &ImageSearchItem{
+			FileDetailResponse: BuildFileDetailResponse(m.File, 0, nil),
+			MatchType:          ImageSearchMatchVisual,
+			Distance:           &d,
+		}
+	}
+
+	if engine := vector.GetGlobalVectorEngine(); engine != nil && engine.IsEnabled() {
+		description, scores, err := semanticImageMatches(engine, img, userID, scope, limit, threshold)
+		if err != nil {
+			logger.Warn("以图搜图语义召回失败，仅使用感知哈希: %v", err)
+		} else {
+			result.Description = description
+			result.VectorUsed = true
+			if err := mergeSemanticMatches(items, scores, userID, scope); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, item := range items {
+		result.Items = append(result.Items, *item)
+	}
+	sortImageSearchItems(result.Items)
+	if len(result.Items) > limit {
+		result.Items = result.Items[:limit]
+	}
+	return result, nil
+}
+
+// semanticImageMatches 语义召回：图像向量可用时直接用像素向量检索，否则生成AI描述后检索描述文本向量
+func semanticImageMatches(engine *vector.VectorEngine, img image.Image, userID uint, scope string, limit int, threshold float32) (string, map[string]float32, error) {
+	// Qdrant 过滤条件只支持 AND，自己的文件与公开文件分两次召回
+	filters := []*vector.SearchFilter{{UserID: userID}}
+	if scope == ImageSearchScopeAccessible {
+		filters = append(filters, &vector.SearchFilter{AccessLevels: []string{"public"}})
+	}
+
+	var search func(filter *vector.SearchFilter) ([]vector.VectorSearchResult, error)
+	description := ""
+	if engine.ImageVectorsEnabled() {
+		data, err := encodeEmbeddingImage(img)
+		if err != nil {
+			return "", nil, err
+		}
+		search = func(filter *vector.SearchFilter) ([]vector.VectorSearchResult, error) {
+			return engine.SearchByImage(data, limit, filter, threshold)
+		}
+	} else {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, imaging.Fit(img, imageSearchDescribeSize, imageSearchDescribeSize, imaging.Lanczos), &jpeg.Options{Quality: 85}); err != nil {
+			return "", nil, fmt.Errorf("压缩图片失败: %v", err)
+		}
+		var err error
+		description, err = ai.DescribeImageForSearch(base64.StdEncoding.EncodeToString(buf.Bytes()), "jpeg")
+		if err != nil {
+			return "", nil, err
+		}
+		search = func(filter *vector.SearchFilter) ([]vector.VectorSearchResult, error) {
+			return engine.SearchFilesTarget(description, vector.QueryTargetText, limit, filter, threshold)
+		}
+	}
+
+	scores := make(map[string]float32)
+	for _, filter := range filters {
+		results, err := search(filter)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, r := range results {
+			if r.Similarity > scores[r.FileID] {
+				scores[r.FileID] = r.Similarity
+			}
+		}
+	}
+	return description, scores, nil
+}
+
+// mergeSemanticMatches 合并语义召回结果，向量库中的数据可能滞后，因此重新校验访问权限
+func mergeSemanticMatches(items map[string]*ImageSearchItem, scores map[string]float32, userID uint, scope string) error {
+	if len(scores) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		if item, ok := items[id]; ok {
+			item.MatchType = ImageSearchMatchBoth
+			item.Similarity = scores[id]
+			continue
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var files []models.File
+	query := database.DB.Where("id IN ?", ids).Where("status <> ?", StatusPendingDeletion)
+	if err := accessibleScope(query, userID, scope).Find(&files).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	}
+	for _, f := range files {
+		items[f.ID] = &ImageSearchItem{
+			FileDetailResponse: BuildFileDetailResponse(f, 0, nil),
+			MatchType:          ImageSearchMatchSemantic,
+			Similarity:         scores[f.ID],
+		}
+	}
+	return nil
+}
+
+// accessibleScope 他人的文件只返回公开且已通过审核的
+func accessibleScope(db *gorm.DB, userID uint, scope string) *gorm.DB {
+	if scope == ImageSearchScopeAccessible {
+		return db.Where("user_id = ? OR (access_level = ? AND status NOT IN ?)",
+			userID, AccessPublic, []string{StatusPendingDeletion, StatusPendingReview})
+	}
+	return db.Where("user_id = ?", userID)
+}
+
+// sortImageSearchItems 视觉匹配优先（按汉明距离），其余按向量相似度降序
+func sortImageSearchItems(items []ImageSearchItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if (a.Distance != nil) != (b.Distance != nil) {
+			return a.Distance != nil
+		}
+		if a.Distance != nil && *a.Distance != *b.Distance {
+			return *a.Distance < *b.Distance
+		}
+		if a.Similarity != b.Similarity {
+			return a.Similarity > b.Similarity
+		}
+		return a.ID < b.ID
+	})
+}
+
+/* DecodeImageSearchData 解析粘贴的图片数据，支持 data URI 与纯 base64 */
+func DecodeImageSearchData(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "data:") {
+		idx := strings.Index(s, ",")
+		if idx < 0 || !strings.HasSuffix(s[:idx], ";base64") {
+			return nil, errors.New(errors.CodeInvalidParameter, "图片数据格式错误")
+		}
+		s = s[idx+1:]
+	}
+	if base64.StdEncoding.DecodedLen(len(s)) > MaxImageSearchBytes+3 {
+		return nil, errors.New(errors.CodeInvalidParameter, "图片过大")
+	}
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New(errors.CodeInvalidParameter, "图片数据不是有效的base64")
+	}
+	return data, nil
+}
+
+/* FetchImageForSearch 下载远程图片用于搜索，拒绝内网地址与重定向 */
+func FetchImageForSearch(rawURL string) ([]byte, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New(errors.CodeInvalidParameter, "图片地址无效")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), imageSearchFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.New(errors.CodeInvalidParameter, "图片地址无效")
+	}
+	resp, err := utils.NewSafeHTTPClient(false).Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInvalidParameter, "下载图片失败")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(errors.CodeInvalidParameter, fmt.Sprintf("下载图片失败，状态码: %d", resp.StatusCode))
+	}
+	if resp.ContentLength > MaxImageSearchBytes {
+		return nil, errors.New(errors.CodeInvalidParameter, "图片过大")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxImageSearchBytes+1))
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInvalidParameter, "下载图片失败")
+	}
+	if len(data) > MaxImageSearchBytes {
+		return nil, errors.New(errors.CodeInvalidParameter, "图片过大")
+	}
+	return data, nil
+}
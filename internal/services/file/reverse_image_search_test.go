@@ -0,0 +1,63 @@
+package file
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"strings"
+	"testing"
+)
+
+func TestDecodeImageSearchData(t *testing.T) {
+	want := []byte("\x89PNG\r\n")
+	for _, in := range []string{"iVBORw0K", "data:image/png;base64,iVBORw0K", "  data:image/png;base64,iVBORw0K\n"} {
+		got, err := DecodeImageSearchData(in)
+		if err != nil {
+			t.Fatalf("DecodeImageSearchData(%q) error: %v", in, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("DecodeImageSearchData(%q) = %q, want %q", in, got, want)
+		}
+	}
+	for _, in := range []string{"data:image/png,iVBORw0K", "data:image/png;base64", "not base64!"} {
+		if _, err := DecodeImageSearchData(in); err == nil {
+			t.Fatalf("DecodeImageSearchData(%q) expected error", in)
+		}
+	}
+}
+
+func TestSortImageSearchItems(t *testing.T) {
+	d1, d3 := 1, 3
+	items := []ImageSearchItem{
+		{FileDetailResponse: FileDetailResponse{ID: "sem-low"}, Similarity: 0.4},
+		{FileDetailResponse: FileDetailResponse{ID: "vis-3"}, Distance: &d3},
+		{FileDetailResponse: FileDetailResponse{ID: "sem-high"}, Similarity: 0.8},
+		{FileDetailResponse: FileDetailResponse{ID: "both-1"}, Distance: &d1, Similarity: 0.5},
+	}
+	sortImageSearchItems(items)
+
+	want := []string{"both-1", "vis-3", "sem-high", "sem-low"}
+	for i, id := range want {
+		if items[i].ID != id {
+			t.Fatalf("position %d = %s, want %s", i, items[i].ID, id)
+		}
+	}
+}
+
+func TestSearchByImageRejectsOversizedImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, image.NewPaletted(image.Rect(0, 0, 1, 1), []color.Color{color.Black}), nil); err != nil {
+		t.Fatal(err)
+	}
+	// 只改写头部的逻辑屏幕尺寸，解码像素数据前就应被拒绝
+	data := buf.Bytes()
+	binary.LittleEndian.PutUint16(data[6:], 60000)
+	binary.LittleEndian.PutUint16(data[8:], 60000)
+
+	_, err := SearchByImage(1, data, ImageSearchScopeMine, 0, 0, 0)
+	if err == nil || !strings.Contains(err.Error(), "图片尺寸过大") {
+		t.Fatalf("expected oversized image to be rejected, got %v", err)
+	}
+}
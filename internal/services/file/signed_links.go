@@ -0,0 +1,380 @@
+package file
+
+/* Revocable signed links with optional IP/referer binding, use limits and native presign delegation. */
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/pkg/cache"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/storage"
+	"pixelpunk/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultSignedLinkExpireHours = 24 * 7
+	// 主流对象存储的预签名有效期上限为 7 天
+	maxNativeSignedLinkExpire = 7 * 24 * time.Hour
+	maxSignedLinkBatchSize    = 100
+	// 计次后同一 IP 在该时间内的区间续传与缩略图请求视为同一次访问，不再消耗次数
+	signedLinkFollowUpWindow = 10 * time.Minute
+)
+
+/* SignedLinkOptions 签名链接参数 */
+type SignedLinkOptions struct {
+	ExpireMinutes   int
+	MaxUses         int
+	AllowedIPs      string
+	AllowedReferers string
+	Native          bool
+	Note            string
+}
+
+/* SignedLinkResult 签名链接生成结果 */
+type SignedLinkResult struct {
+	LinkID    string    `json:"link_id"`
+	FileID    string    `json:"file_id"`
+	URL       string    `json:"url"`
+	Native    bool      `json:"native"`
+	MaxUses   int       `json:"max_uses"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+/* SignedLinkMaxExpire 返回管理员配置的签名链接最长有效期 */
+func SignedLinkMaxExpire() time.Duration {
+	hours := setting.GetInt("security", "signed_link_max_expire_hours", defaultSignedLinkExpireHours)
+	if hours <= 0 {
+		hours = defaultSignedLinkExpireHours
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+/* CreateSignedLink 为用户文件生成签名链接 */
+func CreateSignedLink(userID uint, fileID string, opts SignedLinkOptions) (*SignedLinkResult, error) {
+	if fileID == "" {
+		return nil, errors.New(errors.CodeInvalidParameter, "文件ID不能为空")
+	}
+
+	var file models.File
+	if err := database.DB.Where("id = ? AND user_id = ?", fileID, userID).
+		Where("status <> ?", "pending_deletion").
+		First(&file).Error; err != nil {
+		return nil, errors.New(errors.CodeFileNotFound, "文件不存在或无权访问")
+	}
+
+	return createSignedLink(file, opts)
+}
+
+/* CreateSignedLinks 批量为用户文件生成签名链接，跳过无权访问的文件 */
+func CreateSignedLinks(userID uint, fileIDs []string, opts SignedLinkOptions) ([]SignedLinkResult, error) {
+	if len(fileIDs) == 0 {
+		return nil, errors.New(errors.CodeInvalidParameter, "文件ID列表不能为空")
+	}
+	if len(fileIDs) > maxSignedLinkBatchSize {
+		return nil, errors.New(errors.CodeInvalidParameter, fmt.Sprintf("单次最多签名 %d 个文件", maxSignedLinkBatchSize))
+	}
+
+	var files []models.File
+	if err := database.DB.Where("id IN ? AND user_id = ?", fileIDs, userID).
+		Where("status <> ?", "pending_deletion").
+		Find(&files).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	}
+
+	results := make([]SignedLinkResult, 0, len(files))
+	for _, file := range files {
+		result, err := createSignedLink(file, opts)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+func createSignedLink(file models.File, opts SignedLinkOptions) (*SignedLinkResult, error) {
+	allowedIPs, err := normalizeIPList(opts.AllowedIPs)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxUses < 0 {
+		return nil, errors.New(errors.CodeInvalidParameter, "最大使用次数不能为负数")
+	}
+
+	expire := time.Duration(opts.ExpireMinutes) * time.Minute
+	if expire <= 0 {
+		expire = time.Hour
+	}
+	if maxExpire := SignedLinkMaxExpire(); expire > maxExpire {
+		expire = maxExpire
+	}
+
+	link := models.SignedLink{
+		UserID:          file.UserID,
+		FileID:          file.ID,
+		MaxUses:         opts.MaxUses,
+		AllowedIPs:      allowedIPs,
+		AllowedReferers: normalizeList(opts.AllowedReferers),
+		Note:            opts.Note,
+	}
+
+	var nativeURL string
+	if opts.Native && canUseNativePresign(link) {
+		if expire > maxNativeSignedLinkExpire {
+			expire = maxNativeSignedLinkExpire
+		}
+		nativeURL, err = storage.NewGlobalStorage().PresignGetURL(context.Background(), file.StorageProviderID, ResolveObjectKey(file, false), expire)
+		if err != nil {
+			// 渠道不支持或签名失败时回退为系统签名链接
+			logger.Warn("原生预签名失败，回退为系统签名链接 [%s]: %v", file.ID, err)
+			nativeURL = ""
+		}
+	}
+
+	expiresAt := time.Now().Add(expire)
+	link.ExpiresAt = common.JSONTime(expiresAt)
+	link.Native = nativeURL != ""
+
+	if err := database.DB.Create(&link).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBCreateFailed, "保存签名链接失败")
+	}
+
+	url := nativeURL
+	if url == "" {
+		sig := utils.GetURLSigner().SignMessage(signedLinkMessage(link.ID, file.ID, expiresAt.Unix()))
+		url = utils.GetSystemFileURL(fmt.Sprintf("/f/%s?lid=%s&sig=%s", file.ID, link.ID, sig))
+	}
+
+	return &SignedLinkResult{
+		LinkID:    link.ID,
+		FileID:    file.ID,
+		URL:       url,
+		Native:    link.Native,
+		MaxUses:   link.MaxUses,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// canUseNativePresign 原生预签名链接直连存储，无法执行IP/来源/次数限制
+func canUseNativePresign(link models.SignedLink) bool {
+	if link.AllowedIPs != "" || link.AllowedReferers != "" || link.MaxUses > 0 {
+		return false
+	}
+	return setting.GetBool("security", "signed_link_native_enabled", true)
+}
+
+func signedLinkMessage(linkID, fileID string, expiry int64) string {
+	return fmt.Sprintf("link:%s:%s:%d", linkID, fileID, expiry)
+}
+
+/* SignedLinkUse 签名链接请求的计次方式 */
+type SignedLinkUse int
+
+const (
+	SignedLinkUseFull  SignedLinkUse = iota // 完整文件请求，每次计次
+	SignedLinkUseRange                      // 区间请求：从文件中间开始的续传在窗口与字节额度内不计次，其余按完整请求计次
+	SignedLinkUsePeek                       // 缩略图、HEAD 等不返回原文件的请求：不计次，次数用完后只在续传窗口内放行
+)
+
+/* SignedLinkRequest 签名链接请求的计次信息 */
+type SignedLinkRequest struct {
+	Use      SignedLinkUse
+	Range    string // Range 请求头
+	FileSize int64
+}
+
+/* ValidateSignedLink 校验签名链接并按请求类型消耗使用次数 */
+func ValidateSignedLink(linkID, signature, fileID, ip, domain string, req SignedLinkRequest, isIPInListFunc, isDomainInListFunc func(string, string) bool) error {
+	var link models.SignedLink
+	if err := database.DB.Where("id = ? AND file_id = ?", linkID, fileID).First(&link).Error; err != nil {
+		return errors.New(errors.CodeUnauthorized, "签名链接无效")
+	}
+
+	expiresAt := time.Time(link.ExpiresAt)
+	if !utils.GetURLSigner().VerifyMessage(signedLinkMessage(link.ID, link.FileID, expiresAt.Unix()), signature) {
+		return errors.New(errors.CodeUnauthorized, "签名链接无效")
+	}
+	if link.Revoked {
+		return errors.New(errors.CodeUnauthorized, "签名链接已被撤销")
+	}
+	if time.Now().After(expiresAt) {
+		return errors.New(errors.CodeUnauthorized, "签名链接已过期")
+	}
+	if link.AllowedIPs != "" && !isIPInListFunc(ip, link.AllowedIPs) {
+		return errors.New(errors.CodeForbidden, "当前IP无权使用该链接")
+	}
+	if link.AllowedReferers != "" && !isDomainInListFunc(domain, link.AllowedReferers) {
+		return errors.New(errors.CodeForbidden, "当前来源无权使用该链接")
+	}
+
+	switch req.Use {
+	case SignedLinkUseRange:
+		if n, ok := continuationRangeBytes(req.Range, req.FileSize); ok && isSignedLinkFollowUp(link, ip) && takeSignedLinkRangeBudget(link.ID, ip, n) {
+			return nil
+		}
+	case SignedLinkUsePeek:
+		if link.MaxUses == 0 || link.UseCount < link.MaxUses || isSignedLinkFollowUp(link, ip) {
+			return nil
+		}
+		return errors.New(errors.CodeUnauthorized, "签名链接使用次数已用完")
+	}
+
+	// 条件更新保证并发下不会超出次数限制
+	now := common.JSONTime(time.Now())
+	result := database.DB.Model(&models.SignedLink{}).
+		Where("id = ? AND (max_uses = 0 OR use_count < max_uses)", link.ID).
+		Updates(map[string]interface{}{
+			"use_count":    gorm.Expr("use_count + 1"),
+			"last_used_at": &now,
+			"last_used_ip": ip,
+		})
+	if result.Error != nil {
+		return errors.Wrap(result.Error, errors.CodeDBUpdateFailed, "更新签名链接失败")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(errors.CodeUnauthorized, "签名链接使用次数已用完")
+	}
+	// 每次计次最多再免费续传一个文件大小的字节数
+	cache.Set(signedLinkRangeBudgetKey(link.ID, ip), strconv.FormatInt(req.FileSize, 10), signedLinkFollowUpWindow)
+	return nil
+}
+
+// isSignedLinkFollowUp 请求是否来自最近一次计次的同一 IP 且仍在续传窗口内
+func isSignedLinkFollowUp(link models.SignedLink, ip string) bool {
+	return link.LastUsedAt != nil && link.LastUsedIP == ip &&
+		time.Since(time.Time(*link.LastUsedAt)) < signedLinkFollowUpWindow
+}
+
+// continuationRangeBytes 解析单段且不从文件开头开始的区间，返回其字节数；bytes=0-、后缀区间与多段区间都可能取到整个文件，不算续传
+func continuationRangeBytes(header string, size int64) (int64, bool) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, false
+	}
+	startStr, endStr, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok || startStr == "" {
+		return 0, false
+	}
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start <= 0 || start >= size {
+		return 0, false
+	}
+	end := size - 1
+	if endStr != "" {
+		e, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || e < start {
+			return 0, false
+		}
+		end = min(e, size-1)
+	}
+	return end - start + 1, true
+}
+
+// takeSignedLinkRangeBudget 从最近一次计次留下的续传额度中扣除 n 字节，额度不足时返回 false
+func takeSignedLinkRangeBudget(linkID, ip string, n int64) bool {
+	key := signedLinkRangeBudgetKey(linkID, ip)
+	val, err := cache.Get(key)
+	if err != nil {
+		return false
+	}
+	left, err := strconv.ParseInt(val, 10, 64)
+	if err != nil || left < n {
+		return false
+	}
+	ttl, err := cache.TTL(key)
+	if err != nil || ttl <= 0 {
+		return false
+	}
+	return cache.Set(key, strconv.FormatInt(left-n, 10), ttl) == nil
+}
+
+func signedLinkRangeBudgetKey(linkID, ip string) string {
+	return "signed_link:range_budget:" + linkID + ":" + ip
+}
+
+/* ListSignedLinks 获取用户的签名链接列表，fileID 为空时返回全部 */
+func ListSignedLinks(userID uint, fileID string, page, size int) ([]models.SignedLink, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > 100 {
+		size = 20
+	}
+
+	query := database.DB.Model(&models.SignedLink{}).Where("user_id = ?", userID)
+	if fileID != "" {
+		query = query.Where("file_id = ?", fileID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询签名链接失败")
+	}
+
+	var links []models.SignedLink
+	if err := query.Order("created_at DESC").Offset((page - 1) * size).Limit(size).Find(&links).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询签名链接失败")
+	}
+	return links, total, nil
+}
+
+/* RevokeSignedLinks 撤销签名链接，userID 为 0 时不校验归属（管理员） */
+func RevokeSignedLinks(userID uint, linkIDs []string) (int64, error) {
+	if len(linkIDs) == 0 {
+		return 0, errors.New(errors.CodeInvalidParameter, "链接ID不能为空")
+	}
+
+	now := common.JSONTime(time.Now())
+	query := database.DB.Model(&models.SignedLink{}).Where("id IN ? AND revoked = ?", linkIDs, false)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	result := query.Updates(map[string]interface{}{"revoked": true, "revoked_at": &now})
+	if result.Error != nil {
+		return 0, errors.Wrap(result.Error, errors.CodeDBUpdateFailed, "撤销签名链接失败")
+	}
+	return result.RowsAffected, nil
+}
+
+/* DeleteFileSignedLinks 删除文件关联的全部签名链接 */
+func DeleteFileSignedLinks(fileID string) {
+	if err := database.DB.Where("file_id = ?", fileID).Delete(&models.SignedLink{}).Error; err != nil {
+		logger.Error("删除签名链接失败 [%s]: %v", fileID, err)
+	}
+}
+
+func normalizeIPList(raw string) (string, error) {
+	list := normalizeList(raw)
+	if list == "" {
+		return "", nil
+	}
+	for _, entry := range strings.Split(list, ",") {
+		if strings.Contains(entry, "/") {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				return "", errors.New(errors.CodeInvalidParameter, fmt.Sprintf("无效的CIDR: %s", entry))
+			}
+		} else if net.ParseIP(entry) == nil {
+			return "", errors.New(errors.CodeInvalidParameter, fmt.Sprintf("无效的IP地址: %s", entry))
+		}
+	}
+	return list, nil
+}
+
+func normalizeList(raw string) string {
+	parts := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == '\n' || r == ' '
+	})
+	return strings.Join(parts, ",")
+}
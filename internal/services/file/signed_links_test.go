@@ -0,0 +1,28 @@
+package file
+
+import "testing"
+
+func TestContinuationRangeBytes(t *testing.T) {
+	cases := []struct {
+		header string
+		n      int64
+		ok     bool
+	}{
+		{"bytes=500-", 500, true},
+		{"bytes=500-599", 100, true},
+		{"bytes=900-5000", 100, true},
+		{"bytes=0-", 0, false},
+		{"bytes=0-999", 0, false},
+		{"bytes=-1000", 0, false},
+		{"bytes=1000-", 0, false},
+		{"bytes=500-400", 0, false},
+		{"bytes=1-10,20-30", 0, false},
+		{"items=500-", 0, false},
+	}
+	for _, tc := range cases {
+		n, ok := continuationRangeBytes(tc.header, 1000)
+		if n != tc.n || ok != tc.ok {
+			t.Fatalf("continuationRangeBytes(%q) = %d, %v, want %d, %v", tc.header, n, ok, tc.n, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,568 @@
+package file
+
+/* Storage channel migration jobs: copy files and thumbnails between adapters with verification. */
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pixelpunk/internal/controllers/websocket"
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/storage"
+	ws "pixelpunk/internal/websocket"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/imagex/formats"
+	"pixelpunk/pkg/logger"
+	newstorage "pixelpunk/pkg/storage"
+	"pixelpunk/pkg/storage/adapter"
+	pathutil "pixelpunk/pkg/storage/path"
+	storageutils "pixelpunk/pkg/storage/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	storageMigrationBatchSize   = 50
+	maxStorageMigrationThrottle = 60 * 1000
+	// 进度推送的最小间隔，避免大批量迁移时刷屏
+	storageMigrationBroadcastInterval = time.Second
+
+	migrationSignalPause  int32 = 1
+	migrationSignalCancel int32 = 2
+)
+
+// migrationRunners 正在运行的迁移任务 jobID -> *int32 控制信号
+var migrationRunners sync.Map
+
+/* StorageMigrationOptions 迁移任务参数 */
+type StorageMigrationOptions struct {
+	SourceChannelID string
+	TargetChannelID string
+	DryRun          bool
+	DeleteSource    bool
+	ThrottleMs      int
+}
+
+/* CreateStorageMigration 创建并启动存储渠道迁移任务 */
+func CreateStorageMigration(adminID uint, opts StorageMigrationOptions) (*models.StorageMigrationJob, error) {
+	if opts.SourceChannelID == "" || opts.TargetChannelID == "" {
+		return nil, errors.New(errors.CodeInvalidParameter, "源渠道和目标渠道不能为空")
+	}
+	if opts.SourceChannelID == opts.TargetChannelID {
+		return nil, errors.New(errors.CodeInvalidParameter, "源渠道和目标渠道不能相同")
+	}
+	if _, err := storage.GetChannelByID(opts.SourceChannelID); err != nil {
+		return nil, errors.New(errors.CodeStorageProviderNotFound, "源存储渠道不存在")
+	}
+	target, err := storage.GetChannelByID(opts.TargetChannelID)
+	if err != nil {
+		return nil, errors.New(errors.CodeStorageProviderNotFound, "目标存储渠道不存在")
+	}
+	if target.Status != 1 {
+		return nil, errors.New(errors.CodeInvalidParameter, "目标存储渠道未启用")
+	}
+	if opts.ThrottleMs < 0 || opts.ThrottleMs > maxStorageMigrationThrottle {
+		return nil, errors.New(errors.CodeInvalidParameter, "迁移间隔必须在0到60000毫秒之间")
+	}
+
+	var active int64
+	database.DB.Model(&models.StorageMigrationJob{}).
+		Where("source_channel_id = ? AND status IN ?", opts.SourceChannelID, []string{
+			models.StorageMigrationStatusPending, models.StorageMigrationStatusRunning, models.StorageMigrationStatusPaused,
+		}).Count(&active)
+	if active > 0 {
+		return nil, errors.New(errors.CodeConflict, "该渠道已有未完成的迁移任务")
+	}
+
+	var total int64
+	if err := database.DB.Model(&models.File{}).Where("storage_provider_id = ?", opts.SourceChannelID).Count(&total).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "统计待迁移文件失败")
+	}
+
+	job := models.StorageMigrationJob{
+		SourceChannelID: opts.SourceChannelID,
+		TargetChannelID: opts.TargetChannelID,
+		CreatedBy:       adminID,
+		Status:          models.StorageMigrationStatusPending,
+		DryRun:          opts.DryRun,
+		DeleteSource:    opts.DeleteSource && !opts.DryRun,
+		ThrottleMs:      opts.ThrottleMs,
+		Total:           total,
+	}
+	if err := database.DB.Create(&job).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBCreateFailed, "创建迁移任务失败")
+	}
+
+	go runStorageMigration(job.ID)
+	return &job, nil
+}
+
+/* ListStorageMigrations 分页获取迁移任务 */
+func ListStorageMigrations(page, size int) ([]models.StorageMigrationJob, int64, error) {
+	query := database.DB.Model(&models.StorageMigrationJob{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询迁移任务失败")
+	}
+
+	var jobs []models.StorageMigrationJob
+	if err := query.Order("created_at DESC").Offset((page - 1) * size).Limit(size).Find(&jobs).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询迁移任务失败")
+	}
+	return jobs, total, nil
+}
+
+/* GetStorageMigration 获取迁移任务详情 */
+func GetStorageMigration(jobID string) (*models.StorageMigrationJob, error) {
+	var job models.StorageMigrationJob
+	if err := database.DB.Where("id = ?", jobID).First(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeNotFound, "迁移任务不存在")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询迁移任务失败")
+	}
+	return &job, nil
+}
+
+/* ListStorageMigrationFailures 分页获取迁移失败的文件 */
+func ListStorageMigrationFailures(jobID string, page, size int) ([]models.StorageMigrationFailure, int64, error) {
+	query := database.DB.Model(&models.StorageMigrationFailure{}).Where("job_id = ?", jobID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询迁移失败记录失败")
+	}
+
+	var failures []models.StorageMigrationFailure
+	if err := query.Order("id DESC").Offset((page - 1) * size).Limit(size).Find(&failures).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询迁移失败记录失败")
+	}
+	return failures, total, nil
+}
+
+/* PauseStorageMigration 暂停迁移任务，当前文件处理完成后停止 */
+func PauseStorageMigration(jobID string) error {
+	job, err := GetStorageMigration(jobID)
+	if err != nil {
+		return err
+	}
+	if job.Status != models.StorageMigrationStatusRunning && job.Status != models.StorageMigrationStatusPending {
+		return errors.New(errors.CodeInvalidParameter, "只能暂停进行中的迁移任务")
+	}
+	if signalStorageMigration(jobID, migrationSignalPause) {
+		return nil
+	}
+	return updateStorageMigrationStatus(job, models.StorageMigrationStatusPaused)
+}
+
+/* ResumeStorageMigration 从断点继续已暂停或失败的迁移任务 */
+func ResumeStorageMigration(jobID string) error {
+	job, err := GetStorageMigration(jobID)
+	if err != nil {
+		return err
+	}
+	if job.Status != models.StorageMigrationStatusPaused && job.Status != models.StorageMigrationStatusFailed {
+		return errors.New(errors.CodeInvalidParameter, "只能继续已暂停或失败的迁移任务")
+	}
+	if _, running := migrationRunners.Load(jobID); running {
+		return errors.New(errors.CodeConflict, "迁移任务正在停止，请稍后重试")
+	}
+	if err := updateStorageMigrationStatus(job, models.StorageMigrationStatusPending); err != nil {
+		return err
+	}
+	go runStorageMigration(jobID)
+	return nil
+}
+
+/* CancelStorageMigration 取消迁移任务，已迁移的文件保持在目标渠道 */
+func CancelStorageMigration(jobID string) error {
+	job, err := GetStorageMigration(jobID)
+	if err != nil {
+		return err
+	}
+	switch job.Status {
+	case models.StorageMigrationStatusCompleted, models.StorageMigrationStatusCancelled:
+		return errors.New(errors.CodeInvalidParameter, "迁移任务已结束")
+	}
+	if signalStorageMigration(jobID, migrationSignalCancel) {
+		return nil
+	}
+	return updateStorageMigrationStatus(job, models.StorageMigrationStatusCancelled)
+}
+
+/* ResumeInterruptedStorageMigrations 服务重启后继续执行中断的迁移任务 */
+func ResumeInterruptedStorageMigrations() {
+	var jobs []models.StorageMigrationJob
+	if err := database.DB.Where("status IN ?", []string{
+		models.StorageMigrationStatusPending, models.StorageMigrationStatusRunning,
+	}).Find(&jobs).Error; err != nil {
+		logger.Warn("查询中断的迁移任务失败: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		logger.Info("继续执行存储迁移任务 [%s]", job.ID)
+		go runStorageMigration(job.ID)
+	}
+}
+
+func signalStorageMigration(jobID string, signal int32) bool {
+	v, ok := migrationRunners.Load(jobID)
+	if !ok {
+		return false
+	}
+	atomic.StoreInt32(v.(*int32), signal)
+	return true
+}
+
+func updateStorageMigrationStatus(job *models.StorageMigrationJob, status string) error {
+	updates := map[string]interface{}{"status": status}
+	if status == models.StorageMigrationStatusCompleted || status == models.StorageMigrationStatusCancelled || status == models.StorageMigrationStatusFailed {
+		now := common.JSONTime(time.Now())
+		updates["finished_at"] = &now
+		job.FinishedAt = &now
+	}
+	if err := database.DB.Model(&models.StorageMigrationJob{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBUpdateFailed, "更新迁移任务状态失败")
+	}
+	job.Status = status
+	broadcastStorageMigration(job)
+	return nil
+}
+
+func broadcastStorageMigration(job *models.StorageMigrationJob) {
+	websocket.BroadcastToAdmins(ws.MessageTypeStorageMigration, job)
+}
+
+func runStorageMigration(jobID string) {
+	signal := new(int32)
+	if _, loaded := migrationRunners.LoadOrStore(jobID, signal); loaded {
+		return
+	}
+	defer migrationRunners.Delete(jobID)
+
+	job, err := GetStorageMigration(jobID)
+	if err != nil {
+		logger.Error("加载迁移任务失败 [%s]: %v", jobID, err)
+		return
+	}
+
+	target, err := storage.GetChannelByID(job.TargetChannelID)
+	if err != nil {
+		job.LastError = "目标存储渠道不存在"
+		database.DB.Model(job).Update("last_error", job.LastError)
+		_ = updateStorageMigrationStatus(job, models.StorageMigrationStatusFailed)
+		return
+	}
+
+	now := common.JSONTime(time.Now())
+	updates := map[string]interface{}{"status": models.StorageMigrationStatusRunning}
+	if job.StartedAt == nil {
+		updates["started_at"] = &now
+		job.StartedAt = &now
+	}
+	if err := database.DB.Model(job).Updates(updates).Error; err != nil {
+		logger.Error("更新迁移任务状态失败 [%s]: %v", jobID, err)
+		return
+	}
+	job.Status = models.StorageMigrationStatusRunning
+	broadcastStorageMigration(job)
+
+	lastBroadcast := time.Now()
+	for {
+		var files []models.File
+		query := database.DB.Where("storage_provider_id = ?", job.SourceChannelID).Order("id ASC").Limit(storageMigrationBatchSize)
+		if job.Cursor != "" {
+			query = query.Where("id > ?", job.Cursor)
+		}
+		if err := query.Find(&files).Error; err != nil {
+			job.LastError = truncateMigrationError(err.Error())
+			database.DB.Model(job).Update("last_error", job.LastError)
+			_ = updateStorageMigrationStatus(job, models.StorageMigrationStatusFailed)
+			return
+		}
+		if len(files) == 0 {
+			break
+		}
+
+		for _, file := range files {
+			switch atomic.LoadInt32(signal) {
+			case migrationSignalPause:
+				_ = updateStorageMigrationStatus(job, models.StorageMigrationStatusPaused)
+				return
+			case migrationSignalCancel:
+				_ = updateStorageMigrationStatus(job, models.StorageMigrationStatusCancelled)
+				return
+			}
+
+			copied, err := migrateFile(job, target, file)
+			job.Processed++
+			job.Cursor = file.ID
+			if err != nil {
+				job.Failed++
+				job.LastError = truncateMigrationError(fmt.Sprintf("%s: %v", file.ID, err))
+				database.DB.Create(&models.StorageMigrationFailure{JobID: job.ID, FileID: file.ID, Error: truncateMigrationError(err.Error())})
+			} else {
+				job.Succeeded++
+				job.BytesCopied += copied
+			}
+
+			database.DB.Model(job).Updates(map[string]interface{}{
+				"processed":    job.Processed,
+				"succeeded":    job.Succeeded,
+				"failed":       job.Failed,
+				"bytes_copied": job.BytesCopied,
+				"cursor":       job.Cursor,
+				"last_error":   job.LastError,
+			})
+
+			if time.Since(lastBroadcast) >= storageMigrationBroadcastInterval {
+				broadcastStorageMigration(job)
+				lastBroadcast = time.Now()
+			}
+			if job.ThrottleMs > 0 {
+				time.Sleep(time.Duration(job.ThrottleMs) * time.Millisecond)
+			}
+		}
+	}
+
+	_ = updateStorageMigrationStatus(job, models.StorageMigrationStatusCompleted)
+	logger.Info("存储迁移任务完成 [%s]: 成功=%d, 失败=%d", job.ID, job.Succeeded, job.Failed)
+}
+
+// migrateFile 复制单个文件（含缩略图）到目标渠道，校验后原子切换文件记录，返回复制的字节数
+func migrateFile(job *models.StorageMigrationJob, target *models.StorageChannel, file models.File) (int64, error) {
+	cp, err := copyFileToChannel(file, target, job.DryRun)
+	if err != nil {
+		return 0, err
+	}
+	if job.DryRun {
+		return cp.Bytes, nil
+	}
+
+	migrated := cp.View
+	// 仅当文件仍位于源渠道时切换，避免与并发修改冲突
+	result := database.DB.Model(&models.File{}).
+		Where("id = ? AND storage_provider_id = ?", file.ID, job.SourceChannelID).
+		Updates(map[string]interface{}{
+			"storage_provider_id": migrated.StorageProviderID,
+			"storage_type":        migrated.StorageType,
+			"file_name":           migrated.FileName,
+			"file_path":           migrated.FilePath,
+			"url":                 migrated.URL,
+			"full_path":           migrated.FullPath,
+			"remote_url":          migrated.RemoteURL,
+			"local_file_path":     migrated.LocalFilePath,
+			"thumb_url":           migrated.ThumbURL,
+			"local_thumb_path":    migrated.LocalThumbPath,
+			"remote_thumb_url":    migrated.RemoteThumbURL,
+		})
+	if result.Error != nil {
+		cp.rollback()
+		return 0, errors.Wrap(result.Error, errors.CodeDBUpdateFailed, "更新文件存储信息失败")
+	}
+	if result.RowsAffected == 0 {
+		cp.rollback()
+		return 0, errors.New(errors.CodeConflict, "文件在迁移过程中已被修改")
+	}
+
+	if job.DeleteSource {
+		// 秒传文件共享存储对象，源渠道上仍有其他文件引用时保留
+		var refs int64
+		database.DB.Model(&models.File{}).
+			Where("storage_provider_id = ? AND url = ? AND id <> ?", job.SourceChannelID, file.URL, file.ID).
+			Count(&refs)
+		if refs == 0 {
+			deleteChannelObjects(file)
+		}
+		DeleteFileVariants(file.ID)
+	}
+	return cp.Bytes, nil
+}
+
+// channelCopy 文件在目标渠道上的副本
+type channelCopy struct {
+	View      models.File // 指向目标渠道副本的文件视图
+	Bytes     int64
+	channelID string
+	uploaded  []string
+}
+
+func (c *channelCopy) rollback() {
+	mgr := newstorage.NewGlobalStorage().GetManager()
+	for _, p := range c.uploaded {
+		if err := mgr.Delete(context.Background(), c.channelID, p); err != nil {
+			logger.Warn("回滚目标渠道文件失败 %s: %v", p, err)
+		}
+	}
+}
+
+// copyFileToChannel 读取文件原图与缩略图并写入目标渠道，读回校验大小与MD5；dryRun 时只读取源文件
+func copyFileToChannel(file models.File, target *models.StorageChannel, dryRun bool) (*channelCopy, error) {
+	data, err := ReadFileContent(file, false)
+	if err != nil {
+		return nil, err
+	}
+	var thumbData []byte
+	if file.ThumbURL != "" || file.LocalThumbPath != "" || file.RemoteThumbURL != "" {
+		if thumbData, err = ReadFileContent(file, true); err != nil {
+			// 缩略图缺失不阻断复制，服务端会回退到原图
+			logger.Warn("读取缩略图失败，副本将不含缩略图 [%s]: %v", file.ID, err)
+			thumbData = nil
+		}
+	}
+	cp := &channelCopy{Bytes: int64(len(data) + len(thumbData)), channelID: target.ID}
+	if dryRun {
+		return cp, nil
+	}
+
+	ctx := context.Background()
+	mgr := newstorage.NewGlobalStorage().GetManager()
+	folderPath, fileName := migrationObjectName(file)
+
+	res, err := mgr.Upload(ctx, target.ID, &adapter.UploadRequest{
+		ProcessedData: data,
+		UserID:        file.UserID,
+		FolderPath:    folderPath,
+		FileName:      fileName,
+		ContentType:   formats.GetContentType(file.Format),
+		Options:       &adapter.UploadOptions{},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeFileUploadFailed, "上传到目标渠道失败")
+	}
+	cp.uploaded = append(cp.uploaded, res.OriginalPath)
+
+	view := file
+	view.StorageProviderID = target.ID
+	view.StorageType = target.Type
+	view.FileName = path.Base(res.URL)
+	view.FilePath = res.URL
+	view.URL = res.URL
+	view.FullPath = res.RemoteURL
+	view.RemoteURL = res.RemoteURL
+	view.LocalFilePath = res.OriginalPath
+	view.ThumbURL, view.LocalThumbPath, view.RemoteThumbURL = "", "", ""
+
+	if len(thumbData) > 0 {
+		thumbName := migrationThumbName(file, view.FileName)
+		tres, err := mgr.Upload(ctx, target.ID, &adapter.UploadRequest{
+			ProcessedData: thumbData,
+			UserID:        file.UserID,
+			FolderPath:    folderPath,
+			FileName:      thumbName,
+			ContentType:   formats.GetContentType(strings.TrimPrefix(path.Ext(thumbName), ".")),
+			Options:       &adapter.UploadOptions{},
+		})
+		if err != nil {
+			cp.rollback()
+			return nil, errors.Wrap(err, errors.CodeFileUploadFailed, "上传缩略图到目标渠道失败")
+		}
+		cp.uploaded = append(cp.uploaded, tres.OriginalPath)
+		view.ThumbURL = tres.URL
+		view.LocalThumbPath = tres.OriginalPath
+		view.RemoteThumbURL = tres.RemoteURL
+	}
+
+	if err := verifyMigratedContent(view, false, data); err != nil {
+		cp.rollback()
+		return nil, err
+	}
+	if len(thumbData) > 0 {
+		if err := verifyMigratedContent(view, true, thumbData); err != nil {
+			cp.rollback()
+			return nil, err
+		}
+	}
+
+	cp.View = view
+	return cp, nil
+}
+
+// deleteChannelObjects 删除文件视图所在渠道中的原图与缩略图，路径解析与读取时保持一致
+func deleteChannelObjects(file models.File) {
+	provider, err := newstorage.GetStorageProviderByChannelID(file.StorageProviderID)
+	if err != nil {
+		logger.Warn("获取源存储渠道失败，跳过删除源文件 [%s]: %v", file.ID, err)
+		return
+	}
+	var paths []string
+	if provider.IsDirectAccess() {
+		paths = []string{file.LocalFilePath, file.LocalThumbPath}
+	} else {
+		paths = []string{ResolveObjectKey(file, false)}
+		if file.ThumbURL != "" || file.RemoteThumbURL != "" {
+			paths = append(paths, ResolveObjectKey(file, true))
+		}
+	}
+
+	st := newstorage.NewGlobalStorage()
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		if err := st.Delete(context.Background(), file.StorageProviderID, p); err != nil {
+			logger.Warn("删除渠道文件失败 %s: %v", p, err)
+		}
+	}
+}
+
+// verifyMigratedContent 从目标渠道读回内容，校验大小与MD5
+func verifyMigratedContent(migrated models.File, isThumb bool, expected []byte) error {
+	actual, err := readChannelContent(migrated, isThumb)
+	if err != nil {
+		return errors.Wrap(err, errors.CodeFileNotFound, "从目标渠道读回文件失败")
+	}
+	if len(actual) != len(expected) {
+		return errors.New(errors.CodeFileUploadFailed, fmt.Sprintf("校验失败：大小不一致（%d != %d）", len(actual), len(expected)))
+	}
+	if want, got := md5.Sum(expected), md5.Sum(actual); !bytes.Equal(want[:], got[:]) {
+		return errors.New(errors.CodeFileUploadFailed, "校验失败：MD5不一致")
+	}
+	return nil
+}
+
+// migrationObjectName 根据逻辑路径拆分目标文件夹与文件名，保持迁移前后的相对路径一致
+func migrationObjectName(file models.File) (string, string) {
+	logical := strings.TrimPrefix(file.URL, "/")
+	if logical == "" || pathutil.IsHTTPURL(logical) {
+		logical = strings.TrimPrefix(file.FilePath, "/")
+	}
+	if logical == "" || pathutil.IsHTTPURL(logical) {
+		name := file.FileName
+		if name == "" {
+			name = file.ID + "." + file.Format
+		}
+		return "", name
+	}
+	dir := path.Dir(logical)
+	if dir == "." {
+		dir = ""
+	}
+	return dir, path.Base(logical)
+}
+
+func migrationThumbName(file models.File, fileName string) string {
+	for _, candidate := range []string{file.ThumbURL, file.RemoteThumbURL, file.LocalThumbPath} {
+		if candidate != "" && !pathutil.IsHTTPURL(candidate) {
+			return path.Base(candidate)
+		}
+	}
+	return storageutils.MakeThumbName(fileName, "jpg")
+}
+
+func truncateMigrationError(msg string) string {
+	if len(msg) > 500 {
+		return msg[:500]
+	}
+	return msg
+}
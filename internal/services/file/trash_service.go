@@ -0,0 +1,411 @@
+package file
+
+/* Recycle bin for files and folders: trashed items keep their records until retention expires. */
+
+import (
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/folder"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/internal/services/user"
+	"pixelpunk/internal/services/workspace"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const defaultTrashRetentionDays = 30
+
+/* TrashRetentionDays 返回回收站保留天数，0 表示不启用回收站 */
+func TrashRetentionDays() int {
+	days := setting.GetInt("upload", "trash_retention_days", defaultTrashRetentionDays)
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+/* TrashEnabled 是否启用回收站 */
+func TrashEnabled() bool {
+	return TrashRetentionDays() > 0
+}
+
+func trashExpiresAt() common.JSONTime {
+	return common.JSONTime(time.Now().AddDate(0, 0, TrashRetentionDays()))
+}
+
+func isFileInTrash(fileID string) bool {
+	var count int64
+	database.DB.Model(&models.TrashItem{}).
+		Where("item_type = ? AND item_id = ?", models.TrashItemTypeFile, fileID).
+		Count(&count)
+	return count > 0
+}
+
+func cleanupFileTrashItems(fileID string) {
+	if err := database.DB.Where("item_type = ? AND item_id = ?", models.TrashItemTypeFile, fileID).Delete(&models.TrashItem{}).Error; err != nil {
+		logger.Error("删除回收站记录失败 [%s]: %v", fileID, err)
+	}
+}
+
+// folderNamePath 返回文件夹的名称路径（含已删除的文件夹），用于恢复时重建父文件夹
+func folderNamePath(userID uint, folderID string) string {
+	var names []string
+	for depth := 0; folderID != "" && folderID != "0" && depth < 64; depth++ {
+		var f models.Folder
+		if err := database.DB.Unscoped().Where("id = ? AND user_id = ?", folderID, userID).First(&f).Error; err != nil {
+			break
+		}
+		names = append([]string{f.Name}, names...)
+		folderID = f.ParentID
+	}
+	return strings.Join(names, "/")
+}
+
+/* MoveFileToTrash 将文件移入回收站 */
+func MoveFileToTrash(userID uint, fileID string) error {
+	var file models.File
+	if err := database.DB.Scopes(workspace.PersonalScope(userID)).Where("id = ?", fileID).
+		Where("status <> ?", StatusPendingDeletion).
+		First(&file).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New(errors.CodeFileNotFound, "文件不存在")
+		}
+		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	}
+
+	item := models.TrashItem{
+		UserID:           userID,
+		ItemType:         models.TrashItemTypeFile,
+		ItemID:           file.ID,
+		Name:             trashFileName(file),
+		Size:             file.Size,
+		FileCount:        1,
+		OriginalParentID: file.FolderID,
+		OriginalPath:     folderNamePath(userID, file.FolderID),
+		PrevStatus:       file.Status,
+		ExpiresAt:        trashExpiresAt(),
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&item).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBCreateFailed, "创建回收站记录失败")
+		}
+		if err := tx.Model(&models.File{}).Where("id = ?", file.ID).Update("status", StatusPendingDeletion).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBUpdateFailed, "标记文件为已删除失败")
+		}
+		return user.AdjustFileUsage(tx, userID, -1, -file.Size)
+	})
+}
+
+/* MoveFolderToTrash 将文件夹及其中的子文件夹、文件一并移入回收站 */
+func MoveFolderToTrash(userID uint, folderID string) error {
+	var root models.Folder
+	if err := database.DB.Scopes(workspace.PersonalScope(userID)).Where("id = ?", folderID).First(&root).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New(errors.CodeFolderNotFound, "文件夹不存在")
+		}
+		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹失败")
+	}
+
+	folderIDs := []string{root.ID}
+	var subFolders []models.Folder
+	for queue := []string{root.ID}; len(queue) > 0; {
+		var children []models.Folder
+		if err := database.DB.Where("user_id = ? AND parent_id IN ?", userID, queue).Find(&children).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBQueryFailed, "查询子文件夹失败")
+		}
+		queue = queue[:0]
+		for _, child := range children {
+			subFolders = append(subFolders, child)
+			folderIDs = append(folderIDs, child.ID)
+			queue = append(queue, child.ID)
+		}
+	}
+
+	var files []models.File
+	if err := database.DB.Where("user_id = ? AND folder_id IN ?", userID, folderIDs).
+		Where("status <> ?", StatusPendingDeletion).
+		Find(&files).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	}
+
+	var totalSize int64
+	for _, f := range files {
+		totalSize += f.Size
+	}
+
+	expiresAt := trashExpiresAt()
+	top := models.TrashItem{
+		UserID:           userID,
+		ItemType:         models.TrashItemTypeFolder,
+		ItemID:           root.ID,
+		Name:             root.Name,
+		Size:             totalSize,
+		FileCount:        len(files),
+		OriginalParentID: root.ParentID,
+		OriginalPath:     folderNamePath(userID, root.ParentID),
+		ExpiresAt:        expiresAt,
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&top).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBCreateFailed, "创建回收站记录失败")
+		}
+
+		children := make([]models.TrashItem, 0, len(subFolders)+len(files))
+		for _, sf := range subFolders {
+			children = append(children, models.TrashItem{
+				UserID: userID, ItemType: models.TrashItemTypeFolder, ItemID: sf.ID, ParentTrashID: top.ID,
+				Name: sf.Name, OriginalParentID: sf.ParentID, ExpiresAt: expiresAt,
+			})
+		}
+		fileIDs := make([]string, 0, len(files))
+		for _, f := range files {
+			fileIDs = append(fileIDs, f.ID)
+			children = append(children, models.TrashItem{
+				UserID: userID, ItemType: models.TrashItemTypeFile, ItemID: f.ID, ParentTrashID: top.ID,
+				Name: trashFileName(f), Size: f.Size, FileCount: 1, OriginalParentID: f.FolderID,
+				PrevStatus: f.Status, ExpiresAt: expiresAt,
+			})
+		}
+		if len(children) > 0 {
+			if err := tx.CreateInBatches(&children, 200).Error; err != nil {
+				return errors.Wrap(err, errors.CodeDBCreateFailed, "创建回收站记录失败")
+			}
+		}
+
+		if len(fileIDs) > 0 {
+			if err := tx.Model(&models.File{}).Where("id IN ?", fileIDs).Update("status", StatusPendingDeletion).Error; err != nil {
+				return errors.Wrap(err, errors.CodeDBUpdateFailed, "标记文件为已删除失败")
+			}
+			if err := user.AdjustFileUsage(tx, userID, -int64(len(fileIDs)), -totalSize); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("id IN ?", folderIDs).Delete(&models.Folder{}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeFolderDeleteFailed, "删除文件夹失败")
+		}
+		return nil
+	})
+}
+
+func trashFileName(f models.File) string {
+	if f.DisplayName != "" {
+		return f.DisplayName
+	}
+	return f.OriginalName
+}
+
+/* ListTrash 分页获取用户回收站中的顶层条目 */
+func ListTrash(userID uint, page, size int) ([]models.TrashItem, int64, error) {
+	query := database.DB.Model(&models.TrashItem{}).Where("user_id = ? AND parent_trash_id = ''", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询回收站失败")
+	}
+
+	var items []models.TrashItem
+	if err := query.Order("created_at DESC").Offset((page - 1) * size).Limit(size).Find(&items).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询回收站失败")
+	}
+	return items, total, nil
+}
+
+func loadTopTrashItems(userID uint, ids []string) ([]models.TrashItem, error) {
+	var items []models.TrashItem
+	if err := database.DB.Where("user_id = ? AND id IN ? AND parent_trash_id = ''", userID, ids).Find(&items).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询回收站失败")
+	}
+	return items, nil
+}
+
+/* RestoreTrashItems 从回收站恢复条目到原位置，原父文件夹已不存在时按原路径重建 */
+func RestoreTrashItems(userID uint, ids []string) ([]string, error) {
+	items, err := loadTopTrashItems(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := make([]string, 0, len(items))
+	for _, item := range items {
+		if err := restoreTrashItem(item); err != nil {
+			logger.Warn("恢复回收站条目失败 [%s]: %v", item.ID, err)
+			continue
+		}
+		restored = append(restored, item.ID)
+	}
+	return restored, nil
+}
+
+// resolveRestoreParent 返回恢复目标文件夹，原文件夹不存在时按名称路径重建
+func resolveRestoreParent(userID uint, parentID, path string) (string, error) {
+	if parentID == "" || parentID == "0" {
+		return "", nil
+	}
+	var count int64
+	database.DB.Model(&models.Folder{}).Where("id = ? AND user_id = ?", parentID, userID).Count(&count)
+	if count > 0 {
+		return parentID, nil
+	}
+	return folder.CreateFolderByPath(userID, path)
+}
+
+func restoreTrashItem(item models.TrashItem) error {
+	parentID, err := resolveRestoreParent(item.UserID, item.OriginalParentID, item.OriginalPath)
+	if err != nil {
+		return err
+	}
+
+	var children []models.TrashItem
+	if item.ItemType == models.TrashItemTypeFolder {
+		if err := database.DB.Where("parent_trash_id = ?", item.ID).Find(&children).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBQueryFailed, "查询回收站失败")
+		}
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var fileItems []models.TrashItem
+		switch item.ItemType {
+		case models.TrashItemTypeFile:
+			if err := tx.Model(&models.File{}).Where("id = ?", item.ItemID).Update("folder_id", parentID).Error; err != nil {
+				return errors.Wrap(err, errors.CodeDBUpdateFailed, "恢复文件失败")
+			}
+			fileItems = append(fileItems, item)
+		case models.TrashItemTypeFolder:
+			if err := tx.Unscoped().Model(&models.Folder{}).Where("id = ?", item.ItemID).
+				Updates(map[string]interface{}{"deleted_at": nil, "parent_id": parentID}).Error; err != nil {
+				return errors.Wrap(err, errors.CodeFolderUpdateFailed, "恢复文件夹失败")
+			}
+			folderIDs := make([]string, 0)
+			for _, child := range children {
+				if child.ItemType == models.TrashItemTypeFolder {
+					folderIDs = append(folderIDs, child.ItemID)
+				} else {
+					fileItems = append(fileItems, child)
+				}
+			}
+			if len(folderIDs) > 0 {
+				if err := tx.Unscoped().Model(&models.Folder{}).Where("id IN ?", folderIDs).Update("deleted_at", nil).Error; err != nil {
+					return errors.Wrap(err, errors.CodeFolderUpdateFailed, "恢复文件夹失败")
+				}
+			}
+		}
+
+		var restoredCount, restoredSize int64
+		for _, fi := range fileItems {
+			status := fi.PrevStatus
+			if status == "" || status == StatusPendingDeletion {
+				status = "active"
+			}
+			result := tx.Model(&models.File{}).Where("id = ? AND status = ?", fi.ItemID, StatusPendingDeletion).Update("status", status)
+			if result.Error != nil {
+				return errors.Wrap(result.Error, errors.CodeDBUpdateFailed, "恢复文件失败")
+			}
+			if result.RowsAffected > 0 {
+				restoredCount++
+				restoredSize += fi.Size
+			}
+		}
+		if restoredCount > 0 {
+			if err := user.AdjustFileUsage(tx, item.UserID, restoredCount, restoredSize); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("id = ? OR parent_trash_id = ?", item.ID, item.ID).Delete(&models.TrashItem{}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除回收站记录失败")
+		}
+		return nil
+	})
+}
+
+/* PurgeTrashItems 彻底删除回收站中的指定条目 */
+func PurgeTrashItems(userID uint, ids []string) (int, error) {
+	items, err := loadTopTrashItems(userID, ids)
+	if err != nil {
+		return 0, err
+	}
+	return purgeTrashItems(items), nil
+}
+
+/* EmptyTrash 清空用户回收站 */
+func EmptyTrash(userID uint) (int, error) {
+	var items []models.TrashItem
+	if err := database.DB.Where("user_id = ? AND parent_trash_id = ''", userID).Find(&items).Error; err != nil {
+		return 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询回收站失败")
+	}
+	return purgeTrashItems(items), nil
+}
+
+/* PurgeExpiredTrash 清除超过保留期的回收站条目（定时任务调用） */
+func PurgeExpiredTrash(limit int) (int, error) {
+	var items []models.TrashItem
+	query := database.DB.Where("parent_trash_id = '' AND expires_at < ?", time.Now()).Order("expires_at ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&items).Error; err != nil {
+		return 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询过期回收站条目失败")
+	}
+	return purgeTrashItems(items), nil
+}
+
+func purgeTrashItems(items []models.TrashItem) int {
+	purged := 0
+	for _, item := range items {
+		if err := purgeTrashItem(item); err != nil {
+			logger.Warn("清除回收站条目失败 [%s]: %v", item.ID, err)
+			continue
+		}
+		purged++
+	}
+	return purged
+}
+
+func purgeTrashItem(item models.TrashItem) error {
+	entries := []models.TrashItem{item}
+	if item.ItemType == models.TrashItemTypeFolder {
+		var children []models.TrashItem
+		if err := database.DB.Where("parent_trash_id = ?", item.ID).Find(&children).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBQueryFailed, "查询回收站失败")
+		}
+		entries = append(entries, children...)
+	}
+
+	folderIDs := make([]string, 0)
+	for _, entry := range entries {
+		if entry.ItemType == models.TrashItemTypeFolder {
+			folderIDs = append(folderIDs, entry.ItemID)
+			continue
+		}
+		var file models.File
+		if err := database.DB.Where("id = ?", entry.ItemID).First(&file).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				continue
+			}
+			return errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+		}
+		// 用户统计已在移入回收站时扣减
+		if err := deleteFileWithCascade(&file, file.UserID, false); err != nil {
+			return err
+		}
+	}
+
+	if len(folderIDs) > 0 {
+		if err := database.DB.Unscoped().Where("id IN ?", folderIDs).Delete(&models.Folder{}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeFolderDeleteFailed, "删除文件夹失败")
+		}
+	}
+	if err := database.DB.Where("id = ? OR parent_trash_id = ?", item.ID, item.ID).Delete(&models.TrashItem{}).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除回收站记录失败")
+	}
+	return nil
+}
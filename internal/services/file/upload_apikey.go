@@ -2,7 +2,9 @@ package file
 
 import (
 	"fmt"
+	"io"
 	"mime/multipart"
+	"net/textproto"
 	"path/filepath"
 	"strings"
 
@@ -11,7 +13,6 @@ import (
 	"pixelpunk/internal/models"
 	"pixelpunk/internal/services/apikey"
 	"pixelpunk/internal/services/folder"
-	"pixelpunk/internal/services/stats"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 	"pixelpunk/pkg/logger"
@@ -246,7 +247,7 @@ func validateSingleFileLimits(key *models.APIKey, file *multipart.FileHeader) er
 
 /* UploadFileForAPI API专用的文件上传，返回简化响应 */
 func UploadFileForAPI(c *gin.Context, userID uint, file *multipart.FileHeader, folderID, accessLevel string, optimize bool) (*ExternalAPIFileResponse, error) {
-	available, err := stats.CheckUserStorageAvailable(userID, file.Size)
+	available, err := checkStorageAvailable(userID, folderID, file.Size)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.CodeInternal, "检查用户存储空间失败")
 	}
@@ -276,3 +277,79 @@ func UploadFileForAPI(c *gin.Context, userID uint, file *multipart.FileHeader, f
 
 	return createExternalAPIResponse(ctx), nil
 }
+
+/* UploadReaderWithAPIKey 以数据流形式使用API密钥上传单个文件，与表单上传共用配额校验、处理与入库流程 */
+func UploadReaderWithAPIKey(key *models.APIKey, folderID, fileName, contentType, accessLevel string, body io.Reader) (*models.File, error) {
+	return uploadFromReader(key.UserID, key, folderID, fileName, contentType, accessLevel, body)
+}
+
+/* UploadReader 以数据流形式为用户上传单个文件（供 WebDAV 等非表单入口使用） */
+func UploadReader(userID uint, folderID, fileName, contentType, accessLevel string, body io.Reader) (*models.File, error) {
+	return uploadFromReader(userID, nil, folderID, fileName, contentType, accessLevel, body)
+}
+
+func uploadFromReader(userID uint, key *models.APIKey, folderID, fileName, contentType, accessLevel string, body io.Reader) (*models.File, error) {
+	header, cleanup, err := fileHeaderFromReader(fileName, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	if key != nil {
+		if err := validateSingleFileLimits(key, header); err != nil {
+			return nil, err
+		}
+	}
+
+	info, err := UploadFileForAPI(nil, userID, header, folderID, accessLevel, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if key != nil {
+		if err := associateFileWithAPIKey(info.ID, key.ID); err != nil {
+			logger.Error("更新文件API密钥关联失败", "fileID", info.ID, "error", err)
+		}
+		go updateAPIKeyUsageAsync(key.ID, header.Size)
+	}
+
+	var file models.File
+	if err := database.DB.Where("id = ?", info.ID).First(&file).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询上传文件失败")
+	}
+	return &file, nil
+}
+
+// fileHeaderFromReader 将数据流转换为 multipart.FileHeader，超过内存阈值的内容落盘到临时文件
+func fileHeaderFromReader(fileName, contentType string, body io.Reader) (*multipart.FileHeader, func(), error) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	go func() {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, strings.ReplaceAll(fileName, `"`, "")))
+		if contentType != "" {
+			h.Set("Content-Type", contentType)
+		}
+		part, err := writer.CreatePart(h)
+		if err == nil {
+			_, err = io.Copy(part, body)
+		}
+		if err == nil {
+			err = writer.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	form, err := multipart.NewReader(pr, writer.Boundary()).ReadForm(32 << 20)
+	// 读取失败时让写入端尽快退出
+	pr.CloseWithError(io.ErrClosedPipe)
+	if err != nil {
+		return nil, func() {}, errors.Wrap(err, errors.CodeInvalidParameter, "读取上传内容失败")
+	}
+	files := form.File["file"]
+	if len(files) == 0 {
+		form.RemoveAll()
+		return nil, func() {}, errors.New(errors.CodeInvalidParameter, "未检测到上传文件")
+	}
+	return files[0], func() { form.RemoveAll() }, nil
+}
@@ -92,8 +92,11 @@ type UploadContext struct {
 	FolderID   string // 文件夹ID
 	FolderPath string // 文件夹相对路径
 
+	WorkspaceID string // 目标文件夹所属工作区，空表示个人空间
+
 	FileExt           string // 文件扩展名
 	FileHash          string // 文件MD5哈希
+	PHash             string // 感知哈希（仅图片）
 	IsDuplicate       bool   // 是否重复文件
 	OriginalFileID    string // 原始文件ID（重复文件时有值）
 	ReuseExistingFile bool   // 是否复用现有文件
@@ -228,6 +231,7 @@ func CreateInstantUploadContext(c *gin.Context, userID uint, originalFile *model
 		ReuseExistingFile: true,                 // 标记为复用现有文件
 		ExistingFile:      originalFile,         // 保存原文件信息
 		FileHash:          originalFile.MD5Hash, // 使用原文件的MD5
+		PHash:             originalFile.PHash,
 		FileSize:          fileSize,
 		FileFormat:        originalFile.Format,
 		ActualChannelID:   originalFile.StorageProviderID,
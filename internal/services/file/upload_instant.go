@@ -4,7 +4,6 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"pixelpunk/internal/models"
-	"pixelpunk/internal/services/stats"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 	"pixelpunk/pkg/logger"
@@ -83,7 +82,7 @@ func InstantUpload(c *gin.Context, userID uint, md5Hash, fileName string, fileSi
 		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询原始文件失败")
 	}
 
-	available, err := stats.CheckUserStorageAvailable(userID, fileSize)
+	available, err := checkStorageAvailable(userID, folderID, fileSize)
 	if err != nil {
 		logger.Error("检查用户存储空间失败: %v", err)
 		return nil, errors.Wrap(err, errors.CodeInternal, "检查用户存储空间失败")
@@ -139,7 +138,7 @@ func InstantUploadWithDuration(c *gin.Context, userID uint, md5Hash, fileName st
 		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询原始文件失败")
 	}
 
-	available, err := stats.CheckUserStorageAvailable(userID, fileSize)
+	available, err := checkStorageAvailable(userID, folderID, fileSize)
 	if err != nil {
 		logger.Error("检查用户存储空间失败: %v", err)
 		return nil, errors.Wrap(err, errors.CodeInternal, "检查用户存储空间失败")
@@ -55,6 +55,7 @@ func createFileModel(ctx *UploadContext) *models.File {
 		ID:                        ctx.FileID,
 		UserID:                    ctx.UserID,
 		FolderID:                  ctx.FolderID,
+		WorkspaceID:               ctx.WorkspaceID,
 		OriginalName:              ctx.File.Filename,
 		DisplayName:               ctx.DisplayName,
 		FileName:                  filepath.Base(ctx.Result.URL),
@@ -67,6 +68,7 @@ func createFileModel(ctx *UploadContext) *models.File {
 		RemoteURL:                 ctx.Result.RemoteUrl,
 		RemoteThumbURL:            ctx.Result.RemoteThumbUrl,
 		MD5Hash:                   ctx.FileHash,
+		PHash:                     ctx.PHash,
 		Size:                      ctx.File.Size,
 		SizeFormatted:             sizeFormatted,
 		Width:                     ctx.Result.Width,
@@ -122,10 +124,13 @@ func saveFileRecord(tx *gorm.DB, file *models.File) error {
 	if err := InitFileStats(tx, file.ID); err != nil {
 		return err
 	}
-	return nil
+	return savePHashBands(tx, file)
 }
 
 func updateUserStats(tx *gorm.DB, ctx *UploadContext) error {
+	if ctx.WorkspaceID != "" {
+		return nil // 工作区文件计入工作区共享配额，不占用上传者的个人用量
+	}
 	return user.UpdateFileUploadStats(tx, ctx.UserID, ctx.File.Size)
 }
 
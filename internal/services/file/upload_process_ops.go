@@ -9,6 +9,7 @@ import (
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 	"pixelpunk/pkg/exif"
+	imgHash "pixelpunk/pkg/imagex/hash"
 	pkgStorage "pixelpunk/pkg/storage"
 	storageutils "pixelpunk/pkg/storage/utils"
 	"strings"
@@ -38,6 +39,12 @@ func processFile(ctx *UploadContext) error {
 		ctx.EXIFData = convertToFileEXIF(exifData)
 	}
 
+	if ctx.IsDuplicate && ctx.ExistingFile != nil && ctx.ExistingFile.PHash != "" {
+		ctx.PHash = ctx.ExistingFile.PHash
+	} else if phash, err := imgHash.DHashFromBytes(ctx.OriginalFileData); err == nil {
+		ctx.PHash = imgHash.FormatPerceptual(phash)
+	}
+
 	src.Seek(0, 0)
 	return processFileName(ctx)
 }
@@ -8,7 +8,8 @@ import (
 	"pixelpunk/internal/services/activity"
 	"pixelpunk/internal/services/ai"
 	messageService "pixelpunk/internal/services/message"
-	"pixelpunk/internal/services/stats"
+	"pixelpunk/internal/services/webhook"
+	"pixelpunk/internal/services/workspace"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
@@ -29,7 +30,7 @@ func UploadFile(c *gin.Context, userID uint, file *multipart.FileHeader, folderI
 
 /* UploadFileWithDuration 上传单张文件（支持存储时长） */
 func UploadFileWithDuration(c *gin.Context, userID uint, file *multipart.FileHeader, folderID, accessLevel string, optimize bool, storageDuration string) (*FileDetailResponse, error) {
-	available, err := stats.CheckUserStorageAvailable(userID, file.Size)
+	available, err := checkStorageAvailable(userID, folderID, file.Size)
 	if err != nil {
 		logger.Error("检查用户存储空间失败: %v", err)
 		return nil, errors.Wrap(err, errors.CodeInternal, "检查用户存储空间失败")
@@ -90,6 +91,10 @@ func saveFileRecordAndStats(ctx *UploadContext) error {
 		return err
 	}
 	updateStatisticsAsync(ctx)
+	workspace.RecordActivity(ctx.WorkspaceID, ctx.UserID, workspace.ActivityFileUpload, "file", ctx.FileID, ctx.File.Filename, ctx.File.Size)
+	if ctx.SavedFile != nil {
+		webhook.Dispatch(webhook.EventFileUploaded, ctx.UserID, webhook.FileEventData(ctx.SavedFile))
+	}
 	return nil
 }
 
@@ -237,6 +242,10 @@ func saveFileData(ctx *UploadContext) error {
 			vector.AddFileToVectorQueue(*file)
 		}
 
+		if RenditionMode() == RenditionModeUpload {
+			GenerateRenditions(*file)
+		}
+
 	}()
 
 	return nil
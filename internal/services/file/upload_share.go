@@ -0,0 +1,57 @@
+package file
+
+import (
+	"mime/multipart"
+
+	"github.com/gin-gonic/gin"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+)
+
+/* ShareUpload 文件收集分享的访客上传：沿用游客上传的IP限制与上传日志，文件归属分享者并计入其存储配额 */
+func ShareUpload(c *gin.Context, ownerID uint, file *multipart.FileHeader, folderID string) (*FileDetailResponse, error) {
+	if ownerID == 0 {
+		return nil, errors.New(errors.CodeInvalidParameter, "分享者不存在")
+	}
+
+	ip := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+	fingerprint := common.GenerateFingerprint(c.Request)
+
+	service := GetGuestUploadLimitService()
+	if _, err := service.CheckIPUploadLimit(ip); err != nil {
+		recordShareUploadLog(file, fingerprint, ip, userAgent, "", "blocked", err.Error())
+		return nil, err
+	}
+
+	fileInfo, err := UploadFileWithDuration(c, ownerID, file, folderID, "private", false, "")
+	if err != nil {
+		recordShareUploadLog(file, fingerprint, ip, userAgent, "", "failed", err.Error())
+		return nil, err
+	}
+
+	recordShareUploadLog(file, fingerprint, ip, userAgent, fileInfo.ID, "success", "")
+	return fileInfo, nil
+}
+
+// recordShareUploadLog 写入游客上传日志，IP每日上传次数按该日志统计
+func recordShareUploadLog(file *multipart.FileHeader, fingerprint, ip, userAgent, fileID, status, reason string) {
+	log := &models.GuestUploadLog{
+		FileID:          fileID,
+		Fingerprint:     fingerprint,
+		IP:              ip,
+		UserAgent:       userAgent,
+		StorageDuration: "permanent",
+		Status:          status,
+		Reason:          reason,
+		FileSize:        file.Size,
+		FileName:        file.Filename,
+		OriginalName:    file.Filename,
+	}
+	if err := GetGuestUploadLogService().RecordGuestUpload(log); err != nil {
+		logger.Error("记录文件收集上传日志失败: %v", err)
+	}
+}
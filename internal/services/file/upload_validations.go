@@ -8,14 +8,13 @@ import (
 	"path/filepath"
 	"pixelpunk/internal/models"
 	"pixelpunk/internal/services/setting"
+	"pixelpunk/internal/services/workspace"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 	"pixelpunk/pkg/logger"
 	"strings"
 	"time"
-
-	"gorm.io/gorm"
 )
 
 func validateUploadInput(ctx *UploadContext) error {
@@ -96,13 +95,11 @@ func validateFolder(ctx *UploadContext) error {
 	if ctx.FolderID == "" {
 		return nil
 	}
-	var folder models.Folder
-	if err := database.DB.Where("id = ? AND user_id = ?", ctx.FolderID, ctx.UserID).First(&folder).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.New(errors.CodeFolderNotFound, "文件夹不存在")
-		}
-		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹失败")
+	folder, err := workspace.ResolveFolder(ctx.UserID, ctx.FolderID, workspace.ActionUpload)
+	if err != nil {
+		return err
 	}
+	ctx.WorkspaceID = folder.WorkspaceID
 	return nil
 }
 
@@ -4,7 +4,6 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"mime/multipart"
-	"pixelpunk/internal/services/stats"
 	"pixelpunk/pkg/errors"
 	"pixelpunk/pkg/logger"
 	"pixelpunk/pkg/watermark"
@@ -60,7 +59,7 @@ func applyWatermarkToFile(ctx *UploadContext) error {
 
 /* UploadFileWithWatermark 上传单张文件（支持水印） */
 func UploadFileWithWatermark(c *gin.Context, userID uint, file *multipart.FileHeader, folderID, accessLevel string, optimize bool, storageDuration string, watermarkEnabled bool, watermarkConfig string) (*FileUploadResponse, error) {
-	available, err := stats.CheckUserStorageAvailable(userID, file.Size)
+	available, err := checkStorageAvailable(userID, folderID, file.Size)
 	if err != nil {
 		logger.Error("检查用户存储空间失败: %v", err)
 		return nil, errors.Wrap(err, errors.CodeInternal, "检查用户存储空间失败")
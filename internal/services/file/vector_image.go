@@ -0,0 +1,58 @@
+package file
+
+/* Supplies downscaled image bytes to the vector engine for pixel (CLIP-style) embeddings. */
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/vector"
+
+	"github.com/disintegration/imaging"
+)
+
+// 多模态模型的输入尺寸通常只有 224~512，缩小后再上传可显著减少请求体积
+const embeddingImageSize = 512
+
+func init() {
+	vector.SetImageLoader(loadImageForEmbedding)
+}
+
+// loadImageForEmbedding 读取文件图片用于图像向量化，优先使用缩略图
+func loadImageForEmbedding(fileID string) ([]byte, error) {
+	var file models.File
+	if err := database.DB.Where("id = ?", fileID).Take(&file).Error; err != nil {
+		return nil, err
+	}
+	if !file.IsImage() {
+		return nil, fmt.Errorf("非图片文件不生成图像向量")
+	}
+
+	data, err := ReadFileContent(file, true)
+	if err != nil || len(data) == 0 {
+		data, err = ReadFileContent(file, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解码图片失败: %v", err)
+	}
+	return encodeEmbeddingImage(img)
+}
+
+// encodeEmbeddingImage 缩放并统一编码为 JPEG，避免接口不支持 webp/avif 等格式
+func encodeEmbeddingImage(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	resized := imaging.Fit(img, embeddingImageSize, embeddingImageSize, imaging.Lanczos)
+	if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, fmt.Errorf("编码图片失败: %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,423 @@
+package file
+
+/* Serve-time watermark policies: originals stay clean, public reads get a cached watermarked variant. */
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/imagex/formats"
+	"pixelpunk/pkg/imagex/transform"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/watermark"
+
+	"gorm.io/gorm"
+)
+
+const (
+	watermarkPolicyCacheTTL  = 30 * time.Second
+	maxWatermarkPolicies     = 50
+	maxWatermarkConfigLength = 2 << 20 // 含 base64 水印图片
+	maxFolderDepth           = 64
+)
+
+/* WatermarkPolicyInput 创建/更新水印策略的参数，更新时 nil 字段保持不变 */
+type WatermarkPolicyInput struct {
+	Name      *string
+	ScopeType string
+	ScopeID   string
+	Config    *string
+	Enabled   *bool
+}
+
+type cachedWatermarkPolicies struct {
+	policies []models.WatermarkPolicy
+	loadedAt time.Time
+}
+
+// 按用户缓存已启用的策略，访问文件时无需每次查询
+var watermarkPolicyCache sync.Map
+
+/* CreateWatermarkPolicy 创建水印策略，同一作用域只能有一条策略 */
+func CreateWatermarkPolicy(userID uint, input WatermarkPolicyInput) (*models.WatermarkPolicy, error) {
+	scopeID, err := resolveWatermarkScope(userID, input.ScopeType, input.ScopeID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Config == nil {
+		return nil, errors.New(errors.CodeInvalidParameter, "水印配置不能为空")
+	}
+	config, err := normalizeWatermarkConfig(*input.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	database.DB.Model(&models.WatermarkPolicy{}).Where("user_id = ?", userID).Count(&count)
+	if count >= maxWatermarkPolicies {
+		return nil, errors.New(errors.CodeInvalidParameter, fmt.Sprintf("最多创建 %d 条水印策略", maxWatermarkPolicies))
+	}
+	if watermarkScopeTaken(input.ScopeType, scopeID, 0) {
+		return nil, errors.New(errors.CodeInvalidParameter, "该作用域已存在水印策略")
+	}
+
+	policy := models.WatermarkPolicy{
+		UserID:    userID,
+		ScopeType: input.ScopeType,
+		ScopeID:   scopeID,
+		Config:    config,
+		Enabled:   true,
+	}
+	if input.Name != nil {
+		policy.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Enabled != nil {
+		policy.Enabled = *input.Enabled
+	}
+	if err := database.DB.Create(&policy).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBCreateFailed, "创建水印策略失败")
+	}
+
+	invalidateWatermarkPolicies(userID)
+	return &policy, nil
+}
+
+/* ListWatermarkPolicies 分页获取用户的水印策略 */
+func ListWatermarkPolicies(userID uint, page, size int) ([]models.WatermarkPolicy, int64, error) {
+	query := database.DB.Model(&models.WatermarkPolicy{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询水印策略失败")
+	}
+
+	var policies []models.WatermarkPolicy
+	if err := query.Order("id DESC").Offset((page - 1) * size).Limit(size).Find(&policies).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询水印策略失败")
+	}
+	return policies, total, nil
+}
+
+/* GetWatermarkPolicy 获取用户的单条水印策略 */
+func GetWatermarkPolicy(userID, policyID uint) (*models.WatermarkPolicy, error) {
+	var policy models.WatermarkPolicy
+	if err := database.DB.Where("id = ? AND user_id = ?", policyID, userID).First(&policy).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeNotFound, "水印策略不存在")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询水印策略失败")
+	}
+	return &policy, nil
+}
+
+/* UpdateWatermarkPolicy 更新水印策略，并清理按旧配置生成的水印图 */
+func UpdateWatermarkPolicy(userID, policyID uint, input WatermarkPolicyInput) (*models.WatermarkPolicy, error) {
+	policy, err := GetWatermarkPolicy(userID, policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.ScopeType != "" {
+		scopeID, err := resolveWatermarkScope(userID, input.ScopeType, input.ScopeID)
+		if err != nil {
+			return nil, err
+		}
+		if watermarkScopeTaken(input.ScopeType, scopeID, policy.ID) {
+			return nil, errors.New(errors.CodeInvalidParameter, "该作用域已存在水印策略")
+		}
+		policy.ScopeType = input.ScopeType
+		policy.ScopeID = scopeID
+	}
+	if input.Config != nil {
+		config, err := normalizeWatermarkConfig(*input.Config)
+		if err != nil {
+			return nil, err
+		}
+		policy.Config = config
+	}
+	if input.Name != nil {
+		policy.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Enabled != nil {
+		policy.Enabled = *input.Enabled
+	}
+
+	if err := database.DB.Save(policy).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "更新水印策略失败")
+	}
+
+	invalidateWatermarkPolicies(userID)
+	go purgeWatermarkVariants(policyWatermark(policy).tag)
+	return policy, nil
+}
+
+/* DeleteWatermarkPolicy 删除水印策略及其生成的水印图 */
+func DeleteWatermarkPolicy(userID, policyID uint) error {
+	policy, err := GetWatermarkPolicy(userID, policyID)
+	if err != nil {
+		return err
+	}
+	if err := database.DB.Delete(policy).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除水印策略失败")
+	}
+
+	invalidateWatermarkPolicies(userID)
+	go purgeWatermarkVariants(policyWatermark(policy).tag)
+	return nil
+}
+
+/* PreviewWatermark 按给定配置为用户文件生成水印预览，不写入缓存 */
+func PreviewWatermark(userID uint, fileID, configJSON string) (*ProxyResponse, error) {
+	config, err := normalizeWatermarkConfig(configJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var file models.File
+	if err := database.DB.Where("id = ? AND user_id = ?", fileID, userID).
+		Where("status <> ?", "pending_deletion").
+		First(&file).Error; err != nil {
+		return nil, errors.New(errors.CodeFileNotFound, "文件不存在或无权访问")
+	}
+	if !supportsWatermark(file) {
+		return nil, errors.New(errors.CodeFileFormatNotSupport, "该文件类型不支持水印")
+	}
+
+	original, err := ReadFileContent(file, false)
+	if err != nil {
+		return nil, err
+	}
+	// 预览限制尺寸，避免大图反复全量处理
+	result, err := watermarkAndTransform(file, original, config, transform.Options{Width: 1280, Height: 1280})
+	if err != nil {
+		return nil, err
+	}
+	return &ProxyResponse{
+		Content:       io.NopCloser(bytes.NewReader(result.Data)),
+		ContentType:   formats.GetContentType(result.Format),
+		ContentLength: int64(len(result.Data)),
+	}, nil
+}
+
+/* ServeWatermarkedFile 按文件生效的水印策略返回水印图，未命中策略时返回 nil */
+func ServeWatermarkedFile(file models.File) (*ProxyResponse, error) {
+	if !supportsWatermark(file) {
+		return nil, nil
+	}
+	policy := ResolveWatermarkPolicy(file)
+	if policy == nil {
+		return nil, nil
+	}
+	return serveVariant(file, transform.Options{}, policyWatermark(policy))
+}
+
+/* ServeWatermarkedThumb 按文件生效的水印策略返回带水印的缩略图，由原图合成水印后按缩略图尺寸缩放；未命中策略时返回 nil */
+func ServeWatermarkedThumb(file models.File) (*ProxyResponse, error) {
+	if !supportsWatermark(file) {
+		return nil, nil
+	}
+	policy := ResolveWatermarkPolicy(file)
+	if policy == nil {
+		return nil, nil
+	}
+	thumb := createCompressOptions()
+	opts := transform.Options{
+		Width:   thumb.MaxWidth,
+		Height:  thumb.MaxHeight,
+		Fit:     transform.FitContain,
+		Quality: thumb.Quality,
+		Format:  transform.FormatAuto,
+	}
+	return serveVariant(file, opts, policyWatermark(policy))
+}
+
+/* ResolveWatermarkPolicy 返回文件生效的水印策略，优先级：API密钥 > 文件夹（由近及远） > 用户 */
+func ResolveWatermarkPolicy(file models.File) *models.WatermarkPolicy {
+	policies := enabledWatermarkPolicies(file.UserID)
+	if len(policies) == 0 {
+		return nil
+	}
+
+	find := func(scopeType, scopeID string) *models.WatermarkPolicy {
+		for i := range policies {
+			if policies[i].ScopeType == scopeType && policies[i].ScopeID == scopeID {
+				return &policies[i]
+			}
+		}
+		return nil
+	}
+	hasScope := func(scopeType string) bool {
+		for _, p := range policies {
+			if p.ScopeType == scopeType {
+				return true
+			}
+		}
+		return false
+	}
+
+	if file.APIKeyID != "" {
+		if p := find(models.WatermarkScopeAPIKey, file.APIKeyID); p != nil {
+			return p
+		}
+	}
+
+	if hasScope(models.WatermarkScopeFolder) {
+		for folderID, depth := file.FolderID, 0; folderID != "" && folderID != "0" && depth < maxFolderDepth; depth++ {
+			if p := find(models.WatermarkScopeFolder, folderID); p != nil {
+				return p
+			}
+			var f models.Folder
+			if err := database.DB.Select("id", "parent_id").Where("id = ?", folderID).First(&f).Error; err != nil {
+				break
+			}
+			folderID = f.ParentID
+		}
+	}
+
+	return find(models.WatermarkScopeUser, strconv.FormatUint(uint64(file.UserID), 10))
+}
+
+func enabledWatermarkPolicies(userID uint) []models.WatermarkPolicy {
+	if v, ok := watermarkPolicyCache.Load(userID); ok {
+		if cached := v.(cachedWatermarkPolicies); time.Since(cached.loadedAt) < watermarkPolicyCacheTTL {
+			return cached.policies
+		}
+	}
+
+	var policies []models.WatermarkPolicy
+	if err := database.DB.Where("user_id = ? AND enabled = ?", userID, true).Find(&policies).Error; err != nil {
+		logger.Warn("查询水印策略失败 [%d]: %v", userID, err)
+		return nil
+	}
+	watermarkPolicyCache.Store(userID, cachedWatermarkPolicies{policies: policies, loadedAt: time.Now()})
+	return policies
+}
+
+func invalidateWatermarkPolicies(userID uint) {
+	watermarkPolicyCache.Delete(userID)
+}
+
+// supportsWatermark 动图与矢量图不做水印处理
+func supportsWatermark(file models.File) bool {
+	if !file.IsImage() {
+		return false
+	}
+	switch strings.ToLower(file.Format) {
+	case "jpg", "jpeg", "png", "webp":
+		return true
+	default:
+		return false
+	}
+}
+
+func resolveWatermarkScope(userID uint, scopeType, scopeID string) (string, error) {
+	switch scopeType {
+	case models.WatermarkScopeUser:
+		return strconv.FormatUint(uint64(userID), 10), nil
+	case models.WatermarkScopeFolder:
+		if _, err := models.GetFolderByIDAndUserID(database.DB, scopeID, userID); err != nil {
+			return "", errors.New(errors.CodeFolderNotFound, "文件夹不存在")
+		}
+		return scopeID, nil
+	case models.WatermarkScopeAPIKey:
+		var count int64
+		database.DB.Model(&models.APIKey{}).Where("id = ? AND user_id = ?", scopeID, userID).Count(&count)
+		if count == 0 {
+			return "", errors.New(errors.CodeNotFound, "API密钥不存在")
+		}
+		return scopeID, nil
+	default:
+		return "", errors.New(errors.CodeInvalidParameter, "无效的策略作用域")
+	}
+}
+
+func watermarkScopeTaken(scopeType, scopeID string, excludeID uint) bool {
+	var count int64
+	database.DB.Model(&models.WatermarkPolicy{}).
+		Where("scope_type = ? AND scope_id = ? AND id <> ?", scopeType, scopeID, excludeID).
+		Count(&count)
+	return count > 0
+}
+
+// normalizeWatermarkConfig 校验配置并规范化为 JSON，策略中的水印始终视为启用
+func normalizeWatermarkConfig(raw string) (string, error) {
+	if len(raw) > maxWatermarkConfigLength {
+		return "", errors.New(errors.CodeInvalidParameter, "水印配置过大")
+	}
+	config, err := watermark.ParseConfigFromJSON(raw)
+	if err != nil {
+		return "", errors.Wrap(err, errors.CodeInvalidParameter, "水印配置格式错误")
+	}
+	config.Enabled = true
+	if config.Type == "" {
+		config.Type = watermark.TypeText
+	}
+	if err := watermark.ValidateConfig(config); err != nil {
+		return "", errors.New(errors.CodeInvalidParameter, err.Error())
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", errors.Wrap(err, errors.CodeInternal, "水印配置序列化失败")
+	}
+	return string(data), nil
+}
+
+// variantWatermark 衍生图上叠加的水印，tag 标识水印来源（策略或防盗链），写入衍生图参数以便批量清理
+type variantWatermark struct {
+	tag    string
+	config string
+
+	transient bool // 按请求渲染，不保存衍生图（配置随请求变化时使用）
+}
+
+func policyWatermark(policy *models.WatermarkPolicy) *variantWatermark {
+	if policy == nil {
+		return nil
+	}
+	return &variantWatermark{tag: fmt.Sprintf("wm=%d", policy.ID), config: policy.Config}
+}
+
+// variantKey 缓存键包含水印来源与配置摘要，配置变化后不会命中旧图
+func (w *variantWatermark) variantKey(opts transform.Options) string {
+	sum := md5.Sum([]byte(opts.Key() + "|" + w.tag + "|" + w.config))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// watermarkAndTransform 先在原图尺寸上合成水印，再执行缩放与转码，保证各尺寸水印比例一致
+func watermarkAndTransform(file models.File, original []byte, configJSON string, opts transform.Options) (*transform.Result, error) {
+	result, err := watermark.ProcessBytesWithConfigJSON(original, configJSON)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeFileFormatNotSupport, "水印合成失败")
+	}
+	if opts.Format == "" || opts.Format == transform.FormatAuto {
+		// 水印处理会把非 JPEG 输出为 PNG，这里按原图格式转回
+		opts.Format = autoRenditionFormat(file.Format)
+	}
+	out, err := transform.Apply(result.ProcessedData, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeFileFormatNotSupport, "图片变换失败")
+	}
+	return out, nil
+}
+
+// purgeWatermarkVariants 删除指定来源生成的全部水印图，tag 位于参数末尾，按后缀匹配避免误删
+func purgeWatermarkVariants(tag string) {
+	var variants []models.FileVariant
+	if err := database.DB.Where("params LIKE ?", "%&"+tag).Find(&variants).Error; err != nil {
+		logger.Error("查询水印衍生图失败 [%s]: %v", tag, err)
+		return
+	}
+	deleteVariants(variants)
+}
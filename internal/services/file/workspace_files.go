@@ -0,0 +1,55 @@
+package file
+
+/* Workspace-aware helpers: shared quota checks and folder boundaries for files uploaded into team workspaces. */
+
+import (
+	"pixelpunk/internal/services/workspace"
+	"pixelpunk/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// checkStorageAvailable 上传到工作区文件夹时检查工作区共享配额，否则检查用户个人配额
+func checkStorageAvailable(userID uint, folderID string, size int64) (bool, error) {
+	if folderID != "" && folderID != "null" {
+		folder, err := workspace.ResolveFolder(userID, folderID, workspace.ActionUpload)
+		if err != nil {
+			return false, err
+		}
+		if folder.WorkspaceID != "" {
+			return workspace.CheckStorageAvailable(folder.WorkspaceID, size)
+		}
+	}
+	return workspace.CheckPersonalStorageAvailable(userID, size)
+}
+
+// checkTargetFolder 校验文件可以移动到目标文件夹：目标可上传，且不跨越个人空间与工作区的边界
+func checkTargetFolder(userID uint, workspaceID, targetFolderID string) error {
+	targetWorkspaceID := ""
+	if targetFolderID != "" {
+		target, err := workspace.ResolveFolder(userID, targetFolderID, workspace.ActionUpload)
+		if err != nil {
+			if errors.Is(err, errors.CodeFolderNotFound) {
+				return errors.New(errors.CodeFolderNotFound, "目标文件夹不存在")
+			}
+			return err
+		}
+		targetWorkspaceID = target.WorkspaceID
+	}
+	if targetWorkspaceID != workspaceID {
+		return errors.New(errors.CodeInvalidParameter, "不能在工作区与其他空间之间移动文件")
+	}
+	return nil
+}
+
+// fileFolderScope 返回文件夹内文件的查询范围：工作区文件夹按工作区过滤，根目录与个人文件夹按用户过滤
+func fileFolderScope(userID uint, folderID string, action workspace.Action) (func(*gorm.DB) *gorm.DB, error) {
+	if folderID == "" {
+		return workspace.PersonalScope(userID), nil
+	}
+	folder, err := workspace.ResolveFolder(userID, folderID, action)
+	if err != nil {
+		return nil, err
+	}
+	return workspace.FolderScope(folder), nil
+}
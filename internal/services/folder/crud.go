@@ -3,29 +3,29 @@ package folder
 import (
 	"pixelpunk/internal/models"
 	"pixelpunk/internal/services/stats"
+	"pixelpunk/internal/services/workspace"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 	file "pixelpunk/pkg/storage"
-
-	"gorm.io/gorm"
 )
 
 func CreateFolder(userID uint, name, parentID, permission, description string) (*FolderResponse, error) {
 	if !file.IsValidFolderName(name) {
 		return nil, errors.New(errors.CodeInvalidParameter, "文件夹名称无效：不能为空或包含 / \\ : * ? \" < > | 等特殊字符")
 	}
+	scope := workspace.PersonalScope(userID)
+	workspaceID := ""
 	if parentID != "" {
-		var parentFolder models.Folder
-		if err := database.DB.Where("id = ? AND user_id = ?", parentID, userID).First(&parentFolder).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return nil, errors.New(errors.CodeFolderNotFound, "父文件夹不存在")
-			}
-			return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询父文件夹失败")
+		parentFolder, err := workspace.ResolveFolder(userID, parentID, workspace.ActionUpload)
+		if err != nil {
+			return nil, err
 		}
+		scope = workspace.FolderScope(parentFolder)
+		workspaceID = parentFolder.WorkspaceID
 	}
 	var count int64
-	if err := database.DB.Model(&models.Folder{}).
-		Where("user_id = ? AND parent_id = ? AND name = ?", userID, parentID, name).
+	if err := database.DB.Model(&models.Folder{}).Scopes(scope).
+		Where("parent_id = ? AND name = ?", parentID, name).
 		Count(&count).Error; err != nil {
 		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹失败")
 	}
@@ -33,22 +33,20 @@ func CreateFolder(userID uint, name, parentID, permission, description string) (
 		return nil, errors.New(errors.CodeFolderNameDuplicate, "同级目录下已存在同名文件夹")
 	}
 
-	folder := models.Folder{ID: file.GenerateFolderID(), UserID: userID, ParentID: parentID, Name: name, Permission: permission, Description: description}
+	folder := models.Folder{ID: file.GenerateFolderID(), UserID: userID, ParentID: parentID, Name: name, Permission: permission, Description: description, WorkspaceID: workspaceID}
 	if err := database.DB.Create(&folder).Error; err != nil {
 		return nil, errors.Wrap(err, errors.CodeFolderCreateFailed, "创建文件夹失败")
 	}
 	stats.GetStatsAdapter().RecordFolderCreated()
+	workspace.RecordActivity(workspaceID, userID, workspace.ActivityFolderCreate, "folder", folder.ID, folder.Name, 0)
 
 	return toResponse(&folder), nil
 }
 
 func UpdateFolder(userID uint, folderID, name, parentID, permission, description string) (*FolderResponse, error) {
-	var folder models.Folder
-	if err := database.DB.Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New(errors.CodeFolderNotFound, "文件夹不存在")
-		}
-		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹失败")
+	folder, err := workspace.ResolveFolder(userID, folderID, workspace.ActionEdit)
+	if err != nil {
+		return nil, err
 	}
 	if name != "" && name != folder.Name {
 		if !file.IsValidFolderName(name) {
@@ -59,6 +57,9 @@ func UpdateFolder(userID uint, folderID, name, parentID, permission, description
 		if folderID == parentID {
 			return nil, errors.New(errors.CodeInvalidParameter, "不能将文件夹移动到自己下面")
 		}
+		if workspace.IsWorkspaceRoot(folder) {
+			return nil, errors.New(errors.CodeInvalidParameter, "工作区根文件夹不能移动")
+		}
 		var subFolders []models.Folder
 		if err := database.DB.Where("parent_id = ?", folderID).Find(&subFolders).Error; err != nil {
 			return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询子文件夹失败")
@@ -82,18 +83,18 @@ func UpdateFolder(userID uint, folderID, name, parentID, permission, description
 		if isSubFolder(parentID) {
 			return nil, errors.New(errors.CodeInvalidParameter, "不能将文件夹移动到自己的子文件夹下")
 		}
-		var parentFolder models.Folder
-		if err := database.DB.Where("id = ? AND user_id = ?", parentID, userID).First(&parentFolder).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return nil, errors.New(errors.CodeFolderNotFound, "父文件夹不存在")
-			}
-			return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询父文件夹失败")
+		parentFolder, err := workspace.ResolveFolder(userID, parentID, workspace.ActionEdit)
+		if err != nil {
+			return nil, err
+		}
+		if parentFolder.WorkspaceID != folder.WorkspaceID {
+			return nil, errors.New(errors.CodeInvalidParameter, "不能在工作区与其他空间之间移动文件夹")
 		}
 	}
 	if name != "" && name != folder.Name {
 		var count int64
-		if err := database.DB.Model(&models.Folder{}).
-			Where("user_id = ? AND parent_id = ? AND name = ? AND id != ?", userID, folder.ParentID, name, folderID).
+		if err := database.DB.Model(&models.Folder{}).Scopes(workspace.FolderScope(folder)).
+			Where("parent_id = ? AND name = ? AND id != ?", folder.ParentID, name, folderID).
 			Count(&count).Error; err != nil {
 			return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹失败")
 		}
@@ -113,19 +114,20 @@ func UpdateFolder(userID uint, folderID, name, parentID, permission, description
 	if description != "" {
 		folder.Description = description
 	}
-	if err := database.DB.Save(&folder).Error; err != nil {
+	if err := database.DB.Save(folder).Error; err != nil {
 		return nil, errors.Wrap(err, errors.CodeFolderUpdateFailed, "更新文件夹失败")
 	}
-	return toResponse(&folder), nil
+	workspace.RecordActivity(folder.WorkspaceID, userID, workspace.ActivityFolderUpdate, "folder", folder.ID, folder.Name, 0)
+	return toResponse(folder), nil
 }
 
 func DeleteFolder(userID uint, folderID string) error {
-	var folder models.Folder
-	if err := database.DB.Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.New(errors.CodeFolderNotFound, "文件夹不存在")
-		}
-		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹失败")
+	folder, err := workspace.ResolveFolder(userID, folderID, workspace.ActionEdit)
+	if err != nil {
+		return err
+	}
+	if workspace.IsWorkspaceRoot(folder) {
+		return errors.New(errors.CodeInvalidParameter, "工作区根文件夹随工作区删除")
 	}
 	var imageCount int64
 	if err := database.DB.Model(&models.File{}).Where("folder_id = ?", folderID).Count(&imageCount).Error; err != nil {
@@ -141,39 +143,34 @@ func DeleteFolder(userID uint, folderID string) error {
 	if subFolderCount > 0 {
 		return errors.New(errors.CodeFolderNotEmpty, "请先删除子文件夹")
 	}
-	if err := database.DB.Delete(&folder).Error; err != nil {
+	if err := database.DB.Delete(folder).Error; err != nil {
 		return errors.Wrap(err, errors.CodeFolderDeleteFailed, "删除文件夹失败")
 	}
+	workspace.RecordActivity(folder.WorkspaceID, userID, workspace.ActivityFolderDelete, "folder", folder.ID, folder.Name, 0)
 	return nil
 }
 
 func GetFolderDetail(userID uint, folderID string) (*FolderResponse, error) {
-	var folder models.Folder
-	if err := database.DB.Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New(errors.CodeFolderNotFound, "文件夹不存在")
-		}
-		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹失败")
+	folder, err := workspace.ResolveFolder(userID, folderID, workspace.ActionView)
+	if err != nil {
+		return nil, err
 	}
-	return toResponse(&folder), nil
+	return toResponse(folder), nil
 }
 
 /* ToggleFolderAccessLevel 切换文件夹权限状态（private/public） */
 func ToggleFolderAccessLevel(userID uint, folderID string) (*FolderResponse, error) {
-	var folder models.Folder
-	if err := database.DB.Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New(errors.CodeFolderNotFound, "文件夹不存在或无权限访问")
-		}
-		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹失败")
+	folder, err := workspace.ResolveFolder(userID, folderID, workspace.ActionEdit)
+	if err != nil {
+		return nil, err
 	}
 	newPermission := "public"
 	if folder.Permission == "public" {
 		newPermission = "private"
 	}
-	if err := database.DB.Model(&folder).Update("permission", newPermission).Error; err != nil {
+	if err := database.DB.Model(folder).Update("permission", newPermission).Error; err != nil {
 		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "更新文件夹权限失败")
 	}
 	folder.Permission = newPermission
-	return toResponse(&folder), nil
+	return toResponse(folder), nil
 }
@@ -9,13 +9,12 @@ import (
 )
 
 func ListFolders(userID uint, parentID string) ([]*FolderResponse, error) {
-	var folders []models.Folder
-	query := database.DB.Where("user_id = ?", userID)
-	if parentID != "" {
-		query = query.Where("parent_id = ?", parentID)
-	} else {
-		query = query.Where("parent_id = '' OR parent_id IS NULL")
+	scope, err := resolveContentScope(userID, parentID)
+	if err != nil {
+		return nil, err
 	}
+	var folders []models.Folder
+	query := database.DB.Scopes(scope("parent_id"))
 	if err := query.Order("sort_order ASC, name ASC").Find(&folders).Error; err != nil {
 		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹列表失败")
 	}
@@ -27,13 +26,12 @@ func ListFolders(userID uint, parentID string) ([]*FolderResponse, error) {
 }
 
 func ListFolderContents(userID uint, parentID string) (*FolderContentResponse, error) {
-	var folders []models.Folder
-	folderQuery := database.DB.Where("user_id = ?", userID)
-	if parentID != "" {
-		folderQuery = folderQuery.Where("parent_id = ?", parentID)
-	} else {
-		folderQuery = folderQuery.Where("parent_id = '' OR parent_id IS NULL")
+	scope, err := resolveContentScope(userID, parentID)
+	if err != nil {
+		return nil, err
 	}
+	var folders []models.Folder
+	folderQuery := database.DB.Scopes(scope("parent_id"))
 	if err := folderQuery.Order("sort_order ASC, name ASC").Find(&folders).Error; err != nil {
 		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹列表失败")
 	}
@@ -43,12 +41,7 @@ func ListFolderContents(userID uint, parentID string) (*FolderContentResponse, e
 	}
 
 	var images []models.File
-	imageQuery := database.DB.Where("user_id = ?", userID).Where("status <> ?", "pending_deletion")
-	if parentID != "" {
-		imageQuery = imageQuery.Where("folder_id = ?", parentID)
-	} else {
-		imageQuery = imageQuery.Where("folder_id = '' OR folder_id IS NULL")
-	}
+	imageQuery := database.DB.Where("status <> ?", "pending_deletion").Scopes(scope("folder_id"))
 	if err := imageQuery.Order("created_at DESC").Find(&images).Error; err != nil {
 		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件列表失败")
 	}
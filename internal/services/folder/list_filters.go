@@ -16,13 +16,12 @@ func ListFolderContentsWithFilters(userID uint, folderID, keyword string, page,
 		sortOrder = "desc"
 	}
 
-	var folders []models.Folder
-	folderQuery := database.DB.Where("user_id = ?", userID)
-	if folderID != "" {
-		folderQuery = folderQuery.Where("parent_id = ?", folderID)
-	} else {
-		folderQuery = folderQuery.Where("parent_id = '' OR parent_id IS NULL")
+	scope, err := resolveContentScope(userID, folderID)
+	if err != nil {
+		return nil, err
 	}
+	var folders []models.Folder
+	folderQuery := database.DB.Scopes(scope("parent_id"))
 	if keyword != "" {
 		folderQuery = folderQuery.Where("name LIKE ?", "%"+keyword+"%")
 	}
@@ -47,12 +46,7 @@ func ListFolderContentsWithFilters(userID uint, folderID, keyword string, page,
 	}
 
 	var images []models.File
-	imageQuery := database.DB.Where("user_id = ?", userID).Where("status <> ?", "pending_deletion")
-	if folderID != "" {
-		imageQuery = imageQuery.Where("folder_id = ?", folderID)
-	} else {
-		imageQuery = imageQuery.Where("folder_id = '' OR folder_id IS NULL")
-	}
+	imageQuery := database.DB.Where("status <> ?", "pending_deletion").Scopes(scope("folder_id"))
 	if accessLevel != "" {
 		imageQuery = imageQuery.Where("access_level = ?", accessLevel)
 	}
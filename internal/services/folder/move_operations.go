@@ -2,11 +2,10 @@ package folder
 
 import (
 	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/workspace"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 	"strings"
-
-	"gorm.io/gorm"
 )
 
 const (
@@ -22,25 +21,17 @@ func MoveFolders(userID uint, folderIDs []string, newParentID string) error {
 		return errors.New(errors.CodeInvalidParameter, "单次最多移动100个文件夹")
 	}
 
-	var count int64
-	if err := database.DB.Model(&models.Folder{}).
-		Where("id IN ? AND user_id = ?", folderIDs, userID).
-		Count(&count).Error; err != nil {
-		return errors.Wrap(err, errors.CodeDBQueryFailed, "验证文件夹所属失败")
-	}
-
-	if count != int64(len(folderIDs)) {
-		return errors.New(errors.CodeInvalidParameter, "部分文件夹不存在或无权限")
+	folders := make([]*models.Folder, 0, len(folderIDs))
+	for _, folderID := range folderIDs {
+		f, err := workspace.ResolveFolder(userID, folderID, workspace.ActionEdit)
+		if err != nil {
+			return errors.New(errors.CodeInvalidParameter, "部分文件夹不存在或无权限")
+		}
+		folders = append(folders, f)
 	}
 
-	if newParentID != "" {
-		var parentFolder models.Folder
-		if err := database.DB.Where("id = ? AND user_id = ?", newParentID, userID).First(&parentFolder).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return errors.New(errors.CodeFolderNotFound, "目标父文件夹不存在")
-			}
-			return errors.Wrap(err, errors.CodeDBQueryFailed, "查询目标父文件夹失败")
-		}
+	if err := checkMoveTarget(userID, folders, newParentID); err != nil {
+		return err
 	}
 
 	for _, folderID := range folderIDs {
@@ -48,13 +39,13 @@ func MoveFolders(userID uint, folderIDs []string, newParentID string) error {
 			return errors.New(errors.CodeInvalidParameter, "不能将文件夹移动到自己")
 		}
 
-		if newParentID != "" && isSubFolder(userID, folderID, newParentID) {
+		if newParentID != "" && isSubFolder(folderID, newParentID) {
 			return errors.New(errors.CodeInvalidParameter, "不能将文件夹移动到自己的子文件夹")
 		}
 	}
 
 	if err := database.DB.Model(&models.Folder{}).
-		Where("id IN ? AND user_id = ?", folderIDs, userID).
+		Where("id IN ?", folderIDs).
 		Update("parent_id", newParentID).Error; err != nil {
 		return errors.Wrap(err, errors.CodeDBUpdateFailed, "批量移动文件夹失败")
 	}
@@ -68,54 +59,72 @@ func MoveFolder(userID uint, folderID, newParentID string) error {
 		return errors.New(errors.CodeInvalidParameter, "文件夹ID不能为空")
 	}
 
-	var folder models.Folder
-	if err := database.DB.Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.New(errors.CodeFolderNotFound, "源文件夹不存在")
-		}
-		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询源文件夹失败")
+	folder, err := workspace.ResolveFolder(userID, folderID, workspace.ActionEdit)
+	if err != nil {
+		return err
 	}
 
 	if folderID == newParentID {
 		return errors.New(errors.CodeInvalidParameter, "不能将文件夹移动到自己")
 	}
 
-	if newParentID != "" {
-		var parentFolder models.Folder
-		if err := database.DB.Where("id = ? AND user_id = ?", newParentID, userID).First(&parentFolder).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return errors.New(errors.CodeFolderNotFound, "目标父文件夹不存在")
-			}
-			return errors.Wrap(err, errors.CodeDBQueryFailed, "查询目标父文件夹失败")
-		}
-
-		if isSubFolder(userID, folderID, newParentID) {
-			return errors.New(errors.CodeInvalidParameter, "不能将文件夹移动到自己的子文件夹")
-		}
+	if err := checkMoveTarget(userID, []*models.Folder{folder}, newParentID); err != nil {
+		return err
+	}
 
+	if newParentID != "" && isSubFolder(folderID, newParentID) {
+		return errors.New(errors.CodeInvalidParameter, "不能将文件夹移动到自己的子文件夹")
 	}
 
-	if err := database.DB.Model(&folder).Update("parent_id", newParentID).Error; err != nil {
+	if err := database.DB.Model(folder).Update("parent_id", newParentID).Error; err != nil {
 		return errors.Wrap(err, errors.CodeDBUpdateFailed, "移动文件夹失败")
 	}
 
 	return nil
 }
 
+// checkMoveTarget 校验目标父文件夹可写，且移动不跨越个人空间与工作区的边界
+func checkMoveTarget(userID uint, folders []*models.Folder, newParentID string) error {
+	targetWorkspaceID := ""
+	if newParentID != "" {
+		parentFolder, err := workspace.ResolveFolder(userID, newParentID, workspace.ActionEdit)
+		if err != nil {
+			if errors.Is(err, errors.CodeFolderNotFound) {
+				return errors.New(errors.CodeFolderNotFound, "目标父文件夹不存在")
+			}
+			return err
+		}
+		targetWorkspaceID = parentFolder.WorkspaceID
+	}
+	for _, f := range folders {
+		if workspace.IsWorkspaceRoot(f) {
+			return errors.New(errors.CodeInvalidParameter, "工作区根文件夹不能移动")
+		}
+		if f.WorkspaceID != targetWorkspaceID {
+			return errors.New(errors.CodeInvalidParameter, "不能在工作区与其他空间之间移动文件夹")
+		}
+	}
+	return nil
+}
+
 func ReorderFolders(userID uint, parentID string, folderIDs []string) error {
 	if len(folderIDs) == 0 {
 		return errors.New(errors.CodeInvalidParameter, "文件夹ID列表不能为空")
 	}
 
-	var count int64
-	query := database.DB.Model(&models.Folder{}).Where("user_id = ? AND id IN ?", userID, folderIDs)
-
-	if parentID == "" {
-		query = query.Where("parent_id = '' OR parent_id IS NULL")
-	} else {
-		query = query.Where("parent_id = ?", parentID)
+	scope, err := resolveContentScope(userID, parentID)
+	if err != nil {
+		return err
+	}
+	if parentID != "" {
+		if _, err := workspace.ResolveFolder(userID, parentID, workspace.ActionEdit); err != nil {
+			return err
+		}
 	}
 
+	var count int64
+	query := database.DB.Model(&models.Folder{}).Where("id IN ?", folderIDs).Scopes(scope("parent_id"))
+
 	if err := query.Count(&count).Error; err != nil {
 		return errors.Wrap(err, errors.CodeDBQueryFailed, "验证文件夹所属失败")
 	}
@@ -142,9 +151,9 @@ func ReorderFolders(userID uint, parentID string, folderIDs []string) error {
 	return database.DB.Exec(sqlBuilder.String(), args...).Error
 }
 
-func isSubFolder(userID uint, parentID, childID string) bool {
+func isSubFolder(parentID, childID string) bool {
 	var childFolders []models.Folder
-	if err := database.DB.Where("parent_id = ? AND user_id = ?", parentID, userID).Find(&childFolders).Error; err != nil {
+	if err := database.DB.Where("parent_id = ?", parentID).Find(&childFolders).Error; err != nil {
 		return false
 	}
 
@@ -152,7 +161,7 @@ func isSubFolder(userID uint, parentID, childID string) bool {
 		if folder.ID == childID {
 			return true
 		}
-		if isSubFolder(userID, folder.ID, childID) {
+		if isSubFolder(folder.ID, childID) {
 			return true
 		}
 	}
@@ -182,30 +191,20 @@ func ValidateFolderMove(userID uint, folderID, newParentID string) error {
 		return errors.New(errors.CodeInvalidParameter, "不能将文件夹移动到自己")
 	}
 
-	var count int64
-	if err := database.DB.Model(&models.Folder{}).
-		Where("id = ? AND user_id = ?", folderID, userID).
-		Count(&count).Error; err != nil {
-		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询源文件夹失败")
-	}
-	if count == 0 {
-		return errors.New(errors.CodeFolderNotFound, "源文件夹不存在")
-	}
-
-	if newParentID != "" {
-		if err := database.DB.Model(&models.Folder{}).
-			Where("id = ? AND user_id = ?", newParentID, userID).
-			Count(&count).Error; err != nil {
-			return errors.Wrap(err, errors.CodeDBQueryFailed, "查询目标文件夹失败")
-		}
-		if count == 0 {
-			return errors.New(errors.CodeFolderNotFound, "目标父文件夹不存在")
+	folder, err := workspace.ResolveFolder(userID, folderID, workspace.ActionEdit)
+	if err != nil {
+		if errors.Is(err, errors.CodeFolderNotFound) {
+			return errors.New(errors.CodeFolderNotFound, "源文件夹不存在")
 		}
+		return err
+	}
 
-		if isSubFolder(userID, folderID, newParentID) {
-			return errors.New(errors.CodeInvalidParameter, "不能将文件夹移动到自己的子文件夹")
-		}
+	if err := checkMoveTarget(userID, []*models.Folder{folder}, newParentID); err != nil {
+		return err
+	}
 
+	if newParentID != "" && isSubFolder(folderID, newParentID) {
+		return errors.New(errors.CodeInvalidParameter, "不能将文件夹移动到自己的子文件夹")
 	}
 
 	return nil
@@ -7,6 +7,7 @@ import (
 
 	"pixelpunk/internal/controllers/folder/dto"
 	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/workspace"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 	file "pixelpunk/pkg/storage"
@@ -35,19 +36,32 @@ func GetFolderPath(baseDir string, userID uint, folderID string) (string, error)
 }
 
 func CreateFolderByPath(userID uint, filePath string) (string, error) {
+	return CreateFolderByPathUnder(userID, "", filePath)
+}
+
+/* CreateFolderByPathUnder 在指定父文件夹下按路径逐级查找或创建文件夹，返回最末级文件夹ID */
+func CreateFolderByPathUnder(userID uint, parentID, filePath string) (string, error) {
 	filePath = strings.Trim(filePath, "/")
 	if filePath == "" {
-		return "", nil
+		return parentID, nil
+	}
+	scope := workspace.PersonalScope(userID)
+	if parentID != "" {
+		parent, err := workspace.ResolveFolder(userID, parentID, workspace.ActionUpload)
+		if err != nil {
+			return "", err
+		}
+		scope = workspace.FolderScope(parent)
 	}
 	parts := strings.Split(filePath, "/")
-	var currentParentID string
+	currentParentID := parentID
 	for _, folderName := range parts {
 		folderName = strings.TrimSpace(folderName)
 		if folderName == "" {
 			continue
 		}
 		var existingFolder models.Folder
-		result := database.DB.Where("user_id = ? AND parent_id = ? AND name = ?", userID, currentParentID, folderName).First(&existingFolder)
+		result := database.DB.Scopes(scope).Where("parent_id = ? AND name = ?", currentParentID, folderName).First(&existingFolder)
 		if result.Error == nil {
 			currentParentID = existingFolder.ID
 			continue
@@ -65,7 +79,7 @@ func CreateFolderByPath(userID uint, filePath string) (string, error) {
 }
 
 func GetFolderPathChain(folderID string, userID uint) (*dto.FolderPathChainResponseDTO, error) {
-	folderModel, err := models.GetFolderByIDAndUserID(database.DB, folderID, userID)
+	folderModel, err := workspace.ResolveFolder(userID, folderID, workspace.ActionView)
 	if err != nil {
 		return nil, errors.New(errors.CodeFolderNotFound, "文件夹不存在或无权访问")
 	}
@@ -77,11 +91,11 @@ func GetFolderPathChain(folderID string, userID uint) (*dto.FolderPathChainRespo
 		if currentFolder.ParentID == "" {
 			break
 		}
-		parentFolder, err := models.GetFolderByIDAndUserID(database.DB, currentFolder.ParentID, userID)
-		if err != nil {
+		var parentFolder models.Folder
+		if err := database.DB.Scopes(workspace.FolderScope(folderModel)).Where("id = ?", currentFolder.ParentID).First(&parentFolder).Error; err != nil {
 			break
 		}
-		currentFolder = parentFolder
+		currentFolder = &parentFolder
 		level++
 	}
 	var pathNames []string
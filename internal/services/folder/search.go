@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/workspace"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
@@ -16,7 +17,7 @@ func SearchFolders(userID uint, keyword string) ([]FolderResponse, error) {
 	if keyword == "" {
 		return []FolderResponse{}, nil
 	}
-	query := database.DB.Where("user_id = ?", userID).Where("LOWER(name) LIKE ?", "%"+strings.ToLower(keyword)+"%")
+	query := database.DB.Scopes(workspace.PersonalScope(userID)).Where("LOWER(name) LIKE ?", "%"+strings.ToLower(keyword)+"%")
 	if err := query.Order("name ASC").Find(&folders).Error; err != nil {
 		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "搜索文件夹失败")
 	}
@@ -2,13 +2,14 @@ package folder
 
 import (
 	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/workspace"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 )
 
 func GetFolderTree(userID uint) ([]*TreeNodeResponse, error) {
 	var folders []models.Folder
-	if err := database.DB.Where("user_id = ?", userID).Order("sort_order ASC, name ASC").Find(&folders).Error; err != nil {
+	if err := database.DB.Scopes(workspace.PersonalScope(userID)).Order("sort_order ASC, name ASC").Find(&folders).Error; err != nil {
 		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹列表失败")
 	}
 	return buildFolderTree(folders), nil
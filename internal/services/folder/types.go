@@ -17,6 +17,8 @@ type FolderResponse struct {
 	Level       int             `json:"level"`
 	CreatedAt   common.JSONTime `json:"created_at"`
 	UpdatedAt   common.JSONTime `json:"updated_at"`
+
+	WorkspaceID string `json:"workspace_id,omitempty"`
 }
 
 /* PaginationInfo 分页信息（仍保留以兼容调用方） */
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 
 	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/workspace"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 
@@ -21,7 +22,7 @@ func toResponse(folder *models.Folder) *FolderResponse {
 	database.DB.Model(&models.File{}).Where("folder_id = ?", folder.ID).Count(&fileCount)
 	var childCount int64
 	database.DB.Model(&models.Folder{}).Where("parent_id = ?", folder.ID).Count(&childCount)
-	level := calculateFolderLevel(folder.UserID, folder.ID)
+	level := calculateFolderLevel(folder.ID)
 	return &FolderResponse{
 		ID:          folder.ID,
 		Name:        folder.Name,
@@ -34,19 +35,41 @@ func toResponse(folder *models.Folder) *FolderResponse {
 		Level:       level,
 		CreatedAt:   folder.CreatedAt,
 		UpdatedAt:   folder.UpdatedAt,
+		WorkspaceID: folder.WorkspaceID,
 	}
 }
 
-func calculateFolderLevel(userID uint, folderID string) int {
+func calculateFolderLevel(folderID string) int {
 	var folder models.Folder
-	err := database.DB.Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error
+	err := database.DB.Select("id, parent_id").Where("id = ?", folderID).First(&folder).Error
 	if err != nil {
 		return 1
 	}
 	if folder.ParentID == "" {
 		return 1
 	}
-	return calculateFolderLevel(userID, folder.ParentID) + 1
+	return calculateFolderLevel(folder.ParentID) + 1
+}
+
+// resolveContentScope 返回列出某文件夹下内容的查询范围：根目录为个人空间，其他文件夹校验浏览权限后按其归属过滤
+func resolveContentScope(userID uint, parentID string) (func(column string) func(*gorm.DB) *gorm.DB, error) {
+	ownerScope := workspace.PersonalScope(userID)
+	if parentID != "" {
+		parent, err := workspace.ResolveFolder(userID, parentID, workspace.ActionView)
+		if err != nil {
+			return nil, err
+		}
+		ownerScope = workspace.FolderScope(parent)
+	}
+	return func(column string) func(*gorm.DB) *gorm.DB {
+		return func(db *gorm.DB) *gorm.DB {
+			db = db.Scopes(ownerScope)
+			if parentID == "" {
+				return db.Where(column + " = '' OR " + column + " IS NULL")
+			}
+			return db.Where(column+" = ?", parentID)
+		}
+	}, nil
 }
 
 func getFileAIInfo(fileID string) (*FolderFileAIInfo, error) {
@@ -461,6 +461,20 @@ func (s *TemplateService) InitDefaultTemplates() error {
 			DefaultActionStyle: "secondary",
 			ActionURLTemplate:  "/admin/shares",
 		},
+		{
+			Type:               common.MessageTypeShareFileReceived,
+			Title:              "收到新的上传文件",
+			Content:            "{{.visitor_name}} 通过文件收集「{{.share_name}}」上传了文件「{{.file_name}}」，已保存到目标文件夹。",
+			Description:        "文件收集分享收到上传通知",
+			IsEnabled:          true,
+			SendEmail:          false,
+			ShowToast:          true,
+			ToastType:          "info",
+			DefaultActionType:  common.ActionTypeView,
+			DefaultActionText:  "查看分享",
+			DefaultActionStyle: "secondary",
+			ActionURLTemplate:  "/admin/shares",
+		},
 	}
 
 	for _, template := range templates {
@@ -9,8 +9,7 @@ import (
 	"os"
 	"pixelpunk/internal/models"
 	settingService "pixelpunk/internal/services/setting"
-	"pixelpunk/pkg/database"
-	"pixelpunk/pkg/utils"
+	"strconv"
 	"time"
 )
 
@@ -259,45 +258,13 @@ func (s *GithubOAuthService) GetUserEmails(accessToken string) ([]GithubEmail, e
 }
 
 func (s *GithubOAuthService) FindOrCreateUser(githubUser *GithubUserInfo) (*models.User, error) {
-	db := database.GetDB()
-	if db == nil {
-		return nil, fmt.Errorf("数据库连接失败")
-	}
-
-	var user models.User
-	err := db.Where("github_id = ?", githubUser.ID).First(&user).Error
-	if err == nil {
-		return &user, nil
-	}
-
-	username := githubUser.Login
-	existingUser := models.User{}
-	if db.Where("username = ?", username).First(&existingUser).Error == nil {
-		for i := 1; i < 100; i++ {
-			newUsername := fmt.Sprintf("%s%d", username, i)
-			if db.Where("username = ?", newUsername).First(&existingUser).Error != nil {
-				username = newUsername
-				break
-			}
-		}
-	}
-
-	githubID := githubUser.ID
-	newUser := models.User{
-		Username:  username,
-		Email:     githubUser.Email,
-		Avatar:    githubUser.AvatarURL,
-		Bio:       githubUser.Bio,
-		Website:   githubUser.Blog,
-		GithubID:  &githubID,
-		PathAlias: utils.GenerateRandomString(16),
-		Status:    1,
-		Role:      3,
-	}
-
-	if err := db.Create(&newUser).Error; err != nil {
-		return nil, fmt.Errorf("创建用户失败: %w", err)
-	}
-
-	return &newUser, nil
+	return FindOrCreateIdentityUser(&ExternalProfile{
+		Provider: "github",
+		Subject:  strconv.FormatInt(githubUser.ID, 10),
+		Username: githubUser.Login,
+		Email:    githubUser.Email,
+		Avatar:   githubUser.AvatarURL,
+		Bio:      githubUser.Bio,
+		Website:  githubUser.Blog,
+	})
 }
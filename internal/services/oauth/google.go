@@ -7,8 +7,6 @@ import (
 	"net/http"
 	"net/url"
 	"pixelpunk/internal/models"
-	"pixelpunk/pkg/database"
-	"pixelpunk/pkg/utils"
 	"strings"
 )
 
@@ -133,47 +131,17 @@ func (s *GoogleOAuthService) GetUserInfo(accessToken string) (*GoogleUserInfo, e
 }
 
 func (s *GoogleOAuthService) FindOrCreateUser(googleUser *GoogleUserInfo) (*models.User, error) {
-	db := database.GetDB()
-	if db == nil {
-		return nil, fmt.Errorf("数据库连接失败")
-	}
-
-	var user models.User
-	err := db.Where("google_id = ?", googleUser.ID).First(&user).Error
-	if err == nil {
-		return &user, nil
-	}
-
 	username := googleUser.Email
 	if googleUser.Name != "" {
 		username = googleUser.Name
 	}
 
-	existingUser := models.User{}
-	if db.Where("username = ?", username).First(&existingUser).Error == nil {
-		for i := 1; i < 100; i++ {
-			newUsername := fmt.Sprintf("%s%d", username, i)
-			if db.Where("username = ?", newUsername).First(&existingUser).Error != nil {
-				username = newUsername
-				break
-			}
-		}
-	}
-
-	googleID := googleUser.ID
-	newUser := models.User{
-		Username:  username,
-		Email:     googleUser.Email,
-		Avatar:    googleUser.Picture,
-		GoogleID:  &googleID,
-		PathAlias: utils.GenerateRandomString(16),
-		Status:    1,
-		Role:      3,
-	}
-
-	if err := db.Create(&newUser).Error; err != nil {
-		return nil, fmt.Errorf("创建用户失败: %w", err)
-	}
-
-	return &newUser, nil
+	return FindOrCreateIdentityUser(&ExternalProfile{
+		Provider:      "google",
+		Subject:       googleUser.ID,
+		Username:      username,
+		Email:         googleUser.Email,
+		EmailVerified: googleUser.VerifiedEmail,
+		Avatar:        googleUser.Picture,
+	})
 }
@@ -0,0 +1,165 @@
+package oauth
+
+import (
+	"fmt"
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExternalProfile 第三方提供方返回的用户资料
+type ExternalProfile struct {
+	Provider      string
+	Subject       string
+	Username      string
+	Email         string
+	EmailVerified bool
+	Avatar        string
+	Bio           string
+	Website       string
+
+	LinkByEmail bool // 首次登录时按已验证邮箱关联已有账号
+	Role        int  // 新建用户的角色，0 表示普通用户
+	SyncRole    bool // 每次登录同步角色（组映射），超级管理员不受影响
+}
+
+// FindOrCreateIdentityUser 通过 user_identity 查找已关联的用户，首次登录时关联已有账号或创建新用户
+func FindOrCreateIdentityUser(profile *ExternalProfile) (*models.User, error) {
+	db := database.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("数据库连接失败")
+	}
+	if profile.Provider == "" || profile.Subject == "" {
+		return nil, fmt.Errorf("未获取到用户唯一标识")
+	}
+
+	now := common.JSONTime(time.Now())
+
+	var identity models.UserIdentity
+	err := db.Where("provider = ? AND subject = ?", profile.Provider, profile.Subject).First(&identity).Error
+	if err == nil {
+		var user models.User
+		if err := db.First(&user, identity.UserID).Error; err != nil {
+			return nil, fmt.Errorf("关联的用户不存在")
+		}
+		db.Model(&identity).Updates(map[string]interface{}{
+			"email":         profile.Email,
+			"username":      profile.Username,
+			"last_login_at": &now,
+		})
+		syncIdentityRole(&user, profile)
+		return &user, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("查询身份关联失败: %w", err)
+	}
+
+	var user models.User
+	linked := false
+	if profile.LinkByEmail && profile.EmailVerified && profile.Email != "" {
+		linked = db.Where("email = ?", profile.Email).First(&user).Error == nil
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if !linked {
+			newUser, err := newIdentityUser(tx, profile)
+			if err != nil {
+				return err
+			}
+			user = *newUser
+		}
+		return tx.Create(&models.UserIdentity{
+			UserID:      user.ID,
+			Provider:    profile.Provider,
+			Subject:     profile.Subject,
+			Email:       profile.Email,
+			Username:    profile.Username,
+			LastLoginAt: &now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if linked {
+		syncIdentityRole(&user, profile)
+	}
+	return &user, nil
+}
+
+func newIdentityUser(tx *gorm.DB, profile *ExternalProfile) (*models.User, error) {
+	email := profile.Email
+	if email == "" {
+		email = fmt.Sprintf("%s_%s@placeholder.local", profile.Provider, utils.GenerateRandomString(12))
+	} else {
+		var count int64
+		tx.Model(&models.User{}).Where("email = ?", email).Count(&count)
+		if count > 0 {
+			return nil, fmt.Errorf("邮箱 %s 已被其他账号使用，请使用该账号登录后再关联", email)
+		}
+	}
+
+	role := profile.Role
+	if role == 0 {
+		role = common.UserRoleUser
+	}
+
+	newUser := models.User{
+		Username:  uniqueUsername(tx, identityUsername(profile)),
+		Email:     email,
+		Avatar:    profile.Avatar,
+		Bio:       profile.Bio,
+		Website:   profile.Website,
+		PathAlias: utils.GenerateRandomString(16),
+		Status:    common.UserStatusNormal,
+		Role:      role,
+	}
+	if err := tx.Create(&newUser).Error; err != nil {
+		return nil, fmt.Errorf("创建用户失败: %w", err)
+	}
+	return &newUser, nil
+}
+
+func identityUsername(profile *ExternalProfile) string {
+	username := strings.TrimSpace(profile.Username)
+	if username == "" && profile.Email != "" {
+		username = strings.Split(profile.Email, "@")[0]
+	}
+	if username == "" {
+		username = profile.Provider + "_user"
+	}
+	if len([]rune(username)) > 40 {
+		username = string([]rune(username)[:40])
+	}
+	return username
+}
+
+func uniqueUsername(tx *gorm.DB, username string) string {
+	existingUser := models.User{}
+	if tx.Where("username = ?", username).First(&existingUser).Error == nil {
+		for i := 1; i < 100; i++ {
+			newUsername := fmt.Sprintf("%s%d", username, i)
+			if tx.Where("username = ?", newUsername).First(&existingUser).Error != nil {
+				return newUsername
+			}
+		}
+		return fmt.Sprintf("%s_%s", username, utils.GenerateRandomString(6))
+	}
+	return username
+}
+
+// syncIdentityRole 按组映射同步角色，超级管理员不会被降级
+func syncIdentityRole(user *models.User, profile *ExternalProfile) {
+	if !profile.SyncRole || profile.Role == 0 || user.IsSuperAdmin() || user.Role == profile.Role {
+		return
+	}
+	if err := database.GetDB().Model(user).Updates(map[string]interface{}{"role": profile.Role, "admin_role_id": 0}).Error; err == nil {
+		user.Role = profile.Role
+		user.AdminRoleID = 0
+	}
+}
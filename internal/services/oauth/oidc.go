@@ -0,0 +1,415 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"pixelpunk/internal/controllers/setting/dto"
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/cache"
+	"pixelpunk/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	oidcStateTTL     = 10 * time.Minute
+	oidcDiscoveryTTL = time.Hour
+)
+
+// OIDCService 通用 OpenID Connect / OAuth2 登录（授权码 + PKCE）
+type OIDCService struct {
+	Config      *dto.OIDCProviderConfig
+	ProxyConfig *ProxyConfig
+}
+
+// OIDCDiscovery 发现文档中用到的字段
+type OIDCDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+type OIDCTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// oidcLoginState 发起授权时保存的一次性状态
+type oidcLoginState struct {
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+}
+
+func NewOIDCService(config *dto.OIDCProviderConfig, proxyConfig *ProxyConfig) *OIDCService {
+	return &OIDCService{
+		Config:      config,
+		ProxyConfig: proxyConfig,
+	}
+}
+
+// Discover 获取端点信息：优先使用手动配置的端点，缺失部分从发现文档补全
+func (s *OIDCService) Discover() (*OIDCDiscovery, error) {
+	result := &OIDCDiscovery{
+		Issuer:                s.Config.Issuer,
+		AuthorizationEndpoint: s.Config.AuthorizationEndpoint,
+		TokenEndpoint:         s.Config.TokenEndpoint,
+		UserinfoEndpoint:      s.Config.UserinfoEndpoint,
+	}
+	if result.AuthorizationEndpoint != "" && result.TokenEndpoint != "" {
+		return result, nil
+	}
+
+	discoveryURL := s.Config.DiscoveryURL
+	if discoveryURL == "" {
+		if s.Config.Issuer == "" {
+			return nil, fmt.Errorf("未配置 issuer 或发现文档地址")
+		}
+		discoveryURL = s.Config.Issuer + "/.well-known/openid-configuration"
+	}
+
+	cacheKey := "oauth:oidc:discovery:" + s.Config.Slug
+	var doc OIDCDiscovery
+	if cached, err := cache.GetCache().Get(cacheKey); err == nil && cached != "" && json.Unmarshal([]byte(cached), &doc) == nil {
+		return mergeDiscovery(result, &doc), nil
+	}
+
+	body, err := s.doRequest("GET", discoveryURL, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("获取发现文档失败: %w", err)
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("解析发现文档失败: %w", err)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" {
+		return nil, fmt.Errorf("发现文档缺少授权或令牌端点")
+	}
+	_ = cache.GetCache().Set(cacheKey, string(body), oidcDiscoveryTTL)
+
+	return mergeDiscovery(result, &doc), nil
+}
+
+func mergeDiscovery(configured, doc *OIDCDiscovery) *OIDCDiscovery {
+	if configured.Issuer == "" {
+		configured.Issuer = strings.TrimRight(doc.Issuer, "/")
+	}
+	if configured.AuthorizationEndpoint == "" {
+		configured.AuthorizationEndpoint = doc.AuthorizationEndpoint
+	}
+	if configured.TokenEndpoint == "" {
+		configured.TokenEndpoint = doc.TokenEndpoint
+	}
+	if configured.UserinfoEndpoint == "" {
+		configured.UserinfoEndpoint = doc.UserinfoEndpoint
+	}
+	return configured
+}
+
+// AuthorizationURL 生成授权地址，state 对应的 PKCE verifier 与 nonce 保存在缓存中
+func (s *OIDCService) AuthorizationURL() (string, string, error) {
+	endpoints, err := s.Discover()
+	if err != nil {
+		return "", "", err
+	}
+
+	verifier, err := randomURLSafe(32)
+	if err != nil {
+		return "", "", fmt.Errorf("生成 PKCE 参数失败: %w", err)
+	}
+	state := utils.GenerateRandomString(32)
+	loginState := oidcLoginState{
+		Provider:     s.Config.Slug,
+		CodeVerifier: verifier,
+		Nonce:        utils.GenerateRandomString(32),
+	}
+	data, _ := json.Marshal(loginState)
+	if err := cache.GetCache().Set(oidcStateKey(state), string(data), oidcStateTTL); err != nil {
+		return "", "", fmt.Errorf("保存登录状态失败: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", s.Config.ClientID)
+	params.Set("redirect_uri", s.Config.RedirectURI)
+	params.Set("scope", s.Config.Scope)
+	params.Set("state", state)
+	params.Set("nonce", loginState.Nonce)
+	params.Set("code_challenge", pkceChallenge(verifier))
+	params.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(endpoints.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return endpoints.AuthorizationEndpoint + sep + params.Encode(), state, nil
+}
+
+// Login 校验 state 后用授权码换取令牌，合并 id_token 与 userinfo 声明并映射为本地用户
+func (s *OIDCService) Login(code, state string) (*models.User, error) {
+	loginState, err := consumeOIDCState(state)
+	if err != nil {
+		return nil, err
+	}
+	if loginState.Provider != s.Config.Slug {
+		return nil, fmt.Errorf("登录状态与提供方不匹配")
+	}
+
+	endpoints, err := s.Discover()
+	if err != nil {
+		return nil, err
+	}
+
+	tokenResp, err := s.ExchangeCode(endpoints.TokenEndpoint, code, loginState.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("授权失败: %w", err)
+	}
+
+	claims := map[string]interface{}{}
+	if tokenResp.IDToken != "" {
+		idClaims, err := s.verifyIDTokenClaims(tokenResp.IDToken, endpoints.Issuer, loginState.Nonce)
+		if err != nil {
+			return nil, err
+		}
+		claims = idClaims
+	}
+
+	if endpoints.UserinfoEndpoint != "" {
+		userinfo, err := s.GetUserInfo(endpoints.UserinfoEndpoint, tokenResp.AccessToken)
+		if err != nil {
+			return nil, fmt.Errorf("获取用户信息失败: %w", err)
+		}
+		// userinfo 的 sub 必须与 id_token 一致
+		if sub, ok := claims["sub"].(string); ok && sub != "" {
+			if infoSub, _ := userinfo["sub"].(string); infoSub != "" && infoSub != sub {
+				return nil, fmt.Errorf("用户信息与身份令牌不一致")
+			}
+		}
+		for k, v := range userinfo {
+			claims[k] = v
+		}
+	} else if tokenResp.IDToken == "" {
+		return nil, fmt.Errorf("提供方未返回 id_token 且未配置 userinfo 端点")
+	}
+
+	return FindOrCreateIdentityUser(MapOIDCClaims(s.Config, claims))
+}
+
+// ExchangeCode 授权码换取令牌，携带 PKCE code_verifier；未配置密钥时按公共客户端处理
+func (s *OIDCService) ExchangeCode(tokenURL, code, codeVerifier string) (*OIDCTokenResponse, error) {
+	data := url.Values{}
+	data.Set("grant_type", "authorization_code")
+	data.Set("code", code)
+	data.Set("redirect_uri", s.Config.RedirectURI)
+	data.Set("client_id", s.Config.ClientID)
+	data.Set("code_verifier", codeVerifier)
+	if s.Config.ClientSecret != "" {
+		data.Set("client_secret", s.Config.ClientSecret)
+	}
+
+	body, err := s.doRequest("POST", tokenURL, strings.NewReader(data.Encode()), "")
+	if err != nil {
+		return nil, err
+	}
+
+	var tokenResp OIDCTokenResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if tokenResp.AccessToken == "" && tokenResp.IDToken == "" {
+		return nil, fmt.Errorf("未获取到 access_token")
+	}
+	return &tokenResp, nil
+}
+
+func (s *OIDCService) GetUserInfo(userinfoURL, accessToken string) (map[string]interface{}, error) {
+	body, err := s.doRequest("GET", userinfoURL, nil, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	claims := map[string]interface{}{}
+	if err := json.Unmarshal(body, &claims); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return claims, nil
+}
+
+// verifyIDTokenClaims id_token 由令牌端点经 TLS 直接返回，按 OIDC Core 3.1.3.7 以 TLS 校验代替签名校验，
+// 此处校验 iss、aud、exp 与 nonce
+func (s *OIDCService) verifyIDTokenClaims(idToken, issuer, nonce string) (map[string]interface{}, error) {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(idToken, claims); err != nil {
+		return nil, fmt.Errorf("解析身份令牌失败: %w", err)
+	}
+
+	if issuer != "" {
+		if iss, _ := claims.GetIssuer(); strings.TrimRight(iss, "/") != strings.TrimRight(issuer, "/") {
+			return nil, fmt.Errorf("身份令牌签发方不匹配")
+		}
+	}
+	aud, _ := claims.GetAudience()
+	audienceOK := false
+	for _, a := range aud {
+		if a == s.Config.ClientID {
+			audienceOK = true
+			break
+		}
+	}
+	if !audienceOK {
+		return nil, fmt.Errorf("身份令牌受众不匹配")
+	}
+	if exp, err := claims.GetExpirationTime(); err != nil || exp == nil || exp.Before(time.Now().Add(-time.Minute)) {
+		return nil, fmt.Errorf("身份令牌已过期")
+	}
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, fmt.Errorf("身份令牌 nonce 不匹配")
+	}
+	return claims, nil
+}
+
+func (s *OIDCService) doRequest(method, target string, body io.Reader, bearer string) ([]byte, error) {
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	client := getHTTPClient(s.ProxyConfig)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned error (status %d): %s", s.Config.Slug, resp.StatusCode, string(respBody))
+	}
+	return respBody, nil
+}
+
+// MapOIDCClaims 按提供方的声明映射生成用户资料，组映射决定角色
+func MapOIDCClaims(config *dto.OIDCProviderConfig, claims map[string]interface{}) *ExternalProfile {
+	profile := &ExternalProfile{
+		Provider:    config.Slug,
+		Subject:     claimString(claims, config.SubjectClaim),
+		Username:    claimString(claims, config.UsernameClaim),
+		Email:       claimString(claims, config.EmailClaim),
+		Avatar:      claimString(claims, config.AvatarClaim),
+		LinkByEmail: config.LinkByEmail,
+		Role:        config.DefaultRole,
+	}
+	if verified, ok := lookupClaim(claims, "email_verified").(bool); ok {
+		profile.EmailVerified = verified
+	}
+	if profile.Username == "" {
+		profile.Username = claimString(claims, "name")
+	}
+
+	if len(config.RoleMapping) > 0 {
+		profile.SyncRole = true
+		for _, group := range claimStrings(claims, config.GroupsClaim) {
+			if role, ok := config.RoleMapping[group]; ok && role < profile.Role {
+				profile.Role = role
+			}
+		}
+	}
+	return profile
+}
+
+// lookupClaim 支持以点号访问嵌套声明，如 Keycloak 的 realm_access.roles
+func lookupClaim(claims map[string]interface{}, path string) interface{} {
+	if path == "" {
+		return nil
+	}
+	if v, ok := claims[path]; ok {
+		return v
+	}
+	var current interface{} = claims
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+	return current
+}
+
+func claimString(claims map[string]interface{}, path string) string {
+	switch v := lookupClaim(claims, path).(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return fmt.Sprintf("%.0f", v)
+	}
+	return ""
+}
+
+func claimStrings(claims map[string]interface{}, path string) []string {
+	var result []string
+	switch v := lookupClaim(claims, path).(type) {
+	case []interface{}:
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				result = append(result, strings.TrimSpace(str))
+			}
+		}
+	case []string:
+		result = v
+	case string:
+		result = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+	return result
+}
+
+func consumeOIDCState(state string) (*oidcLoginState, error) {
+	key := oidcStateKey(state)
+	val, err := cache.GetCache().Get(key)
+	if err != nil || val == "" {
+		return nil, fmt.Errorf("登录状态已过期，请重新发起登录")
+	}
+	_ = cache.GetCache().Del(key)
+
+	var loginState oidcLoginState
+	if err := json.Unmarshal([]byte(val), &loginState); err != nil {
+		return nil, fmt.Errorf("登录状态无效")
+	}
+	return &loginState, nil
+}
+
+func oidcStateKey(state string) string {
+	return "oauth:oidc:state:" + state
+}
+
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomURLSafe(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
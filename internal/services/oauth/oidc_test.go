@@ -0,0 +1,62 @@
+package oauth
+
+import (
+	"testing"
+
+	"pixelpunk/internal/controllers/setting/dto"
+	"pixelpunk/pkg/common"
+)
+
+// S256：BASE64URL(SHA256(verifier))，不带填充
+func TestPKCEChallenge(t *testing.T) {
+	got := pkceChallenge("pixelpunk-code-verifier-0123456789-abcdefghij")
+	if want := "fPClia1enCPOcNP6aZYSnUgZmsHDd2RpITVv7U60eoA"; got != want {
+		t.Errorf("pkceChallenge = %s, want %s", got, want)
+	}
+}
+
+func TestMapOIDCClaims(t *testing.T) {
+	config := &dto.OIDCProviderConfig{
+		Slug:          "keycloak",
+		SubjectClaim:  "sub",
+		UsernameClaim: "preferred_username",
+		EmailClaim:    "email",
+		AvatarClaim:   "picture",
+		GroupsClaim:   "realm_access.roles",
+		RoleMapping:   map[string]int{"pixelpunk-admin": common.UserRoleAdmin},
+		DefaultRole:   common.UserRoleUser,
+	}
+	claims := map[string]interface{}{
+		"sub":                "f3a1",
+		"preferred_username": "alice",
+		"email":              "alice@example.com",
+		"email_verified":     true,
+		"realm_access": map[string]interface{}{
+			"roles": []interface{}{"offline_access", "pixelpunk-admin"},
+		},
+	}
+
+	profile := MapOIDCClaims(config, claims)
+	if profile.Provider != "keycloak" || profile.Subject != "f3a1" || profile.Username != "alice" {
+		t.Fatalf("unexpected profile: %+v", profile)
+	}
+	if !profile.EmailVerified || profile.Email != "alice@example.com" {
+		t.Errorf("email not mapped: %+v", profile)
+	}
+	if profile.Role != common.UserRoleAdmin || !profile.SyncRole {
+		t.Errorf("role mapping not applied: role=%d sync=%v", profile.Role, profile.SyncRole)
+	}
+
+	claims["realm_access"] = map[string]interface{}{"roles": []interface{}{"offline_access"}}
+	if profile := MapOIDCClaims(config, claims); profile.Role != common.UserRoleUser {
+		t.Errorf("unmatched groups should fall back to default role, got %d", profile.Role)
+	}
+}
+
+func TestClaimStrings(t *testing.T) {
+	claims := map[string]interface{}{"groups": "editors, admins"}
+	got := claimStrings(claims, "groups")
+	if len(got) != 2 || got[0] != "editors" || got[1] != "admins" {
+		t.Errorf("claimStrings = %v", got)
+	}
+}
@@ -0,0 +1,119 @@
+package rbac
+
+/* Admin permission catalogue and built-in role definitions. */
+
+import (
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/common"
+)
+
+/* 后台权限标识，每个管理路由组对应一个权限 */
+const (
+	PermAll                = "*"
+	PermDashboardView      = "dashboard.view"
+	PermUserView           = "user.view"
+	PermUserManage         = "user.manage"
+	PermFileManage         = "file.manage"
+	PermContentReview      = "content.review"
+	PermShareManage        = "share.manage"
+	PermTagManage          = "tag.manage"
+	PermMessageManage      = "message.manage"
+	PermAnnouncementManage = "announcement.manage"
+	PermStorageManage      = "storage.manage"
+	PermSettingManage      = "setting.manage"
+	PermAIManage           = "ai.manage"
+	PermWebhookManage      = "webhook.manage"
+	PermWorkspaceManage    = "workspace.manage"
+	PermRoleManage         = "role.manage"
+)
+
+/* PermissionInfo 权限说明，供后台角色编辑页展示 */
+type PermissionInfo struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+var permissionCatalog = []PermissionInfo{
+	{PermDashboardView, "仪表盘", "查看后台统计数据与系统信息"},
+	{PermUserView, "查看用户", "查看用户列表与详情"},
+	{PermUserManage, "管理用户", "创建、编辑、禁用、删除用户，重置密码与两步验证"},
+	{PermFileManage, "文件管理", "管理全站文件、分片上传与相似图片检索"},
+	{PermContentReview, "内容审核", "处理审核队列与审核记录"},
+	{PermShareManage, "分享管理", "管理全站分享与访客记录"},
+	{PermTagManage, "标签与分类", "管理全局标签与分类模板"},
+	{PermMessageManage, "站内消息", "管理站内消息与消息模板"},
+	{PermAnnouncementManage, "公告管理", "发布与编辑系统公告"},
+	{PermStorageManage, "存储渠道", "配置存储渠道与存储迁移"},
+	{PermSettingManage, "系统设置", "修改系统设置（含邮件服务）与上传系统素材"},
+	{PermAIManage, "AI 与向量", "配置 AI 服务、向量库与相关任务"},
+	{PermWebhookManage, "全局 Webhook", "管理系统级 Webhook"},
+	{PermWorkspaceManage, "工作区管理", "查看全部工作区并调整共享配额"},
+	{PermRoleManage, "角色权限", "管理后台角色并为用户分配角色"},
+}
+
+// builtinRoles 内置角色的权限由代码定义，新增权限时自动生效
+var builtinRoles = map[string][]string{
+	models.AdminRoleSuperAdmin: {PermAll},
+	models.AdminRoleAdmin: {
+		PermDashboardView, PermUserView, PermFileManage, PermContentReview, PermShareManage,
+		PermTagManage, PermMessageManage, PermAnnouncementManage, PermStorageManage,
+		PermSettingManage, PermAIManage, PermWebhookManage,
+	},
+	models.AdminRoleModerator: {PermDashboardView, PermContentReview, PermShareManage},
+}
+
+/* BuiltinRoles 内置角色定义，迁移时写入数据库 */
+func BuiltinRoles() []models.AdminRole {
+	return []models.AdminRole{
+		{Name: models.AdminRoleSuperAdmin, DisplayName: "超级管理员", Description: "拥有全部权限", IsBuiltIn: true},
+		{Name: models.AdminRoleAdmin, DisplayName: "管理员", Description: "除用户管理、工作区管理与角色权限外的全部后台权限", IsBuiltIn: true},
+		{Name: models.AdminRoleModerator, DisplayName: "审核员", Description: "仅可进行内容审核与分享管理", IsBuiltIn: true},
+	}
+}
+
+/* Permissions 全部可分配的权限 */
+func Permissions() []PermissionInfo {
+	return permissionCatalog
+}
+
+/* IsValidPermission 权限标识是否存在 */
+func IsValidPermission(key string) bool {
+	for _, p := range permissionCatalog {
+		if p.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// rolePermissions 角色的有效权限，内置角色以代码定义为准
+func rolePermissions(role *models.AdminRole) []string {
+	if role.IsBuiltIn {
+		if perms, ok := builtinRoles[role.Name]; ok {
+			return perms
+		}
+	}
+	return role.PermissionList()
+}
+
+// legacyRoleName 未分配角色的管理员按旧的固定角色使用对应的内置角色
+func legacyRoleName(userRole int) string {
+	switch userRole {
+	case common.UserRoleSuperAdmin:
+		return models.AdminRoleSuperAdmin
+	case common.UserRoleAdmin:
+		return models.AdminRoleAdmin
+	}
+	return ""
+}
+
+// hasPermission 权限列表中包含目标权限或通配符
+func hasPermission(perms []string, perm string) bool {
+	for _, p := range perms {
+		if p == PermAll || p == perm {
+			return true
+		}
+	}
+	return false
+}
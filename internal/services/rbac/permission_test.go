@@ -0,0 +1,66 @@
+package rbac
+
+import (
+	"testing"
+
+	"pixelpunk/internal/models"
+)
+
+func TestHasPermission(t *testing.T) {
+	if !hasPermission([]string{PermAll}, PermRoleManage) {
+		t.Error("通配符应包含全部权限")
+	}
+	if !hasPermission([]string{PermContentReview}, PermContentReview) {
+		t.Error("应包含已授予的权限")
+	}
+	if hasPermission([]string{PermContentReview}, PermStorageManage) || hasPermission(nil, PermContentReview) {
+		t.Error("不应包含未授予的权限")
+	}
+}
+
+func TestBuiltinRoles(t *testing.T) {
+	for name, perms := range builtinRoles {
+		for _, p := range perms {
+			if p != PermAll && !IsValidPermission(p) {
+				t.Errorf("内置角色 %s 包含未知权限 %s", name, p)
+			}
+		}
+	}
+
+	admin := builtinRoles[models.AdminRoleAdmin]
+	for _, p := range []string{PermUserManage, PermWorkspaceManage, PermRoleManage} {
+		if hasPermission(admin, p) {
+			t.Errorf("管理员不应拥有原超级管理员专属权限 %s", p)
+		}
+	}
+
+	moderator := builtinRoles[models.AdminRoleModerator]
+	if !hasPermission(moderator, PermContentReview) {
+		t.Error("审核员应拥有内容审核权限")
+	}
+	for _, p := range []string{PermStorageManage, PermSettingManage, PermAIManage} {
+		if hasPermission(moderator, p) {
+			t.Errorf("审核员不应拥有 %s", p)
+		}
+	}
+
+	custom := models.AdminRole{Name: models.AdminRoleModerator, Permissions: PermStorageManage, IsBuiltIn: true}
+	if hasPermission(rolePermissions(&custom), PermStorageManage) {
+		t.Error("内置角色应以代码定义的权限为准")
+	}
+}
+
+func TestNormalizePermissions(t *testing.T) {
+	got, err := normalizePermissions([]string{PermShareManage, " " + PermContentReview, PermShareManage})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := PermContentReview + "," + PermShareManage; got != want {
+		t.Errorf("normalizePermissions = %q, want %q", got, want)
+	}
+	for _, bad := range []string{PermAll, "storage.delete", ""} {
+		if _, err := normalizePermissions([]string{bad}); err == nil {
+			t.Errorf("normalizePermissions(%q) 应返回错误", bad)
+		}
+	}
+}
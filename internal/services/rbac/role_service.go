@@ -0,0 +1,345 @@
+package rbac
+
+/* Admin roles: custom role CRUD, role assignment and permission lookup for the admin middleware. */
+
+import (
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const systemRootAdminID uint = 1 // 系统默认超级管理员ID
+
+var roleNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+/* RoleResponse 角色及其有效权限、已分配用户数 */
+type RoleResponse struct {
+	models.AdminRole
+	Permissions []string `json:"permissions"`
+	UserCount   int64    `json:"user_count"`
+}
+
+/* RoleInput 创建或修改角色的参数，nil 字段保持不变 */
+type RoleInput struct {
+	DisplayName *string
+	Description *string
+	Permissions []string
+}
+
+/* UserPermissions 获取用户的有效后台权限 */
+func UserPermissions(userID uint) ([]string, error) {
+	var user models.User
+	if err := database.DB.Select("id, role, admin_role_id").Where("id = ?", userID).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeUserNotFound, "用户不存在")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询用户失败")
+	}
+	switch user.Role {
+	case common.UserRoleSuperAdmin:
+		return []string{PermAll}, nil
+	case common.UserRoleAdmin:
+		if user.AdminRoleID > 0 {
+			var role models.AdminRole
+			if err := database.DB.Where("id = ?", user.AdminRoleID).First(&role).Error; err == nil {
+				return rolePermissions(&role), nil
+			} else if err != gorm.ErrRecordNotFound {
+				return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询角色失败")
+			}
+		}
+		return builtinRoles[legacyRoleName(user.Role)], nil
+	}
+	return []string{}, nil
+}
+
+/* HasPermission 用户是否拥有指定后台权限 */
+func HasPermission(userID uint, perm string) (bool, error) {
+	perms, err := UserPermissions(userID)
+	if err != nil {
+		return false, err
+	}
+	return hasPermission(perms, perm), nil
+}
+
+/* ListRoles 获取全部角色 */
+func ListRoles() ([]RoleResponse, error) {
+	var roles []models.AdminRole
+	if err := database.DB.Order("is_built_in DESC, id ASC").Find(&roles).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询角色失败")
+	}
+	items := make([]RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		items = append(items, buildRoleResponse(role))
+	}
+	return items, nil
+}
+
+/* CreateRole 创建自定义角色，只能授予操作者自身拥有的权限 */
+func CreateRole(operatorID uint, name string, input RoleInput) (*RoleResponse, error) {
+	name = strings.TrimSpace(name)
+	if !roleNamePattern.MatchString(name) {
+		return nil, errors.New(errors.CodeInvalidParameter, "角色标识只能包含小写字母、数字、下划线或连字符，且以字母或数字开头")
+	}
+	perms, err := normalizePermissions(input.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkGrantable(operatorID, input.Permissions); err != nil {
+		return nil, err
+	}
+	var count int64
+	if err := database.DB.Model(&models.AdminRole{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询角色失败")
+	}
+	if count > 0 {
+		return nil, errors.New(errors.CodeConflict, "角色标识已存在")
+	}
+
+	role := models.AdminRole{Name: name, DisplayName: name, Permissions: perms}
+	if input.DisplayName != nil && strings.TrimSpace(*input.DisplayName) != "" {
+		role.DisplayName = strings.TrimSpace(*input.DisplayName)
+	}
+	if input.Description != nil {
+		role.Description = *input.Description
+	}
+	if err := database.DB.Create(&role).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBCreateFailed, "创建角色失败")
+	}
+	resp := buildRoleResponse(role)
+	return &resp, nil
+}
+
+/* UpdateRole 修改自定义角色，内置角色不可修改 */
+func UpdateRole(operatorID, roleID uint, input RoleInput) (*RoleResponse, error) {
+	role, err := getCustomRole(roleID)
+	if err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{}
+	if input.DisplayName != nil {
+		updates["display_name"] = strings.TrimSpace(*input.DisplayName)
+	}
+	if input.Description != nil {
+		updates["description"] = *input.Description
+	}
+	if input.Permissions != nil {
+		perms, err := normalizePermissions(input.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkGrantable(operatorID, input.Permissions); err != nil {
+			return nil, err
+		}
+		updates["permissions"] = perms
+	}
+	if len(updates) > 0 {
+		if err := database.DB.Model(role).Updates(updates).Error; err != nil {
+			return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "更新角色失败")
+		}
+	}
+	if err := database.DB.Where("id = ?", roleID).First(role).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询角色失败")
+	}
+	resp := buildRoleResponse(*role)
+	return &resp, nil
+}
+
+/* DeleteRole 删除未被使用的自定义角色 */
+func DeleteRole(roleID uint) error {
+	role, err := getCustomRole(roleID)
+	if err != nil {
+		return err
+	}
+	var count int64
+	if err := database.DB.Model(&models.User{}).Where("admin_role_id = ?", roleID).Count(&count).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询角色用户失败")
+	}
+	if count > 0 {
+		return errors.New(errors.CodeConflict, "仍有用户使用该角色，请先调整这些用户的角色")
+	}
+	if err := database.DB.Delete(role).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除角色失败")
+	}
+	return nil
+}
+
+/* AssignRole 为用户分配后台角色，roleID 为 0 时取消管理权限；同步旧的 Role 字段 */
+func AssignRole(operatorID, userID, roleID uint) error {
+	if operatorID == userID {
+		return errors.New(errors.CodeForbidden, "不能修改自己的角色")
+	}
+	if userID == systemRootAdminID {
+		return errors.New(errors.CodeForbidden, "系统默认超级管理员的角色不能被修改")
+	}
+	operator, err := getUserRole(operatorID)
+	if err != nil {
+		return err
+	}
+	user, err := getUserRole(userID)
+	if err != nil {
+		return err
+	}
+	if err := CheckManageTarget(operatorID, userID); err != nil {
+		return err
+	}
+
+	newRole := common.UserRoleUser
+	if roleID > 0 {
+		var role models.AdminRole
+		if err := database.DB.Where("id = ?", roleID).First(&role).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.New(errors.CodeNotFound, "角色不存在")
+			}
+			return errors.Wrap(err, errors.CodeDBQueryFailed, "查询角色失败")
+		}
+		newRole = common.UserRoleAdmin
+		if role.IsBuiltIn && role.Name == models.AdminRoleSuperAdmin {
+			newRole = common.UserRoleSuperAdmin
+		}
+		if err := checkGrantable(operatorID, rolePermissions(&role)); err != nil {
+			return err
+		}
+	}
+	if (user.IsSuperAdmin() || newRole == common.UserRoleSuperAdmin) && !operator.IsSuperAdmin() {
+		return errors.New(errors.CodeForbidden, "只有超级管理员可以调整超级管理员角色")
+	}
+
+	if err := database.DB.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"role":          newRole,
+		"admin_role_id": roleID,
+	}).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBUpdateFailed, "分配角色失败")
+	}
+	return nil
+}
+
+/* CheckRoleWrite 直接修改用户固定角色（创建、编辑、批量设置）仅限超级管理员，且不能授予自身没有的权限 */
+func CheckRoleWrite(operatorID uint, role int) error {
+	operator, err := getUserRole(operatorID)
+	if err != nil {
+		return err
+	}
+	if !operator.IsSuperAdmin() {
+		return errors.New(errors.CodeForbidden, "只有超级管理员可以调整用户角色")
+	}
+	return checkGrantable(operatorID, builtinRoles[legacyRoleName(role)])
+}
+
+/* CheckManageTarget 管理其他用户时：超级管理员只能由超级管理员操作，管理员只能由拥有其全部权限的操作者操作 */
+func CheckManageTarget(operatorID, targetID uint) error {
+	if operatorID == targetID {
+		return nil
+	}
+	target, err := getUserRole(targetID)
+	if err != nil {
+		return err
+	}
+	if !target.IsAdmin() {
+		return nil
+	}
+	operator, err := getUserRole(operatorID)
+	if err != nil {
+		return err
+	}
+	if target.IsSuperAdmin() {
+		if !operator.IsSuperAdmin() {
+			return errors.New(errors.CodeForbidden, "只有超级管理员可以管理超级管理员")
+		}
+		return nil
+	}
+	own, err := UserPermissions(operatorID)
+	if err != nil {
+		return err
+	}
+	targetPerms, err := UserPermissions(targetID)
+	if err != nil {
+		return err
+	}
+	for _, p := range targetPerms {
+		if !hasPermission(own, p) {
+			return errors.New(errors.CodeForbidden, "不能管理权限高于自己的管理员")
+		}
+	}
+	return nil
+}
+
+// getUserRole 仅查询用户的角色字段
+func getUserRole(userID uint) (*models.User, error) {
+	var user models.User
+	if err := database.DB.Select("id, role, admin_role_id").Where("id = ?", userID).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeUserNotFound, "用户不存在")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询用户失败")
+	}
+	return &user, nil
+}
+
+// checkGrantable 操作者只能授予自己拥有的权限，避免通过角色管理提升权限
+func checkGrantable(operatorID uint, perms []string) error {
+	own, err := UserPermissions(operatorID)
+	if err != nil {
+		return err
+	}
+	for _, p := range perms {
+		if !hasPermission(own, strings.TrimSpace(p)) {
+			return errors.New(errors.CodeForbidden, "不能授予自己没有的权限："+p)
+		}
+	}
+	return nil
+}
+
+// getCustomRole 获取可修改的自定义角色
+func getCustomRole(roleID uint) (*models.AdminRole, error) {
+	var role models.AdminRole
+	if err := database.DB.Where("id = ?", roleID).First(&role).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeNotFound, "角色不存在")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询角色失败")
+	}
+	if role.IsBuiltIn {
+		return nil, errors.New(errors.CodeForbidden, "内置角色不能修改或删除")
+	}
+	return &role, nil
+}
+
+// normalizePermissions 校验权限标识并去重，按权限目录顺序保存
+func normalizePermissions(perms []string) (string, error) {
+	selected := make(map[string]bool, len(perms))
+	for _, p := range perms {
+		p = strings.TrimSpace(p)
+		if !IsValidPermission(p) {
+			return "", errors.New(errors.CodeInvalidParameter, "未知的权限标识："+p)
+		}
+		selected[p] = true
+	}
+	ordered := make([]string, 0, len(selected))
+	for _, info := range permissionCatalog {
+		if selected[info.Key] {
+			ordered = append(ordered, info.Key)
+		}
+	}
+	return strings.Join(ordered, ","), nil
+}
+
+func buildRoleResponse(role models.AdminRole) RoleResponse {
+	resp := RoleResponse{AdminRole: role, Permissions: rolePermissions(&role)}
+	query := database.DB.Model(&models.User{}).Where("admin_role_id = ?", role.ID)
+	if role.IsBuiltIn {
+		// 未分配角色的旧管理员按固定角色计入对应的内置角色
+		switch role.Name {
+		case models.AdminRoleSuperAdmin:
+			query = database.DB.Model(&models.User{}).Where("role = ?", common.UserRoleSuperAdmin)
+		case models.AdminRoleAdmin:
+			query = database.DB.Model(&models.User{}).Where("role = ? AND (admin_role_id = ? OR admin_role_id = 0)", common.UserRoleAdmin, role.ID)
+		}
+	}
+	query.Count(&resp.UserCount)
+	return resp
+}
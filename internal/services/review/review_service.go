@@ -5,6 +5,7 @@ import (
 	"pixelpunk/internal/models"
 	messageService "pixelpunk/internal/services/message"
 	"pixelpunk/internal/services/setting"
+	"pixelpunk/internal/services/webhook"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/logger"
@@ -64,6 +65,11 @@ func ApproveFileWithLog(fileID string, auditorID uint, reason string) error {
 
 		go sendFileReviewNotification(file.UserID, fileID, file.OriginalName, "approve", "", auditorID)
 
+		data := webhook.FileEventData(&file)
+		data["auditor_id"] = auditorID
+		data["reason"] = reason
+		webhook.Dispatch(webhook.EventFileApproved, file.UserID, data)
+
 		return nil
 	})
 }
@@ -149,6 +155,12 @@ func RejectFileWithLog(fileID string, auditorID uint, reason string, hardDelete
 		return err
 	}
 
+	data := webhook.FileEventData(&fileToDelete)
+	data["auditor_id"] = auditorID
+	data["reason"] = reason
+	data["hard_delete"] = hardDelete
+	webhook.Dispatch(webhook.EventFileRejected, fileToDelete.UserID, data)
+
 	// 在事务外执行硬删除操作（避免事务锁定）
 	if hardDelete {
 		// 使用 goroutine 异步执行硬删除，避免阻塞
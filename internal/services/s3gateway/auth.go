@@ -0,0 +1,69 @@
+package s3gateway
+
+import (
+	"net/http"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/apikey"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/pkg/errors"
+)
+
+/* Identity 通过 SigV4 校验的调用方：AccessKey 为 API 密钥ID，SecretKey 为 API 密钥值 */
+type Identity struct {
+	Key     *models.APIKey
+	Request *SignedRequest
+}
+
+/* Enabled S3 兼容接口是否启用 */
+func Enabled() bool {
+	return setting.GetBool("upload", "s3_gateway_enabled", false)
+}
+
+/* Authenticate 校验请求签名并返回调用方身份 */
+func Authenticate(r *http.Request) (*Identity, error) {
+	sr, err := parseSignedRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	if sr.Service != "s3" {
+		return nil, ErrAuthorizationMalformed.WithMessage("凭证范围中的服务必须为 s3")
+	}
+	if err := sr.checkTime(time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	key, err := apikey.ValidateAPIKeyByID(sr.AccessKey)
+	if err != nil {
+		if e, ok := err.(*errors.Error); ok && e.Code == errors.CodeDBQueryFailed {
+			return nil, ErrInternal
+		}
+		return nil, ErrInvalidAccessKeyID
+	}
+	if err := sr.verify(r, key.KeyValue); err != nil {
+		return nil, err
+	}
+	// 桶与对象按密钥所属用户解析，工作区目录不在其中
+	if key.WorkspaceID != "" {
+		return nil, ErrAccessDenied.WithMessage("工作区API密钥不支持S3访问")
+	}
+	if key.S3Permissions == "" {
+		return nil, ErrAccessDenied.WithMessage("该API密钥未开启S3访问")
+	}
+	return &Identity{Key: key, Request: sr}, nil
+}
+
+/* Require 检查密钥是否授予了指定的 S3 权限 */
+func (id *Identity) Require(perm string) error {
+	if id.Key.HasS3Permission(perm) {
+		return nil
+	}
+	switch perm {
+	case models.APIKeyS3Write:
+		return ErrAccessDenied.WithMessage("该API密钥没有S3上传权限")
+	case models.APIKeyS3Delete:
+		return ErrAccessDenied.WithMessage("该API密钥没有S3删除权限")
+	}
+	return ErrAccessDenied.WithMessage("该API密钥没有S3读取权限")
+}
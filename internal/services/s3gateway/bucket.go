@@ -0,0 +1,65 @@
+package s3gateway
+
+import (
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+)
+
+/* Bucket 存储桶：用户名桶映射到用户根目录，API 密钥ID桶映射到密钥绑定的上传目录 */
+type Bucket struct {
+	Name      string
+	UserID    uint
+	RootID    string // 桶对应的根文件夹ID，空表示用户根目录
+	CreatedAt time.Time
+}
+
+/* ListBuckets 列出当前密钥可访问的存储桶 */
+func (id *Identity) ListBuckets() ([]Bucket, error) {
+	buckets := []Bucket{}
+	if id.Key.FolderID == "" {
+		var user models.User
+		if err := database.DB.Where("id = ?", id.Key.UserID).First(&user).Error; err == nil {
+			buckets = append(buckets, Bucket{Name: user.Username, UserID: user.ID, CreatedAt: time.Time(user.CreatedAt)})
+		}
+	}
+	if b, err := id.ResolveBucket(id.Key.ID); err == nil {
+		buckets = append(buckets, *b)
+	}
+	return buckets, nil
+}
+
+/* ResolveBucket 解析存储桶名称；绑定了目录的密钥只能访问自己的密钥桶 */
+func (id *Identity) ResolveBucket(name string) (*Bucket, error) {
+	key := id.Key
+	if strings.EqualFold(name, key.ID) {
+		b := &Bucket{Name: key.ID, UserID: key.UserID, RootID: key.FolderID, CreatedAt: time.Time(key.CreatedAt)}
+		if key.FolderID != "" {
+			var count int64
+			database.DB.Model(&models.Folder{}).Where("id = ? AND user_id = ?", key.FolderID, key.UserID).Count(&count)
+			if count == 0 {
+				return nil, ErrNoSuchBucket.WithMessage("密钥绑定的目录已被删除")
+			}
+		}
+		return b, nil
+	}
+
+	var user models.User
+	if err := database.DB.Where("username = ?", name).First(&user).Error; err != nil {
+		var count int64
+		database.DB.Model(&models.APIKey{}).Where("id = ?", name).Count(&count)
+		if count > 0 {
+			return nil, ErrAccessDenied
+		}
+		return nil, ErrNoSuchBucket
+	}
+	if user.ID != key.UserID {
+		return nil, ErrAccessDenied
+	}
+	if key.FolderID != "" {
+		return nil, ErrAccessDenied.WithMessage("该密钥绑定了上传目录，只能访问以密钥ID命名的存储桶")
+	}
+	return &Bucket{Name: user.Username, UserID: user.ID, CreatedAt: time.Time(user.CreatedAt)}, nil
+}
@@ -0,0 +1,75 @@
+package s3gateway
+
+import (
+	"net/http"
+
+	"pixelpunk/pkg/errors"
+)
+
+/* Error S3 协议错误，按 S3 规范以 XML 形式返回给客户端 */
+type Error struct {
+	Code    string
+	Message string
+	Status  int
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+/* WithMessage 复制错误并替换描述信息 */
+func (e *Error) WithMessage(msg string) *Error {
+	return &Error{Code: e.Code, Message: msg, Status: e.Status}
+}
+
+var (
+	ErrAccessDenied                 = &Error{"AccessDenied", "拒绝访问", http.StatusForbidden}
+	ErrInvalidAccessKeyID           = &Error{"InvalidAccessKeyId", "访问密钥不存在或已失效", http.StatusForbidden}
+	ErrSignatureDoesNotMatch        = &Error{"SignatureDoesNotMatch", "签名校验失败", http.StatusForbidden}
+	ErrSignatureVersion             = &Error{"InvalidRequest", "仅支持 AWS4-HMAC-SHA256 签名", http.StatusBadRequest}
+	ErrAuthorizationMalformed       = &Error{"AuthorizationHeaderMalformed", "Authorization 请求头格式错误", http.StatusBadRequest}
+	ErrAuthorizationQueryParameters = &Error{"AuthorizationQueryParametersError", "预签名参数错误", http.StatusBadRequest}
+	ErrRequestTimeTooSkewed         = &Error{"RequestTimeTooSkewed", "请求时间与服务器时间偏差过大", http.StatusForbidden}
+	ErrContentSHA256Mismatch        = &Error{"XAmzContentSHA256Mismatch", "请求体哈希与签名不一致", http.StatusBadRequest}
+	ErrIncompleteBody               = &Error{"IncompleteBody", "请求体不完整或分块格式错误", http.StatusBadRequest}
+	ErrNoSuchBucket                 = &Error{"NoSuchBucket", "存储桶不存在", http.StatusNotFound}
+	ErrNoSuchKey                    = &Error{"NoSuchKey", "对象不存在", http.StatusNotFound}
+	ErrNoSuchUpload                 = &Error{"NoSuchUpload", "分片上传不存在或已结束", http.StatusNotFound}
+	ErrInvalidPart                  = &Error{"InvalidPart", "分片不存在或ETag不匹配", http.StatusBadRequest}
+	ErrInvalidPartOrder             = &Error{"InvalidPartOrder", "分片编号必须递增", http.StatusBadRequest}
+	ErrEntityTooSmall               = &Error{"EntityTooSmall", "除最后一个分片外，分片不能小于5MB", http.StatusBadRequest}
+	ErrEntityTooLarge               = &Error{"EntityTooLarge", "对象大小超过限制", http.StatusBadRequest}
+	ErrInvalidArgument              = &Error{"InvalidArgument", "参数无效", http.StatusBadRequest}
+	ErrInvalidObjectName            = &Error{"KeyTooLongError", "对象键无效", http.StatusBadRequest}
+	ErrMalformedXML                 = &Error{"MalformedXML", "请求体XML格式错误", http.StatusBadRequest}
+	ErrNotImplemented               = &Error{"NotImplemented", "不支持的操作", http.StatusNotImplemented}
+	ErrServiceDisabled              = &Error{"ServiceUnavailable", "S3 兼容接口未启用", http.StatusServiceUnavailable}
+	ErrQuotaExceeded                = &Error{"QuotaExceeded", "存储容量或上传次数已用尽", http.StatusForbidden}
+	ErrInternal                     = &Error{"InternalError", "服务器内部错误", http.StatusInternalServerError}
+)
+
+/* ToError 将业务错误转换为 S3 协议错误 */
+func ToError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	if e, ok := err.(*errors.Error); ok {
+		switch e.Code {
+		case errors.CodeFileNotFound, errors.CodeNotFound:
+			return ErrNoSuchKey.WithMessage(e.Message)
+		case errors.CodeForbidden, errors.CodeUnauthorized:
+			return ErrAccessDenied.WithMessage(e.Message)
+		case errors.CodeFileTooLarge:
+			return ErrEntityTooLarge.WithMessage(e.Message)
+		case errors.CodeStorageLimitExceeded, errors.CodeUploadLimitExceeded:
+			return ErrQuotaExceeded.WithMessage(e.Message)
+		case errors.CodeInvalidParameter, errors.CodeFileTypeNotSupported:
+			return ErrInvalidArgument.WithMessage(e.Message)
+		}
+		return ErrInternal.WithMessage(e.Message)
+	}
+	return ErrInternal.WithMessage(err.Error())
+}
@@ -0,0 +1,230 @@
+package s3gateway
+
+import (
+	"encoding/base64"
+	"sort"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+)
+
+const maxListKeys = 1000
+
+/* ObjectInfo 列表中的对象信息 */
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ETag         string
+	LastModified time.Time
+}
+
+/* ListOptions ListObjectsV2 参数 */
+type ListOptions struct {
+	Prefix            string
+	Delimiter         string
+	StartAfter        string
+	ContinuationToken string
+	MaxKeys           int
+}
+
+/* ListResult ListObjectsV2 结果 */
+type ListResult struct {
+	Objects               []ObjectInfo
+	CommonPrefixes        []string
+	IsTruncated           bool
+	NextMarker            string // 本页最后一个键或公共前缀，供 ListObjects(V1) 翻页
+	NextContinuationToken string
+}
+
+type listEntry struct {
+	key    string
+	object *ObjectInfo // 为空表示目录条目（以 / 结尾）
+}
+
+/* ListObjects 将文件夹树展开为按字典序排列的对象键；分隔符为 / 时只读取前缀所在目录的直接子项 */
+func ListObjects(b *Bucket, opts ListOptions) (*ListResult, error) {
+	if opts.MaxKeys <= 0 || opts.MaxKeys > maxListKeys {
+		opts.MaxKeys = maxListKeys
+	}
+	marker := opts.StartAfter
+	if opts.ContinuationToken != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(opts.ContinuationToken)
+		if err != nil {
+			return nil, ErrInvalidArgument.WithMessage("无效的 continuation-token")
+		}
+		if string(raw) > marker {
+			marker = string(raw)
+		}
+	}
+
+	dirPrefix := ""
+	if i := strings.LastIndex(opts.Prefix, "/"); i >= 0 {
+		dirPrefix = opts.Prefix[:i+1]
+	}
+	var segments []string
+	if dirPrefix != "" {
+		segments = strings.Split(strings.TrimSuffix(dirPrefix, "/"), "/")
+	}
+	folderID, ok, err := findFolder(b, segments)
+	if err != nil {
+		return nil, err
+	}
+	result := &ListResult{Objects: []ObjectInfo{}, CommonPrefixes: []string{}}
+	if !ok {
+		return result, nil
+	}
+
+	var entries []listEntry
+	if opts.Delimiter == "/" {
+		entries, err = listFolderEntries(b, folderID, dirPrefix)
+	} else {
+		entries, err = walkFolderEntries(b, folderID, dirPrefix)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	count := 0
+	lastEmitted, lastPrefix := "", ""
+	for _, e := range entries {
+		if !strings.HasPrefix(e.key, opts.Prefix) || e.key <= marker {
+			continue
+		}
+		// 上一页以公共前缀结束时，跳过该前缀下的所有键
+		if opts.Delimiter != "" && strings.HasSuffix(marker, opts.Delimiter) && strings.HasPrefix(e.key, marker) {
+			continue
+		}
+
+		emitted, isPrefix := e.key, false
+		if opts.Delimiter != "" {
+			rest := e.key[len(opts.Prefix):]
+			if idx := strings.Index(rest, opts.Delimiter); idx >= 0 {
+				emitted, isPrefix = opts.Prefix+rest[:idx+len(opts.Delimiter)], true
+			}
+		}
+		if isPrefix && emitted == lastPrefix {
+			continue
+		}
+		// 目录条目只贡献公共前缀，不作为对象返回
+		if !isPrefix && e.object == nil {
+			continue
+		}
+
+		if count == opts.MaxKeys {
+			result.IsTruncated = true
+			result.NextMarker = lastEmitted
+			result.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(lastEmitted))
+			break
+		}
+		if isPrefix {
+			result.CommonPrefixes = append(result.CommonPrefixes, emitted)
+			lastPrefix = emitted
+		} else {
+			result.Objects = append(result.Objects, *e.object)
+		}
+		lastEmitted = emitted
+		count++
+	}
+	return result, nil
+}
+
+// listFolderEntries 读取目录的直接子文件与子目录
+func listFolderEntries(b *Bucket, folderID, dirPrefix string) ([]listEntry, error) {
+	entries, err := folderObjectEntries(b, []string{folderID}, map[string]string{folderID: dirPrefix})
+	if err != nil {
+		return nil, err
+	}
+	var children []models.Folder
+	if err := database.DB.Select("id, name").Where("user_id = ? AND parent_id = ?", b.UserID, folderID).Find(&children).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询子文件夹失败")
+	}
+	for _, child := range children {
+		entries = append(entries, listEntry{key: dirPrefix + child.Name + "/"})
+	}
+	return entries, nil
+}
+
+// walkFolderEntries 递归展开目录下的全部文件
+func walkFolderEntries(b *Bucket, folderID, dirPrefix string) ([]listEntry, error) {
+	var folders []models.Folder
+	if err := database.DB.Select("id, parent_id, name").Where("user_id = ?", b.UserID).Find(&folders).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹失败")
+	}
+	children := make(map[string][]models.Folder)
+	for _, f := range folders {
+		children[f.ParentID] = append(children[f.ParentID], f)
+	}
+
+	prefixes := map[string]string{folderID: dirPrefix}
+	ids := []string{folderID}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if _, seen := prefixes[child.ID]; seen {
+				continue
+			}
+			prefixes[child.ID] = prefixes[ids[i]] + child.Name + "/"
+			ids = append(ids, child.ID)
+		}
+	}
+	return folderObjectEntries(b, ids, prefixes)
+}
+
+// folderObjectEntries 查询多个目录下的文件并按对象键去重（同名取最新）
+func folderObjectEntries(b *Bucket, folderIDs []string, prefixes map[string]string) ([]listEntry, error) {
+	var files []models.File
+	for start := 0; start < len(folderIDs); start += 500 {
+		end := start + 500
+		if end > len(folderIDs) {
+			end = len(folderIDs)
+		}
+		batch := folderIDs[start:end]
+		query := database.DB.Model(&models.File{}).
+			Select("id, folder_id, original_name, size, md5_hash, created_at, updated_at").
+			Where("user_id = ?", b.UserID).
+			Where("status <> ?", filesvc.StatusPendingDeletion)
+		if containsRoot(batch) {
+			query = query.Where("folder_id IN ? OR folder_id IS NULL", batch)
+		} else {
+			query = query.Where("folder_id IN ?", batch)
+		}
+		var chunk []models.File
+		if err := query.Order("created_at DESC").Find(&chunk).Error; err != nil {
+			return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+		}
+		files = append(files, chunk...)
+	}
+
+	seen := make(map[string]bool, len(files))
+	entries := make([]listEntry, 0, len(files))
+	for _, f := range files {
+		if f.OriginalName == "" || strings.Contains(f.OriginalName, "/") {
+			continue
+		}
+		key := prefixes[f.FolderID] + f.OriginalName
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		entries = append(entries, listEntry{key: key, object: &ObjectInfo{
+			Key:          key,
+			Size:         f.Size,
+			ETag:         f.MD5Hash,
+			LastModified: time.Time(f.UpdatedAt),
+		}})
+	}
+	return entries, nil
+}
+
+func containsRoot(ids []string) bool {
+	for _, id := range ids {
+		if id == "" {
+			return true
+		}
+	}
+	return false
+}
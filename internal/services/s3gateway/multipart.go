@@ -0,0 +1,220 @@
+package s3gateway
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	minPartSize   = 5 << 20
+	maxPartNumber = 10000
+)
+
+/* CompletedPart CompleteMultipartUpload 请求中的分片 */
+type CompletedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+func multipartDir(uploadID string) string {
+	return filepath.Join("temp", "s3_multipart", uploadID)
+}
+
+func partPath(uploadID string, partNumber int) string {
+	return filepath.Join(multipartDir(uploadID), fmt.Sprintf("%05d.part", partNumber))
+}
+
+/* CreateMultipartUpload 创建分片上传会话 */
+func (id *Identity) CreateMultipartUpload(b *Bucket, key, contentType, accessLevel string) (*models.S3MultipartUpload, error) {
+	if _, name, err := splitObjectKey(key); err != nil {
+		return nil, err
+	} else if name == "" {
+		return nil, ErrInvalidObjectName
+	}
+	upload := &models.S3MultipartUpload{
+		UserID:      b.UserID,
+		APIKeyID:    id.Key.ID,
+		Bucket:      b.Name,
+		ObjectKey:   key,
+		ContentType: contentType,
+		AccessLevel: accessLevel,
+	}
+	if err := database.DB.Create(upload).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBCreateFailed, "创建分片上传失败")
+	}
+	return upload, nil
+}
+
+func getMultipartUpload(b *Bucket, key, uploadID string) (*models.S3MultipartUpload, error) {
+	var upload models.S3MultipartUpload
+	err := database.DB.Where("id = ? AND user_id = ? AND bucket = ? AND object_key = ?", uploadID, b.UserID, b.Name, key).First(&upload).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrNoSuchUpload
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询分片上传失败")
+	}
+	return &upload, nil
+}
+
+/* UploadPart 写入单个分片并返回其 ETag（内容MD5），重复上传同一编号会覆盖 */
+func UploadPart(b *Bucket, key, uploadID string, partNumber int, body io.Reader) (string, error) {
+	if partNumber < 1 || partNumber > maxPartNumber {
+		return "", ErrInvalidArgument.WithMessage("分片编号必须在1到10000之间")
+	}
+	if _, err := getMultipartUpload(b, key, uploadID); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(multipartDir(uploadID), 0755); err != nil {
+		return "", errors.Wrap(err, errors.CodeInternal, "创建分片目录失败")
+	}
+
+	target := partPath(uploadID, partNumber)
+	tmp := target + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return "", errors.Wrap(err, errors.CodeInternal, "创建分片文件失败")
+	}
+	h := md5.New()
+	br := &bodyReader{r: body, limit: maxObjectSize()}
+	size, copyErr := io.Copy(io.MultiWriter(f, h), br)
+	closeErr := f.Close()
+	if copyErr != nil || closeErr != nil {
+		os.Remove(tmp)
+		if br.err != nil {
+			return "", br.err
+		}
+		return "", errors.Wrap(copyErr, errors.CodeInternal, "写入分片失败")
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		os.Remove(tmp)
+		return "", errors.Wrap(err, errors.CodeInternal, "保存分片失败")
+	}
+
+	etag := hex.EncodeToString(h.Sum(nil))
+	part := models.S3MultipartPart{UploadID: uploadID, PartNumber: partNumber, ETag: etag, Size: size}
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "upload_id"}, {Name: "part_number"}},
+		DoUpdates: clause.AssignmentColumns([]string{"etag", "size", "updated_at"}),
+	}).Create(&part).Error; err != nil {
+		return "", errors.Wrap(err, errors.CodeDBCreateFailed, "记录分片失败")
+	}
+	return etag, nil
+}
+
+/* ListParts 列出已上传的分片 */
+func ListParts(b *Bucket, key, uploadID string) ([]models.S3MultipartPart, error) {
+	if _, err := getMultipartUpload(b, key, uploadID); err != nil {
+		return nil, err
+	}
+	var parts []models.S3MultipartPart
+	if err := database.DB.Where("upload_id = ?", uploadID).Order("part_number ASC").Find(&parts).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询分片失败")
+	}
+	return parts, nil
+}
+
+/* CompleteMultipartUpload 按客户端提交的分片顺序拼接内容，走常规上传流程写入对象，返回 S3 风格的分片 ETag */
+func (id *Identity) CompleteMultipartUpload(b *Bucket, key, uploadID string, completed []CompletedPart) (*models.File, string, error) {
+	upload, err := getMultipartUpload(b, key, uploadID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(completed) == 0 {
+		return nil, "", ErrMalformedXML.WithMessage("至少需要一个分片")
+	}
+	stored, err := ListParts(b, key, uploadID)
+	if err != nil {
+		return nil, "", err
+	}
+	byNumber := make(map[int]models.S3MultipartPart, len(stored))
+	for _, p := range stored {
+		byNumber[p.PartNumber] = p
+	}
+
+	digests := md5.New()
+	readers := make([]io.Reader, 0, len(completed))
+	var files []*os.File
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	for i, cp := range completed {
+		if i > 0 && cp.PartNumber <= completed[i-1].PartNumber {
+			return nil, "", ErrInvalidPartOrder
+		}
+		part, ok := byNumber[cp.PartNumber]
+		if !ok || strings.Trim(cp.ETag, `"`) != part.ETag {
+			return nil, "", ErrInvalidPart
+		}
+		if i < len(completed)-1 && part.Size < minPartSize {
+			return nil, "", ErrEntityTooSmall
+		}
+		raw, _ := hex.DecodeString(part.ETag)
+		digests.Write(raw)
+
+		f, err := os.Open(partPath(uploadID, cp.PartNumber))
+		if err != nil {
+			return nil, "", ErrInvalidPart.WithMessage("分片数据已丢失，请重新上传")
+		}
+		files = append(files, f)
+		readers = append(readers, f)
+	}
+
+	file, err := id.PutObject(b, key, upload.ContentType, upload.AccessLevel, io.MultiReader(readers...))
+	if err != nil {
+		return nil, "", err
+	}
+	removeMultipartUpload(uploadID)
+	return file, fmt.Sprintf("%s-%d", hex.EncodeToString(digests.Sum(nil)), len(completed)), nil
+}
+
+/* AbortMultipartUpload 放弃分片上传并清理已上传的分片 */
+func AbortMultipartUpload(b *Bucket, key, uploadID string) error {
+	if _, err := getMultipartUpload(b, key, uploadID); err != nil {
+		return err
+	}
+	removeMultipartUpload(uploadID)
+	return nil
+}
+
+func removeMultipartUpload(uploadID string) {
+	if err := database.DB.Where("upload_id = ?", uploadID).Delete(&models.S3MultipartPart{}).Error; err != nil {
+		logger.Error("删除分片记录失败 [%s]: %v", uploadID, err)
+	}
+	if err := database.DB.Where("id = ?", uploadID).Delete(&models.S3MultipartUpload{}).Error; err != nil {
+		logger.Error("删除分片上传会话失败 [%s]: %v", uploadID, err)
+	}
+	if err := os.RemoveAll(multipartDir(uploadID)); err != nil {
+		logger.Error("清理分片临时文件失败 [%s]: %v", uploadID, err)
+	}
+}
+
+/* CleanupStaleMultipartUploads 清理超过保留时间仍未完成的分片上传 */
+func CleanupStaleMultipartUploads(olderThan time.Duration, limit int) (int, error) {
+	var uploads []models.S3MultipartUpload
+	cutoff := common.JSONTime(time.Now().Add(-olderThan))
+	if err := database.DB.Select("id").Where("created_at < ?", cutoff).Limit(limit).Find(&uploads).Error; err != nil {
+		return 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询过期分片上传失败")
+	}
+	for _, u := range uploads {
+		removeMultipartUpload(u.ID)
+	}
+	return len(uploads), nil
+}
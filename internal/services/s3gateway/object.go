@@ -0,0 +1,201 @@
+package s3gateway
+
+import (
+	"io"
+	"strings"
+
+	"pixelpunk/internal/models"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/internal/services/folder"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const maxObjectKeyLength = 1024
+
+/* splitObjectKey 将对象键拆分为目录层级与文件名，以 / 结尾的键表示目录 */
+func splitObjectKey(key string) ([]string, string, error) {
+	if key == "" || len(key) > maxObjectKeyLength || strings.HasPrefix(key, "/") {
+		return nil, "", ErrInvalidObjectName
+	}
+	segments := strings.Split(key, "/")
+	name := segments[len(segments)-1]
+	dirs := segments[:len(segments)-1]
+	for _, seg := range dirs {
+		if seg == "" || seg == "." || seg == ".." || len(seg) > 100 {
+			return nil, "", ErrInvalidObjectName
+		}
+	}
+	if name == "." || name == ".." || len(name) > 255 {
+		return nil, "", ErrInvalidObjectName
+	}
+	return dirs, name, nil
+}
+
+/* findFolder 在桶根目录下逐级查找文件夹，不存在时返回 false */
+func findFolder(b *Bucket, segments []string) (string, bool, error) {
+	current := b.RootID
+	for _, name := range segments {
+		var f models.Folder
+		err := database.DB.Select("id").Where("user_id = ? AND parent_id = ? AND name = ?", b.UserID, current, name).First(&f).Error
+		if err == gorm.ErrRecordNotFound {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹失败")
+		}
+		current = f.ID
+	}
+	return current, true, nil
+}
+
+func folderFilesQuery(b *Bucket, folderID string) *gorm.DB {
+	query := database.DB.Model(&models.File{}).
+		Where("user_id = ?", b.UserID).
+		Where("status <> ?", filesvc.StatusPendingDeletion)
+	if folderID == "" {
+		return query.Where("folder_id = '' OR folder_id IS NULL")
+	}
+	return query.Where("folder_id = ?", folderID)
+}
+
+/* HeadObject 按对象键查找文件，同名文件取最新上传的一个 */
+func HeadObject(b *Bucket, key string) (*models.File, error) {
+	dirs, name, err := splitObjectKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, ErrNoSuchKey
+	}
+	folderID, ok, err := findFolder(b, dirs)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNoSuchKey
+	}
+	var file models.File
+	err = folderFilesQuery(b, folderID).Where("original_name = ?", name).Order("created_at DESC").First(&file).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrNoSuchKey
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	}
+	return &file, nil
+}
+
+/* OpenObject 以流的方式打开对象内容并返回存储中的实际大小，主渠道不可用时按副本故障转移 */
+func OpenObject(file *models.File) (*filesvc.ProxyResponse, error) {
+	return filesvc.OpenFileContent(*file, false)
+}
+
+/* maxObjectSize 单个对象的上限，沿用上传设置中的最大文件大小 */
+func maxObjectSize() int64 {
+	return int64(setting.GetInt("upload", "max_file_size", 20)) << 20
+}
+
+// bodyReader 记录请求体读取过程中的首个错误，以便上传失败时返回准确的协议错误
+type bodyReader struct {
+	r     io.Reader
+	limit int64
+	read  int64
+	err   error
+}
+
+func (br *bodyReader) Read(p []byte) (int, error) {
+	n, err := br.r.Read(p)
+	br.read += int64(n)
+	if br.limit > 0 && br.read > br.limit {
+		err = ErrEntityTooLarge
+	}
+	if err != nil && err != io.EOF && br.err == nil {
+		br.err = err
+	}
+	return n, err
+}
+
+/* PutObject 通过常规上传流程写入对象；键已存在时上传成功后删除旧文件，以 / 结尾的键只创建目录 */
+func (id *Identity) PutObject(b *Bucket, key, contentType, accessLevel string, body io.Reader) (*models.File, error) {
+	dirs, name, err := splitObjectKey(key)
+	if err != nil {
+		return nil, err
+	}
+	folderID, err := folder.CreateFolderByPathUnder(b.UserID, b.RootID, strings.Join(dirs, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		_, _ = io.Copy(io.Discard, body)
+		return nil, nil
+	}
+
+	existing, err := HeadObject(b, key)
+	if err != nil && err != ErrNoSuchKey {
+		return nil, err
+	}
+
+	br := &bodyReader{r: body, limit: maxObjectSize()}
+	file, err := filesvc.UploadReaderWithAPIKey(id.Key, folderID, name, contentType, accessLevel, br)
+	if br.err != nil {
+		return nil, br.err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil && existing.ID != file.ID {
+		if err := filesvc.DeleteFile(b.UserID, existing.ID); err != nil {
+			logger.Warn("S3覆盖写入后删除旧文件失败 [%s]: %v", existing.ID, err)
+		}
+	}
+	return file, nil
+}
+
+/* DeleteObject 删除对象（按系统设置进入回收站），对象不存在时视为成功；以 / 结尾的键删除空目录 */
+func DeleteObject(b *Bucket, key string) error {
+	dirs, name, err := splitObjectKey(key)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return deleteEmptyFolder(b, dirs)
+	}
+	file, err := HeadObject(b, key)
+	if err == ErrNoSuchKey {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return filesvc.DeleteFile(b.UserID, file.ID)
+}
+
+func deleteEmptyFolder(b *Bucket, dirs []string) error {
+	if len(dirs) == 0 {
+		return nil
+	}
+	folderID, ok, err := findFolder(b, dirs)
+	if err != nil || !ok {
+		return err
+	}
+	var files, children int64
+	folderFilesQuery(b, folderID).Count(&files)
+	database.DB.Model(&models.Folder{}).Where("user_id = ? AND parent_id = ?", b.UserID, folderID).Count(&children)
+	if files > 0 || children > 0 {
+		return nil
+	}
+	if err := folder.DeleteFolder(b.UserID, folderID); err != nil {
+		// 回收站中的文件仍归属该目录，此时保留目录
+		if e, ok := err.(*errors.Error); ok && e.Code == errors.CodeFolderNotEmpty {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package s3gateway
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	amzDateLayout    = "20060102T150405Z"
+	maxClockSkew     = 15 * time.Minute
+	maxPresignExpiry = 7 * 24 * 3600
+
+	PayloadUnsigned         = "UNSIGNED-PAYLOAD"
+	PayloadStreamingSigned  = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	PayloadStreamingTrailer = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+
+	emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+/* SignedRequest 从请求中解析出的 SigV4 签名信息 */
+type SignedRequest struct {
+	AccessKey     string
+	Date          string // 凭证范围中的日期 yyyymmdd
+	Region        string
+	Service       string
+	SignedHeaders []string
+	Signature     string
+	AmzDate       string
+	PayloadHash   string
+	Presigned     bool
+	Expires       int64
+}
+
+/* Scope 凭证范围 date/region/service/aws4_request */
+func (s *SignedRequest) Scope() string {
+	return s.Date + "/" + s.Region + "/" + s.Service + "/aws4_request"
+}
+
+/* parseSignedRequest 解析 Authorization 头或预签名查询参数 */
+func parseSignedRequest(r *http.Request) (*SignedRequest, error) {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		return parseAuthorizationHeader(r, auth)
+	}
+	q := r.URL.Query()
+	if q.Get("X-Amz-Algorithm") != "" {
+		return parsePresignedQuery(q)
+	}
+	return nil, ErrAccessDenied.WithMessage("缺少签名信息")
+}
+
+func parseAuthorizationHeader(r *http.Request, auth string) (*SignedRequest, error) {
+	if !strings.HasPrefix(auth, sigV4Algorithm+" ") {
+		return nil, ErrSignatureVersion
+	}
+	sr := &SignedRequest{}
+	for _, field := range strings.Split(strings.TrimPrefix(auth, sigV4Algorithm+" "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "Credential":
+			if err := sr.parseCredential(kv[1]); err != nil {
+				return nil, err
+			}
+		case "SignedHeaders":
+			sr.SignedHeaders = strings.Split(kv[1], ";")
+		case "Signature":
+			sr.Signature = kv[1]
+		}
+	}
+	if sr.AccessKey == "" || len(sr.SignedHeaders) == 0 || sr.Signature == "" {
+		return nil, ErrAuthorizationMalformed
+	}
+	sr.AmzDate = r.Header.Get("X-Amz-Date")
+	if sr.AmzDate == "" {
+		return nil, ErrAccessDenied.WithMessage("缺少 X-Amz-Date 请求头")
+	}
+	sr.PayloadHash = r.Header.Get("X-Amz-Content-Sha256")
+	if sr.PayloadHash == "" {
+		return nil, ErrAccessDenied.WithMessage("缺少 X-Amz-Content-Sha256 请求头")
+	}
+	return sr, nil
+}
+
+func parsePresignedQuery(q url.Values) (*SignedRequest, error) {
+	if q.Get("X-Amz-Algorithm") != sigV4Algorithm {
+		return nil, ErrSignatureVersion
+	}
+	sr := &SignedRequest{Presigned: true, PayloadHash: PayloadUnsigned}
+	if err := sr.parseCredential(q.Get("X-Amz-Credential")); err != nil {
+		return nil, err
+	}
+	sr.SignedHeaders = strings.Split(q.Get("X-Amz-SignedHeaders"), ";")
+	sr.Signature = q.Get("X-Amz-Signature")
+	sr.AmzDate = q.Get("X-Amz-Date")
+	expires, err := strconv.ParseInt(q.Get("X-Amz-Expires"), 10, 64)
+	if err != nil || expires <= 0 || expires > maxPresignExpiry {
+		return nil, ErrAuthorizationQueryParameters
+	}
+	sr.Expires = expires
+	if sr.Signature == "" || sr.AmzDate == "" {
+		return nil, ErrAuthorizationQueryParameters
+	}
+	return sr, nil
+}
+
+func (s *SignedRequest) parseCredential(value string) error {
+	parts := strings.Split(value, "/")
+	if len(parts) != 5 || parts[4] != "aws4_request" {
+		return ErrAuthorizationMalformed
+	}
+	s.AccessKey, s.Date, s.Region, s.Service = parts[0], parts[1], parts[2], parts[3]
+	return nil
+}
+
+/* checkTime 校验请求时间：普通请求允许15分钟偏差，预签名请求检查过期时间 */
+func (s *SignedRequest) checkTime(now time.Time) error {
+	t, err := time.Parse(amzDateLayout, s.AmzDate)
+	if err != nil || !strings.HasPrefix(s.AmzDate, s.Date) {
+		return ErrAccessDenied.WithMessage("请求时间格式无效")
+	}
+	if s.Presigned {
+		if now.Before(t.Add(-maxClockSkew)) {
+			return ErrRequestTimeTooSkewed
+		}
+		if now.After(t.Add(time.Duration(s.Expires) * time.Second)) {
+			return ErrAccessDenied.WithMessage("签名链接已过期")
+		}
+		return nil
+	}
+	if d := now.Sub(t); d > maxClockSkew || d < -maxClockSkew {
+		return ErrRequestTimeTooSkewed
+	}
+	return nil
+}
+
+/* verify 使用给定密钥重算签名并比较 */
+func (s *SignedRequest) verify(r *http.Request, secret string) error {
+	canonical := canonicalRequest(r, s.SignedHeaders, s.PayloadHash, s.Presigned)
+	expected := signString(secret, s.Date, s.Region, s.Service, stringToSign(s.AmzDate, s.Scope(), canonical))
+	if !hmac.Equal([]byte(expected), []byte(s.Signature)) {
+		return ErrSignatureDoesNotMatch
+	}
+	return nil
+}
+
+func canonicalRequest(r *http.Request, signedHeaders []string, payloadHash string, presigned bool) string {
+	var b strings.Builder
+	b.WriteString(r.Method)
+	b.WriteByte('\n')
+	b.WriteString(uriEncode(r.URL.Path, false))
+	b.WriteByte('\n')
+	b.WriteString(canonicalQuery(r.URL.Query(), presigned))
+	b.WriteByte('\n')
+	for _, name := range signedHeaders {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(canonicalHeaderValue(r, name))
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	b.WriteString(strings.Join(signedHeaders, ";"))
+	b.WriteByte('\n')
+	b.WriteString(payloadHash)
+	return b.String()
+}
+
+func canonicalQuery(q url.Values, presigned bool) string {
+	pairs := make([]string, 0, len(q))
+	for key, values := range q {
+		if presigned && key == "X-Amz-Signature" {
+			continue
+		}
+		for _, v := range values {
+			pairs = append(pairs, uriEncode(key, true)+"="+uriEncode(v, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+func canonicalHeaderValue(r *http.Request, name string) string {
+	if name == "host" {
+		return r.Host
+	}
+	values := r.Header.Values(name)
+	if name == "content-length" && len(values) == 0 && r.ContentLength >= 0 {
+		return strconv.FormatInt(r.ContentLength, 10)
+	}
+	trimmed := make([]string, len(values))
+	for i, v := range values {
+		trimmed[i] = strings.Join(strings.Fields(v), " ")
+	}
+	return strings.Join(trimmed, ",")
+}
+
+func stringToSign(amzDate, scope, canonical string) string {
+	return sigV4Algorithm + "\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonical))
+}
+
+func signString(secret, date, region, service, toSign string) string {
+	return hex.EncodeToString(hmacSHA256(signingKey(secret, date, region, service), toSign))
+}
+
+func signingKey(secret, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+/* uriEncode 按 SigV4 规则编码：仅保留非保留字符，encodeSlash 为 false 时保留路径分隔符 */
+func uriEncode(s string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&15])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package search
+
+/* Hybrid search: structured filters are pushed down to Qdrant payload filters and re-applied in SQL before blending. */
+
+import (
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/vector"
+)
+
+// 向量候选数量与页码无关，保证翻页时候选集合与排序一致
+const hybridVectorCandidates = 300
+
+/* HybridSearch 混合检索：向量召回在 Qdrant 内按同样的筛选条件过滤，再与关键词得分融合排序；向量搜索不可用时退化为全文检索 */
+func HybridSearch(p Params, threshold float32) (*Result, bool, error) {
+	vectorUsed := false
+	if p.Query != "" {
+		if engine := vector.GetEngine(); engine != nil && engine.IsEnabled() {
+			filter, ok := vectorFilter(p)
+			if ok {
+				results, err := engine.SearchFilesFiltered(p.Query, hybridVectorCandidates, filter, threshold)
+				if err != nil {
+					logger.Warn("混合检索向量召回失败，仅使用关键词: %v", err)
+				} else {
+					p.VectorScores = make(map[string]float64, len(results))
+					for _, r := range results {
+						p.VectorScores[r.FileID] = float64(r.Similarity)
+					}
+					vectorUsed = true
+				}
+			}
+		}
+	}
+
+	result, err := Search(p)
+	if err != nil {
+		return nil, false, err
+	}
+	return result, vectorUsed, nil
+}
+
+// vectorFilter 将检索参数转换为向量过滤条件；指定的标签不存在时返回 false，此时向量召回必然为空
+func vectorFilter(p Params) (*vector.SearchFilter, bool) {
+	filter := &vector.SearchFilter{
+		UserID:       p.UserID,
+		CategoryIDs:  p.CategoryIDs,
+		Formats:      p.Formats,
+		AccessLevels: p.AccessLevels,
+		NSFW:         p.NSFW,
+		TakenFrom:    p.TakenFrom,
+		TakenTo:      p.TakenTo,
+	}
+	if p.FolderID != "" {
+		filter.FolderIDs = descendantFolderIDs(p.UserID, p.FolderID)
+	}
+	if len(p.Tags) > 0 {
+		if err := database.DB.Model(&models.GlobalTag{}).Where("name IN ?", p.Tags).Pluck("id", &filter.TagIDs).Error; err != nil || len(filter.TagIDs) < len(p.Tags) {
+			return nil, false
+		}
+	}
+	return filter, true
+}
+
+// refreshVectorPayloads 索引重建后同步向量 payload，使标签、分类等筛选条件在 Qdrant 中保持最新
+func refreshVectorPayloads(fileIDs []string) {
+	engine := vector.GetEngine()
+	if engine == nil || len(fileIDs) == 0 {
+		return
+	}
+	if err := engine.RefreshPayloads(fileIDs); err != nil {
+		logger.Warn("同步向量payload失败: %v", err)
+	}
+}
@@ -0,0 +1,251 @@
+package search
+
+/* Index maintenance: builds per-file term rows from names, descriptions, AI info, tags, category and EXIF. */
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	statusPendingDeletion = "pending_deletion"
+	maxTermsPerFile       = 2000
+	maxDocumentTextBytes  = 200 * 1024 // 文档正文只索引开头部分，避免超大文档拖慢建索引
+)
+
+// 字段权重：文件名与标签最能代表用户意图，长文本权重最低
+const (
+	weightName        = 3.0
+	weightTag         = 3.0
+	weightCategory    = 2.0
+	weightDescription = 2.0
+	weightAIContent   = 1.5
+	weightEXIF        = 1.5
+	weightDocument    = 1.0
+)
+
+var dirtyFiles sync.Map // fileID -> struct{}
+
+/* MarkDirty 标记文件需要重建索引，由定时任务批量处理 */
+func MarkDirty(fileIDs ...string) {
+	for _, id := range fileIDs {
+		if id != "" {
+			dirtyFiles.Store(id, struct{}{})
+		}
+	}
+}
+
+/* RemoveFiles 从索引中移除文件 */
+func RemoveFiles(fileIDs ...string) error {
+	if len(fileIDs) == 0 {
+		return nil
+	}
+	for _, id := range fileIDs {
+		dirtyFiles.Delete(id)
+	}
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("file_id IN ?", fileIDs).Delete(&models.FileSearchTerm{}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除索引词项失败")
+		}
+		if err := tx.Where("file_id IN ?", fileIDs).Delete(&models.FileSearchDocument{}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除索引文档失败")
+		}
+		return nil
+	})
+}
+
+/* IndexFile 重建单个文件的索引，文件不存在或已删除时移除索引 */
+func IndexFile(fileID string) error {
+	db := database.DB
+
+	var file models.File
+	if err := db.Where("id = ?", fileID).Take(&file).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return RemoveFiles(fileID)
+		}
+		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	}
+	if file.Status == statusPendingDeletion {
+		return RemoveFiles(fileID)
+	}
+
+	fields := collectFields(db, &file)
+	weights := termWeights(fields)
+
+	terms := make([]models.FileSearchTerm, 0, len(weights))
+	for term, weight := range weights {
+		terms = append(terms, models.FileSearchTerm{UserID: file.UserID, Term: term, FileID: file.ID, Weight: weight})
+	}
+	if len(terms) > maxTermsPerFile {
+		sort.Slice(terms, func(i, j int) bool { return terms[i].Weight > terms[j].Weight })
+		terms = terms[:maxTermsPerFile]
+	}
+
+	doc := models.FileSearchDocument{FileID: file.ID, UserID: file.UserID, TermCount: len(terms), IndexedAt: time.Now()}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("file_id = ?", file.ID).Delete(&models.FileSearchTerm{}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除旧索引失败")
+		}
+		if len(terms) > 0 {
+			if err := tx.CreateInBatches(&terms, 200).Error; err != nil {
+				return errors.Wrap(err, errors.CodeDBCreateFailed, "写入索引失败")
+			}
+		}
+		if err := tx.Save(&doc).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBUpdateFailed, "更新索引文档失败")
+		}
+		return nil
+	})
+}
+
+/* SyncIndex 增量同步索引：处理标记的文件、未建索引或内容更新过的文件，同步向量 payload，并清理已删除文件的索引 */
+func SyncIndex(limit int) (int, error) {
+	db := database.DB
+	if db == nil {
+		return 0, nil
+	}
+
+	var ids []string
+	dirtyFiles.Range(func(key, _ interface{}) bool {
+		ids = append(ids, key.(string))
+		dirtyFiles.Delete(key)
+		return len(ids) < limit
+	})
+
+	if remaining := limit - len(ids); remaining > 0 {
+		var stale []string
+		err := db.Table("file").
+			Select("file.id").
+			Joins("LEFT JOIN file_search_document AS d ON d.file_id = file.id").
+			Joins("LEFT JOIN file_ai_info ON file_ai_info.file_id = file.id").
+			Joins("LEFT JOIN file_exif ON file_exif.file_id = file.id").
+			Where("file.status <> ?", statusPendingDeletion).
+			Where("d.file_id IS NULL OR file.updated_at > d.indexed_at OR file_ai_info.updated_at > d.indexed_at OR file_exif.updated_at > d.indexed_at").
+			Limit(remaining).
+			Pluck("file.id", &stale).Error
+		if err != nil {
+			return 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询待索引文件失败")
+		}
+		ids = append(ids, stale...)
+	}
+
+	var indexed []string
+	for _, id := range ids {
+		if err := IndexFile(id); err != nil {
+			logger.Warn("文件 %s 建立全文索引失败: %v", id, err)
+			continue
+		}
+		indexed = append(indexed, id)
+	}
+	refreshVectorPayloads(indexed)
+
+	var orphans []string
+	err := db.Table("file_search_document AS d").
+		Select("d.file_id").
+		Joins("LEFT JOIN file ON file.id = d.file_id").
+		Where("file.id IS NULL OR file.status = ?", statusPendingDeletion).
+		Limit(limit).
+		Pluck("d.file_id", &orphans).Error
+	if err != nil {
+		return len(indexed), errors.Wrap(err, errors.CodeDBQueryFailed, "查询失效索引失败")
+	}
+	if err := RemoveFiles(orphans...); err != nil {
+		return len(indexed), err
+	}
+	return len(indexed), nil
+}
+
+type indexField struct {
+	weight float64
+	text   string
+}
+
+// collectFields 汇总文件的可检索文本，关联数据缺失时跳过
+func collectFields(db *gorm.DB, file *models.File) []indexField {
+	fields := []indexField{
+		{weightName, file.OriginalName},
+		{weightName, file.DisplayName},
+		{weightDescription, file.Description},
+	}
+
+	var aiInfo models.FileAIInfo
+	if err := db.Where("file_id = ?", file.ID).Take(&aiInfo).Error; err == nil {
+		fields = append(fields,
+			indexField{weightDescription, aiInfo.Description},
+			indexField{weightAIContent, aiInfo.SearchContent},
+			indexField{weightAIContent, jsonStrings(aiInfo.SemanticKeywords)},
+			indexField{weightDocument, aiInfo.DocumentSummary},
+			indexField{weightDocument, truncateText(aiInfo.DocumentText, maxDocumentTextBytes)},
+		)
+	}
+
+	var tags []string
+	db.Table("file_global_tag_relation AS r").
+		Joins("JOIN global_tag AS t ON t.id = r.tag_id").
+		Where("r.file_id = ?", file.ID).
+		Pluck("t.name", &tags)
+	fields = append(fields, indexField{weightTag, strings.Join(tags, " ")})
+
+	if file.CategoryID != nil {
+		var category models.FileCategory
+		if err := db.Select("name").Where("id = ?", *file.CategoryID).Take(&category).Error; err == nil {
+			fields = append(fields, indexField{weightCategory, category.Name})
+		}
+	}
+
+	var exif models.FileEXIF
+	if err := db.Where("file_id = ?", file.ID).Take(&exif).Error; err == nil {
+		fields = append(fields, indexField{weightEXIF, strings.Join([]string{
+			exif.Make, exif.Model, exif.LensMake, exif.LensModel, exif.Software,
+			exif.Artist, exif.Copyright, exif.ImageDescription,
+		}, " ")})
+	}
+	return fields
+}
+
+// termWeights 计算每个词项的权重：各字段权重乘以 1+ln(词频) 后累加
+func termWeights(fields []indexField) map[string]float64 {
+	weights := make(map[string]float64)
+	for _, f := range fields {
+		if f.text == "" {
+			continue
+		}
+		counts := make(map[string]int)
+		for _, t := range Tokenize(f.text) {
+			counts[t]++
+		}
+		for t, n := range counts {
+			weights[t] += f.weight * (1 + math.Log(float64(n)))
+		}
+	}
+	return weights
+}
+
+func jsonStrings(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var values []string
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return ""
+	}
+	return strings.Join(values, " ")
+}
+
+func truncateText(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+	return strings.ToValidUTF8(text[:maxBytes], "")
+}
@@ -0,0 +1,429 @@
+package search
+
+/* Full-text query with facet filters and facet counts; optionally blended with vector similarity. */
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxQueryTerms    = 32
+	maxCandidates    = 1000
+	maxFacetBuckets  = 30
+	defaultPageSize  = 20
+	maxPageSize      = 100
+	defaultVectorMix = 0.5
+)
+
+/* Params 全文检索参数，Query 为空时只按筛选条件列出文件 */
+type Params struct {
+	UserID         uint
+	Query          string
+	Formats        []string
+	Resolutions    []string
+	DominantColors []string
+	CameraModels   []string
+	Tags           []string
+	CategoryIDs    []uint
+	FolderID       string // 限定文件夹及其子文件夹
+	AccessLevels   []string
+	NSFW           *bool
+	TakenFrom      *time.Time
+	TakenTo        *time.Time
+	Page           int
+	Size           int
+
+	VectorScores map[string]float64 // 向量检索的相似度，非空时与关键词得分融合
+	VectorWeight float64            // 向量得分所占比例，0 表示使用默认值
+}
+
+/* Hit 单条检索结果 */
+type Hit struct {
+	FileID       string  `json:"file_id"`
+	Score        float64 `json:"score"`
+	KeywordScore float64 `json:"keyword_score"`
+	VectorScore  float64 `json:"vector_score"`
+	MatchedTerms int     `json:"matched_terms"`
+}
+
+/* FacetBucket 分面统计项 */
+type FacetBucket struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int64  `json:"count"`
+}
+
+/* Facets 当前结果集上的分面统计 */
+type Facets struct {
+	Formats        []FacetBucket `json:"formats"`
+	Resolutions    []FacetBucket `json:"resolutions"`
+	DominantColors []FacetBucket `json:"dominant_colors"`
+	CameraModels   []FacetBucket `json:"camera_models"`
+	Years          []FacetBucket `json:"years"`
+	Tags           []FacetBucket `json:"tags"`
+	Categories     []FacetBucket `json:"categories"`
+}
+
+/* Result 检索结果，Hits 为当前页 */
+type Result struct {
+	Hits   []Hit
+	Total  int64
+	Page   int
+	Size   int
+	Terms  []string
+	Facets Facets
+}
+
+/* Search 执行全文检索：先按倒排表召回候选并打分，再应用分面筛选、分页并统计分面 */
+func Search(p Params) (*Result, error) {
+	if p.Page <= 0 {
+		p.Page = 1
+	}
+	if p.Size <= 0 {
+		p.Size = defaultPageSize
+	}
+	if p.Size > maxPageSize {
+		p.Size = maxPageSize
+	}
+
+	result := &Result{Page: p.Page, Size: p.Size, Terms: QueryTerms(p.Query, maxQueryTerms)}
+	ranked := len(result.Terms) > 0 || len(p.VectorScores) > 0
+
+	var candidateIDs []string
+	var hits []Hit
+	if ranked {
+		keyword, err := keywordHits(p.UserID, result.Terms)
+		if err != nil {
+			return nil, err
+		}
+		hits = MergeScores(keyword, p.VectorScores, p.VectorWeight)
+		if len(hits) == 0 {
+			return result, nil
+		}
+		candidateIDs = make([]string, len(hits))
+		for i, h := range hits {
+			candidateIDs[i] = h.FileID
+		}
+	}
+
+	scope := func() *gorm.DB {
+		q := filteredFiles(p)
+		if ranked {
+			q = q.Where("file.id IN ?", candidateIDs)
+		}
+		return q
+	}
+
+	if ranked {
+		var matched []string
+		if err := scope().Pluck("file.id", &matched).Error; err != nil {
+			return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "筛选检索结果失败")
+		}
+		keep := make(map[string]bool, len(matched))
+		for _, id := range matched {
+			keep[id] = true
+		}
+		filtered := hits[:0]
+		for _, h := range hits {
+			if keep[h.FileID] {
+				filtered = append(filtered, h)
+			}
+		}
+		result.Total = int64(len(filtered))
+		start := (p.Page - 1) * p.Size
+		if start < len(filtered) {
+			end := start + p.Size
+			if end > len(filtered) {
+				end = len(filtered)
+			}
+			result.Hits = filtered[start:end]
+		}
+	} else {
+		if err := scope().Count(&result.Total).Error; err != nil {
+			return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "统计文件数量失败")
+		}
+		var ids []string
+		if err := scope().Order("file.created_at DESC").Offset((p.Page-1)*p.Size).Limit(p.Size).Pluck("file.id", &ids).Error; err != nil {
+			return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+		}
+		for _, id := range ids {
+			result.Hits = append(result.Hits, Hit{FileID: id})
+		}
+	}
+
+	if result.Total > 0 {
+		facets, err := facetCounts(scope)
+		if err != nil {
+			return nil, err
+		}
+		result.Facets = facets
+	}
+	return result, nil
+}
+
+type termMatch struct {
+	FileID  string
+	Matched int
+	Score   float64
+}
+
+// keywordHits 在倒排表中召回候选文件，按 BM25 风格的 idf 加权求和，并按命中词项比例折算
+func keywordHits(userID uint, terms []string) ([]Hit, error) {
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	db := database.DB
+
+	var docCount int64
+	if err := db.Table("file_search_document").Where("user_id = ?", userID).Count(&docCount).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "统计索引文档失败")
+	}
+	if docCount == 0 {
+		return nil, nil
+	}
+
+	var freqs []struct {
+		Term string
+		DF   int64 `gorm:"column:df"`
+	}
+	if err := db.Table("file_search_term").
+		Select("term, COUNT(*) AS df").
+		Where("user_id = ? AND term IN ?", userID, terms).
+		Group("term").
+		Scan(&freqs).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "统计词频失败")
+	}
+	if len(freqs) < minShouldMatch(len(terms)) {
+		return nil, nil
+	}
+
+	var idfCase strings.Builder
+	args := make([]interface{}, 0, len(freqs)*2+4)
+	idfCase.WriteString("SUM(weight * CASE term")
+	for _, f := range freqs {
+		idfCase.WriteString(" WHEN ? THEN ?")
+		args = append(args, f.Term, inverseDocumentFrequency(docCount, f.DF))
+	}
+	idfCase.WriteString(" ELSE 0 END) AS score")
+
+	var matches []termMatch
+	err := db.Table("file_search_term").
+		Select("file_id, COUNT(*) AS matched, "+idfCase.String(), args...).
+		Where("user_id = ? AND term IN ?", userID, terms).
+		Group("file_id").
+		Having("COUNT(*) >= ?", minShouldMatch(len(terms))).
+		Order("matched DESC, score DESC").
+		Limit(maxCandidates).
+		Scan(&matches).Error
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "全文检索失败")
+	}
+
+	hits := make([]Hit, len(matches))
+	for i, m := range matches {
+		score := m.Score * float64(m.Matched) / float64(len(terms))
+		hits[i] = Hit{FileID: m.FileID, Score: score, KeywordScore: score, MatchedTerms: m.Matched}
+	}
+	return hits, nil
+}
+
+func inverseDocumentFrequency(docCount, df int64) float64 {
+	return math.Log(1 + (float64(docCount)-float64(df)+0.5)/(float64(df)+0.5))
+}
+
+/* MergeScores 融合关键词与向量得分：关键词得分按最高分归一化到 0~1 后与相似度加权求和，按综合得分降序 */
+func MergeScores(keyword []Hit, vectorScores map[string]float64, vectorWeight float64) []Hit {
+	if vectorWeight <= 0 || vectorWeight > 1 {
+		vectorWeight = defaultVectorMix
+	}
+	if len(vectorScores) == 0 {
+		vectorWeight = 0
+	} else if len(keyword) == 0 {
+		vectorWeight = 1
+	}
+
+	maxKeyword := 0.0
+	for _, h := range keyword {
+		if h.KeywordScore > maxKeyword {
+			maxKeyword = h.KeywordScore
+		}
+	}
+
+	merged := make(map[string]*Hit, len(keyword)+len(vectorScores))
+	for i := range keyword {
+		h := keyword[i]
+		merged[h.FileID] = &h
+	}
+	for id, sim := range vectorScores {
+		if h, ok := merged[id]; ok {
+			h.VectorScore = sim
+		} else {
+			merged[id] = &Hit{FileID: id, VectorScore: sim}
+		}
+	}
+
+	hits := make([]Hit, 0, len(merged))
+	for _, h := range merged {
+		normalized := 0.0
+		if maxKeyword > 0 {
+			normalized = h.KeywordScore / maxKeyword
+		}
+		h.Score = (1-vectorWeight)*normalized + vectorWeight*h.VectorScore
+		hits = append(hits, *h)
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].FileID < hits[j].FileID
+	})
+	return hits
+}
+
+// filteredFiles 构造带分面筛选的文件查询，每次调用返回新的查询对象
+func filteredFiles(p Params) *gorm.DB {
+	q := database.DB.Table("file").
+		Joins("LEFT JOIN file_ai_info ON file_ai_info.file_id = file.id").
+		Joins("LEFT JOIN file_exif ON file_exif.file_id = file.id").
+		Where("file.user_id = ?", p.UserID).
+		Where("file.status <> ?", statusPendingDeletion)
+
+	if len(p.Formats) > 0 {
+		formats := make([]string, len(p.Formats))
+		for i, f := range p.Formats {
+			formats[i] = strings.ToLower(strings.TrimPrefix(f, "."))
+		}
+		q = q.Where("LOWER(file.format) IN ?", formats)
+	}
+	if len(p.Resolutions) > 0 {
+		q = q.Where("file.resolution IN ?", p.Resolutions)
+	}
+	if len(p.DominantColors) > 0 {
+		q = q.Where("file_ai_info.dominant_color IN ?", colorVariants(p.DominantColors))
+	}
+	if len(p.CameraModels) > 0 {
+		q = q.Where("file_exif.model IN ?", p.CameraModels)
+	}
+	if p.TakenFrom != nil {
+		q = q.Where("file_exif.date_time_original >= ?", *p.TakenFrom)
+	}
+	if p.TakenTo != nil {
+		q = q.Where("file_exif.date_time_original < ?", *p.TakenTo)
+	}
+	if len(p.Tags) > 0 {
+		sub := database.DB.Table("file_global_tag_relation AS r").
+			Select("r.file_id").
+			Joins("JOIN global_tag AS t ON t.id = r.tag_id").
+			Where("r.user_id = ? AND t.name IN ?", p.UserID, p.Tags).
+			Group("r.file_id").
+			Having("COUNT(DISTINCT t.id) = ?", len(p.Tags))
+		q = q.Where("file.id IN (?)", sub)
+	}
+	if len(p.CategoryIDs) > 0 {
+		q = q.Where("file.category_id IN ?", p.CategoryIDs)
+	}
+	if p.FolderID != "" {
+		q = q.Where("file.folder_id IN ?", descendantFolderIDs(p.UserID, p.FolderID))
+	}
+	if len(p.AccessLevels) > 0 {
+		q = q.Where("file.access_level IN ?", p.AccessLevels)
+	}
+	if p.NSFW != nil {
+		q = q.Where("file.nsfw = ?", *p.NSFW)
+	}
+	return q
+}
+
+// descendantFolderIDs 返回文件夹及其全部子文件夹ID
+func descendantFolderIDs(userID uint, rootID string) []string {
+	ids := []string{rootID}
+	for queue := []string{rootID}; len(queue) > 0; {
+		var children []string
+		if err := database.DB.Model(&models.Folder{}).Where("user_id = ? AND parent_id IN ?", userID, queue).Pluck("id", &children).Error; err != nil {
+			break
+		}
+		ids = append(ids, children...)
+		queue = children
+	}
+	return ids
+}
+
+// colorVariants 主色调在库中可能带或不带 #、大小写不一，各种写法都匹配
+func colorVariants(colors []string) []string {
+	all := make([]string, 0, len(colors)*4)
+	for _, c := range colors {
+		c = strings.TrimPrefix(strings.TrimSpace(c), "#")
+		lower, upper := strings.ToLower(c), strings.ToUpper(c)
+		all = append(all, lower, "#"+lower, upper, "#"+upper)
+	}
+	return all
+}
+
+func facetCounts(scope func() *gorm.DB) (Facets, error) {
+	var facets Facets
+
+	simple := []struct {
+		column string
+		dest   *[]FacetBucket
+	}{
+		{"file.format", &facets.Formats},
+		{"file.resolution", &facets.Resolutions},
+		{"file_ai_info.dominant_color", &facets.DominantColors},
+		{"file_exif.model", &facets.CameraModels},
+		{yearExpression("file_exif.date_time_original"), &facets.Years},
+	}
+	for _, f := range simple {
+		err := scope().
+			Select(f.column + " AS value, COUNT(*) AS count").
+			Where(f.column + " IS NOT NULL").
+			Where(f.column + " <> ''").
+			Group(f.column).
+			Order("count DESC").
+			Limit(maxFacetBuckets).
+			Scan(f.dest).Error
+		if err != nil {
+			return facets, errors.Wrap(err, errors.CodeDBQueryFailed, "统计分面失败")
+		}
+	}
+
+	err := scope().
+		Select("global_tag.name AS value, COUNT(*) AS count").
+		Joins("JOIN file_global_tag_relation ON file_global_tag_relation.file_id = file.id").
+		Joins("JOIN global_tag ON global_tag.id = file_global_tag_relation.tag_id").
+		Group("global_tag.name").
+		Order("count DESC").
+		Limit(maxFacetBuckets).
+		Scan(&facets.Tags).Error
+	if err != nil {
+		return facets, errors.Wrap(err, errors.CodeDBQueryFailed, "统计标签分面失败")
+	}
+
+	err = scope().
+		Select("file_category.id AS value, file_category.name AS label, COUNT(*) AS count").
+		Joins("JOIN file_category ON file_category.id = file.category_id").
+		Group("file_category.id, file_category.name").
+		Order("count DESC").
+		Limit(maxFacetBuckets).
+		Scan(&facets.Categories).Error
+	if err != nil {
+		return facets, errors.Wrap(err, errors.CodeDBQueryFailed, "统计分类分面失败")
+	}
+	return facets, nil
+}
+
+// yearExpression 按数据库方言提取年份
+func yearExpression(column string) string {
+	if database.DB.Dialector.Name() == "sqlite" {
+		return "substr(" + column + ", 1, 4)"
+	}
+	return "CAST(YEAR(" + column + ") AS CHAR)"
+}
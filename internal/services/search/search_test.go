@@ -0,0 +1,54 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"IMG_2023-Beach.JPG", []string{"img", "2023", "beach", "jpg"}},
+		{"海边日落", []string{"海边", "边日", "日落"}},
+		{"Sony A7 拍摄的猫", []string{"sony", "a7", "拍摄", "摄的", "的猫"}},
+		{"a 猫 b", []string{"猫"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+
+	if got := QueryTerms("cat Cat dog", 0); !reflect.DeepEqual(got, []string{"cat", "dog"}) {
+		t.Errorf("QueryTerms 应去重，实际 %v", got)
+	}
+}
+
+func TestMergeScores(t *testing.T) {
+	keyword := []Hit{
+		{FileID: "a", KeywordScore: 10},
+		{FileID: "b", KeywordScore: 5},
+	}
+
+	only := MergeScores(keyword, nil, 0)
+	if only[0].FileID != "a" || only[0].Score != 1 || only[1].Score != 0.5 {
+		t.Fatalf("仅关键词时应按最高分归一化，实际 %+v", only)
+	}
+
+	merged := MergeScores(keyword, map[string]float64{"b": 0.9, "c": 0.8}, 0.5)
+	order := []string{merged[0].FileID, merged[1].FileID, merged[2].FileID}
+	if !reflect.DeepEqual(order, []string{"b", "a", "c"}) {
+		t.Fatalf("融合排序错误: %v", order)
+	}
+	if merged[0].Score != 0.7 || merged[0].VectorScore != 0.9 {
+		t.Fatalf("融合得分错误: %+v", merged[0])
+	}
+
+	vectorOnly := MergeScores(nil, map[string]float64{"c": 0.8}, 0.3)
+	if len(vectorOnly) != 1 || vectorOnly[0].Score != 0.8 {
+		t.Fatalf("无关键词结果时应直接使用相似度，实际 %+v", vectorOnly)
+	}
+}
@@ -0,0 +1,93 @@
+package search
+
+/* Tokenizer for the built-in full-text index: latin words plus CJK bigrams. */
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const maxTermLength = 64 // 与 FileSearchTerm.Term 字段长度一致
+
+/* Tokenize 将文本切分为索引词项：字母数字按单词切分并转小写，中日韩文字按二元组切分 */
+func Tokenize(text string) []string {
+	var tokens []string
+	var word, cjk []rune
+
+	flushWord := func() {
+		if len(word) >= 2 {
+			tokens = append(tokens, truncateTerm(strings.ToLower(string(word))))
+		}
+		word = word[:0]
+	}
+	flushCJK := func() {
+		switch {
+		case len(cjk) == 1:
+			tokens = append(tokens, string(cjk))
+		case len(cjk) > 1:
+			for i := 0; i+1 < len(cjk); i++ {
+				tokens = append(tokens, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return tokens
+}
+
+/* QueryTerms 切分搜索词并去重，保留出现顺序 */
+func QueryTerms(query string, limit int) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, t := range Tokenize(query) {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		terms = append(terms, t)
+		if limit > 0 && len(terms) >= limit {
+			break
+		}
+	}
+	return terms
+}
+
+// minShouldMatch 最少需要命中的词项数：二元组切分会产生跨词组合，允许约三分之一未命中
+func minShouldMatch(n int) int {
+	if n <= 2 {
+		return n
+	}
+	return n - n/3
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+func truncateTerm(term string) string {
+	if len(term) <= maxTermLength {
+		return term
+	}
+	term = term[:maxTermLength]
+	for !utf8.ValidString(term) {
+		term = term[:len(term)-1]
+	}
+	return term
+}
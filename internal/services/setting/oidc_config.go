@@ -0,0 +1,89 @@
+package setting
+
+import (
+	"encoding/json"
+	"pixelpunk/internal/controllers/setting/dto"
+	"pixelpunk/pkg/common"
+	"regexp"
+	"strings"
+)
+
+// 内置提供方已占用的标识，避免与 user_identity 中的历史关联冲突
+var reservedOIDCSlugs = map[string]bool{"github": true, "google": true, "linuxdo": true}
+
+var oidcSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+/* GetOIDCProviderConfig 按标识获取已启用的 OIDC 提供方配置 */
+func GetOIDCProviderConfig(slug string) (*dto.OIDCProviderConfig, bool) {
+	config, err := GetOAuthConfig()
+	if err != nil {
+		return nil, false
+	}
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	for i := range config.OIDC {
+		if config.OIDC[i].Slug == slug && config.OIDC[i].Enabled {
+			return &config.OIDC[i], true
+		}
+	}
+	return nil, false
+}
+
+// parseOIDCProviders 解析 oidc_providers 设置并补全默认值，跳过标识非法或重复的条目
+func parseOIDCProviders(raw interface{}) []dto.OIDCProviderConfig {
+	providers := []dto.OIDCProviderConfig{}
+	if raw == nil {
+		return providers
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return providers
+	}
+	var items []dto.OIDCProviderConfig
+	if err := json.Unmarshal(data, &items); err != nil {
+		return providers
+	}
+
+	seen := make(map[string]bool)
+	for _, p := range items {
+		p.Slug = strings.ToLower(strings.TrimSpace(p.Slug))
+		if !oidcSlugPattern.MatchString(p.Slug) || reservedOIDCSlugs[p.Slug] || seen[p.Slug] {
+			continue
+		}
+		seen[p.Slug] = true
+
+		p.Issuer = strings.TrimRight(strings.TrimSpace(p.Issuer), "/")
+		if p.Name == "" {
+			p.Name = p.Slug
+		}
+		if p.Scope == "" {
+			p.Scope = "openid profile email"
+		}
+		if p.SubjectClaim == "" {
+			p.SubjectClaim = "sub"
+		}
+		if p.UsernameClaim == "" {
+			p.UsernameClaim = "preferred_username"
+		}
+		if p.EmailClaim == "" {
+			p.EmailClaim = "email"
+		}
+		if p.AvatarClaim == "" {
+			p.AvatarClaim = "picture"
+		}
+		if p.GroupsClaim == "" {
+			p.GroupsClaim = "groups"
+		}
+		// 通过组映射最多授予管理员，超级管理员只能在系统内指定
+		if p.DefaultRole != common.UserRoleAdmin {
+			p.DefaultRole = common.UserRoleUser
+		}
+		for group, role := range p.RoleMapping {
+			if role != common.UserRoleAdmin && role != common.UserRoleUser {
+				delete(p.RoleMapping, group)
+			}
+		}
+		providers = append(providers, p)
+	}
+	return providers
+}
@@ -0,0 +1,35 @@
+package setting
+
+import (
+	"testing"
+
+	"pixelpunk/pkg/common"
+)
+
+func TestParseOIDCProviders(t *testing.T) {
+	raw := []interface{}{
+		map[string]interface{}{"slug": "Authentik", "issuer": "https://auth.example.com/application/o/pixelpunk/", "enabled": true,
+			"role_mapping": map[string]interface{}{"admins": 2, "root": 1}},
+		map[string]interface{}{"slug": "github"},
+		map[string]interface{}{"slug": "bad slug"},
+		map[string]interface{}{"slug": "authentik"},
+	}
+
+	providers := parseOIDCProviders(raw)
+	if len(providers) != 1 {
+		t.Fatalf("expected 1 provider, got %d: %+v", len(providers), providers)
+	}
+	p := providers[0]
+	if p.Slug != "authentik" || p.Issuer != "https://auth.example.com/application/o/pixelpunk" {
+		t.Errorf("slug/issuer not normalized: %s %s", p.Slug, p.Issuer)
+	}
+	if p.Scope != "openid profile email" || p.SubjectClaim != "sub" || p.DefaultRole != common.UserRoleUser {
+		t.Errorf("defaults not applied: %+v", p)
+	}
+	if _, ok := p.RoleMapping["root"]; ok {
+		t.Error("mapping to super admin should be dropped")
+	}
+	if p.RoleMapping["admins"] != common.UserRoleAdmin {
+		t.Error("admin mapping should be kept")
+	}
+}
@@ -162,6 +162,16 @@ func GetGlobalSettingsGroups() (*dto.GlobalSettingsResponseDTO, error) {
 				result.OAuthProviders.LinuxdoEnabled = enabledBool
 			}
 		}
+		result.OAuthProviders.OIDC = []dto.OIDCProviderPublicDTO{}
+		for _, provider := range parseOIDCProviders(oauthSettings.Settings["oidc_providers"]) {
+			if provider.Enabled {
+				result.OAuthProviders.OIDC = append(result.OAuthProviders.OIDC, dto.OIDCProviderPublicDTO{
+					Slug: provider.Slug,
+					Name: provider.Name,
+					Icon: provider.Icon,
+				})
+			}
+		}
 	}
 
 	result.DeployMode = common.GetDeployMode()
@@ -440,6 +450,20 @@ func GetOAuthConfig() (*dto.OAuthConfigResponseDTO, error) {
 		}
 	}
 
+	// 通用 OIDC 提供方：启用代理时同样使用统一的代理配置
+	result.OIDC = parseOIDCProviders(oauthSettings.Settings["oidc_providers"])
+	for i := range result.OIDC {
+		if result.OIDC[i].ProxyEnabled {
+			result.OIDC[i].ProxyType = sharedProxyConfig.ProxyType
+			result.OIDC[i].ProxyHost = sharedProxyConfig.ProxyHost
+			result.OIDC[i].ProxyPort = sharedProxyConfig.ProxyPort
+			result.OIDC[i].ProxyUsername = sharedProxyConfig.ProxyUsername
+			result.OIDC[i].ProxyPassword = sharedProxyConfig.ProxyPassword
+			result.OIDC[i].ProxyDynamic = sharedProxyConfig.ProxyDynamic
+			result.OIDC[i].ProxyAPIURL = sharedProxyConfig.ProxyAPIURL
+		}
+	}
+
 	return result, nil
 }
 
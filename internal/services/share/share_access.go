@@ -4,6 +4,7 @@ import (
 	stderrors "errors"
 	"fmt"
 	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/album"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
@@ -15,7 +16,9 @@ import (
 	"gorm.io/gorm"
 )
 
-func GetShareForView(shareKey string, folderID string) (map[string]interface{}, error) {
+const maxSharedAlbumFiles = 1000
+
+func GetShareForView(shareKey string, folderID string, albumID string) (map[string]interface{}, error) {
 	share, err := GetShareByKey(shareKey)
 	if err != nil {
 		return nil, err
@@ -36,7 +39,7 @@ func GetShareForView(shareKey string, folderID string) (map[string]interface{},
 
 	if folderID != "" && folderID != "0" {
 		var folder models.Folder
-		if err := database.DB.Where("id = ? AND user_id = ?", folderID, share.UserID).First(&folder).Error; err != nil {
+		if err := database.DB.Scopes(contentScope(share)).Where("id = ?", folderID).First(&folder).Error; err != nil {
 			return nil, errors.New(errors.CodeFolderNotFound, "指定的文件夹不存在或无权访问")
 		}
 
@@ -76,7 +79,7 @@ func GetShareForView(shareKey string, folderID string) (map[string]interface{},
 					}
 
 					var parentFolder models.Folder
-					if err := database.DB.Where("id = ? AND user_id = ?", pathFolder.ParentID, share.UserID).First(&parentFolder).Error; err != nil {
+					if err := database.DB.Scopes(contentScope(share)).Where("id = ?", pathFolder.ParentID).First(&parentFolder).Error; err != nil {
 						break
 					}
 					pathFolder = parentFolder
@@ -94,145 +97,57 @@ func GetShareForView(shareKey string, folderID string) (map[string]interface{},
 
 	folders := []models.Folder{}
 	files := []map[string]interface{}{}
+	albums := []map[string]interface{}{}
+	var currentAlbum map[string]interface{}
 
-	if currentFolder != nil {
-		if err := database.DB.Where("parent_id = ? AND user_id = ?", folderID, share.UserID).Find(&folders).Error; err != nil {
+	if currentFolder == nil && albumID != "" {
+		sharedAlbum, err := getSharedAlbum(share, shareItems, albumID)
+		if err != nil {
+			return nil, err
+		}
+		entries, _, err := album.ListEntries(sharedAlbum, 1, maxSharedAlbumFiles)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			fileMap := sharedFileMap(e.File, shareKey)
+			fileMap["caption"] = e.Caption
+			files = append(files, fileMap)
+		}
+		currentAlbum = sharedAlbumMap(sharedAlbum, shareKey)
+	} else if currentFolder != nil {
+		if err := database.DB.Scopes(contentScope(share)).Where("parent_id = ?", folderID).Find(&folders).Error; err != nil {
 			return nil, err
 		}
 
 		var folderImages []models.File
-		if err := database.DB.Preload("AIInfo").Where("folder_id = ? AND user_id = ?", folderID, share.UserID).
+		if err := database.DB.Preload("AIInfo").Scopes(contentScope(share)).Where("folder_id = ?", folderID).
 			Where("status <> ?", "pending_deletion").
 			Find(&folderImages).Error; err != nil {
 			return nil, err
 		}
 
 		for _, file := range folderImages {
-			fullURL, fullThumbURL, _ := storage.GetFullURLs(file)
-
-			if fullURL != "" {
-				if strings.Contains(fullURL, "?") {
-					fullURL = fullURL + "&share=" + shareKey
-				} else {
-					fullURL = fullURL + "?share=" + shareKey
-				}
-			}
-
-			if fullThumbURL != "" {
-				if strings.Contains(fullThumbURL, "?") {
-					fullThumbURL = fullThumbURL + "&share=" + shareKey
-				} else {
-					fullThumbURL = fullThumbURL + "?share=" + shareKey
-				}
-			}
-
-			fileMap := map[string]interface{}{
-				"id":             file.ID,
-				"display_name":   file.DisplayName,
-				"description":    file.Description,
-				"url":            file.URL,
-				"thumb_url":      file.ThumbURL,
-				"size":           file.Size,
-				"size_formatted": file.SizeFormatted,
-				"width":          file.Width,
-				"height":         file.Height,
-				"format":         file.Format,
-				"mime":           file.Mime,
-				"created_at":     file.CreatedAt,
-				"updated_at":     file.UpdatedAt,
-				"full_url":       fullURL,            // 添加完整URL
-				"full_thumb_url": fullThumbURL,       // 添加完整缩略图URL
-				"resolution":     file.Resolution,    // 添加分辨率信息
-				"is_recommended": file.IsRecommended, // 添加推荐标记
-				"ai_info":        file.AIInfo,        // 添加AI信息
-			}
-
-			var tags []map[string]interface{}
-			var globalTags []models.GlobalTag
-			if err := database.DB.Model(&models.GlobalTag{}).
-				Joins("JOIN file_global_tag_relation ON file_global_tag_relation.tag_id = global_tag.id").
-				Where("file_global_tag_relation.file_id = ?", file.ID).
-				Find(&globalTags).Error; err == nil {
-				for _, globalTag := range globalTags {
-					tags = append(tags, map[string]interface{}{
-						"id":         globalTag.ID,
-						"name":       globalTag.Name,
-						"created_at": globalTag.CreatedAt,
-					})
-				}
-			}
-			fileMap["tags"] = tags
-
-			files = append(files, fileMap)
+			files = append(files, sharedFileMap(file, shareKey))
 		}
 	} else {
 		for _, item := range shareItems {
 			if item.ItemType == common.ShareItemTypeFolder {
 				var folder models.Folder
-				if err := database.DB.Where("id = ? AND user_id = ?", item.ItemID, share.UserID).First(&folder).Error; err == nil {
+				if err := database.DB.Scopes(contentScope(share)).Where("id = ?", item.ItemID).First(&folder).Error; err == nil {
 					folders = append(folders, folder)
 				}
 			} else if item.ItemType == common.ShareItemTypeFile {
 				var file models.File
-				if err := database.DB.Preload("AIInfo").Where("id = ? AND user_id = ?", item.ItemID, share.UserID).
+				if err := database.DB.Preload("AIInfo").Scopes(contentScope(share)).Where("id = ?", item.ItemID).
 					Where("status <> ?", "pending_deletion").
 					First(&file).Error; err == nil {
-					fullURL, fullThumbURL, _ := storage.GetFullURLs(file)
-
-					if fullURL != "" {
-						if strings.Contains(fullURL, "?") {
-							fullURL = fullURL + "&share=" + shareKey
-						} else {
-							fullURL = fullURL + "?share=" + shareKey
-						}
-					}
-
-					if fullThumbURL != "" {
-						if strings.Contains(fullThumbURL, "?") {
-							fullThumbURL = fullThumbURL + "&share=" + shareKey
-						} else {
-							fullThumbURL = fullThumbURL + "?share=" + shareKey
-						}
-					}
-
-					fileMap := map[string]interface{}{
-						"id":             file.ID,
-						"display_name":   file.DisplayName,
-						"description":    file.Description,
-						"url":            file.URL,
-						"thumb_url":      file.ThumbURL,
-						"size":           file.Size,
-						"size_formatted": file.SizeFormatted,
-						"width":          file.Width,
-						"height":         file.Height,
-						"format":         file.Format,
-						"mime":           file.Mime,
-						"created_at":     file.CreatedAt,
-						"updated_at":     file.UpdatedAt,
-						"full_url":       fullURL,            // 添加完整URL
-						"full_thumb_url": fullThumbURL,       // 添加完整缩略图URL
-						"resolution":     file.Resolution,    // 添加分辨率信息
-						"is_recommended": file.IsRecommended, // 添加推荐标记
-						"ai_info":        file.AIInfo,        // 添加AI信息
-					}
-
-					var tags []map[string]interface{}
-					var globalTags []models.GlobalTag
-					if err := database.DB.Model(&models.GlobalTag{}).
-						Joins("JOIN file_global_tag_relation ON file_global_tag_relation.tag_id = global_tag.id").
-						Where("file_global_tag_relation.file_id = ?", file.ID).
-						Find(&globalTags).Error; err == nil {
-						for _, globalTag := range globalTags {
-							tags = append(tags, map[string]interface{}{
-								"id":         globalTag.ID,
-								"name":       globalTag.Name,
-								"created_at": globalTag.CreatedAt,
-							})
-						}
-					}
-					fileMap["tags"] = tags
-
-					files = append(files, fileMap)
+					files = append(files, sharedFileMap(file, shareKey))
+				}
+			} else if item.ItemType == common.ShareItemTypeAlbum {
+				var a models.Album
+				if err := database.DB.Where("id = ? AND user_id = ?", item.ItemID, share.UserID).First(&a).Error; err == nil {
+					albums = append(albums, sharedAlbumMap(&a, shareKey))
 				}
 			}
 		}
@@ -251,20 +166,121 @@ func GetShareForView(shareKey string, folderID string) (map[string]interface{},
 			"has_password":           share.Password != "",
 			"collect_visitor_info":   share.CollectVisitorInfo,
 			"notification_on_access": share.NotificationOnAccess,
+			"share_type":             share.ShareType,
 		},
 		"user": map[string]interface{}{
 			"username": user.Username,
 			"avatar":   user.Avatar,
 		},
 		"folders":        folders,
+		"albums":         albums,
 		"files":          files,
 		"current_folder": currentFolder,
+		"current_album":  currentAlbum,
 		"parent_id":      parentFolderID,
 	}
+	if share.IsUploadShare() {
+		result["upload"] = uploadInfo(&share)
+	}
 
 	return result, nil
 }
 
+// sharedFileMap 构建分享页展示的文件信息，文件地址附带分享标识用于访问校验
+func sharedFileMap(file models.File, shareKey string) map[string]interface{} {
+	fullURL, fullThumbURL, _ := storage.GetFullURLs(file)
+
+	if fullURL != "" {
+		if strings.Contains(fullURL, "?") {
+			fullURL = fullURL + "&share=" + shareKey
+		} else {
+			fullURL = fullURL + "?share=" + shareKey
+		}
+	}
+
+	if fullThumbURL != "" {
+		if strings.Contains(fullThumbURL, "?") {
+			fullThumbURL = fullThumbURL + "&share=" + shareKey
+		} else {
+			fullThumbURL = fullThumbURL + "?share=" + shareKey
+		}
+	}
+
+	fileMap := map[string]interface{}{
+		"id":             file.ID,
+		"display_name":   file.DisplayName,
+		"description":    file.Description,
+		"url":            file.URL,
+		"thumb_url":      file.ThumbURL,
+		"size":           file.Size,
+		"size_formatted": file.SizeFormatted,
+		"width":          file.Width,
+		"height":         file.Height,
+		"format":         file.Format,
+		"mime":           file.Mime,
+		"created_at":     file.CreatedAt,
+		"updated_at":     file.UpdatedAt,
+		"full_url":       fullURL,            // 添加完整URL
+		"full_thumb_url": fullThumbURL,       // 添加完整缩略图URL
+		"resolution":     file.Resolution,    // 添加分辨率信息
+		"is_recommended": file.IsRecommended, // 添加推荐标记
+		"ai_info":        file.AIInfo,        // 添加AI信息
+	}
+
+	var tags []map[string]interface{}
+	var globalTags []models.GlobalTag
+	if err := database.DB.Model(&models.GlobalTag{}).
+		Joins("JOIN file_global_tag_relation ON file_global_tag_relation.tag_id = global_tag.id").
+		Where("file_global_tag_relation.file_id = ?", file.ID).
+		Find(&globalTags).Error; err == nil {
+		for _, globalTag := range globalTags {
+			tags = append(tags, map[string]interface{}{
+				"id":         globalTag.ID,
+				"name":       globalTag.Name,
+				"created_at": globalTag.CreatedAt,
+			})
+		}
+	}
+	fileMap["tags"] = tags
+
+	return fileMap
+}
+
+// getSharedAlbum 校验相册属于该分享，且相册仍归分享者所有
+func getSharedAlbum(share models.Share, shareItems []models.ShareItem, albumID string) (*models.Album, error) {
+	inShare := false
+	for _, item := range shareItems {
+		if item.ItemType == common.ShareItemTypeAlbum && item.ItemID == albumID {
+			inShare = true
+			break
+		}
+	}
+	if !inShare {
+		return nil, errors.New(errors.CodeValidationFailed, "该相册不包含在分享内容中")
+	}
+
+	var a models.Album
+	if err := database.DB.Where("id = ? AND user_id = ?", albumID, share.UserID).First(&a).Error; err != nil {
+		return nil, errors.New(errors.CodeNotFound, "指定的相册不存在或无权访问")
+	}
+	return &a, nil
+}
+
+func sharedAlbumMap(a *models.Album, shareKey string) map[string]interface{} {
+	result := map[string]interface{}{
+		"id":          a.ID,
+		"title":       a.Title,
+		"description": a.Description,
+		"layout":      a.Layout,
+		"file_count":  a.FileCount,
+		"created_at":  a.CreatedAt,
+	}
+	if cover := album.CoverFile(a); cover != nil {
+		result["cover"] = sharedFileMap(*cover, shareKey)
+	}
+	return result
+}
+
 /* GenerateAccessToken 生成临时访问令牌 */
 func GenerateAccessToken(shareKey string, password string, clientIP, userAgent string) (string, error) {
 	share, err := GetShareByKey(shareKey)
@@ -391,6 +407,9 @@ func ValidateSharedFileAccess(shareID, fileID string) (bool, error) {
 
 	if err != nil {
 		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			if ok, err := validateFileInSharedAlbum(share, fileID); err != nil || ok {
+				return ok, err
+			}
 			return validateFileInSharedFolder(shareID, fileID)
 		}
 		return false, err
@@ -399,6 +418,19 @@ func ValidateSharedFileAccess(shareID, fileID string) (bool, error) {
 	return true, nil
 }
 
+func validateFileInSharedAlbum(share models.Share, fileID string) (bool, error) {
+	var count int64
+	if err := database.DB.Model(&models.ShareItem{}).
+		Joins("JOIN album_file ON album_file.album_id = share_item.item_id").
+		Joins("JOIN file ON file.id = album_file.file_id").
+		Where("share_item.share_id = ? AND share_item.item_type = ? AND album_file.file_id = ? AND file.user_id = ?",
+			share.ID, common.ShareItemTypeAlbum, fileID, share.UserID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 func validateFileInSharedFolder(shareID, fileID string) (bool, error) {
 	var file models.File
 	if err := database.DB.Where("id = ?", fileID).First(&file).Error; err != nil {
@@ -3,15 +3,43 @@ package share
 import (
 	"pixelpunk/internal/controllers/share/dto"
 	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/workspace"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
 	"pixelpunk/pkg/utils"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 func CreateShare(userID uint, req *dto.CreateShareDTO) (models.Share, error) {
+	shareType := req.ShareType
+	if shareType == "" {
+		shareType = common.ShareTypeView
+	}
+	workspaceID := ""
+	if shareType == common.ShareTypeUpload {
+		if req.UploadFolderID != "" {
+			folder, err := workspace.ResolveFolder(userID, req.UploadFolderID, workspace.ActionShare)
+			if err != nil {
+				if errors.Is(err, errors.CodeFolderNotFound) {
+					return models.Share{}, errors.New(errors.CodeFolderNotFound, "目标文件夹不存在")
+				}
+				return models.Share{}, err
+			}
+			workspaceID = folder.WorkspaceID
+		}
+	} else if len(req.Items) == 0 {
+		return models.Share{}, errors.New(errors.CodeInvalidParameter, "至少需要分享一个项目")
+	} else {
+		var err error
+		if workspaceID, err = resolveItemsWorkspace(userID, req.Items); err != nil {
+			return models.Share{}, err
+		}
+	}
+
 	shareKey := utils.GenerateRandomString(16)
 
 	for {
@@ -37,6 +65,15 @@ func CreateShare(userID uint, req *dto.CreateShareDTO) (models.Share, error) {
 		Status:               common.ShareStatusNormal,
 		CollectVisitorInfo:   req.CollectVisitorInfo,
 		NotificationOnAccess: req.NotificationOnAccess,
+		ShareType:            shareType,
+		WorkspaceID:          workspaceID,
+	}
+
+	if shareType == common.ShareTypeUpload {
+		share.UploadFolderID = req.UploadFolderID
+		share.UploadMaxFiles = req.UploadMaxFiles
+		share.UploadMaxFileSize = req.UploadMaxFileSize
+		share.UploadAllowedFormats = normalizeUploadFormats(req.UploadAllowedFormats)
 	}
 
 	if req.NotificationOnAccess && req.NotificationThreshold > 0 {
@@ -57,6 +94,10 @@ func CreateShare(userID uint, req *dto.CreateShareDTO) (models.Share, error) {
 			return err
 		}
 
+		if shareType == common.ShareTypeUpload {
+			return nil
+		}
+
 		for i, item := range req.Items {
 			shareItem := models.ShareItem{
 				ID:        generateID(),
@@ -78,5 +119,52 @@ func CreateShare(userID uint, req *dto.CreateShareDTO) (models.Share, error) {
 		return models.Share{}, err
 	}
 
+	workspace.RecordActivity(workspaceID, userID, workspace.ActivityShareCreate, "share", share.ID, share.Name, 0)
 	return share, nil
 }
+
+// resolveItemsWorkspace 校验工作区内容的分享权限，并确保同一分享不混合个人与工作区内容
+func resolveItemsWorkspace(userID uint, items []dto.ShareItemDTO) (string, error) {
+	workspaceID := ""
+	for i, item := range items {
+		itemWorkspaceID := ""
+		switch item.ItemType {
+		case common.ShareItemTypeFolder:
+			var folder models.Folder
+			if err := database.DB.Select("id, workspace_id").Where("id = ?", item.ItemID).First(&folder).Error; err == nil && folder.WorkspaceID != "" {
+				if _, err := workspace.ResolveFolder(userID, item.ItemID, workspace.ActionShare); err != nil {
+					return "", err
+				}
+				itemWorkspaceID = folder.WorkspaceID
+			}
+		case common.ShareItemTypeFile:
+			var file models.File
+			if err := database.DB.Select("id, workspace_id").Where("id = ?", item.ItemID).First(&file).Error; err == nil && file.WorkspaceID != "" {
+				if _, err := workspace.ResolveFile(userID, item.ItemID, workspace.ActionShare); err != nil {
+					return "", err
+				}
+				itemWorkspaceID = file.WorkspaceID
+			}
+		}
+		if i > 0 && itemWorkspaceID != workspaceID {
+			return "", errors.New(errors.CodeInvalidParameter, "同一分享不能混合个人空间与工作区的内容")
+		}
+		workspaceID = itemWorkspaceID
+	}
+	return workspaceID, nil
+}
+
+// normalizeUploadFormats 统一为小写、去掉前导点并去重的逗号分隔格式列表
+func normalizeUploadFormats(raw string) string {
+	seen := make(map[string]bool)
+	formats := []string{}
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(f), "."))
+		if f == "" || seen[f] {
+			continue
+		}
+		seen[f] = true
+		formats = append(formats, f)
+	}
+	return strings.Join(formats, ",")
+}
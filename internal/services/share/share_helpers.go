@@ -3,9 +3,22 @@ package share
 import (
 	"strings"
 
+	"pixelpunk/internal/models"
+
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 func generateID() string {
 	return strings.Replace(uuid.New().String(), "-", "", -1)
 }
+
+// contentScope 分享内容的归属范围：工作区分享按工作区过滤，个人分享按分享者过滤
+func contentScope(share models.Share) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if share.WorkspaceID != "" {
+			return db.Where("workspace_id = ?", share.WorkspaceID)
+		}
+		return db.Where("user_id = ?", share.UserID)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"pixelpunk/internal/controllers/share/dto"
 	"pixelpunk/internal/models"
 	"pixelpunk/internal/services/activity"
+	"pixelpunk/internal/services/webhook"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/email"
@@ -78,6 +79,13 @@ func IncreaseShareViews(shareID string) error {
 
 	newViews := previousViews + 1
 
+	webhook.Dispatch(webhook.EventShareAccessed, share.UserID, map[string]interface{}{
+		"share_id":   share.ID,
+		"share_key":  share.ShareKey,
+		"share_name": share.Name,
+		"views":      newViews,
+	})
+
 	milestones := []int{50, 100, 200, 500, 1000}
 	for _, milestone := range milestones {
 		if previousViews < milestone && newViews >= milestone {
@@ -230,10 +238,12 @@ func GetUserShares(userID uint, query *dto.ShareListQueryDTO) ([]map[string]inte
 	for i, share := range shares {
 		folderCount := int64(0)
 		fileCount := int64(0)
+		albumCount := int64(0)
 
 		if countMap[share.ID] != nil {
 			folderCount = countMap[share.ID]["folder"]
 			fileCount = countMap[share.ID]["file"]
+			albumCount = countMap[share.ID]["album"]
 		}
 
 		shareMap := map[string]interface{}{
@@ -250,6 +260,9 @@ func GetUserShares(userID uint, query *dto.ShareListQueryDTO) ([]map[string]inte
 			"updated_at":             share.UpdatedAt,
 			"folder_count":           folderCount,
 			"file_count":             fileCount,
+			"album_count":            albumCount,
+			"share_type":             share.ShareType,
+			"uploaded_files":         share.UploadedFiles,
 			"collect_visitor_info":   share.CollectVisitorInfo,
 			"notification_on_access": share.NotificationOnAccess,
 		}
@@ -0,0 +1,190 @@
+package share
+
+/* File request shares: anonymous visitors upload into the owner's target folder within the share's limits. */
+
+import (
+	"fmt"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+
+	"pixelpunk/internal/controllers/share/dto"
+	"pixelpunk/internal/models"
+	filesvc "pixelpunk/internal/services/file"
+	messageService "pixelpunk/internal/services/message"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const anonymousVisitorName = "匿名访客"
+
+/* UploadToShare 访客向文件收集分享上传文件 */
+func UploadToShare(c *gin.Context, shareKey string, req *dto.ShareUploadDTO, file *multipart.FileHeader) (*filesvc.FileDetailResponse, error) {
+	share, err := GetShareByKey(shareKey)
+	if err != nil {
+		return nil, err
+	}
+	if !share.IsUploadShare() {
+		return nil, errors.New(errors.CodeForbidden, "该分享不接收上传")
+	}
+
+	if share.Password != "" {
+		if req.AccessToken == "" {
+			return nil, errors.New(errors.CodeUnauthorized, "需要提供访问令牌")
+		}
+		if valid, err := ValidateAccessToken(shareKey, req.AccessToken); err != nil || !valid {
+			return nil, errors.New(errors.CodeUnauthorized, "访问令牌无效或已过期")
+		}
+	}
+
+	visitorName := strings.TrimSpace(req.VisitorName)
+	visitorEmail := strings.TrimSpace(req.VisitorEmail)
+	if share.CollectVisitorInfo && (visitorName == "" || visitorEmail == "") {
+		return nil, errors.New(errors.CodeInvalidParameter, "请填写姓名和邮箱")
+	}
+
+	if err := checkUploadConstraints(&share, file); err != nil {
+		return nil, err
+	}
+
+	// 先占用名额，上传失败时归还，避免并发上传超出数量限制
+	if err := reserveUploadSlot(&share); err != nil {
+		return nil, err
+	}
+	fileInfo, err := filesvc.ShareUpload(c, share.UserID, file, share.UploadFolderID)
+	if err != nil {
+		database.DB.Model(&models.Share{}).Where("id = ? AND uploaded_files > 0", share.ID).
+			UpdateColumn("uploaded_files", gorm.Expr("uploaded_files - 1"))
+		return nil, err
+	}
+
+	ip := c.ClientIP()
+	if visitorName != "" {
+		visitor := &dto.VisitorInfoDTO{Name: visitorName, Email: visitorEmail}
+		if err := SaveVisitorInfo(shareKey, visitor, ip, c.Request.UserAgent(), c.Request.Referer()); err != nil {
+			logger.Warn("保存文件收集访客信息失败: %v", err)
+		}
+	}
+
+	record := models.ShareUpload{
+		ID:           generateID(),
+		ShareID:      share.ID,
+		FileID:       fileInfo.ID,
+		VisitorName:  visitorName,
+		VisitorEmail: visitorEmail,
+		IPAddress:    ip,
+		FileName:     file.Filename,
+		FileSize:     file.Size,
+	}
+	if err := database.DB.Create(&record).Error; err != nil {
+		logger.Error("记录文件收集上传失败: %v", err)
+	}
+
+	go notifyShareUpload(share, visitorName, file.Filename)
+
+	return fileInfo, nil
+}
+
+/* GetShareUploads 获取文件收集分享的上传记录 */
+func GetShareUploads(shareID string, userID uint, page, size int) ([]models.ShareUpload, int64, error) {
+	var count int64
+	if err := database.DB.Model(&models.Share{}).Where("id = ? AND user_id = ?", shareID, userID).Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询分享失败")
+	}
+	if count == 0 {
+		return nil, 0, errors.New(errors.CodeNotFound, "分享不存在或您无权访问")
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > common.MaxPageSize {
+		size = common.DefaultPageSize
+	}
+
+	db := database.DB.Model(&models.ShareUpload{}).Where("share_id = ?", shareID)
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "获取上传记录总数失败")
+	}
+	var uploads []models.ShareUpload
+	if err := db.Order("created_at DESC").Offset((page - 1) * size).Limit(size).Find(&uploads).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询上传记录失败")
+	}
+	return uploads, total, nil
+}
+
+// uploadInfo 分享页展示的上传限制
+func uploadInfo(share *models.Share) map[string]interface{} {
+	remaining := -1 // -1 表示不限制
+	if share.UploadMaxFiles > 0 {
+		remaining = share.UploadMaxFiles - share.UploadedFiles
+		if remaining < 0 {
+			remaining = 0
+		}
+	}
+	formats := []string{}
+	if share.UploadAllowedFormats != "" {
+		formats = strings.Split(share.UploadAllowedFormats, ",")
+	}
+	return map[string]interface{}{
+		"max_files":       share.UploadMaxFiles,
+		"remaining_files": remaining,
+		"max_file_size":   share.UploadMaxFileSize,
+		"allowed_formats": formats,
+	}
+}
+
+func checkUploadConstraints(share *models.Share, file *multipart.FileHeader) error {
+	if share.UploadMaxFileSize > 0 && file.Size > int64(share.UploadMaxFileSize)*1024*1024 {
+		return errors.New(errors.CodeFileTooLarge, fmt.Sprintf("文件大小不能超过%dMB", share.UploadMaxFileSize))
+	}
+	if !share.AllowsUploadFormat(filepath.Ext(file.Filename)) {
+		return errors.New(errors.CodeFileTypeNotSupported, "该文件收集只接收以下格式: "+share.UploadAllowedFormats)
+	}
+	if share.UploadMaxFiles > 0 && share.UploadedFiles >= share.UploadMaxFiles {
+		return errors.New(errors.CodeUploadLimitExceeded, "该文件收集已达到接收数量上限")
+	}
+	return nil
+}
+
+func reserveUploadSlot(share *models.Share) error {
+	query := database.DB.Model(&models.Share{}).Where("id = ?", share.ID)
+	if share.UploadMaxFiles > 0 {
+		query = query.Where("uploaded_files < upload_max_files")
+	}
+	result := query.UpdateColumn("uploaded_files", gorm.Expr("uploaded_files + 1"))
+	if result.Error != nil {
+		return errors.Wrap(result.Error, errors.CodeDBUpdateFailed, "更新分享上传计数失败")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(errors.CodeUploadLimitExceeded, "该文件收集已达到接收数量上限")
+	}
+	return nil
+}
+
+func notifyShareUpload(share models.Share, visitorName, fileName string) {
+	if visitorName == "" {
+		visitorName = anonymousVisitorName
+	}
+	shareName := share.Name
+	if shareName == "" {
+		shareName = share.ShareKey
+	}
+	variables := map[string]interface{}{
+		"share_id":     share.ID,
+		"share_name":   shareName,
+		"visitor_name": visitorName,
+		"file_name":    fileName,
+		"related_type": "share",
+		"related_id":   share.ID,
+	}
+	if err := messageService.GetMessageService().SendTemplateMessage(share.UserID, common.MessageTypeShareFileReceived, variables); err != nil {
+		logger.Warn("发送文件收集上传通知失败: userID=%d, shareID=%s, error=%v", share.UserID, share.ID, err)
+	}
+}
@@ -0,0 +1,41 @@
+package share
+
+import (
+	"mime/multipart"
+	"testing"
+
+	"pixelpunk/internal/models"
+)
+
+func TestNormalizeUploadFormats(t *testing.T) {
+	if got := normalizeUploadFormats(" JPG, .png,,jpg ,webp"); got != "jpg,png,webp" {
+		t.Errorf("normalizeUploadFormats = %q", got)
+	}
+	if got := normalizeUploadFormats(""); got != "" {
+		t.Errorf("normalizeUploadFormats empty = %q", got)
+	}
+}
+
+func TestCheckUploadConstraints(t *testing.T) {
+	share := &models.Share{UploadMaxFileSize: 1, UploadAllowedFormats: "jpg,png", UploadMaxFiles: 2}
+
+	if err := checkUploadConstraints(share, &multipart.FileHeader{Filename: "a.JPG", Size: 1024}); err != nil {
+		t.Errorf("allowed file rejected: %v", err)
+	}
+	if err := checkUploadConstraints(share, &multipart.FileHeader{Filename: "a.gif", Size: 1024}); err == nil {
+		t.Error("format outside allow list accepted")
+	}
+	if err := checkUploadConstraints(share, &multipart.FileHeader{Filename: "a.png", Size: 2 << 20}); err == nil {
+		t.Error("oversized file accepted")
+	}
+
+	share.UploadedFiles = 2
+	if err := checkUploadConstraints(share, &multipart.FileHeader{Filename: "a.png", Size: 1024}); err == nil {
+		t.Error("upload accepted after reaching max files")
+	}
+
+	// 未设置限制时只受系统限制约束
+	if err := checkUploadConstraints(&models.Share{}, &multipart.FileHeader{Filename: "a.tiff", Size: 100 << 20}); err != nil {
+		t.Errorf("unrestricted share rejected: %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package tag
 import (
 	"fmt"
 	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/search"
 	"pixelpunk/pkg/ai"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
@@ -70,6 +71,7 @@ func (s *FileGlobalTagService) AddTagsToFile(fileID string, tagIDs []uint, sourc
 	if err := s.db.Create(&newRelations).Error; err != nil {
 		return fmt.Errorf("批量创建文件标签关联失败: %v", err)
 	}
+	search.MarkDirty(fileID)
 
 	// 手动批量更新受影响标签的usage_count
 	if len(actuallyAddedTagIDs) > 0 {
@@ -106,6 +108,7 @@ func (s *FileGlobalTagService) RemoveTagsFromFile(fileID string, tagIDs []uint)
 	if err != nil {
 		return fmt.Errorf("移除文件标签失败: %v", err)
 	}
+	search.MarkDirty(fileID)
 
 	// 手动批量更新受影响标签的usage_count
 	if len(tagIDs) > 0 {
@@ -249,6 +252,10 @@ func (s *FileGlobalTagService) ReplaceFileTagsTx(tx *gorm.DB, fileID string, new
 		}
 	}
 
+	if len(tagsToRemove) > 0 || len(tagsToAdd) > 0 {
+		search.MarkDirty(fileID)
+	}
+
 	// 8. 手动批量更新受影响标签的usage_count
 	affectedTagIDs := make(map[uint]bool)
 	for _, tagID := range tagsToRemove {
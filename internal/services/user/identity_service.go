@@ -0,0 +1,41 @@
+package user
+
+import (
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+)
+
+/* ListUserIdentities 获取用户关联的第三方登录身份 */
+func ListUserIdentities(userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	if err := database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&identities).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询关联账号失败")
+	}
+	return identities, nil
+}
+
+/* UnlinkUserIdentity 解除第三方登录关联；未设置密码时至少保留一个关联，避免账号无法登录 */
+func UnlinkUserIdentity(userID, identityID uint) error {
+	var identity models.UserIdentity
+	if err := database.DB.Where("id = ? AND user_id = ?", identityID, userID).First(&identity).Error; err != nil {
+		return errors.New(errors.CodeNotFound, "关联账号不存在")
+	}
+
+	var user models.User
+	if err := database.DB.Select("id, password").First(&user, userID).Error; err != nil {
+		return errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+	if user.Password == "" {
+		var count int64
+		database.DB.Model(&models.UserIdentity{}).Where("user_id = ?", userID).Count(&count)
+		if count <= 1 {
+			return errors.New(errors.CodeForbidden, "账号未设置密码，无法解除最后一个关联登录方式")
+		}
+	}
+
+	if err := database.DB.Delete(&identity).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBDeleteFailed, "解除关联失败")
+	}
+	return nil
+}
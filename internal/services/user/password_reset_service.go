@@ -139,7 +139,7 @@ func ResetPasswordWithToken(token, newPassword string) error {
 	}
 
 	// 使用 GORM Transaction 方法替代手动事务管理，确保 SQLite 兼容性
-	return db.Transaction(func(tx *gorm.DB) error {
+	err = db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Update("password", hashedPassword).Error; err != nil {
 			return errors.New(errors.CodeDBUpdateFailed, "更新密码失败")
 		}
@@ -157,6 +157,12 @@ func ResetPasswordWithToken(token, newPassword string) error {
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	revokeSessionsAfterPasswordChange(user.ID, "")
+	return nil
 }
 
 // CleanupExpiredTokens 清理过期的重置token
@@ -0,0 +1,458 @@
+package user
+
+/* TOTP two-factor authentication: enrollment, recovery codes and the challenge step of login. */
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/auth"
+	"pixelpunk/internal/services/rbac"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/pkg/cache"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	twoFactorChallengeTTL      = 5 * time.Minute
+	twoFactorChallengeAttempts = 5  // 单个挑战允许的错误次数
+	recoveryCodeCount          = 10 // 每次生成的恢复码数量
+)
+
+/* TwoFactorChallenge 密码校验通过后返回的两步验证挑战 */
+type TwoFactorChallenge struct {
+	ChallengeToken string `json:"challenge_token"`
+	SetupRequired  bool   `json:"setup_required"` // 角色被强制要求两步验证但尚未绑定
+	ExpiresIn      int    `json:"expires_in"`
+}
+
+/* TwoFactorSetup 绑定身份验证器所需的信息，otpauth_uri 可直接生成二维码 */
+type TwoFactorSetup struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+/* BeginTwoFactorChallenge 用户已启用或被要求启用两步验证时创建挑战，否则返回 nil */
+func BeginTwoFactorChallenge(user *models.User) (*TwoFactorChallenge, error) {
+	enabled := isTwoFactorEnabled(user.ID)
+	required := IsTwoFactorRequired(user.Role)
+	if !enabled && !required {
+		return nil, nil
+	}
+
+	token, err := generateResetToken()
+	if err != nil {
+		return nil, errors.New(errors.CodeInternal, "生成验证挑战失败")
+	}
+	if err := cache.GetCache().Set(challengeKey(token), strconv.FormatUint(uint64(user.ID), 10), twoFactorChallengeTTL); err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "保存验证挑战失败")
+	}
+
+	return &TwoFactorChallenge{
+		ChallengeToken: token,
+		SetupRequired:  !enabled,
+		ExpiresIn:      int(twoFactorChallengeTTL.Seconds()),
+	}, nil
+}
+
+/* ResolveTwoFactorChallenge 根据挑战令牌获取对应用户 */
+func ResolveTwoFactorChallenge(challengeToken string) (*models.User, error) {
+	val, err := cache.GetCache().Get(challengeKey(challengeToken))
+	if err != nil || val == "" {
+		return nil, errors.New(errors.CodeUnauthorized, "验证已过期，请重新登录")
+	}
+	userID, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		return nil, errors.New(errors.CodeUnauthorized, "验证已过期，请重新登录")
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+	if !user.IsNormal() {
+		return nil, errors.New(errors.CodeUserDisabled, "账号已被禁用")
+	}
+	return &user, nil
+}
+
+/* CompleteTwoFactorLogin 使用验证码或恢复码完成登录挑战并签发token */
+func CompleteTwoFactorLogin(challengeToken, code string, client auth.SessionClient) (map[string]interface{}, *auth.TokenPair, error) {
+	user, err := ResolveTwoFactorChallenge(challengeToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := verifyChallengeCode(challengeToken, user.ID, code); err != nil {
+		return nil, nil, err
+	}
+
+	_ = cache.GetCache().Del(challengeKey(challengeToken))
+	return IssueLoginToken(user, client)
+}
+
+/* CompleteTwoFactorEnrollment 被强制要求两步验证的用户在登录挑战中完成绑定，返回恢复码并签发token */
+func CompleteTwoFactorEnrollment(challengeToken, code string, client auth.SessionClient) (map[string]interface{}, *auth.TokenPair, []string, error) {
+	user, err := ResolveTwoFactorChallenge(challengeToken)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	codes, err := EnableTwoFactor(user.ID, code)
+	if err != nil {
+		if failErr := recordChallengeFailure(challengeToken); failErr != nil {
+			return nil, nil, nil, failErr
+		}
+		return nil, nil, nil, err
+	}
+
+	_ = cache.GetCache().Del(challengeKey(challengeToken))
+	userInfo, tokens, err := IssueLoginToken(user, client)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return userInfo, tokens, codes, nil
+}
+
+/* GetTwoFactorStatus 获取用户两步验证状态 */
+func GetTwoFactorStatus(userID uint) (map[string]interface{}, error) {
+	var user models.User
+	if err := database.DB.Select("id, role").First(&user, userID).Error; err != nil {
+		return nil, errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+
+	result := map[string]interface{}{
+		"enabled":                  false,
+		"enabled_at":               nil,
+		"required":                 IsTwoFactorRequired(user.Role),
+		"recovery_codes_remaining": 0,
+	}
+	if tf, err := getTwoFactor(userID); err == nil && tf.Enabled {
+		result["enabled"] = true
+		result["enabled_at"] = tf.EnabledAt
+		result["recovery_codes_remaining"] = len(parseRecoveryCodes(tf.RecoveryCodes))
+	}
+	return result, nil
+}
+
+/* SetupTwoFactor 生成新的待确认密钥；已启用时需先关闭 */
+func SetupTwoFactor(userID uint) (*TwoFactorSetup, error) {
+	var user models.User
+	if err := database.DB.Select("id, username, email").First(&user, userID).Error; err != nil {
+		return nil, errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+	if isTwoFactorEnabled(userID) {
+		return nil, errors.New(errors.CodeConflict, "两步验证已启用，如需更换请先关闭")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, errors.New(errors.CodeInternal, "生成密钥失败")
+	}
+
+	tf := models.UserTwoFactor{UserID: userID, Secret: secret}
+	if err := database.DB.Save(&tf).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "保存两步验证配置失败")
+	}
+
+	account := user.Email
+	if account == "" {
+		account = user.Username
+	}
+	return &TwoFactorSetup{
+		Secret:     secret,
+		OtpauthURI: utils.TOTPKeyURI(twoFactorIssuer(), account, secret),
+	}, nil
+}
+
+/* EnableTwoFactor 使用身份验证器生成的验证码确认绑定，返回一次性恢复码（仅展示这一次） */
+func EnableTwoFactor(userID uint, code string) ([]string, error) {
+	tf, err := getTwoFactor(userID)
+	if err != nil {
+		return nil, errors.New(errors.CodeInvalidParameter, "请先获取两步验证密钥")
+	}
+	if tf.Enabled {
+		return nil, errors.New(errors.CodeConflict, "两步验证已启用")
+	}
+
+	step, ok := utils.ValidateTOTP(tf.Secret, code, time.Now())
+	if !ok {
+		return nil, errors.New(errors.CodeValidationFailed, "验证码错误")
+	}
+
+	codes, hashed, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, errors.New(errors.CodeInternal, "生成恢复码失败")
+	}
+
+	now := common.JSONTime(time.Now())
+	if err := database.DB.Model(&models.UserTwoFactor{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+		"enabled":        true,
+		"enabled_at":     &now,
+		"recovery_codes": hashed,
+		"last_used_step": step,
+	}).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "启用两步验证失败")
+	}
+	return codes, nil
+}
+
+/* DisableTwoFactor 使用验证码或恢复码关闭两步验证；角色被强制要求时不允许关闭 */
+func DisableTwoFactor(userID uint, code string) error {
+	var user models.User
+	if err := database.DB.Select("id, role").First(&user, userID).Error; err != nil {
+		return errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+	if IsTwoFactorRequired(user.Role) {
+		return errors.New(errors.CodeForbidden, "当前角色被要求启用两步验证，无法关闭")
+	}
+	if err := verifyTwoFactorCode(userID, code); err != nil {
+		return err
+	}
+
+	if err := database.DB.Where("user_id = ?", userID).Delete(&models.UserTwoFactor{}).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBDeleteFailed, "关闭两步验证失败")
+	}
+	return nil
+}
+
+/* RegenerateRecoveryCodes 使用验证码重新生成恢复码，旧恢复码全部失效 */
+func RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	if err := verifyTwoFactorCode(userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashed, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, errors.New(errors.CodeInternal, "生成恢复码失败")
+	}
+	if err := database.DB.Model(&models.UserTwoFactor{}).Where("user_id = ?", userID).
+		Update("recovery_codes", hashed).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "更新恢复码失败")
+	}
+	return codes, nil
+}
+
+/* AdminResetTwoFactor 管理员重置用户的两步验证（用户丢失设备与恢复码时使用） */
+func AdminResetTwoFactor(userID, operatorID uint) error {
+	var count int64
+	if err := database.DB.Model(&models.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询用户失败")
+	}
+	if count == 0 {
+		return errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+	if err := rbac.CheckManageTarget(operatorID, userID); err != nil {
+		return err
+	}
+
+	if err := database.DB.Where("user_id = ?", userID).Delete(&models.UserTwoFactor{}).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBDeleteFailed, "重置两步验证失败")
+	}
+	_ = cache.GetCache().Del(fmt.Sprintf("user:2fa:attempts:%d", userID))
+	return nil
+}
+
+/* IsTwoFactorRequired 角色是否被设置为强制启用两步验证 */
+func IsTwoFactorRequired(role int) bool {
+	securitySettings, err := setting.GetSettingsByGroupAsMap("security")
+	if err != nil {
+		return false
+	}
+	roles, ok := securitySettings.Settings["two_factor_required_roles"].([]interface{})
+	if !ok {
+		return false
+	}
+	for _, r := range roles {
+		switch v := r.(type) {
+		case float64:
+			if int(v) == role {
+				return true
+			}
+		case string:
+			if n, err := strconv.Atoi(v); err == nil && n == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/* RequiresSecondFactor 用户已启用或被要求启用两步验证，仅凭密码的认证入口应拒绝此类账号 */
+func RequiresSecondFactor(user *models.User) bool {
+	return isTwoFactorEnabled(user.ID) || IsTwoFactorRequired(user.Role)
+}
+
+func twoFactorIssuer() string {
+	if securitySettings, err := setting.GetSettingsByGroupAsMap("security"); err == nil {
+		if issuer, ok := securitySettings.Settings["two_factor_issuer"].(string); ok && strings.TrimSpace(issuer) != "" {
+			return strings.TrimSpace(issuer)
+		}
+	}
+	return "PixelPunk"
+}
+
+func challengeKey(token string) string {
+	return "user:2fa:challenge:" + token
+}
+
+func getTwoFactor(userID uint) (*models.UserTwoFactor, error) {
+	var tf models.UserTwoFactor
+	if err := database.DB.Where("user_id = ?", userID).First(&tf).Error; err != nil {
+		return nil, err
+	}
+	return &tf, nil
+}
+
+func isTwoFactorEnabled(userID uint) bool {
+	var count int64
+	database.DB.Model(&models.UserTwoFactor{}).Where("user_id = ? AND enabled = ?", userID, true).Count(&count)
+	return count > 0
+}
+
+// verifyChallengeCode 登录挑战中的校验，错误次数同时计入挑战与账号，超限后挑战作废
+func verifyChallengeCode(challengeToken string, userID uint, code string) error {
+	if err := verifyTwoFactorCode(userID, code); err != nil {
+		if failErr := recordChallengeFailure(challengeToken); failErr != nil {
+			return failErr
+		}
+		return err
+	}
+	return nil
+}
+
+func recordChallengeFailure(challengeToken string) error {
+	key := challengeKey(challengeToken) + ":attempts"
+	count := 0
+	if val, err := cache.GetCache().Get(key); err == nil && val != "" {
+		count, _ = strconv.Atoi(val)
+	}
+	count++
+	if count >= twoFactorChallengeAttempts {
+		_ = cache.GetCache().Del(challengeKey(challengeToken))
+		_ = cache.GetCache().Del(key)
+		return errors.New(errors.CodeUnauthorized, "验证码错误次数过多，请重新登录")
+	}
+	_ = cache.GetCache().Set(key, strconv.Itoa(count), twoFactorChallengeTTL)
+	return nil
+}
+
+// verifyTwoFactorCode 校验 6 位验证码或恢复码；按账号累计失败次数，复用登录锁定策略
+func verifyTwoFactorCode(userID uint, code string) error {
+	lockKey := fmt.Sprintf("user:login:lock:%d", userID)
+	if cache.GetCache().Exists(lockKey) {
+		return errors.New(errors.CodeForbidden, "账户已被锁定，请稍后再试")
+	}
+
+	tf, err := getTwoFactor(userID)
+	if err != nil || !tf.Enabled {
+		return errors.New(errors.CodeInvalidParameter, "未启用两步验证")
+	}
+
+	if consumeTwoFactorCode(tf, code) {
+		_ = cache.GetCache().Del(fmt.Sprintf("user:2fa:attempts:%d", userID))
+		return nil
+	}
+
+	maxAttempts, lockoutMinutes := loginLockoutSettings()
+	attemptKey := fmt.Sprintf("user:2fa:attempts:%d", userID)
+	attempts := 0
+	if val, err := cache.GetCache().Get(attemptKey); err == nil && val != "" {
+		attempts, _ = strconv.Atoi(val)
+	}
+	attempts++
+	if attempts >= maxAttempts {
+		_ = cache.GetCache().Set(lockKey, "1", time.Duration(lockoutMinutes)*time.Minute)
+		_ = cache.GetCache().Del(attemptKey)
+		return errors.New(errors.CodeForbidden,
+			fmt.Sprintf("验证码错误次数过多，账户已被锁定%d分钟", lockoutMinutes))
+	}
+	_ = cache.GetCache().Set(attemptKey, strconv.Itoa(attempts), time.Duration(lockoutMinutes)*time.Minute)
+	return errors.New(errors.CodeValidationFailed, "验证码错误")
+}
+
+// consumeTwoFactorCode 验证码通过后记录时间步防止重放；恢复码通过后即从列表移除。
+// 均使用条件更新，并发提交同一个码时只有一个请求能成功
+func consumeTwoFactorCode(tf *models.UserTwoFactor, code string) bool {
+	code = strings.TrimSpace(code)
+
+	if step, ok := utils.ValidateTOTP(tf.Secret, code, time.Now()); ok {
+		result := database.DB.Model(&models.UserTwoFactor{}).
+			Where("user_id = ? AND last_used_step < ?", tf.UserID, step).
+			Update("last_used_step", step)
+		return result.Error == nil && result.RowsAffected == 1
+	}
+
+	hashes := parseRecoveryCodes(tf.RecoveryCodes)
+	target := hashRecoveryCode(code)
+	for i, h := range hashes {
+		if h != target {
+			continue
+		}
+		remaining := append(append([]string{}, hashes[:i]...), hashes[i+1:]...)
+		data, _ := json.Marshal(remaining)
+		result := database.DB.Model(&models.UserTwoFactor{}).
+			Where("user_id = ? AND recovery_codes = ?", tf.UserID, tf.RecoveryCodes).
+			Update("recovery_codes", string(data))
+		return result.Error == nil && result.RowsAffected == 1
+	}
+	return false
+}
+
+func loginLockoutSettings() (int, int) {
+	maxAttempts, lockoutMinutes := 5, 30
+	if securitySettings, err := setting.GetSettingsByGroupAsMap("security"); err == nil {
+		if v, ok := securitySettings.Settings["max_login_attempts"].(float64); ok && v > 0 {
+			maxAttempts = int(v)
+		}
+		if v, ok := securitySettings.Settings["account_lockout_minutes"].(float64); ok && v > 0 {
+			lockoutMinutes = int(v)
+		}
+	}
+	return maxAttempts, lockoutMinutes
+}
+
+// generateRecoveryCodes 返回明文恢复码（xxxxx-xxxxx）与其摘要列表的 JSON
+func generateRecoveryCodes() ([]string, string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, "", err
+		}
+		raw := hex.EncodeToString(buf)
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	data, err := json.Marshal(hashes)
+	if err != nil {
+		return nil, "", err
+	}
+	return codes, string(data), nil
+}
+
+// hashRecoveryCode 忽略大小写、空格与连字符后取摘要
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func parseRecoveryCodes(raw string) []string {
+	var hashes []string
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &hashes)
+	}
+	return hashes
+}
@@ -4,6 +4,7 @@ import (
 	"pixelpunk/internal/controllers/user/dto"
 	"pixelpunk/internal/models"
 	messageService "pixelpunk/internal/services/message"
+	"pixelpunk/internal/services/rbac"
 	"pixelpunk/pkg/cache"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
@@ -135,6 +136,15 @@ func AdminUpdateUser(updateDTO *dto.AdminUpdateUserDTO, currentUserID uint) erro
 		}
 	}
 
+	if err := rbac.CheckManageTarget(currentUserID, user.ID); err != nil {
+		return err
+	}
+	if updateDTO.Role != user.Role {
+		if err := rbac.CheckRoleWrite(currentUserID, updateDTO.Role); err != nil {
+			return err
+		}
+	}
+
 	if updateDTO.Username != user.Username {
 		var count int64
 		if err := db.Model(&models.User{}).
@@ -155,6 +165,9 @@ func AdminUpdateUser(updateDTO *dto.AdminUpdateUserDTO, currentUserID uint) erro
 		"status":   updateDTO.Status,
 		"role":     updateDTO.Role,
 	}
+	if updateDTO.Role != user.Role {
+		updates["admin_role_id"] = 0 // 直接调整固定角色时回到对应的内置后台角色
+	}
 
 	if err := db.Model(&user).Updates(updates).Error; err != nil {
 		return errors.Wrap(err, errors.CodeDBUpdateFailed, "更新用户信息失败")
@@ -222,7 +235,7 @@ func AdminGetUserByID(id uint) (*dto.AdminUserResponseDTO, error) {
 	}, nil
 }
 
-func AdminDeleteUser(id uint) error {
+func AdminDeleteUser(id, operatorID uint) error {
 	db := database.GetDB()
 
 	var user models.User
@@ -233,6 +246,9 @@ func AdminDeleteUser(id uint) error {
 	if user.IsSuperAdmin() {
 		return errors.New(errors.CodeForbidden, "不能删除超级管理员")
 	}
+	if err := rbac.CheckManageTarget(operatorID, user.ID); err != nil {
+		return err
+	}
 
 	if err := db.Model(&user).Update("status", common.UserStatusDeleted).Error; err != nil {
 		return errors.Wrap(err, errors.CodeDBUpdateFailed, "删除用户失败")
@@ -241,9 +257,15 @@ func AdminDeleteUser(id uint) error {
 	return nil
 }
 
-func AdminCreateUser(createDTO *dto.AdminCreateUserDTO) (*dto.AdminUserResponseDTO, error) {
+func AdminCreateUser(createDTO *dto.AdminCreateUserDTO, operatorID uint) (*dto.AdminUserResponseDTO, error) {
 	db := database.GetDB()
 
+	if createDTO.Role != common.UserRoleUser {
+		if err := rbac.CheckRoleWrite(operatorID, createDTO.Role); err != nil {
+			return nil, err
+		}
+	}
+
 	var existingUser models.User
 	if err := db.Where("username = ?", createDTO.Username).First(&existingUser).Error; err == nil {
 		return nil, errors.New(errors.CodeUserExists, "用户名已存在")
@@ -355,6 +377,9 @@ func AdminUpdateUserStorage(updateDTO *dto.AdminUpdateUserStorageDTO) error {
 	if user.IsSuperAdmin() && updateDTO.CurrentUserID != updateDTO.UserID {
 		return errors.New(errors.CodeForbidden, "只有超级管理员本人可以修改自己的设置")
 	}
+	if err := rbac.CheckManageTarget(updateDTO.CurrentUserID, user.ID); err != nil {
+		return err
+	}
 
 	var userSettings models.UserSettings
 	var oldStorageLimit int64 = 0
@@ -389,7 +414,7 @@ func AdminUpdateUserStorage(updateDTO *dto.AdminUpdateUserStorageDTO) error {
 	return nil
 }
 
-func AdminResetUserPassword(id uint) (*dto.AdminResetUserPasswordResponseDTO, error) {
+func AdminResetUserPassword(id, operatorID uint) (*dto.AdminResetUserPasswordResponseDTO, error) {
 	db := database.GetDB()
 
 	var user models.User
@@ -400,6 +425,9 @@ func AdminResetUserPassword(id uint) (*dto.AdminResetUserPasswordResponseDTO, er
 	if user.IsSuperAdmin() {
 		return nil, errors.New(errors.CodeForbidden, "不能重置超级管理员密码")
 	}
+	if err := rbac.CheckManageTarget(operatorID, user.ID); err != nil {
+		return nil, err
+	}
 
 	newPassword := utils.GenerateRandomString(8)
 
@@ -411,6 +439,7 @@ func AdminResetUserPassword(id uint) (*dto.AdminResetUserPasswordResponseDTO, er
 	if err := db.Model(&user).Update("password", string(hashedPassword)).Error; err != nil {
 		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "重置密码失败")
 	}
+	revokeSessionsAfterPasswordChange(user.ID, "")
 
 	return &dto.AdminResetUserPasswordResponseDTO{
 		NewPassword: newPassword,
@@ -428,7 +457,7 @@ func AdminSendUserEmail(emailDTO *dto.AdminSendUserEmailDTO) error {
 	return nil
 }
 
-func AdminToggleUserStatus(statusDTO *dto.AdminToggleUserStatusDTO) error {
+func AdminToggleUserStatus(statusDTO *dto.AdminToggleUserStatusDTO, operatorID uint) error {
 	db := database.GetDB()
 
 	var user models.User
@@ -439,6 +468,9 @@ func AdminToggleUserStatus(statusDTO *dto.AdminToggleUserStatusDTO) error {
 	if user.IsSuperAdmin() {
 		return errors.New(errors.CodeForbidden, "不能修改超级管理员状态")
 	}
+	if err := rbac.CheckManageTarget(operatorID, user.ID); err != nil {
+		return err
+	}
 
 	if err := db.Model(&user).Update("status", statusDTO.Status).Error; err != nil {
 		return errors.Wrap(err, errors.CodeDBUpdateFailed, "更新用户状态失败")
@@ -450,7 +482,7 @@ func AdminToggleUserStatus(statusDTO *dto.AdminToggleUserStatusDTO) error {
 	return nil
 }
 
-func AdminBatchOperateUsers(batchDTO *dto.AdminBatchOperateUsersDTO) error {
+func AdminBatchOperateUsers(batchDTO *dto.AdminBatchOperateUsersDTO, operatorID uint) error {
 	db := database.GetDB()
 
 	var users []models.User
@@ -466,6 +498,15 @@ func AdminBatchOperateUsers(batchDTO *dto.AdminBatchOperateUsersDTO) error {
 		if user.IsSuperAdmin() {
 			return errors.New(errors.CodeForbidden, "不能对超级管理员进行批量操作")
 		}
+		if err := rbac.CheckManageTarget(operatorID, user.ID); err != nil {
+			return err
+		}
+	}
+
+	if batchDTO.Operation == "set_role" && batchDTO.Role != 0 {
+		if err := rbac.CheckRoleWrite(operatorID, batchDTO.Role); err != nil {
+			return err
+		}
 	}
 
 	var affectedStatus int
@@ -488,10 +529,13 @@ func AdminBatchOperateUsers(batchDTO *dto.AdminBatchOperateUsersDTO) error {
 			}
 			affectedStatus = common.UserStatusDeleted
 		case "set_role":
-			if batchDTO.Role == 0 {
-				return errors.New(errors.CodeInvalidParameter, "设置角色时必须指定角色值")
+			if batchDTO.Role < common.UserRoleSuperAdmin || batchDTO.Role > common.UserRoleUser {
+				return errors.New(errors.CodeInvalidParameter, "设置角色时必须指定有效的角色值")
 			}
-			if err := tx.Model(&models.User{}).Where("id IN (?)", batchDTO.UserIDs).Update("role", batchDTO.Role).Error; err != nil {
+			if err := tx.Model(&models.User{}).Where("id IN (?)", batchDTO.UserIDs).Updates(map[string]interface{}{
+				"role":          batchDTO.Role,
+				"admin_role_id": 0,
+			}).Error; err != nil {
 				return errors.Wrap(err, errors.CodeDBUpdateFailed, "批量设置角色失败")
 			}
 			affectedStatus = 0 // 角色变更不涉及状态同步
@@ -19,7 +19,6 @@ import (
 	"pixelpunk/pkg/storage/tenant"
 	"pixelpunk/pkg/utils"
 	"strconv"
-	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -38,12 +37,64 @@ func GetUserService() *UserService {
 	return userService
 }
 
-func Login(account, password string) (map[string]interface{}, string, error) {
+/* Login 账号密码登录；账号需要两步验证时不签发token，而是返回短期有效的验证挑战 */
+func Login(account, password string, client auth.SessionClient) (map[string]interface{}, *auth.TokenPair, *TwoFactorChallenge, error) {
+	user, err := VerifyCredentials(account, password)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	challenge, err := BeginTwoFactorChallenge(user)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if challenge != nil {
+		return nil, nil, challenge, nil
+	}
+
+	userInfo, tokens, err := IssueLoginToken(user, client)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return userInfo, tokens, nil, nil
+}
+
+/* IssueLoginToken 为已通过全部认证步骤的用户创建登录会话，签发访问令牌与刷新令牌并返回用户信息 */
+func IssueLoginToken(user *models.User, client auth.SessionClient) (map[string]interface{}, *auth.TokenPair, error) {
+	tokens, err := auth.CreateSession(user, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	avatarFullPath := ""
+	if user.Avatar != "" {
+		avatarFullPath = utils.GetSystemFileURL(user.Avatar)
+	} else {
+		avatarFullPath = ""
+	}
+
+	userInfo := map[string]interface{}{
+		"id":             user.ID,
+		"username":       user.Username,
+		"email":          user.Email,
+		"avatar":         user.Avatar,
+		"avatarFullPath": avatarFullPath,
+		"bio":            user.Bio,
+		"website":        user.Website,
+		"role":           user.Role,
+		"status":         user.Status,
+	}
+
+	return userInfo, tokens, nil
+}
+
+/* VerifyCredentials 校验账号密码（含失败次数锁定与账号状态），供登录及 WebDAV 等基础认证入口复用 */
+func VerifyCredentials(account, password string) (*models.User, error) {
 	db := database.GetDB()
 	var user models.User
 	result := db.Where("username = ? OR email = ?", account, account).First(&user)
 	if result.Error != nil {
-		return nil, "", errors.New(errors.CodeUserNotFound, "用户不存在")
+		return nil, errors.New(errors.CodeUserNotFound, "用户不存在")
 	}
 
 	securitySettings, err := setting.GetSettingsByGroupAsMap("security")
@@ -52,27 +103,7 @@ func Login(account, password string) (map[string]interface{}, string, error) {
 	accountLockoutMinutes := 30
 
 	if err != nil {
-		return nil, "", errors.New(errors.CodeInternal, "安全配置读取失败：security 组缺失")
-	}
-
-	jwtSecret := ""
-	if val, ok := securitySettings.Settings["jwt_secret"]; ok {
-		if secretStr, ok := val.(string); ok {
-			jwtSecret = secretStr
-		}
-	}
-	if strings.TrimSpace(jwtSecret) == "" {
-		return nil, "", errors.New(errors.CodeInternal, "安全配置缺失：jwt_secret 未设置")
-	}
-
-	expiresHours := 0
-	if val, ok := securitySettings.Settings["login_expire_hours"]; ok {
-		if hours, ok := val.(float64); ok && hours > 0 {
-			expiresHours = int(hours)
-		}
-	}
-	if expiresHours <= 0 {
-		return nil, "", errors.New(errors.CodeInternal, "安全配置缺失：login_expire_hours 未设置或非法")
+		return nil, errors.New(errors.CodeInternal, "安全配置读取失败：security 组缺失")
 	}
 
 	if val, ok := securitySettings.Settings["max_login_attempts"]; ok {
@@ -103,10 +134,10 @@ func Login(account, password string) (map[string]interface{}, string, error) {
 				timeMsg = fmt.Sprintf("%d秒", seconds)
 			}
 
-			return nil, "", errors.New(errors.CodeForbidden, fmt.Sprintf("账户已被锁定，请%s后再试", timeMsg))
+			return nil, errors.New(errors.CodeForbidden, fmt.Sprintf("账户已被锁定，请%s后再试", timeMsg))
 		}
 
-		return nil, "", errors.New(errors.CodeForbidden, "账户已被锁定，请稍后再试")
+		return nil, errors.New(errors.CodeForbidden, "账户已被锁定，请稍后再试")
 	}
 
 	attemptKey := fmt.Sprintf("user:login:attempts:%d", user.ID)
@@ -125,45 +156,21 @@ func Login(account, password string) (map[string]interface{}, string, error) {
 			_ = cache.GetCache().Set(lockKey, "1", time.Duration(accountLockoutMinutes)*time.Minute)
 			_ = cache.GetCache().Del(attemptKey)
 
-			return nil, "", errors.New(errors.CodeForbidden,
+			return nil, errors.New(errors.CodeForbidden,
 				fmt.Sprintf("密码错误次数过多，账户已被锁定%d分钟", accountLockoutMinutes))
 		}
 
-		return nil, "", errors.New(errors.CodeWrongPassword,
+		return nil, errors.New(errors.CodeWrongPassword,
 			fmt.Sprintf("密码错误，还有%d次尝试机会", maxLoginAttempts-attemptCount))
 	}
 
 	_ = cache.GetCache().Del(attemptKey)
 
 	if !user.IsNormal() {
-		return nil, "", errors.New(errors.CodeUserDisabled, "账号已被禁用")
-	}
-
-	token, err := auth.GenerateToken(user.ID, user.Username, int(user.Role), jwtSecret, expiresHours)
-	if err != nil {
-		return nil, "", errors.New(errors.CodeInternal, "生成token失败")
-	}
-
-	avatarFullPath := ""
-	if user.Avatar != "" {
-		avatarFullPath = utils.GetSystemFileURL(user.Avatar)
-	} else {
-		avatarFullPath = ""
-	}
-
-	userInfo := map[string]interface{}{
-		"id":             user.ID,
-		"username":       user.Username,
-		"email":          user.Email,
-		"avatar":         user.Avatar,
-		"avatarFullPath": avatarFullPath,
-		"bio":            user.Bio,
-		"website":        user.Website,
-		"role":           user.Role,
-		"status":         user.Status,
+		return nil, errors.New(errors.CodeUserDisabled, "账号已被禁用")
 	}
 
-	return userInfo, token, nil
+	return &user, nil
 }
 
 func FindUsers() ([]models.User, error) {
@@ -518,20 +525,21 @@ func ResetPassword(email, code, newPassword string) error {
 		return errors.New(errors.CodeInternal, "密码加密失败")
 	}
 
-	result := db.Model(&models.User{}).Where("email = ?", email).Update("password", hashedPassword)
-	if result.Error != nil {
-		return errors.New(errors.CodeDBUpdateFailed, "更新密码失败")
+	var user models.User
+	if err := db.Select("id").Where("email = ?", email).First(&user).Error; err != nil {
+		return errors.New(errors.CodeUserNotFound, "未找到用户")
 	}
 
-	if result.RowsAffected == 0 {
-		return errors.New(errors.CodeUserNotFound, "未找到用户")
+	if err := db.Model(&user).Update("password", hashedPassword).Error; err != nil {
+		return errors.New(errors.CodeDBUpdateFailed, "更新密码失败")
 	}
 
+	revokeSessionsAfterPasswordChange(user.ID, "")
 	return nil
 }
 
-/* UpdatePassword 修改密码（需验证旧密码） */
-func UpdatePassword(userID uint, oldPassword, newPassword string) error {
+/* UpdatePassword 修改密码（需验证旧密码），成功后其他设备的登录会话全部失效 */
+func UpdatePassword(userID uint, oldPassword, newPassword, currentSessionID string) error {
 	db := database.GetDB()
 
 	var user models.User
@@ -552,9 +560,17 @@ func UpdatePassword(userID uint, oldPassword, newPassword string) error {
 		return errors.New(errors.CodeDBUpdateFailed, "更新密码失败")
 	}
 
+	revokeSessionsAfterPasswordChange(userID, currentSessionID)
 	return nil
 }
 
+// revokeSessionsAfterPasswordChange 密码变更后撤销登录会话，密码已更新，撤销失败只记录日志
+func revokeSessionsAfterPasswordChange(userID uint, keepSessionID string) {
+	if _, err := auth.RevokeAllSessions(userID, keepSessionID); err != nil {
+		logger.Warn("密码变更后撤销登录会话失败: userID=%d, error=%v", userID, err)
+	}
+}
+
 func SendChangeEmailCode(userID uint, newEmail string) error {
 	currentUser, err := FindUserByID(fmt.Sprintf("%d", userID))
 	if err != nil {
@@ -176,3 +176,16 @@ func UpdateFileUploadStats(tx *gorm.DB, userID uint, fileSize int64) error {
 
 	return nil
 }
+
+/* AdjustFileUsage 调整用户文件数量与存储使用量（移入/移出回收站时使用） */
+func AdjustFileUsage(tx *gorm.DB, userID uint, filesDelta, sizeDelta int64) error {
+	if err := tx.Model(&models.UserUsageStats{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"total_images": getGreatestExpr("total_images", filesDelta),
+			"total_size":   getGreatestExpr("total_size", sizeDelta),
+		}).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBUpdateFailed, "更新用户统计失败")
+	}
+	return nil
+}
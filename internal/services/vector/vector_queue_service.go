@@ -57,11 +57,11 @@ func RegisterVectorConfigHooks() {
 
 		eng := vector.GetGlobalVectorEngine()
 		if eng == nil {
-			// 从数据库读取Qdrant配置并初始化引擎
+			// 从数据库读取向量存储配置并初始化引擎
 			qdrantURL := setting.GetStringDirectFromDB("vector", "qdrant_url", "http://localhost:6333")
 			qdrantTimeout := setting.GetIntDirectFromDB("vector", "qdrant_timeout", 30)
 
-			if err := vector.InitQdrantVectorEngine(qdrantURL, qdrantTimeout); err != nil {
+			if err := vector.InitConfiguredVectorEngine(qdrantURL, qdrantTimeout); err != nil {
 				logger.Error("[向量服务] 初始化向量引擎失败: %v", err)
 				return
 			}
@@ -100,7 +100,7 @@ func RegisterVectorConfigHooks() {
 		}
 	}
 
-	criticalKeys := []string{"vector_enabled", "vector_api_key", "vector_base_url", "vector_model", "qdrant_url"}
+	criticalKeys := []string{"vector_enabled", "vector_api_key", "vector_base_url", "vector_model", "qdrant_url", "image_embedding_enabled", "image_embedding_base_url"}
 	for _, key := range criticalKeys {
 		setting.RegisterSettingChangeHandler("vector", key, func(value string) {
 			handleVectorConfigChange()
@@ -624,42 +624,8 @@ func GetQdrantRealStats() (*QdrantRealStatsResponse, error) {
 		return nil, fmt.Errorf("查询 MySQL 已完成记录数失败: %v", err)
 	}
 
-	qdrantURL := setting.GetStringDirectFromDB("vector", "qdrant_url", "")
-	if qdrantURL == "" {
-		return &QdrantRealStatsResponse{
-			QdrantVectorCount:   0,
-			QdrantIndexedCount:  0,
-			MySQLTotalCount:     mysqlTotal,
-			MySQLCompletedCount: mysqlCompleted,
-			SyncRatio:           0,
-			IsHealthy:           false,
-			CollectionStatus:    "engine_not_initialized",
-			LastChecked:         time.Now().Format("2006-01-02 15:04:05"),
-		}, nil
-	}
-
-	qdrantTimeout := setting.GetIntDirectFromDB("vector", "qdrant_timeout", 30)
-	if qdrantTimeout <= 0 {
-		qdrantTimeout = 30
-	}
-
-	client := vector.NewQdrantClient(qdrantURL, qdrantTimeout)
-
-	if err := client.HealthCheck(); err != nil {
-		return &QdrantRealStatsResponse{
-			QdrantVectorCount:   0,
-			QdrantIndexedCount:  0,
-			MySQLTotalCount:     mysqlTotal,
-			MySQLCompletedCount: mysqlCompleted,
-			SyncRatio:           0,
-			IsHealthy:           false,
-			CollectionStatus:    "qdrant_connection_failed",
-			LastChecked:         time.Now().Format("2006-01-02 15:04:05"),
-		}, nil
-	}
-
-	storageStats, err := client.GetStorageStats()
-	if err != nil {
+	storageStats, failedStatus := fetchVectorStorageStats()
+	if failedStatus != "" {
 		return &QdrantRealStatsResponse{
 			QdrantVectorCount:   0,
 			QdrantIndexedCount:  0,
@@ -667,7 +633,7 @@ func GetQdrantRealStats() (*QdrantRealStatsResponse, error) {
 			MySQLCompletedCount: mysqlCompleted,
 			SyncRatio:           0,
 			IsHealthy:           false,
-			CollectionStatus:    "qdrant_connection_failed",
+			CollectionStatus:    failedStatus,
 			LastChecked:         time.Now().Format("2006-01-02 15:04:05"),
 		}, nil
 	}
@@ -722,3 +688,34 @@ func GetQdrantRealStats() (*QdrantRealStatsResponse, error) {
 		LastChecked:         time.Now().Format("2006-01-02 15:04:05"),
 	}, nil
 }
+
+// fetchVectorStorageStats 获取向量存储的实际统计：内置存储直接读取引擎，Qdrant 按配置地址连接；失败时返回集合状态
+func fetchVectorStorageStats() (*vector.VectorStorageStats, string) {
+	if eng := vector.GetGlobalVectorEngine(); eng != nil && eng.StoreType() == vector.StoreEmbedded {
+		stats, err := eng.GetStorageStats()
+		if err != nil {
+			return nil, "embedded_store_failed"
+		}
+		return stats, ""
+	}
+
+	qdrantURL := setting.GetStringDirectFromDB("vector", "qdrant_url", "")
+	if qdrantURL == "" {
+		return nil, "engine_not_initialized"
+	}
+
+	qdrantTimeout := setting.GetIntDirectFromDB("vector", "qdrant_timeout", 30)
+	if qdrantTimeout <= 0 {
+		qdrantTimeout = 30
+	}
+
+	client := vector.NewQdrantClient(qdrantURL, qdrantTimeout)
+	if err := client.HealthCheck(); err != nil {
+		return nil, "qdrant_connection_failed"
+	}
+	stats, err := client.GetStorageStats()
+	if err != nil {
+		return nil, "qdrant_connection_failed"
+	}
+	return stats, ""
+}
@@ -0,0 +1,59 @@
+package webdav
+
+import (
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/apikey"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/internal/services/user"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+)
+
+/* Identity WebDAV 调用方：账号密码登录时挂载整个文件库，API 密钥限定了文件夹时以该文件夹为根 */
+type Identity struct {
+	UserID uint
+	Key    *models.APIKey
+	RootID string
+}
+
+/* Enabled WebDAV 接口是否启用 */
+func Enabled() bool {
+	return setting.GetBool("upload", "webdav_enabled", false)
+}
+
+/* Authenticate 校验基础认证凭证：密码为API密钥值时按密钥认证，否则按账号密码认证（未开启两步验证的账号） */
+func Authenticate(username, password string) (*Identity, error) {
+	if password == "" {
+		return nil, errors.New(errors.CodeUnauthorized, "未提供认证信息")
+	}
+
+	key, err := apikey.ValidateAPIKey(password)
+	if err == nil {
+		if key.WorkspaceID != "" {
+			return nil, errors.New(errors.CodeForbidden, "工作区API密钥不支持WebDAV访问")
+		}
+		if key.FolderID != "" {
+			var count int64
+			if err := database.DB.Model(&models.Folder{}).Where("id = ? AND user_id = ?", key.FolderID, key.UserID).Count(&count).Error; err != nil {
+				return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹失败")
+			}
+			if count == 0 {
+				return nil, errors.New(errors.CodeForbidden, "API密钥绑定的文件夹不存在")
+			}
+		}
+		return &Identity{UserID: key.UserID, Key: key, RootID: key.FolderID}, nil
+	}
+	if e, ok := err.(*errors.Error); ok && (e.Code == errors.CodeForbidden || e.Code == errors.CodeDBQueryFailed) {
+		return nil, err
+	}
+
+	u, err := user.VerifyCredentials(username, password)
+	if err != nil {
+		return nil, err
+	}
+	// 基础认证无法完成两步验证，开启两步验证的账号只能使用API密钥挂载
+	if user.RequiresSecondFactor(u) {
+		return nil, errors.New(errors.CodeUnauthorized, "账号已开启两步验证，请使用API密钥作为WebDAV密码")
+	}
+	return &Identity{UserID: u.ID}, nil
+}
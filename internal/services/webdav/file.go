@@ -0,0 +1,279 @@
+package webdav
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"pixelpunk/internal/models"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/errors"
+
+	dav "golang.org/x/net/webdav"
+)
+
+/* fileInfo 实现 os.FileInfo，并提供内容类型与 ETag 以免 PROPFIND 时读取文件内容 */
+type fileInfo struct {
+	name        string
+	size        int64
+	modTime     time.Time
+	dir         bool
+	etag        string
+	contentType string
+}
+
+func newFileInfo(file *models.File) *fileInfo {
+	contentType := file.MimeType
+	if contentType == "" {
+		contentType = file.Mime
+	}
+	etag := file.MD5Hash
+	if etag == "" {
+		etag = file.ID
+	}
+	return &fileInfo{
+		name:        file.OriginalName,
+		size:        file.Size,
+		modTime:     time.Time(file.UpdatedAt),
+		etag:        etag,
+		contentType: contentType,
+	}
+}
+
+func (fi *fileInfo) Name() string       { return fi.name }
+func (fi *fileInfo) Size() int64        { return fi.size }
+func (fi *fileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *fileInfo) IsDir() bool        { return fi.dir }
+func (fi *fileInfo) Sys() interface{}   { return nil }
+
+func (fi *fileInfo) Mode() os.FileMode {
+	if fi.dir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+
+func (fi *fileInfo) ContentType(ctx context.Context) (string, error) {
+	if fi.contentType == "" {
+		return "", dav.ErrNotImplemented
+	}
+	return fi.contentType, nil
+}
+
+func (fi *fileInfo) ETag(ctx context.Context) (string, error) {
+	if fi.etag == "" {
+		return "", dav.ErrNotImplemented
+	}
+	return `"` + fi.etag + `"`, nil
+}
+
+// dirFile 打开的目录，仅支持列出子项
+type dirFile struct {
+	fs       *FileSystem
+	name     string
+	folderID string
+	info     *fileInfo
+	entries  []os.FileInfo
+	loaded   bool
+	pos      int
+}
+
+func (d *dirFile) Readdir(count int) ([]os.FileInfo, error) {
+	if !d.loaded {
+		entries, err := d.fs.listDir(d.name, d.folderID)
+		if err != nil {
+			return nil, err
+		}
+		d.entries, d.loaded = entries, true
+	}
+	rest := d.entries[d.pos:]
+	if count <= 0 {
+		d.pos = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(rest) {
+		count = len(rest)
+	}
+	d.pos += count
+	return rest[:count], nil
+}
+
+func (d *dirFile) Stat() (os.FileInfo, error)                   { return d.info, nil }
+func (d *dirFile) Read(p []byte) (int, error)                   { return 0, os.ErrInvalid }
+func (d *dirFile) Seek(offset int64, whence int) (int64, error) { return 0, nil }
+func (d *dirFile) Write(p []byte) (int, error)                  { return 0, os.ErrPermission }
+func (d *dirFile) Close() error                                 { return nil }
+
+// readFile 只读文件，首次读取时才加载内容（主渠道不可用时按副本故障转移）
+type readFile struct {
+	file   *models.File
+	info   *fileInfo
+	r      *bytes.Reader
+	offset int64
+}
+
+func (f *readFile) load() error {
+	if f.r != nil {
+		return nil
+	}
+	var data []byte
+	if f.file != nil {
+		var err error
+		if data, err = filesvc.ReadFileContent(*f.file, false); err != nil {
+			return err
+		}
+	}
+	f.r = bytes.NewReader(data)
+	_, err := f.r.Seek(f.offset, io.SeekStart)
+	return err
+}
+
+func (f *readFile) Read(p []byte) (int, error) {
+	if err := f.load(); err != nil {
+		return 0, err
+	}
+	return f.r.Read(p)
+}
+
+func (f *readFile) Seek(offset int64, whence int) (int64, error) {
+	if f.r == nil && whence != io.SeekEnd {
+		if whence == io.SeekCurrent {
+			offset += f.offset
+		}
+		if offset < 0 {
+			return 0, os.ErrInvalid
+		}
+		f.offset = offset
+		return offset, nil
+	}
+	// 记录中的大小可能与实际内容不一致，按实际内容计算末尾位置
+	if err := f.load(); err != nil {
+		return 0, err
+	}
+	return f.r.Seek(offset, whence)
+}
+
+func (f *readFile) Readdir(count int) ([]os.FileInfo, error) { return nil, os.ErrInvalid }
+func (f *readFile) Stat() (os.FileInfo, error)               { return f.info, nil }
+func (f *readFile) Write(p []byte) (int, error)              { return 0, os.ErrPermission }
+func (f *readFile) Close() error                             { return nil }
+
+// writeFile 写入的内容通过管道流入上传流程，Stat 或 Close 时提交并等待上传完成
+type writeFile struct {
+	ctx      context.Context
+	fs       *FileSystem
+	name     string
+	folderID string
+	base     string
+
+	pw       *io.PipeWriter
+	done     chan struct{}
+	result   *models.File
+	err      error
+	writeErr error
+	closed   bool
+	info     *fileInfo
+}
+
+func newWriteFile(ctx context.Context, fs *FileSystem, name, folderID, base string) *writeFile {
+	return &writeFile{ctx: ctx, fs: fs, name: name, folderID: folderID, base: base}
+}
+
+func (w *writeFile) start() {
+	pr, pw := io.Pipe()
+	w.pw, w.done = pw, make(chan struct{})
+	go func() {
+		defer close(w.done)
+		w.result, w.err = w.fs.upload(w.folderID, w.base, pr)
+		if w.err != nil {
+			pr.CloseWithError(w.err)
+		} else {
+			pr.Close()
+		}
+	}()
+}
+
+func (w *writeFile) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if len(p) == 0 || isIgnoredName(w.base) {
+		return len(p), nil
+	}
+	if w.pw == nil {
+		w.start()
+	}
+	n, err := w.pw.Write(p)
+	if err != nil && w.writeErr == nil {
+		w.writeErr = err
+	}
+	return n, err
+}
+
+func (w *writeFile) finish() error {
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+
+	if w.pw == nil {
+		// 空文件：已有同名文件时保持不变，否则仅保留占位
+		if !isIgnoredName(w.base) {
+			existing, err := w.fs.findFile(w.folderID, w.base)
+			if err != nil {
+				w.err = err
+				return err
+			}
+			if existing != nil {
+				w.info = newFileInfo(existing)
+				return nil
+			}
+		}
+		w.info = w.fs.putPlaceholder(w.name)
+		return nil
+	}
+
+	switch {
+	case w.writeErr != nil:
+		w.pw.CloseWithError(w.writeErr)
+	case w.ctx.Err() != nil:
+		// 客户端中途断开时不提交不完整的内容
+		w.pw.CloseWithError(w.ctx.Err())
+	default:
+		w.pw.Close()
+	}
+	<-w.done
+	if w.err == nil && w.writeErr != nil {
+		w.err = w.writeErr
+	}
+	if w.err != nil {
+		return w.err
+	}
+	w.fs.removePlaceholder(w.name)
+	w.info = newFileInfo(w.result)
+	return nil
+}
+
+func (w *writeFile) Stat() (os.FileInfo, error) {
+	if err := w.finish(); err != nil {
+		return nil, err
+	}
+	return w.info, nil
+}
+
+func (w *writeFile) Close() error {
+	return w.finish()
+}
+
+func (w *writeFile) Read(p []byte) (int, error) { return 0, os.ErrInvalid }
+
+func (w *writeFile) Seek(offset int64, whence int) (int64, error) {
+	return 0, errors.New(errors.CodeInvalidParameter, "写入中的文件不支持定位")
+}
+
+func (w *writeFile) Readdir(count int) ([]os.FileInfo, error) { return nil, os.ErrInvalid }
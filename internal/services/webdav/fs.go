@@ -0,0 +1,424 @@
+package webdav
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"pixelpunk/internal/models"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/internal/services/folder"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/internal/services/workspace"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+
+	dav "golang.org/x/net/webdav"
+	"gorm.io/gorm"
+)
+
+/* FileSystem 将用户的文件夹与文件映射为 WebDAV 目录树，实现 webdav.FileSystem */
+type FileSystem struct {
+	id *Identity
+}
+
+/* NewFileSystem 创建调用方的文件系统视图 */
+func NewFileSystem(id *Identity) *FileSystem {
+	return &FileSystem{id: id}
+}
+
+var lockSystems sync.Map
+
+/* LockSystemFor 返回调用方挂载点对应的锁管理器，不同用户的同名路径互不影响 */
+func LockSystemFor(id *Identity) dav.LockSystem {
+	key := fmt.Sprintf("%d:%s", id.UserID, id.RootID)
+	ls, _ := lockSystems.LoadOrStore(key, dav.NewMemLS())
+	return ls.(dav.LockSystem)
+}
+
+// entry 解析路径得到的目录或文件
+type entry struct {
+	name     string
+	folderID string
+	file     *models.File
+}
+
+func (e *entry) isDir() bool {
+	return e.file == nil
+}
+
+func splitPath(name string) []string {
+	cleaned := strings.Trim(path.Clean("/"+name), "/")
+	if cleaned == "" {
+		return nil
+	}
+	return strings.Split(cleaned, "/")
+}
+
+// childFolder 查找父目录下的同名子文件夹
+func (fs *FileSystem) childFolder(parentID, name string) (*models.Folder, error) {
+	var f models.Folder
+	err := database.DB.Scopes(workspace.PersonalScope(fs.id.UserID)).Where("parent_id = ? AND name = ?", parentID, name).First(&f).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹失败")
+	}
+	return &f, nil
+}
+
+// findFolderID 在挂载根目录下逐级查找文件夹
+func (fs *FileSystem) findFolderID(segments []string) (string, error) {
+	current := fs.id.RootID
+	for _, name := range segments {
+		f, err := fs.childFolder(current, name)
+		if err != nil {
+			return "", err
+		}
+		if f == nil {
+			return "", os.ErrNotExist
+		}
+		current = f.ID
+	}
+	return current, nil
+}
+
+func (fs *FileSystem) filesQuery(folderID string) *gorm.DB {
+	query := database.DB.Model(&models.File{}).
+		Scopes(workspace.PersonalScope(fs.id.UserID)).
+		Where("status <> ?", filesvc.StatusPendingDeletion)
+	if folderID == "" {
+		return query.Where("folder_id = '' OR folder_id IS NULL")
+	}
+	return query.Where("folder_id = ?", folderID)
+}
+
+// findFile 查找目录下的同名文件，同名时取最新上传的一个
+func (fs *FileSystem) findFile(folderID, name string) (*models.File, error) {
+	var file models.File
+	err := fs.filesQuery(folderID).Where("original_name = ?", name).Order("created_at DESC").First(&file).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	}
+	return &file, nil
+}
+
+func (fs *FileSystem) resolve(name string) (*entry, error) {
+	segments := splitPath(name)
+	if len(segments) == 0 {
+		return &entry{name: "/", folderID: fs.id.RootID}, nil
+	}
+	parentID, err := fs.findFolderID(segments[:len(segments)-1])
+	if err != nil {
+		return nil, err
+	}
+	base := segments[len(segments)-1]
+	f, err := fs.childFolder(parentID, base)
+	if err != nil {
+		return nil, err
+	}
+	if f != nil {
+		return &entry{name: base, folderID: f.ID}, nil
+	}
+	file, err := fs.findFile(parentID, base)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, os.ErrNotExist
+	}
+	return &entry{name: base, folderID: parentID, file: file}, nil
+}
+
+func (fs *FileSystem) stat(e *entry) (*fileInfo, error) {
+	if !e.isDir() {
+		return newFileInfo(e.file), nil
+	}
+	info := &fileInfo{name: e.name, dir: true}
+	if e.folderID != "" {
+		var f models.Folder
+		if err := database.DB.Select("updated_at").Where("id = ?", e.folderID).First(&f).Error; err == nil {
+			info.modTime = time.Time(f.UpdatedAt)
+		}
+	}
+	return info, nil
+}
+
+/* Stat 返回路径对应的目录或文件信息 */
+func (fs *FileSystem) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	if info := fs.placeholderInfo(name); info != nil {
+		return info, nil
+	}
+	e, err := fs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.stat(e)
+}
+
+/* Mkdir 创建文件夹，父目录不存在时返回 os.ErrNotExist */
+func (fs *FileSystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	segments := splitPath(name)
+	if len(segments) == 0 {
+		return os.ErrExist
+	}
+	parentID, err := fs.findFolderID(segments[:len(segments)-1])
+	if err != nil {
+		return err
+	}
+	if _, err := fs.resolve(name); err == nil {
+		return os.ErrExist
+	} else if err != os.ErrNotExist {
+		return err
+	}
+	_, err = folder.CreateFolder(fs.id.UserID, segments[len(segments)-1], parentID, "", "")
+	return err
+}
+
+/* OpenFile 以只读方式打开目录或文件；写入时经常规上传流程保存，关闭时提交 */
+func (fs *FileSystem) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (dav.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC) == 0 {
+		if p := fs.placeholderInfo(name); p != nil {
+			return &readFile{info: p}, nil
+		}
+		e, err := fs.resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		info, err := fs.stat(e)
+		if err != nil {
+			return nil, err
+		}
+		if e.isDir() {
+			return &dirFile{fs: fs, name: name, folderID: e.folderID, info: info}, nil
+		}
+		return &readFile{file: e.file, info: info}, nil
+	}
+
+	segments := splitPath(name)
+	if len(segments) == 0 {
+		return nil, os.ErrPermission
+	}
+	parentID, err := fs.findFolderID(segments[:len(segments)-1])
+	if err != nil {
+		return nil, err
+	}
+	base := segments[len(segments)-1]
+	if f, err := fs.childFolder(parentID, base); err != nil {
+		return nil, err
+	} else if f != nil {
+		return nil, os.ErrExist
+	}
+	if flag&os.O_EXCL != 0 {
+		if fs.placeholderInfo(name) != nil {
+			return nil, os.ErrExist
+		}
+		if file, err := fs.findFile(parentID, base); err != nil {
+			return nil, err
+		} else if file != nil {
+			return nil, os.ErrExist
+		}
+	}
+	return newWriteFile(ctx, fs, name, parentID, base), nil
+}
+
+/* RemoveAll 删除文件或整个文件夹（启用回收站时移入回收站） */
+func (fs *FileSystem) RemoveAll(ctx context.Context, name string) error {
+	if len(splitPath(name)) == 0 {
+		return os.ErrPermission
+	}
+	if fs.removePlaceholder(name) {
+		return nil
+	}
+	e, err := fs.resolve(name)
+	if err != nil {
+		return err
+	}
+	if !e.isDir() {
+		return filesvc.DeleteFile(fs.id.UserID, e.file.ID)
+	}
+	if filesvc.TrashEnabled() {
+		return filesvc.MoveFolderToTrash(fs.id.UserID, e.folderID)
+	}
+	return fs.purgeFolder(e.folderID)
+}
+
+// purgeFolder 未启用回收站时逐个删除文件，再由深到浅删除文件夹
+func (fs *FileSystem) purgeFolder(folderID string) error {
+	levels := [][]string{{folderID}}
+	for {
+		var children []string
+		if err := database.DB.Model(&models.Folder{}).
+			Scopes(workspace.PersonalScope(fs.id.UserID)).Where("parent_id IN ?", levels[len(levels)-1]).
+			Pluck("id", &children).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBQueryFailed, "查询子文件夹失败")
+		}
+		if len(children) == 0 {
+			break
+		}
+		levels = append(levels, children)
+	}
+
+	for i := len(levels) - 1; i >= 0; i-- {
+		for _, id := range levels[i] {
+			var fileIDs []string
+			if err := fs.filesQuery(id).Pluck("id", &fileIDs).Error; err != nil {
+				return errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+			}
+			for _, fileID := range fileIDs {
+				if err := filesvc.DeleteFile(fs.id.UserID, fileID); err != nil {
+					return err
+				}
+			}
+			if err := folder.DeleteFolder(fs.id.UserID, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+/* Rename 移动或重命名文件与文件夹，跨目录移动复用 MoveFiles/MoveFolders */
+func (fs *FileSystem) Rename(ctx context.Context, oldName, newName string) error {
+	oldSegments, newSegments := splitPath(oldName), splitPath(newName)
+	if len(oldSegments) == 0 || len(newSegments) == 0 {
+		return os.ErrPermission
+	}
+	parentID, err := fs.findFolderID(newSegments[:len(newSegments)-1])
+	if err != nil {
+		return err
+	}
+	base := newSegments[len(newSegments)-1]
+
+	if fs.renamePlaceholder(oldName, newName) {
+		return nil
+	}
+	e, err := fs.resolve(oldName)
+	if err != nil {
+		return err
+	}
+
+	if e.isDir() {
+		var f models.Folder
+		if err := database.DB.Scopes(workspace.PersonalScope(fs.id.UserID)).Where("id = ?", e.folderID).First(&f).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹失败")
+		}
+		if f.ParentID != parentID {
+			if err := folder.MoveFolders(fs.id.UserID, []string{f.ID}, parentID); err != nil {
+				return err
+			}
+		}
+		if f.Name != base {
+			if _, err := folder.UpdateFolder(fs.id.UserID, f.ID, base, "", "", ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if e.file.FolderID != parentID {
+		if err := filesvc.MoveFiles(fs.id.UserID, []string{e.file.ID}, parentID); err != nil {
+			return err
+		}
+	}
+	if e.file.OriginalName != base {
+		return filesvc.RenameFile(fs.id.UserID, e.file.ID, base)
+	}
+	return nil
+}
+
+// bodyReader 限制单个文件大小，并记录读取请求体时的首个错误
+type bodyReader struct {
+	r     io.Reader
+	limit int64
+	read  int64
+	err   error
+}
+
+func (br *bodyReader) Read(p []byte) (int, error) {
+	n, err := br.r.Read(p)
+	br.read += int64(n)
+	if br.limit > 0 && br.read > br.limit {
+		err = errors.New(errors.CodeFileTooLarge, "文件大小超过限制")
+	}
+	if err != nil && err != io.EOF && br.err == nil {
+		br.err = err
+	}
+	return n, err
+}
+
+// upload 经常规上传流程保存文件；同名文件已存在时上传成功后删除旧文件
+func (fs *FileSystem) upload(folderID, name string, body io.Reader) (*models.File, error) {
+	existing, err := fs.findFile(folderID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	br := &bodyReader{r: body, limit: int64(setting.GetInt("upload", "max_file_size", 20)) << 20}
+	var file *models.File
+	if fs.id.Key != nil {
+		file, err = filesvc.UploadReaderWithAPIKey(fs.id.Key, folderID, name, contentType, "", br)
+	} else {
+		file, err = filesvc.UploadReader(fs.id.UserID, folderID, name, contentType, "", br)
+	}
+	if br.err != nil {
+		return nil, br.err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil && existing.ID != file.ID {
+		if err := filesvc.DeleteFile(fs.id.UserID, existing.ID); err != nil {
+			logger.Warn("WebDAV覆盖写入后删除旧文件失败 [%s]: %v", existing.ID, err)
+		}
+	}
+	return file, nil
+}
+
+// listDir 列出目录下的子文件夹与文件，同名文件只保留最新的一个
+func (fs *FileSystem) listDir(name, folderID string) ([]os.FileInfo, error) {
+	var folders []models.Folder
+	if err := database.DB.Scopes(workspace.PersonalScope(fs.id.UserID)).Where("parent_id = ?", folderID).
+		Order("sort_order ASC, name ASC").Find(&folders).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹失败")
+	}
+	var files []models.File
+	if err := fs.filesQuery(folderID).Order("created_at DESC").Find(&files).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	}
+
+	seen := make(map[string]bool, len(folders)+len(files))
+	infos := make([]os.FileInfo, 0, len(folders)+len(files))
+	for _, f := range folders {
+		seen[f.Name] = true
+		infos = append(infos, &fileInfo{name: f.Name, dir: true, modTime: time.Time(f.UpdatedAt)})
+	}
+	for i := range files {
+		name := files[i].OriginalName
+		if name == "" || strings.Contains(name, "/") || seen[name] {
+			continue
+		}
+		seen[name] = true
+		infos = append(infos, newFileInfo(&files[i]))
+	}
+	for _, p := range fs.listPlaceholders(name) {
+		if !seen[p.name] {
+			infos = append(infos, p)
+		}
+	}
+	return infos, nil
+}
@@ -0,0 +1,51 @@
+package webdav
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestSplitPath(t *testing.T) {
+	cases := map[string][]string{
+		"":              nil,
+		"/":             nil,
+		"/a/b/":         {"a", "b"},
+		"a//b/./c.jpg":  {"a", "b", "c.jpg"},
+		"/a/../../etc/": {"etc"},
+	}
+	for in, want := range cases {
+		if got := splitPath(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitPath(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestDirFileReaddir(t *testing.T) {
+	d := &dirFile{loaded: true, entries: []os.FileInfo{&fileInfo{name: "a"}, &fileInfo{name: "b"}, &fileInfo{name: "c"}}}
+	first, err := d.Readdir(2)
+	if err != nil || len(first) != 2 {
+		t.Fatalf("first page = %d, %v", len(first), err)
+	}
+	rest, err := d.Readdir(2)
+	if err != nil || len(rest) != 1 || rest[0].Name() != "c" {
+		t.Fatalf("second page = %v, %v", rest, err)
+	}
+	if _, err := d.Readdir(2); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestReadFileSeekBeforeLoad(t *testing.T) {
+	f := &readFile{info: &fileInfo{name: "empty.jpg"}}
+	if n, err := f.Seek(0, io.SeekStart); err != nil || n != 0 {
+		t.Fatalf("seek start = %d, %v", n, err)
+	}
+	if n, err := f.Seek(0, io.SeekEnd); err != nil || n != 0 {
+		t.Fatalf("seek end = %d, %v", n, err)
+	}
+	if data, err := io.ReadAll(f); err != nil || len(data) != 0 {
+		t.Fatalf("read = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,97 @@
+package webdav
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+ * Finder/资源管理器复制文件时会先写入空文件再写入内容，并附带 ._*、.DS_Store 等元数据文件。
+ * 这些内容无法也无需进入上传流程，只在内存中短暂保留，使客户端的后续请求能正常完成。
+ */
+
+const placeholderTTL = 10 * time.Minute
+
+type placeholder struct {
+	info    *fileInfo
+	expires time.Time
+}
+
+var placeholders sync.Map
+
+func isIgnoredName(name string) bool {
+	switch name {
+	case ".DS_Store", "Thumbs.db", "desktop.ini", ".localized":
+		return true
+	}
+	return strings.HasPrefix(name, "._")
+}
+
+func (fs *FileSystem) placeholderKey(name string) string {
+	return fmt.Sprintf("%d:%s:/%s", fs.id.UserID, fs.id.RootID, strings.Join(splitPath(name), "/"))
+}
+
+func (fs *FileSystem) putPlaceholder(name string) *fileInfo {
+	info := &fileInfo{name: path.Base("/" + strings.Join(splitPath(name), "/")), modTime: time.Now()}
+	placeholders.Store(fs.placeholderKey(name), &placeholder{info: info, expires: time.Now().Add(placeholderTTL)})
+	return info
+}
+
+func (fs *FileSystem) placeholderInfo(name string) *fileInfo {
+	key := fs.placeholderKey(name)
+	v, ok := placeholders.Load(key)
+	if !ok {
+		return nil
+	}
+	p := v.(*placeholder)
+	if time.Now().After(p.expires) {
+		placeholders.Delete(key)
+		return nil
+	}
+	return p.info
+}
+
+func (fs *FileSystem) removePlaceholder(name string) bool {
+	_, ok := placeholders.LoadAndDelete(fs.placeholderKey(name))
+	return ok
+}
+
+func (fs *FileSystem) renamePlaceholder(oldName, newName string) bool {
+	v, ok := placeholders.LoadAndDelete(fs.placeholderKey(oldName))
+	if !ok {
+		return false
+	}
+	p := v.(*placeholder)
+	info := *p.info
+	info.name = path.Base("/" + strings.Join(splitPath(newName), "/"))
+	placeholders.Store(fs.placeholderKey(newName), &placeholder{info: &info, expires: p.expires})
+	return true
+}
+
+// listPlaceholders 列出目录下仍有效的空文件占位，系统元数据文件不展示
+func (fs *FileSystem) listPlaceholders(dirName string) []*fileInfo {
+	prefix := fs.placeholderKey(dirName)
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	now := time.Now()
+	var infos []*fileInfo
+	placeholders.Range(func(k, v interface{}) bool {
+		key := k.(string)
+		p := v.(*placeholder)
+		if now.After(p.expires) {
+			placeholders.Delete(key)
+			return true
+		}
+		rest := strings.TrimPrefix(key, prefix)
+		if rest == key || strings.Contains(rest, "/") || isIgnoredName(rest) {
+			return true
+		}
+		infos = append(infos, p.info)
+		return true
+	})
+	return infos
+}
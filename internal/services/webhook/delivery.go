@@ -0,0 +1,201 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"pixelpunk/internal/models"
+	qqueue "pixelpunk/internal/queue"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/utils"
+)
+
+const (
+	deliveryWorkers    = 4
+	deliveryLease      = 2 * time.Minute
+	retryBaseDelay     = 30 * time.Second
+	retryMaxDelay      = 6 * time.Hour
+	responseBodyLimit  = 2048
+	defaultMaxAttempts = 8
+	defaultTimeoutSecs = 10
+	idlePollInterval   = 5 * time.Second
+	webhookUserAgent   = "PixelPunk-Webhook/1.0"
+)
+
+var (
+	deliveryQueue qqueue.Queue
+	initOnce      sync.Once
+	wakeCh        = make(chan struct{}, 1)
+)
+
+/* InitWebhookDelivery 启动回调投递队列的后台协程 */
+func InitWebhookDelivery() {
+	initOnce.Do(func() {
+		deliveryQueue = qqueue.NewDBQueueWebhook()
+		for i := 0; i < deliveryWorkers; i++ {
+			go deliveryWorker()
+		}
+	})
+}
+
+// wake 有新投递时唤醒空闲的协程
+func wake() {
+	select {
+	case wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+func deliveryWorker() {
+	for {
+		task, ack, nack, err := deliveryQueue.Fetch(deliveryLease)
+		if err != nil || task == nil {
+			select {
+			case <-wakeCh:
+			case <-time.After(idlePollInterval):
+			}
+			continue
+		}
+		processDelivery(task.FileID, ack, nack)
+	}
+}
+
+// RetryDelay 第 attempt 次（从1开始）投递失败后的等待时间，指数退避并设置上限
+func RetryDelay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := retryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
+
+func maxAttempts() int {
+	n := setting.GetInt("security", "webhook_max_attempts", defaultMaxAttempts)
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+func processDelivery(deliveryID string, ack qqueue.AckFunc, nack qqueue.NackFunc) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("回调投递异常 [%s]: %v", deliveryID, r)
+			_ = nack(RetryDelay(1), false, fmt.Sprintf("panic: %v", r))
+		}
+	}()
+
+	db := database.GetDB()
+	var delivery models.WebhookDelivery
+	if err := db.Where("id = ?", deliveryID).Take(&delivery).Error; err != nil {
+		_ = ack()
+		return
+	}
+	var hook models.Webhook
+	if err := db.Where("id = ?", delivery.WebhookID).Take(&hook).Error; err != nil {
+		_ = nack(0, true, "回调订阅已删除")
+		return
+	}
+	if !hook.Enabled {
+		_ = nack(0, true, "回调订阅已停用")
+		return
+	}
+
+	attempt := send(&hook, &delivery)
+	updateWebhookStatus(hook.ID, attempt.Error == "")
+
+	if attempt.Error == "" {
+		if err := ack(); err != nil {
+			logger.Warn("确认回调投递失败 [%s]: %v", deliveryID, err)
+		}
+		return
+	}
+	if delivery.Attempt+1 >= maxAttempts() {
+		_ = nack(0, true, attempt.Error)
+		return
+	}
+	_ = nack(RetryDelay(delivery.Attempt+1), false, attempt.Error)
+}
+
+// send 执行一次投递并写入尝试记录，返回的记录 Error 为空表示成功
+func send(hook *models.Webhook, delivery *models.WebhookDelivery) *models.WebhookDeliveryAttempt {
+	attempt := &models.WebhookDeliveryAttempt{
+		DeliveryID: delivery.ID,
+		WebhookID:  hook.ID,
+		Attempt:    delivery.Attempt + 1,
+	}
+
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+	timeout := time.Duration(setting.GetInt("security", "webhook_timeout_seconds", defaultTimeoutSecs)) * time.Second
+	if timeout <= 0 {
+		timeout = defaultTimeoutSecs * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = "构建请求失败: " + err.Error()
+	} else {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", webhookUserAgent)
+		req.Header.Set(HeaderEvent, delivery.Event)
+		req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+		req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(HeaderSignature, Sign(hook.Secret, timestamp, body))
+
+		resp, err := httpClient().Do(req)
+		if err != nil {
+			attempt.Error = err.Error()
+		} else {
+			respBody, _ := io.ReadAll(io.LimitReader(resp.Body, responseBodyLimit))
+			resp.Body.Close()
+			attempt.StatusCode = resp.StatusCode
+			attempt.ResponseBody = string(respBody)
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				attempt.Error = fmt.Sprintf("响应状态码 %d", resp.StatusCode)
+			}
+		}
+	}
+	attempt.LatencyMs = time.Since(start).Milliseconds()
+
+	db := database.GetDB()
+	if err := db.Create(attempt).Error; err != nil {
+		logger.Warn("保存回调投递记录失败: %v", err)
+	}
+	db.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).Update("last_status_code", attempt.StatusCode)
+	return attempt
+}
+
+func updateWebhookStatus(webhookID uint, success bool) {
+	status := models.WebhookDeliveryDone
+	if !success {
+		status = models.WebhookDeliveryFailed
+	}
+	database.GetDB().Model(&models.Webhook{}).Where("id = ?", webhookID).Updates(map[string]interface{}{
+		"last_delivery_at": time.Now(),
+		"last_status":      status,
+	})
+}
+
+// httpClient 不跟随重定向；未允许内网投递时拒绝连接内网地址
+func httpClient() *http.Client {
+	return utils.NewSafeHTTPClient(setting.GetBool("security", "webhook_allow_private_network", false))
+}
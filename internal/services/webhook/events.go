@@ -0,0 +1,160 @@
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/utils"
+)
+
+const (
+	EventFileUploaded  = "file.uploaded"
+	EventFileTagged    = "file.tagged"
+	EventFileApproved  = "file.approved"
+	EventFileRejected  = "file.rejected"
+	EventFileDeleted   = "file.deleted"
+	EventShareAccessed = "share.accessed"
+	EventPing          = "ping" // 手动测试，不受订阅过滤影响
+)
+
+/* AllEvents 可订阅的事件列表 */
+var AllEvents = []string{
+	EventFileUploaded,
+	EventFileTagged,
+	EventFileApproved,
+	EventFileRejected,
+	EventFileDeleted,
+	EventShareAccessed,
+}
+
+/* Payload 投递给订阅方的消息体 */
+type Payload struct {
+	ID        string                 `json:"id"`
+	Event     string                 `json:"event"`
+	CreatedAt string                 `json:"created_at"`
+	UserID    uint                   `json:"user_id"` // 事件所属用户
+	Data      map[string]interface{} `json:"data"`
+}
+
+/* Enabled 出站回调是否启用 */
+func Enabled() bool {
+	return setting.GetBool("security", "webhook_enabled", true)
+}
+
+/* IsValidEvent 是否为可订阅的事件 */
+func IsValidEvent(event string) bool {
+	for _, e := range AllEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// subscribes 判断订阅的事件列表是否包含事件，支持 * 与 file.* 形式的通配
+func subscribes(events, event string) bool {
+	for _, e := range strings.Split(events, ",") {
+		e = strings.TrimSpace(e)
+		if e == "*" || e == event {
+			return true
+		}
+		if strings.HasSuffix(e, ".*") && strings.HasPrefix(event, strings.TrimSuffix(e, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+/* Dispatch 异步派发事件：为所有匹配的订阅生成投递记录，由后台队列负责投递与重试 */
+func Dispatch(event string, userID uint, data map[string]interface{}) {
+	if !Enabled() {
+		return
+	}
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("派发回调事件异常 [%s]: %v", event, r)
+			}
+		}()
+		if err := dispatch(event, userID, data); err != nil {
+			logger.Warn("派发回调事件失败 [%s]: %v", event, err)
+		}
+	}()
+}
+
+func dispatch(event string, userID uint, data map[string]interface{}) error {
+	db := database.GetDB()
+	if db == nil {
+		return nil
+	}
+
+	var hooks []models.Webhook
+	query := db.Where("enabled = ?", true)
+	if userID > 0 {
+		query = query.Where("scope = ? OR (scope = ? AND user_id = ?)", models.WebhookScopeAdmin, models.WebhookScopeUser, userID)
+	} else {
+		query = query.Where("scope = ?", models.WebhookScopeAdmin)
+	}
+	if err := query.Find(&hooks).Error; err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	var body string
+	eventID := ""
+	for _, hook := range hooks {
+		if !subscribes(hook.Events, event) {
+			continue
+		}
+		if body == "" {
+			eventID = utils.GenerateRandomString(24)
+			raw, err := json.Marshal(Payload{
+				ID:        eventID,
+				Event:     event,
+				CreatedAt: time.Now().Format(time.RFC3339),
+				UserID:    userID,
+				Data:      data,
+			})
+			if err != nil {
+				return err
+			}
+			body = string(raw)
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID: hook.ID,
+			EventID:   eventID,
+			Event:     event,
+			Payload:   body,
+			Status:    models.WebhookDeliveryQueued,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := db.Create(&deliveries).Error; err != nil {
+		return err
+	}
+	wake()
+	return nil
+}
+
+/* FileEventData 文件事件的通用数据 */
+func FileEventData(file *models.File) map[string]interface{} {
+	return map[string]interface{}{
+		"file_id":       file.ID,
+		"original_name": file.OriginalName,
+		"folder_id":     file.FolderID,
+		"size":          file.Size,
+		"width":         file.Width,
+		"height":        file.Height,
+		"format":        file.Format,
+		"mime":          file.Mime,
+		"access_level":  file.AccessLevel,
+		"url":           utils.GetFileFullURL(file.ID),
+	}
+}
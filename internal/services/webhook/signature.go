@@ -0,0 +1,31 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+const (
+	HeaderEvent     = "X-PixelPunk-Event"
+	HeaderDelivery  = "X-PixelPunk-Delivery"
+	HeaderTimestamp = "X-PixelPunk-Timestamp"
+	HeaderSignature = "X-PixelPunk-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+/* Sign 计算签名：HMAC-SHA256(secret, 时间戳 + "." + 请求体)，接收方应同时校验时间戳以防重放 */
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+/* Verify 校验签名，供接入方参考实现 */
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
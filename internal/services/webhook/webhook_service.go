@@ -0,0 +1,347 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	secretLength = 40
+	maxPerOwner  = 20
+)
+
+/* Owner 订阅的归属：普通用户只管理自己的用户级订阅，管理员管理全站级订阅 */
+type Owner struct {
+	UserID uint
+	Scope  string
+}
+
+func (o Owner) scoped(db *gorm.DB) *gorm.DB {
+	if o.Scope == models.WebhookScopeAdmin {
+		return db.Where("scope = ?", models.WebhookScopeAdmin)
+	}
+	return db.Where("scope = ? AND user_id = ?", models.WebhookScopeUser, o.UserID)
+}
+
+/* WebhookInput 创建或更新订阅的参数 */
+type WebhookInput struct {
+	Name        string
+	URL         string
+	Events      []string
+	Description *string
+	Enabled     *bool
+}
+
+// normalizeEvents 校验并去重订阅的事件
+func normalizeEvents(events []string) (string, error) {
+	if len(events) == 0 {
+		return "", errors.New(errors.CodeInvalidParameter, "至少订阅一个事件")
+	}
+	seen := make(map[string]bool, len(events))
+	result := make([]string, 0, len(events))
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		valid := e == "*" || IsValidEvent(e)
+		if !valid && strings.HasSuffix(e, ".*") {
+			for _, known := range AllEvents {
+				if strings.HasPrefix(known, strings.TrimSuffix(e, "*")) {
+					valid = true
+					break
+				}
+			}
+		}
+		if !valid {
+			return "", errors.New(errors.CodeInvalidParameter, "不支持的事件: "+e)
+		}
+		if !seen[e] {
+			seen[e] = true
+			result = append(result, e)
+		}
+	}
+	return strings.Join(result, ","), nil
+}
+
+func validateURL(raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New(errors.CodeInvalidParameter, "回调地址必须是有效的 http 或 https 地址")
+	}
+	if u.User != nil {
+		return errors.New(errors.CodeInvalidParameter, "回调地址不能包含账号信息")
+	}
+	return nil
+}
+
+/* CreateWebhook 创建订阅，返回的签名密钥仅在创建时完整返回 */
+func CreateWebhook(owner Owner, input WebhookInput) (*models.Webhook, string, error) {
+	if err := validateURL(input.URL); err != nil {
+		return nil, "", err
+	}
+	events, err := normalizeEvents(input.Events)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var count int64
+	if err := owner.scoped(database.DB.Model(&models.Webhook{})).Count(&count).Error; err != nil {
+		return nil, "", errors.Wrap(err, errors.CodeDBQueryFailed, "查询回调订阅失败")
+	}
+	if count >= maxPerOwner {
+		return nil, "", errors.New(errors.CodeInvalidParameter, "回调订阅数量已达上限")
+	}
+
+	hook := &models.Webhook{
+		UserID:  owner.UserID,
+		Scope:   owner.Scope,
+		Name:    strings.TrimSpace(input.Name),
+		URL:     strings.TrimSpace(input.URL),
+		Secret:  "whsec_" + utils.GenerateRandomString(secretLength),
+		Events:  events,
+		Enabled: input.Enabled == nil || *input.Enabled,
+	}
+	if input.Description != nil {
+		hook.Description = *input.Description
+	}
+	if err := database.DB.Create(hook).Error; err != nil {
+		return nil, "", errors.Wrap(err, errors.CodeDBCreateFailed, "创建回调订阅失败")
+	}
+	return hook, hook.Secret, nil
+}
+
+/* ListWebhooks 分页获取订阅 */
+func ListWebhooks(owner Owner, page, size int) ([]models.Webhook, int64, error) {
+	var total int64
+	if err := owner.scoped(database.DB.Model(&models.Webhook{})).Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询回调订阅失败")
+	}
+	var hooks []models.Webhook
+	if err := owner.scoped(database.DB).Order("id DESC").Offset((page - 1) * size).Limit(size).Find(&hooks).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询回调订阅失败")
+	}
+	return hooks, total, nil
+}
+
+/* GetWebhook 获取订阅，不属于调用方时视为不存在 */
+func GetWebhook(owner Owner, id uint) (*models.Webhook, error) {
+	var hook models.Webhook
+	if err := owner.scoped(database.DB).Where("id = ?", id).Take(&hook).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeNotFound, "回调订阅不存在")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询回调订阅失败")
+	}
+	return &hook, nil
+}
+
+/* UpdateWebhook 更新订阅，未提供的字段保持不变 */
+func UpdateWebhook(owner Owner, id uint, input WebhookInput) (*models.Webhook, error) {
+	hook, err := GetWebhook(owner, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if name := strings.TrimSpace(input.Name); name != "" {
+		updates["name"] = name
+	}
+	if input.URL != "" {
+		if err := validateURL(input.URL); err != nil {
+			return nil, err
+		}
+		updates["url"] = strings.TrimSpace(input.URL)
+	}
+	if input.Events != nil {
+		events, err := normalizeEvents(input.Events)
+		if err != nil {
+			return nil, err
+		}
+		updates["events"] = events
+	}
+	if input.Enabled != nil {
+		updates["enabled"] = *input.Enabled
+	}
+	if input.Description != nil {
+		updates["description"] = *input.Description
+	}
+	if len(updates) == 0 {
+		return hook, nil
+	}
+
+	if err := database.DB.Model(hook).Updates(updates).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "更新回调订阅失败")
+	}
+	return GetWebhook(owner, id)
+}
+
+/* DeleteWebhook 删除订阅及其投递记录 */
+func DeleteWebhook(owner Owner, id uint) error {
+	hook, err := GetWebhook(owner, id)
+	if err != nil {
+		return err
+	}
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", hook.ID).Delete(&models.WebhookDeliveryAttempt{}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除投递记录失败")
+		}
+		if err := tx.Where("webhook_id = ?", hook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除投递记录失败")
+		}
+		if err := tx.Delete(hook).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除回调订阅失败")
+		}
+		return nil
+	})
+}
+
+/* RotateSecret 重新生成签名密钥 */
+func RotateSecret(owner Owner, id uint) (string, error) {
+	hook, err := GetWebhook(owner, id)
+	if err != nil {
+		return "", err
+	}
+	secret := "whsec_" + utils.GenerateRandomString(secretLength)
+	if err := database.DB.Model(hook).Update("secret", secret).Error; err != nil {
+		return "", errors.Wrap(err, errors.CodeDBUpdateFailed, "重新生成签名密钥失败")
+	}
+	return secret, nil
+}
+
+/* TestWebhook 立即发送一次 ping 事件并返回本次尝试结果，不进入重试队列 */
+func TestWebhook(owner Owner, id uint) (*models.WebhookDeliveryAttempt, error) {
+	hook, err := GetWebhook(owner, id)
+	if err != nil {
+		return nil, err
+	}
+
+	eventID := utils.GenerateRandomString(24)
+	body, _ := json.Marshal(Payload{
+		ID:        eventID,
+		Event:     EventPing,
+		CreatedAt: time.Now().Format(time.RFC3339),
+		UserID:    owner.UserID,
+		Data:      map[string]interface{}{"webhook_id": hook.ID, "events": strings.Split(hook.Events, ",")},
+	})
+	delivery := &models.WebhookDelivery{
+		WebhookID: hook.ID,
+		EventID:   eventID,
+		Event:     EventPing,
+		Payload:   string(body),
+		Status:    models.WebhookDeliveryProcessing,
+	}
+	if err := database.DB.Create(delivery).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBCreateFailed, "创建投递记录失败")
+	}
+
+	attempt := send(hook, delivery)
+	updateWebhookStatus(hook.ID, attempt.Error == "")
+
+	updates := map[string]interface{}{"attempt": 1, "last_error": attempt.Error, "status": models.WebhookDeliveryDone}
+	if attempt.Error != "" {
+		updates["status"] = models.WebhookDeliveryFailed
+	} else {
+		updates["delivered_at"] = time.Now()
+	}
+	database.DB.Model(delivery).Updates(updates)
+	return attempt, nil
+}
+
+/* DeliveryQuery 投递记录查询条件；WebhookID 为 0 时查询调用方的全部订阅 */
+type DeliveryQuery struct {
+	WebhookID uint
+	Status    string
+	Event     string
+	Page      int
+	Size      int
+}
+
+func deliveriesOf(owner Owner, q DeliveryQuery) *gorm.DB {
+	hookIDs := owner.scoped(database.DB.Model(&models.Webhook{})).Select("id")
+	query := database.DB.Model(&models.WebhookDelivery{}).Where("webhook_id IN (?)", hookIDs)
+	if q.WebhookID > 0 {
+		query = query.Where("webhook_id = ?", q.WebhookID)
+	}
+	if q.Status != "" {
+		query = query.Where("status = ?", q.Status)
+	}
+	if q.Event != "" {
+		query = query.Where("event = ?", q.Event)
+	}
+	return query
+}
+
+/* ListDeliveries 分页获取投递记录，status=failed 即死信列表 */
+func ListDeliveries(owner Owner, q DeliveryQuery) ([]models.WebhookDelivery, int64, error) {
+	var total int64
+	if err := deliveriesOf(owner, q).Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询投递记录失败")
+	}
+	var deliveries []models.WebhookDelivery
+	if err := deliveriesOf(owner, q).Order("id DESC").Offset((q.Page - 1) * q.Size).Limit(q.Size).Find(&deliveries).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询投递记录失败")
+	}
+	return deliveries, total, nil
+}
+
+func getDelivery(owner Owner, deliveryID uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := deliveriesOf(owner, DeliveryQuery{}).Where("id = ?", deliveryID).Take(&delivery).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeNotFound, "投递记录不存在")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询投递记录失败")
+	}
+	return &delivery, nil
+}
+
+/* ListDeliveryAttempts 获取投递的每次尝试（状态码与耗时） */
+func ListDeliveryAttempts(owner Owner, deliveryID uint) ([]models.WebhookDeliveryAttempt, error) {
+	if _, err := getDelivery(owner, deliveryID); err != nil {
+		return nil, err
+	}
+	var attempts []models.WebhookDeliveryAttempt
+	if err := database.DB.Where("delivery_id = ?", deliveryID).Order("id ASC").Find(&attempts).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询投递尝试失败")
+	}
+	return attempts, nil
+}
+
+/* Redeliver 重新投递一条记录（通常用于死信），重试次数重新计算 */
+func Redeliver(owner Owner, deliveryID uint) error {
+	delivery, err := getDelivery(owner, deliveryID)
+	if err != nil {
+		return err
+	}
+	if delivery.Status == models.WebhookDeliveryProcessing || delivery.Status == models.WebhookDeliveryQueued {
+		return errors.New(errors.CodeInvalidParameter, "该投递正在进行中")
+	}
+	InitWebhookDelivery()
+	if err := deliveryQueue.EnqueueUnique(strconv.FormatUint(uint64(delivery.ID), 10), 0); err != nil {
+		return errors.Wrap(err, errors.CodeDBUpdateFailed, "重新投递失败")
+	}
+	wake()
+	return nil
+}
+
+/* CleanupDeliveries 清理过期的已完成投递记录，死信保留以便排查 */
+func CleanupDeliveries(retentionDays int) (int64, error) {
+	if retentionDays <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+	ids := database.DB.Model(&models.WebhookDelivery{}).Select("id").Where("status = ? AND created_at < ?", models.WebhookDeliveryDone, cutoff)
+	if err := database.DB.Where("delivery_id IN (?)", ids).Delete(&models.WebhookDeliveryAttempt{}).Error; err != nil {
+		return 0, err
+	}
+	result := database.DB.Where("status = ? AND created_at < ?", models.WebhookDeliveryDone, cutoff).Delete(&models.WebhookDelivery{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"ping"}`)
+	sig := Sign("whsec_test", 1700000000, body)
+	if sig != Sign("whsec_test", 1700000000, body) || len(sig) != len(signaturePrefix)+64 {
+		t.Fatalf("unexpected signature %q", sig)
+	}
+	if !Verify("whsec_test", 1700000000, body, sig) {
+		t.Error("signature should verify")
+	}
+	if Verify("whsec_other", 1700000000, body, sig) || Verify("whsec_test", 1700000001, body, sig) {
+		t.Error("signature must depend on secret and timestamp")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	cases := map[int]time.Duration{
+		0:  30 * time.Second,
+		1:  30 * time.Second,
+		2:  time.Minute,
+		5:  8 * time.Minute,
+		20: retryMaxDelay,
+	}
+	for attempt, want := range cases {
+		if got := RetryDelay(attempt); got != want {
+			t.Errorf("RetryDelay(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+func TestSubscribes(t *testing.T) {
+	cases := []struct {
+		events, event string
+		want          bool
+	}{
+		{"*", EventFileDeleted, true},
+		{"file.uploaded, file.deleted", EventFileDeleted, true},
+		{"file.*", EventFileApproved, true},
+		{"file.*", EventShareAccessed, false},
+		{"file.uploaded", EventFileTagged, false},
+	}
+	for _, c := range cases {
+		if got := subscribes(c.events, c.event); got != c.want {
+			t.Errorf("subscribes(%q, %q) = %v, want %v", c.events, c.event, got, c.want)
+		}
+	}
+}
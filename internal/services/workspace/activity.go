@@ -0,0 +1,70 @@
+package workspace
+
+import (
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// 工作区活动类型
+const (
+	ActivityFileUpload   = "file_upload"
+	ActivityFileUpdate   = "file_update"
+	ActivityFileDelete   = "file_delete"
+	ActivityFolderCreate = "folder_create"
+	ActivityFolderUpdate = "folder_update"
+	ActivityFolderDelete = "folder_delete"
+	ActivityShareCreate  = "share_create"
+	ActivityMemberAdd    = "member_add"
+	ActivityMemberUpdate = "member_update"
+	ActivityMemberRemove = "member_remove"
+)
+
+/* RecordActivity 异步记录工作区活动，workspaceID 为空时忽略（个人资源） */
+func RecordActivity(workspaceID string, userID uint, action, targetType, targetID, targetName string, size int64) {
+	if workspaceID == "" {
+		return
+	}
+	entry := models.WorkspaceActivity{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Action:      action,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		TargetName:  targetName,
+		Size:        size,
+	}
+	go func() {
+		db := database.GetDB()
+		if db == nil {
+			return
+		}
+		if err := db.Create(&entry).Error; err != nil {
+			logger.Warn("记录工作区活动失败: workspace=%s, action=%s, err=%v", workspaceID, action, err)
+		}
+	}()
+}
+
+/* ListActivities 分页获取工作区活动，memberID 非 0 时只看该成员的操作 */
+func ListActivities(userID uint, workspaceID string, memberID uint, page, size int) ([]models.WorkspaceActivity, int64, error) {
+	if _, err := requireRole(userID, workspaceID, ActionView); err != nil {
+		return nil, 0, err
+	}
+	query := database.DB.Model(&models.WorkspaceActivity{}).Where("workspace_id = ?", workspaceID)
+	if memberID > 0 {
+		query = query.Where("user_id = ?", memberID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询工作区活动失败")
+	}
+	var items []models.WorkspaceActivity
+	if err := query.Preload("User", func(db *gorm.DB) *gorm.DB { return db.Select("id, username, avatar") }).
+		Order("id DESC").Offset((page - 1) * size).Limit(size).Find(&items).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询工作区活动失败")
+	}
+	return items, total, nil
+}
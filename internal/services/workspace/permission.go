@@ -0,0 +1,136 @@
+package workspace
+
+/* Permission checks shared by folder, file, share and apikey services for workspace-owned resources. */
+
+import (
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+/* Action 工作区内的操作类型 */
+type Action string
+
+const (
+	ActionView   Action = "view"   // 浏览文件夹与文件
+	ActionUpload Action = "upload" // 上传文件、新建子文件夹
+	ActionEdit   Action = "edit"   // 重命名、移动、删除、修改访问级别
+	ActionShare  Action = "share"  // 创建分享、绑定API密钥
+	ActionManage Action = "manage" // 管理成员、配额与工作区信息
+)
+
+var rolePermissions = map[string]map[Action]bool{
+	models.WorkspaceRoleOwner:    {ActionView: true, ActionUpload: true, ActionEdit: true, ActionShare: true, ActionManage: true},
+	models.WorkspaceRoleEditor:   {ActionView: true, ActionUpload: true, ActionEdit: true, ActionShare: true},
+	models.WorkspaceRoleUploader: {ActionView: true, ActionUpload: true},
+	models.WorkspaceRoleViewer:   {ActionView: true},
+}
+
+/* RoleAllows 角色是否具备指定操作权限 */
+func RoleAllows(role string, action Action) bool {
+	return rolePermissions[role][action]
+}
+
+/* IsAssignableRole 可分配给成员的角色，所有者只能通过转让产生 */
+func IsAssignableRole(role string) bool {
+	return role == models.WorkspaceRoleEditor || role == models.WorkspaceRoleUploader || role == models.WorkspaceRoleViewer
+}
+
+// allowsOnResource 上传者可以编辑自己上传或创建的内容
+func allowsOnResource(role string, action Action, userID, resourceUserID uint) bool {
+	if RoleAllows(role, action) {
+		return true
+	}
+	return action == ActionEdit && role == models.WorkspaceRoleUploader && userID == resourceUserID
+}
+
+/* MemberRole 获取用户在工作区中的角色，非成员返回空字符串 */
+func MemberRole(workspaceID string, userID uint) string {
+	if workspaceID == "" || userID == 0 {
+		return ""
+	}
+	var member models.WorkspaceMember
+	if err := database.DB.Select("role").Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error; err != nil {
+		return ""
+	}
+	return member.Role
+}
+
+/* ResolveFolder 按权限获取文件夹：个人文件夹要求本人所有，工作区文件夹要求成员角色允许该操作 */
+func ResolveFolder(userID uint, folderID string, action Action) (*models.Folder, error) {
+	var folder models.Folder
+	if err := database.DB.Where("id = ?", folderID).First(&folder).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeFolderNotFound, "文件夹不存在")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件夹失败")
+	}
+	if err := checkResource(userID, folder.UserID, folder.WorkspaceID, action); err != nil {
+		if errors.Is(err, errors.CodeNotFound) {
+			return nil, errors.New(errors.CodeFolderNotFound, "文件夹不存在")
+		}
+		return nil, err
+	}
+	return &folder, nil
+}
+
+/* ResolveFile 按权限获取文件，规则与 ResolveFolder 一致 */
+func ResolveFile(userID uint, fileID string, action Action) (*models.File, error) {
+	var file models.File
+	if err := database.DB.Where("id = ?", fileID).First(&file).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeFileNotFound, "文件不存在")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	}
+	if err := checkResource(userID, file.UserID, file.WorkspaceID, action); err != nil {
+		if errors.Is(err, errors.CodeNotFound) {
+			return nil, errors.New(errors.CodeFileNotFound, "文件不存在")
+		}
+		return nil, err
+	}
+	return &file, nil
+}
+
+// checkResource 非成员与他人的个人资源统一按不存在处理，避免泄露资源ID
+func checkResource(userID, resourceUserID uint, workspaceID string, action Action) error {
+	if workspaceID == "" {
+		if resourceUserID != userID {
+			return errors.New(errors.CodeNotFound, "资源不存在")
+		}
+		return nil
+	}
+	role := MemberRole(workspaceID, userID)
+	if role == "" {
+		return errors.New(errors.CodeNotFound, "资源不存在")
+	}
+	if !allowsOnResource(role, action, userID, resourceUserID) {
+		return errors.New(errors.CodeForbidden, "当前工作区角色无权执行该操作")
+	}
+	return nil
+}
+
+/* IsWorkspaceRoot 是否为工作区根文件夹，根文件夹随工作区创建和删除 */
+func IsWorkspaceRoot(folder *models.Folder) bool {
+	return folder.WorkspaceID != "" && folder.ParentID == ""
+}
+
+/* PersonalScope 个人资源查询范围：本人所有且不属于任何工作区 */
+func PersonalScope(userID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ? AND (workspace_id = '' OR workspace_id IS NULL)", userID)
+	}
+}
+
+/* FolderScope 与文件夹归属一致的查询范围：工作区文件夹按 workspace_id，个人文件夹按 PersonalScope */
+func FolderScope(folder *models.Folder) func(*gorm.DB) *gorm.DB {
+	if folder.WorkspaceID == "" {
+		return PersonalScope(folder.UserID)
+	}
+	workspaceID := folder.WorkspaceID
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("workspace_id = ?", workspaceID)
+	}
+}
@@ -0,0 +1,61 @@
+package workspace
+
+import (
+	"testing"
+
+	"pixelpunk/internal/models"
+)
+
+func TestRoleAllows(t *testing.T) {
+	actions := []Action{ActionView, ActionUpload, ActionEdit, ActionShare, ActionManage}
+	want := map[string][]bool{
+		models.WorkspaceRoleOwner:    {true, true, true, true, true},
+		models.WorkspaceRoleEditor:   {true, true, true, true, false},
+		models.WorkspaceRoleUploader: {true, true, false, false, false},
+		models.WorkspaceRoleViewer:   {true, false, false, false, false},
+		"":                           {false, false, false, false, false},
+		"admin":                      {false, false, false, false, false},
+	}
+	for role, expected := range want {
+		for i, action := range actions {
+			if got := RoleAllows(role, action); got != expected[i] {
+				t.Errorf("RoleAllows(%q, %q) = %v, want %v", role, action, got, expected[i])
+			}
+		}
+	}
+}
+
+func TestUploaderEditsOwnResources(t *testing.T) {
+	cases := []struct {
+		role   string
+		action Action
+		owner  uint
+		want   bool
+	}{
+		{models.WorkspaceRoleUploader, ActionEdit, 7, true},
+		{models.WorkspaceRoleUploader, ActionEdit, 8, false},
+		// 上传者不能分享，即使是自己上传的文件
+		{models.WorkspaceRoleUploader, ActionShare, 7, false},
+		{models.WorkspaceRoleViewer, ActionEdit, 7, false},
+		{models.WorkspaceRoleEditor, ActionEdit, 8, true},
+	}
+	for _, c := range cases {
+		if got := allowsOnResource(c.role, c.action, 7, c.owner); got != c.want {
+			t.Errorf("allowsOnResource(%q, %q, owner=%d) = %v, want %v", c.role, c.action, c.owner, got, c.want)
+		}
+	}
+}
+
+func TestIsAssignableRole(t *testing.T) {
+	for role, want := range map[string]bool{
+		models.WorkspaceRoleEditor:   true,
+		models.WorkspaceRoleUploader: true,
+		models.WorkspaceRoleViewer:   true,
+		models.WorkspaceRoleOwner:    false,
+		"":                           false,
+	} {
+		if got := IsAssignableRole(role); got != want {
+			t.Errorf("IsAssignableRole(%q) = %v, want %v", role, got, want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package workspace
+
+import (
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/stats"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+)
+
+const statusPendingDeletion = "pending_deletion"
+
+/* MemberUsage 成员在工作区内的上传统计 */
+type MemberUsage struct {
+	UserID    uint  `json:"user_id"`
+	FileCount int64 `json:"file_count"`
+	TotalSize int64 `json:"total_size"`
+}
+
+/* UsedStorage 工作区已用存储，按工作区内未删除文件实时统计 */
+func UsedStorage(workspaceID string) (int64, error) {
+	var used int64
+	if err := database.DB.Model(&models.File{}).
+		Where("workspace_id = ? AND status <> ?", workspaceID, statusPendingDeletion).
+		Select("COALESCE(SUM(size), 0)").Scan(&used).Error; err != nil {
+		return 0, errors.Wrap(err, errors.CodeDBQueryFailed, "统计工作区存储失败")
+	}
+	return used, nil
+}
+
+/* OwnedStorage 用户拥有的全部工作区已用存储之和 */
+func OwnedStorage(userID uint) (int64, error) {
+	var used int64
+	if err := database.DB.Model(&models.File{}).
+		Joins("JOIN workspace ON workspace.id = file.workspace_id").
+		Where("workspace.owner_id = ? AND file.status <> ?", userID, statusPendingDeletion).
+		Select("COALESCE(SUM(file.size), 0)").Scan(&used).Error; err != nil {
+		return 0, errors.Wrap(err, errors.CodeDBQueryFailed, "统计工作区存储失败")
+	}
+	return used, nil
+}
+
+/* CheckStorageAvailable 检查工作区共享配额是否足够上传指定大小，配额为 0 表示不限制；
+ * 工作区用量同时计入所有者的个人配额，避免通过创建工作区绕过个人配额
+ */
+func CheckStorageAvailable(workspaceID string, size int64) (bool, error) {
+	var ws models.Workspace
+	if err := database.DB.Select("id, owner_id, storage_limit").Where("id = ?", workspaceID).First(&ws).Error; err != nil {
+		return false, errors.New(errors.CodeNotFound, "工作区不存在")
+	}
+	if ws.StorageLimit > 0 {
+		used, err := UsedStorage(workspaceID)
+		if err != nil {
+			return false, err
+		}
+		if used+size > ws.StorageLimit {
+			return false, nil
+		}
+	}
+	return CheckPersonalStorageAvailable(ws.OwnerID, size)
+}
+
+/* CheckPersonalStorageAvailable 检查用户个人配额，用量包含个人文件与其拥有的工作区文件 */
+func CheckPersonalStorageAvailable(userID uint, size int64) (bool, error) {
+	owned, err := OwnedStorage(userID)
+	if err != nil {
+		return false, err
+	}
+	return stats.CheckUserStorageAvailable(userID, owned+size)
+}
+
+/* MemberUsages 按成员统计工作区内的文件数与占用 */
+func MemberUsages(workspaceID string) ([]MemberUsage, error) {
+	var usages []MemberUsage
+	if err := database.DB.Model(&models.File{}).
+		Select("user_id, COUNT(*) AS file_count, COALESCE(SUM(size), 0) AS total_size").
+		Where("workspace_id = ? AND status <> ?", workspaceID, statusPendingDeletion).
+		Group("user_id").Scan(&usages).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "统计成员用量失败")
+	}
+	return usages, nil
+}
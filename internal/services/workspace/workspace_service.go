@@ -0,0 +1,427 @@
+package workspace
+
+/* Team workspaces: a shared folder tree with its own storage quota and role-based member access. */
+
+import (
+	"strconv"
+	"strings"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/storage"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+/* WorkspaceResponse 工作区信息，附带当前用户角色与用量 */
+type WorkspaceResponse struct {
+	models.Workspace
+	Role        string `json:"role,omitempty"`
+	MemberCount int64  `json:"member_count"`
+	UsedStorage int64  `json:"used_storage"`
+}
+
+/* MemberResponse 工作区成员及其上传用量 */
+type MemberResponse struct {
+	models.WorkspaceMember
+	FileCount int64 `json:"file_count"`
+	TotalSize int64 `json:"total_size"`
+}
+
+/* CreateWorkspace 创建工作区及其根文件夹，创建者成为所有者 */
+func CreateWorkspace(userID uint, name, description string) (*WorkspaceResponse, error) {
+	name = strings.TrimSpace(name)
+	if !storage.IsValidFolderName(name) {
+		return nil, errors.New(errors.CodeInvalidParameter, "工作区名称无效：不能为空或包含 / \\ : * ? \" < > | 等特殊字符")
+	}
+
+	if err := checkOwnedLimit(userID); err != nil {
+		return nil, err
+	}
+
+	ws := models.Workspace{
+		ID:           strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Name:         name,
+		Description:  description,
+		OwnerID:      userID,
+		StorageLimit: models.DefaultWorkspaceStorageLimit,
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		root := models.Folder{
+			ID:          storage.GenerateFolderID(),
+			UserID:      userID,
+			Name:        name,
+			Description: description,
+			WorkspaceID: ws.ID,
+		}
+		if err := tx.Create(&root).Error; err != nil {
+			return errors.Wrap(err, errors.CodeFolderCreateFailed, "创建工作区根文件夹失败")
+		}
+		ws.RootFolderID = root.ID
+		if err := tx.Create(&ws).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBCreateFailed, "创建工作区失败")
+		}
+		member := models.WorkspaceMember{WorkspaceID: ws.ID, UserID: userID, Role: models.WorkspaceRoleOwner, InvitedBy: userID}
+		if err := tx.Create(&member).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBCreateFailed, "添加工作区所有者失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &WorkspaceResponse{Workspace: ws, Role: models.WorkspaceRoleOwner, MemberCount: 1}, nil
+}
+
+/* ListWorkspaces 获取用户加入的全部工作区 */
+func ListWorkspaces(userID uint) ([]WorkspaceResponse, error) {
+	var members []models.WorkspaceMember
+	if err := database.DB.Where("user_id = ?", userID).Find(&members).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询工作区失败")
+	}
+	roles := make(map[string]string, len(members))
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		roles[m.WorkspaceID] = m.Role
+		ids = append(ids, m.WorkspaceID)
+	}
+
+	items := make([]WorkspaceResponse, 0, len(ids))
+	if len(ids) == 0 {
+		return items, nil
+	}
+	var workspaces []models.Workspace
+	if err := database.DB.Where("id IN ?", ids).Order("created_at ASC").Find(&workspaces).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询工作区失败")
+	}
+	for _, ws := range workspaces {
+		items = append(items, buildResponse(ws, roles[ws.ID]))
+	}
+	return items, nil
+}
+
+/* GetWorkspace 获取工作区详情 */
+func GetWorkspace(userID uint, workspaceID string) (*WorkspaceResponse, error) {
+	role, err := requireRole(userID, workspaceID, ActionView)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := getWorkspace(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	resp := buildResponse(*ws, role)
+	return &resp, nil
+}
+
+/* UpdateWorkspace 修改工作区名称与描述，名称同步到根文件夹 */
+func UpdateWorkspace(userID uint, workspaceID string, name, description *string) (*WorkspaceResponse, error) {
+	role, err := requireRole(userID, workspaceID, ActionManage)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := getWorkspace(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if name != nil {
+		trimmed := strings.TrimSpace(*name)
+		if !storage.IsValidFolderName(trimmed) {
+			return nil, errors.New(errors.CodeInvalidParameter, "工作区名称无效：不能为空或包含 / \\ : * ? \" < > | 等特殊字符")
+		}
+		ws.Name = trimmed
+	}
+	if description != nil {
+		ws.Description = *description
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(ws).Updates(map[string]interface{}{"name": ws.Name, "description": ws.Description}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBUpdateFailed, "更新工作区失败")
+		}
+		if err := tx.Model(&models.Folder{}).Where("id = ?", ws.RootFolderID).Update("name", ws.Name).Error; err != nil {
+			return errors.Wrap(err, errors.CodeFolderUpdateFailed, "更新工作区根文件夹失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := buildResponse(*ws, role)
+	return &resp, nil
+}
+
+/* DeleteWorkspace 删除工作区：需先清空其中的文件，绑定的API密钥会被禁用，分享会失效 */
+func DeleteWorkspace(userID uint, workspaceID string) error {
+	if _, err := requireRole(userID, workspaceID, ActionManage); err != nil {
+		return err
+	}
+	var fileCount int64
+	if err := database.DB.Model(&models.File{}).Where("workspace_id = ?", workspaceID).Count(&fileCount).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询工作区文件失败")
+	}
+	if fileCount > 0 {
+		return errors.New(errors.CodeConflict, "请先删除工作区内的全部文件")
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("workspace_id = ?", workspaceID).Delete(&models.Folder{}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeFolderDeleteFailed, "删除工作区文件夹失败")
+		}
+		if err := tx.Model(&models.APIKey{}).Where("workspace_id = ?", workspaceID).
+			Updates(map[string]interface{}{"status": models.APIKeyStatusDisabled, "folder_id": "", "workspace_id": ""}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBUpdateFailed, "禁用工作区API密钥失败")
+		}
+		if err := tx.Model(&models.Share{}).Where("workspace_id = ?", workspaceID).
+			Update("status", common.ShareStatusDeleted).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBUpdateFailed, "关闭工作区分享失败")
+		}
+		if err := tx.Where("workspace_id = ?", workspaceID).Delete(&models.WorkspaceActivity{}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除工作区活动失败")
+		}
+		if err := tx.Where("workspace_id = ?", workspaceID).Delete(&models.WorkspaceMember{}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除工作区成员失败")
+		}
+		if err := tx.Where("id = ?", workspaceID).Delete(&models.Workspace{}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除工作区失败")
+		}
+		return nil
+	})
+}
+
+/* ListMembers 获取工作区成员及各自的上传用量 */
+func ListMembers(userID uint, workspaceID string) ([]MemberResponse, error) {
+	if _, err := requireRole(userID, workspaceID, ActionView); err != nil {
+		return nil, err
+	}
+	var members []models.WorkspaceMember
+	if err := database.DB.Preload("User", func(db *gorm.DB) *gorm.DB { return db.Select("id, username, email, avatar") }).
+		Where("workspace_id = ?", workspaceID).Order("id ASC").Find(&members).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询工作区成员失败")
+	}
+	usages, err := MemberUsages(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	usageByUser := make(map[uint]MemberUsage, len(usages))
+	for _, u := range usages {
+		usageByUser[u.UserID] = u
+	}
+
+	items := make([]MemberResponse, 0, len(members))
+	for _, m := range members {
+		usage := usageByUser[m.UserID]
+		items = append(items, MemberResponse{WorkspaceMember: m, FileCount: usage.FileCount, TotalSize: usage.TotalSize})
+	}
+	return items, nil
+}
+
+/* AddMember 按用户名或邮箱邀请成员加入工作区 */
+func AddMember(userID uint, workspaceID, account, role string) (*models.WorkspaceMember, error) {
+	if _, err := requireRole(userID, workspaceID, ActionManage); err != nil {
+		return nil, err
+	}
+	if !IsAssignableRole(role) {
+		return nil, errors.New(errors.CodeInvalidParameter, "无效的成员角色")
+	}
+
+	var user models.User
+	account = strings.TrimSpace(account)
+	if err := database.DB.Where("username = ? OR email = ?", account, account).First(&user).Error; err != nil {
+		return nil, errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+	if !user.IsNormal() {
+		return nil, errors.New(errors.CodeUserDisabled, "该用户已被禁用")
+	}
+	if MemberRole(workspaceID, user.ID) != "" {
+		return nil, errors.New(errors.CodeConflict, "该用户已是工作区成员")
+	}
+
+	member := models.WorkspaceMember{WorkspaceID: workspaceID, UserID: user.ID, Role: role, InvitedBy: userID}
+	if err := database.DB.Create(&member).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBCreateFailed, "添加工作区成员失败")
+	}
+	RecordActivity(workspaceID, userID, ActivityMemberAdd, "member", strconv.FormatUint(uint64(user.ID), 10), user.Username, 0)
+	return &member, nil
+}
+
+/* UpdateMemberRole 调整成员角色，所有者角色只能通过转让变更 */
+func UpdateMemberRole(userID uint, workspaceID string, memberUserID uint, role string) error {
+	if _, err := requireRole(userID, workspaceID, ActionManage); err != nil {
+		return err
+	}
+	if !IsAssignableRole(role) {
+		return errors.New(errors.CodeInvalidParameter, "无效的成员角色")
+	}
+	member, err := getMember(workspaceID, memberUserID)
+	if err != nil {
+		return err
+	}
+	if member.Role == models.WorkspaceRoleOwner {
+		return errors.New(errors.CodeForbidden, "不能修改所有者的角色，请使用转让功能")
+	}
+	if err := database.DB.Model(member).Update("role", role).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBUpdateFailed, "更新成员角色失败")
+	}
+	RecordActivity(workspaceID, userID, ActivityMemberUpdate, "member", strconv.FormatUint(uint64(memberUserID), 10), memberName(member), 0)
+	return nil
+}
+
+/* RemoveMember 移除成员或主动退出工作区；该成员绑定到工作区的API密钥会被禁用 */
+func RemoveMember(userID uint, workspaceID string, memberUserID uint) error {
+	if memberUserID != userID {
+		if _, err := requireRole(userID, workspaceID, ActionManage); err != nil {
+			return err
+		}
+	}
+	member, err := getMember(workspaceID, memberUserID)
+	if err != nil {
+		return err
+	}
+	if member.Role == models.WorkspaceRoleOwner {
+		return errors.New(errors.CodeForbidden, "所有者不能退出工作区，请先转让或删除工作区")
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(member).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBDeleteFailed, "移除工作区成员失败")
+		}
+		if err := tx.Model(&models.APIKey{}).Where("workspace_id = ? AND user_id = ?", workspaceID, memberUserID).
+			Update("status", models.APIKeyStatusDisabled).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBUpdateFailed, "禁用成员API密钥失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	RecordActivity(workspaceID, userID, ActivityMemberRemove, "member", strconv.FormatUint(uint64(memberUserID), 10), memberName(member), 0)
+	return nil
+}
+
+/* TransferOwnership 将工作区转让给其他成员，原所有者降为编辑者 */
+func TransferOwnership(userID uint, workspaceID string, newOwnerID uint) error {
+	role, err := requireRole(userID, workspaceID, ActionManage)
+	if err != nil {
+		return err
+	}
+	if role != models.WorkspaceRoleOwner {
+		return errors.New(errors.CodeForbidden, "只有所有者可以转让工作区")
+	}
+	target, err := getMember(workspaceID, newOwnerID)
+	if err != nil {
+		return err
+	}
+	if err := checkOwnedLimit(newOwnerID); err != nil {
+		return err
+	}
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.WorkspaceMember{}).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+			Update("role", models.WorkspaceRoleEditor).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBUpdateFailed, "转让工作区失败")
+		}
+		if err := tx.Model(target).Update("role", models.WorkspaceRoleOwner).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBUpdateFailed, "转让工作区失败")
+		}
+		if err := tx.Model(&models.Workspace{}).Where("id = ?", workspaceID).Update("owner_id", newOwnerID).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBUpdateFailed, "转让工作区失败")
+		}
+		return nil
+	})
+}
+
+// checkOwnedLimit 用户拥有的工作区数量不能超过上限
+func checkOwnedLimit(userID uint) error {
+	var owned int64
+	if err := database.DB.Model(&models.Workspace{}).Where("owner_id = ?", userID).Count(&owned).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询工作区失败")
+	}
+	if owned >= models.MaxOwnedWorkspaces {
+		return errors.New(errors.CodeForbidden, "拥有的工作区数量已达上限")
+	}
+	return nil
+}
+
+/* AdminListWorkspaces 管理员分页查看全部工作区 */
+func AdminListWorkspaces(keyword string, page, size int) ([]WorkspaceResponse, int64, error) {
+	query := database.DB.Model(&models.Workspace{})
+	if keyword != "" {
+		query = query.Where("name LIKE ?", "%"+keyword+"%")
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询工作区失败")
+	}
+	var workspaces []models.Workspace
+	if err := query.Order("created_at DESC").Offset((page - 1) * size).Limit(size).Find(&workspaces).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询工作区失败")
+	}
+	items := make([]WorkspaceResponse, 0, len(workspaces))
+	for _, ws := range workspaces {
+		items = append(items, buildResponse(ws, ""))
+	}
+	return items, total, nil
+}
+
+/* AdminSetStorageLimit 管理员调整工作区共享配额，0 表示不限制 */
+func AdminSetStorageLimit(workspaceID string, limit int64) error {
+	if limit < 0 {
+		return errors.New(errors.CodeInvalidParameter, "存储配额不能为负数")
+	}
+	result := database.DB.Model(&models.Workspace{}).Where("id = ?", workspaceID).Update("storage_limit", limit)
+	if result.Error != nil {
+		return errors.Wrap(result.Error, errors.CodeDBUpdateFailed, "更新工作区配额失败")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(errors.CodeNotFound, "工作区不存在")
+	}
+	return nil
+}
+
+// requireRole 校验用户在工作区中的角色是否允许操作，非成员按工作区不存在处理
+func requireRole(userID uint, workspaceID string, action Action) (string, error) {
+	role := MemberRole(workspaceID, userID)
+	if role == "" {
+		return "", errors.New(errors.CodeNotFound, "工作区不存在")
+	}
+	if !RoleAllows(role, action) {
+		return "", errors.New(errors.CodeForbidden, "当前工作区角色无权执行该操作")
+	}
+	return role, nil
+}
+
+func getWorkspace(workspaceID string) (*models.Workspace, error) {
+	var ws models.Workspace
+	if err := database.DB.Where("id = ?", workspaceID).First(&ws).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeNotFound, "工作区不存在")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询工作区失败")
+	}
+	return &ws, nil
+}
+
+func getMember(workspaceID string, userID uint) (*models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+	if err := database.DB.Preload("User", func(db *gorm.DB) *gorm.DB { return db.Select("id, username") }).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error; err != nil {
+		return nil, errors.New(errors.CodeNotFound, "成员不存在")
+	}
+	return &member, nil
+}
+
+func memberName(member *models.WorkspaceMember) string {
+	if member.User != nil {
+		return member.User.Username
+	}
+	return ""
+}
+
+func buildResponse(ws models.Workspace, role string) WorkspaceResponse {
+	resp := WorkspaceResponse{Workspace: ws, Role: role}
+	database.DB.Model(&models.WorkspaceMember{}).Where("workspace_id = ?", ws.ID).Count(&resp.MemberCount)
+	resp.UsedStorage, _ = UsedStorage(ws.ID)
+	return resp
+}
@@ -10,14 +10,15 @@ type MessageType string
 
 const (
 	// 消息类型常量
-	MessageTypeQueueStats   MessageType = "queue_stats"
-	MessageTypeVectorStats  MessageType = "vector_stats"
-	MessageTypeLogs         MessageType = "logs"
-	MessageTypeAnnouncement MessageType = "announcement"
-	MessageTypeSystemStatus MessageType = "system_status"
-	MessageTypeError        MessageType = "error"
-	MessageTypePing         MessageType = "ping"
-	MessageTypePong         MessageType = "pong"
+	MessageTypeQueueStats       MessageType = "queue_stats"
+	MessageTypeVectorStats      MessageType = "vector_stats"
+	MessageTypeLogs             MessageType = "logs"
+	MessageTypeAnnouncement     MessageType = "announcement"
+	MessageTypeStorageMigration MessageType = "storage_migration"
+	MessageTypeSystemStatus     MessageType = "system_status"
+	MessageTypeError            MessageType = "error"
+	MessageTypePing             MessageType = "ping"
+	MessageTypePong             MessageType = "pong"
 )
 
 // MessagePriority 消息优先级
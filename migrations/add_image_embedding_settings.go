@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"pixelpunk/internal/controllers/setting/dto"
+
+	"gorm.io/gorm"
+)
+
+// AddImageEmbeddingSettings 添加图像向量化（多模态向量）设置
+func AddImageEmbeddingSettings(db *gorm.DB) error {
+	return upsertFeatureSettings("图像向量化", []dto.SettingCreateDTO{
+		{
+			Key:         "image_embedding_enabled",
+			Value:       DefaultSettings.Vector.ImageEmbeddingEnabled,
+			Type:        "boolean",
+			Group:       "vector",
+			Description: "启用图像向量化，直接对图片像素生成向量（需要Qdrant集合为命名向量结构）",
+			IsSystem:    true,
+		},
+		{
+			Key:         "image_embedding_api_format",
+			Value:       DefaultSettings.Vector.ImageEmbeddingAPIFormat,
+			Type:        "string",
+			Group:       "vector",
+			Description: "多模态向量接口格式：openai 为 OpenAI 兼容的 /embeddings 接口，local 为本地自建接口",
+			IsSystem:    true,
+		},
+		{
+			Key:         "image_embedding_base_url",
+			Value:       DefaultSettings.Vector.ImageEmbeddingBaseURL,
+			Type:        "string",
+			Group:       "vector",
+			Description: "多模态向量接口地址",
+			IsSystem:    true,
+		},
+		{
+			Key:         "image_embedding_api_key",
+			Value:       DefaultSettings.Vector.ImageEmbeddingAPIKey,
+			Type:        "string",
+			Group:       "vector",
+			Description: "多模态向量接口密钥",
+			IsSystem:    true,
+		},
+		{
+			Key:         "image_embedding_model",
+			Value:       DefaultSettings.Vector.ImageEmbeddingModel,
+			Type:        "string",
+			Group:       "vector",
+			Description: "多模态向量模型",
+			IsSystem:    true,
+		},
+		{
+			Key:         "image_embedding_dimension",
+			Value:       DefaultSettings.Vector.ImageEmbeddingDimension,
+			Type:        "number",
+			Group:       "vector",
+			Description: "图像向量维度，修改后需重建集合并重新生成向量",
+			IsSystem:    true,
+		},
+		{
+			Key:         "image_embedding_threshold",
+			Value:       DefaultSettings.Vector.ImageEmbeddingThreshold,
+			Type:        "number",
+			Group:       "vector",
+			Description: "图像向量搜索阈值(0-1)，跨模态相似度通常低于文本相似度",
+			IsSystem:    true,
+		},
+		{
+			Key:         "vector_query_target",
+			Value:       DefaultSettings.Vector.VectorQueryTarget,
+			Type:        "string",
+			Group:       "vector",
+			Description: "语义搜索默认使用的向量：text 描述文本，image 图像，both 两者融合",
+			IsSystem:    true,
+		},
+	})
+}
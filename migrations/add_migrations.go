@@ -15,6 +15,19 @@ type migrationTask struct {
 // 注册的迁移列表
 var registeredMigrations = []migrationTask{
 	{"add_system_settings", AddSystemSettings},
+	{"add_signed_link_settings", AddSignedLinkSettings},
+	{"add_trash_settings", AddTrashSettings},
+	{"add_s3_gateway_settings", AddS3GatewaySettings},
+	{"add_webdav_settings", AddWebDAVSettings},
+	{"add_rendition_settings", AddRenditionSettings},
+	{"add_webhook_settings", AddWebhookSettings},
+	{"add_image_embedding_settings", AddImageEmbeddingSettings},
+	{"add_vector_store_settings", AddVectorStoreSettings},
+	{"add_two_factor_settings", AddTwoFactorSettings},
+	{"add_oidc_settings", AddOIDCSettings},
+	{"migrate_oauth_identities", MigrateOAuthIdentities},
+	{"add_session_settings", AddSessionSettings},
+	{"migrate_admin_roles", MigrateAdminRoles},
 }
 
 // RegisterAllMigrations 注册所有迁移函数
@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"pixelpunk/internal/controllers/setting/dto"
+
+	"gorm.io/gorm"
+)
+
+// AddOIDCSettings 添加通用 OIDC 提供方列表设置
+func AddOIDCSettings(db *gorm.DB) error {
+	return upsertFeatureSettings("OIDC 登录", []dto.SettingCreateDTO{
+		{
+			Key:         "oidc_providers",
+			Value:       []interface{}{},
+			Type:        "array",
+			Group:       "oauth",
+			Description: "通用 OpenID Connect / OAuth2 提供方列表（slug、issuer、client_id、声明与组角色映射等）",
+			IsSystem:    true,
+		},
+	})
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"pixelpunk/internal/controllers/setting/dto"
+
+	"gorm.io/gorm"
+)
+
+// AddRenditionSettings 添加响应式衍生尺寸设置
+func AddRenditionSettings(db *gorm.DB) error {
+	return upsertFeatureSettings("响应式衍生尺寸", []dto.SettingCreateDTO{
+		{
+			Key:         "rendition_presets",
+			Value:       DefaultSettings.Upload.RenditionPresets,
+			Type:        "string",
+			Group:       "upload",
+			Description: "衍生尺寸预设，多个以逗号分隔，格式为 名称=宽度w [jpeg|png|webp|avif|auto] [q质量]，如 small=320w webp, medium=1024w avif q60；留空不生成",
+			IsSystem:    true,
+		},
+		{
+			Key:         "rendition_mode",
+			Value:       DefaultSettings.Upload.RenditionMode,
+			Type:        "string",
+			Group:       "upload",
+			Description: "衍生尺寸生成时机：upload 上传后立即生成，lazy 首次访问时生成",
+			IsSystem:    true,
+		},
+	})
+}
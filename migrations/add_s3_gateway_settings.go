@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"pixelpunk/internal/controllers/setting/dto"
+
+	"gorm.io/gorm"
+)
+
+// AddS3GatewaySettings 添加 S3 兼容接口开关
+func AddS3GatewaySettings(db *gorm.DB) error {
+	return upsertFeatureSettings("S3兼容接口", []dto.SettingCreateDTO{
+		{
+			Key:         "s3_gateway_enabled",
+			Value:       DefaultSettings.Upload.S3GatewayEnabled,
+			Type:        "boolean",
+			Group:       "upload",
+			Description: "启用 S3 兼容接口(/s3)，使用API密钥ID与密钥值作为访问凭证",
+			IsSystem:    true,
+		},
+	})
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"pixelpunk/internal/controllers/setting/dto"
+
+	"gorm.io/gorm"
+)
+
+// AddSessionSettings 添加登录会话相关的安全设置
+func AddSessionSettings(db *gorm.DB) error {
+	return upsertFeatureSettings("登录会话", []dto.SettingCreateDTO{
+		{
+			Key:         "access_token_expire_minutes",
+			Value:       DefaultSettings.Security.AccessTokenExpireMinutes,
+			Type:        "number",
+			Group:       "security",
+			Description: "访问令牌有效期(分钟)，过期后使用刷新令牌续期；登录有效期(小时)决定刷新令牌的有效期",
+			IsSystem:    true,
+		},
+	})
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"pixelpunk/internal/controllers/setting/dto"
+
+	"gorm.io/gorm"
+)
+
+// AddSignedLinkSettings 添加签名链接相关的安全设置
+func AddSignedLinkSettings(db *gorm.DB) error {
+	return upsertFeatureSettings("签名链接", []dto.SettingCreateDTO{
+		{
+			Key:         "signed_link_max_expire_hours",
+			Value:       DefaultSettings.Security.SignedLinkMaxExpireHours,
+			Type:        "number",
+			Group:       "security",
+			Description: "签名链接最长有效期(小时)",
+			IsSystem:    true,
+		},
+		{
+			Key:         "signed_link_native_enabled",
+			Value:       DefaultSettings.Security.SignedLinkNativeEnabled,
+			Type:        "boolean",
+			Group:       "security",
+			Description: "允许签名链接委托存储渠道原生预签名",
+			IsSystem:    true,
+		},
+	})
+}
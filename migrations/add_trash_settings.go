@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"pixelpunk/internal/controllers/setting/dto"
+
+	"gorm.io/gorm"
+)
+
+// AddTrashSettings 添加回收站相关的上传设置
+func AddTrashSettings(db *gorm.DB) error {
+	return upsertFeatureSettings("回收站", []dto.SettingCreateDTO{
+		{
+			Key:         "trash_retention_days",
+			Value:       DefaultSettings.Upload.TrashRetentionDays,
+			Type:        "number",
+			Group:       "upload",
+			Description: "回收站保留天数(0表示删除后立即清除)",
+			IsSystem:    true,
+		},
+	})
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"pixelpunk/internal/controllers/setting/dto"
+
+	"gorm.io/gorm"
+)
+
+// AddTwoFactorSettings 添加两步验证相关的安全设置
+func AddTwoFactorSettings(db *gorm.DB) error {
+	return upsertFeatureSettings("两步验证", []dto.SettingCreateDTO{
+		{
+			Key:         "two_factor_required_roles",
+			Value:       DefaultSettings.Security.TwoFactorRequiredRoles,
+			Type:        "array",
+			Group:       "security",
+			Description: "强制启用两步验证的角色(1超级管理员 2管理员 3普通用户)",
+			IsSystem:    true,
+		},
+		{
+			Key:         "two_factor_issuer",
+			Value:       DefaultSettings.Security.TwoFactorIssuer,
+			Type:        "string",
+			Group:       "security",
+			Description: "身份验证器中显示的发行方名称",
+			IsSystem:    true,
+		},
+	})
+}
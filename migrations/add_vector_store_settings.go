@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"pixelpunk/internal/controllers/setting/dto"
+
+	"gorm.io/gorm"
+)
+
+// AddVectorStoreSettings 添加向量存储选择设置
+func AddVectorStoreSettings(db *gorm.DB) error {
+	return upsertFeatureSettings("向量存储", []dto.SettingCreateDTO{
+		{
+			Key:         "vector_store",
+			Value:       DefaultSettings.Vector.VectorStore,
+			Type:        "string",
+			Group:       "vector",
+			Description: "向量存储：auto 为 Qdrant 可连接时使用 Qdrant，否则使用内置存储；qdrant 为仅使用 Qdrant；embedded 为使用主数据库内置存储（修改后需重启生效）",
+			IsSystem:    true,
+		},
+	})
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"pixelpunk/internal/controllers/setting/dto"
+
+	"gorm.io/gorm"
+)
+
+// AddWebDAVSettings 添加 WebDAV 接口开关
+func AddWebDAVSettings(db *gorm.DB) error {
+	return upsertFeatureSettings("WebDAV接口", []dto.SettingCreateDTO{
+		{
+			Key:         "webdav_enabled",
+			Value:       DefaultSettings.Upload.WebDAVEnabled,
+			Type:        "boolean",
+			Group:       "upload",
+			Description: "启用 WebDAV 接口(/dav)，可使用账号密码或API密钥挂载个人文件库",
+			IsSystem:    true,
+		},
+	})
+}
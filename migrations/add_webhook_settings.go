@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"pixelpunk/internal/controllers/setting/dto"
+
+	"gorm.io/gorm"
+)
+
+// AddWebhookSettings 添加出站回调相关的安全设置
+func AddWebhookSettings(db *gorm.DB) error {
+	return upsertFeatureSettings("出站回调", []dto.SettingCreateDTO{
+		{
+			Key:         "webhook_enabled",
+			Value:       DefaultSettings.Security.WebhookEnabled,
+			Type:        "boolean",
+			Group:       "security",
+			Description: "启用出站回调(Webhook)，文件上传、打标、审核、删除及分享访问时通知订阅地址",
+			IsSystem:    true,
+		},
+		{
+			Key:         "webhook_max_attempts",
+			Value:       DefaultSettings.Security.WebhookMaxAttempts,
+			Type:        "number",
+			Group:       "security",
+			Description: "回调投递最大尝试次数，超过后进入死信",
+			IsSystem:    true,
+		},
+		{
+			Key:         "webhook_timeout_seconds",
+			Value:       DefaultSettings.Security.WebhookTimeoutSeconds,
+			Type:        "number",
+			Group:       "security",
+			Description: "回调请求超时时间(秒)",
+			IsSystem:    true,
+		},
+		{
+			Key:         "webhook_allow_private_network",
+			Value:       DefaultSettings.Security.WebhookAllowPrivateNetwork,
+			Type:        "boolean",
+			Group:       "security",
+			Description: "允许回调投递到内网及本机地址",
+			IsSystem:    true,
+		},
+		{
+			Key:         "webhook_log_retention_days",
+			Value:       DefaultSettings.Security.WebhookLogRetentionDays,
+			Type:        "number",
+			Group:       "security",
+			Description: "成功投递记录的保留天数，死信不会自动清理",
+			IsSystem:    true,
+		},
+	})
+}
@@ -94,6 +94,11 @@ var DefaultSettings = struct {
 		AIAnalysisEnabled:           true,
 		UserAllowedStorageDurations: []string{"1h", "3d", "7d", "30d", "permanent"},
 		UserDefaultStorageDuration:  "permanent",
+		TrashRetentionDays:          30,
+		S3GatewayEnabled:            false,
+		WebDAVEnabled:               false,
+		RenditionPresets:            "",
+		RenditionMode:               "lazy",
 	},
 
 	Theme: ThemeSettings{
@@ -118,6 +123,20 @@ var DefaultSettings = struct {
 		IPBlacklist:           "",
 		DomainWhitelist:       "",
 		DomainBlacklist:       "",
+
+		SignedLinkMaxExpireHours: 24 * 7,
+		SignedLinkNativeEnabled:  true,
+
+		WebhookEnabled:             true,
+		WebhookMaxAttempts:         8,
+		WebhookTimeoutSeconds:      10,
+		WebhookAllowPrivateNetwork: false,
+		WebhookLogRetentionDays:    30,
+
+		TwoFactorRequiredRoles: []int{},
+		TwoFactorIssuer:        "PixelPunk",
+
+		AccessTokenExpireMinutes: 15,
 	},
 
 	Vector: VectorSettings{
@@ -132,6 +151,16 @@ var DefaultSettings = struct {
 		VectorSearchThreshold:       0.36,
 		VectorMaxResults:            100,
 		VectorConcurrency:           3,
+		VectorQueryTarget:           "text",
+		VectorStore:                 "auto",
+
+		ImageEmbeddingEnabled:   false,
+		ImageEmbeddingAPIFormat: "openai",
+		ImageEmbeddingBaseURL:   "",
+		ImageEmbeddingAPIKey:    "",
+		ImageEmbeddingModel:     "jina-clip-v2",
+		ImageEmbeddingDimension: 1024,
+		ImageEmbeddingThreshold: 0.2,
 	},
 
 	Version: VersionSettings{
@@ -250,6 +279,11 @@ type UploadSettings struct {
 	AIAnalysisEnabled           bool
 	UserAllowedStorageDurations []string
 	UserDefaultStorageDuration  string
+	TrashRetentionDays          int    // 回收站保留天数，0表示删除后立即清除
+	S3GatewayEnabled            bool   // 是否启用 S3 兼容接口
+	WebDAVEnabled               bool   // 是否启用 WebDAV 接口
+	RenditionPresets            string // 响应式衍生尺寸预设，如 small=320w webp, medium=1024w avif
+	RenditionMode               string // 衍生尺寸生成时机 upload/lazy
 }
 
 // ThemeSettings 网站装修设置
@@ -277,6 +311,20 @@ type SecuritySettings struct {
 	IPBlacklist           string
 	DomainWhitelist       string
 	DomainBlacklist       string
+
+	SignedLinkMaxExpireHours int  // 签名链接最长有效期(小时)
+	SignedLinkNativeEnabled  bool // 是否允许委托存储渠道原生预签名
+
+	WebhookEnabled             bool // 是否启用出站回调
+	WebhookMaxAttempts         int  // 单次投递最大尝试次数
+	WebhookTimeoutSeconds      int  // 单次请求超时(秒)
+	WebhookAllowPrivateNetwork bool // 是否允许投递到内网地址
+	WebhookLogRetentionDays    int  // 已成功投递记录的保留天数
+
+	TwoFactorRequiredRoles []int  // 强制启用两步验证的角色
+	TwoFactorIssuer        string // 身份验证器中显示的发行方名称
+
+	AccessTokenExpireMinutes int // 访问令牌有效期(分钟)，登录有效期由刷新令牌控制
 }
 
 // VectorSettings 向量搜索设置
@@ -292,6 +340,16 @@ type VectorSettings struct {
 	VectorSearchThreshold       float64
 	VectorMaxResults            int
 	VectorConcurrency           int
+	VectorQueryTarget           string // 语义搜索默认使用的向量：text / image / both
+	VectorStore                 string // 向量存储：auto / qdrant / embedded
+
+	ImageEmbeddingEnabled   bool    // 是否启用图像向量化（CLIP类多模态模型）
+	ImageEmbeddingAPIFormat string  // 接口格式：openai / local
+	ImageEmbeddingBaseURL   string  // 接口地址
+	ImageEmbeddingAPIKey    string  // 接口密钥
+	ImageEmbeddingModel     string  // 模型名称
+	ImageEmbeddingDimension int     // 向量维度
+	ImageEmbeddingThreshold float64 // 图像向量搜索阈值
 }
 
 // VersionSettings 版本信息设置
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"fmt"
+	"pixelpunk/internal/controllers/setting/dto"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/pkg/logger"
+)
+
+// upsertFeatureSettings 为已安装的系统补充新功能的设置项
+func upsertFeatureSettings(feature string, settings []dto.SettingCreateDTO) error {
+	result, err := setting.BatchUpsertSettings(&dto.BatchUpsertSettingDTO{Settings: settings})
+	if err != nil {
+		return fmt.Errorf("初始化%s设置失败: %v", feature, err)
+	}
+
+	for _, failedItem := range result.Failed {
+		logger.Errorf("设置项 %s 初始化失败: %s", failedItem.Key, failedItem.Message)
+	}
+	logger.Infof("%s设置初始化完成! 成功: %d, 失败: %d", feature, len(result.Success), len(result.Failed))
+	return nil
+}
@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"fmt"
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/rbac"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// MigrateAdminRoles 写入内置后台角色，并将现有的超级管理员、管理员映射到对应角色
+func MigrateAdminRoles(db *gorm.DB) error {
+	roleIDs := make(map[string]uint)
+	for _, builtin := range rbac.BuiltinRoles() {
+		role := builtin
+		if err := db.Where("name = ?", role.Name).Attrs(role).FirstOrCreate(&role).Error; err != nil {
+			return fmt.Errorf("创建内置角色 %s 失败: %v", builtin.Name, err)
+		}
+		if !role.IsBuiltIn {
+			if err := db.Model(&role).Update("is_built_in", true).Error; err != nil {
+				return fmt.Errorf("标记内置角色 %s 失败: %v", builtin.Name, err)
+			}
+		}
+		roleIDs[role.Name] = role.ID
+	}
+
+	mappings := map[int]string{
+		common.UserRoleSuperAdmin: models.AdminRoleSuperAdmin,
+		common.UserRoleAdmin:      models.AdminRoleAdmin,
+	}
+	var migrated int64
+	for userRole, roleName := range mappings {
+		result := db.Model(&models.User{}).
+			Where("role = ? AND (admin_role_id = 0 OR admin_role_id IS NULL)", userRole).
+			Update("admin_role_id", roleIDs[roleName])
+		if result.Error != nil {
+			return fmt.Errorf("映射 %s 角色失败: %v", roleName, result.Error)
+		}
+		migrated += result.RowsAffected
+	}
+
+	logger.Infof("后台角色迁移完成，共映射 %d 个管理员", migrated)
+	return nil
+}
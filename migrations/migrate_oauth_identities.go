@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"fmt"
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/logger"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// MigrateOAuthIdentities 将 user 表中按提供方分列的第三方关联迁移到 user_identity
+func MigrateOAuthIdentities(db *gorm.DB) error {
+	var users []models.User
+	if err := db.Where("github_id IS NOT NULL OR google_id IS NOT NULL OR linuxdo_id IS NOT NULL").
+		Find(&users).Error; err != nil {
+		return fmt.Errorf("查询第三方关联用户失败: %v", err)
+	}
+
+	migrated := 0
+	for _, u := range users {
+		var identities []models.UserIdentity
+		if u.GithubID != nil {
+			identities = append(identities, models.UserIdentity{UserID: u.ID, Provider: "github", Subject: strconv.FormatInt(*u.GithubID, 10), Email: u.Email})
+		}
+		if u.GoogleID != nil && *u.GoogleID != "" {
+			identities = append(identities, models.UserIdentity{UserID: u.ID, Provider: "google", Subject: *u.GoogleID, Email: u.Email})
+		}
+		if u.LinuxdoID != nil {
+			identities = append(identities, models.UserIdentity{UserID: u.ID, Provider: "linuxdo", Subject: strconv.FormatInt(*u.LinuxdoID, 10), Email: u.Email})
+		}
+
+		for _, identity := range identities {
+			var count int64
+			db.Model(&models.UserIdentity{}).Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).Count(&count)
+			if count > 0 {
+				continue
+			}
+			if err := db.Create(&identity).Error; err != nil {
+				return fmt.Errorf("迁移用户 %d 的 %s 关联失败: %v", u.ID, identity.Provider, err)
+			}
+			migrated++
+		}
+	}
+
+	logger.Infof("第三方登录关联迁移完成，共迁移 %d 条", migrated)
+	return nil
+}
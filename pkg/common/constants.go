@@ -83,6 +83,12 @@ const (
 const (
 	ShareItemTypeFolder = "folder"
 	ShareItemTypeFile   = "file"
+	ShareItemTypeAlbum  = "album"
+)
+
+const (
+	ShareTypeView   = "view"   // 访客浏览分享内容
+	ShareTypeUpload = "upload" // 文件收集：访客上传文件到指定文件夹
 )
 
 const (
@@ -135,6 +141,7 @@ const (
 	MessageTypeRandomAPIEnabled  = "random_api.enabled"
 
 	MessageTypeShareExpiryWarning = "share.expiry_warning"
+	MessageTypeShareFileReceived  = "share.file_received"
 )
 
 const (
@@ -143,6 +143,14 @@ func GetSimilarFingerprints(fingerprint string) []string {
 	return similar
 }
 
+// DeviceName 根据User-Agent生成简短的设备描述，如 "Chrome · Windows"
+func DeviceName(ua string) string {
+	if ua == "" {
+		return "Unknown"
+	}
+	return extractBrowser(ua) + " · " + extractOS(ua)
+}
+
 // extractBrowser 从User-Agent中提取浏览器信息
 func extractBrowser(ua string) string {
 	browsers := []struct {
@@ -346,9 +346,29 @@ func autoMigrate() error {
 		&models.File{},
 		&models.FileStats{},
 		&models.FileDownloadLog{},
+		&models.FileVariant{},
+		&models.SignedLink{},
+		&models.FilePHashBand{},
+		&models.TrashItem{},
+		&models.StorageMigrationJob{},
+		&models.StorageMigrationFailure{},
+		&models.ReplicationPolicy{},
+		&models.FileReplica{},
+		&models.WatermarkPolicy{},
+		&models.FileSearchDocument{},
+		&models.FileSearchTerm{},
+		&models.S3MultipartUpload{},
+		&models.S3MultipartPart{},
 		&models.Folder{},
 		&models.UserUsageStats{},
 		&models.UserSettings{},
+		&models.UserTwoFactor{},
+		&models.UserIdentity{},
+		&models.UserSession{},
+		&models.AdminRole{},
+		&models.Workspace{},
+		&models.WorkspaceMember{},
+		&models.WorkspaceActivity{},
 		&models.GlobalStats{},
 		&models.APIKey{},
 		&models.RandomImageAPI{},
@@ -360,12 +380,16 @@ func autoMigrate() error {
 		&models.UserAccessControl{},
 		&models.Share{},
 		&models.ShareItem{},
+		&models.Album{},
+		&models.AlbumFile{},
 		&models.ShareAccessLog{},
 		&models.ShareVisitorInfo{},
+		&models.ShareUpload{},
 		&models.ShareAccessToken{},
 		&models.UploadSession{},
 		&models.UploadChunk{},
 		&models.FileVector{},
+		&models.FileEmbedding{},
 		&models.VectorProcessingLog{},
 		&models.VectorVerificationTask{},
 		&models.ReviewLog{},
@@ -388,6 +412,9 @@ func autoMigrate() error {
 		&models.AIJob{},
 		&models.VectorJob{},
 		&models.Announcement{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.WebhookDeliveryAttempt{},
 	}
 
 	silentDB := DB.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)})
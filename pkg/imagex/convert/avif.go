@@ -0,0 +1,59 @@
+package convert
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/disintegration/imaging"
+)
+
+// AVIF 编码依赖系统安装的 libavif 命令行工具 avifenc（纯 Go 暂无可用编码器）
+const avifEncoderBinary = "avifenc"
+
+var (
+	avifOnce      sync.Once
+	avifAvailable bool
+)
+
+// AVIFAvailable 系统中是否可用 AVIF 编码器
+func AVIFAvailable() bool {
+	avifOnce.Do(func() {
+		_, err := exec.LookPath(avifEncoderBinary)
+		avifAvailable = err == nil
+	})
+	return avifAvailable
+}
+
+// EncodeAVIF 将已解码的图像编码为AVIF
+func EncodeAVIF(img image.Image, quality int) ([]byte, error) {
+	if !AVIFAvailable() {
+		return nil, fmt.Errorf("avif encoder %s not found", avifEncoderBinary)
+	}
+
+	dir, err := os.MkdirTemp("", "avif-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input.png")
+	output := filepath.Join(dir, "output.avif")
+	if err := imaging.Save(img, input); err != nil {
+		return nil, fmt.Errorf("write temp png: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, avifEncoderBinary, "-q", strconv.Itoa(safeQ(quality)), "-s", "6", input, output)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("avifenc: %w: %s", err, out)
+	}
+	return os.ReadFile(output)
+}
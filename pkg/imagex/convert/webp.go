@@ -28,7 +28,13 @@ func ToWebP(input []byte, opts WebPOptions) (*WebPResult, error) {
 	if err != nil {
 		return nil, fmt.Errorf("decode: %w", err)
 	}
+	return EncodeWebP(file, opts)
+}
+
+// EncodeWebP 将已解码的图像编码为WebP
+func EncodeWebP(file image.Image, opts WebPOptions) (*WebPResult, error) {
 	var enc *encoder.Options
+	var err error
 	if opts.Lossless {
 		enc, err = encoder.NewLosslessEncoderOptions(encoder.PresetDefault, clampLevel(opts.CompressionLevel))
 	} else {
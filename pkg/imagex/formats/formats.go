@@ -26,6 +26,7 @@ var extToMIME = map[string]string{
 	"tga":  "image/x-tga",
 	"heic": "image/heic",
 	"heif": "image/heif",
+	"avif": "image/avif",
 }
 
 // NormalizeFormat 规格化格式/扩展名（去点、转小写）
@@ -0,0 +1,76 @@
+package hash
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math/bits"
+	"strconv"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+
+	"github.com/disintegration/imaging"
+)
+
+// DHashBands 感知哈希分段数量（每段8位），汉明距离小于该值时至少有一段完全相同
+const DHashBands = 8
+
+// DHash 计算64位差值哈希（dHash），对缩放、重新编码与轻微调色不敏感
+func DHash(img image.Image) uint64 {
+	small := imaging.Grayscale(imaging.Resize(img, 9, 8, imaging.Box))
+
+	var h uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			left := small.Pix[small.PixOffset(x, y)]
+			right := small.Pix[small.PixOffset(x+1, y)]
+			h <<= 1
+			if left > right {
+				h |= 1
+			}
+		}
+	}
+	return h
+}
+
+// DHashFromBytes 解码图片数据并计算dHash
+func DHashFromBytes(data []byte) (uint64, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("decode image: %w", err)
+	}
+	return DHash(img), nil
+}
+
+// Hamming 返回两个哈希的汉明距离
+func Hamming(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// FormatPerceptual 将哈希格式化为16位十六进制字符串
+func FormatPerceptual(h uint64) string {
+	return fmt.Sprintf("%016x", h)
+}
+
+// ParsePerceptual 解析16位十六进制哈希字符串
+func ParsePerceptual(s string) (uint64, error) {
+	if len(s) != 16 {
+		return 0, fmt.Errorf("invalid perceptual hash length: %d", len(s))
+	}
+	return strconv.ParseUint(s, 16, 64)
+}
+
+// Bands 将哈希拆分为 DHashBands 段，用于索引候选查找
+func Bands(h uint64) [DHashBands]uint8 {
+	var out [DHashBands]uint8
+	for i := 0; i < DHashBands; i++ {
+		out[i] = uint8(h >> (uint(i) * 8))
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package hash
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/disintegration/imaging"
+)
+
+func gradientImage(w, h int, invert bool) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			c := color.RGBA{R: uint8((x*255/w + y*128/h) % 256), G: uint8(x * 255 / w), B: 80, A: 255}
+			if invert {
+				c = color.RGBA{R: 255 - c.R, G: 255 - c.G, B: 255 - c.B, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// TestDHashNearDuplicate 验证缩放与重新编码后的图片哈希接近，不同图片差异明显
+func TestDHashNearDuplicate(t *testing.T) {
+	src := gradientImage(640, 480, false)
+
+	var original bytes.Buffer
+	if err := png.Encode(&original, src); err != nil {
+		t.Fatal(err)
+	}
+	var resized bytes.Buffer
+	if err := jpeg.Encode(&resized, imaging.Resize(src, 200, 0, imaging.Lanczos), &jpeg.Options{Quality: 60}); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := DHashFromBytes(original.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := DHashFromBytes(resized.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := Hamming(a, b); d > 6 {
+		t.Errorf("resized copy distance = %d, want <= 6", d)
+	}
+
+	c := DHash(gradientImage(640, 480, true))
+	if d := Hamming(a, c); d < 16 {
+		t.Errorf("different image distance = %d, want >= 16", d)
+	}
+}
+
+// TestPerceptualRoundTrip 验证哈希格式化与分段
+func TestPerceptualRoundTrip(t *testing.T) {
+	h := uint64(0x0123456789abcdef)
+	parsed, err := ParsePerceptual(FormatPerceptual(h))
+	if err != nil || parsed != h {
+		t.Fatalf("round trip = %x, %v", parsed, err)
+	}
+	bands := Bands(h)
+	if bands[0] != 0xef || bands[7] != 0x01 {
+		t.Errorf("bands = %x", bands)
+	}
+}
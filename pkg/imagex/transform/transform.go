@@ -0,0 +1,254 @@
+package transform
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+
+	"pixelpunk/pkg/imagex/convert"
+
+	"github.com/disintegration/imaging"
+)
+
+// 缩放模式
+const (
+	FitContain = "contain" // 等比缩放到框内（不放大）
+	FitCover   = "cover"   // 等比缩放并居中裁剪填满
+	FitFill    = "fill"    // 拉伸到指定尺寸
+)
+
+// 输出格式
+const (
+	FormatAuto = "auto"
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+	FormatAVIF = "avif"
+)
+
+// MaxDimension 单边最大像素，防止恶意请求耗尽内存
+const MaxDimension = 4096
+
+// MaxSourcePixels 原图像素总数上限，解码前按头信息校验，避免超大原图每次变换都完整解码
+const MaxSourcePixels = 50_000_000
+
+// 查询参数中的尺寸与质量按步长向上取整，限制同一文件可产生的衍生图组合数
+const (
+	sizeStep    = 64
+	qualityStep = 10
+)
+
+// Options 变换参数
+type Options struct {
+	Width   int
+	Height  int
+	Fit     string
+	Quality int
+	Format  string
+}
+
+// Result 变换结果
+type Result struct {
+	Data   []byte
+	Width  int
+	Height int
+	Format string
+}
+
+// FromQuery 从查询参数解析变换参数（w/h/fit/q/fmt），未携带任何参数时返回 false
+func FromQuery(values url.Values) (Options, bool, error) {
+	opts := Options{}
+	present := false
+
+	parseInt := func(key string, max int) (int, error) {
+		raw := strings.TrimSpace(values.Get(key))
+		if raw == "" {
+			return 0, nil
+		}
+		present = true
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 || n > max {
+			return 0, fmt.Errorf("参数 %s 无效，取值范围 0-%d", key, max)
+		}
+		return n, nil
+	}
+
+	var err error
+	if opts.Width, err = parseInt("w", MaxDimension); err != nil {
+		return opts, true, err
+	}
+	if opts.Height, err = parseInt("h", MaxDimension); err != nil {
+		return opts, true, err
+	}
+	if opts.Quality, err = parseInt("q", 100); err != nil {
+		return opts, true, err
+	}
+	opts.Width = roundUp(opts.Width, sizeStep, MaxDimension)
+	opts.Height = roundUp(opts.Height, sizeStep, MaxDimension)
+	opts.Quality = roundUp(opts.Quality, qualityStep, 100)
+
+	if fit := strings.ToLower(strings.TrimSpace(values.Get("fit"))); fit != "" {
+		present = true
+		switch fit {
+		case FitContain, FitCover, FitFill:
+			opts.Fit = fit
+		default:
+			return opts, true, fmt.Errorf("参数 fit 无效，可选值: contain, cover, fill")
+		}
+	}
+
+	if f := strings.ToLower(strings.TrimSpace(values.Get("fmt"))); f != "" {
+		present = true
+		switch f {
+		case "jpg", FormatJPEG:
+			opts.Format = FormatJPEG
+		case FormatPNG, FormatWebP, FormatAVIF, FormatAuto:
+			opts.Format = f
+		default:
+			return opts, true, fmt.Errorf("参数 fmt 无效，可选值: jpeg, png, webp, avif, auto")
+		}
+	}
+
+	return opts.normalize(), present, nil
+}
+
+// roundUp 向上取整到 step 的倍数，不超过 max；0 表示未指定，保持不变
+func roundUp(n, step, max int) int {
+	if n <= 0 {
+		return n
+	}
+	return min((n+step-1)/step*step, max)
+}
+
+func (o Options) normalize() Options {
+	if o.Fit == "" {
+		o.Fit = FitContain
+	}
+	if o.Format == "" {
+		o.Format = FormatAuto
+	}
+	if o.Quality <= 0 {
+		o.Quality = 85
+	}
+	return o
+}
+
+// Key 返回参数的规范化摘要，用于衍生图缓存键
+func (o Options) Key() string {
+	o = o.normalize()
+	canonical := fmt.Sprintf("w%d_h%d_%s_q%d_%s", o.Width, o.Height, o.Fit, o.Quality, o.Format)
+	sum := md5.Sum([]byte(canonical))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// Apply 对原图执行缩放/裁剪并重新编码
+func Apply(input []byte, opts Options) (*Result, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty input")
+	}
+	opts = opts.normalize()
+
+	if err := CheckSourceSize(input); err != nil {
+		return nil, err
+	}
+	src, srcFormat, err := image.Decode(bytes.NewReader(input))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+
+	out := resize(src, opts)
+
+	format := opts.Format
+	if format == FormatAuto {
+		switch srcFormat {
+		case "png", "webp":
+			format = srcFormat
+		case "gif":
+			format = FormatPNG
+		default:
+			format = FormatJPEG
+		}
+	}
+
+	data, err := encode(out, format, opts.Quality)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Data: data, Width: out.Bounds().Dx(), Height: out.Bounds().Dy(), Format: format}, nil
+}
+
+// CheckSourceSize 只读取图片头信息，像素总数超过 MaxSourcePixels 时返回错误
+func CheckSourceSize(input []byte) error {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(input))
+	if err != nil {
+		return fmt.Errorf("decode image config: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > MaxSourcePixels {
+		return fmt.Errorf("image too large: %dx%d", cfg.Width, cfg.Height)
+	}
+	return nil
+}
+
+func resize(src image.Image, opts Options) image.Image {
+	w, h := opts.Width, opts.Height
+	if w == 0 && h == 0 {
+		return src
+	}
+	if w == 0 || h == 0 {
+		// 单边约束时始终等比缩放，且不放大
+		b := src.Bounds()
+		if (w > 0 && w >= b.Dx()) || (h > 0 && h >= b.Dy()) {
+			return src
+		}
+		return imaging.Resize(src, w, h, imaging.Lanczos)
+	}
+	switch opts.Fit {
+	case FitCover:
+		return imaging.Fill(src, w, h, imaging.Center, imaging.Lanczos)
+	case FitFill:
+		return imaging.Resize(src, w, h, imaging.Lanczos)
+	default:
+		return imaging.Fit(src, w, h, imaging.Lanczos)
+	}
+}
+
+func encode(img image.Image, format string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case FormatPNG:
+		if err := imaging.Encode(&buf, img, imaging.PNG); err != nil {
+			return nil, err
+		}
+	case FormatWebP:
+		res, err := convert.EncodeWebP(img, convert.WebPOptions{Quality: quality})
+		if err != nil {
+			return nil, fmt.Errorf("encode webp: %w", err)
+		}
+		return io.ReadAll(res.Reader)
+	case FormatAVIF:
+		data, err := convert.EncodeAVIF(img, quality)
+		if err != nil {
+			return nil, fmt.Errorf("encode avif: %w", err)
+		}
+		return data, nil
+	default:
+		if err := imaging.Encode(&buf, img, imaging.JPEG, imaging.JPEGQuality(quality)); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
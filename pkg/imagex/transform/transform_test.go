@@ -0,0 +1,122 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"net/url"
+	"testing"
+)
+
+// TestFromQuery 验证查询参数解析与校验
+func TestFromQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		present   bool
+		expectErr bool
+		expected  Options
+	}{
+		{name: "无参数", query: "", present: false, expected: Options{Fit: FitContain, Format: FormatAuto, Quality: 85}},
+		{name: "仅宽度", query: "w=320", present: true, expected: Options{Width: 320, Fit: FitContain, Format: FormatAuto, Quality: 85}},
+		{name: "完整参数", query: "w=320&h=192&fit=cover&q=70&fmt=jpg", present: true, expected: Options{Width: 320, Height: 192, Fit: FitCover, Format: FormatJPEG, Quality: 70}},
+		{name: "尺寸与质量按步长取整", query: "w=300&h=4095&q=71", present: true, expected: Options{Width: 320, Height: 4096, Fit: FitContain, Format: FormatAuto, Quality: 80}},
+		{name: "超出最大尺寸", query: "w=99999", present: true, expectErr: true},
+		{name: "非法fit", query: "fit=stretch", present: true, expectErr: true},
+		{name: "非法格式", query: "fmt=bmp", present: true, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.query)
+			opts, present, err := FromQuery(values)
+			if present != tt.present {
+				t.Fatalf("present = %v, want %v", present, tt.present)
+			}
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", tt.query)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if opts != tt.expected {
+				t.Errorf("opts = %+v, want %+v", opts, tt.expected)
+			}
+		})
+	}
+}
+
+// TestKeyIsCanonical 验证等价参数生成相同缓存键
+func TestKeyIsCanonical(t *testing.T) {
+	a := Options{Width: 100}
+	b := Options{Width: 100, Fit: FitContain, Format: FormatAuto, Quality: 85}
+	if a.Key() != b.Key() {
+		t.Errorf("equivalent options produced different keys: %s vs %s", a.Key(), b.Key())
+	}
+	if a.Key() == (Options{Width: 101}).Key() {
+		t.Error("different options produced the same key")
+	}
+}
+
+// TestApplyResize 验证各缩放模式的输出尺寸
+func TestApplyResize(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for x := 0; x < 400; x++ {
+		for y := 0; y < 200; y++ {
+			src.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		opts   Options
+		width  int
+		height int
+		format string
+	}{
+		{name: "contain", opts: Options{Width: 100, Height: 100}, width: 100, height: 50, format: FormatPNG},
+		{name: "cover", opts: Options{Width: 100, Height: 100, Fit: FitCover}, width: 100, height: 100, format: FormatPNG},
+		{name: "fill", opts: Options{Width: 100, Height: 100, Fit: FitFill, Format: FormatJPEG}, width: 100, height: 100, format: FormatJPEG},
+		{name: "单边不放大", opts: Options{Width: 800}, width: 400, height: 200, format: FormatPNG},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Apply(buf.Bytes(), tt.opts)
+			if err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+			if res.Width != tt.width || res.Height != tt.height {
+				t.Errorf("size = %dx%d, want %dx%d", res.Width, res.Height, tt.width, tt.height)
+			}
+			if res.Format != tt.format {
+				t.Errorf("format = %s, want %s", res.Format, tt.format)
+			}
+		})
+	}
+}
+
+// TestApplyRejectsOversizedSource 验证按头信息拒绝像素总数超限的原图
+func TestApplyRejectsOversizedSource(t *testing.T) {
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, image.NewPaletted(image.Rect(0, 0, 1, 1), []color.Color{color.Black}), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	binary.LittleEndian.PutUint16(data[6:], 60000)
+	binary.LittleEndian.PutUint16(data[8:], 60000)
+
+	if _, err := Apply(data, Options{Width: 64}); err == nil {
+		t.Fatal("expected oversized source to be rejected")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"mime/multipart"
+	"time"
 )
 
 // StorageAdapter 存储适配器接口
@@ -28,6 +29,19 @@ type StorageAdapter interface {
 	GetCapabilities() Capabilities
 }
 
+// Presigner 可选接口：支持原生预签名下载链接的适配器（S3/R2/OSS/COS）
+// 生成的链接由存储服务直接校验，访问流量不经过本服务
+type Presigner interface {
+	PresignGetURL(ctx context.Context, path string, expires time.Duration) (string, error)
+}
+
+// RangeReader 可选接口：支持查询对象大小与按字节区间读取的适配器（S3/R2）
+// 代理回源时用于转发 Range 请求，避免每个分段请求都拉取整个对象；length < 0 表示读到对象末尾
+type RangeReader interface {
+	ObjectSize(ctx context.Context, path string) (int64, error)
+	ReadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error)
+}
+
 // UploadRequest 上传请求
 type UploadRequest struct {
 	File          *multipart.FileHeader // 上传的文件
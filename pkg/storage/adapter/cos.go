@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"pixelpunk/pkg/imagex/compress"
 	"pixelpunk/pkg/imagex/decode"
@@ -236,6 +237,18 @@ func (a *COSAdapter) GetURL(path string, options *URLOptions) (string, error) {
 	return fmt.Sprintf("%s://%s.cos.%s.myqcloud.com/%s", scheme, a.bucket, a.region, encodePathSegments(path)), nil
 }
 
+// PresignGetURL 生成原生预签名下载链接
+func (a *COSAdapter) PresignGetURL(ctx context.Context, path string, expires time.Duration) (string, error) {
+	if !a.initialized {
+		return "", NewStorageError(ErrorTypeInternal, "adapter not initialized", nil)
+	}
+	u, err := a.client.Object.GetPresignedURL(ctx, http.MethodGet, path, a.secretID, a.secretKey, expires, nil)
+	if err != nil {
+		return "", NewStorageError(ErrorTypeInternal, "failed to generate presigned URL", err)
+	}
+	return u.String(), nil
+}
+
 func (a *COSAdapter) GetCapabilities() Capabilities {
 	return Capabilities{
 		SupportsSignedURL: true,
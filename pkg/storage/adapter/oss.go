@@ -8,6 +8,7 @@ import (
 	"io"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"pixelpunk/pkg/imagex/compress"
 	"pixelpunk/pkg/imagex/convert"
@@ -249,7 +250,20 @@ func (a *OSSAdapter) GetURL(path string, options *URLOptions) (string, error) {
 	return fmt.Sprintf("%s://%s.%s/%s", scheme, a.bucket, a.endpoint, encodePathSegments(path)), nil
 }
 
-//
+// PresignGetURL 生成原生预签名下载链接
+func (a *OSSAdapter) PresignGetURL(ctx context.Context, path string, expires time.Duration) (string, error) {
+	if !a.initialized {
+		return "", NewStorageError(ErrorTypeInternal, "adapter not initialized", nil)
+	}
+	result, err := a.client.Presign(ctx, &oss.GetObjectRequest{
+		Bucket: oss.Ptr(a.bucket),
+		Key:    oss.Ptr(path),
+	}, oss.PresignExpiration(time.Now().Add(expires)))
+	if err != nil {
+		return "", NewStorageError(ErrorTypeInternal, "failed to generate presigned URL", err)
+	}
+	return result.URL, nil
+}
 
 // HealthCheck 健康检查
 func (a *OSSAdapter) HealthCheck(ctx context.Context) error {
@@ -208,6 +208,30 @@ func (a *R2Adapter) ReadFile(ctx context.Context, path string) (io.ReadCloser, e
 	return resp.Body, nil
 }
 
+// ObjectSize 查询对象的实际存储大小
+func (a *R2Adapter) ObjectSize(ctx context.Context, path string) (int64, error) {
+	if !a.initialized {
+		return 0, NewStorageError(ErrorTypeInternal, "adapter not initialized", nil)
+	}
+	return s3ObjectSize(ctx, a.client, a.bucket, path)
+}
+
+// ReadRange 按字节区间读取对象
+func (a *R2Adapter) ReadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	if !a.initialized {
+		return nil, NewStorageError(ErrorTypeInternal, "adapter not initialized", nil)
+	}
+	return s3ReadRange(ctx, a.client, a.bucket, path, offset, length)
+}
+
+// PresignGetURL 生成原生预签名下载链接
+func (a *R2Adapter) PresignGetURL(ctx context.Context, path string, expires time.Duration) (string, error) {
+	if !a.initialized {
+		return "", NewStorageError(ErrorTypeInternal, "adapter not initialized", nil)
+	}
+	return a.generatePresignedURL(path, &URLOptions{Expires: int64(expires / time.Second)})
+}
+
 // generatePresignedURL 生成私有访问签名 URL
 func (a *R2Adapter) generatePresignedURL(path string, options *URLOptions) (string, error) {
 	if a.presignClient == nil {
@@ -213,6 +213,30 @@ func (a *S3Adapter) generatePresignedURL(path string, options *URLOptions) (stri
 	return req.URL, nil
 }
 
+// ObjectSize 查询对象的实际存储大小
+func (a *S3Adapter) ObjectSize(ctx context.Context, path string) (int64, error) {
+	if !a.initialized {
+		return 0, NewStorageError(ErrorTypeInternal, "adapter not initialized", nil)
+	}
+	return s3ObjectSize(ctx, a.client, a.bucket, path)
+}
+
+// ReadRange 按字节区间读取对象
+func (a *S3Adapter) ReadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	if !a.initialized {
+		return nil, NewStorageError(ErrorTypeInternal, "adapter not initialized", nil)
+	}
+	return s3ReadRange(ctx, a.client, a.bucket, path, offset, length)
+}
+
+// PresignGetURL 生成原生预签名下载链接（忽略桶的公开/私有配置）
+func (a *S3Adapter) PresignGetURL(ctx context.Context, path string, expires time.Duration) (string, error) {
+	if !a.initialized {
+		return "", NewStorageError(ErrorTypeInternal, "adapter not initialized", nil)
+	}
+	return a.generatePresignedURL(path, &URLOptions{Expires: int64(expires / time.Second)})
+}
+
 func (a *S3Adapter) SetObjectACL(ctx context.Context, path string, acl string) error {
 	if !a.initialized {
 		return NewStorageError(ErrorTypeInternal, "adapter not initialized", nil)
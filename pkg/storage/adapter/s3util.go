@@ -1,11 +1,38 @@
 package adapter
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"strings"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
+// s3ObjectSize returns the stored size of an object via HeadObject.
+func s3ObjectSize(ctx context.Context, client *s3.Client, bucket, path string) (int64, error) {
+	out, err := client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(path)})
+	if err != nil {
+		return 0, err
+	}
+	return aws.ToInt64(out.ContentLength), nil
+}
+
+// s3ReadRange reads length bytes starting at offset; length < 0 reads to the end of the object.
+func s3ReadRange(ctx context.Context, client *s3.Client, bucket, path string, offset, length int64) (io.ReadCloser, error) {
+	rangeHeader := fmt.Sprintf("bytes=%d-", offset)
+	if length >= 0 {
+		rangeHeader = fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+	}
+	resp, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(path), Range: aws.String(rangeHeader)})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
 // s3ExtractHost trims scheme and returns host for an endpoint like https://host or host.
 func s3ExtractHost(endpoint string) string {
 	h := strings.TrimSpace(endpoint)
@@ -337,3 +337,32 @@ func (m *StorageManager) ReadFile(ctx context.Context, channelID, path string) (
 
 	return adapterInstance.ReadFile(ctx, path)
 }
+
+// PresignGetURL 通过支持原生预签名的适配器生成下载链接
+func (m *StorageManager) PresignGetURL(ctx context.Context, channelID, path string, expires time.Duration) (string, error) {
+	adapterInstance, err := m.GetAdapter(channelID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get adapter for channel %s: %w", channelID, err)
+	}
+
+	presigner, ok := adapterInstance.(adapter.Presigner)
+	if !ok || !adapterInstance.GetCapabilities().SupportsSignedURL {
+		return "", adapter.NewStorageError(
+			adapter.ErrorTypeInternal,
+			fmt.Sprintf("channel %s does not support presigned URLs", channelID),
+			nil,
+		)
+	}
+
+	return presigner.PresignGetURL(ctx, path, expires)
+}
+
+// RangeReader 返回支持区间读取的适配器（仅 S3/R2 等实现了 adapter.RangeReader 的渠道）
+func (m *StorageManager) RangeReader(channelID string) (adapter.RangeReader, bool) {
+	adapterInstance, err := m.GetAdapter(channelID)
+	if err != nil {
+		return nil, false
+	}
+	rr, ok := adapterInstance.(adapter.RangeReader)
+	return rr, ok
+}
@@ -17,6 +17,8 @@ type RemoteReadProvider interface {
 	IsDirectAccess() bool
 	GetRemoteContent(objectPath string, isThumb bool, userID uint) (io.ReadCloser, string, error)
 	GetFileURL(relativePath string, isThumb bool) (string, error)
+	// RemoteRangeReader 渠道支持区间读取时返回读取器与对象键
+	RemoteRangeReader(objectPath string, isThumb bool, userID uint) (adapter.RangeReader, string, bool)
 }
 
 type providerImpl struct {
@@ -46,12 +48,25 @@ func (p *providerImpl) GetFileURL(relativePath string, isThumb bool) (string, er
 	return "", fmt.Errorf("provider_compat已废弃，请使用FileID-based新架构")
 }
 
-func (p *providerImpl) GetRemoteContent(objectPath string, isThumb bool, userID uint) (io.ReadCloser, string, error) {
-	// Normalize logical path to object key
+func (p *providerImpl) RemoteRangeReader(objectPath string, isThumb bool, userID uint) (adapter.RangeReader, string, bool) {
+	rr, ok := p.ad.(adapter.RangeReader)
+	if !ok {
+		return nil, "", false
+	}
+	return rr, remoteObjectKey(objectPath, isThumb, userID), true
+}
+
+// remoteObjectKey normalizes a logical path to an object key.
+func remoteObjectKey(objectPath string, isThumb bool, userID uint) string {
 	key := pathutil.EnsureObjectKey(userID, objectPath, isThumb)
 	if key == "" {
 		key = strings.TrimPrefix(objectPath, "/")
 	}
+	return key
+}
+
+func (p *providerImpl) GetRemoteContent(objectPath string, isThumb bool, userID uint) (io.ReadCloser, string, error) {
+	key := remoteObjectKey(objectPath, isThumb, userID)
 	reader, err := p.ad.ReadFile(context.Background(), key)
 	if err != nil {
 		return nil, "", err
@@ -260,3 +260,13 @@ func (s *Storage) GetThumbnailBase64(ctx context.Context, channelID, path string
 func (s *Storage) ReadFile(ctx context.Context, channelID, path string) (io.ReadCloser, error) {
 	return s.manager.ReadFile(ctx, channelID, path)
 }
+
+// PresignGetURL 生成原生预签名下载链接（仅支持实现了 adapter.Presigner 的渠道）
+func (s *Storage) PresignGetURL(ctx context.Context, channelID, path string, expires time.Duration) (string, error) {
+	return s.manager.PresignGetURL(ctx, channelID, path, expires)
+}
+
+// RangeReader 返回渠道的区间读取能力，不支持时返回 false
+func (s *Storage) RangeReader(channelID string) (adapter.RangeReader, bool) {
+	return s.manager.RangeReader(channelID)
+}
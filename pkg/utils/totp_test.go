@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 附录 B 的 SHA1 测试向量（取后 6 位）
+func TestGenerateTOTPCodeRFCVectors(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	cases := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for ts, want := range cases {
+		got, err := GenerateTOTPCode(secret, TOTPStep(time.Unix(ts, 0)))
+		if err != nil {
+			t.Fatalf("GenerateTOTPCode(%d) error: %v", ts, err)
+		}
+		if got != want {
+			t.Errorf("GenerateTOTPCode(%d) = %s, want %s", ts, got, want)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	prev, _ := GenerateTOTPCode(secret, TOTPStep(now)-1)
+	if step, ok := ValidateTOTP(secret, prev, now); !ok || step != TOTPStep(now)-1 {
+		t.Errorf("previous step code should be accepted, got step=%d ok=%v", step, ok)
+	}
+	old, _ := GenerateTOTPCode(secret, TOTPStep(now)-3)
+	if _, ok := ValidateTOTP(secret, old, now); ok {
+		t.Error("code outside the skew window should be rejected")
+	}
+	if _, ok := ValidateTOTP(secret, "12345", now); ok {
+		t.Error("short code should be rejected")
+	}
+}
+
+func TestTOTPKeyURI(t *testing.T) {
+	uri := TOTPKeyURI("PixelPunk", "alice@example.com", "JBSWY3DPEHPK3PXP")
+	if !strings.HasPrefix(uri, "otpauth://totp/PixelPunk:alice@example.com?") {
+		t.Errorf("unexpected label in %s", uri)
+	}
+	if !strings.Contains(uri, "secret=JBSWY3DPEHPK3PXP") || !strings.Contains(uri, "issuer=PixelPunk") {
+		t.Errorf("missing params in %s", uri)
+	}
+}
@@ -140,6 +140,19 @@ func (s *URLSigner) VerifyFileURL(path, timeParam, signatureParam string) bool {
 	return hmac.Equal([]byte(expectedSignature), []byte(signatureParam))
 }
 
+// SignMessage 对任意消息生成签名（与文件URL签名共用密钥）
+func (s *URLSigner) SignMessage(message string) string {
+	return s.generateSignature(message)
+}
+
+// VerifyMessage 校验任意消息的签名
+func (s *URLSigner) VerifyMessage(message, signature string) bool {
+	if signature == "" {
+		return false
+	}
+	return hmac.Equal([]byte(s.generateSignature(message)), []byte(signature))
+}
+
 // generateSignature 生成HMAC签名
 func (s *URLSigner) generateSignature(message string) string {
 	mac := hmac.New(sha256.New, []byte(s.secret))
@@ -0,0 +1,451 @@
+package vector
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmbeddedStore 内置向量存储：向量持久化在主数据库，首次使用时全部加载到内存做暴力检索，适合未部署Qdrant的单机实例
+type EmbeddedStore struct {
+	db     *gorm.DB
+	mutex  sync.RWMutex
+	loaded bool
+	points map[string]*embeddedPoint // fileID -> 向量点
+}
+
+// embeddedPoint 内存中的向量点，向量已归一化，点积即余弦相似度
+type embeddedPoint struct {
+	vectors map[string][]float32
+	payload map[string]interface{}
+	attrs   payloadAttrs
+}
+
+func NewEmbeddedStore(db *gorm.DB) *EmbeddedStore {
+	return &EmbeddedStore{db: db, points: make(map[string]*embeddedPoint)}
+}
+
+// ensureLoaded 首次访问时从数据库加载全部向量，调用方需持有写锁
+func (s *EmbeddedStore) ensureLoaded() error {
+	if s.loaded {
+		return nil
+	}
+	if s.db == nil {
+		return fmt.Errorf("数据库连接不可用")
+	}
+
+	var rows []models.FileEmbedding
+	err := s.db.Model(&models.FileEmbedding{}).FindInBatches(&rows, 500, func(tx *gorm.DB, batch int) error {
+		for _, row := range rows {
+			p := s.points[row.FileID]
+			if p == nil {
+				p = &embeddedPoint{vectors: make(map[string][]float32)}
+				if err := p.setPayload(row.Payload); err != nil {
+					logger.Warn("解析向量payload失败 [%s]: %v", row.FileID, err)
+				}
+				s.points[row.FileID] = p
+			}
+			p.vectors[row.Name] = normalizeVector(decodeVector(row.Vector))
+		}
+		return nil
+	}).Error
+	if err != nil {
+		s.points = make(map[string]*embeddedPoint)
+		return fmt.Errorf("加载内置向量失败: %v", err)
+	}
+	s.loaded = true
+	logger.Info("内置向量存储已加载 %d 个文件的向量", len(s.points))
+	return nil
+}
+
+func (p *embeddedPoint) setPayload(raw []byte) error {
+	p.payload = map[string]interface{}{}
+	p.attrs = payloadAttrs{}
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, &p.payload); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, &p.attrs)
+}
+
+// mergePayload 合并payload字段（与Qdrant set payload一致，未提供的字段保持不变），返回合并后的JSON
+func (p *embeddedPoint) mergePayload(payload map[string]interface{}) ([]byte, error) {
+	merged := make(map[string]interface{}, len(p.payload)+len(payload))
+	for k, v := range p.payload {
+		merged[k] = v
+	}
+	for k, v := range payload {
+		merged[k] = v
+	}
+	raw, err := json.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("序列化payload失败: %w", err)
+	}
+	if err := p.setPayload(raw); err != nil {
+		return nil, fmt.Errorf("解析payload失败: %w", err)
+	}
+	return raw, nil
+}
+
+// StoreVector 存储描述文本向量
+func (s *EmbeddedStore) StoreVector(fileID string, vector []float32, description string, model string) error {
+	return s.storeNamedVector(fileID, VectorNameText, vector, model, map[string]interface{}{
+		"description": description,
+		"model":       model,
+	}, true)
+}
+
+// StoreImageVector 存储图像向量，保留同一文件的文本向量
+func (s *EmbeddedStore) StoreImageVector(fileID string, vector []float32, model string) error {
+	return s.storeNamedVector(fileID, VectorNameImage, vector, model, map[string]interface{}{"image_model": model}, false)
+}
+
+// storeNamedVector 写入指定命名向量；refresh 为 true 或文件首次写入时重新汇总可过滤属性
+func (s *EmbeddedStore) storeNamedVector(fileID, name string, vector []float32, model string, payload map[string]interface{}, refresh bool) error {
+	if len(vector) == 0 {
+		return fmt.Errorf("向量为空")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.ensureLoaded(); err != nil {
+		return err
+	}
+
+	p := s.points[fileID]
+	isNew := p == nil
+	if isNew {
+		p = &embeddedPoint{vectors: make(map[string][]float32), payload: map[string]interface{}{}}
+	}
+	full := payload
+	if refresh || isNew {
+		full = BuildFilePayload(s.db, fileID)
+		for k, v := range payload {
+			full[k] = v
+		}
+	}
+	raw, err := p.mergePayload(full)
+	if err != nil {
+		return err
+	}
+
+	row := models.FileEmbedding{
+		FileID:    fileID,
+		Name:      name,
+		UserID:    p.attrs.UserID,
+		Model:     model,
+		Dimension: len(vector),
+		Vector:    encodeVector(vector),
+		Payload:   raw,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&row).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.FileEmbedding{}).
+			Where("file_id = ? AND name <> ?", fileID, name).
+			Updates(map[string]interface{}{"payload": raw, "user_id": p.attrs.UserID}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("存储向量失败: %v", err)
+	}
+
+	p.vectors[name] = normalizeVector(vector)
+	s.points[fileID] = p
+	return nil
+}
+
+// BatchStoreVectors 批量存储向量
+func (s *EmbeddedStore) BatchStoreVectors(items []VectorItem) error {
+	for _, item := range items {
+		if err := s.StoreVector(item.FileID, item.Vector, item.Description, item.Model); err != nil {
+			return fmt.Errorf("批量存储失败，文件ID: %s, 错误: %w", item.FileID, err)
+		}
+	}
+	return nil
+}
+
+// SearchVectors 搜索相似向量
+func (s *EmbeddedStore) SearchVectors(queryVector []float32, limit int, userID uint, threshold float32) ([]VectorSearchResult, error) {
+	return s.SearchFiltered(queryVector, limit, &SearchFilter{UserID: userID}, threshold)
+}
+
+// SearchFiltered 带结构化过滤条件搜索相似的描述文本向量
+func (s *EmbeddedStore) SearchFiltered(queryVector []float32, limit int, filter *SearchFilter, threshold float32) ([]VectorSearchResult, error) {
+	return s.SearchFilteredUsing(VectorNameText, queryVector, limit, filter, threshold)
+}
+
+// SearchFilteredUsing 在指定命名向量上暴力检索余弦相似度不低于阈值的文件，按相似度降序返回
+func (s *EmbeddedStore) SearchFilteredUsing(using string, queryVector []float32, limit int, filter *SearchFilter, threshold float32) ([]VectorSearchResult, error) {
+	if len(queryVector) == 0 {
+		return nil, fmt.Errorf("查询向量为空")
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	query := normalizeVector(queryVector)
+
+	s.mutex.RLock()
+	results := make([]VectorSearchResult, 0)
+	for fileID, p := range s.points {
+		vec := p.vectors[using]
+		if len(vec) != len(query) || !filter.matches(&p.attrs) {
+			continue
+		}
+		score := dotProduct(query, vec)
+		if score < threshold {
+			continue
+		}
+		desc, _ := p.payload["description"].(string)
+		results = append(results, VectorSearchResult{
+			FileID:      fileID,
+			Similarity:  score,
+			Score:       score,
+			Description: desc,
+			Using:       using,
+		})
+	}
+	s.mutex.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Similarity != results[j].Similarity {
+			return results[i].Similarity > results[j].Similarity
+		}
+		return results[i].FileID < results[j].FileID
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// SearchSimilar 搜索相似向量
+func (s *EmbeddedStore) SearchSimilar(queryVector []float32, limit int, userID uint, threshold float32, model string) ([]VectorSearchResult, error) {
+	return s.SearchVectors(queryVector, limit, userID, threshold)
+}
+
+// SearchSimilarWithQuery 带查询的搜索相似向量
+func (s *EmbeddedStore) SearchSimilarWithQuery(queryVector []float32, limit int, userID uint, threshold float32, query string, model string) ([]VectorSearchResult, error) {
+	return s.SearchVectors(queryVector, limit, userID, threshold)
+}
+
+// load 以写锁完成首次加载
+func (s *EmbeddedStore) load() error {
+	s.mutex.RLock()
+	loaded := s.loaded
+	s.mutex.RUnlock()
+	if loaded {
+		return nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.ensureLoaded()
+}
+
+// FetchVectors 获取指定文件的全部命名向量（归一化后）及payload
+func (s *EmbeddedStore) FetchVectors(fileID string) (map[string][]float32, map[string]interface{}, error) {
+	if err := s.load(); err != nil {
+		return nil, nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	p := s.points[fileID]
+	if p == nil || len(p.vectors) == 0 {
+		return nil, nil, fmt.Errorf("向量不存在或为空")
+	}
+	vectors := make(map[string][]float32, len(p.vectors))
+	for name, vec := range p.vectors {
+		vectors[name] = append([]float32(nil), vec...)
+	}
+	payload := make(map[string]interface{}, len(p.payload))
+	for k, v := range p.payload {
+		payload[k] = v
+	}
+	return vectors, payload, nil
+}
+
+// UpdatePayload 覆盖写入指定文件的payload字段（不影响向量本身），文件无向量时忽略
+func (s *EmbeddedStore) UpdatePayload(fileID string, payload map[string]interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.ensureLoaded(); err != nil {
+		return err
+	}
+
+	p := s.points[fileID]
+	if p == nil {
+		return nil
+	}
+	raw, err := p.mergePayload(payload)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Model(&models.FileEmbedding{}).
+		Where("file_id = ?", fileID).
+		Updates(map[string]interface{}{"payload": raw, "user_id": p.attrs.UserID}).Error; err != nil {
+		return fmt.Errorf("更新payload失败: %v", err)
+	}
+	return nil
+}
+
+func (s *EmbeddedStore) DeleteVector(fileID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.db.Where("file_id = ?", fileID).Delete(&models.FileEmbedding{}).Error; err != nil {
+		return fmt.Errorf("删除向量失败: %v", err)
+	}
+	delete(s.points, fileID)
+	return nil
+}
+
+// VectorExists 检查文件的文本向量是否存在
+func (s *EmbeddedStore) VectorExists(fileID string) (bool, error) {
+	if err := s.load(); err != nil {
+		return false, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	p := s.points[fileID]
+	return p != nil && len(p.vectors[VectorNameText]) > 0, nil
+}
+
+func (s *EmbeddedStore) GetVector(fileID string) (*models.FileVector, error) {
+	var vec models.FileVector
+	if err := s.db.Where("file_id = ?", fileID).First(&vec).Error; err != nil {
+		return nil, fmt.Errorf("向量记录不存在: %v", err)
+	}
+	return &vec, nil
+}
+
+// GetVectorCount 统计用户的文本向量数量，userID 为 0 时统计全部
+func (s *EmbeddedStore) GetVectorCount(userID uint) (int64, error) {
+	query := s.db.Model(&models.FileEmbedding{}).Where("name = ?", VectorNameText)
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("统计向量数量失败: %v", err)
+	}
+	return count, nil
+}
+
+func (s *EmbeddedStore) GetStorageStats() (*VectorStorageStats, error) {
+	var stats struct {
+		Total int64
+		Texts int64
+		Bytes int64
+	}
+	if err := s.db.Model(&models.FileEmbedding{}).
+		Select("COUNT(DISTINCT file_id) AS total, SUM(CASE WHEN name = ? THEN 1 ELSE 0 END) AS texts, COALESCE(SUM(dimension), 0) * 4 AS bytes", VectorNameText).
+		Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("统计向量存储失败: %v", err)
+	}
+	return &VectorStorageStats{
+		TotalVectors:   stats.Total,
+		CompletedCount: stats.Texts,
+		LastUpdateTime: time.Now(),
+		StorageSize:    stats.Bytes,
+	}, nil
+}
+
+// GetAllFileIDs 返回已存储向量的文件ID，按ID排序；limit<=0 表示不限制
+func (s *EmbeddedStore) GetAllFileIDs(limit int) ([]string, error) {
+	query := s.db.Model(&models.FileEmbedding{}).Distinct("file_id").Order("file_id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var ids []string
+	if err := query.Pluck("file_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("查询向量文件ID失败: %v", err)
+	}
+	return ids, nil
+}
+
+func (s *EmbeddedStore) SupportsImageVectors() bool {
+	return true
+}
+
+// RecreateCollection 清空全部向量，调用方需随后重新生成
+func (s *EmbeddedStore) RecreateCollection() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.db.Where("1 = 1").Delete(&models.FileEmbedding{}).Error; err != nil {
+		return fmt.Errorf("清空向量失败: %v", err)
+	}
+	s.points = make(map[string]*embeddedPoint)
+	s.loaded = true
+	return nil
+}
+
+// HealthCheck 检查数据库连接
+func (s *EmbeddedStore) HealthCheck() error {
+	if s.db == nil {
+		return fmt.Errorf("数据库连接不可用")
+	}
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return fmt.Errorf("获取数据库连接失败: %v", err)
+	}
+	if err := sqlDB.Ping(); err != nil {
+		return fmt.Errorf("数据库连接不可用: %v", err)
+	}
+	return nil
+}
+
+func encodeVector(vec []float32) []byte {
+	buf := make([]byte, len(vec)*4)
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) []float32 {
+	vec := make([]float32, len(buf)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	return vec
+}
+
+// normalizeVector 归一化为单位向量，零向量原样返回
+func normalizeVector(vec []float32) []float32 {
+	var sum float64
+	for _, v := range vec {
+		sum += float64(v) * float64(v)
+	}
+	out := make([]float32, len(vec))
+	if sum == 0 {
+		copy(out, vec)
+		return out
+	}
+	norm := math.Sqrt(sum)
+	for i, v := range vec {
+		out[i] = float32(float64(v) / norm)
+	}
+	return out
+}
+
+func dotProduct(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
@@ -0,0 +1,94 @@
+package vector
+
+import (
+	"testing"
+
+	"pixelpunk/internal/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	_ "modernc.org/sqlite"
+)
+
+func newTestEmbeddedStore(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Dialector{DriverName: "sqlite", DSN: "file::memory:"}, &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&models.FileEmbedding{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestEmbeddedStoreSearch(t *testing.T) {
+	db := newTestEmbeddedStore(t)
+	store := NewEmbeddedStore(db)
+
+	vectors := map[string][]float32{
+		"a": {1, 0, 0},
+		"b": {0.8, 0.6, 0},
+		"c": {0, 1, 0},
+		"d": {2, 0.1, 0}, // 未归一化的向量按余弦相似度计算
+	}
+	owners := map[string]uint{"a": 1, "b": 1, "c": 1, "d": 2}
+	for id, vec := range vectors {
+		if err := store.StoreVector(id, vec, "desc "+id, "m"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.UpdatePayload(id, map[string]interface{}{"user_id": owners[id], "format": "jpg"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := store.SearchVectors([]float32{1, 0, 0}, 10, 1, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].FileID != "a" || results[1].FileID != "b" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if results[1].Similarity < 0.79 || results[1].Similarity > 0.81 || results[0].Description != "desc a" {
+		t.Fatalf("unexpected similarity or description %+v", results)
+	}
+
+	all, _ := store.SearchVectors([]float32{1, 0, 0}, 10, 0, 0.5)
+	if len(all) != 3 || all[0].FileID != "a" || all[1].FileID != "d" {
+		t.Fatalf("userID 0 should search all users, got %+v", all)
+	}
+	if limited, _ := store.SearchVectors([]float32{1, 0, 0}, 1, 0, 0); len(limited) != 1 {
+		t.Fatalf("limit not applied: %+v", limited)
+	}
+	if none, _ := store.SearchFiltered([]float32{1, 0, 0}, 10, &SearchFilter{Formats: []string{".png"}}, 0); len(none) != 0 {
+		t.Fatalf("format filter not applied: %+v", none)
+	}
+
+	if err := store.StoreImageVector("a", []float32{0, 0, 1}, "clip"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteVector("c"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新从数据库加载
+	reloaded := NewEmbeddedStore(db)
+	image, err := reloaded.SearchFilteredUsing(VectorNameImage, []float32{0, 0, 3}, 10, &SearchFilter{UserID: 1}, 0.9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(image) != 1 || image[0].FileID != "a" || image[0].Using != VectorNameImage {
+		t.Fatalf("unexpected image results %+v", image)
+	}
+	if exists, _ := reloaded.VectorExists("c"); exists {
+		t.Fatal("deleted vector should not be loaded")
+	}
+	if count, _ := reloaded.GetVectorCount(1); count != 2 {
+		t.Fatalf("count = %d, want 2", count)
+	}
+	stored, payload, err := reloaded.FetchVectors("a")
+	if err != nil || len(stored) != 2 || payload["image_model"] != "clip" || payload["model"] != "m" {
+		t.Fatalf("unexpected fetch %v %v %v", stored, payload, err)
+	}
+}
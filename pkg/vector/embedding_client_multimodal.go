@@ -0,0 +1,309 @@
+package vector
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/utils"
+)
+
+// 多模态向量接口格式
+const (
+	MultimodalFormatOpenAI = "openai" // POST {base}/embeddings，input 为 [{"image": dataURI}] 或 [{"text": ...}]（Jina CLIP 等兼容服务）
+	MultimodalFormatLocal  = "local"  // POST {base}，请求体为 {"image": base64} 或 {"text": ...}，响应 {"embedding": [...]}
+)
+
+// ImageEmbeddingProvider 图像向量化提供者接口（CLIP类模型，文本与图像共享同一向量空间）
+type ImageEmbeddingProvider interface {
+	EmbeddingProvider
+	GenerateImageEmbedding(imageData []byte) ([]float32, error)
+}
+
+// MultimodalEmbeddingConfig 多模态向量化配置
+type MultimodalEmbeddingConfig struct {
+	BaseURL   string
+	APIKey    string
+	Model     string
+	Format    string
+	Dimension int
+	Timeout   time.Duration
+}
+
+// MultimodalEmbeddingClient 通过HTTP调用多模态向量接口，直接对像素进行向量化
+type MultimodalEmbeddingClient struct {
+	config     MultimodalEmbeddingConfig
+	httpClient *http.Client
+}
+
+func NewMultimodalEmbeddingClient(config MultimodalEmbeddingConfig) *MultimodalEmbeddingClient {
+	if config.Format == "" {
+		config.Format = MultimodalFormatOpenAI
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.Dimension <= 0 {
+		config.Dimension = 1024
+	}
+	return &MultimodalEmbeddingClient{
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// GenerateImageEmbedding 生成图片向量
+func (c *MultimodalEmbeddingClient) GenerateImageEmbedding(imageData []byte) ([]float32, error) {
+	if len(imageData) == 0 {
+		return nil, fmt.Errorf("图片内容为空")
+	}
+	encoded := base64.StdEncoding.EncodeToString(imageData)
+	if c.config.Format == MultimodalFormatLocal {
+		return c.request(map[string]interface{}{"model": c.config.Model, "image": encoded})
+	}
+	dataURI := "data:" + http.DetectContentType(imageData) + ";base64," + encoded
+	return c.request(map[string]interface{}{
+		"model": c.config.Model,
+		"input": []map[string]string{{"image": dataURI}},
+	})
+}
+
+// GenerateEmbedding 将文本编码到图像向量空间，用于以文搜图
+func (c *MultimodalEmbeddingClient) GenerateEmbedding(text string) ([]float32, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("文本内容为空")
+	}
+	if c.config.Format == MultimodalFormatLocal {
+		return c.request(map[string]interface{}{"model": c.config.Model, "text": text})
+	}
+	return c.request(map[string]interface{}{
+		"model": c.config.Model,
+		"input": []map[string]string{{"text": text}},
+	})
+}
+
+// BatchGenerateEmbeddings 批量生成文本向量
+func (c *MultimodalEmbeddingClient) BatchGenerateEmbeddings(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		vec, err := c.GenerateEmbedding(text)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, vec)
+	}
+	return vectors, nil
+}
+
+func (c *MultimodalEmbeddingClient) GetDimension() int {
+	return c.config.Dimension
+}
+
+func (c *MultimodalEmbeddingClient) GetModel() string {
+	return c.config.Model
+}
+
+func (c *MultimodalEmbeddingClient) endpoint() string {
+	base := strings.TrimRight(c.config.BaseURL, "/")
+	if c.config.Format == MultimodalFormatLocal {
+		return base
+	}
+	return utils.NormalizeOpenAIBaseURL(base) + "/embeddings"
+}
+
+func (c *MultimodalEmbeddingClient) request(body map[string]interface{}) ([]float32, error) {
+	if c.config.BaseURL == "" {
+		return nil, fmt.Errorf("多模态向量接口地址未配置")
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("序列化向量化请求失败: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(), bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("创建向量化请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("多模态向量化请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return nil, fmt.Errorf("读取向量化响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("多模态向量化失败，状态码: %d, 响应: %s", resp.StatusCode, truncateBody(respBody))
+	}
+
+	vec, err := parseEmbeddingResponse(respBody)
+	if err != nil {
+		return nil, err
+	}
+	if c.config.Dimension > 0 && len(vec) != c.config.Dimension {
+		return nil, fmt.Errorf("向量维度不匹配，期望: %d, 实际: %d", c.config.Dimension, len(vec))
+	}
+	return vec, nil
+}
+
+// parseEmbeddingResponse 兼容 OpenAI 风格 {"data":[{"embedding":[...]}]} 与 {"embedding":[...]} / {"embeddings":[[...]]}
+func parseEmbeddingResponse(body []byte) ([]float32, error) {
+	var resp struct {
+		Data []struct {
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+		Embedding  []float32   `json:"embedding"`
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("解析向量化响应失败: %w", err)
+	}
+	switch {
+	case len(resp.Data) > 0 && len(resp.Data[0].Embedding) > 0:
+		return resp.Data[0].Embedding, nil
+	case len(resp.Embedding) > 0:
+		return resp.Embedding, nil
+	case len(resp.Embeddings) > 0 && len(resp.Embeddings[0]) > 0:
+		return resp.Embeddings[0], nil
+	}
+	return nil, fmt.Errorf("向量化响应中没有向量数据")
+}
+
+func truncateBody(body []byte) string {
+	if len(body) > 512 {
+		return string(body[:512])
+	}
+	return string(body)
+}
+
+// DynamicMultimodalClient 动态多模态客户端，每次调用时读取最新配置
+type DynamicMultimodalClient struct{}
+
+func NewDynamicMultimodalClient() *DynamicMultimodalClient {
+	return &DynamicMultimodalClient{}
+}
+
+// loadMultimodalConfig 直接从数据库读取多模态向量配置（绕过缓存，与 DynamicOpenAIClient 一致）
+func loadMultimodalConfig() (enabled bool, config MultimodalEmbeddingConfig, err error) {
+	db := database.GetDB()
+	if db == nil {
+		return false, config, fmt.Errorf("数据库连接不可用")
+	}
+
+	var settings []struct {
+		Key   string
+		Value string
+	}
+	if err := db.Table("setting").
+		Where("`group` = ?", "vector").
+		Where("`key` IN (?)", []string{
+			"image_embedding_enabled", "image_embedding_base_url", "image_embedding_api_key",
+			"image_embedding_model", "image_embedding_api_format", "image_embedding_dimension", "vector_timeout",
+		}).
+		Select("`key`, `value`").
+		Find(&settings).Error; err != nil {
+		return false, config, fmt.Errorf("查询多模态向量配置失败: %v", err)
+	}
+
+	config = MultimodalEmbeddingConfig{Format: MultimodalFormatOpenAI, Dimension: 1024, Timeout: 30 * time.Second}
+	for _, s := range settings {
+		switch s.Key {
+		case "image_embedding_enabled":
+			_ = json.Unmarshal([]byte(s.Value), &enabled)
+		case "image_embedding_base_url":
+			_ = json.Unmarshal([]byte(s.Value), &config.BaseURL)
+		case "image_embedding_api_key":
+			_ = json.Unmarshal([]byte(s.Value), &config.APIKey)
+		case "image_embedding_model":
+			_ = json.Unmarshal([]byte(s.Value), &config.Model)
+		case "image_embedding_api_format":
+			var format string
+			if err := json.Unmarshal([]byte(s.Value), &format); err == nil && format != "" {
+				config.Format = format
+			}
+		case "image_embedding_dimension":
+			var dim float64
+			if err := json.Unmarshal([]byte(s.Value), &dim); err == nil && dim > 0 {
+				config.Dimension = int(dim)
+			}
+		case "vector_timeout":
+			var seconds float64
+			if err := json.Unmarshal([]byte(s.Value), &seconds); err == nil && seconds > 0 {
+				config.Timeout = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return enabled && config.BaseURL != "", config, nil
+}
+
+func (c *DynamicMultimodalClient) client() (*MultimodalEmbeddingClient, error) {
+	enabled, config, err := loadMultimodalConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, fmt.Errorf("图像向量化未启用")
+	}
+	return NewMultimodalEmbeddingClient(config), nil
+}
+
+// Enabled 是否已启用并配置图像向量化
+func (c *DynamicMultimodalClient) Enabled() bool {
+	enabled, _, err := loadMultimodalConfig()
+	return err == nil && enabled
+}
+
+func (c *DynamicMultimodalClient) GenerateImageEmbedding(imageData []byte) ([]float32, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+	return client.GenerateImageEmbedding(imageData)
+}
+
+func (c *DynamicMultimodalClient) GenerateEmbedding(text string) ([]float32, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+	return client.GenerateEmbedding(text)
+}
+
+func (c *DynamicMultimodalClient) BatchGenerateEmbeddings(texts []string) ([][]float32, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+	return client.BatchGenerateEmbeddings(texts)
+}
+
+func (c *DynamicMultimodalClient) GetDimension() int {
+	_, config, _ := loadMultimodalConfig()
+	if config.Dimension <= 0 {
+		return 1024
+	}
+	return config.Dimension
+}
+
+func (c *DynamicMultimodalClient) GetModel() string {
+	_, config, _ := loadMultimodalConfig()
+	return config.Model
+}
@@ -0,0 +1,95 @@
+package vector
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMultimodalEmbeddingClient(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+
+	var lastPath, lastAuth string
+	var lastBody map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastPath, lastAuth = r.URL.Path, r.Header.Get("Authorization")
+		lastBody = nil
+		_ = json.NewDecoder(r.Body).Decode(&lastBody)
+		if strings.HasPrefix(r.URL.Path, "/v1/") {
+			w.Write([]byte(`{"data":[{"embedding":[0.1,0.2,0.3]}]}`))
+			return
+		}
+		w.Write([]byte(`{"embedding":[0.4,0.5,0.6]}`))
+	}))
+	defer srv.Close()
+
+	openai := NewMultimodalEmbeddingClient(MultimodalEmbeddingConfig{BaseURL: srv.URL, APIKey: "k", Model: "clip", Dimension: 3})
+	vec, err := openai.GenerateImageEmbedding(png)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vec) != 3 || vec[0] != 0.1 {
+		t.Fatalf("unexpected vector %v", vec)
+	}
+	if lastPath != "/v1/embeddings" || lastAuth != "Bearer k" {
+		t.Fatalf("unexpected request %s auth=%q", lastPath, lastAuth)
+	}
+	input := lastBody["input"].([]interface{})[0].(map[string]interface{})
+	if !strings.HasPrefix(input["image"].(string), "data:image/png;base64,") {
+		t.Fatalf("image input should be a data URI, got %v", input["image"])
+	}
+
+	if _, err := openai.GenerateEmbedding("a red bicycle"); err != nil {
+		t.Fatal(err)
+	}
+	input = lastBody["input"].([]interface{})[0].(map[string]interface{})
+	if input["text"] != "a red bicycle" {
+		t.Fatalf("unexpected text input %v", input)
+	}
+
+	local := NewMultimodalEmbeddingClient(MultimodalEmbeddingConfig{BaseURL: srv.URL + "/embed", Format: MultimodalFormatLocal, Dimension: 3})
+	vec, err = local.GenerateImageEmbedding(png)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vec[0] != 0.4 || lastPath != "/embed" || lastBody["image"] == nil {
+		t.Fatalf("unexpected local request %s %v -> %v", lastPath, lastBody, vec)
+	}
+
+	wrongDim := NewMultimodalEmbeddingClient(MultimodalEmbeddingConfig{BaseURL: srv.URL, Dimension: 512})
+	if _, err := wrongDim.GenerateImageEmbedding(png); err == nil {
+		t.Fatal("expected dimension mismatch error")
+	}
+}
+
+func TestMergeVectorResults(t *testing.T) {
+	text := []VectorSearchResult{
+		{FileID: "a", Similarity: 0.8, Using: VectorNameText, Description: "desc a"},
+		{FileID: "b", Similarity: 0.5, Using: VectorNameText},
+	}
+	image := []VectorSearchResult{
+		{FileID: "b", Similarity: 0.3, Using: VectorNameImage},
+		{FileID: "c", Similarity: 0.6, Using: VectorNameImage},
+	}
+
+	merged := MergeVectorResults(10, text, image)
+	want := []struct {
+		id    string
+		using string
+		sim   float32
+	}{{"a", VectorNameText, 0.8}, {"c", VectorNameImage, 0.6}, {"b", QueryTargetBoth, 0.5}}
+	if len(merged) != len(want) {
+		t.Fatalf("got %d results, want %d", len(merged), len(want))
+	}
+	for i, w := range want {
+		if merged[i].FileID != w.id || merged[i].Using != w.using || merged[i].Similarity != w.sim {
+			t.Errorf("result %d = %+v, want %+v", i, merged[i], w)
+		}
+	}
+
+	if got := MergeVectorResults(2, text, image); len(got) != 2 {
+		t.Fatalf("limit not applied: %d", len(got))
+	}
+}
@@ -15,23 +15,37 @@ import (
 	"pixelpunk/pkg/logger"
 )
 
+// 命名向量：同一个点同时保存描述文本向量与图像向量
+const (
+	VectorNameText  = "text"
+	VectorNameImage = "image"
+)
+
 // QdrantClient 直接连接Qdrant的简单客户端
 type QdrantClient struct {
 	baseURL    string
 	httpClient *http.Client
 	collection string
+	// namedVectors 集合是否使用命名向量；旧版集合只有一个匿名文本向量
+	namedVectors bool
 }
 
-// QdrantPoint Qdrant点结构
+// QdrantPoint Qdrant点结构，Vector 为 []float32（匿名向量）或 map[string][]float32（命名向量）
 type QdrantPoint struct {
 	Id      string                 `json:"id"`
-	Vector  []float32              `json:"vector"`
+	Vector  interface{}            `json:"vector"`
 	Payload map[string]interface{} `json:"payload"`
 }
 
-// QdrantSearchRequest 搜索请求
+// QdrantNamedVector 按名称查询命名向量
+type QdrantNamedVector struct {
+	Name   string    `json:"name"`
+	Vector []float32 `json:"vector"`
+}
+
+// QdrantSearchRequest 搜索请求，Vector 为 []float32 或 QdrantNamedVector
 type QdrantSearchRequest struct {
-	Vector         []float32              `json:"vector"`
+	Vector         interface{}            `json:"vector"`
 	Filter         map[string]interface{} `json:"filter,omitempty"`
 	Limit          int                    `json:"limit"`
 	WithPayload    bool                   `json:"with_payload"`
@@ -52,7 +66,6 @@ type QdrantSearchResult struct {
 	Version int                    `json:"version"`
 	Score   float32                `json:"score"`
 	Payload map[string]interface{} `json:"payload"`
-	Vector  []float32              `json:"vector,omitempty"`
 }
 
 func NewQdrantClient(qdrantURL string, timeout int) *QdrantClient {
@@ -89,21 +102,35 @@ func (q *QdrantClient) collectionExists() bool {
 	return resp.StatusCode == 200
 }
 
-// InitCollection 初始化向量集合
+// InitCollection 初始化向量集合，新建的集合使用 text/image 命名向量
 func (q *QdrantClient) InitCollection() error {
 	resp, err := q.httpClient.Get(fmt.Sprintf("%s/collections/%s", q.baseURL, q.collection))
 	if err == nil && resp != nil && resp.StatusCode == 200 {
+		q.detectVectorLayout(resp.Body)
 		resp.Body.Close()
-		return nil
+		return q.ensurePayloadIndexes()
 	}
 	if resp != nil {
 		resp.Body.Close()
 	}
 
+	if err := q.createCollection(); err != nil {
+		return err
+	}
+	return q.ensurePayloadIndexes()
+}
+
+func (q *QdrantClient) createCollection() error {
 	createReq := map[string]interface{}{
 		"vectors": map[string]interface{}{
-			"size":     1536, // text-embedding-3-small 向量维度
-			"distance": "Cosine",
+			VectorNameText: map[string]interface{}{
+				"size":     1536, // text-embedding-3-small 向量维度
+				"distance": "Cosine",
+			},
+			VectorNameImage: map[string]interface{}{
+				"size":     NewDynamicMultimodalClient().GetDimension(),
+				"distance": "Cosine",
+			},
 		},
 	}
 
@@ -120,7 +147,7 @@ func (q *QdrantClient) InitCollection() error {
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err = q.httpClient.Do(req)
+	resp, err := q.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("创建集合请求失败: %w", err)
 	}
@@ -131,6 +158,88 @@ func (q *QdrantClient) InitCollection() error {
 		return fmt.Errorf("创建集合失败，状态码: %d, 响应: %s", resp.StatusCode, string(body))
 	}
 
+	q.namedVectors = true
+	return nil
+}
+
+// detectVectorLayout 根据集合配置判断是否为命名向量集合
+func (q *QdrantClient) detectVectorLayout(body io.Reader) {
+	var info struct {
+		Result struct {
+			Config struct {
+				Params struct {
+					Vectors map[string]json.RawMessage `json:"vectors"`
+				} `json:"params"`
+			} `json:"config"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(body).Decode(&info); err != nil {
+		return
+	}
+	vectors := info.Result.Config.Params.Vectors
+	_, anonymous := vectors["size"]
+	q.namedVectors = len(vectors) > 0 && !anonymous
+	if !q.namedVectors {
+		logger.Warn("Qdrant集合 %s 为旧版单向量结构，图像向量不可用；重新生成向量并重建集合后启用", q.collection)
+	}
+}
+
+// SupportsImageVectors 集合是否包含图像命名向量
+func (q *QdrantClient) SupportsImageVectors() bool {
+	return q.namedVectors
+}
+
+// RecreateCollection 删除并按当前结构重建集合，所有向量需要重新生成
+func (q *QdrantClient) RecreateCollection() error {
+	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/collections/%s", q.baseURL, q.collection), nil)
+	if err != nil {
+		return fmt.Errorf("创建DELETE请求失败: %w", err)
+	}
+	resp, err := q.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("删除集合请求失败: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 && resp.StatusCode != 404 {
+		return fmt.Errorf("删除集合失败，状态码: %d", resp.StatusCode)
+	}
+
+	if err := q.createCollection(); err != nil {
+		return err
+	}
+	return q.ensurePayloadIndexes()
+}
+
+// ensurePayloadIndexes 为结构化过滤字段建立payload索引，已存在的索引Qdrant会直接返回成功
+func (q *QdrantClient) ensurePayloadIndexes() error {
+	for field, schema := range PayloadIndexes {
+		reqBody, err := json.Marshal(map[string]interface{}{
+			"field_name":   field,
+			"field_schema": schema,
+		})
+		if err != nil {
+			return fmt.Errorf("序列化索引请求失败: %w", err)
+		}
+
+		req, err := http.NewRequest("PUT",
+			fmt.Sprintf("%s/collections/%s/index?wait=true", q.baseURL, q.collection),
+			bytes.NewBuffer(reqBody))
+		if err != nil {
+			return fmt.Errorf("创建PUT请求失败: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := q.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("创建payload索引请求失败: %w", err)
+		}
+		if resp.StatusCode != 200 {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return fmt.Errorf("创建payload索引 %s 失败，状态码: %d, 响应: %s", field, resp.StatusCode, string(body))
+		}
+		resp.Body.Close()
+	}
 	return nil
 }
 
@@ -140,37 +249,76 @@ func (q *QdrantClient) ProcessFile(fileID, description string, userID uint) erro
 	return fmt.Errorf("向量化功能需要集成OpenAI客户端")
 }
 
-// StoreVector 存储向量
+// StoreVector 存储描述文本向量
 func (q *QdrantClient) StoreVector(fileID string, vector []float32, description string, model string) error {
 	if !q.collectionExists() {
 		return fmt.Errorf("collection '%s' doesn't exist", q.collection)
 	}
 
+	payload := BuildFilePayload(database.GetDB(), fileID) // 包含原始文件ID与可过滤属性
+	payload["description"] = description
+	payload["model"] = model
+
+	if !q.namedVectors {
+		return q.upsertPoint(QdrantPoint{Id: q.generateQdrantID(fileID), Vector: vector, Payload: payload})
+	}
+	return q.storeNamedVector(fileID, VectorNameText, vector, payload)
+}
+
+// StoreImageVector 存储图像向量，保留同一点上的文本向量
+func (q *QdrantClient) StoreImageVector(fileID string, vector []float32, model string) error {
+	if !q.namedVectors {
+		return fmt.Errorf("当前集合不支持图像向量")
+	}
+	return q.storeNamedVector(fileID, VectorNameImage, vector, map[string]interface{}{"image_model": model})
+}
+
+// storeNamedVector 只更新点上的指定命名向量与payload字段，点不存在时新建
+func (q *QdrantClient) storeNamedVector(fileID, name string, vector []float32, payload map[string]interface{}) error {
 	qdrantID := q.generateQdrantID(fileID)
-	var userID uint
-	if db := database.GetDB(); db != nil {
-		var file models.File
-		if err := db.Where("id = ?", fileID).First(&file).Error; err == nil {
-			userID = file.UserID
-		}
+	data, err := json.Marshal(map[string]interface{}{
+		"points": []map[string]interface{}{{
+			"id":     qdrantID,
+			"vector": map[string][]float32{name: vector},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("序列化存储请求失败: %w", err)
 	}
 
-	point := QdrantPoint{
-		Id:     qdrantID,
-		Vector: vector,
-		Payload: map[string]interface{}{
-			"file_id":     fileID, // 保存原始文件ID
-			"description": description,
-			"model":       model,
-			"user_id":     userID,
-		},
+	req, err := http.NewRequest("PUT",
+		fmt.Sprintf("%s/collections/%s/points/vectors?wait=true", q.baseURL, q.collection),
+		bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("创建PUT请求失败: %w", err)
 	}
+	req.Header.Set("Content-Type", "application/json")
 
-	reqBody := map[string]interface{}{
-		"points": []QdrantPoint{point},
+	resp, err := q.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("存储向量请求失败: %w", err)
 	}
+	defer resp.Body.Close()
 
-	data, err := json.Marshal(reqBody)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return q.UpdatePayload(fileID, payload)
+	case http.StatusNotFound:
+		full := BuildFilePayload(database.GetDB(), fileID)
+		for k, v := range payload {
+			full[k] = v
+		}
+		return q.upsertPoint(QdrantPoint{Id: qdrantID, Vector: map[string][]float32{name: vector}, Payload: full})
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("存储向量失败，状态码: %d, 响应: %s", resp.StatusCode, string(body))
+	}
+}
+
+func (q *QdrantClient) upsertPoint(point QdrantPoint) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"points": []QdrantPoint{point},
+	})
 	if err != nil {
 		return fmt.Errorf("序列化存储请求失败: %w", err)
 	}
@@ -199,25 +347,32 @@ func (q *QdrantClient) StoreVector(fileID string, vector []float32, description
 
 // SearchVectors 搜索相似向量
 func (q *QdrantClient) SearchVectors(queryVector []float32, limit int, userID uint, threshold float32) ([]VectorSearchResult, error) {
+	return q.SearchFiltered(queryVector, limit, &SearchFilter{UserID: userID}, threshold)
+}
+
+// SearchFiltered 带结构化过滤条件搜索相似的描述文本向量
+func (q *QdrantClient) SearchFiltered(queryVector []float32, limit int, filter *SearchFilter, threshold float32) ([]VectorSearchResult, error) {
+	return q.SearchFilteredUsing(VectorNameText, queryVector, limit, filter, threshold)
+}
+
+// SearchFilteredUsing 在指定的命名向量上搜索，旧版集合只支持文本向量
+func (q *QdrantClient) SearchFilteredUsing(using string, queryVector []float32, limit int, filter *SearchFilter, threshold float32) ([]VectorSearchResult, error) {
+	var vector interface{} = queryVector
+	if q.namedVectors {
+		vector = QdrantNamedVector{Name: using, Vector: queryVector}
+	} else if using != VectorNameText {
+		return nil, fmt.Errorf("当前集合不支持图像向量")
+	}
+
 	searchReq := QdrantSearchRequest{
-		Vector:         queryVector,
+		Vector:         vector,
+		Filter:         filter.QdrantFilter(),
 		Limit:          limit,
 		WithPayload:    true,
 		WithVector:     false,
 		ScoreThreshold: &threshold,
 	}
 
-	if userID > 0 {
-		searchReq.Filter = map[string]interface{}{
-			"must": []map[string]interface{}{
-				{
-					"key":   "user_id",
-					"match": map[string]interface{}{"value": userID},
-				},
-			},
-		}
-	}
-
 	reqData, err := json.Marshal(searchReq)
 	if err != nil {
 		return nil, fmt.Errorf("序列化搜索请求失败: %w", err)
@@ -248,6 +403,7 @@ func (q *QdrantClient) SearchVectors(queryVector []float32, limit int, userID ui
 		result := VectorSearchResult{
 			Score:      item.Score,
 			Similarity: item.Score, // 相似度就是Qdrant返回的Score
+			Using:      using,
 		}
 
 		// 从 payload 中提取原始文件ID
@@ -518,8 +674,20 @@ func (q *QdrantClient) GetVector(fileID string) (*models.FileVector, error) {
 	return nil, fmt.Errorf("GetVector not implemented for direct Qdrant connection")
 }
 
-// FetchVectorWithPayload 获取指定 fileID 的向量及payload（description/model等）
+// FetchVectorWithPayload 获取指定 fileID 的文本向量及payload（description/model等）
 func (q *QdrantClient) FetchVectorWithPayload(fileID string) ([]float32, map[string]interface{}, error) {
+	vectors, payload, err := q.FetchVectors(fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(vectors[VectorNameText]) == 0 {
+		return nil, nil, fmt.Errorf("向量不存在或为空")
+	}
+	return vectors[VectorNameText], payload, nil
+}
+
+// FetchVectors 获取指定 fileID 的全部向量（按名称）及payload，旧版集合的匿名向量视为文本向量
+func (q *QdrantClient) FetchVectors(fileID string) (map[string][]float32, map[string]interface{}, error) {
 	qdrantID := q.generateQdrantID(fileID)
 	// 带上 with_vector 与 with_payload，确保返回完整信息
 	url := fmt.Sprintf("%s/collections/%s/points/%s?with_vector=true&with_payload=true", q.baseURL, q.collection, qdrantID)
@@ -538,7 +706,7 @@ func (q *QdrantClient) FetchVectorWithPayload(fileID string) ([]float32, map[str
 	var pointResp struct {
 		Result struct {
 			ID      interface{}            `json:"id"`
-			Vector  []float32              `json:"vector"`
+			Vector  json.RawMessage        `json:"vector"`
 			Payload map[string]interface{} `json:"payload"`
 		} `json:"result"`
 		Status string `json:"status"`
@@ -546,10 +714,56 @@ func (q *QdrantClient) FetchVectorWithPayload(fileID string) ([]float32, map[str
 	if err := json.NewDecoder(resp.Body).Decode(&pointResp); err != nil {
 		return nil, nil, fmt.Errorf("解析向量响应失败: %w", err)
 	}
-	if pointResp.Status != "ok" || len(pointResp.Result.Vector) == 0 {
+	if pointResp.Status != "ok" {
 		return nil, nil, fmt.Errorf("向量不存在或为空")
 	}
-	return pointResp.Result.Vector, pointResp.Result.Payload, nil
+
+	vectors := map[string][]float32{}
+	var anonymous []float32
+	if err := json.Unmarshal(pointResp.Result.Vector, &anonymous); err == nil {
+		vectors[VectorNameText] = anonymous
+	} else if err := json.Unmarshal(pointResp.Result.Vector, &vectors); err != nil {
+		return nil, nil, fmt.Errorf("解析向量响应失败: %w", err)
+	}
+	for name, vec := range vectors {
+		if len(vec) == 0 {
+			delete(vectors, name)
+		}
+	}
+	if len(vectors) == 0 {
+		return nil, nil, fmt.Errorf("向量不存在或为空")
+	}
+	return vectors, pointResp.Result.Payload, nil
+}
+
+// UpdatePayload 覆盖写入指定文件向量点的payload字段（不影响向量本身）
+func (q *QdrantClient) UpdatePayload(fileID string, payload map[string]interface{}) error {
+	reqBody := map[string]interface{}{
+		"payload": payload,
+		"points":  []string{q.generateQdrantID(fileID)},
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("序列化请求失败: %w", err)
+	}
+
+	resp, err := q.httpClient.Post(
+		fmt.Sprintf("%s/collections/%s/points/payload", q.baseURL, q.collection),
+		"application/json",
+		bytes.NewBuffer(jsonData),
+	)
+	if err != nil {
+		return fmt.Errorf("更新payload请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("更新payload失败，状态码: %d, 响应: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
 }
 
 func (q *QdrantClient) DeleteVector(fileID string) error {
@@ -586,44 +800,22 @@ func (q *QdrantClient) DeleteVector(fileID string) error {
 	return nil
 }
 
-// SearchSimilarByID 通过文件ID搜索相似向量
+// SearchSimilarByID 通过文件ID搜索相似向量（使用描述文本向量）
 func (q *QdrantClient) SearchSimilarByID(fileID string, limit int, userID uint, threshold float32, model string) ([]VectorSearchResult, error) {
-	// 先从Qdrant获取基准向量
-	pointID := q.generateQdrantID(fileID)
-
-	// 构建获取向量的URL，指定需要返回向量数据
-	url := fmt.Sprintf("%s/collections/%s/points/%s?with_vector=true", q.baseURL, q.collection, pointID)
+	return q.SearchSimilarByIDUsing(VectorNameText, fileID, limit, &SearchFilter{UserID: userID}, threshold)
+}
 
-	resp, err := q.httpClient.Get(url)
+// SearchSimilarByIDUsing 以文件自身的指定命名向量为基准搜索相似文件
+func (q *QdrantClient) SearchSimilarByIDUsing(using, fileID string, limit int, filter *SearchFilter, threshold float32) ([]VectorSearchResult, error) {
+	vectors, _, err := q.FetchVectors(fileID)
 	if err != nil {
 		return nil, fmt.Errorf("获取基准向量失败: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("获取基准向量失败，状态码: %d, 响应: %s", resp.StatusCode, string(body))
-	}
-
-	var pointResp struct {
-		Result struct {
-			ID      string                 `json:"id"`
-			Vector  []float32              `json:"vector"`
-			Payload map[string]interface{} `json:"payload"`
-		} `json:"result"`
-		Status string `json:"status"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&pointResp); err != nil {
-		return nil, fmt.Errorf("解析基准向量响应失败: %w", err)
-	}
-
-	if pointResp.Status != "ok" || len(pointResp.Result.Vector) == 0 {
+	base, ok := vectors[using]
+	if !ok {
 		return nil, fmt.Errorf("基准向量不存在或为空")
 	}
-
-	// 使用获取到的向量进行相似度搜索
-	return q.SearchVectors(pointResp.Result.Vector, limit, userID, threshold)
+	return q.SearchFilteredUsing(using, base, limit, filter, threshold)
 }
 
 // IsEnabled 检查是否启用
@@ -0,0 +1,203 @@
+package vector
+
+import (
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// PayloadIndexes 需要在集合上建立索引的payload字段及其类型，用于结构化过滤
+var PayloadIndexes = map[string]string{
+	"user_id":      "integer",
+	"folder_id":    "keyword",
+	"tag_ids":      "integer",
+	"category_id":  "integer",
+	"format":       "keyword",
+	"access_level": "keyword",
+	"nsfw":         "bool",
+	"taken_at":     "integer",
+}
+
+// SearchFilter 向量搜索的结构化过滤条件，下推为Qdrant payload过滤
+type SearchFilter struct {
+	UserID       uint
+	FolderIDs    []string // 文件夹及其子文件夹
+	TagIDs       []uint   // 需全部命中
+	CategoryIDs  []uint
+	Formats      []string
+	AccessLevels []string
+	NSFW         *bool
+	TakenFrom    *time.Time // 拍摄时间（含）
+	TakenTo      *time.Time // 拍摄时间（不含）
+}
+
+// QdrantFilter 转换为Qdrant过滤条件，无条件时返回nil
+func (f *SearchFilter) QdrantFilter() map[string]interface{} {
+	if f == nil {
+		return nil
+	}
+
+	var must []map[string]interface{}
+	match := func(key string, value interface{}) {
+		must = append(must, map[string]interface{}{"key": key, "match": map[string]interface{}{"value": value}})
+	}
+	matchAny := func(key string, values interface{}) {
+		must = append(must, map[string]interface{}{"key": key, "match": map[string]interface{}{"any": values}})
+	}
+
+	if f.UserID > 0 {
+		match("user_id", f.UserID)
+	}
+	if len(f.FolderIDs) > 0 {
+		matchAny("folder_id", f.FolderIDs)
+	}
+	for _, id := range f.TagIDs {
+		match("tag_ids", id)
+	}
+	if len(f.CategoryIDs) > 0 {
+		matchAny("category_id", f.CategoryIDs)
+	}
+	if len(f.Formats) > 0 {
+		formats := make([]string, len(f.Formats))
+		for i, v := range f.Formats {
+			formats[i] = strings.ToLower(strings.TrimPrefix(v, "."))
+		}
+		matchAny("format", formats)
+	}
+	if len(f.AccessLevels) > 0 {
+		matchAny("access_level", f.AccessLevels)
+	}
+	if f.NSFW != nil {
+		match("nsfw", *f.NSFW)
+	}
+	if f.TakenFrom != nil || f.TakenTo != nil {
+		rng := map[string]interface{}{}
+		if f.TakenFrom != nil {
+			rng["gte"] = f.TakenFrom.Unix()
+		}
+		if f.TakenTo != nil {
+			rng["lt"] = f.TakenTo.Unix()
+		}
+		must = append(must, map[string]interface{}{"key": "taken_at", "range": rng})
+	}
+
+	if len(must) == 0 {
+		return nil
+	}
+	return map[string]interface{}{"must": must}
+}
+
+// payloadAttrs 向量payload中参与过滤的字段，供内置向量存储在内存中过滤
+type payloadAttrs struct {
+	UserID      uint   `json:"user_id"`
+	FolderID    string `json:"folder_id"`
+	TagIDs      []uint `json:"tag_ids"`
+	CategoryID  *uint  `json:"category_id"`
+	Format      string `json:"format"`
+	AccessLevel string `json:"access_level"`
+	NSFW        *bool  `json:"nsfw"`
+	TakenAt     *int64 `json:"taken_at"`
+}
+
+// matches 判断payload是否满足过滤条件，语义与 QdrantFilter 一致：字段缺失时对应条件不成立
+func (f *SearchFilter) matches(a *payloadAttrs) bool {
+	if f == nil {
+		return true
+	}
+	if f.UserID > 0 && a.UserID != f.UserID {
+		return false
+	}
+	if len(f.FolderIDs) > 0 && !containsString(f.FolderIDs, a.FolderID) {
+		return false
+	}
+	for _, id := range f.TagIDs {
+		if !containsUint(a.TagIDs, id) {
+			return false
+		}
+	}
+	if len(f.CategoryIDs) > 0 && (a.CategoryID == nil || !containsUint(f.CategoryIDs, *a.CategoryID)) {
+		return false
+	}
+	if len(f.Formats) > 0 {
+		matched := false
+		for _, v := range f.Formats {
+			if strings.ToLower(strings.TrimPrefix(v, ".")) == a.Format {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(f.AccessLevels) > 0 && !containsString(f.AccessLevels, a.AccessLevel) {
+		return false
+	}
+	if f.NSFW != nil && (a.NSFW == nil || *a.NSFW != *f.NSFW) {
+		return false
+	}
+	if f.TakenFrom != nil || f.TakenTo != nil {
+		if a.TakenAt == nil {
+			return false
+		}
+		if f.TakenFrom != nil && *a.TakenAt < f.TakenFrom.Unix() {
+			return false
+		}
+		if f.TakenTo != nil && *a.TakenAt >= f.TakenTo.Unix() {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsUint(values []uint, v uint) bool {
+	for _, n := range values {
+		if n == v {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildFilePayload 从数据库汇总文件的可过滤属性，写入向量payload
+func BuildFilePayload(db *gorm.DB, fileID string) map[string]interface{} {
+	payload := map[string]interface{}{"file_id": fileID}
+	if db == nil {
+		return payload
+	}
+
+	var file models.File
+	if err := db.Where("id = ?", fileID).Take(&file).Error; err != nil {
+		return payload
+	}
+	payload["user_id"] = file.UserID
+	payload["folder_id"] = file.FolderID
+	payload["format"] = strings.ToLower(file.Format)
+	payload["access_level"] = file.AccessLevel
+	payload["nsfw"] = file.NSFW
+	if file.CategoryID != nil {
+		payload["category_id"] = *file.CategoryID
+	}
+
+	tagIDs := []uint{}
+	db.Model(&models.FileGlobalTagRelation{}).Where("file_id = ?", fileID).Pluck("tag_id", &tagIDs)
+	payload["tag_ids"] = tagIDs
+
+	var exif models.FileEXIF
+	if err := db.Select("date_time_original").Where("file_id = ?", fileID).Take(&exif).Error; err == nil && exif.DateTimeOriginal != nil {
+		payload["taken_at"] = exif.DateTimeOriginal.Unix()
+	}
+	return payload
+}
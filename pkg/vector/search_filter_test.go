@@ -0,0 +1,39 @@
+package vector
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSearchFilterQdrantFilter(t *testing.T) {
+	var empty *SearchFilter
+	if empty.QdrantFilter() != nil {
+		t.Fatal("空过滤条件应返回nil")
+	}
+	if (&SearchFilter{}).QdrantFilter() != nil {
+		t.Fatal("无条件时应返回nil")
+	}
+
+	nsfw := false
+	from := time.Unix(1672531200, 0)
+	filter := &SearchFilter{
+		UserID:    7,
+		FolderIDs: []string{"a", "b"},
+		TagIDs:    []uint{1, 2},
+		Formats:   []string{".JPG"},
+		NSFW:      &nsfw,
+		TakenFrom: &from,
+	}
+	data, err := json.Marshal(filter.QdrantFilter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"must":[{"key":"user_id","match":{"value":7}},{"key":"folder_id","match":{"any":["a","b"]}},` +
+		`{"key":"tag_ids","match":{"value":1}},{"key":"tag_ids","match":{"value":2}},` +
+		`{"key":"format","match":{"any":["jpg"]}},{"key":"nsfw","match":{"value":false}},` +
+		`{"key":"taken_at","range":{"gte":1672531200}}]}`
+	if string(data) != want {
+		t.Fatalf("过滤条件不符\n got: %s\nwant: %s", data, want)
+	}
+}
@@ -16,6 +16,17 @@ type VectorStorage interface {
 	GetVectorCount(userID uint) (int64, error)
 	GetStorageStats() (*VectorStorageStats, error)
 	VectorExists(fileID string) (bool, error) // 新增：检查向量是否存在
+	SearchFiltered(queryVector []float32, limit int, filter *SearchFilter, threshold float32) ([]VectorSearchResult, error)
+	UpdatePayload(fileID string, payload map[string]interface{}) error
+	// 命名向量：text 为描述文本向量，image 为图像向量
+	StoreImageVector(fileID string, vector []float32, model string) error
+	SearchFilteredUsing(using string, queryVector []float32, limit int, filter *SearchFilter, threshold float32) ([]VectorSearchResult, error)
+	SupportsImageVectors() bool
+	// FetchVectors 获取文件的全部命名向量及payload
+	FetchVectors(fileID string) (map[string][]float32, map[string]interface{}, error)
+	GetAllFileIDs(limit int) ([]string, error)
+	RecreateCollection() error
+	HealthCheck() error
 }
 
 // VectorItem 批量向量处理项
@@ -31,7 +42,8 @@ type VectorSearchResult struct {
 	FileID      string  `json:"file_id"`
 	Similarity  float32 `json:"similarity"`
 	Description string  `json:"description"`
-	Score       float32 `json:"score"` // 归一化分数 (0-100)
+	Score       float32 `json:"score"`           // 归一化分数 (0-100)
+	Using       string  `json:"using,omitempty"` // 命中的命名向量：text / image / both
 }
 
 // VectorStorageStats 向量存储统计信息
@@ -7,6 +7,7 @@ import (
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/logger"
+	"sort"
 	"sync"
 
 	"gorm.io/gorm"
@@ -119,13 +120,39 @@ func getVectorConfigFromDB() (*VectorConfig, error) {
 	return config, nil
 }
 
+// 查询目标：描述文本向量、图像向量或两者融合
+const (
+	QueryTargetText  = "text"
+	QueryTargetImage = "image"
+	QueryTargetBoth  = "both"
+)
+
+// ImageLoader 读取文件的图片内容（优先缩略图），由文件服务注册，避免向量包依赖存储层
+type ImageLoader func(fileID string) ([]byte, error)
+
+var imageLoader ImageLoader
+
+// SetImageLoader 注册图片读取函数
+func SetImageLoader(loader ImageLoader) {
+	imageLoader = loader
+}
+
+// 向量存储类型
+const (
+	StoreAuto     = "auto"     // Qdrant 可连接时使用 Qdrant，否则使用内置存储
+	StoreQdrant   = "qdrant"   // 独立部署的 Qdrant 服务
+	StoreEmbedded = "embedded" // 主数据库内置存储，内存暴力检索
+)
+
 // VectorEngine 向量引擎
 type VectorEngine struct {
-	db        *gorm.DB
-	storage   VectorStorage
-	embedding EmbeddingProvider
-	enabled   bool
-	mutex     sync.RWMutex
+	db             *gorm.DB
+	storage        VectorStorage
+	storeType      string
+	embedding      EmbeddingProvider
+	imageEmbedding ImageEmbeddingProvider
+	enabled        bool
+	mutex          sync.RWMutex
 }
 
 // VectorService 向量服务接口
@@ -191,26 +218,62 @@ func InitQdrantVectorEngine(qdrantURL string, timeout int) error {
 			logger.Error("初始化Qdrant集合失败: %v", err)
 		}
 
-		// 使用动态OpenAI客户端（无需初始化配置，每次调用时动态读取）
-		dynamicClient := NewDynamicOpenAIClient()
+		initGlobalEngine(qdrantClient, StoreQdrant)
+	})
 
-		db := database.GetDB()
-		if db == nil {
-			logger.Error("数据库连接不可用，向量引擎初始化失败")
-			return
-		}
+	return nil
+}
 
-		globalVectorEngine = &VectorEngine{
-			db:        db,
-			storage:   qdrantClient,
-			embedding: dynamicClient, // 动态客户端，自动读取最新配置
-			enabled:   true,
-		}
+// InitEmbeddedVectorEngine 初始化内置向量存储引擎，向量保存在主数据库中，无需部署Qdrant
+func InitEmbeddedVectorEngine() error {
+	engineOnce.Do(func() {
+		initGlobalEngine(NewEmbeddedStore(database.GetDB()), StoreEmbedded)
 	})
 
 	return nil
 }
 
+// InitConfiguredVectorEngine 按 vector_store 设置选择向量存储：auto 时 Qdrant 可连接则使用 Qdrant，否则回退到内置存储
+func InitConfiguredVectorEngine(qdrantURL string, timeout int) error {
+	store := setting.GetStringDirectFromDB("vector", "vector_store", StoreAuto)
+	switch store {
+	case StoreEmbedded:
+		return InitEmbeddedVectorEngine()
+	case StoreQdrant:
+		if qdrantURL == "" {
+			return fmt.Errorf("未配置 qdrant_url")
+		}
+		return InitQdrantVectorEngine(qdrantURL, timeout)
+	}
+
+	if qdrantURL != "" {
+		err := NewQdrantClient(qdrantURL, timeout).HealthCheck()
+		if err == nil {
+			return InitQdrantVectorEngine(qdrantURL, timeout)
+		}
+		logger.Warn("Qdrant不可用，使用内置向量存储: %v", err)
+	}
+	return InitEmbeddedVectorEngine()
+}
+
+func initGlobalEngine(storage VectorStorage, storeType string) {
+	db := database.GetDB()
+	if db == nil {
+		logger.Error("数据库连接不可用，向量引擎初始化失败")
+		return
+	}
+
+	globalVectorEngine = &VectorEngine{
+		db:             db,
+		storage:        storage,
+		storeType:      storeType,
+		embedding:      NewDynamicOpenAIClient(), // 动态客户端，自动读取最新配置
+		imageEmbedding: NewDynamicMultimodalClient(),
+		enabled:        true,
+	}
+	logger.Info("向量引擎已初始化，存储: %s", storeType)
+}
+
 // ensureInitialized 确保向量引擎已正确初始化（简化版，动态客户端无需懒加载）
 func (ve *VectorEngine) ensureInitialized() error {
 	if ve == nil {
@@ -232,12 +295,10 @@ func (ve *VectorEngine) ensureInitialized() error {
 		return fmt.Errorf("embedding客户端未初始化")
 	}
 
-	// 对于QdrantClient，检查连接是否可用
-	if qdrantClient, ok := ve.storage.(*QdrantClient); ok {
-		if err := qdrantClient.HealthCheck(); err != nil {
-			logger.Warn("Qdrant连接检查失败: %v", err)
-			return fmt.Errorf("qdrant不可用: %v", err)
-		}
+	// 检查存储是否可用
+	if err := ve.storage.HealthCheck(); err != nil {
+		logger.Warn("向量存储连接检查失败: %v", err)
+		return fmt.Errorf("%s不可用: %v", ve.storeType, err)
 	}
 
 	return nil
@@ -298,7 +359,7 @@ func (ve *VectorEngine) Initialize() error {
 }
 
 // CloneVectorFrom 从已有文件复制向量到新文件（用于重复文件零成本复用）
-// description 为空时将尝试从Qdrant payload 或 AIInfo 获取
+// description 为空时将尝试从向量 payload 或 AIInfo 获取
 func (ve *VectorEngine) CloneVectorFrom(originalID, newID, description string) error {
 	if ve == nil {
 		return nil
@@ -307,16 +368,14 @@ func (ve *VectorEngine) CloneVectorFrom(originalID, newID, description string) e
 		return fmt.Errorf("向量引擎未就绪: %v", err)
 	}
 
-	// 仅在存储为 Qdrant 时支持直接拷贝
-	qc, ok := ve.storage.(*QdrantClient)
-	if !ok {
-		return nil // 非Qdrant存储暂不处理，后续由队列兜底
-	}
-
-	vec, payload, err := qc.FetchVectorWithPayload(originalID)
+	vectors, payload, err := ve.storage.FetchVectors(originalID)
 	if err != nil {
 		return err
 	}
+	vec := vectors[VectorNameText]
+	if len(vec) == 0 {
+		return fmt.Errorf("原文件缺少文本向量")
+	}
 
 	desc := description
 	if desc == "" {
@@ -343,6 +402,12 @@ func (ve *VectorEngine) CloneVectorFrom(originalID, newID, description string) e
 	if err := ve.storage.StoreVector(newID, vec, desc, model); err != nil {
 		return err
 	}
+	if imageVec := vectors[VectorNameImage]; len(imageVec) > 0 {
+		imageModel, _ := payload["image_model"].(string)
+		if err := ve.storage.StoreImageVector(newID, imageVec, imageModel); err != nil {
+			logger.Warn("复制图像向量失败 [%s]: %v", newID, err)
+		}
+	}
 
 	var existing models.FileVector
 	if err := ve.db.Where("file_id = ?", newID).First(&existing).Error; err == nil {
@@ -391,9 +456,50 @@ func (ve *VectorEngine) ProcessFile(fileID, description string) error {
 		return fmt.Errorf("存储失败: %v", err)
 	}
 
+	// 图像向量失败不影响文本向量，下次重新生成时再补
+	if ve.ImageVectorsEnabled() {
+		if err := ve.ProcessFileImage(fileID); err != nil {
+			logger.Warn("图像向量生成失败 [%s]: %v", fileID, err)
+		}
+	}
+
 	return nil
 }
 
+// ImageVectorsEnabled 图像向量化是否可用：已配置多模态接口且集合支持命名向量
+func (ve *VectorEngine) ImageVectorsEnabled() bool {
+	if err := ve.ensureInitialized(); err != nil {
+		return false
+	}
+	if ve.imageEmbedding == nil || !ve.storage.SupportsImageVectors() {
+		return false
+	}
+	if e, ok := ve.imageEmbedding.(interface{ Enabled() bool }); ok {
+		return e.Enabled()
+	}
+	return true
+}
+
+// ProcessFileImage 读取文件图片并生成图像向量
+func (ve *VectorEngine) ProcessFileImage(fileID string) error {
+	if !ve.ImageVectorsEnabled() {
+		return fmt.Errorf("图像向量化不可用")
+	}
+	if imageLoader == nil {
+		return fmt.Errorf("未注册图片读取函数")
+	}
+
+	data, err := imageLoader(fileID)
+	if err != nil {
+		return fmt.Errorf("读取图片失败: %v", err)
+	}
+	vec, err := ve.imageEmbedding.GenerateImageEmbedding(data)
+	if err != nil {
+		return fmt.Errorf("图像向量化失败: %v", err)
+	}
+	return ve.storage.StoreImageVector(fileID, vec, ve.imageEmbedding.GetModel())
+}
+
 // BatchProcessFiles 批量处理文件向量化
 func (ve *VectorEngine) BatchProcessFiles(items []VectorItem) error {
 	if err := ve.ensureInitialized(); err != nil {
@@ -446,15 +552,32 @@ func (ve *VectorEngine) SearchSimilarByFileID(fileID string, limit int, userID u
 		return nil, fmt.Errorf("文件向量信息不存在或未完成处理")
 	}
 
-	// 使用Qdrant进行向量相似度搜索
-	if qdrantClient, ok := ve.storage.(*QdrantClient); ok {
-		// 直接使用 fileID 从 Qdrant 搜索相似向量
-		return qdrantClient.SearchSimilarByID(fileID, limit, userID, threshold, baseVector.Model)
+	// 以文件自身存储的向量为基准搜索，向量缺失时降级使用描述文本搜索
+	vectors, _, err := ve.storage.FetchVectors(fileID)
+	if err != nil {
+		logger.Warn("获取基准向量失败，降级使用文本搜索 [%s]: %v", fileID, err)
+		return ve.SearchFiles(baseVector.Description, limit, userID, threshold)
 	}
 
-	// 如果不是Qdrant存储，降级使用描述文本搜索
-	logger.Warn("非Qdrant存储，降级使用文本搜索")
-	return ve.SearchFiles(baseVector.Description, limit, userID, threshold)
+	filter := &SearchFilter{UserID: userID}
+	var lists [][]VectorSearchResult
+	for _, using := range targetVectors(ve.resolveTarget(DefaultQueryTarget())) {
+		base := vectors[using]
+		if len(base) == 0 {
+			// 文件尚未生成图像向量时仅使用文本向量
+			continue
+		}
+		results, err := ve.storage.SearchFilteredUsing(using, base, limit, filter, ve.thresholdFor(using, threshold))
+		if err != nil {
+			logger.Debug("按%s向量搜索相似文件失败 [%s]: %v", using, fileID, err)
+			continue
+		}
+		lists = append(lists, results)
+	}
+	if len(lists) == 0 {
+		return ve.SearchFiles(baseVector.Description, limit, userID, threshold)
+	}
+	return MergeVectorResults(limit, lists...), nil
 }
 
 // SearchFiles 搜索相似文件
@@ -485,6 +608,174 @@ func (ve *VectorEngine) SearchFiles(query string, limit int, userID uint, thresh
 	return results, nil
 }
 
+// SearchFilesFiltered 带结构化过滤条件的语义搜索，过滤在Qdrant内完成，保证返回的是过滤后的前N条；
+// 按系统设置的默认查询目标选择文本向量、图像向量或两者
+func (ve *VectorEngine) SearchFilesFiltered(query string, limit int, filter *SearchFilter, threshold float32) ([]VectorSearchResult, error) {
+	return ve.SearchFilesTarget(query, DefaultQueryTarget(), limit, filter, threshold)
+}
+
+// SearchFilesTarget 在指定查询目标上进行语义搜索，图像向量不可用时退回文本向量
+func (ve *VectorEngine) SearchFilesTarget(query, target string, limit int, filter *SearchFilter, threshold float32) ([]VectorSearchResult, error) {
+	if err := ve.ensureInitialized(); err != nil {
+		return nil, fmt.Errorf("向量搜索功能不可用: %v", err)
+	}
+
+	if query == "" {
+		return nil, fmt.Errorf("搜索查询为空")
+	}
+
+	var lists [][]VectorSearchResult
+	for _, using := range targetVectors(ve.resolveTarget(target)) {
+		provider := ve.embedding
+		if using == VectorNameImage {
+			provider = ve.imageEmbedding
+		}
+		queryVector, err := provider.GenerateEmbedding(query)
+		if err != nil {
+			logger.Error("查询向量化失败: %v", err)
+			return nil, fmt.Errorf("查询向量化失败: %v", err)
+		}
+
+		results, err := ve.storage.SearchFilteredUsing(using, queryVector, limit, filter, ve.thresholdFor(using, threshold))
+		if err != nil {
+			logger.Error("向量过滤搜索失败: %v", err)
+			return nil, fmt.Errorf("搜索失败: %v", err)
+		}
+		lists = append(lists, results)
+	}
+
+	return MergeVectorResults(limit, lists...), nil
+}
+
+// SearchByImage 以图片像素向量搜索图像向量，仅在图像向量化可用时支持；同模态比较使用调用方阈值，未指定时使用图像向量阈值
+func (ve *VectorEngine) SearchByImage(imageData []byte, limit int, filter *SearchFilter, threshold float32) ([]VectorSearchResult, error) {
+	if !ve.ImageVectorsEnabled() {
+		return nil, fmt.Errorf("图像向量化不可用")
+	}
+
+	queryVector, err := ve.imageEmbedding.GenerateImageEmbedding(imageData)
+	if err != nil {
+		return nil, fmt.Errorf("图像向量化失败: %v", err)
+	}
+	if threshold <= 0 {
+		threshold = imageSearchThreshold()
+	}
+	return ve.storage.SearchFilteredUsing(VectorNameImage, queryVector, limit, filter, threshold)
+}
+
+// resolveTarget 图像向量不可用时统一退回文本向量
+func (ve *VectorEngine) resolveTarget(target string) string {
+	if target != QueryTargetImage && target != QueryTargetBoth {
+		return QueryTargetText
+	}
+	if !ve.ImageVectorsEnabled() {
+		return QueryTargetText
+	}
+	return target
+}
+
+// thresholdFor 跨模态相似度普遍低于文本相似度，图像向量使用单独的阈值
+func (ve *VectorEngine) thresholdFor(using string, threshold float32) float32 {
+	if using == VectorNameImage {
+		return imageSearchThreshold()
+	}
+	return threshold
+}
+
+func targetVectors(target string) []string {
+	switch target {
+	case QueryTargetImage:
+		return []string{VectorNameImage}
+	case QueryTargetBoth:
+		return []string{VectorNameText, VectorNameImage}
+	}
+	return []string{VectorNameText}
+}
+
+// DefaultQueryTarget 系统设置的默认查询目标
+func DefaultQueryTarget() string {
+	return setting.GetStringDirectFromDB("vector", "vector_query_target", QueryTargetText)
+}
+
+func imageSearchThreshold() float32 {
+	return float32(setting.GetFloatDirectFromDB("vector", "image_embedding_threshold", 0.2))
+}
+
+// MergeVectorResults 合并多个命名向量的搜索结果：同一文件取最高相似度，按相似度降序截取前 limit 条
+func MergeVectorResults(limit int, lists ...[]VectorSearchResult) []VectorSearchResult {
+	if len(lists) == 1 {
+		if limit > 0 && len(lists[0]) > limit {
+			return lists[0][:limit]
+		}
+		return lists[0]
+	}
+
+	byID := make(map[string]*VectorSearchResult)
+	order := make([]string, 0)
+	for _, list := range lists {
+		for _, r := range list {
+			existing, ok := byID[r.FileID]
+			if !ok {
+				item := r
+				byID[r.FileID] = &item
+				order = append(order, r.FileID)
+				continue
+			}
+			if r.Using != existing.Using {
+				existing.Using = QueryTargetBoth
+			}
+			if r.Similarity > existing.Similarity {
+				existing.Similarity, existing.Score = r.Similarity, r.Score
+			}
+			if existing.Description == "" {
+				existing.Description = r.Description
+			}
+		}
+	}
+
+	merged := make([]VectorSearchResult, 0, len(order))
+	for _, id := range order {
+		merged = append(merged, *byID[id])
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Similarity != merged[j].Similarity {
+			return merged[i].Similarity > merged[j].Similarity
+		}
+		return merged[i].FileID < merged[j].FileID
+	})
+	if limit > 0 && len(merged) > limit {
+		merged = merged[:limit]
+	}
+	return merged
+}
+
+// RefreshPayloads 文件标签、分类、访问级别等变化后同步向量payload，未完成向量化的文件直接跳过
+func (ve *VectorEngine) RefreshPayloads(fileIDs []string) error {
+	if len(fileIDs) == 0 {
+		return nil
+	}
+	if err := ve.ensureInitialized(); err != nil {
+		return nil
+	}
+
+	var vectors []models.FileVector
+	if err := ve.db.Select("file_id, description, model").
+		Where("file_id IN ? AND status = ?", fileIDs, common.VectorStatusCompleted).
+		Find(&vectors).Error; err != nil {
+		return fmt.Errorf("查询向量记录失败: %v", err)
+	}
+
+	for _, vec := range vectors {
+		payload := BuildFilePayload(ve.db, vec.FileID)
+		payload["description"] = vec.Description
+		payload["model"] = vec.Model
+		if err := ve.storage.UpdatePayload(vec.FileID, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (ve *VectorEngine) DeleteVector(fileID string) error {
 	// 如果向量功能未启用，不执行删除操作（不算错误）
 	if err := ve.ensureInitialized(); err != nil {
@@ -519,15 +810,27 @@ func (ve *VectorEngine) VectorExists(fileID string) (bool, error) {
 	return ve.storage.VectorExists(fileID)
 }
 
+// RecreateCollection 删除并重建向量集合，调用方需随后重新生成全部向量
+func (ve *VectorEngine) RecreateCollection() error {
+	if err := ve.ensureInitialized(); err != nil {
+		return fmt.Errorf("向量搜索功能不可用: %v", err)
+	}
+	return ve.storage.RecreateCollection()
+}
+
 func (ve *VectorEngine) GetAllFileIDs(limit int) ([]string, error) {
 	if err := ve.ensureInitialized(); err != nil {
 		return nil, fmt.Errorf("向量搜索功能不可用: %v", err)
 	}
-	// 仅在 Qdrant 存储下支持
-	if qdrantClient, ok := ve.storage.(*QdrantClient); ok {
-		return qdrantClient.GetAllFileIDs(limit)
+	return ve.storage.GetAllFileIDs(limit)
+}
+
+// StoreType 当前使用的向量存储类型：qdrant / embedded
+func (ve *VectorEngine) StoreType() string {
+	if ve == nil {
+		return ""
 	}
-	return nil, fmt.Errorf("当前存储不支持遍历所有向量id")
+	return ve.storeType
 }
 
 // HealthCheck 健康检查
@@ -540,11 +843,8 @@ func (ve *VectorEngine) HealthCheck() error {
 		return fmt.Errorf("向量存储未初始化")
 	}
 
-	// 对于 QdrantClient，检查连接健康状态
-	if qdrantClient, ok := ve.storage.(*QdrantClient); ok {
-		if err := qdrantClient.HealthCheck(); err != nil {
-			return fmt.Errorf("qdrant连接不健康: %v", err)
-		}
+	if err := ve.storage.HealthCheck(); err != nil {
+		return fmt.Errorf("%s连接不健康: %v", ve.storeType, err)
 	}
 
 	// 注意：在直连模式下，ve.db 可以为 nil，ve.embedding 也可能不需要
@@ -0,0 +1,95 @@
+package watermark
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+)
+
+// 内置字体（Go 字体，仅覆盖拉丁字符；中文等字符需通过 FontFile 指定字体文件）
+var builtinFonts = map[string][]byte{
+	"regular":   goregular.TTF,
+	"bold":      gobold.TTF,
+	"italic":    goitalic.TTF,
+	"mono":      gomono.TTF,
+	"mono-bold": gomonobold.TTF,
+}
+
+const defaultFontFamily = "regular"
+
+var fontCache sync.Map // key -> *opentype.Font
+
+/* BuiltinFontFamilies 内置字体名称列表 */
+func BuiltinFontFamilies() []string {
+	return []string{"regular", "bold", "italic", "mono", "mono-bold"}
+}
+
+// loadFont 按配置加载字体，已解析的字体会被缓存
+func (p *Processor) loadFont(config *WatermarkConfig) (*opentype.Font, error) {
+	if config.FontFile != "" {
+		return p.loadFontFile(config.FontFile)
+	}
+
+	family := strings.ToLower(strings.TrimSpace(config.FontFamily))
+	if family == "" {
+		family = defaultFontFamily
+	}
+	data, ok := builtinFonts[family]
+	if !ok {
+		return nil, fmt.Errorf("不支持的字体: %s", config.FontFamily)
+	}
+	return cachedFont("builtin:"+family, data)
+}
+
+func (p *Processor) loadFontFile(fontFile string) (*opentype.Font, error) {
+	filePath := strings.TrimPrefix(fontFile, "/")
+	if strings.Contains(filePath, "..") {
+		return nil, fmt.Errorf("非法字体路径：包含 '..' 路径遍历")
+	}
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".ttf", ".otf", ".ttc", ".otc":
+	default:
+		return nil, fmt.Errorf("仅支持 TTF/OTF/TTC 字体文件")
+	}
+
+	fullPath := strings.ReplaceAll(fmt.Sprintf("%s/%s", p.watermarkImagePath, filePath), "\\", "/")
+	key := "file:" + fullPath
+	if f, ok := fontCache.Load(key); ok {
+		return f.(*opentype.Font), nil
+	}
+
+	data, err := readFileBytes(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取字体文件失败 %s: %w", fullPath, err)
+	}
+	return cachedFont(key, data)
+}
+
+func cachedFont(key string, data []byte) (*opentype.Font, error) {
+	if f, ok := fontCache.Load(key); ok {
+		return f.(*opentype.Font), nil
+	}
+
+	f, err := opentype.Parse(data)
+	if err != nil {
+		// 字体集合取第一个字体
+		collection, cerr := opentype.ParseCollection(data)
+		if cerr != nil {
+			return nil, fmt.Errorf("字体解析失败: %w", err)
+		}
+		if f, err = collection.Font(0); err != nil {
+			return nil, fmt.Errorf("字体解析失败: %w", err)
+		}
+	}
+
+	actual, _ := fontCache.LoadOrStore(key, f)
+	return actual.(*opentype.Font), nil
+}
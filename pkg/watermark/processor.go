@@ -21,14 +21,15 @@ type Processor struct {
 	watermarkImagePath  string
 	maxImageSize        int
 	defaultFontSize     float64
-	enableTextWatermark bool // 预留开关（当前不支持文字水印）
+	enableTextWatermark bool
 }
 
 func NewProcessor() *Processor {
 	return &Processor{
-		watermarkImagePath: "./assets/watermarks",
-		maxImageSize:       8192, // 支持现代相机/手机的8K分辨率
-		defaultFontSize:    24,
+		watermarkImagePath:  "./assets/watermarks",
+		maxImageSize:        8192, // 支持现代相机/手机的8K分辨率
+		defaultFontSize:     24,
+		enableTextWatermark: true,
 	}
 }
 
@@ -67,8 +68,8 @@ func (p *Processor) ProcessImage(file image.Image, config *WatermarkConfig) (ima
 	newImg := image.NewRGBA(bounds)
 	draw.Draw(newImg, bounds, file, bounds.Min, draw.Over)
 
-	if err := p.applyImageWatermark(newImg, config); err != nil {
-		return nil, fmt.Errorf("应用文件水印失败: %w", err)
+	if err := p.applyWatermark(newImg, config); err != nil {
+		return nil, fmt.Errorf("应用水印失败: %w", err)
 	}
 
 	return newImg, nil
@@ -121,10 +122,44 @@ func (p *Processor) hasTransparency(file image.Image) bool {
 	return false
 }
 
-func (p *Processor) applyImageWatermark(dst *image.RGBA, config *WatermarkConfig) error {
+func (p *Processor) applyWatermark(dst *image.RGBA, config *WatermarkConfig) error {
+	var src image.Image
+	if config.Type == TypeText {
+		bounds := dst.Bounds()
+		textImg, err := p.renderTextWatermark(bounds.Dx(), bounds.Dy(), config)
+		if err != nil {
+			return fmt.Errorf("渲染文字水印失败: %w", err)
+		}
+		src = textImg
+	} else {
+		scaled, err := p.loadScaledWatermarkImage(config)
+		if err != nil {
+			return err
+		}
+		src = scaled
+	}
+
+	if config.Rotation != 0 {
+		src = p.rotateImageGeneric(src, float64(config.Rotation))
+	}
+
+	if config.Tiled {
+		p.drawTiled(dst, trimTransparent(src), config)
+		return nil
+	}
+
+	// 使用旋转后的尺寸计算位置，避免被裁切；支持锚点+偏移
+	wmW, wmH := src.Bounds().Dx(), src.Bounds().Dy()
+	bounds := dst.Bounds()
+	pos := p.calculatePositionWithConfig(bounds.Max.X, bounds.Max.Y, wmW, wmH, config)
+	p.drawWatermark(dst, src, image.Rect(pos.X, pos.Y, pos.X+wmW, pos.Y+wmH), config)
+	return nil
+}
+
+func (p *Processor) loadScaledWatermarkImage(config *WatermarkConfig) (*image.RGBA, error) {
 	watermarkImg, err := p.loadWatermarkImage(config)
 	if err != nil {
-		return fmt.Errorf("加载水印文件失败: %w", err)
+		return nil, fmt.Errorf("加载水印文件失败: %w", err)
 	}
 
 	wmBounds := watermarkImg.Bounds()
@@ -146,25 +181,38 @@ func (p *Processor) applyImageWatermark(dst *image.RGBA, config *WatermarkConfig
 		wmHeight = originalHeight
 	}
 
-	scaled := p.scaleImageNearest(watermarkImg, wmWidth, wmHeight)
-	finalSrc := image.Image(scaled)
+	return p.scaleImageNearest(watermarkImg, wmWidth, wmHeight), nil
+}
 
-	var drawRect image.Rectangle
-	if config.Rotation != 0 {
-		rotated := p.rotateImageGeneric(scaled, float64(config.Rotation))
-		finalSrc = rotated
-		rotW := rotated.Bounds().Dx()
-		rotH := rotated.Bounds().Dy()
-		bounds := dst.Bounds()
-		// 使用旋转后的尺寸计算位置，避免被裁切；新算法支持锚点+偏移
-		pos := p.calculatePositionWithConfig(bounds.Max.X, bounds.Max.Y, rotW, rotH, config)
-		drawRect = image.Rect(pos.X, pos.Y, pos.X+rotW, pos.Y+rotH)
-	} else {
-		bounds := dst.Bounds()
-		pos := p.calculatePositionWithConfig(bounds.Max.X, bounds.Max.Y, wmWidth, wmHeight, config)
-		drawRect = image.Rect(pos.X, pos.Y, pos.X+wmWidth, pos.Y+wmHeight)
+// drawTiled 按网格平铺水印，奇数行错开半个步长；起点向左上扩展一格以覆盖边缘
+func (p *Processor) drawTiled(dst *image.RGBA, src image.Image, config *WatermarkConfig) {
+	bounds := dst.Bounds()
+	wmW, wmH := src.Bounds().Dx(), src.Bounds().Dy()
+	if wmW <= 0 || wmH <= 0 {
+		return
+	}
+
+	gapX, gapY := config.TileGapX, config.TileGapY
+	if config.OffsetUnit == "percent" {
+		gapX *= float64(bounds.Dx())
+		gapY *= float64(bounds.Dy())
 	}
+	stepX := maxInt(wmW+int(gapX), 1)
+	stepY := maxInt(wmH+int(gapY), 1)
+
+	for row, y := 0, bounds.Min.Y-stepY/2; y < bounds.Max.Y; row, y = row+1, y+stepY {
+		shift := 0
+		if row%2 == 1 {
+			shift = stepX / 2
+		}
+		for x := bounds.Min.X - stepX + shift; x < bounds.Max.X; x += stepX {
+			p.drawWatermark(dst, src, image.Rect(x, y, x+wmW, y+wmH), config)
+		}
+	}
+}
 
+// drawWatermark 在指定区域绘制水印（含阴影）
+func (p *Processor) drawWatermark(dst *image.RGBA, src image.Image, drawRect image.Rectangle, config *WatermarkConfig) {
 	if config.Shadow {
 		shadowRect := image.Rect(drawRect.Min.X+config.ShadowOffsetX, drawRect.Min.Y+config.ShadowOffsetY, drawRect.Max.X+config.ShadowOffsetX, drawRect.Max.Y+config.ShadowOffsetY)
 		// 解析前端传入的阴影颜色，默认黑色
@@ -185,11 +233,44 @@ func (p *Processor) applyImageWatermark(dst *image.RGBA, config *WatermarkConfig
 		if shadowOpacity > 1.0 {
 			shadowOpacity = 1.0
 		}
-		p.drawShadowWithMask(dst, finalSrc, shadowRect, shadowCol, shadowOpacity)
+		p.drawShadowWithMask(dst, src, shadowRect, shadowCol, shadowOpacity)
 	}
 
-	p.drawImageWithOpacity(dst, finalSrc, drawRect, config.Opacity)
-	return nil
+	p.drawImageWithOpacity(dst, src, drawRect, config.Opacity)
+}
+
+// trimTransparent 裁掉四周完全透明的区域，旋转后的水印四角留白较多，平铺时会导致间距过大
+func trimTransparent(src image.Image) image.Image {
+	b := src.Bounds()
+	minX, minY, maxX, maxY := b.Max.X, b.Max.Y, b.Min.X-1, b.Min.Y-1
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := src.At(x, y).RGBA(); a == 0 {
+				continue
+			}
+			if x < minX {
+				minX = x
+			}
+			if x > maxX {
+				maxX = x
+			}
+			if y < minY {
+				minY = y
+			}
+			if y > maxY {
+				maxY = y
+			}
+		}
+	}
+	if maxX < minX || maxY < minY {
+		return src
+	}
+	if sub, ok := src.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(image.Rect(minX, minY, maxX+1, maxY+1))
+	}
+	return src
 }
 
 func (p *Processor) drawShadowWithMask(dst *image.RGBA, src image.Image, rect image.Rectangle, shadow color.RGBA, opacity float64) {
@@ -465,16 +546,61 @@ func (p *Processor) ValidateConfig(config *WatermarkConfig) error {
 	if !config.Enabled {
 		return nil
 	}
-	if config.Type != TypeImage && config.Type != TypeFile {
-		return fmt.Errorf("仅支持图片水印")
+	switch config.Type {
+	case TypeImage:
+	case TypeText:
+		return p.validateTextConfig(config)
+	default:
+		return fmt.Errorf("不支持的水印类型: %s", config.Type)
 	}
 
 	if config.FileBase64 == "" && len(config.FileData) == 0 && config.GeneratedImage == "" && config.GeneratedFile == "" && config.FileURL == "" {
 		return fmt.Errorf("图片水印必须指定水印数据源（fileBase64[前端生成]/fileURL[后端文件]/其他）")
 	}
+	return p.validateCommonConfig(config)
+}
+
+func (p *Processor) validateTextConfig(config *WatermarkConfig) error {
+	if !p.enableTextWatermark {
+		return fmt.Errorf("文字水印未启用")
+	}
+	text := strings.TrimSpace(config.Text)
+	if text == "" {
+		return fmt.Errorf("文字水印内容不能为空")
+	}
+	if len([]rune(text)) > maxTextRunes {
+		return fmt.Errorf("文字水印内容不能超过%d个字符", maxTextRunes)
+	}
+	if config.FontSizeUnit != "" && config.FontSizeUnit != "px" && config.FontSizeUnit != "percent" {
+		return fmt.Errorf("FontSizeUnit 仅支持 px 或 percent")
+	}
+	if config.FontSizeUnit == "percent" && config.FontSize > 1 {
+		return fmt.Errorf("当 FontSizeUnit=percent 时，fontSize 必须在 0-1 之间")
+	}
+	if config.StrokeWidth < 0 || config.StrokeWidth > maxStrokeRatio {
+		return fmt.Errorf("描边宽度必须在0-%.1f之间", maxStrokeRatio)
+	}
+	for _, c := range []string{config.FontColor, config.StrokeColor} {
+		if c == "" {
+			continue
+		}
+		if _, err := p.parseColor(c); err != nil {
+			return err
+		}
+	}
+	if _, err := p.loadFont(config); err != nil {
+		return err
+	}
+	return p.validateCommonConfig(config)
+}
+
+func (p *Processor) validateCommonConfig(config *WatermarkConfig) error {
 	if config.Opacity < 0 || config.Opacity > 1 {
 		return fmt.Errorf("透明度必须在0-1之间")
 	}
+	if config.TileGapX < 0 || config.TileGapY < 0 {
+		return fmt.Errorf("平铺间距不能为负数")
+	}
 	if config.OffsetUnit != "" && config.OffsetUnit != "px" && config.OffsetUnit != "percent" {
 		return fmt.Errorf("OffsetUnit 仅支持 px 或 percent")
 	}
@@ -531,10 +657,10 @@ func maxInt(a, b int) int {
 	return b
 }
 
-func (p *Processor) rotateImageGeneric(src image.Image, degrees float64) *image.RGBA {
+func (p *Processor) rotateImageGeneric(src image.Image, degrees float64) *image.NRGBA {
 	if degrees == 0 {
 		bounds := src.Bounds()
-		dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		dst := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
 		draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Src)
 		return dst
 	}
@@ -542,7 +668,7 @@ func (p *Processor) rotateImageGeneric(src image.Image, degrees float64) *image.
 	width := bounds.Dx()
 	height := bounds.Dy()
 	newSize := int(math.Sqrt(float64(width*width+height*height))) + 10
-	dst := image.NewRGBA(image.Rect(0, 0, newSize, newSize))
+	dst := image.NewNRGBA(image.Rect(0, 0, newSize, newSize))
 	centerX := float64(newSize / 2)
 	centerY := float64(newSize / 2)
 	srcCenterX := float64(width / 2)
@@ -0,0 +1,169 @@
+package watermark
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	defaultFontSizeRatio = 0.04 // 未设置字号时取图片短边的 4%
+	minFontSize          = 6
+	maxFontSize          = 1000
+	maxStrokeRatio       = 0.2
+	maxTextRunes         = 200
+)
+
+// resolveFontSize 计算实际像素字号，percent 模式相对图片短边
+func resolveFontSize(imgW, imgH int, config *WatermarkConfig) float64 {
+	short := float64(imgW)
+	if imgH < imgW {
+		short = float64(imgH)
+	}
+
+	size := config.FontSize
+	switch {
+	case size <= 0:
+		size = short * defaultFontSizeRatio
+	case config.FontSizeUnit == "percent":
+		size = short * size
+	}
+	return math.Min(math.Max(size, minFontSize), maxFontSize)
+}
+
+// renderTextWatermark 将文字渲染为透明背景的水印图，多行文字按行居中
+func (p *Processor) renderTextWatermark(imgW, imgH int, config *WatermarkConfig) (*image.NRGBA, error) {
+	f, err := p.loadFont(config)
+	if err != nil {
+		return nil, err
+	}
+
+	size := resolveFontSize(imgW, imgH, config)
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("创建字体失败: %w", err)
+	}
+	defer face.Close()
+
+	fillCol := color.RGBA{255, 255, 255, 255}
+	if config.FontColor != "" {
+		c, err := p.parseColor(config.FontColor)
+		if err != nil {
+			return nil, err
+		}
+		fillCol = c.(color.RGBA)
+	}
+	strokeCol := color.RGBA{0, 0, 0, 255}
+	if config.StrokeColor != "" {
+		c, err := p.parseColor(config.StrokeColor)
+		if err != nil {
+			return nil, err
+		}
+		strokeCol = c.(color.RGBA)
+	}
+	strokeRadius := math.Min(config.StrokeWidth, maxStrokeRatio) * size
+
+	lines := strings.Split(strings.ReplaceAll(config.Text, "\r\n", "\n"), "\n")
+	metrics := face.Metrics()
+	lineHeight := metrics.Height.Ceil()
+	ascent := metrics.Ascent.Ceil()
+
+	widths := make([]int, len(lines))
+	textW := 0
+	for i, line := range lines {
+		widths[i] = font.MeasureString(face, line).Ceil()
+		if widths[i] > textW {
+			textW = widths[i]
+		}
+	}
+	textH := lineHeight*(len(lines)-1) + ascent + metrics.Descent.Ceil()
+	if textW <= 0 || textH <= 0 {
+		return nil, fmt.Errorf("水印文字为空")
+	}
+
+	pad := int(math.Ceil(strokeRadius)) + 2
+	mask := image.NewAlpha(image.Rect(0, 0, textW+pad*2, textH+pad*2))
+	drawer := &font.Drawer{Dst: mask, Src: image.Opaque, Face: face}
+	for i, line := range lines {
+		drawer.Dot = fixed.P(pad+(textW-widths[i])/2, pad+ascent+i*lineHeight)
+		drawer.DrawString(line)
+	}
+
+	var strokeMask *image.Alpha
+	if strokeRadius > 0 {
+		strokeMask = dilateAlpha(mask, strokeRadius)
+	}
+	return composeText(mask, strokeMask, fillCol, strokeCol), nil
+}
+
+// dilateAlpha 按圆形半径膨胀蒙版，得到描边区域
+func dilateAlpha(src *image.Alpha, radius float64) *image.Alpha {
+	r := int(math.Ceil(radius))
+	type offset struct{ dx, dy int }
+	var offsets []offset
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if float64(dx*dx+dy*dy) <= radius*radius+0.5 {
+				offsets = append(offsets, offset{dx, dy})
+			}
+		}
+	}
+
+	b := src.Bounds()
+	dst := image.NewAlpha(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			var maxA uint8
+			for _, o := range offsets {
+				sx, sy := x+o.dx, y+o.dy
+				if sx < b.Min.X || sy < b.Min.Y || sx >= b.Max.X || sy >= b.Max.Y {
+					continue
+				}
+				if a := src.Pix[src.PixOffset(sx, sy)]; a > maxA {
+					maxA = a
+					if maxA == 255 {
+						break
+					}
+				}
+			}
+			dst.Pix[dst.PixOffset(x, y)] = maxA
+		}
+	}
+	return dst
+}
+
+// composeText 将文字填充叠加在描边之上，输出非预乘的 NRGBA 图
+func composeText(fill, stroke *image.Alpha, fillCol, strokeCol color.RGBA) *image.NRGBA {
+	b := fill.Bounds()
+	out := image.NewNRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			fa := float64(fill.Pix[fill.PixOffset(x, y)]) / 255
+			sa := 0.0
+			if stroke != nil {
+				sa = float64(stroke.Pix[stroke.PixOffset(x, y)]) / 255
+			}
+			outA := fa + sa*(1-fa)
+			if outA <= 0 {
+				continue
+			}
+			sw := sa * (1 - fa)
+			blend := func(f, s uint8) uint8 {
+				return uint8(math.Round((float64(f)*fa + float64(s)*sw) / outA))
+			}
+			out.SetNRGBA(x, y, color.NRGBA{
+				R: blend(fillCol.R, strokeCol.R),
+				G: blend(fillCol.G, strokeCol.G),
+				B: blend(fillCol.B, strokeCol.B),
+				A: uint8(math.Round(outA * 255)),
+			})
+		}
+	}
+	return out
+}
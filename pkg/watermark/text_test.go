@@ -0,0 +1,119 @@
+package watermark
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func newSolidImage(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: c}, image.Point{}, draw.Src)
+	return img
+}
+
+func changedPixels(before, after image.Image) int {
+	n := 0
+	b := before.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r1, g1, b1, _ := before.At(x, y).RGBA()
+			r2, g2, b2, _ := after.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// TestResolveFontSize 验证字号计算：percent 相对短边，未设置时取默认比例
+func TestResolveFontSize(t *testing.T) {
+	tests := []struct {
+		name   string
+		config WatermarkConfig
+		want   float64
+	}{
+		{"px", WatermarkConfig{FontSize: 32}, 32},
+		{"percent", WatermarkConfig{FontSize: 0.1, FontSizeUnit: "percent"}, 60},
+		{"default", WatermarkConfig{}, 600 * defaultFontSizeRatio},
+		{"min", WatermarkConfig{FontSize: 1}, minFontSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveFontSize(800, 600, &tt.config); got != tt.want {
+				t.Errorf("resolveFontSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTextWatermark 验证文字水印只绘制在锚点附近，平铺模式覆盖整张图片
+func TestTextWatermark(t *testing.T) {
+	processor := NewProcessor()
+	src := newSolidImage(400, 300, color.RGBA{40, 40, 40, 255})
+
+	config := &WatermarkConfig{
+		Enabled:     true,
+		Type:        TypeText,
+		Text:        "PixelPunk",
+		FontSize:    24,
+		FontColor:   "#ffffff",
+		StrokeColor: "#ff0000",
+		StrokeWidth: 0.1,
+		Position:    PositionBottomRight,
+		OffsetX:     10,
+		OffsetY:     10,
+		Opacity:     1,
+	}
+	single, err := processor.ProcessImage(src, config)
+	if err != nil {
+		t.Fatalf("ProcessImage() error = %v", err)
+	}
+	if changedPixels(src.SubImage(image.Rect(200, 150, 400, 300)), single) == 0 {
+		t.Fatal("右下角应绘制文字水印")
+	}
+	if n := changedPixels(src.SubImage(image.Rect(0, 0, 200, 150)), single); n != 0 {
+		t.Fatalf("左上角不应绘制水印，实际改变 %d 个像素", n)
+	}
+
+	config.Tiled = true
+	config.Rotation = -30
+	tiled, err := processor.ProcessImage(src, config)
+	if err != nil {
+		t.Fatalf("ProcessImage() tiled error = %v", err)
+	}
+	for _, r := range []image.Rectangle{image.Rect(0, 0, 200, 150), image.Rect(200, 150, 400, 300)} {
+		if changedPixels(src.SubImage(r), tiled) == 0 {
+			t.Fatalf("平铺水印应覆盖区域 %v", r)
+		}
+	}
+}
+
+func TestValidateTextConfig(t *testing.T) {
+	processor := NewProcessor()
+	base := WatermarkConfig{Enabled: true, Type: TypeText, Text: "hi", Opacity: 0.5}
+
+	if err := processor.ValidateConfig(&base); err != nil {
+		t.Fatalf("合法配置校验失败: %v", err)
+	}
+
+	invalid := []WatermarkConfig{
+		{Enabled: true, Type: TypeText, Text: "  ", Opacity: 0.5},
+		{Enabled: true, Type: TypeText, Text: "hi", FontFamily: "comic", Opacity: 0.5},
+		{Enabled: true, Type: TypeText, Text: "hi", FontSize: 2, FontSizeUnit: "percent"},
+		{Enabled: true, Type: TypeText, Text: "hi", StrokeWidth: 0.5},
+		{Enabled: true, Type: TypeText, Text: "hi", FontFile: "../secret.ttf"},
+	}
+	for i := range invalid {
+		if err := processor.ValidateConfig(&invalid[i]); err == nil {
+			t.Errorf("第 %d 个配置应校验失败", i)
+		}
+	}
+
+	processor.SetEnableTextWatermark(false)
+	if err := processor.ValidateConfig(&base); err == nil {
+		t.Error("关闭文字水印后应校验失败")
+	}
+}
@@ -9,6 +9,7 @@ type WatermarkType string
 const (
 	TypeImage WatermarkType = "image"
 	TypeFile  WatermarkType = "image" // 向后兼容别名
+	TypeText  WatermarkType = "text"
 )
 
 type WatermarkPosition string
@@ -43,6 +44,21 @@ type WatermarkConfig struct {
 	Scale      float64           `json:"scale"`                // 0.1-2
 	Rotation   int               `json:"rotation"`             // -180 到 180 度
 
+	// 文字水印配置（Type=text 时生效）
+	Text         string  `json:"text"`
+	FontFamily   string  `json:"fontFamily,omitempty"`   // 内置字体: regular|bold|italic|mono|mono-bold
+	FontFile     string  `json:"fontFile,omitempty"`     // 水印目录下的 TTF/OTF/TTC 字体文件，优先于 FontFamily（中文需指定）
+	FontSize     float64 `json:"fontSize"`               // 字号
+	FontSizeUnit string  `json:"fontSizeUnit,omitempty"` // "px" | "percent"（相对图片短边，0-1）
+	FontColor    string  `json:"fontColor"`
+	StrokeColor  string  `json:"strokeColor,omitempty"`
+	StrokeWidth  float64 `json:"strokeWidth,omitempty"` // 描边宽度（相对字号的比例，0-0.2）
+
+	// 平铺模式：水印按网格重复铺满整张图片，隔行错位；间距单位与 OffsetUnit 相同
+	Tiled    bool    `json:"tiled"`
+	TileGapX float64 `json:"tileGapX,omitempty"`
+	TileGapY float64 `json:"tileGapY,omitempty"`
+
 	Shadow        bool   `json:"shadow"`
 	ShadowColor   string `json:"shadowColor"`
 	ShadowBlur    int    `json:"shadowBlur"`
@@ -60,7 +76,7 @@ type ProcessorInterface interface {
 	SetWatermarkImagePath(path string)
 	ValidateConfig(config *WatermarkConfig) error
 	GetSupportedFormats() []string
-	SetEnableTextWatermark(enabled bool) // 预留的文字水印开关（当前无效）
+	SetEnableTextWatermark(enabled bool) // 文字水印开关
 }
 
 type Position struct {
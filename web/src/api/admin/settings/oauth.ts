@@ -37,6 +37,7 @@ export interface GithubOAuthLoginRequest {
 
 export interface OAuthLoginResponse {
   token: string
+  refresh_token?: string
   userInfo: {
     id: number
     username: string
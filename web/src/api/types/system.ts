@@ -142,6 +142,7 @@ export interface ApiKeyInfo extends StatusInfo, TimeStamps {
   upload_count_limit: number
   upload_count_used?: number
   allowed_types: string[]
+  s3_permissions?: string[]
   folder_id?: string
   folder_path?: string
   is_expired?: boolean
@@ -156,6 +157,7 @@ export interface CreateApiKeyRequest {
   single_file_limit?: number
   upload_count_limit?: number
   allowed_types?: string[]
+  s3_permissions?: string[]
   folder_id?: string
   expires_in_days?: number
 }
@@ -167,6 +169,7 @@ export interface UpdateApiKeyRequest {
   single_file_limit?: number
   upload_count_limit?: number
   allowed_types?: string[]
+  s3_permissions?: string[]
   folder_id?: string
   expires_in_days?: number
 }
@@ -28,6 +28,8 @@ export interface UserRegisterRequest {
 
 export interface LoginResponse {
   token: string
+  refresh_token?: string
+  expires_in?: number
   userInfo: UserInfo
 }
 
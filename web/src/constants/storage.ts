@@ -3,6 +3,7 @@
  */
 
 export const TOKEN_KEY = 'token'
+export const REFRESH_TOKEN_KEY = 'refreshToken'
 export const USER_INFO_KEY = 'userInfo'
 export const TOKEN_EXPIRES = 24 * 7 // 7天
 export const LAYOUT_MODE_KEY = 'layoutMode'
@@ -362,6 +362,13 @@ export const settings = {
       expiresHint: 'Leave blank for no expiration time',
       folderLabel: 'Bound Folder',
       folderHint: 'Optional bound upload folder, leave blank to upload to root directory',
+      s3PermissionsLabel: 'S3 access',
+      s3PermissionsPlaceholder: 'S3 access disabled',
+      s3PermissionsHint:
+        'Lets this key list, download, upload or delete files through the S3-compatible API; not available for keys bound to a workspace folder',
+      s3Read: 'Read (list and download)',
+      s3Write: 'Upload',
+      s3Delete: 'Delete',
     },
     detail: {
       copyCta: 'Copy to Clipboard',
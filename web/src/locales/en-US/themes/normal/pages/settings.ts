@@ -363,6 +363,13 @@ export const settings = {
       expiresHint: 'Leave empty means never expires',
       folderLabel: 'Associated Folder',
       folderHint: 'Specify upload folder, leave empty means upload to root, supports multi-level directory selection',
+      s3PermissionsLabel: 'S3 access',
+      s3PermissionsPlaceholder: 'S3 access disabled',
+      s3PermissionsHint:
+        'Lets this key list, download, upload or delete files through the S3-compatible API; not available for keys bound to a workspace folder',
+      s3Read: 'Read (list and download)',
+      s3Write: 'Upload',
+      s3Delete: 'Delete',
     },
     detail: {
       copyCta: 'Copy to Clipboard',
@@ -364,6 +364,13 @@ export const settings = {
       expiresHint: '空欄は期限なしを意味します',
       folderLabel: '関連フォルダ',
       folderHint: 'アップロードフォルダを指定、空欄はルートにアップロード、マルチレベルディレクトリ選択をサポート',
+      s3PermissionsLabel: 'S3 アクセス権限',
+      s3PermissionsPlaceholder: 'S3 アクセスを無効にする',
+      s3PermissionsHint:
+        'S3 互換 API を通じて、選択した権限でファイルの一覧表示・ダウンロード・アップロード・削除ができます。ワークスペースのフォルダに紐付けたキーでは利用できません',
+      s3Read: '読み取り（一覧とダウンロード）',
+      s3Write: 'アップロード',
+      s3Delete: '削除',
     },
     detail: {
       copyCta: 'クリップボードにコピー',
@@ -360,6 +360,12 @@ export const settings = {
       expiresHint: '留空表示永不过期',
       folderLabel: '关联文件夹',
       folderHint: '指定上传文件夹，留空表示上传到根目录，支持多级目录选择',
+      s3PermissionsLabel: 'S3 访问权限',
+      s3PermissionsPlaceholder: '不开启 S3 访问',
+      s3PermissionsHint: '开启 S3 兼容接口后密钥可按所选权限列举、下载、上传或删除文件；绑定工作区文件夹的密钥不支持',
+      s3Read: '读取（列举与下载）',
+      s3Write: '上传',
+      s3Delete: '删除',
     },
     detail: {
       copyCta: '复制到剪贴板',
@@ -2,7 +2,7 @@ import { defineStore } from 'pinia'
 import { userApi } from '@/api'
 import type { UserInfo, UserLoginRequest, UserRegisterRequest } from '@/api/types/index'
 import { StorageUtil } from '@/utils/storage/storage'
-import { TOKEN_EXPIRES, TOKEN_KEY, USER_INFO_KEY } from '@/constants'
+import { REFRESH_TOKEN_KEY, TOKEN_EXPIRES, TOKEN_KEY, USER_INFO_KEY } from '@/constants'
 
 interface AuthState {
   user: UserInfo | null
@@ -47,9 +47,9 @@ export const useAuthStore = defineStore('auth', {
     async login(loginData: UserLoginRequest) {
       const result = await userApi.login(loginData)
       if (result.success) {
-        const { userInfo, token } = result.data
+        const { userInfo, token, refresh_token } = result.data
         this.setUserInfo(userInfo)
-        this.setToken(token)
+        this.setToken(token, refresh_token)
         return result.data
       }
       // 优先使用后端返回的错误消息，fallback使用默认消息
@@ -79,9 +79,12 @@ export const useAuthStore = defineStore('auth', {
       StorageUtil.set<UserInfo>(USER_INFO_KEY, user, TOKEN_EXPIRES)
     },
 
-    setToken(token: string) {
+    setToken(token: string, refreshToken?: string) {
       this.token = token
       StorageUtil.set<string>(TOKEN_KEY, token, TOKEN_EXPIRES)
+      if (refreshToken) {
+        StorageUtil.set<string>(REFRESH_TOKEN_KEY, refreshToken, TOKEN_EXPIRES)
+      }
 
       const tokenExpireTime = this.getTokenExpireTime(token)
       if (tokenExpireTime > 0) {
@@ -99,6 +102,7 @@ export const useAuthStore = defineStore('auth', {
       this.avatarUrl = null
       this.initialized = false
       StorageUtil.remove(TOKEN_KEY)
+      StorageUtil.remove(REFRESH_TOKEN_KEY)
       StorageUtil.remove(USER_INFO_KEY)
       StorageUtil.remove('rememberedLogin')
 
@@ -85,6 +85,7 @@ export interface ExtendedRequestConfig extends ErrorHandlingConfig {
   loadingGroup?: string // loading分组标识，同组共享loading状态
   smartLoading?: boolean // 是否开启智能loading检测，默认true
   _detectedLoadingRefs?: Array<{ value: boolean }> // 内部使用：检测到的loading引用
+  _retried?: boolean // 内部使用：续期访问令牌后已重试过一次
 }
 
 /* 业务错误码分类 */
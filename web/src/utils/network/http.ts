@@ -1,6 +1,6 @@
 import axios, { type AxiosInstance, type AxiosRequestConfig, type AxiosResponse, type CancelTokenSource } from 'axios'
 import { StorageUtil } from '../storage'
-import { HTTP_STATUS, REQUEST_TIMEOUT, TOKEN_KEY } from '@/constants'
+import { HTTP_STATUS, REFRESH_TOKEN_KEY, REQUEST_TIMEOUT, TOKEN_KEY } from '@/constants'
 // Avoid importing router here to prevent circular dependencies with '@/router'
 import {
   ErrorCodes,
@@ -271,6 +271,69 @@ export const getApiBaseUrl = (): string => {
 
 const apiBaseUrl = getApiBaseUrl()
 
+const REFRESH_URL = '/auth/refresh'
+const REFRESH_LOCK_NAME = 'pixelpunk-token-refresh'
+const TOKEN_REFRESH_LEEWAY = 60 // 访问令牌剩余有效期（秒）低于该值时提前续期
+
+let refreshPromise: Promise<string | null> | null = null
+
+/* 解析访问令牌的剩余有效期（秒），无法解析时返回0 */
+const tokenExpiresIn = (token: string): number => {
+  try {
+    const payload = token.split('.')[1]
+    if (!payload) {
+      return 0
+    }
+    const padded = payload + '=='.substring(0, (4 - (payload.length % 4)) % 4)
+    const claims = JSON.parse(atob(padded.replace(/-/g, '+').replace(/_/g, '/')))
+    return typeof claims.exp === 'number' ? claims.exp - Math.floor(Date.now() / 1000) : 0
+  } catch {
+    return 0
+  }
+}
+
+const requestNewToken = async (staleToken: string | null): Promise<string | null> => {
+  // 其他标签页可能已完成续期，直接使用新令牌，避免重复提交已轮换的刷新令牌导致会话被撤销
+  const current = StorageUtil.get<string>(TOKEN_KEY)
+  if (current && current !== staleToken && tokenExpiresIn(current) > TOKEN_REFRESH_LEEWAY) {
+    return current
+  }
+
+  const refreshToken = StorageUtil.get<string>(REFRESH_TOKEN_KEY)
+  if (!refreshToken) {
+    return null
+  }
+
+  try {
+    const response = await axios.post(
+      `${apiBaseUrl}${REFRESH_URL}`,
+      { refresh_token: refreshToken },
+      { timeout: REQUEST_TIMEOUT.DEFAULT }
+    )
+    const data = response.data?.data
+    if (response.data?.code !== ErrorCodes.SUCCESS || !data?.token) {
+      return null
+    }
+    const { useAuthStore } = await import('@/store/auth')
+    useAuthStore().setToken(data.token, data.refresh_token)
+    return data.token as string
+  } catch {
+    return null
+  }
+}
+
+/* 使用刷新令牌换取新的访问令牌；同一页面内的并发请求共享一次续期，多个标签页之间通过 Web Locks 串行 */
+export function refreshAccessToken(staleToken: string | null): Promise<string | null> {
+  if (!refreshPromise) {
+    const run = () => requestNewToken(staleToken)
+    const locks = typeof navigator !== 'undefined' ? navigator.locks : undefined
+    refreshPromise = (locks ? locks.request(REFRESH_LOCK_NAME, run) : run()).finally(() => {
+      refreshPromise = null
+    })
+  }
+  return refreshPromise
+}
+
 const instance: AxiosInstance = axios.create({
   baseURL: apiBaseUrl,
   timeout: REQUEST_TIMEOUT.UPLOAD, // 上传请求超时时间
@@ -300,10 +363,18 @@ function createErrorResult(code: number, message: string, request_id?: string):
 }
 
 instance.interceptors.request.use(
-  (config: ExtendedAxiosRequestConfig) => {
+  async (config: ExtendedAxiosRequestConfig) => {
     addPendingRequest(config)
 
-    const token = StorageUtil.get<string>(TOKEN_KEY)
+    let token = StorageUtil.get<string>(TOKEN_KEY)
+    if (
+      token &&
+      config.url !== REFRESH_URL &&
+      tokenExpiresIn(token) < TOKEN_REFRESH_LEEWAY &&
+      StorageUtil.get<string>(REFRESH_TOKEN_KEY)
+    ) {
+      token = (await refreshAccessToken(token)) || token
+    }
     if (token) {
       config.headers.Authorization = `Bearer ${token}`
     }
@@ -534,6 +605,22 @@ instance.interceptors.response.use(
       return Promise.reject(cancelError)
     }
 
+    // 访问令牌过期或失效时先尝试续期并重试一次，续期失败再按未登录处理
+    if (
+      error.response?.status === HTTP_STATUS.UNAUTHORIZED &&
+      config &&
+      !config._retried &&
+      config.url !== REFRESH_URL &&
+      config.headers?.Authorization
+    ) {
+      const staleToken = String(config.headers.Authorization).replace(/^Bearer /, '')
+      const newToken = await refreshAccessToken(staleToken)
+      if (newToken) {
+        config._retried = true
+        return instance(config) as Promise<any>
+      }
+    }
+
     let message = ''
     let errorCode = 999 // 默认网络错误码
 
@@ -547,6 +634,7 @@ instance.interceptors.response.use(
             showToast.error(message)
           }
           StorageUtil.remove(TOKEN_KEY)
+          StorageUtil.remove(REFRESH_TOKEN_KEY)
           const currentPath = window.location.pathname
           if (!isRedirecting && currentPath !== '/auth' && currentPath !== '/login') {
             isRedirecting = true